@@ -12,9 +12,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/api/handlers"
 	"github.com/qiuhaonan/float-backend/internal/api/routes"
 	"github.com/qiuhaonan/float-backend/internal/backup"
 	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
 	"github.com/qiuhaonan/float-backend/pkg/cache"
 	"github.com/qiuhaonan/float-backend/pkg/database"
 	"github.com/qiuhaonan/float-backend/pkg/email"
@@ -57,15 +60,45 @@ func main() {
 
 	// 自动迁移数据库表
 	logger.Info("Running database migrations...")
+	if err := migrateExternalIDIndex(); err != nil {
+		logger.Fatal("Failed to migrate database:", err)
+	}
+	splitTransferReconciliations := database.DB.Migrator().HasTable(&models.Transaction{}) &&
+		!database.DB.Migrator().HasColumn(&models.Transaction{}, "ToReconciliationID")
 	if err := database.AutoMigrate(
 		&models.User{},
 		&models.Category{},
 		&models.Account{},
 		&models.AppUpdate{},
 		&models.Transaction{},
+		&models.TransactionSplit{},
+		&models.Bill{},
+		&models.BillHistory{},
+		&models.RecurringRule{},
+		&models.Notification{},
+		&models.Budget{},
+		&models.SavingsPlan{},
+		&models.SavingsRecord{},
+		&models.Wishlist{},
+		&models.WishlistDeposit{},
+		&models.ImportProfile{},
+		&models.ImportBatch{},
+		&models.ExportJob{},
+		&models.ExchangeRate{},
+		&models.CreditStatement{},
+		&models.AccountBalanceSnapshot{},
+		&models.Loan{},
+		&models.LoanInstallment{},
+		&models.Reconciliation{},
+		&models.TransactionRule{},
 	); err != nil {
 		logger.Fatal("Failed to migrate database:", err)
 	}
+	if splitTransferReconciliations {
+		if err := migrateTransferReconciliations(); err != nil {
+			logger.Fatal("Failed to migrate database:", err)
+		}
+	}
 	logger.Info("Database migrations completed")
 
 	// 初始化 Redis
@@ -100,6 +133,121 @@ func main() {
 	backupService := backup.NewBackupService()
 	backupService.StartScheduler()
 
+	// 后台任务共用备份服务的调度器
+	accountRepo := repository.NewAccountRepository()
+	categoryRepo := repository.NewCategoryRepository()
+	transactionRepo := repository.NewTransactionRepository()
+	notificationService := service.NewNotificationService(repository.NewNotificationRepository())
+	exchangeRateService := service.NewExchangeRateService(repository.NewExchangeRateRepository(), repository.NewUserRepository())
+	transactionService := handlers.NewTransactionService()
+
+	// 汇率同步：每天从汇率数据源（离线汇率文件）写入公共汇率，启动时先同步一次，须早于周期交易记账
+	runRateSync := func() {
+		if _, err := exchangeRateService.SyncRates(); err != nil {
+			logger.Error("Failed to sync exchange rates:", err)
+		}
+	}
+	if err := backupService.AddJob("exchange rates", "0 6 * * *", runRateSync); err != nil {
+		logger.Warn("Exchange rates will not be synced automatically:", err)
+	}
+	runRateSync()
+
+	// 周期交易：每小时生成到期交易，启动时先补齐停机期间错过的期数
+	recurringService := service.NewRecurringService(repository.NewRecurringRuleRepository(), accountRepo, categoryRepo, transactionService)
+	runRecurring := func() {
+		if _, err := recurringService.ProcessDueRules(time.Now()); err != nil {
+			logger.Error("Failed to process recurring rules:", err)
+		}
+	}
+	if err := backupService.AddJob("recurring transactions", "5 * * * *", runRecurring); err != nil {
+		logger.Warn("Recurring transactions will not be generated automatically:", err)
+	}
+	go backup.Recover("recurring transactions", runRecurring)()
+
+	// 账单到期提醒：每天 09:00 发送
+	billService := service.NewBillService(repository.NewBillRepository(), accountRepo, categoryRepo, transactionService, notificationService)
+	if err := backupService.AddJob("bill reminders", "0 9 * * *", func() {
+		if _, err := billService.SendDueReminders(time.Now()); err != nil {
+			logger.Error("Failed to send bill reminders:", err)
+		}
+	}); err != nil {
+		logger.Warn("Bill reminders will not be sent automatically:", err)
+	}
+
+	// 贷款/分期：每天 00:15 将到期的各期记为支出，启动时先补齐停机期间错过的期数
+	loanService := service.NewLoanService(repository.NewLoanRepository(), accountRepo, categoryRepo, transactionService)
+	runLoans := func() {
+		if _, err := loanService.ProcessDueInstallments(time.Now()); err != nil {
+			logger.Error("Failed to post loan installments:", err)
+		}
+	}
+	if err := backupService.AddJob("loan installments", "15 0 * * *", runLoans); err != nil {
+		logger.Warn("Loan installments will not be posted automatically:", err)
+	}
+	go backup.Recover("loan installments", runLoans)()
+
+	// 信用账单：每天 08:30 生成已过账单日的账单，并发送还款提醒和额度预警
+	creditService := service.NewCreditService(accountRepo, repository.NewCreditStatementRepository(), transactionRepo, transactionService, notificationService)
+	if err := backupService.AddJob("credit statements", "30 8 * * *", func() {
+		if _, err := creditService.ProcessStatements(time.Now()); err != nil {
+			logger.Error("Failed to process credit statements:", err)
+		}
+	}); err != nil {
+		logger.Warn("Credit statements will not be generated automatically:", err)
+	}
+
+	// 余额走势：每天 03:30 重建有变动账户的余额快照，首次运行时按交易记录回填全部账户
+	balanceHistoryService := service.NewBalanceHistoryService(accountRepo, repository.NewBalanceSnapshotRepository(), transactionRepo, repository.NewLoanRepository(), exchangeRateService)
+	runSnapshots := func() {
+		if _, err := balanceHistoryService.RefreshSnapshots(); err != nil {
+			logger.Error("Failed to refresh balance snapshots:", err)
+		}
+	}
+	if err := backupService.AddJob("balance snapshots", "30 3 * * *", runSnapshots); err != nil {
+		logger.Warn("Balance snapshots will not be refreshed automatically:", err)
+	}
+	go backup.Recover("balance snapshots", runSnapshots)()
+
+	// 记账统计：每天 04:00 按交易记录校正用户的记账笔数和连续记账天数
+	userService := service.NewUserService()
+	if err := backupService.AddJob("user record stats", "0 4 * * *", func() {
+		if _, err := userService.RecomputeRecordStats(time.Now()); err != nil {
+			logger.Error("Failed to recompute user record stats:", err)
+		}
+	}); err != nil {
+		logger.Warn("User record stats will not be repaired automatically:", err)
+	}
+
+	// 数据导出：每 5 分钟补做排队中的任务（如重启前未完成的任务），每小时清理过期文件
+	exportService := service.NewExportService(repository.NewExportRepository(), transactionRepo, accountRepo, categoryRepo)
+	runExports := func() {
+		if _, err := exportService.ProcessPendingJobs(); err != nil {
+			logger.Error("Failed to process export jobs:", err)
+		}
+	}
+	if err := backupService.AddJob("export jobs", "*/5 * * * *", runExports); err != nil {
+		logger.Warn("Pending export jobs will not be retried automatically:", err)
+	}
+	if err := backupService.AddJob("export cleanup", "30 * * * *", func() {
+		if _, err := exportService.CleanupExpired(time.Now()); err != nil {
+			logger.Error("Failed to clean up export files:", err)
+		}
+	}); err != nil {
+		logger.Warn("Expired export files will not be cleaned up automatically:", err)
+	}
+	go backup.Recover("export jobs", runExports)()
+
+	// 交易导入：每 30 分钟将服务重启等原因中断的批次标记为失败，以便回滚后重新导入
+	ruleService := service.NewTransactionRuleService(repository.NewTransactionRuleRepository(), transactionRepo, accountRepo, categoryRepo, repository.NewUnitOfWork(), nil)
+	importService := service.NewImportService(repository.NewImportRepository(), transactionRepo, accountRepo, categoryRepo, transactionService, ruleService)
+	if err := backupService.AddJob("import cleanup", "*/30 * * * *", func() {
+		if _, err := importService.FailStaleBatches(time.Now()); err != nil {
+			logger.Error("Failed to clean up interrupted imports:", err)
+		}
+	}); err != nil {
+		logger.Warn("Interrupted imports will not be cleaned up automatically:", err)
+	}
+
 	// 启动服务器（goroutine）
 	go func() {
 		logger.Infof("Server starting on %s:%s", viper.GetString("server.host"), viper.GetString("server.port"))
@@ -151,3 +299,24 @@ func initConfig() error {
 
 	return nil
 }
+
+// migrateExternalIDIndex 交易订单号索引改为唯一索引前，将空订单号改为 NULL 并删除原普通索引
+func migrateExternalIDIndex() error {
+	migrator := database.DB.Migrator()
+	if !migrator.HasTable(&models.Transaction{}) || !migrator.HasIndex(&models.Transaction{}, "idx_user_external") {
+		return nil
+	}
+	if err := database.DB.Exec("UPDATE transactions SET external_id = NULL WHERE external_id = ''").Error; err != nil {
+		return err
+	}
+	return migrator.DropIndex(&models.Transaction{}, "idx_user_external")
+}
+
+// migrateTransferReconciliations 新增 to_reconciliation_id 后，将由转入账户对账标记的转账移到转入一侧
+func migrateTransferReconciliations() error {
+	if err := database.DB.Exec(`UPDATE transactions SET to_reconciliation_id = reconciliation_id
+		WHERE reconciliation_id IN (SELECT id FROM reconciliations WHERE reconciliations.account_id = transactions.to_account_id)`).Error; err != nil {
+		return err
+	}
+	return database.DB.Exec("UPDATE transactions SET reconciliation_id = NULL WHERE reconciliation_id = to_reconciliation_id").Error
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
 	"github.com/qiuhaonan/float-backend/internal/service"
 	"github.com/qiuhaonan/float-backend/internal/utils"
 	"github.com/qiuhaonan/float-backend/pkg/logger"
@@ -15,12 +16,20 @@ import (
 // AccountHandler 账户处理器
 type AccountHandler struct {
 	accountService service.AccountService
+	historyService service.BalanceHistoryService
 }
 
 // NewAccountHandler 创建账户处理器实例
 func NewAccountHandler() *AccountHandler {
 	return &AccountHandler{
 		accountService: service.NewAccountService(),
+		historyService: service.NewBalanceHistoryService(
+			repository.NewAccountRepository(),
+			repository.NewBalanceSnapshotRepository(),
+			repository.NewTransactionRepository(),
+			repository.NewLoanRepository(),
+			service.NewExchangeRateService(repository.NewExchangeRateRepository(), repository.NewUserRepository()),
+		),
 	}
 }
 
@@ -203,3 +212,34 @@ func (h *AccountHandler) GetAccountBalance(c *gin.Context) {
 	logger.Info(fmt.Sprintf("[Handler][账户余额] 获取成功 | 用户ID: %d", uID))
 	utils.SuccessResponse(c, balance)
 }
+
+// GetBalanceHistory 获取净资产与账户余额走势
+// @Summary 按天、周或月获取净资产及各账户余额走势，用于资产趋势图
+// @Tags 账户管理
+// @Accept json
+// @Produce json
+// @Param start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param end_date query string false "结束日期 (YYYY-MM-DD)，默认今天"
+// @Param granularity query string false "粒度: day/week/month，默认 day"
+// @Success 200 {object} utils.Response{data=response.BalanceHistoryResponse}
+// @Router /accounts/history [get]
+func (h *AccountHandler) GetBalanceHistory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.BalanceHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][余额走势] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	history, err := h.historyService.GetHistory(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][余额走势] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, history)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// BillHandler 账单订阅处理器
+type BillHandler struct {
+	billService service.BillService
+}
+
+// NewBillHandler 创建账单处理器实例
+func NewBillHandler() *BillHandler {
+	return &BillHandler{
+		billService: service.NewBillService(
+			repository.NewBillRepository(),
+			repository.NewAccountRepository(),
+			repository.NewCategoryRepository(),
+			NewTransactionService(),
+			service.NewNotificationService(repository.NewNotificationRepository()),
+		),
+	}
+}
+
+// GetBills 获取账单列表
+// @Summary 获取账单列表
+// @Tags 账单订阅
+// @Accept json
+// @Produce json
+// @Param active_only query bool false "仅返回启用中的账单"
+// @Success 200 {object} utils.Response{data=[]response.BillResponse}
+// @Router /bills [get]
+func (h *BillHandler) GetBills(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	activeOnly := c.Query("active_only") == "true"
+	logger.Info(fmt.Sprintf("[Handler][账单列表] 获取账单列表请求 | 用户ID: %d", uID))
+
+	bills, err := h.billService.GetBills(uID, activeOnly)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][账单列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取账单失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][账单列表] 获取成功 | 用户ID: %d | 数量: %d", uID, len(bills)))
+	utils.SuccessResponse(c, bills)
+}
+
+// GetBill 获取账单详情
+// @Summary 获取账单详情（含历史记录）
+// @Tags 账单订阅
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Success 200 {object} utils.Response{data=response.BillDetailResponse}
+// @Router /bills/:id [get]
+func (h *BillHandler) GetBill(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	billID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][账单详情] 账单ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账单ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][账单详情] 获取账单详情请求 | 用户ID: %d | 账单ID: %d", uID, billID))
+
+	bill, err := h.billService.GetBill(uID, billID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][账单详情] 获取失败 | 用户ID: %d | 账单ID: %d | 错误: %v", uID, billID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][账单详情] 获取成功 | 用户ID: %d | 账单ID: %d", uID, billID))
+	utils.SuccessResponse(c, bill)
+}
+
+// CreateBill 创建账单
+// @Summary 创建账单
+// @Tags 账单订阅
+// @Accept json
+// @Produce json
+// @Param bill body request.CreateBillRequest true "账单信息"
+// @Success 200 {object} utils.Response{data=response.BillResponse}
+// @Router /bills [post]
+func (h *BillHandler) CreateBill(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.CreateBillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建账单] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建账单] 创建账单请求 | 用户ID: %d | 账单名: %s", uID, req.BillName))
+
+	bill, err := h.billService.CreateBill(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建账单] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建账单] 创建成功 | 用户ID: %d | 账单ID: %d", uID, bill.ID))
+	utils.SuccessResponse(c, bill)
+}
+
+// UpdateBill 更新账单
+// @Summary 更新账单
+// @Tags 账单订阅
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Param bill body request.UpdateBillRequest true "更新信息"
+// @Success 200 {object} utils.Response{data=response.BillResponse}
+// @Router /bills/:id [put]
+func (h *BillHandler) UpdateBill(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	billID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新账单] 账单ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账单ID")
+		return
+	}
+
+	var req request.UpdateBillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新账单] 请求参数错误 | 用户ID: %d | 账单ID: %d | 错误: %v", uID, billID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新账单] 更新账单请求 | 用户ID: %d | 账单ID: %d", uID, billID))
+
+	bill, err := h.billService.UpdateBill(uID, billID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新账单] 更新失败 | 用户ID: %d | 账单ID: %d | 错误: %v", uID, billID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新账单] 更新成功 | 用户ID: %d | 账单ID: %d", uID, billID))
+	utils.SuccessResponse(c, bill)
+}
+
+// DeleteBill 删除账单
+// @Summary 删除账单
+// @Tags 账单订阅
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Success 200 {object} utils.Response
+// @Router /bills/:id [delete]
+func (h *BillHandler) DeleteBill(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	billID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除账单] 账单ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账单ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除账单] 删除账单请求 | 用户ID: %d | 账单ID: %d", uID, billID))
+
+	if err := h.billService.DeleteBill(uID, billID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除账单] 删除失败 | 用户ID: %d | 账单ID: %d | 错误: %v", uID, billID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除账单] 删除成功 | 用户ID: %d | 账单ID: %d", uID, billID))
+	utils.SuccessResponse(c, nil)
+}
+
+// GetUpcomingBills 获取即将到期账单
+// @Summary 获取即将到期（含已逾期）的账单
+// @Tags 账单订阅
+// @Accept json
+// @Produce json
+// @Param days query int false "未来天数" default(7)
+// @Success 200 {object} utils.Response{data=[]response.BillResponse}
+// @Router /bills/upcoming [get]
+func (h *BillHandler) GetUpcomingBills(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	days := 7
+	if d, err := strconv.Atoi(c.Query("days")); err == nil && d > 0 {
+		days = d
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][即将到期账单] 获取即将到期账单请求 | 用户ID: %d | 天数: %d", uID, days))
+
+	bills, err := h.billService.GetUpcomingBills(uID, days)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][即将到期账单] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取账单失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][即将到期账单] 获取成功 | 用户ID: %d | 数量: %d", uID, len(bills)))
+	utils.SuccessResponse(c, bills)
+}
+
+// PayBill 支付账单
+// @Summary 标记本期账单已支付，生成交易并推进下次扣费日期
+// @Tags 账单订阅
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Param body body request.PayBillRequest false "支付信息（缺省使用账单金额与账户）"
+// @Success 200 {object} utils.Response{data=response.PayBillResponse}
+// @Router /bills/:id/pay [post]
+func (h *BillHandler) PayBill(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	billID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][支付账单] 账单ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账单ID")
+		return
+	}
+
+	var req request.PayBillRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error(fmt.Sprintf("[Handler][支付账单] 请求参数错误 | 用户ID: %d | 账单ID: %d | 错误: %v", uID, billID, err))
+			utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+			return
+		}
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][支付账单] 支付账单请求 | 用户ID: %d | 账单ID: %d", uID, billID))
+
+	resp, err := h.billService.PayBill(uID, billID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][支付账单] 支付失败 | 用户ID: %d | 账单ID: %d | 错误: %v", uID, billID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][支付账单] 支付成功 | 用户ID: %d | 账单ID: %d | 交易ID: %d", uID, billID, resp.Transaction.ID))
+	utils.SuccessResponse(c, resp)
+}
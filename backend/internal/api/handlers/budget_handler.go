@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// BudgetHandler 预算处理器
+type BudgetHandler struct {
+	budgetService service.BudgetService
+}
+
+// NewBudgetHandler 创建预算处理器实例
+func NewBudgetHandler() *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: service.NewBudgetService(
+			repository.NewBudgetRepository(),
+			repository.NewTransactionRepository(),
+			repository.NewCategoryRepository(),
+			service.NewNotificationService(repository.NewNotificationRepository()),
+		),
+	}
+}
+
+// GetBudgets 获取预算列表
+// @Summary 获取预算列表
+// @Tags 预算
+// @Accept json
+// @Produce json
+// @Param active_only query bool false "仅返回启用中的预算"
+// @Success 200 {object} utils.Response{data=[]response.BudgetResponse}
+// @Router /budgets [get]
+func (h *BudgetHandler) GetBudgets(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	activeOnly := c.Query("active_only") == "true"
+	logger.Info(fmt.Sprintf("[Handler][预算列表] 获取预算列表请求 | 用户ID: %d", uID))
+
+	budgets, err := h.budgetService.GetBudgets(uID, activeOnly)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][预算列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取预算失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][预算列表] 获取成功 | 用户ID: %d | 数量: %d", uID, len(budgets)))
+	utils.SuccessResponse(c, budgets)
+}
+
+// GetBudget 获取预算详情
+// @Summary 获取预算详情（含本期支出、剩余与预计支出）
+// @Tags 预算
+// @Accept json
+// @Produce json
+// @Param id path int true "预算ID"
+// @Success 200 {object} utils.Response{data=response.BudgetResponse}
+// @Router /budgets/:id [get]
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	budgetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][预算详情] 预算ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的预算ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][预算详情] 获取预算详情请求 | 用户ID: %d | 预算ID: %d", uID, budgetID))
+
+	budget, err := h.budgetService.GetBudget(uID, budgetID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][预算详情] 获取失败 | 用户ID: %d | 预算ID: %d | 错误: %v", uID, budgetID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][预算详情] 获取成功 | 用户ID: %d | 预算ID: %d", uID, budgetID))
+	utils.SuccessResponse(c, budget)
+}
+
+// CreateBudget 创建预算
+// @Summary 创建预算
+// @Tags 预算
+// @Accept json
+// @Produce json
+// @Param budget body request.CreateBudgetRequest true "预算信息"
+// @Success 200 {object} utils.Response{data=response.BudgetResponse}
+// @Router /budgets [post]
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建预算] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建预算] 创建预算请求 | 用户ID: %d | 周期: %s", uID, req.PeriodType))
+
+	budget, err := h.budgetService.CreateBudget(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建预算] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建预算] 创建成功 | 用户ID: %d | 预算ID: %d", uID, budget.ID))
+	utils.SuccessResponse(c, budget)
+}
+
+// UpdateBudget 更新预算
+// @Summary 更新预算
+// @Tags 预算
+// @Accept json
+// @Produce json
+// @Param id path int true "预算ID"
+// @Param budget body request.UpdateBudgetRequest true "更新信息"
+// @Success 200 {object} utils.Response{data=response.BudgetResponse}
+// @Router /budgets/:id [put]
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	budgetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新预算] 预算ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的预算ID")
+		return
+	}
+
+	var req request.UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新预算] 请求参数错误 | 用户ID: %d | 预算ID: %d | 错误: %v", uID, budgetID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新预算] 更新预算请求 | 用户ID: %d | 预算ID: %d", uID, budgetID))
+
+	budget, err := h.budgetService.UpdateBudget(uID, budgetID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新预算] 更新失败 | 用户ID: %d | 预算ID: %d | 错误: %v", uID, budgetID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新预算] 更新成功 | 用户ID: %d | 预算ID: %d", uID, budgetID))
+	utils.SuccessResponse(c, budget)
+}
+
+// DeleteBudget 删除预算
+// @Summary 删除预算
+// @Tags 预算
+// @Accept json
+// @Produce json
+// @Param id path int true "预算ID"
+// @Success 200 {object} utils.Response
+// @Router /budgets/:id [delete]
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	budgetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除预算] 预算ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的预算ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除预算] 删除预算请求 | 用户ID: %d | 预算ID: %d", uID, budgetID))
+
+	if err := h.budgetService.DeleteBudget(uID, budgetID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除预算] 删除失败 | 用户ID: %d | 预算ID: %d | 错误: %v", uID, budgetID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除预算] 删除成功 | 用户ID: %d | 预算ID: %d", uID, budgetID))
+	utils.SuccessResponse(c, nil)
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
 	"github.com/qiuhaonan/float-backend/internal/service"
 	"github.com/qiuhaonan/float-backend/internal/utils"
 	"github.com/qiuhaonan/float-backend/pkg/logger"
@@ -14,13 +16,21 @@ import (
 
 // CategoryHandler 分类处理器
 type CategoryHandler struct {
-	categoryService service.CategoryService
+	categoryService   service.CategoryService
+	suggestionService service.CategorySuggestionService
 }
 
+// categorySuggestions 分类建议服务在内存中缓存各用户的模型，所有处理器共用同一实例，
+// 以便批量修改分类、合并分类和追溯执行规则后丢弃的是同一份模型
+var categorySuggestions = sync.OnceValue(func() service.CategorySuggestionService {
+	return service.NewCategorySuggestionService(repository.NewTransactionRepository(), repository.NewCategoryRepository())
+})
+
 // NewCategoryHandler 创建分类处理器实例
 func NewCategoryHandler() *CategoryHandler {
 	return &CategoryHandler{
-		categoryService: service.NewCategoryService(),
+		categoryService:   service.NewCategoryService(categorySuggestions()),
+		suggestionService: categorySuggestions(),
 	}
 }
 
@@ -30,6 +40,7 @@ func NewCategoryHandler() *CategoryHandler {
 // @Accept json
 // @Produce json
 // @Param type query string false "分类类型: expense/income"
+// @Param tree query bool false "是否按树形返回（子分类在 children 中）"
 // @Success 200 {object} utils.Response{data=[]response.CategoryResponse}
 // @Router /categories [get]
 func (h *CategoryHandler) GetCategories(c *gin.Context) {
@@ -44,11 +55,12 @@ func (h *CategoryHandler) GetCategories(c *gin.Context) {
 	uID := userID.(int64)
 	// 获取查询参数
 	categoryType := c.Query("type") // expense 或 income 或 空（全部）
+	tree := c.Query("tree") == "true"
 
 	logger.Info(fmt.Sprintf("[Handler][分类列表] 获取分类列表请求 | 用户ID: %d | 分类类型: %s", uID, categoryType))
 
 	// 获取分类列表
-	categories, err := h.categoryService.GetCategories(uID, categoryType)
+	categories, err := h.categoryService.GetCategories(uID, categoryType, tree)
 	if err != nil {
 		logger.Error(fmt.Sprintf("[Handler][分类列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
 		utils.ErrorResponse(c, http.StatusInternalServerError, "获取分类失败")
@@ -168,6 +180,8 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "分类ID"
+// @Param children query string false "存在子分类时的处理方式: reassign/cascade"
+// @Param reassign_to query int false "reassign 时子分类的新上级分类ID，默认移到被删分类的上级"
 // @Success 200 {object} utils.Response
 // @Router /categories/:id [delete]
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
@@ -181,9 +195,16 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	logger.Info(fmt.Sprintf("[Handler][删除分类] 删除分类请求 | 用户ID: %d | 分类ID: %d", uID, categoryID))
+	var req request.DeleteCategoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除分类] 请求参数错误 | 用户ID: %d | 分类ID: %d | 错误: %v", uID, categoryID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除分类] 删除分类请求 | 用户ID: %d | 分类ID: %d | 子分类处理: %s", uID, categoryID, req.Children))
 
-	if err := h.categoryService.DeleteCategory(uID, categoryID); err != nil {
+	if err := h.categoryService.DeleteCategory(uID, categoryID, &req); err != nil {
 		logger.Error(fmt.Sprintf("[Handler][删除分类] 删除失败 | 用户ID: %d | 分类ID: %d | 错误: %v", uID, categoryID, err))
 		utils.ErrorResponse(c, http.StatusForbidden, err.Error())
 		return
@@ -193,6 +214,67 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	utils.SuccessResponse(c, nil)
 }
 
+// MergeCategories 合并分类
+// @Summary 合并重复分类：源分类的交易、子分类等移到目标分类，然后停用源分类
+// @Tags 分类管理
+// @Accept json
+// @Produce json
+// @Param request body request.MergeCategoriesRequest true "源分类与目标分类"
+// @Success 200 {object} utils.Response{data=response.MergeCategoriesResponse}
+// @Router /categories/merge [post]
+func (h *CategoryHandler) MergeCategories(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.MergeCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][合并分类] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][合并分类] 合并分类请求 | 用户ID: %d | 源分类: %v | 目标分类: %d", uID, req.SourceIDs, req.TargetID))
+
+	result, err := h.categoryService.MergeCategories(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][合并分类] 合并失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][合并分类] 合并成功 | 用户ID: %d | 交易数: %d", uID, result.MovedTransactions))
+	utils.SuccessResponse(c, result)
+}
+
+// SuggestCategories 分类建议
+// @Summary 根据标题、金额、地点和时间，按用户自己的历史记账推荐可能的分类
+// @Tags 分类管理
+// @Accept json
+// @Produce json
+// @Param request body request.SuggestCategoryRequest true "交易草稿"
+// @Success 200 {object} utils.Response{data=[]response.CategorySuggestionResponse}
+// @Router /categories/suggest [post]
+func (h *CategoryHandler) SuggestCategories(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.SuggestCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][分类建议] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	suggestions, err := h.suggestionService.SuggestCategories(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][分类建议] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取分类建议失败")
+		return
+	}
+
+	utils.SuccessResponse(c, suggestions)
+}
+
 // GetSystemCategories 获取系统默认分类
 // @Summary 获取系统默认分类
 // @Tags 分类管理
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// CreditHandler 信用账户处理器
+type CreditHandler struct {
+	creditService service.CreditService
+}
+
+// NewCreditHandler 创建信用账户处理器实例
+func NewCreditHandler() *CreditHandler {
+	return &CreditHandler{
+		creditService: service.NewCreditService(
+			repository.NewAccountRepository(),
+			repository.NewCreditStatementRepository(),
+			repository.NewTransactionRepository(),
+			NewTransactionService(),
+			service.NewNotificationService(repository.NewNotificationRepository()),
+		),
+	}
+}
+
+// GetCreditAccounts 获取信用账户概览
+// @Summary 获取信用账户列表（含可用额度、使用率和下一账单日）
+// @Tags 信用账户
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]response.CreditAccountResponse}
+// @Router /credit-accounts [get]
+func (h *CreditHandler) GetCreditAccounts(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][信用账户] 获取信用账户列表请求 | 用户ID: %d", uID))
+
+	accounts, err := h.creditService.GetCreditAccounts(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][信用账户] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取信用账户失败")
+		return
+	}
+
+	utils.SuccessResponse(c, accounts)
+}
+
+// GetCreditAccount 获取单个信用账户概览
+// @Summary 获取信用账户详情
+// @Tags 信用账户
+// @Accept json
+// @Produce json
+// @Param id path int true "账户ID"
+// @Success 200 {object} utils.Response{data=response.CreditAccountResponse}
+// @Router /credit-accounts/:id [get]
+func (h *CreditHandler) GetCreditAccount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账户ID")
+		return
+	}
+
+	account, err := h.creditService.GetCreditAccount(uID, accountID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][信用账户] 获取详情失败 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, account)
+}
+
+// GetStatements 获取信用账单列表
+// @Summary 获取信用账户的历史账单
+// @Tags 信用账户
+// @Accept json
+// @Produce json
+// @Param id path int true "账户ID"
+// @Success 200 {object} utils.Response{data=[]response.CreditStatementResponse}
+// @Router /credit-accounts/:id/statements [get]
+func (h *CreditHandler) GetStatements(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账户ID")
+		return
+	}
+
+	statements, err := h.creditService.GetStatements(uID, accountID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][信用账单] 获取失败 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, statements)
+}
+
+// GetStatement 获取单期信用账单
+// @Summary 获取信用账单详情
+// @Tags 信用账户
+// @Accept json
+// @Produce json
+// @Param id path int true "账户ID"
+// @Param statementId path int true "账单ID"
+// @Success 200 {object} utils.Response{data=response.CreditStatementResponse}
+// @Router /credit-accounts/:id/statements/:statementId [get]
+func (h *CreditHandler) GetStatement(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账户ID")
+		return
+	}
+	statementID, err := strconv.ParseInt(c.Param("statementId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账单ID")
+		return
+	}
+
+	statement, err := h.creditService.GetStatement(uID, accountID, statementID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, statement)
+}
+
+// Repay 信用账户还款
+// @Summary 从储蓄卡等账户转账还款，缺省金额为最近一期账单的剩余应还
+// @Tags 信用账户
+// @Accept json
+// @Produce json
+// @Param id path int true "账户ID"
+// @Param body body request.RepayCreditRequest true "还款信息"
+// @Success 200 {object} utils.Response{data=response.RepayCreditResponse}
+// @Router /credit-accounts/:id/repay [post]
+func (h *CreditHandler) Repay(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账户ID")
+		return
+	}
+
+	var req request.RepayCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][信用还款] 请求参数错误 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][信用还款] 还款请求 | 用户ID: %d | 账户ID: %d | 来源账户: %d", uID, accountID, req.FromAccountID))
+
+	resp, err := h.creditService.Repay(uID, accountID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][信用还款] 还款失败 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][信用还款] 还款成功 | 用户ID: %d | 账户ID: %d | 交易ID: %d", uID, accountID, resp.Transaction.ID))
+	utils.SuccessResponse(c, resp)
+}
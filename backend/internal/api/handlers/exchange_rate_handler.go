@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// ExchangeRateHandler 汇率处理器
+type ExchangeRateHandler struct {
+	exchangeRateService service.ExchangeRateService
+}
+
+// NewExchangeRateHandler 创建汇率处理器实例
+func NewExchangeRateHandler() *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		exchangeRateService: service.NewExchangeRateService(repository.NewExchangeRateRepository(), repository.NewUserRepository()),
+	}
+}
+
+// GetRates 获取汇率列表
+// @Summary 获取本人录入的汇率和同步的公共汇率，可按币种筛选
+// @Tags 汇率
+// @Accept json
+// @Produce json
+// @Param currency query string false "币种代码"
+// @Success 200 {object} utils.Response{data=[]response.ExchangeRateResponse}
+// @Router /exchange-rates [get]
+func (h *ExchangeRateHandler) GetRates(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.ListExchangeRateRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	rates, err := h.exchangeRateService.GetRates(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][汇率列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, rates)
+}
+
+// CreateRate 录入汇率
+// @Summary 手工录入某一天的汇率，1 单位源币种可兑换 rate 单位目标币种
+// @Tags 汇率
+// @Accept json
+// @Produce json
+// @Param rate body request.CreateExchangeRateRequest true "汇率信息"
+// @Success 200 {object} utils.Response{data=response.ExchangeRateResponse}
+// @Router /exchange-rates [post]
+func (h *ExchangeRateHandler) CreateRate(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.CreateExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][录入汇率] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	rate, err := h.exchangeRateService.CreateRate(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][录入汇率] 录入失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, rate)
+}
+
+// UpdateRate 修改汇率
+// @Summary 修改本人录入的汇率，已记账的交易保留记账时的汇率
+// @Tags 汇率
+// @Accept json
+// @Produce json
+// @Param id path int true "汇率ID"
+// @Param rate body request.UpdateExchangeRateRequest true "汇率信息"
+// @Success 200 {object} utils.Response{data=response.ExchangeRateResponse}
+// @Router /exchange-rates/:id [put]
+func (h *ExchangeRateHandler) UpdateRate(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	rateID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的汇率ID")
+		return
+	}
+
+	var req request.UpdateExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	rate, err := h.exchangeRateService.UpdateRate(uID, rateID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][修改汇率] 修改失败 | 用户ID: %d | 汇率ID: %d | 错误: %v", uID, rateID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, rate)
+}
+
+// DeleteRate 删除汇率
+// @Summary 删除本人录入的汇率
+// @Tags 汇率
+// @Accept json
+// @Produce json
+// @Param id path int true "汇率ID"
+// @Success 200 {object} utils.Response
+// @Router /exchange-rates/:id [delete]
+func (h *ExchangeRateHandler) DeleteRate(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	rateID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的汇率ID")
+		return
+	}
+
+	if err := h.exchangeRateService.DeleteRate(uID, rateID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除汇率] 删除失败 | 用户ID: %d | 汇率ID: %d | 错误: %v", uID, rateID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil)
+}
+
+// Convert 币种换算
+// @Summary 按指定日期的汇率换算金额，未指定目标币种时换算为本位币
+// @Tags 汇率
+// @Accept json
+// @Produce json
+// @Param convert body request.ConvertCurrencyRequest true "换算参数"
+// @Success 200 {object} utils.Response{data=response.ConvertCurrencyResponse}
+// @Router /exchange-rates/convert [post]
+func (h *ExchangeRateHandler) Convert(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.ConvertCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	result, err := h.exchangeRateService.Convert(uID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, result)
+}
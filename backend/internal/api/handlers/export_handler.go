@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// ExportHandler 数据导出处理器
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+// NewExportHandler 创建数据导出处理器实例
+func NewExportHandler() *ExportHandler {
+	return &ExportHandler{
+		exportService: service.NewExportService(
+			repository.NewExportRepository(),
+			repository.NewTransactionRepository(),
+			repository.NewAccountRepository(),
+			repository.NewCategoryRepository(),
+		),
+	}
+}
+
+// CreateJob 创建导出任务
+// @Summary 创建导出任务，按交易列表的筛选条件在后台生成 CSV/XLSX/JSON 文件或 PDF 月度报告
+// @Tags 数据导出
+// @Accept json
+// @Produce json
+// @Param export body request.CreateExportRequest true "导出参数"
+// @Success 200 {object} utils.Response{data=response.ExportJobResponse}
+// @Router /exports [post]
+func (h *ExportHandler) CreateJob(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建导出] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建导出] 创建导出任务请求 | 用户ID: %d | 格式: %s", uID, req.ExportType))
+
+	job, err := h.exportService.CreateJob(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建导出] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建导出] 创建成功 | 用户ID: %d | 任务ID: %d", uID, job.ID))
+	utils.SuccessResponse(c, job)
+}
+
+// GetJobs 获取导出任务列表
+// @Summary 获取最近的导出任务
+// @Tags 数据导出
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]response.ExportJobResponse}
+// @Router /exports [get]
+func (h *ExportHandler) GetJobs(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][导出任务列表] 获取导出任务请求 | 用户ID: %d", uID))
+
+	jobs, err := h.exportService.GetJobs(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导出任务列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取导出任务失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][导出任务列表] 获取成功 | 用户ID: %d | 数量: %d", uID, len(jobs)))
+	utils.SuccessResponse(c, jobs)
+}
+
+// GetJob 获取导出任务详情
+// @Summary 获取导出任务状态，完成后返回带有效期的下载地址
+// @Tags 数据导出
+// @Accept json
+// @Produce json
+// @Param id path int true "任务ID"
+// @Success 200 {object} utils.Response{data=response.ExportJobResponse}
+// @Router /exports/:id [get]
+func (h *ExportHandler) GetJob(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导出任务详情] 任务ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的任务ID")
+		return
+	}
+
+	job, err := h.exportService.GetJob(uID, jobID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导出任务详情] 获取失败 | 用户ID: %d | 任务ID: %d | 错误: %v", uID, jobID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, job)
+}
+
+// DeleteJob 删除导出任务
+// @Summary 删除导出任务及已生成的文件
+// @Tags 数据导出
+// @Accept json
+// @Produce json
+// @Param id path int true "任务ID"
+// @Success 200 {object} utils.Response
+// @Router /exports/:id [delete]
+func (h *ExportHandler) DeleteJob(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除导出任务] 任务ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的任务ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除导出任务] 删除导出任务请求 | 用户ID: %d | 任务ID: %d", uID, jobID))
+
+	if err := h.exportService.DeleteJob(uID, jobID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除导出任务] 删除失败 | 用户ID: %d | 任务ID: %d | 错误: %v", uID, jobID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除导出任务] 删除成功 | 用户ID: %d | 任务ID: %d", uID, jobID))
+	utils.SuccessResponse(c, nil)
+}
+
+// Download 下载导出文件
+// @Summary 凭下载令牌下载导出文件，无需登录，令牌过期后失效
+// @Tags 数据导出
+// @Produce octet-stream
+// @Param token path string true "下载令牌"
+// @Success 200 {file} file
+// @Router /exports/download/:token [get]
+func (h *ExportHandler) Download(c *gin.Context) {
+	path, fileName, err := h.exportService.ResolveDownload(c.Param("token"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][下载导出文件] 下载文件 | 文件: %s", fileName))
+	c.FileAttachment(path, fileName)
+}
@@ -336,70 +336,3 @@ func DeleteCategory(c *gin.Context) {
 	logger.Info(fmt.Sprintf("[Handler][删除分类] 删除分类请求 | 用户ID: %d | 分类ID: %s", userID, categoryID))
 	utils.SuccessResponse(c, gin.H{"message": "DeleteCategory endpoint - to be implemented"})
 }
-
-// GetBills 获取账单列表
-func GetBills(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	logger.Info(fmt.Sprintf("[Handler][账单列表] 获取账单列表请求 | 用户ID: %d", userID))
-	utils.SuccessResponse(c, gin.H{"message": "GetBills endpoint - to be implemented"})
-}
-
-// CreateBill 创建账单
-func CreateBill(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	logger.Info(fmt.Sprintf("[Handler][创建账单] 创建账单请求 | 用户ID: %d", userID))
-	utils.SuccessResponse(c, gin.H{"message": "CreateBill endpoint - to be implemented"})
-}
-
-// GetBill 获取账单详情
-func GetBill(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	billID := c.Param("id")
-	logger.Info(fmt.Sprintf("[Handler][账单详情] 获取账单详情请求 | 用户ID: %d | 账单ID: %s", userID, billID))
-	utils.SuccessResponse(c, gin.H{"message": "GetBill endpoint - to be implemented"})
-}
-
-// UpdateBill 更新账单
-func UpdateBill(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	billID := c.Param("id")
-	logger.Info(fmt.Sprintf("[Handler][更新账单] 更新账单请求 | 用户ID: %d | 账单ID: %s", userID, billID))
-	utils.SuccessResponse(c, gin.H{"message": "UpdateBill endpoint - to be implemented"})
-}
-
-// DeleteBill 删除账单
-func DeleteBill(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	billID := c.Param("id")
-	logger.Info(fmt.Sprintf("[Handler][删除账单] 删除账单请求 | 用户ID: %d | 账单ID: %s", userID, billID))
-	utils.SuccessResponse(c, gin.H{"message": "DeleteBill endpoint - to be implemented"})
-}
-
-// GetUpcomingBills 获取即将到期账单
-func GetUpcomingBills(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	logger.Info(fmt.Sprintf("[Handler][即将到期账单] 获取即将到期账单请求 | 用户ID: %d", userID))
-	utils.SuccessResponse(c, gin.H{"message": "GetUpcomingBills endpoint - to be implemented"})
-}
-
-// GetNotifications 获取通知列表
-func GetNotifications(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	logger.Info(fmt.Sprintf("[Handler][通知列表] 获取通知列表请求 | 用户ID: %d", userID))
-	utils.SuccessResponse(c, gin.H{"message": "GetNotifications endpoint - to be implemented"})
-}
-
-// MarkNotificationRead 标记通知已读
-func MarkNotificationRead(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	notificationID := c.Param("id")
-	logger.Info(fmt.Sprintf("[Handler][标记通知已读] 标记通知已读请求 | 用户ID: %d | 通知ID: %s", userID, notificationID))
-	utils.SuccessResponse(c, gin.H{"message": "MarkNotificationRead endpoint - to be implemented"})
-}
-
-// GetUnreadCount 获取未读数量
-func GetUnreadCount(c *gin.Context) {
-	userID := c.GetInt64("user_id")
-	logger.Info(fmt.Sprintf("[Handler][未读数量] 获取未读数量请求 | 用户ID: %d", userID))
-	utils.SuccessResponse(c, gin.H{"message": "GetUnreadCount endpoint - to be implemented"})
-}
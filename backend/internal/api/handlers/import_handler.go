@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// 导入文件大小上限
+const maxImportFileSize = 10 << 20 // 10 MB
+
+// ImportHandler 交易导入处理器
+type ImportHandler struct {
+	importService service.ImportService
+}
+
+// NewImportHandler 创建交易导入处理器实例
+func NewImportHandler() *ImportHandler {
+	accountRepo := repository.NewAccountRepository()
+	categoryRepo := repository.NewCategoryRepository()
+	transactionRepo := repository.NewTransactionRepository()
+	return &ImportHandler{
+		importService: service.NewImportService(
+			repository.NewImportRepository(),
+			transactionRepo,
+			accountRepo,
+			categoryRepo,
+			NewTransactionService(),
+			service.NewTransactionRuleService(repository.NewTransactionRuleRepository(), transactionRepo, accountRepo, categoryRepo, repository.NewUnitOfWork(), categorySuggestions()),
+		),
+	}
+}
+
+// GetProfiles 获取列映射配置列表
+// @Summary 获取导入列映射配置列表
+// @Tags 交易导入
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]response.ImportProfileResponse}
+// @Router /imports/profiles [get]
+func (h *ImportHandler) GetProfiles(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][导入配置列表] 获取导入配置请求 | 用户ID: %d", uID))
+
+	profiles, err := h.importService.GetProfiles(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导入配置列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取导入配置失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][导入配置列表] 获取成功 | 用户ID: %d | 数量: %d", uID, len(profiles)))
+	utils.SuccessResponse(c, profiles)
+}
+
+// CreateProfile 创建列映射配置
+// @Summary 创建导入列映射配置
+// @Tags 交易导入
+// @Accept json
+// @Produce json
+// @Param profile body request.ImportProfileRequest true "列映射配置"
+// @Success 200 {object} utils.Response{data=response.ImportProfileResponse}
+// @Router /imports/profiles [post]
+func (h *ImportHandler) CreateProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.ImportProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建导入配置] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建导入配置] 创建导入配置请求 | 用户ID: %d | 名称: %s", uID, req.Name))
+
+	profile, err := h.importService.CreateProfile(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建导入配置] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建导入配置] 创建成功 | 用户ID: %d | 配置ID: %d", uID, profile.ID))
+	utils.SuccessResponse(c, profile)
+}
+
+// UpdateProfile 更新列映射配置
+// @Summary 更新导入列映射配置（整体替换）
+// @Tags 交易导入
+// @Accept json
+// @Produce json
+// @Param id path int true "配置ID"
+// @Param profile body request.ImportProfileRequest true "列映射配置"
+// @Success 200 {object} utils.Response{data=response.ImportProfileResponse}
+// @Router /imports/profiles/:id [put]
+func (h *ImportHandler) UpdateProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	profileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新导入配置] 配置ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的配置ID")
+		return
+	}
+
+	var req request.ImportProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新导入配置] 请求参数错误 | 用户ID: %d | 配置ID: %d | 错误: %v", uID, profileID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新导入配置] 更新导入配置请求 | 用户ID: %d | 配置ID: %d", uID, profileID))
+
+	profile, err := h.importService.UpdateProfile(uID, profileID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新导入配置] 更新失败 | 用户ID: %d | 配置ID: %d | 错误: %v", uID, profileID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新导入配置] 更新成功 | 用户ID: %d | 配置ID: %d", uID, profileID))
+	utils.SuccessResponse(c, profile)
+}
+
+// DeleteProfile 删除列映射配置
+// @Summary 删除导入列映射配置
+// @Tags 交易导入
+// @Accept json
+// @Produce json
+// @Param id path int true "配置ID"
+// @Success 200 {object} utils.Response
+// @Router /imports/profiles/:id [delete]
+func (h *ImportHandler) DeleteProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	profileID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除导入配置] 配置ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的配置ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除导入配置] 删除导入配置请求 | 用户ID: %d | 配置ID: %d", uID, profileID))
+
+	if err := h.importService.DeleteProfile(uID, profileID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除导入配置] 删除失败 | 用户ID: %d | 配置ID: %d | 错误: %v", uID, profileID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除导入配置] 删除成功 | 用户ID: %d | 配置ID: %d", uID, profileID))
+	utils.SuccessResponse(c, nil)
+}
+
+// Preview 上传文件并预览导入结果
+// @Summary 上传 CSV/XLSX 文件或 OFX/QIF/camt.053 银行对账单，逐行校验并生成待提交的导入批次
+// @Tags 交易导入
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV、XLSX、OFX/QFX、QIF 或 camt.053 XML 文件"
+// @Param profile_id formData int false "已保存的列映射配置ID"
+// @Param mapping formData string false "列映射配置 (JSON)，未指定 profile_id 时按第三方账单格式解析"
+// @Param source formData string false "账单来源 (alipay/wechat)，未指定配置时自动识别"
+// @Param account_id formData int false "默认账户ID，导入银行对账单时为必填的目标账户"
+// @Param category_id formData int false "默认分类ID"
+// @Success 200 {object} utils.Response{data=response.ImportPreviewResponse}
+// @Router /imports/preview [post]
+func (h *ImportHandler) Preview(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导入预览] 文件获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请上传文件")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.ErrorResponse(c, http.StatusBadRequest, "文件不能超过10MB")
+		return
+	}
+
+	var req request.PreviewImportRequest
+	if err := c.ShouldBindWith(&req, binding.FormMultipart); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导入预览] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	if req.ProfileID == nil && req.Mapping != "" {
+		var profile request.ImportProfileRequest
+		if err := json.Unmarshal([]byte(req.Mapping), &profile); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "列映射格式错误")
+			return
+		}
+		req.Profile = &profile
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][导入预览] 导入预览请求 | 用户ID: %d | 文件: %s | 文件大小: %d", uID, fileHeader.Filename, fileHeader.Size))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导入预览] 文件读取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "文件读取失败")
+		return
+	}
+	defer file.Close()
+
+	resp, err := h.importService.Preview(uID, fileHeader.Filename, file, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导入预览] 预览失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][导入预览] 预览成功 | 用户ID: %d | 批次ID: %d | 有效行数: %d/%d", uID, resp.Batch.ID, resp.Batch.ValidRows, resp.Batch.TotalRows))
+	utils.SuccessResponse(c, resp)
+}
+
+// GetBatches 获取导入批次列表
+// @Summary 获取最近的导入批次
+// @Tags 交易导入
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]response.ImportBatchResponse}
+// @Router /imports [get]
+func (h *ImportHandler) GetBatches(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][导入批次列表] 获取导入批次请求 | 用户ID: %d", uID))
+
+	batches, err := h.importService.GetBatches(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导入批次列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取导入批次失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][导入批次列表] 获取成功 | 用户ID: %d | 数量: %d", uID, len(batches)))
+	utils.SuccessResponse(c, batches)
+}
+
+// GetBatch 获取导入批次详情
+// @Summary 获取导入批次详情（含提交进度与失败原因）
+// @Tags 交易导入
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} utils.Response{data=response.ImportBatchResponse}
+// @Router /imports/:id [get]
+func (h *ImportHandler) GetBatch(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	batchID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导入批次详情] 批次ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的批次ID")
+		return
+	}
+
+	batch, err := h.importService.GetBatch(uID, batchID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][导入批次详情] 获取失败 | 用户ID: %d | 批次ID: %d | 错误: %v", uID, batchID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, batch)
+}
+
+// Commit 提交导入
+// @Summary 提交导入批次，交易在后台写入
+// @Tags 交易导入
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} utils.Response{data=response.ImportBatchResponse}
+// @Router /imports/:id/commit [post]
+func (h *ImportHandler) Commit(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	batchID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][提交导入] 批次ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的批次ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][提交导入] 提交导入请求 | 用户ID: %d | 批次ID: %d", uID, batchID))
+
+	batch, err := h.importService.Commit(uID, batchID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][提交导入] 提交失败 | 用户ID: %d | 批次ID: %d | 错误: %v", uID, batchID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][提交导入] 已开始导入 | 用户ID: %d | 批次ID: %d", uID, batchID))
+	utils.SuccessResponse(c, batch)
+}
+
+// Rollback 回滚导入
+// @Summary 回滚导入批次，删除该批次导入的全部交易并恢复账户余额
+// @Tags 交易导入
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} utils.Response{data=response.ImportBatchResponse}
+// @Router /imports/:id/rollback [post]
+func (h *ImportHandler) Rollback(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	batchID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][回滚导入] 批次ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的批次ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][回滚导入] 回滚导入请求 | 用户ID: %d | 批次ID: %d", uID, batchID))
+
+	batch, err := h.importService.Rollback(uID, batchID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][回滚导入] 回滚失败 | 用户ID: %d | 批次ID: %d | 错误: %v", uID, batchID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][回滚导入] 回滚成功 | 用户ID: %d | 批次ID: %d", uID, batchID))
+	utils.SuccessResponse(c, batch)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// LoanHandler 贷款/分期处理器
+type LoanHandler struct {
+	loanService service.LoanService
+}
+
+// NewLoanHandler 创建贷款处理器实例
+func NewLoanHandler() *LoanHandler {
+	return &LoanHandler{
+		loanService: service.NewLoanService(
+			repository.NewLoanRepository(),
+			repository.NewAccountRepository(),
+			repository.NewCategoryRepository(),
+			NewTransactionService(),
+		),
+	}
+}
+
+// GetLoans 获取贷款列表
+// @Summary 获取贷款/分期列表
+// @Tags 贷款分期
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]response.LoanResponse}
+// @Router /loans [get]
+func (h *LoanHandler) GetLoans(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][贷款列表] 获取贷款列表请求 | 用户ID: %d", uID))
+
+	loans, err := h.loanService.GetLoans(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][贷款列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取贷款失败")
+		return
+	}
+
+	utils.SuccessResponse(c, loans)
+}
+
+// GetLoan 获取贷款详情
+// @Summary 获取贷款详情（含还款计划）
+// @Tags 贷款分期
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Success 200 {object} utils.Response{data=response.LoanDetailResponse}
+// @Router /loans/:id [get]
+func (h *LoanHandler) GetLoan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	loanID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的贷款ID")
+		return
+	}
+
+	loan, err := h.loanService.GetLoan(uID, loanID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][贷款详情] 获取失败 | 用户ID: %d | 贷款ID: %d | 错误: %v", uID, loanID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, loan)
+}
+
+// PreviewSchedule 试算还款计划
+// @Summary 按本金、利率、期数和还款方式试算还款计划（不保存）
+// @Tags 贷款分期
+// @Accept json
+// @Produce json
+// @Param body body request.LoanScheduleRequest true "还款计划参数"
+// @Success 200 {object} utils.Response{data=response.LoanScheduleResponse}
+// @Router /loans/preview [post]
+func (h *LoanHandler) PreviewSchedule(c *gin.Context) {
+	var req request.LoanScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	schedule, err := h.loanService.PreviewSchedule(&req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, schedule)
+}
+
+// CreateLoan 创建贷款
+// @Summary 创建贷款/分期并生成还款计划，每期到期后自动记账
+// @Tags 贷款分期
+// @Accept json
+// @Produce json
+// @Param loan body request.CreateLoanRequest true "贷款信息"
+// @Success 200 {object} utils.Response{data=response.LoanDetailResponse}
+// @Router /loans [post]
+func (h *LoanHandler) CreateLoan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.CreateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建贷款] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建贷款] 创建贷款请求 | 用户ID: %d | 名称: %s", uID, req.Name))
+
+	loan, err := h.loanService.CreateLoan(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建贷款] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建贷款] 创建成功 | 用户ID: %d | 贷款ID: %d", uID, loan.ID))
+	utils.SuccessResponse(c, loan)
+}
+
+// UpdateLoan 更新贷款
+// @Summary 更新贷款名称、分类和备注
+// @Tags 贷款分期
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Param loan body request.UpdateLoanRequest true "贷款信息"
+// @Success 200 {object} utils.Response{data=response.LoanResponse}
+// @Router /loans/:id [put]
+func (h *LoanHandler) UpdateLoan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	loanID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的贷款ID")
+		return
+	}
+
+	var req request.UpdateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	loan, err := h.loanService.UpdateLoan(uID, loanID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新贷款] 更新失败 | 用户ID: %d | 贷款ID: %d | 错误: %v", uID, loanID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, loan)
+}
+
+// DeleteLoan 删除贷款
+// @Summary 删除贷款及还款计划（已入账的交易保留）
+// @Tags 贷款分期
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Success 200 {object} utils.Response
+// @Router /loans/:id [delete]
+func (h *LoanHandler) DeleteLoan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	loanID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的贷款ID")
+		return
+	}
+
+	if err := h.loanService.DeleteLoan(uID, loanID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除贷款] 删除失败 | 用户ID: %d | 贷款ID: %d | 错误: %v", uID, loanID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除贷款] 删除成功 | 用户ID: %d | 贷款ID: %d", uID, loanID))
+	utils.SuccessResponse(c, nil)
+}
+
+// GetUpcomingInstallments 获取预计交易
+// @Summary 获取未来若干天内待入账的分期还款（预计交易）
+// @Tags 贷款分期
+// @Accept json
+// @Produce json
+// @Param days query int false "未来天数" default(30)
+// @Success 200 {object} utils.Response{data=[]response.ProjectedTransactionResponse}
+// @Router /loans/upcoming [get]
+func (h *LoanHandler) GetUpcomingInstallments(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	days, _ := strconv.Atoi(c.Query("days"))
+
+	projected, err := h.loanService.GetUpcomingInstallments(uID, days)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][预计还款] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取预计还款失败")
+		return
+	}
+
+	utils.SuccessResponse(c, projected)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// NotificationHandler 通知处理器
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationHandler 创建通知处理器实例
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: service.NewNotificationService(repository.NewNotificationRepository()),
+	}
+}
+
+// GetNotifications 获取通知列表
+// @Summary 获取通知列表
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Param unread_only query bool false "仅未读"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} utils.Response{data=response.NotificationListResponse}
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][通知列表] 获取通知列表请求 | 用户ID: %d", uID))
+
+	var req request.ListNotificationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][通知列表] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	resp, err := h.notificationService.GetNotifications(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][通知列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取通知失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][通知列表] 获取成功 | 用户ID: %d | 总数: %d", uID, resp.Total))
+	utils.SuccessResponse(c, resp)
+}
+
+// MarkNotificationRead 标记通知已读
+// @Summary 标记通知已读
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Param id path int true "通知ID"
+// @Success 200 {object} utils.Response
+// @Router /notifications/:id/read [put]
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][标记通知已读] 通知ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的通知ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][标记通知已读] 标记通知已读请求 | 用户ID: %d | 通知ID: %d", uID, notificationID))
+
+	if err := h.notificationService.MarkRead(uID, notificationID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][标记通知已读] 标记失败 | 用户ID: %d | 通知ID: %d | 错误: %v", uID, notificationID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "标记已读失败")
+		return
+	}
+
+	utils.SuccessResponse(c, nil)
+}
+
+// MarkAllNotificationsRead 全部标记已读
+// @Summary 全部标记已读
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /notifications/read-all [put]
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][全部已读] 全部标记已读请求 | 用户ID: %d", uID))
+
+	count, err := h.notificationService.MarkAllRead(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][全部已读] 标记失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "标记已读失败")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"updated": count})
+}
+
+// DeleteNotification 删除通知
+// @Summary 删除通知
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Param id path int true "通知ID"
+// @Success 200 {object} utils.Response
+// @Router /notifications/:id [delete]
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除通知] 通知ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的通知ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除通知] 删除通知请求 | 用户ID: %d | 通知ID: %d", uID, notificationID))
+
+	if err := h.notificationService.DeleteNotification(uID, notificationID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除通知] 删除失败 | 用户ID: %d | 通知ID: %d | 错误: %v", uID, notificationID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil)
+}
+
+// GetUnreadCount 获取未读数量
+// @Summary 获取未读通知数量
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=response.UnreadCountResponse}
+// @Router /notifications/unread [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][未读数量] 获取未读数量请求 | 用户ID: %d", uID))
+
+	count, err := h.notificationService.GetUnreadCount(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][未读数量] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取未读数量失败")
+		return
+	}
+
+	utils.SuccessResponse(c, &response.UnreadCountResponse{Count: count})
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// ReconciliationHandler 账户对账处理器
+type ReconciliationHandler struct {
+	reconciliationService service.ReconciliationService
+}
+
+// NewReconciliationHandler 创建账户对账处理器实例
+func NewReconciliationHandler() *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: service.NewReconciliationService(
+			repository.NewAccountRepository(),
+			repository.NewReconciliationRepository(),
+			repository.NewTransactionRepository(),
+			repository.NewUnitOfWork(),
+			NewTransactionService(),
+		),
+	}
+}
+
+// PreviewReconciliation 对账预览
+// @Summary 输入截至某日的实际余额，返回与账面余额的差额及尚未对账的交易
+// @Tags 账户对账
+// @Accept json
+// @Produce json
+// @Param id path int true "账户ID"
+// @Param request body request.ReconcileRequest true "对账信息"
+// @Success 200 {object} utils.Response{data=response.ReconcilePreviewResponse}
+// @Router /accounts/{id}/reconcile/preview [post]
+func (h *ReconciliationHandler) PreviewReconciliation(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账户ID")
+		return
+	}
+
+	var req request.ReconcileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][对账预览] 请求参数错误 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	preview, err := h.reconciliationService.Preview(uID, accountID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][对账预览] 获取失败 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, preview)
+}
+
+// Reconcile 完成对账
+// @Summary 确认对账，存在差额时可记一笔余额调整交易，对账日及之前的交易将被锁定
+// @Tags 账户对账
+// @Accept json
+// @Produce json
+// @Param id path int true "账户ID"
+// @Param request body request.ReconcileRequest true "对账信息"
+// @Success 200 {object} utils.Response{data=response.ReconciliationResponse}
+// @Router /accounts/{id}/reconcile [post]
+func (h *ReconciliationHandler) Reconcile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账户ID")
+		return
+	}
+
+	var req request.ReconcileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][对账] 请求参数错误 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][对账] 对账请求 | 用户ID: %d | 账户ID: %d | 实际余额: %s", uID, accountID, req.StatementBalance))
+
+	reconciliation, err := h.reconciliationService.Reconcile(uID, accountID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][对账] 对账失败 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, reconciliation)
+}
+
+// GetReconciliations 获取对账记录
+// @Summary 获取账户的对账记录，按对账日期倒序
+// @Tags 账户对账
+// @Accept json
+// @Produce json
+// @Param id path int true "账户ID"
+// @Success 200 {object} utils.Response{data=[]response.ReconciliationResponse}
+// @Router /accounts/{id}/reconciliations [get]
+func (h *ReconciliationHandler) GetReconciliations(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账户ID")
+		return
+	}
+
+	reconciliations, err := h.reconciliationService.GetReconciliations(uID, accountID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][对账记录] 获取失败 | 用户ID: %d | 账户ID: %d | 错误: %v", uID, accountID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, reconciliations)
+}
+
+// UndoReconciliation 撤销对账
+// @Summary 撤销最近一次对账，解除交易锁定，已记的余额调整交易保留
+// @Tags 账户对账
+// @Accept json
+// @Produce json
+// @Param id path int true "账户ID"
+// @Param reconciliationId path int true "对账记录ID"
+// @Success 200 {object} utils.Response
+// @Router /accounts/{id}/reconciliations/{reconciliationId} [delete]
+func (h *ReconciliationHandler) UndoReconciliation(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的账户ID")
+		return
+	}
+	reconciliationID, err := strconv.ParseInt(c.Param("reconciliationId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的对账记录ID")
+		return
+	}
+
+	if err := h.reconciliationService.UndoReconciliation(uID, accountID, reconciliationID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][撤销对账] 撤销失败 | 用户ID: %d | 对账ID: %d | 错误: %v", uID, reconciliationID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// RecurringRuleHandler 周期规则处理器
+type RecurringRuleHandler struct {
+	recurringService service.RecurringService
+}
+
+// NewRecurringRuleHandler 创建周期规则处理器实例
+func NewRecurringRuleHandler() *RecurringRuleHandler {
+	return &RecurringRuleHandler{
+		recurringService: service.NewRecurringService(
+			repository.NewRecurringRuleRepository(),
+			repository.NewAccountRepository(),
+			repository.NewCategoryRepository(),
+			NewTransactionService(),
+		),
+	}
+}
+
+// GetRules 获取周期规则列表
+// @Summary 获取周期规则列表
+// @Tags 周期交易
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]response.RecurringRuleResponse}
+// @Router /recurring-rules [get]
+func (h *RecurringRuleHandler) GetRules(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	logger.Info(fmt.Sprintf("[Handler][周期规则列表] 获取周期规则列表请求 | 用户ID: %d", uID))
+
+	rules, err := h.recurringService.GetRules(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][周期规则列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取周期规则失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][周期规则列表] 获取成功 | 用户ID: %d | 数量: %d", uID, len(rules)))
+	utils.SuccessResponse(c, rules)
+}
+
+// GetRule 获取周期规则详情
+// @Summary 获取周期规则详情
+// @Tags 周期交易
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Success 200 {object} utils.Response{data=response.RecurringRuleResponse}
+// @Router /recurring-rules/:id [get]
+func (h *RecurringRuleHandler) GetRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][周期规则详情] 规则ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][周期规则详情] 获取周期规则详情请求 | 用户ID: %d | 规则ID: %d", uID, ruleID))
+
+	rule, err := h.recurringService.GetRule(uID, ruleID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][周期规则详情] 获取失败 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, rule)
+}
+
+// CreateRule 创建周期规则
+// @Summary 创建周期规则
+// @Tags 周期交易
+// @Accept json
+// @Produce json
+// @Param rule body request.CreateRecurringRuleRequest true "规则信息"
+// @Success 200 {object} utils.Response{data=response.RecurringRuleResponse}
+// @Router /recurring-rules [post]
+func (h *RecurringRuleHandler) CreateRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.CreateRecurringRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建周期规则] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建周期规则] 创建周期规则请求 | 用户ID: %d | 规则名: %s", uID, req.Name))
+
+	rule, err := h.recurringService.CreateRule(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建周期规则] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建周期规则] 创建成功 | 用户ID: %d | 规则ID: %d", uID, rule.ID))
+	utils.SuccessResponse(c, rule)
+}
+
+// UpdateRule 更新周期规则
+// @Summary 更新周期规则
+// @Tags 周期交易
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param rule body request.UpdateRecurringRuleRequest true "更新信息"
+// @Success 200 {object} utils.Response{data=response.RecurringRuleResponse}
+// @Router /recurring-rules/:id [put]
+func (h *RecurringRuleHandler) UpdateRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新周期规则] 规则ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	var req request.UpdateRecurringRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新周期规则] 请求参数错误 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新周期规则] 更新周期规则请求 | 用户ID: %d | 规则ID: %d", uID, ruleID))
+
+	rule, err := h.recurringService.UpdateRule(uID, ruleID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新周期规则] 更新失败 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新周期规则] 更新成功 | 用户ID: %d | 规则ID: %d", uID, ruleID))
+	utils.SuccessResponse(c, rule)
+}
+
+// DeleteRule 删除周期规则
+// @Summary 删除周期规则（已生成的交易保留）
+// @Tags 周期交易
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Success 200 {object} utils.Response
+// @Router /recurring-rules/:id [delete]
+func (h *RecurringRuleHandler) DeleteRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除周期规则] 规则ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除周期规则] 删除周期规则请求 | 用户ID: %d | 规则ID: %d", uID, ruleID))
+
+	if err := h.recurringService.DeleteRule(uID, ruleID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除周期规则] 删除失败 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除周期规则] 删除成功 | 用户ID: %d | 规则ID: %d", uID, ruleID))
+	utils.SuccessResponse(c, nil)
+}
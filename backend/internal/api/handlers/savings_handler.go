@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// SavingsHandler 储蓄计划处理器
+type SavingsHandler struct {
+	savingsService service.SavingsService
+}
+
+// NewSavingsHandler 创建储蓄计划处理器实例
+func NewSavingsHandler() *SavingsHandler {
+	return &SavingsHandler{
+		savingsService: service.NewSavingsService(
+			repository.NewSavingsRepository(),
+			repository.NewAccountRepository(),
+			NewTransactionService(),
+		),
+	}
+}
+
+// GetPlans 获取储蓄计划列表
+// @Summary 获取储蓄计划列表
+// @Tags 储蓄计划
+// @Accept json
+// @Produce json
+// @Param status query string false "按状态筛选 (active/completed/paused/cancelled)"
+// @Success 200 {object} utils.Response{data=[]response.SavingsPlanResponse}
+// @Router /savings [get]
+func (h *SavingsHandler) GetPlans(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	status := c.Query("status")
+	logger.Info(fmt.Sprintf("[Handler][储蓄计划列表] 获取储蓄计划列表请求 | 用户ID: %d", uID))
+
+	plans, err := h.savingsService.GetPlans(uID, status)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄计划列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取储蓄计划失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄计划列表] 获取成功 | 用户ID: %d | 数量: %d", uID, len(plans)))
+	utils.SuccessResponse(c, plans)
+}
+
+// GetPlan 获取储蓄计划详情
+// @Summary 获取储蓄计划详情（含进度与每月需存金额）
+// @Tags 储蓄计划
+// @Accept json
+// @Produce json
+// @Param id path int true "计划ID"
+// @Success 200 {object} utils.Response{data=response.SavingsPlanResponse}
+// @Router /savings/:id [get]
+func (h *SavingsHandler) GetPlan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	planID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄计划详情] 计划ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的计划ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄计划详情] 获取储蓄计划详情请求 | 用户ID: %d | 计划ID: %d", uID, planID))
+
+	plan, err := h.savingsService.GetPlan(uID, planID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄计划详情] 获取失败 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄计划详情] 获取成功 | 用户ID: %d | 计划ID: %d", uID, planID))
+	utils.SuccessResponse(c, plan)
+}
+
+// CreatePlan 创建储蓄计划
+// @Summary 创建储蓄计划
+// @Tags 储蓄计划
+// @Accept json
+// @Produce json
+// @Param plan body request.CreateSavingsPlanRequest true "储蓄计划信息"
+// @Success 200 {object} utils.Response{data=response.SavingsPlanResponse}
+// @Router /savings [post]
+func (h *SavingsHandler) CreatePlan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.CreateSavingsPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建储蓄计划] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建储蓄计划] 创建储蓄计划请求 | 用户ID: %d | 计划名: %s", uID, req.PlanName))
+
+	plan, err := h.savingsService.CreatePlan(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建储蓄计划] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建储蓄计划] 创建成功 | 用户ID: %d | 计划ID: %d", uID, plan.ID))
+	utils.SuccessResponse(c, plan)
+}
+
+// UpdatePlan 更新储蓄计划
+// @Summary 更新储蓄计划
+// @Tags 储蓄计划
+// @Accept json
+// @Produce json
+// @Param id path int true "计划ID"
+// @Param plan body request.UpdateSavingsPlanRequest true "更新信息"
+// @Success 200 {object} utils.Response{data=response.SavingsPlanResponse}
+// @Router /savings/:id [put]
+func (h *SavingsHandler) UpdatePlan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	planID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新储蓄计划] 计划ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的计划ID")
+		return
+	}
+
+	var req request.UpdateSavingsPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新储蓄计划] 请求参数错误 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新储蓄计划] 更新储蓄计划请求 | 用户ID: %d | 计划ID: %d", uID, planID))
+
+	plan, err := h.savingsService.UpdatePlan(uID, planID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新储蓄计划] 更新失败 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新储蓄计划] 更新成功 | 用户ID: %d | 计划ID: %d", uID, planID))
+	utils.SuccessResponse(c, plan)
+}
+
+// DeletePlan 删除储蓄计划
+// @Summary 删除储蓄计划
+// @Tags 储蓄计划
+// @Accept json
+// @Produce json
+// @Param id path int true "计划ID"
+// @Success 200 {object} utils.Response
+// @Router /savings/:id [delete]
+func (h *SavingsHandler) DeletePlan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	planID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除储蓄计划] 计划ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的计划ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除储蓄计划] 删除储蓄计划请求 | 用户ID: %d | 计划ID: %d", uID, planID))
+
+	if err := h.savingsService.DeletePlan(uID, planID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除储蓄计划] 删除失败 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除储蓄计划] 删除成功 | 用户ID: %d | 计划ID: %d", uID, planID))
+	utils.SuccessResponse(c, nil)
+}
+
+// GetRecords 获取存取记录
+// @Summary 获取储蓄计划的存取记录
+// @Tags 储蓄计划
+// @Accept json
+// @Produce json
+// @Param id path int true "计划ID"
+// @Success 200 {object} utils.Response{data=[]response.SavingsRecordResponse}
+// @Router /savings/:id/records [get]
+func (h *SavingsHandler) GetRecords(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	planID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄记录] 计划ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的计划ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄记录] 获取存取记录请求 | 用户ID: %d | 计划ID: %d", uID, planID))
+
+	records, err := h.savingsService.GetRecords(uID, planID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄记录] 获取失败 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄记录] 获取成功 | 用户ID: %d | 计划ID: %d | 数量: %d", uID, planID, len(records)))
+	utils.SuccessResponse(c, records)
+}
+
+// Deposit 存款
+// @Summary 从来源账户转账存入储蓄计划
+// @Tags 储蓄计划
+// @Accept json
+// @Produce json
+// @Param id path int true "计划ID"
+// @Param body body request.SavingsDepositRequest true "存款信息"
+// @Success 200 {object} utils.Response{data=response.SavingsOperationResponse}
+// @Router /savings/:id/deposit [post]
+func (h *SavingsHandler) Deposit(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	planID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄存款] 计划ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的计划ID")
+		return
+	}
+
+	var req request.SavingsDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄存款] 请求参数错误 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄存款] 存款请求 | 用户ID: %d | 计划ID: %d | 金额: %s", uID, planID, req.Amount))
+
+	resp, err := h.savingsService.Deposit(uID, planID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄存款] 存款失败 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄存款] 存款成功 | 用户ID: %d | 计划ID: %d | 交易ID: %d", uID, planID, resp.Transaction.ID))
+	utils.SuccessResponse(c, resp)
+}
+
+// Withdraw 取款
+// @Summary 从储蓄计划转账取出到目标账户
+// @Tags 储蓄计划
+// @Accept json
+// @Produce json
+// @Param id path int true "计划ID"
+// @Param body body request.SavingsWithdrawRequest true "取款信息"
+// @Success 200 {object} utils.Response{data=response.SavingsOperationResponse}
+// @Router /savings/:id/withdraw [post]
+func (h *SavingsHandler) Withdraw(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	planID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄取款] 计划ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的计划ID")
+		return
+	}
+
+	var req request.SavingsWithdrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄取款] 请求参数错误 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄取款] 取款请求 | 用户ID: %d | 计划ID: %d | 金额: %s", uID, planID, req.Amount))
+
+	resp, err := h.savingsService.Withdraw(uID, planID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][储蓄取款] 取款失败 | 用户ID: %d | 计划ID: %d | 错误: %v", uID, planID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][储蓄取款] 取款成功 | 用户ID: %d | 计划ID: %d | 交易ID: %d", uID, planID, resp.Transaction.ID))
+	utils.SuccessResponse(c, resp)
+}
@@ -17,22 +17,44 @@ import (
 // TransactionHandler 交易处理器
 type TransactionHandler struct {
 	transactionService service.TransactionService
+	quickEntryService  service.QuickEntryService
 }
 
 // NewTransactionHandler 创建交易处理器实例
 func NewTransactionHandler() *TransactionHandler {
 	return &TransactionHandler{
-		transactionService: service.NewTransactionService(
-			repository.NewTransactionRepository(),
+		transactionService: NewTransactionService(),
+		quickEntryService: service.NewQuickEntryService(
 			repository.NewAccountRepository(),
 			repository.NewCategoryRepository(),
+			categorySuggestions(),
 		),
 	}
 }
 
+// NewTransactionService 创建交易服务及其依赖（预算预警、汇率、交易规则），各处理器与后台任务共用同一套装配
+func NewTransactionService() service.TransactionService {
+	transactionRepo := repository.NewTransactionRepository()
+	accountRepo := repository.NewAccountRepository()
+	categoryRepo := repository.NewCategoryRepository()
+	uow := repository.NewUnitOfWork()
+	notificationService := service.NewNotificationService(repository.NewNotificationRepository())
+	return service.NewTransactionService(
+		transactionRepo,
+		accountRepo,
+		categoryRepo,
+		uow,
+		notificationService,
+		service.NewBudgetService(repository.NewBudgetRepository(), transactionRepo, categoryRepo, notificationService),
+		service.NewExchangeRateService(repository.NewExchangeRateRepository(), repository.NewUserRepository()),
+		service.NewTransactionRuleService(repository.NewTransactionRuleRepository(), transactionRepo, accountRepo, categoryRepo, uow, categorySuggestions()),
+		categorySuggestions(),
+	)
+}
+
 // CreateTransaction godoc
 // @Summary 创建交易
-// @Description 创建新的交易记录
+// @Description 创建新的交易记录；退款或报销到账记为收入并通过 refund_of_id 关联原支出，统计时冲减原支出
 // @Tags Transactions
 // @Accept json
 // @Produce json
@@ -74,6 +96,45 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 	})
 }
 
+// ParseQuickEntry godoc
+// @Summary 快速记账解析
+// @Description 解析一句话（如“午饭 35 微信 昨天”“salary 12000 to ICBC 25th”）生成交易草稿，按用户自己的账户和分类识别并返回各字段置信度，不创建交易
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param body body request.QuickEntryRequest true "记账文本"
+// @Success 200 {object} response.QuickEntryResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/transactions/parse [post]
+// @Security Bearer
+func (h *TransactionHandler) ParseQuickEntry(c *gin.Context) {
+	userID := c.GetInt64("user_id")
+	if userID == 0 {
+		logger.Error("[Handler][快速记账] 未授权的请求")
+		utils.ErrorResponse(c, http.StatusUnauthorized, "未授权的请求")
+		return
+	}
+
+	var req request.QuickEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][快速记账] 请求参数错误 | 用户ID: %d | 错误: %v", userID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][快速记账] 解析请求 | 用户ID: %d | 文本: %s", userID, req.Text))
+
+	resp, err := h.quickEntryService.ParseQuickEntry(userID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][快速记账] 解析失败 | 用户ID: %d | 错误: %v", userID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "解析失败")
+		return
+	}
+
+	utils.SuccessResponse(c, resp)
+}
+
 // CreateBatchTransactions godoc
 // @Summary 批量创建交易
 // @Description 批量创建多条交易记录
@@ -188,23 +249,10 @@ func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 	}
 	logger.Info(fmt.Sprintf("[Handler][交易列表] 查询交易列表请求 | 用户ID: %d", userID))
 
-	var filters request.ListTransactionRequest
-
-	// 解析查询参数
-	filters.Type = c.Query("type")
-	filters.SearchKeyword = c.Query("search_keyword")
+	filters := parseListFilters(c)
 	filters.SortBy = c.Query("sort_by")
 	filters.SortOrder = c.Query("sort_order")
 
-	// 解析数字参数
-	if categoryID, err := strconv.ParseInt(c.Query("category_id"), 10, 64); err == nil && categoryID > 0 {
-		filters.CategoryID = &categoryID
-	}
-
-	if accountID, err := strconv.ParseInt(c.Query("account_id"), 10, 64); err == nil && accountID > 0 {
-		filters.AccountID = &accountID
-	}
-
 	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
 		filters.Page = page
 	} else {
@@ -217,7 +265,32 @@ func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 		filters.PageSize = 20
 	}
 
-	// 解析日期参数
+	resp, err := h.transactionService.ListTransactions(userID, filters)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][交易列表] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][交易列表] 查询成功 | 用户ID: %d | 总数: %d", userID, resp.Total))
+	utils.SuccessResponse(c, resp)
+}
+
+// parseListFilters 解析交易列表的筛选条件（类型、分类、账户、日期范围、关键词）
+func parseListFilters(c *gin.Context) *request.ListTransactionRequest {
+	filters := &request.ListTransactionRequest{
+		Type:          c.Query("type"),
+		SearchKeyword: c.Query("search_keyword"),
+	}
+
+	if categoryID, err := strconv.ParseInt(c.Query("category_id"), 10, 64); err == nil && categoryID > 0 {
+		filters.CategoryID = &categoryID
+	}
+
+	if accountID, err := strconv.ParseInt(c.Query("account_id"), 10, 64); err == nil && accountID > 0 {
+		filters.AccountID = &accountID
+	}
+
 	if startDateStr := c.Query("start_date"); startDateStr != "" {
 		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
 			filters.StartDate = startDate
@@ -230,15 +303,7 @@ func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 		}
 	}
 
-	resp, err := h.transactionService.ListTransactions(userID, &filters)
-	if err != nil {
-		logger.Error(fmt.Sprintf("[Handler][交易列表] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	logger.Info(fmt.Sprintf("[Handler][交易列表] 查询成功 | 用户ID: %d | 总数: %d", userID, resp.Total))
-	utils.SuccessResponse(c, resp)
+	return filters
 }
 
 // UpdateTransaction godoc
@@ -371,6 +436,50 @@ func (h *TransactionHandler) DeleteBatchTransactions(c *gin.Context) {
 	utils.SuccessResponse(c, resp)
 }
 
+// RecategorizeTransactions godoc
+// @Summary 批量修改分类
+// @Description 将符合筛选条件（与交易列表相同）的交易改为指定分类，只修改与分类收支类型相同的交易，拆分交易和退款不修改
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param type query string false "交易类型 (expense/income)"
+// @Param category_id query int false "原分类ID"
+// @Param account_id query int false "账户ID"
+// @Param start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Param search_keyword query string false "搜索关键词"
+// @Param body body request.RecategorizeTransactionRequest true "目标分类"
+// @Success 200 {object} response.BulkOperationResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/transactions/recategorize [post]
+// @Security Bearer
+func (h *TransactionHandler) RecategorizeTransactions(c *gin.Context) {
+	userID := c.GetInt64("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "未授权的请求")
+		return
+	}
+
+	var req request.RecategorizeTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	filters := parseListFilters(c)
+	logger.Info(fmt.Sprintf("[Handler][批量修改分类] 请求 | 用户ID: %d | 目标分类: %d", userID, req.CategoryID))
+
+	resp, err := h.transactionService.RecategorizeTransactions(userID, filters, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][批量修改分类] 修改失败 | 用户ID: %d | 错误: %v", userID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, resp)
+}
+
 // GetTransactionStatistics godoc
 // @Summary 获取交易统计
 // @Description 获取指定日期范围内的交易统计信息
@@ -455,12 +564,13 @@ func (h *TransactionHandler) GetMonthlyStatistics(c *gin.Context) {
 
 // GetCategoryStatistics godoc
 // @Summary 获取分类统计
-// @Description 获取指定日期范围内的分类统计信息
+// @Description 获取指定日期范围内的分类统计信息，默认按末级分类统计，rollup=true 时子分类汇总到顶级分类
 // @Tags Transactions
 // @Accept json
 // @Produce json
 // @Param start_date query string false "开始日期 (YYYY-MM-DD)"
 // @Param end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Param rollup query bool false "是否汇总到顶级分类"
 // @Success 200 {object} []response.CategoryStatisticsResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -487,7 +597,35 @@ func (h *TransactionHandler) GetCategoryStatistics(c *gin.Context) {
 		}
 	}
 
-	resp, err := h.transactionService.GetCategoryStatistics(userID, startDate, endDate)
+	rollup := c.Query("rollup") == "true"
+
+	resp, err := h.transactionService.GetCategoryStatistics(userID, startDate, endDate, rollup)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, resp)
+}
+
+// GetPendingReimbursements godoc
+// @Summary 获取待报销报告
+// @Description 获取标记为可报销、尚未全额报销的支出；报销到账时记录一笔关联原支出（refund_of_id）的收入
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.ReimbursementReportResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/transactions/reimbursements/pending [get]
+// @Security Bearer
+func (h *TransactionHandler) GetPendingReimbursements(c *gin.Context) {
+	userID := c.GetInt64("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "未授权的请求")
+		return
+	}
+
+	resp, err := h.transactionService.GetPendingReimbursements(userID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
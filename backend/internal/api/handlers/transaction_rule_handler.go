@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// TransactionRuleHandler 交易规则处理器
+type TransactionRuleHandler struct {
+	ruleService service.TransactionRuleService
+}
+
+// NewTransactionRuleHandler 创建交易规则处理器实例
+func NewTransactionRuleHandler() *TransactionRuleHandler {
+	return &TransactionRuleHandler{
+		ruleService: service.NewTransactionRuleService(
+			repository.NewTransactionRuleRepository(),
+			repository.NewTransactionRepository(),
+			repository.NewAccountRepository(),
+			repository.NewCategoryRepository(),
+			repository.NewUnitOfWork(),
+			categorySuggestions(),
+		),
+	}
+}
+
+// GetRules 获取交易规则列表
+// @Summary 获取交易规则列表，按执行顺序排列
+// @Tags 交易规则
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]response.TransactionRuleResponse}
+// @Router /transaction-rules [get]
+func (h *TransactionRuleHandler) GetRules(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	rules, err := h.ruleService.GetRules(uID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][交易规则列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取交易规则失败")
+		return
+	}
+
+	utils.SuccessResponse(c, rules)
+}
+
+// CreateRule 创建交易规则
+// @Summary 创建交易规则，新建或导入的交易满足条件时自动设置分类、添加标签或修改标题
+// @Tags 交易规则
+// @Accept json
+// @Produce json
+// @Param rule body request.TransactionRuleRequest true "规则信息"
+// @Success 200 {object} utils.Response{data=response.TransactionRuleResponse}
+// @Router /transaction-rules [post]
+func (h *TransactionRuleHandler) CreateRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.TransactionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建交易规则] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建交易规则] 创建交易规则请求 | 用户ID: %d | 名称: %s", uID, req.Name))
+
+	rule, err := h.ruleService.CreateRule(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建交易规则] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, rule)
+}
+
+// UpdateRule 更新交易规则
+// @Summary 更新交易规则（整体替换）
+// @Tags 交易规则
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param rule body request.TransactionRuleRequest true "规则信息"
+// @Success 200 {object} utils.Response{data=response.TransactionRuleResponse}
+// @Router /transaction-rules/:id [put]
+func (h *TransactionRuleHandler) UpdateRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	var req request.TransactionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新交易规则] 请求参数错误 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	rule, err := h.ruleService.UpdateRule(uID, ruleID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新交易规则] 更新失败 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, rule)
+}
+
+// DeleteRule 删除交易规则
+// @Summary 删除交易规则，已执行过的修改保留
+// @Tags 交易规则
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Success 200 {object} utils.Response
+// @Router /transaction-rules/:id [delete]
+func (h *TransactionRuleHandler) DeleteRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	if err := h.ruleService.DeleteRule(uID, ruleID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除交易规则] 删除失败 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil)
+}
+
+// PreviewRule 预览交易规则
+// @Summary 列出规则对已有交易将做的修改，不写入数据
+// @Tags 交易规则
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param body body request.ApplyTransactionRuleRequest false "检查范围（缺省为最近一年）"
+// @Success 200 {object} utils.Response{data=response.RuleApplyResponse}
+// @Router /transaction-rules/:id/preview [post]
+func (h *TransactionRuleHandler) PreviewRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	var req request.ApplyTransactionRuleRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error(fmt.Sprintf("[Handler][预览交易规则] 请求参数错误 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+			utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+			return
+		}
+	}
+
+	result, err := h.ruleService.PreviewRule(uID, ruleID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][预览交易规则] 预览失败 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, result)
+}
+
+// ApplyRule 追溯执行交易规则
+// @Summary 对已有交易执行规则，匹配的交易改为规则的分类，并添加标签、修改标题
+// @Tags 交易规则
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param body body request.ApplyTransactionRuleRequest false "检查范围（缺省为最近一年）"
+// @Success 200 {object} utils.Response{data=response.RuleApplyResponse}
+// @Router /transaction-rules/:id/apply [post]
+func (h *TransactionRuleHandler) ApplyRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	var req request.ApplyTransactionRuleRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error(fmt.Sprintf("[Handler][执行交易规则] 请求参数错误 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+			utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+			return
+		}
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][执行交易规则] 追溯执行请求 | 用户ID: %d | 规则ID: %d", uID, ruleID))
+
+	result, err := h.ruleService.ApplyRule(uID, ruleID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][执行交易规则] 执行失败 | 用户ID: %d | 规则ID: %d | 错误: %v", uID, ruleID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, result)
+}
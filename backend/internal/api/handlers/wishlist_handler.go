@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/internal/service"
+	"github.com/qiuhaonan/float-backend/internal/utils"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// WishlistHandler 心愿单处理器
+type WishlistHandler struct {
+	wishlistService service.WishlistService
+}
+
+// NewWishlistHandler 创建心愿单处理器实例
+func NewWishlistHandler() *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: service.NewWishlistService(
+			repository.NewWishlistRepository(),
+			repository.NewUnitOfWork(),
+			NewTransactionService(),
+		),
+	}
+}
+
+// GetWishlists 获取心愿列表
+// @Summary 获取心愿列表
+// @Tags 心愿单
+// @Accept json
+// @Produce json
+// @Param status query string false "按状态筛选 (wishlistning/in_progress/completed/cancelled)"
+// @Success 200 {object} utils.Response{data=[]response.WishlistResponse}
+// @Router /wishlists [get]
+func (h *WishlistHandler) GetWishlists(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+	status := c.Query("status")
+	logger.Info(fmt.Sprintf("[Handler][心愿列表] 获取心愿列表请求 | 用户ID: %d", uID))
+
+	wishlists, err := h.wishlistService.GetWishlists(uID, status)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][心愿列表] 获取失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "获取心愿失败")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][心愿列表] 获取成功 | 用户ID: %d | 数量: %d", uID, len(wishlists)))
+	utils.SuccessResponse(c, wishlists)
+}
+
+// GetWishlist 获取心愿详情
+// @Summary 获取心愿详情（含进度）
+// @Tags 心愿单
+// @Accept json
+// @Produce json
+// @Param id path int true "心愿ID"
+// @Success 200 {object} utils.Response{data=response.WishlistResponse}
+// @Router /wishlists/:id [get]
+func (h *WishlistHandler) GetWishlist(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	wishlistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][心愿详情] 心愿ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的心愿ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][心愿详情] 获取心愿详情请求 | 用户ID: %d | 心愿ID: %d", uID, wishlistID))
+
+	wishlist, err := h.wishlistService.GetWishlist(uID, wishlistID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][心愿详情] 获取失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][心愿详情] 获取成功 | 用户ID: %d | 心愿ID: %d", uID, wishlistID))
+	utils.SuccessResponse(c, wishlist)
+}
+
+// CreateWishlist 创建心愿
+// @Summary 创建心愿
+// @Tags 心愿单
+// @Accept json
+// @Produce json
+// @Param wishlist body request.CreateWishlistRequest true "心愿信息"
+// @Success 200 {object} utils.Response{data=response.WishlistResponse}
+// @Router /wishlists [post]
+func (h *WishlistHandler) CreateWishlist(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	var req request.CreateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建心愿] 请求参数错误 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建心愿] 创建心愿请求 | 用户ID: %d | 物品名: %s", uID, req.ItemName))
+
+	wishlist, err := h.wishlistService.CreateWishlist(uID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][创建心愿] 创建失败 | 用户ID: %d | 错误: %v", uID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][创建心愿] 创建成功 | 用户ID: %d | 心愿ID: %d", uID, wishlist.ID))
+	utils.SuccessResponse(c, wishlist)
+}
+
+// UpdateWishlist 更新心愿
+// @Summary 更新心愿
+// @Tags 心愿单
+// @Accept json
+// @Produce json
+// @Param id path int true "心愿ID"
+// @Param wishlist body request.UpdateWishlistRequest true "更新信息"
+// @Success 200 {object} utils.Response{data=response.WishlistResponse}
+// @Router /wishlists/:id [put]
+func (h *WishlistHandler) UpdateWishlist(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	wishlistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新心愿] 心愿ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的心愿ID")
+		return
+	}
+
+	var req request.UpdateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新心愿] 请求参数错误 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新心愿] 更新心愿请求 | 用户ID: %d | 心愿ID: %d", uID, wishlistID))
+
+	wishlist, err := h.wishlistService.UpdateWishlist(uID, wishlistID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][更新心愿] 更新失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][更新心愿] 更新成功 | 用户ID: %d | 心愿ID: %d", uID, wishlistID))
+	utils.SuccessResponse(c, wishlist)
+}
+
+// DeleteWishlist 删除心愿
+// @Summary 删除心愿
+// @Tags 心愿单
+// @Accept json
+// @Produce json
+// @Param id path int true "心愿ID"
+// @Success 200 {object} utils.Response
+// @Router /wishlists/:id [delete]
+func (h *WishlistHandler) DeleteWishlist(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	wishlistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除心愿] 心愿ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的心愿ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除心愿] 删除心愿请求 | 用户ID: %d | 心愿ID: %d", uID, wishlistID))
+
+	if err := h.wishlistService.DeleteWishlist(uID, wishlistID); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][删除心愿] 删除失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][删除心愿] 删除成功 | 用户ID: %d | 心愿ID: %d", uID, wishlistID))
+	utils.SuccessResponse(c, nil)
+}
+
+// GetDeposits 获取存款记录
+// @Summary 获取心愿的存款记录
+// @Tags 心愿单
+// @Accept json
+// @Produce json
+// @Param id path int true "心愿ID"
+// @Success 200 {object} utils.Response{data=[]response.WishlistDepositResponse}
+// @Router /wishlists/:id/deposits [get]
+func (h *WishlistHandler) GetDeposits(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	wishlistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][心愿存款记录] 心愿ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的心愿ID")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][心愿存款记录] 获取存款记录请求 | 用户ID: %d | 心愿ID: %d", uID, wishlistID))
+
+	records, err := h.wishlistService.GetDeposits(uID, wishlistID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][心愿存款记录] 获取失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][心愿存款记录] 获取成功 | 用户ID: %d | 心愿ID: %d | 数量: %d", uID, wishlistID, len(records)))
+	utils.SuccessResponse(c, records)
+}
+
+// Deposit 存取预留金额
+// @Summary 为心愿存入或取出预留金额
+// @Tags 心愿单
+// @Accept json
+// @Produce json
+// @Param id path int true "心愿ID"
+// @Param body body request.WishlistDepositRequest true "存取信息"
+// @Success 200 {object} utils.Response{data=response.WishlistDepositResultResponse}
+// @Router /wishlists/:id/deposit [post]
+func (h *WishlistHandler) Deposit(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	wishlistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][心愿存款] 心愿ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的心愿ID")
+		return
+	}
+
+	var req request.WishlistDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][心愿存款] 请求参数错误 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][心愿存款] 存取请求 | 用户ID: %d | 心愿ID: %d | 金额: %s", uID, wishlistID, req.Amount))
+
+	resp, err := h.wishlistService.Deposit(uID, wishlistID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][心愿存款] 存取失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][心愿存款] 存取成功 | 用户ID: %d | 心愿ID: %d | 已存: %s", uID, wishlistID, resp.Wishlist.CurrentAmount))
+	utils.SuccessResponse(c, resp)
+}
+
+// Purchase 购买心愿
+// @Summary 购买心愿：生成关联心愿的支出交易并标记为已完成
+// @Tags 心愿单
+// @Accept json
+// @Produce json
+// @Param id path int true "心愿ID"
+// @Param body body request.PurchaseWishlistRequest true "购买信息"
+// @Success 200 {object} utils.Response{data=response.PurchaseWishlistResponse}
+// @Router /wishlists/:id/purchase [post]
+func (h *WishlistHandler) Purchase(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uID := userID.(int64)
+
+	wishlistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][购买心愿] 心愿ID格式错误 | 用户ID: %d", uID))
+		utils.ErrorResponse(c, http.StatusBadRequest, "无效的心愿ID")
+		return
+	}
+
+	var req request.PurchaseWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(fmt.Sprintf("[Handler][购买心愿] 请求参数错误 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][购买心愿] 购买请求 | 用户ID: %d | 心愿ID: %d", uID, wishlistID))
+
+	resp, err := h.wishlistService.Purchase(uID, wishlistID, &req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Handler][购买心愿] 购买失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", uID, wishlistID, err))
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Handler][购买心愿] 购买成功 | 用户ID: %d | 心愿ID: %d | 交易ID: %d", uID, wishlistID, resp.Transaction.ID))
+	utils.SuccessResponse(c, resp)
+}
@@ -1,6 +1,11 @@
 package routes
 
 import (
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/qiuhaonan/float-backend/internal/api/handlers"
 	"github.com/qiuhaonan/float-backend/internal/api/middlewares"
@@ -33,6 +38,10 @@ func SetupRouter() *gin.Engine {
 			auth.POST("/refresh", handlers.RefreshToken)
 		}
 
+		// 导出文件下载（凭有效期内的下载令牌访问，无需登录）
+		exportHandler := handlers.NewExportHandler()
+		v1.GET("/exports/download/:token", exportHandler.Download)
+
 		// 需要认证的路由
 		authorized := v1.Group("")
 		authorized.Use(middlewares.AuthMiddleware())
@@ -53,9 +62,12 @@ func SetupRouter() *gin.Engine {
 				transactions.GET("", transactionHandler.ListTransactions)
 				transactions.POST("", transactionHandler.CreateTransaction)
 				transactions.POST("/batch", transactionHandler.CreateBatchTransactions)
+				transactions.POST("/recategorize", transactionHandler.RecategorizeTransactions)
+				transactions.POST("/parse", transactionHandler.ParseQuickEntry)
 				transactions.GET("/statistics", transactionHandler.GetTransactionStatistics)
 				transactions.GET("/monthly-statistics", transactionHandler.GetMonthlyStatistics)
 				transactions.GET("/category-statistics", transactionHandler.GetCategoryStatistics)
+				transactions.GET("/reimbursements/pending", transactionHandler.GetPendingReimbursements)
 				transactions.GET("/:id", transactionHandler.GetTransaction)
 				transactions.PUT("/:id", transactionHandler.UpdateTransaction)
 				transactions.DELETE("/:id", transactionHandler.DeleteTransaction)
@@ -64,14 +76,56 @@ func SetupRouter() *gin.Engine {
 
 			// 账户管理
 			accountHandler := handlers.NewAccountHandler()
+			reconciliationHandler := handlers.NewReconciliationHandler()
 			accounts := authorized.Group("/accounts")
 			{
 				accounts.GET("", accountHandler.GetAccounts)
 				accounts.POST("", accountHandler.CreateAccount)
 				accounts.GET("/balance", accountHandler.GetAccountBalance)
+				accounts.GET("/history", accountHandler.GetBalanceHistory)
 				accounts.GET("/:id", accountHandler.GetAccount)
 				accounts.PUT("/:id", accountHandler.UpdateAccount)
 				accounts.DELETE("/:id", accountHandler.DeleteAccount)
+				// 账户对账
+				accounts.POST("/:id/reconcile/preview", reconciliationHandler.PreviewReconciliation)
+				accounts.POST("/:id/reconcile", reconciliationHandler.Reconcile)
+				accounts.GET("/:id/reconciliations", reconciliationHandler.GetReconciliations)
+				accounts.DELETE("/:id/reconciliations/:reconciliationId", reconciliationHandler.UndoReconciliation)
+			}
+
+			// 信用账户：额度、账单周期与还款
+			creditHandler := handlers.NewCreditHandler()
+			credit := authorized.Group("/credit-accounts")
+			{
+				credit.GET("", creditHandler.GetCreditAccounts)
+				credit.GET("/:id", creditHandler.GetCreditAccount)
+				credit.GET("/:id/statements", creditHandler.GetStatements)
+				credit.GET("/:id/statements/:statementId", creditHandler.GetStatement)
+				credit.POST("/:id/repay", creditHandler.Repay)
+			}
+
+			// 贷款/分期
+			loanHandler := handlers.NewLoanHandler()
+			loans := authorized.Group("/loans")
+			{
+				loans.GET("", loanHandler.GetLoans)
+				loans.POST("", loanHandler.CreateLoan)
+				loans.POST("/preview", loanHandler.PreviewSchedule)
+				loans.GET("/upcoming", loanHandler.GetUpcomingInstallments)
+				loans.GET("/:id", loanHandler.GetLoan)
+				loans.PUT("/:id", loanHandler.UpdateLoan)
+				loans.DELETE("/:id", loanHandler.DeleteLoan)
+			}
+
+			// 汇率
+			exchangeRateHandler := handlers.NewExchangeRateHandler()
+			exchangeRates := authorized.Group("/exchange-rates")
+			{
+				exchangeRates.GET("", exchangeRateHandler.GetRates)
+				exchangeRates.POST("", exchangeRateHandler.CreateRate)
+				exchangeRates.POST("/convert", exchangeRateHandler.Convert)
+				exchangeRates.PUT("/:id", exchangeRateHandler.UpdateRate)
+				exchangeRates.DELETE("/:id", exchangeRateHandler.DeleteRate)
 			}
 
 			// 分类管理
@@ -81,28 +135,121 @@ func SetupRouter() *gin.Engine {
 				categories.GET("", categoryHandler.GetCategories)
 				categories.POST("", categoryHandler.CreateCategory)
 				categories.GET("/system", categoryHandler.GetSystemCategories)
+				categories.POST("/merge", categoryHandler.MergeCategories)
+				categories.POST("/suggest", categoryHandler.SuggestCategories)
 				categories.GET("/:id", categoryHandler.GetCategory)
 				categories.PUT("/:id", categoryHandler.UpdateCategory)
 				categories.DELETE("/:id", categoryHandler.DeleteCategory)
 			}
 
 			// 账单订阅
+			billHandler := handlers.NewBillHandler()
 			bills := authorized.Group("/bills")
 			{
-				bills.GET("", handlers.GetBills)
-				bills.POST("", handlers.CreateBill)
-				bills.GET("/:id", handlers.GetBill)
-				bills.PUT("/:id", handlers.UpdateBill)
-				bills.DELETE("/:id", handlers.DeleteBill)
-				bills.GET("/upcoming", handlers.GetUpcomingBills)
+				bills.GET("", billHandler.GetBills)
+				bills.POST("", billHandler.CreateBill)
+				bills.GET("/upcoming", billHandler.GetUpcomingBills)
+				bills.GET("/:id", billHandler.GetBill)
+				bills.PUT("/:id", billHandler.UpdateBill)
+				bills.DELETE("/:id", billHandler.DeleteBill)
+				bills.POST("/:id/pay", billHandler.PayBill)
+			}
+
+			// 预算
+			budgetHandler := handlers.NewBudgetHandler()
+			budgets := authorized.Group("/budgets")
+			{
+				budgets.GET("", budgetHandler.GetBudgets)
+				budgets.POST("", budgetHandler.CreateBudget)
+				budgets.GET("/:id", budgetHandler.GetBudget)
+				budgets.PUT("/:id", budgetHandler.UpdateBudget)
+				budgets.DELETE("/:id", budgetHandler.DeleteBudget)
+			}
+
+			// 储蓄计划
+			savingsHandler := handlers.NewSavingsHandler()
+			savings := authorized.Group("/savings")
+			{
+				savings.GET("", savingsHandler.GetPlans)
+				savings.POST("", savingsHandler.CreatePlan)
+				savings.GET("/:id", savingsHandler.GetPlan)
+				savings.PUT("/:id", savingsHandler.UpdatePlan)
+				savings.DELETE("/:id", savingsHandler.DeletePlan)
+				savings.GET("/:id/records", savingsHandler.GetRecords)
+				savings.POST("/:id/deposit", savingsHandler.Deposit)
+				savings.POST("/:id/withdraw", savingsHandler.Withdraw)
+			}
+
+			// 心愿单
+			wishlistHandler := handlers.NewWishlistHandler()
+			wishlists := authorized.Group("/wishlists")
+			{
+				wishlists.GET("", wishlistHandler.GetWishlists)
+				wishlists.POST("", wishlistHandler.CreateWishlist)
+				wishlists.GET("/:id", wishlistHandler.GetWishlist)
+				wishlists.PUT("/:id", wishlistHandler.UpdateWishlist)
+				wishlists.DELETE("/:id", wishlistHandler.DeleteWishlist)
+				wishlists.GET("/:id/deposits", wishlistHandler.GetDeposits)
+				wishlists.POST("/:id/deposit", wishlistHandler.Deposit)
+				wishlists.POST("/:id/purchase", wishlistHandler.Purchase)
+			}
+
+			// 交易导入
+			importHandler := handlers.NewImportHandler()
+			imports := authorized.Group("/imports")
+			{
+				imports.GET("/profiles", importHandler.GetProfiles)
+				imports.POST("/profiles", importHandler.CreateProfile)
+				imports.PUT("/profiles/:id", importHandler.UpdateProfile)
+				imports.DELETE("/profiles/:id", importHandler.DeleteProfile)
+				imports.POST("/preview", importHandler.Preview)
+				imports.GET("", importHandler.GetBatches)
+				imports.GET("/:id", importHandler.GetBatch)
+				imports.POST("/:id/commit", importHandler.Commit)
+				imports.POST("/:id/rollback", importHandler.Rollback)
+			}
+
+			// 交易规则
+			ruleHandler := handlers.NewTransactionRuleHandler()
+			rules := authorized.Group("/transaction-rules")
+			{
+				rules.GET("", ruleHandler.GetRules)
+				rules.POST("", ruleHandler.CreateRule)
+				rules.PUT("/:id", ruleHandler.UpdateRule)
+				rules.DELETE("/:id", ruleHandler.DeleteRule)
+				rules.POST("/:id/preview", ruleHandler.PreviewRule)
+				rules.POST("/:id/apply", ruleHandler.ApplyRule)
+			}
+
+			// 数据导出
+			exports := authorized.Group("/exports")
+			{
+				exports.POST("", exportHandler.CreateJob)
+				exports.GET("", exportHandler.GetJobs)
+				exports.GET("/:id", exportHandler.GetJob)
+				exports.DELETE("/:id", exportHandler.DeleteJob)
+			}
+
+			// 周期交易
+			recurringRuleHandler := handlers.NewRecurringRuleHandler()
+			recurringRules := authorized.Group("/recurring-rules")
+			{
+				recurringRules.GET("", recurringRuleHandler.GetRules)
+				recurringRules.POST("", recurringRuleHandler.CreateRule)
+				recurringRules.GET("/:id", recurringRuleHandler.GetRule)
+				recurringRules.PUT("/:id", recurringRuleHandler.UpdateRule)
+				recurringRules.DELETE("/:id", recurringRuleHandler.DeleteRule)
 			}
 
 			// 通知
+			notificationHandler := handlers.NewNotificationHandler()
 			notifications := authorized.Group("/notifications")
 			{
-				notifications.GET("", handlers.GetNotifications)
-				notifications.PUT("/:id/read", handlers.MarkNotificationRead)
-				notifications.GET("/unread", handlers.GetUnreadCount)
+				notifications.GET("", notificationHandler.GetNotifications)
+				notifications.GET("/unread", notificationHandler.GetUnreadCount)
+				notifications.PUT("/read-all", notificationHandler.MarkAllNotificationsRead)
+				notifications.PUT("/:id/read", notificationHandler.MarkNotificationRead)
+				notifications.DELETE("/:id", notificationHandler.DeleteNotification)
 			}
 
 			// 管理员接口
@@ -117,7 +264,7 @@ func SetupRouter() *gin.Engine {
 
 		// 软件更新 (公开接口或部分公开)
 		appUpdateRepo := repository.NewAppUpdateRepository(database.DB)
-		appUpdateService := service.NewAppUpdateService(appUpdateRepo)
+		appUpdateService := service.NewAppUpdateService(appUpdateRepo, service.NewNotificationService(repository.NewNotificationRepository()))
 		appUpdateHandler := handlers.NewAppUpdateHandler(appUpdateService)
 
 		appUpdates := v1.Group("/app-updates")
@@ -129,8 +276,21 @@ func SetupRouter() *gin.Engine {
 		}
 	}
 
-	// 静态文件服务
-	router.Static("/uploads", "./uploads")
+	// 静态文件服务（导出文件只能通过下载令牌获取）
+	router.StaticFS("/uploads", uploadsFileSystem{gin.Dir("./uploads", false)})
 
 	return router
 }
+
+// uploadsFileSystem 上传目录的静态文件系统，屏蔽导出文件目录
+type uploadsFileSystem struct {
+	http.FileSystem
+}
+
+// Open 拒绝访问 exports 目录下的文件
+func (fs uploadsFileSystem) Open(name string) (http.File, error) {
+	if cleaned := path.Clean("/" + name); cleaned == "/exports" || strings.HasPrefix(cleaned, "/exports/") {
+		return nil, os.ErrNotExist
+	}
+	return fs.FileSystem.Open(name)
+}
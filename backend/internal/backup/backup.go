@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime/debug"
 	"time"
 
 	"github.com/qiuhaonan/float-backend/pkg/logger"
@@ -45,6 +46,31 @@ func (s *BackupService) StartScheduler() {
 	logger.Info("[Backup] Database backup scheduler started (Daily at 00:00)")
 }
 
+// AddJob registers another periodic job on the backup scheduler so that
+// all background jobs share a single cron instance
+func (s *BackupService) AddJob(name string, spec string, job func()) error {
+	_, err := s.cron.AddFunc(spec, Recover(name, job))
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Backup] Failed to schedule %s job: %v", name, err))
+		return err
+	}
+	logger.Info(fmt.Sprintf("[Backup] Scheduled %s job (%s)", name, spec))
+	return nil
+}
+
+// Recover wraps a background job so that a panic is logged instead of
+// taking down the whole process
+func Recover(name string, job func()) func() {
+	return func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error(fmt.Sprintf("[Backup] %s job panicked: %v\n%s", name, r, debug.Stack()))
+			}
+		}()
+		job()
+	}
+}
+
 // PerformBackup executes the mysqldump command
 func (s *BackupService) PerformBackup() error {
 	dbHost := viper.GetString("DB_HOST")
@@ -0,0 +1,41 @@
+package classifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenize 测试汉字按两字切分，英文转小写，订单号等纯数字忽略
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"美团", "团外", "外卖", "kfc"}, Tokenize("美团外卖-KFC"))
+	assert.Equal(t, []string{"茶", "latte"}, Tokenize("茶 Latte 20240301123456"))
+	assert.Empty(t, Tokenize("  ¥25.00 "))
+}
+
+// TestNaiveBayesRank 测试按历史样本排序，未出现过的特征不影响结果，可按标签过滤
+func TestNaiveBayesRank(t *testing.T) {
+	nb := NewNaiveBayes()
+	nb.Train(1, Tokenize("滴滴快车"))
+	nb.Train(1, Tokenize("滴滴专车"))
+	nb.Train(2, Tokenize("瑞幸咖啡"))
+	nb.Train(2, Tokenize("星巴克咖啡"))
+	nb.Train(2, Tokenize("Manner咖啡"))
+	assert.Equal(t, 5, nb.Samples())
+
+	scores := nb.Rank(Tokenize("滴滴快车 未知商户"), nil)
+	assert.Len(t, scores, 2)
+	assert.Equal(t, int64(1), scores[0].Label)
+	assert.InDelta(t, 1.0, scores[0].Probability+scores[1].Probability, 1e-9)
+
+	// 没有可识别的特征时按样本数排序
+	scores = nb.Rank(Tokenize("便利店"), nil)
+	assert.Equal(t, int64(2), scores[0].Label)
+
+	scores = nb.Rank(Tokenize("瑞幸咖啡"), func(label int64) bool { return label != 2 })
+	assert.Len(t, scores, 1)
+	assert.Equal(t, int64(1), scores[0].Label)
+	assert.Equal(t, 1.0, scores[0].Probability)
+
+	assert.Empty(t, NewNaiveBayes().Rank(Tokenize("咖啡"), nil))
+}
@@ -0,0 +1,99 @@
+package classifier
+
+import (
+	"math"
+	"sort"
+)
+
+// NaiveBayes 多项式朴素贝叶斯分类器，标签为分类ID，特征为文本词元等离散特征，可随时追加样本增量训练
+// 非并发安全，调用方负责加锁
+type NaiveBayes struct {
+	samples     int
+	labelCounts map[int64]int            // 标签 -> 样本数
+	tokenCounts map[int64]map[string]int // 标签 -> 特征 -> 出现次数
+	tokenTotals map[int64]int            // 标签 -> 特征总次数
+	vocabulary  map[string]bool
+}
+
+// Score 标签及其概率，同一次预测中各标签概率之和为1
+type Score struct {
+	Label       int64
+	Probability float64
+}
+
+// NewNaiveBayes 创建空的分类器
+func NewNaiveBayes() *NaiveBayes {
+	return &NaiveBayes{
+		labelCounts: make(map[int64]int),
+		tokenCounts: make(map[int64]map[string]int),
+		tokenTotals: make(map[int64]int),
+		vocabulary:  make(map[string]bool),
+	}
+}
+
+// Train 追加一个样本
+func (nb *NaiveBayes) Train(label int64, features []string) {
+	nb.samples++
+	nb.labelCounts[label]++
+	counts, ok := nb.tokenCounts[label]
+	if !ok {
+		counts = make(map[string]int)
+		nb.tokenCounts[label] = counts
+	}
+	for _, feature := range features {
+		counts[feature]++
+		nb.tokenTotals[label]++
+		nb.vocabulary[feature] = true
+	}
+}
+
+// Samples 已训练的样本数
+func (nb *NaiveBayes) Samples() int {
+	return nb.samples
+}
+
+// Rank 按概率从高到低返回 allow 允许的标签（allow 为 nil 时不过滤）
+// 使用拉普拉斯平滑，训练中从未出现的特征不参与计算
+func (nb *NaiveBayes) Rank(features []string, allow func(label int64) bool) []Score {
+	known := make([]string, 0, len(features))
+	for _, feature := range features {
+		if nb.vocabulary[feature] {
+			known = append(known, feature)
+		}
+	}
+
+	vocabularySize := float64(len(nb.vocabulary))
+	scores := make([]Score, 0, len(nb.labelCounts))
+	maxLog := math.Inf(-1)
+	for label, count := range nb.labelCounts {
+		if allow != nil && !allow(label) {
+			continue
+		}
+		logProb := math.Log(float64(count) / float64(nb.samples))
+		denominator := float64(nb.tokenTotals[label]) + vocabularySize
+		for _, feature := range known {
+			logProb += math.Log((float64(nb.tokenCounts[label][feature]) + 1) / denominator)
+		}
+		scores = append(scores, Score{Label: label, Probability: logProb})
+		if logProb > maxLog {
+			maxLog = logProb
+		}
+	}
+
+	// 对数概率归一化为概率
+	var sum float64
+	for i := range scores {
+		scores[i].Probability = math.Exp(scores[i].Probability - maxLog)
+		sum += scores[i].Probability
+	}
+	for i := range scores {
+		scores[i].Probability /= sum
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Probability != scores[j].Probability {
+			return scores[i].Probability > scores[j].Probability
+		}
+		return scores[i].Label < scores[j].Label
+	})
+	return scores
+}
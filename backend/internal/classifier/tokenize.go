@@ -0,0 +1,62 @@
+package classifier
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxWordLength 超过该长度的英文或数字串（多为订单号、流水号）不作为词元
+const maxWordLength = 20
+
+// Tokenize 将文本切分为词元：连续汉字按相邻两字切分（单个汉字保留原字），
+// 英文单词转为小写，纯数字和过长的串忽略
+func Tokenize(text string) []string {
+	tokens := make([]string, 0)
+	var han []rune
+	var word []rune
+
+	flushHan := func() {
+		switch {
+		case len(han) == 1:
+			tokens = append(tokens, string(han))
+		case len(han) > 1:
+			for i := 0; i+1 < len(han); i++ {
+				tokens = append(tokens, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+	flushWord := func() {
+		if len(word) > 0 && len(word) <= maxWordLength && !isDigits(word) {
+			tokens = append(tokens, strings.ToLower(string(word)))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+	return tokens
+}
+
+// isDigits 判断是否全部为数字
+func isDigits(word []rune) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,26 +1,55 @@
 package request
 
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
 // CreateAccountRequest 创建账户请求
 type CreateAccountRequest struct {
-	AccountType    string  `json:"account_type" binding:"required,oneof=bank alipay wechat cash credit other"`
-	AccountName    string  `json:"account_name" binding:"required,max=100"`
-	AccountNumber  string  `json:"account_number" binding:"max=50"`
-	Icon           string  `json:"icon" binding:"max=50"`
-	Color          string  `json:"color" binding:"max=20"`
-	InitialBalance float64 `json:"initial_balance"`
-	IncludeInTotal *bool   `json:"include_in_total"` // 使用指针以支持false值
-	DisplayOrder   int     `json:"display_order"`
+	AccountType    string      `json:"account_type" binding:"required,oneof=bank alipay wechat cash credit other"`
+	AccountName    string      `json:"account_name" binding:"required,max=100"`
+	AccountNumber  string      `json:"account_number" binding:"max=50"`
+	Icon           string      `json:"icon" binding:"max=50"`
+	Color          string      `json:"color" binding:"max=20"`
+	InitialBalance money.Money `json:"initial_balance"`
+	Currency       string      `json:"currency" binding:"omitempty,len=3"` // 账户币种，创建后不可修改，默认为用户本位币
+	IncludeInTotal *bool       `json:"include_in_total"`                   // 使用指针以支持false值
+	DisplayOrder   int         `json:"display_order"`
+	CreditLimit    money.Money `json:"credit_limit" binding:"gte=0"`                     // 信用额度，仅信用账户
+	BillingDay     *int        `json:"billing_day" binding:"omitempty,min=1,max=31"`     // 账单日，仅信用账户
+	PaymentDueDay  *int        `json:"payment_due_day" binding:"omitempty,min=1,max=31"` // 还款日，仅信用账户
 }
 
 // UpdateAccountRequest 更新账户请求
 type UpdateAccountRequest struct {
-	AccountName    *string  `json:"account_name" binding:"omitempty,max=100"`
-	AccountNumber  *string  `json:"account_number" binding:"omitempty,max=50"`
-	Icon           *string  `json:"icon" binding:"omitempty,max=50"`
-	Color          *string  `json:"color" binding:"omitempty,max=20"`
-	InitialBalance *float64 `json:"initial_balance"`
-	IncludeInTotal *bool    `json:"include_in_total"`
-	DisplayOrder   *int     `json:"display_order"`
-	IsActive       *bool    `json:"is_active"`
-	Balance        *float64 `json:"balance"` // 允许直接修改余额（修正用）
+	AccountName    *string      `json:"account_name" binding:"omitempty,max=100"`
+	AccountNumber  *string      `json:"account_number" binding:"omitempty,max=50"`
+	Icon           *string      `json:"icon" binding:"omitempty,max=50"`
+	Color          *string      `json:"color" binding:"omitempty,max=20"`
+	InitialBalance *money.Money `json:"initial_balance"`
+	IncludeInTotal *bool        `json:"include_in_total"`
+	DisplayOrder   *int         `json:"display_order"`
+	IsActive       *bool        `json:"is_active"`
+	Balance        *money.Money `json:"balance"` // 允许直接修改余额（修正用）
+	CreditLimit    *money.Money `json:"credit_limit" binding:"omitempty,gte=0"`
+	BillingDay     *int         `json:"billing_day" binding:"omitempty,min=1,max=31"`
+	PaymentDueDay  *int         `json:"payment_due_day" binding:"omitempty,min=1,max=31"`
+}
+
+// BalanceHistoryRequest 净资产与账户余额走势查询参数，未指定日期时按粒度取最近一段时间
+type BalanceHistoryRequest struct {
+	StartDate   *time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate     *time.Time `form:"end_date" time_format:"2006-01-02"`
+	Granularity string     `form:"granularity" binding:"omitempty,oneof=day week month"` // 默认 day
+}
+
+// ReconcileRequest 对账请求：录入账户截至某日的实际余额
+type ReconcileRequest struct {
+	StatementBalance money.Money `json:"statement_balance"` // 实际余额，信用账户欠款为负数
+	ReconcileDate    time.Time   `json:"reconcile_date" binding:"required"`
+	PostAdjustment   bool        `json:"post_adjustment"` // 存在差额时记一笔余额调整交易，否则不能完成对账
+	CategoryID       *int64      `json:"category_id"`     // 余额调整交易的分类
+	Notes            string      `json:"notes" binding:"max=500"`
 }
@@ -0,0 +1,52 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// CreateBillRequest 创建账单请求
+type CreateBillRequest struct {
+	BillName         string      `json:"bill_name" binding:"required,max=100"`
+	CategoryID       *int64      `json:"category_id"`
+	AccountID        *int64      `json:"account_id"`
+	Amount           money.Money `json:"amount" binding:"required,gt=0"`
+	Currency         string      `json:"currency" binding:"omitempty,len=3"`
+	BillingCycle     string      `json:"billing_cycle" binding:"required,oneof=daily weekly monthly quarterly yearly custom"`
+	BillingDay       *int        `json:"billing_day" binding:"omitempty,min=1,max=31"`
+	IntervalDays     int         `json:"interval_days" binding:"required_if=BillingCycle custom,min=0,max=366"`
+	NextBillingDate  *time.Time  `json:"next_billing_date"` // 为空时根据账单日推算
+	AutoRenew        *bool       `json:"auto_renew"`
+	Icon             string      `json:"icon" binding:"max=50"`
+	Color            string      `json:"color" binding:"max=20"`
+	Description      string      `json:"description"`
+	RemindDaysBefore *int        `json:"remind_days_before" binding:"omitempty,min=0,max=30"`
+}
+
+// UpdateBillRequest 更新账单请求
+type UpdateBillRequest struct {
+	BillName         *string      `json:"bill_name" binding:"omitempty,max=100"`
+	CategoryID       *int64       `json:"category_id"`
+	AccountID        *int64       `json:"account_id"`
+	Amount           *money.Money `json:"amount" binding:"omitempty,gt=0"`
+	Currency         *string      `json:"currency" binding:"omitempty,len=3"`
+	BillingCycle     *string      `json:"billing_cycle" binding:"omitempty,oneof=daily weekly monthly quarterly yearly custom"`
+	BillingDay       *int         `json:"billing_day" binding:"omitempty,min=1,max=31"`
+	IntervalDays     *int         `json:"interval_days" binding:"omitempty,min=1,max=366"`
+	NextBillingDate  *time.Time   `json:"next_billing_date"`
+	AutoRenew        *bool        `json:"auto_renew"`
+	IsActive         *bool        `json:"is_active"`
+	Icon             *string      `json:"icon" binding:"omitempty,max=50"`
+	Color            *string      `json:"color" binding:"omitempty,max=20"`
+	Description      *string      `json:"description"`
+	RemindDaysBefore *int         `json:"remind_days_before" binding:"omitempty,min=0,max=30"`
+}
+
+// PayBillRequest 标记账单已支付请求（字段为空时使用账单默认值）
+type PayBillRequest struct {
+	Amount          money.Money `json:"amount" binding:"omitempty,gt=0"`
+	AccountID       *int64      `json:"account_id"`
+	TransactionDate *time.Time  `json:"transaction_date"`
+	Notes           string      `json:"notes"`
+}
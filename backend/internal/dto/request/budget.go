@@ -0,0 +1,29 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// CreateBudgetRequest 创建预算请求
+type CreateBudgetRequest struct {
+	Name           string      `json:"name" binding:"max=100"`
+	CategoryID     *int64      `json:"category_id"` // 为空表示总预算
+	BudgetAmount   money.Money `json:"budget_amount" binding:"required,gt=0"`
+	PeriodType     string      `json:"period_type" binding:"required,oneof=weekly monthly yearly"`
+	StartDate      *time.Time  `json:"start_date"` // 为空时从本周一/本月1日/本年1月1日开始
+	EndDate        *time.Time  `json:"end_date"`
+	Rollover       bool        `json:"rollover"`
+	AlertThreshold *float64    `json:"alert_threshold" binding:"omitempty,gt=0,lt=1"`
+}
+
+// UpdateBudgetRequest 更新预算请求
+type UpdateBudgetRequest struct {
+	Name           *string      `json:"name" binding:"omitempty,max=100"`
+	BudgetAmount   *money.Money `json:"budget_amount" binding:"omitempty,gt=0"`
+	EndDate        *time.Time   `json:"end_date"`
+	Rollover       *bool        `json:"rollover"`
+	AlertThreshold *float64     `json:"alert_threshold" binding:"omitempty,gt=0,lt=1"`
+	IsActive       *bool        `json:"is_active"`
+}
@@ -1,5 +1,11 @@
 package request
 
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
 // CreateCategoryRequest 创建分类请求
 type CreateCategoryRequest struct {
 	Type         string `json:"type" binding:"required,oneof=expense income"`
@@ -7,6 +13,7 @@ type CreateCategoryRequest struct {
 	Icon         string `json:"icon" binding:"required,max=50"`
 	Color        string `json:"color" binding:"required,max=20"`
 	DisplayOrder int    `json:"display_order"`
+	ParentID     *int64 `json:"parent_id"` // 上级分类，不传时创建顶级分类
 }
 
 // UpdateCategoryRequest 更新分类请求
@@ -16,4 +23,28 @@ type UpdateCategoryRequest struct {
 	Color        *string `json:"color" binding:"omitempty,max=20"`
 	DisplayOrder *int    `json:"display_order"`
 	IsActive     *bool   `json:"is_active"`
+	ParentID     *int64  `json:"parent_id"` // 调整上级分类，传 0 时移为顶级分类
+}
+
+// DeleteCategoryRequest 删除分类请求，存在下级分类时须指定处理方式
+type DeleteCategoryRequest struct {
+	Children   string `form:"children" binding:"omitempty,oneof=reassign cascade"` // reassign 移到其他分类下，cascade 连同下级分类一并删除
+	ReassignTo *int64 `form:"reassign_to"`                                         // reassign 时的新上级分类，不传时移到被删分类的上级
+}
+
+// MergeCategoriesRequest 合并分类请求，源分类的交易移到目标分类后停用源分类
+type MergeCategoriesRequest struct {
+	SourceIDs []int64 `json:"source_ids" binding:"required,min=1,max=20"`
+	TargetID  int64   `json:"target_id" binding:"required"`
+}
+
+// SuggestCategoryRequest 分类建议请求，根据正在录入的交易推荐分类，字段均可选
+type SuggestCategoryRequest struct {
+	Type            string      `json:"type" binding:"omitempty,oneof=expense income"` // 指定时只推荐该收支类型的分类
+	Title           string      `json:"title" binding:"max=200"`
+	Location        string      `json:"location" binding:"max=200"`
+	Amount          money.Money `json:"amount" binding:"omitempty,gte=0"`
+	TransactionDate *time.Time  `json:"transaction_date"`
+	TransactionTime *time.Time  `json:"transaction_time"`
+	Limit           int         `json:"limit" binding:"omitempty,min=1,max=10"` // 返回条数，默认5
 }
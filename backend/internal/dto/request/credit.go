@@ -0,0 +1,15 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// RepayCreditRequest 信用账户还款请求，未指定金额时还清最近一期账单的剩余应还，无账单时还清当前欠款
+type RepayCreditRequest struct {
+	FromAccountID   int64       `json:"from_account_id" binding:"required"` // 还款来源账户，不能为信用账户
+	Amount          money.Money `json:"amount" binding:"omitempty,gt=0"`
+	TransactionDate *time.Time  `json:"transaction_date"`
+	Notes           string      `json:"notes"`
+}
@@ -0,0 +1,33 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// CreateExchangeRateRequest 手工录入汇率请求，1 单位 from_currency 可兑换 rate 单位 to_currency
+type CreateExchangeRateRequest struct {
+	FromCurrency string     `json:"from_currency" binding:"required,len=3"`
+	ToCurrency   string     `json:"to_currency" binding:"required,len=3"`
+	Rate         money.Rate `json:"rate" binding:"required"`
+	RateDate     time.Time  `json:"rate_date" binding:"required"`
+}
+
+// UpdateExchangeRateRequest 修改汇率请求
+type UpdateExchangeRateRequest struct {
+	Rate money.Rate `json:"rate" binding:"required"`
+}
+
+// ListExchangeRateRequest 汇率列表查询参数
+type ListExchangeRateRequest struct {
+	Currency string `form:"currency" binding:"omitempty,len=3"`
+}
+
+// ConvertCurrencyRequest 币种换算请求，未指定日期时按当天汇率
+type ConvertCurrencyRequest struct {
+	Amount       money.Money `json:"amount" binding:"required"`
+	FromCurrency string      `json:"from_currency" binding:"required,len=3"`
+	ToCurrency   string      `json:"to_currency" binding:"omitempty,len=3"` // 未指定时换算为用户本位币
+	Date         *time.Time  `json:"date"`
+}
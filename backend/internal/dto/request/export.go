@@ -0,0 +1,16 @@
+package request
+
+import "time"
+
+// CreateExportRequest 创建导出任务请求，筛选条件与交易列表一致
+// 导出 PDF 月度报告时必须指定 month（YYYY-MM），日期范围取该月
+type CreateExportRequest struct {
+	ExportType    string     `json:"export_type" binding:"required,oneof=csv excel json pdf"`
+	Month         string     `json:"month" binding:"required_if=ExportType pdf,omitempty,datetime=2006-01"`
+	Type          string     `json:"type" binding:"omitempty,oneof=expense income transfer"`
+	CategoryID    *int64     `json:"category_id"`
+	AccountID     *int64     `json:"account_id"`
+	StartDate     *time.Time `json:"start_date"`
+	EndDate       *time.Time `json:"end_date"`
+	SearchKeyword string     `json:"search_keyword" binding:"max=100"`
+}
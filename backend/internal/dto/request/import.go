@@ -0,0 +1,26 @@
+package request
+
+// ImportProfileRequest 创建或更新列映射配置请求（更新时整体替换）
+type ImportProfileRequest struct {
+	Name              string            `json:"name" binding:"required,max=100"`
+	Delimiter         string            `json:"delimiter" binding:"max=1"`
+	HeaderRow         *int              `json:"header_row" binding:"omitempty,min=0"`
+	Columns           map[string]string `json:"columns" binding:"required"`
+	DateFormat        string            `json:"date_format" binding:"max=50"`
+	TypeValues        map[string]string `json:"type_values"`
+	DefaultType       string            `json:"default_type" binding:"omitempty,oneof=expense income transfer"`
+	DefaultAccountID  *int64            `json:"default_account_id"`
+	DefaultCategoryID *int64            `json:"default_category_id"`
+}
+
+// PreviewImportRequest 导入预览请求（multipart 表单，文件字段为 file）
+// 未指定 profile_id 和 mapping 时按支付宝、微信支付账单格式自动识别
+// OFX、QIF、camt.053 银行对账单按文件扩展名识别，必须通过 account_id 指定目标账户
+type PreviewImportRequest struct {
+	Source     string                `form:"source" binding:"omitempty,oneof=alipay wechat"`
+	ProfileID  *int64                `form:"profile_id"`
+	Mapping    string                `form:"mapping"` // 未使用已保存配置时提交的列映射（JSON，格式同 ImportProfileRequest）
+	AccountID  *int64                `form:"account_id"`
+	CategoryID *int64                `form:"category_id"`
+	Profile    *ImportProfileRequest `form:"-"` // 由 Mapping 解析得到
+}
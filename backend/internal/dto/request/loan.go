@@ -0,0 +1,34 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// LoanScheduleRequest 还款计划参数，用于试算和创建贷款
+type LoanScheduleRequest struct {
+	Principal       money.Money `json:"principal" binding:"required,gt=0"`
+	AnnualRate      float64     `json:"annual_rate" binding:"gte=0,lte=100"` // 年化利率（百分比），0 表示免息
+	RepaymentMethod string      `json:"repayment_method" binding:"required,oneof=equal_installment equal_principal"`
+	Term            int         `json:"term" binding:"required,min=1,max=360"` // 期数（月）
+	FirstDueDate    time.Time   `json:"first_due_date" binding:"required"`     // 首期还款日，之后每月同一天
+}
+
+// CreateLoanRequest 创建贷款/分期请求
+type CreateLoanRequest struct {
+	LoanScheduleRequest
+	Name        string `json:"name" binding:"required,max=100"`
+	LoanType    string `json:"loan_type" binding:"omitempty,oneof=installment loan"`
+	AccountID   int64  `json:"account_id" binding:"required"` // 还款账户，每期在该账户记一笔支出
+	CategoryID  *int64 `json:"category_id"`
+	PaidPeriods int    `json:"paid_periods" binding:"min=0"` // 录入进行中的贷款时已还的期数，这些期不再生成交易
+	Notes       string `json:"notes"`
+}
+
+// UpdateLoanRequest 更新贷款请求，还款计划创建后不可修改
+type UpdateLoanRequest struct {
+	Name       *string `json:"name" binding:"omitempty,max=100"`
+	CategoryID *int64  `json:"category_id"`
+	Notes      *string `json:"notes"`
+}
@@ -0,0 +1,8 @@
+package request
+
+// ListNotificationRequest 查询通知列表请求
+type ListNotificationRequest struct {
+	UnreadOnly bool `form:"unread_only"`
+	Page       int  `form:"page"`
+	PageSize   int  `form:"page_size"`
+}
@@ -0,0 +1,42 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// CreateRecurringRuleRequest 创建周期规则请求
+type CreateRecurringRuleRequest struct {
+	Name           string      `json:"name" binding:"required,max=100"`
+	Type           string      `json:"type" binding:"required,oneof=expense income transfer"`
+	CategoryID     *int64      `json:"category_id"`
+	AccountID      *int64      `json:"account_id" binding:"required"`
+	ToAccountID    *int64      `json:"to_account_id" binding:"required_if=Type transfer"`
+	Amount         money.Money `json:"amount" binding:"required,gt=0"`
+	Currency       string      `json:"currency" binding:"omitempty,len=3"`
+	Title          string      `json:"title" binding:"max=200"`
+	Description    string      `json:"description"`
+	Frequency      string      `json:"frequency" binding:"required,oneof=daily weekly monthly yearly"`
+	Interval       int         `json:"interval" binding:"omitempty,min=1,max=366"`
+	ByMonthDay     *int        `json:"by_month_day" binding:"omitempty,min=1,max=31"`
+	StartDate      time.Time   `json:"start_date" binding:"required"`
+	EndDate        *time.Time  `json:"end_date"`
+	MaxOccurrences *int        `json:"max_occurrences" binding:"omitempty,min=1"`
+}
+
+// UpdateRecurringRuleRequest 更新周期规则请求
+// 频率、间隔和固定日期不可修改（需删除后重建）；暂停的规则恢复启用时不补记暂停期间的期数
+type UpdateRecurringRuleRequest struct {
+	Name           *string      `json:"name" binding:"omitempty,max=100"`
+	CategoryID     *int64       `json:"category_id"`
+	AccountID      *int64       `json:"account_id"`
+	ToAccountID    *int64       `json:"to_account_id"`
+	Amount         *money.Money `json:"amount" binding:"omitempty,gt=0"`
+	Currency       *string      `json:"currency" binding:"omitempty,len=3"`
+	Title          *string      `json:"title" binding:"omitempty,max=200"`
+	Description    *string      `json:"description"`
+	EndDate        *time.Time   `json:"end_date"`
+	MaxOccurrences *int         `json:"max_occurrences" binding:"omitempty,min=1"`
+	IsActive       *bool        `json:"is_active"`
+}
@@ -0,0 +1,54 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// CreateSavingsPlanRequest 创建储蓄计划请求
+type CreateSavingsPlanRequest struct {
+	PlanName       string       `json:"plan_name" binding:"required,max=100"`
+	PlanType       string       `json:"plan_type" binding:"max=50"`
+	TargetAmount   money.Money  `json:"target_amount" binding:"required,gt=0"`
+	StartDate      *time.Time   `json:"start_date"` // 为空时从今天开始
+	TargetDate     *time.Time   `json:"target_date"`
+	Frequency      *string      `json:"frequency" binding:"omitempty,oneof=daily weekly monthly"`
+	PeriodicAmount *money.Money `json:"periodic_amount" binding:"omitempty,gt=0"`
+	AccountID      int64        `json:"account_id" binding:"required"`
+	Icon           string       `json:"icon" binding:"max=50"`
+	Color          string       `json:"color" binding:"max=20"`
+	Description    string       `json:"description"`
+}
+
+// UpdateSavingsPlanRequest 更新储蓄计划请求
+type UpdateSavingsPlanRequest struct {
+	PlanName       *string      `json:"plan_name" binding:"omitempty,max=100"`
+	PlanType       *string      `json:"plan_type" binding:"omitempty,max=50"`
+	TargetAmount   *money.Money `json:"target_amount" binding:"omitempty,gt=0"`
+	TargetDate     *time.Time   `json:"target_date"`
+	Frequency      *string      `json:"frequency" binding:"omitempty,oneof=daily weekly monthly"`
+	PeriodicAmount *money.Money `json:"periodic_amount" binding:"omitempty,gt=0"`
+	AccountID      *int64       `json:"account_id"`
+	Icon           *string      `json:"icon" binding:"omitempty,max=50"`
+	Color          *string      `json:"color" binding:"omitempty,max=20"`
+	Description    *string      `json:"description"`
+	Status         *string      `json:"status" binding:"omitempty,oneof=active paused cancelled"`
+}
+
+// SavingsDepositRequest 存款请求：从来源账户转入计划关联账户
+type SavingsDepositRequest struct {
+	Amount        money.Money `json:"amount" binding:"required,gt=0"`
+	FromAccountID int64       `json:"from_account_id" binding:"required"`
+	DepositDate   *time.Time  `json:"deposit_date"`
+	PeriodNumber  *int        `json:"period_number" binding:"omitempty,min=1"`
+	Notes         string      `json:"notes"`
+}
+
+// SavingsWithdrawRequest 取款请求：从计划关联账户转出到目标账户
+type SavingsWithdrawRequest struct {
+	Amount       money.Money `json:"amount" binding:"required,gt=0"`
+	ToAccountID  int64       `json:"to_account_id" binding:"required"`
+	WithdrawDate *time.Time  `json:"withdraw_date"`
+	Notes        string      `json:"notes"`
+}
@@ -1,57 +1,78 @@
 package request
 
-import "time"
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
 
 // CreateTransactionRequest 创建交易请求
 type CreateTransactionRequest struct {
-	Type            string     `json:"type" binding:"required,oneof=expense income transfer"`
-	CategoryID      *int64     `json:"category_id"`
-	AccountID       *int64     `json:"account_id" binding:"required_if=Type expense,required_if=Type income"`
-	ToAccountID     *int64     `json:"to_account_id" binding:"required_if=Type transfer"`
-	Amount          float64    `json:"amount" binding:"required,gt=0"`
-	Currency        string     `json:"currency" binding:"omitempty,len=3"`
-	Title           string     `json:"title" binding:"max=200"`
-	Description     string     `json:"description"`
-	Location        string     `json:"location" binding:"max=200"`
-	TransactionDate time.Time  `json:"transaction_date" binding:"required"`
-	TransactionTime *time.Time `json:"transaction_time"`
-	BillID          *int64     `json:"bill_id"`
-	WishlistID      *int64     `json:"wishlist_id"`
-	Tags            []string   `json:"tags"`
-	Images          []string   `json:"images"`
+	Type            string         `json:"type" binding:"required,oneof=expense income transfer"`
+	CategoryID      *int64         `json:"category_id"`
+	AccountID       *int64         `json:"account_id" binding:"required_if=Type expense,required_if=Type income"`
+	ToAccountID     *int64         `json:"to_account_id" binding:"required_if=Type transfer"`
+	Amount          money.Money    `json:"amount" binding:"required,gt=0"`
+	Currency        string         `json:"currency" binding:"omitempty,len=3"`
+	Title           string         `json:"title" binding:"max=200"`
+	Description     string         `json:"description"`
+	Location        string         `json:"location" binding:"max=200"`
+	TransactionDate time.Time      `json:"transaction_date" binding:"required"`
+	TransactionTime *time.Time     `json:"transaction_time"`
+	BillID          *int64         `json:"-"`            // 仅由账单付款设置
+	WishlistID      *int64         `json:"-"`            // 仅由心愿购买设置
+	RecurringRuleID *int64         `json:"-"`            // 仅由周期规则任务设置
+	ImportBatchID   *int64         `json:"-"`            // 仅由导入任务设置
+	ExternalID      string         `json:"-"`            // 仅由导入任务设置
+	RulesApplied    bool           `json:"-"`            // 导入预览时已执行过交易规则
+	AutoPosted      bool           `json:"-"`            // 由后台任务自动记账（如贷款分期），不计入用户记账统计
+	RefundOfID      *int64         `json:"refund_of_id"` // 退款或报销到账时关联的原支出，须为收入类型
+	Reimbursable    bool           `json:"reimbursable"` // 支出可报销
+	Tags            []string       `json:"tags"`
+	Images          []string       `json:"images"`
+	Splits          []SplitRequest `json:"splits" binding:"omitempty,max=20,dive"` // 按分类拆分，至少两项且金额之和等于交易金额
+}
+
+// SplitRequest 交易拆分明细
+type SplitRequest struct {
+	CategoryID *int64      `json:"category_id" binding:"required"`
+	Amount     money.Money `json:"amount" binding:"required,gt=0"`
+	Note       string      `json:"note" binding:"max=200"`
 }
 
 // UpdateTransactionRequest 更新交易请求
 type UpdateTransactionRequest struct {
-	Type            string     `json:"type" binding:"omitempty,oneof=expense income transfer"`
-	CategoryID      *int64     `json:"category_id"`
-	AccountID       *int64     `json:"account_id"`
-	ToAccountID     *int64     `json:"to_account_id"`
-	Amount          float64    `json:"amount" binding:"omitempty,gt=0"`
-	Currency        string     `json:"currency" binding:"omitempty,len=3"`
-	Title           string     `json:"title" binding:"omitempty,max=200"`
-	Description     string     `json:"description"`
-	Location        string     `json:"location" binding:"omitempty,max=200"`
-	TransactionDate time.Time  `json:"transaction_date"`
-	TransactionTime *time.Time `json:"transaction_time"`
-	BillID          *int64     `json:"bill_id"`
-	WishlistID      *int64     `json:"wishlist_id"`
-	Tags            []string   `json:"tags"`
-	Images          []string   `json:"images"`
+	Type            string         `json:"type" binding:"omitempty,oneof=expense income transfer"`
+	CategoryID      *int64         `json:"category_id"`
+	AccountID       *int64         `json:"account_id"`
+	ToAccountID     *int64         `json:"to_account_id"`
+	Amount          money.Money    `json:"amount" binding:"omitempty,gt=0"`
+	Currency        string         `json:"currency" binding:"omitempty,len=3"`
+	Title           string         `json:"title" binding:"omitempty,max=200"`
+	Description     string         `json:"description"`
+	Location        string         `json:"location" binding:"omitempty,max=200"`
+	TransactionDate time.Time      `json:"transaction_date"`
+	TransactionTime *time.Time     `json:"transaction_time"`
+	BillID          *int64         `json:"bill_id"`
+	WishlistID      *int64         `json:"wishlist_id"`
+	Tags            []string       `json:"tags"`
+	Images          []string       `json:"images"`
+	Reimbursable    *bool          `json:"reimbursable"`
+	Splits          []SplitRequest `json:"splits" binding:"omitempty,max=20,dive"` // 不传时保持不变，传空数组时取消拆分
 }
 
 // ListTransactionRequest 查询交易列表请求
 type ListTransactionRequest struct {
-	Type           string    `form:"type" binding:"omitempty,oneof=expense income transfer"`
-	CategoryID     *int64    `form:"category_id"`
-	AccountID      *int64    `form:"account_id"`
-	StartDate      time.Time `form:"start_date" binding:"omitempty"`
-	EndDate        time.Time `form:"end_date" binding:"omitempty"`
-	SearchKeyword  string    `form:"search_keyword" binding:"max=100"`
-	SortBy         string    `form:"sort_by" binding:"omitempty,oneof=date amount"`
-	SortOrder      string    `form:"sort_order" binding:"omitempty,oneof=asc desc"`
-	Page           int       `form:"page" binding:"omitempty,min=1"`
-	PageSize       int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Type          string    `form:"type" binding:"omitempty,oneof=expense income transfer"`
+	CategoryID    *int64    `form:"category_id"`
+	AccountID     *int64    `form:"account_id"`
+	StartDate     time.Time `form:"start_date" binding:"omitempty"`
+	EndDate       time.Time `form:"end_date" binding:"omitempty"`
+	SearchKeyword string    `form:"search_keyword" binding:"max=100"`
+	SortBy        string    `form:"sort_by" binding:"omitempty,oneof=date amount"`
+	SortOrder     string    `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+	Page          int       `form:"page" binding:"omitempty,min=1"`
+	PageSize      int       `form:"page_size" binding:"omitempty,min=1,max=100"`
 }
 
 // BulkCreateTransactionRequest 批量创建交易请求
@@ -59,7 +80,18 @@ type BulkCreateTransactionRequest struct {
 	Transactions []CreateTransactionRequest `json:"transactions" binding:"required,min=1,max=100"`
 }
 
+// RecategorizeTransactionRequest 批量修改分类请求，作用于符合交易列表筛选条件的交易
+type RecategorizeTransactionRequest struct {
+	CategoryID int64 `json:"category_id" binding:"required"`
+}
+
 // BulkDeleteTransactionRequest 批量删除交易请求
 type BulkDeleteTransactionRequest struct {
 	IDs []int64 `json:"ids" binding:"required,min=1,max=1000"`
 }
+
+// QuickEntryRequest 快速记账请求，解析一句话生成交易草稿，如“午饭 35 微信 昨天”
+type QuickEntryRequest struct {
+	Text  string     `json:"text" binding:"required,max=200"`
+	Today *time.Time `json:"today"` // 客户端当天日期，用于计算“昨天”“周三”等相对日期，缺省为服务器当天
+}
@@ -0,0 +1,30 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// TransactionRuleRequest 创建或更新交易规则请求（更新时整体替换）
+// 关键词条件不区分大小写，多个关键词用 | 分隔，包含任一即可
+type TransactionRuleRequest struct {
+	Name                string       `json:"name" binding:"required,max=100"`
+	Priority            int          `json:"priority"`  // 数值越小越先执行
+	IsActive            *bool        `json:"is_active"` // 不传时启用
+	TransactionType     string       `json:"transaction_type" binding:"omitempty,oneof=expense income transfer"`
+	TitleContains       string       `json:"title_contains" binding:"max=200"`
+	DescriptionContains string       `json:"description_contains" binding:"max=200"`
+	LocationContains    string       `json:"location_contains" binding:"max=200"`
+	MinAmount           *money.Money `json:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount           *money.Money `json:"max_amount" binding:"omitempty,gt=0"`
+	AccountID           *int64       `json:"account_id"`
+	CategoryID          *int64       `json:"category_id"` // 未指定交易类型时取分类的收支类型
+	AddTags             []string     `json:"add_tags" binding:"max=10,dive,required,max=50"`
+	RenameTitle         string       `json:"rename_title" binding:"max=200"`
+}
+
+// ApplyTransactionRuleRequest 预览或追溯执行交易规则请求
+type ApplyTransactionRuleRequest struct {
+	StartDate time.Time `json:"start_date"` // 只检查该日期及之后的交易，不传时检查最近一年
+}
@@ -0,0 +1,49 @@
+package request
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// CreateWishlistRequest 创建心愿请求
+type CreateWishlistRequest struct {
+	ItemName     string      `json:"item_name" binding:"required,max=200"`
+	TargetAmount money.Money `json:"target_amount" binding:"required,gt=0"`
+	Icon         string      `json:"icon" binding:"max=100"`
+	ImageURL     string      `json:"image_url" binding:"omitempty,url,max=500"`
+	Priority     string      `json:"priority" binding:"omitempty,oneof=low medium high"`
+	Description  string      `json:"description"`
+	URL          string      `json:"url" binding:"omitempty,url,max=500"`
+	TargetDate   *time.Time  `json:"target_date"`
+}
+
+// UpdateWishlistRequest 更新心愿请求
+type UpdateWishlistRequest struct {
+	ItemName     *string      `json:"item_name" binding:"omitempty,max=200"`
+	TargetAmount *money.Money `json:"target_amount" binding:"omitempty,gt=0"`
+	Icon         *string      `json:"icon" binding:"omitempty,max=100"`
+	ImageURL     *string      `json:"image_url" binding:"omitempty,url,max=500"`
+	Priority     *string      `json:"priority" binding:"omitempty,oneof=low medium high"`
+	Status       *string      `json:"status" binding:"omitempty,oneof=planning in_progress cancelled"`
+	Description  *string      `json:"description"`
+	URL          *string      `json:"url" binding:"omitempty,url,max=500"`
+	TargetDate   *time.Time   `json:"target_date"`
+}
+
+// WishlistDepositRequest 心愿存取请求，Withdraw为true时表示取出
+type WishlistDepositRequest struct {
+	Amount      money.Money `json:"amount" binding:"required,gt=0"`
+	Withdraw    bool        `json:"withdraw"`
+	DepositDate *time.Time  `json:"deposit_date"`
+	Notes       string      `json:"notes"`
+}
+
+// PurchaseWishlistRequest 购买心愿请求：生成支出交易并完成心愿
+type PurchaseWishlistRequest struct {
+	AccountID       int64       `json:"account_id" binding:"required"`
+	CategoryID      *int64      `json:"category_id"`
+	Amount          money.Money `json:"amount" binding:"omitempty,gt=0"` // 为空时使用目标金额
+	TransactionDate *time.Time  `json:"transaction_date"`
+	Notes           string      `json:"notes"`
+}
@@ -1,28 +1,112 @@
 package response
 
-import "time"
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
 
 // AccountResponse 账户响应
 type AccountResponse struct {
-	ID             int64     `json:"id"`
-	UserID         int64     `json:"user_id"`
-	AccountType    string    `json:"account_type"`
-	AccountName    string    `json:"account_name"`
-	AccountNumber  string    `json:"account_number"`
-	Icon           string    `json:"icon"`
-	Color          string    `json:"color"`
-	Balance        float64   `json:"balance"`
-	InitialBalance float64   `json:"initial_balance"`
-	IncludeInTotal bool      `json:"include_in_total"`
-	DisplayOrder   int       `json:"display_order"`
-	IsActive       bool      `json:"is_active"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-}
-
-// AccountBalanceResponse 账户余额汇总响应
+	ID              int64        `json:"id"`
+	UserID          int64        `json:"user_id"`
+	AccountType     string       `json:"account_type"`
+	AccountName     string       `json:"account_name"`
+	AccountNumber   string       `json:"account_number"`
+	Icon            string       `json:"icon"`
+	Color           string       `json:"color"`
+	Balance         money.Money  `json:"balance"`
+	InitialBalance  money.Money  `json:"initial_balance"`
+	Currency        string       `json:"currency"`
+	BaseBalance     *money.Money `json:"base_balance,omitempty"` // 按当前汇率折算的本位币余额，缺少汇率时不返回
+	CreditLimit     money.Money  `json:"credit_limit"`
+	BillingDay      *int         `json:"billing_day"`
+	PaymentDueDay   *int         `json:"payment_due_day"`
+	AvailableCredit *money.Money `json:"available_credit,omitempty"` // 可用额度，仅信用账户返回
+	IncludeInTotal  bool         `json:"include_in_total"`
+	DisplayOrder    int          `json:"display_order"`
+	IsActive        bool         `json:"is_active"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// AccountBalanceResponse 账户余额汇总响应，各账户余额按当前汇率折算为用户本位币后汇总
 type AccountBalanceResponse struct {
-	TotalBalance float64 `json:"total_balance"`
-	AssetBalance float64 `json:"asset_balance"` // 净资产（不含负债）
-	DebtBalance  float64 `json:"debt_balance"`  // 负债总额
+	Currency     string      `json:"currency"`      // 用户本位币
+	TotalBalance money.Money `json:"total_balance"` // 净资产，即资产总额减负债总额
+	AssetBalance money.Money `json:"asset_balance"` // 资产总额（余额为正的账户）
+	DebtBalance  money.Money `json:"debt_balance"`  // 负债总额（余额为负的账户，如信用卡欠款）
+}
+
+// BalanceHistoryResponse 净资产与账户余额走势，每个时间点取该日、该周（周日）或该月最后一天的日终余额
+type BalanceHistoryResponse struct {
+	Currency    string                  `json:"currency"` // 用户本位币
+	Granularity string                  `json:"granularity"`
+	StartDate   time.Time               `json:"start_date"`
+	EndDate     time.Time               `json:"end_date"`
+	NetWorth    []*NetWorthPoint        `json:"net_worth"`
+	Accounts    []*AccountBalanceSeries `json:"accounts"`
+}
+
+// NetWorthPoint 净资产走势的一个时间点，账户余额按该日汇率折算为本位币，负债含贷款/分期的剩余本金
+type NetWorthPoint struct {
+	Date        time.Time   `json:"date"`
+	TotalAssets money.Money `json:"total_assets"`
+	TotalDebt   money.Money `json:"total_debt"`
+	NetWorth    money.Money `json:"net_worth"`
+}
+
+// AccountBalanceSeries 单个账户的余额走势
+type AccountBalanceSeries struct {
+	AccountID      int64                  `json:"account_id"`
+	AccountName    string                 `json:"account_name"`
+	AccountType    string                 `json:"account_type"`
+	Currency       string                 `json:"currency"`
+	IncludeInTotal bool                   `json:"include_in_total"`
+	Points         []*AccountBalancePoint `json:"points"`
+}
+
+// AccountBalancePoint 账户余额走势的一个时间点，账户开立前余额为0
+type AccountBalancePoint struct {
+	Date        time.Time   `json:"date"`
+	Balance     money.Money `json:"balance"`      // 账户币种
+	BaseBalance money.Money `json:"base_balance"` // 按该日汇率折算的本位币余额
+}
+
+// ReconcilePreviewResponse 对账预览：账面余额与实际余额的差额及尚未对账的交易
+type ReconcilePreviewResponse struct {
+	AccountID          int64                              `json:"account_id"`
+	Currency           string                             `json:"currency"`
+	ReconcileDate      time.Time                          `json:"reconcile_date"`
+	StatementBalance   money.Money                        `json:"statement_balance"`
+	LedgerBalance      money.Money                        `json:"ledger_balance"` // 截至对账日的账面余额
+	Difference         money.Money                        `json:"difference"`     // 实际余额 - 账面余额
+	LastReconciledDate *time.Time                         `json:"last_reconciled_date"`
+	Transactions       []*UnreconciledTransactionResponse `json:"transactions"`
+}
+
+// UnreconciledTransactionResponse 尚未对账的交易
+type UnreconciledTransactionResponse struct {
+	ID              int64       `json:"id"`
+	Type            string      `json:"type"`
+	Title           string      `json:"title"`
+	CategoryID      *int64      `json:"category_id"`
+	Amount          money.Money `json:"amount"`
+	Effect          money.Money `json:"effect"` // 对该账户余额的影响，转出和支出为负数
+	TransactionDate time.Time   `json:"transaction_date"`
+}
+
+// ReconciliationResponse 对账记录
+type ReconciliationResponse struct {
+	ID                      int64       `json:"id"`
+	AccountID               int64       `json:"account_id"`
+	ReconcileDate           time.Time   `json:"reconcile_date"`
+	StatementBalance        money.Money `json:"statement_balance"`
+	LedgerBalance           money.Money `json:"ledger_balance"`
+	Difference              money.Money `json:"difference"`
+	Currency                string      `json:"currency"`
+	AdjustmentTransactionID *int64      `json:"adjustment_transaction_id"`
+	TransactionCount        int         `json:"transaction_count"`
+	Notes                   string      `json:"notes"`
+	CreatedAt               time.Time   `json:"created_at"`
 }
@@ -0,0 +1,57 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// BillResponse 账单响应
+type BillResponse struct {
+	ID               int64             `json:"id"`
+	BillName         string            `json:"bill_name"`
+	CategoryID       *int64            `json:"category_id"`
+	Category         *CategoryResponse `json:"category,omitempty"`
+	AccountID        *int64            `json:"account_id"`
+	Account          *AccountResponse  `json:"account,omitempty"`
+	Amount           money.Money       `json:"amount"`
+	Currency         string            `json:"currency"`
+	BillingCycle     string            `json:"billing_cycle"`
+	BillingDay       *int              `json:"billing_day"`
+	IntervalDays     int               `json:"interval_days"`
+	NextBillingDate  time.Time         `json:"next_billing_date"`
+	DaysUntilDue     int               `json:"days_until_due"` // 负数表示已逾期
+	AutoRenew        bool              `json:"auto_renew"`
+	IsActive         bool              `json:"is_active"`
+	Icon             string            `json:"icon"`
+	Color            string            `json:"color"`
+	Description      string            `json:"description"`
+	RemindDaysBefore int               `json:"remind_days_before"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// BillHistoryResponse 账单历史响应
+type BillHistoryResponse struct {
+	ID            int64       `json:"id"`
+	BillID        int64       `json:"bill_id"`
+	TransactionID *int64      `json:"transaction_id"`
+	Amount        money.Money `json:"amount"`
+	BillingDate   time.Time   `json:"billing_date"`
+	Status        string      `json:"status"`
+	Notes         string      `json:"notes"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// BillDetailResponse 账单详情响应（含历史记录）
+type BillDetailResponse struct {
+	*BillResponse
+	History []*BillHistoryResponse `json:"history"`
+}
+
+// PayBillResponse 账单支付响应
+type PayBillResponse struct {
+	Transaction *TransactionResponse `json:"transaction"`
+	History     *BillHistoryResponse `json:"history"`
+	Bill        *BillResponse        `json:"bill"`
+}
@@ -0,0 +1,33 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// BudgetResponse 预算响应（含当前周期执行情况）
+type BudgetResponse struct {
+	ID              int64             `json:"id"`
+	Name            string            `json:"name"`
+	CategoryID      *int64            `json:"category_id"`
+	Category        *CategoryResponse `json:"category,omitempty"`
+	BudgetAmount    money.Money       `json:"budget_amount"`
+	PeriodType      string            `json:"period_type"`
+	StartDate       time.Time         `json:"start_date"`
+	EndDate         *time.Time        `json:"end_date"`
+	Rollover        bool              `json:"rollover"`
+	AlertThreshold  float64           `json:"alert_threshold"`
+	IsActive        bool              `json:"is_active"`
+	PeriodStart     time.Time         `json:"period_start"`
+	PeriodEnd       time.Time         `json:"period_end"`
+	RolloverAmount  money.Money       `json:"rollover_amount"`  // 上期结转额度
+	AvailableAmount money.Money       `json:"available_amount"` // 本期可用 = 预算 + 结转
+	SpentAmount     money.Money       `json:"spent_amount"`
+	RemainingAmount money.Money       `json:"remaining_amount"` // 负数表示已超支
+	ProjectedAmount money.Money       `json:"projected_amount"` // 按当前速度预计本期总支出
+	UsagePercent    float64           `json:"usage_percent"`
+	Status          string            `json:"status"` // normal/warning/exceeded
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
@@ -6,6 +6,7 @@ import "time"
 type CategoryResponse struct {
 	ID           int64     `json:"id"`
 	UserID       int64     `json:"user_id"`
+	ParentID     *int64    `json:"parent_id"`
 	Type         string    `json:"type"`
 	Name         string    `json:"name"`
 	Icon         string    `json:"icon"`
@@ -15,4 +16,24 @@ type CategoryResponse struct {
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	Children []*CategoryResponse `json:"children,omitempty"` // 下级分类，分类列表按树形返回
+}
+
+// MergeCategoriesResponse 合并分类响应
+type MergeCategoriesResponse struct {
+	Target            *CategoryResponse `json:"target"`
+	MergedCount       int               `json:"merged_count"`       // 停用的源分类数
+	MovedTransactions int64             `json:"moved_transactions"` // 移到目标分类的交易笔数
+}
+
+// CategorySuggestionResponse 分类建议
+type CategorySuggestionResponse struct {
+	CategoryID int64   `json:"category_id"`
+	ParentID   *int64  `json:"parent_id"`
+	Type       string  `json:"type"`
+	Name       string  `json:"name"`
+	Icon       string  `json:"icon"`
+	Color      string  `json:"color"`
+	Confidence float64 `json:"confidence"` // 按历史记录估计的可能性（0~1）
 }
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// CreditAccountResponse 信用账户概览
+type CreditAccountResponse struct {
+	Account           *AccountResponse         `json:"account"`
+	CreditLimit       money.Money              `json:"credit_limit"`
+	CurrentDebt       money.Money              `json:"current_debt"`     // 当前欠款
+	AvailableCredit   money.Money              `json:"available_credit"` // 可用额度，超额使用时为0
+	UtilizationRate   float64                  `json:"utilization_rate"` // 额度使用率百分比，未设置额度时为0
+	NextStatementDate *time.Time               `json:"next_statement_date,omitempty"`
+	NextDueDate       *time.Time               `json:"next_due_date,omitempty"` // 最近一期未还清账单的还款日
+	LatestStatement   *CreditStatementResponse `json:"latest_statement,omitempty"`
+}
+
+// CreditStatementResponse 信用账单响应
+type CreditStatementResponse struct {
+	ID               int64       `json:"id"`
+	AccountID        int64       `json:"account_id"`
+	PeriodStart      time.Time   `json:"period_start"`
+	StatementDate    time.Time   `json:"statement_date"`
+	DueDate          time.Time   `json:"due_date"`
+	OpeningBalance   money.Money `json:"opening_balance"`
+	NewCharges       money.Money `json:"new_charges"`
+	Credits          money.Money `json:"credits"`
+	StatementAmount  money.Money `json:"statement_amount"`
+	MinimumPayment   money.Money `json:"minimum_payment"`
+	PaidAmount       money.Money `json:"paid_amount"`
+	RemainingAmount  money.Money `json:"remaining_amount"`
+	Currency         string      `json:"currency"`
+	TransactionCount int         `json:"transaction_count"`
+	Status           string      `json:"status"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+// RepayCreditResponse 信用账户还款结果
+type RepayCreditResponse struct {
+	Transaction *TransactionResponse   `json:"transaction"`
+	Account     *CreditAccountResponse `json:"account"`
+}
@@ -0,0 +1,28 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// ExchangeRateResponse 汇率响应
+type ExchangeRateResponse struct {
+	ID           int64      `json:"id"`
+	FromCurrency string     `json:"from_currency"`
+	ToCurrency   string     `json:"to_currency"`
+	Rate         money.Rate `json:"rate"`
+	RateDate     time.Time  `json:"rate_date"`
+	Source       string     `json:"source"`
+	Editable     bool       `json:"editable"` // 本人手工录入的汇率可修改和删除，同步的公共汇率只读
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ConvertCurrencyResponse 币种换算结果
+type ConvertCurrencyResponse struct {
+	Amount       money.Money `json:"amount"`
+	FromCurrency string      `json:"from_currency"`
+	ToCurrency   string      `json:"to_currency"`
+	Rate         money.Rate  `json:"rate"`
+	Converted    money.Money `json:"converted"`
+}
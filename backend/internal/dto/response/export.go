@@ -0,0 +1,24 @@
+package response
+
+import "time"
+
+// ExportJobResponse 导出任务响应
+type ExportJobResponse struct {
+	ID            int64      `json:"id"`
+	ExportType    string     `json:"export_type"`
+	StartDate     *time.Time `json:"start_date"`
+	EndDate       *time.Time `json:"end_date"`
+	Type          string     `json:"type"`
+	CategoryID    *int64     `json:"category_id"`
+	AccountID     *int64     `json:"account_id"`
+	SearchKeyword string     `json:"search_keyword"`
+	Status        string     `json:"status"`
+	FileName      string     `json:"file_name"`
+	FileSize      int64      `json:"file_size"`
+	RowCount      int        `json:"row_count"`
+	DownloadURL   string     `json:"download_url"` // 任务完成且未过期时返回，无需登录即可下载
+	ErrorMessage  string     `json:"error_message"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+}
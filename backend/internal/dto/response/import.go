@@ -0,0 +1,76 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// ImportProfileResponse 列映射配置响应
+type ImportProfileResponse struct {
+	ID                int64             `json:"id"`
+	Name              string            `json:"name"`
+	Delimiter         string            `json:"delimiter"`
+	HeaderRow         int               `json:"header_row"`
+	Columns           map[string]string `json:"columns"`
+	DateFormat        string            `json:"date_format"`
+	TypeValues        map[string]string `json:"type_values"`
+	DefaultType       string            `json:"default_type"`
+	DefaultAccountID  *int64            `json:"default_account_id"`
+	DefaultCategoryID *int64            `json:"default_category_id"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+// ImportBatchResponse 导入批次响应
+type ImportBatchResponse struct {
+	ID           int64    `json:"id"`
+	ProfileID    *int64   `json:"profile_id"`
+	FileName     string   `json:"file_name"`
+	FileFormat   string   `json:"file_format"`
+	Source       string   `json:"source"`
+	Status       string   `json:"status"`
+	TotalRows    int      `json:"total_rows"`
+	ValidRows    int      `json:"valid_rows"`
+	ImportedRows int      `json:"imported_rows"`
+	FailedRows   int      `json:"failed_rows"`
+	SkippedRows  int      `json:"skipped_rows"`
+	Errors       []string `json:"errors"`
+
+	// 银行对账单导入：目标账户及期末余额核对结果
+	AccountID         *int64       `json:"account_id"`
+	ClosingBalance    *money.Money `json:"closing_balance"`
+	BalanceDate       *time.Time   `json:"balance_date"`
+	BalanceDifference *money.Money `json:"balance_difference"` // 非0表示账户余额与对账单不一致
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// ImportPreviewRow 预览中的一行，Valid 为 false 的行提交时不会导入
+type ImportPreviewRow struct {
+	Line            int         `json:"line"`
+	Valid           bool        `json:"valid"`
+	Skipped         bool        `json:"skipped"`     // 无需导入的行（已退款、已关闭或订单号已导入）
+	SkipReason      string      `json:"skip_reason"` // 跳过原因
+	ExternalID      string      `json:"external_id"`
+	Type            string      `json:"type"`
+	TransactionDate *time.Time  `json:"transaction_date"`
+	Amount          money.Money `json:"amount"`
+	Currency        string      `json:"currency"`
+	AccountID       *int64      `json:"account_id"`
+	ToAccountID     *int64      `json:"to_account_id"`
+	CategoryID      *int64      `json:"category_id"`
+	Title           string      `json:"title"`
+	Description     string      `json:"description"`
+	Tags            []string    `json:"tags"`
+	Errors          []string    `json:"errors"`
+	Warnings        []string    `json:"warnings"`
+}
+
+// ImportPreviewResponse 导入预览响应
+type ImportPreviewResponse struct {
+	Batch *ImportBatchResponse `json:"batch"`
+	Rows  []*ImportPreviewRow  `json:"rows"`
+}
@@ -0,0 +1,73 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// LoanResponse 贷款/分期响应
+type LoanResponse struct {
+	ID                   int64        `json:"id"`
+	Name                 string       `json:"name"`
+	LoanType             string       `json:"loan_type"`
+	AccountID            int64        `json:"account_id"`
+	CategoryID           *int64       `json:"category_id"`
+	Principal            money.Money  `json:"principal"`
+	AnnualRate           float64      `json:"annual_rate"`
+	RepaymentMethod      string       `json:"repayment_method"`
+	Term                 int          `json:"term"`
+	Currency             string       `json:"currency"`
+	FirstDueDate         time.Time    `json:"first_due_date"`
+	PaidPeriods          int          `json:"paid_periods"`
+	OutstandingPrincipal money.Money  `json:"outstanding_principal"`
+	NextDueDate          *time.Time   `json:"next_due_date"`
+	NextAmount           *money.Money `json:"next_amount,omitempty"` // 下期应还，需查询还款计划时返回
+	Status               string       `json:"status"`
+	Notes                string       `json:"notes"`
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at"`
+}
+
+// LoanInstallmentResponse 还款计划单期响应
+type LoanInstallmentResponse struct {
+	Period             int         `json:"period"`
+	DueDate            time.Time   `json:"due_date"`
+	Principal          money.Money `json:"principal"`
+	Interest           money.Money `json:"interest"`
+	Amount             money.Money `json:"amount"`
+	RemainingPrincipal money.Money `json:"remaining_principal"`
+	Status             string      `json:"status"`
+	TransactionID      *int64      `json:"transaction_id"`
+	PaidAt             *time.Time  `json:"paid_at"`
+}
+
+// LoanScheduleResponse 还款计划试算结果
+type LoanScheduleResponse struct {
+	TotalAmount   money.Money                `json:"total_amount"`   // 还款总额
+	TotalInterest money.Money                `json:"total_interest"` // 利息总额
+	Installments  []*LoanInstallmentResponse `json:"installments"`
+}
+
+// LoanDetailResponse 贷款详情响应（含还款计划）
+type LoanDetailResponse struct {
+	*LoanResponse
+	Schedule *LoanScheduleResponse `json:"schedule"`
+}
+
+// ProjectedTransactionResponse 尚未入账的预计交易（由贷款还款计划生成），到期后自动记账
+type ProjectedTransactionResponse struct {
+	LoanID          int64       `json:"loan_id"`
+	LoanName        string      `json:"loan_name"`
+	Period          int         `json:"period"`
+	Term            int         `json:"term"`
+	Type            string      `json:"type"`
+	AccountID       int64       `json:"account_id"`
+	CategoryID      *int64      `json:"category_id"`
+	Title           string      `json:"title"`
+	Amount          money.Money `json:"amount"`
+	Principal       money.Money `json:"principal"`
+	Interest        money.Money `json:"interest"`
+	Currency        string      `json:"currency"`
+	TransactionDate time.Time   `json:"transaction_date"`
+}
@@ -0,0 +1,31 @@
+package response
+
+import "time"
+
+// NotificationResponse 通知响应
+type NotificationResponse struct {
+	ID          int64      `json:"id"`
+	Type        string     `json:"type"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	RelatedType string     `json:"related_type"`
+	RelatedID   *int64     `json:"related_id"`
+	IsRead      bool       `json:"is_read"`
+	Priority    string     `json:"priority"`
+	ReadAt      *time.Time `json:"read_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// NotificationListResponse 通知列表响应
+type NotificationListResponse struct {
+	Total       int64                   `json:"total"`
+	UnreadCount int64                   `json:"unread_count"`
+	Page        int                     `json:"page"`
+	PageSize    int                     `json:"page_size"`
+	Items       []*NotificationResponse `json:"items"`
+}
+
+// UnreadCountResponse 未读数量响应
+type UnreadCountResponse struct {
+	Count int64 `json:"count"`
+}
@@ -0,0 +1,33 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// RecurringRuleResponse 周期规则响应
+type RecurringRuleResponse struct {
+	ID              int64       `json:"id"`
+	Name            string      `json:"name"`
+	Type            string      `json:"type"`
+	CategoryID      *int64      `json:"category_id"`
+	AccountID       *int64      `json:"account_id"`
+	ToAccountID     *int64      `json:"to_account_id"`
+	Amount          money.Money `json:"amount"`
+	Currency        string      `json:"currency"`
+	Title           string      `json:"title"`
+	Description     string      `json:"description"`
+	Frequency       string      `json:"frequency"`
+	Interval        int         `json:"interval"`
+	ByMonthDay      *int        `json:"by_month_day"`
+	StartDate       time.Time   `json:"start_date"`
+	EndDate         *time.Time  `json:"end_date"`
+	MaxOccurrences  *int        `json:"max_occurrences"`
+	OccurrenceCount int         `json:"occurrence_count"`
+	NextRunDate     *time.Time  `json:"next_run_date"`
+	LastRunDate     *time.Time  `json:"last_run_date"`
+	IsActive        bool        `json:"is_active"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
@@ -0,0 +1,53 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// SavingsPlanResponse 储蓄计划响应（含进度）
+type SavingsPlanResponse struct {
+	ID                  int64            `json:"id"`
+	PlanName            string           `json:"plan_name"`
+	PlanType            string           `json:"plan_type"`
+	TargetAmount        money.Money      `json:"target_amount"`
+	CurrentAmount       money.Money      `json:"current_amount"`
+	RemainingAmount     money.Money      `json:"remaining_amount"`
+	Progress            float64          `json:"progress"` // 完成百分比，0-100
+	StartDate           time.Time        `json:"start_date"`
+	TargetDate          *time.Time       `json:"target_date"`
+	DaysLeft            *int             `json:"days_left"`            // 距目标日期天数，负数表示已逾期
+	MonthlyContribution *money.Money     `json:"monthly_contribution"` // 按期达成每月需存金额，未设目标日期时为空
+	Frequency           *string          `json:"frequency"`
+	PeriodicAmount      *money.Money     `json:"periodic_amount"`
+	AccountID           *int64           `json:"account_id"`
+	Account             *AccountResponse `json:"account,omitempty"`
+	Icon                string           `json:"icon"`
+	Color               string           `json:"color"`
+	Description         string           `json:"description"`
+	Status              string           `json:"status"`
+	CreatedAt           time.Time        `json:"created_at"`
+	UpdatedAt           time.Time        `json:"updated_at"`
+	CompletedAt         *time.Time       `json:"completed_at"`
+}
+
+// SavingsRecordResponse 储蓄存取记录响应
+type SavingsRecordResponse struct {
+	ID            int64       `json:"id"`
+	PlanID        int64       `json:"plan_id"`
+	TransactionID *int64      `json:"transaction_id"`
+	RecordType    string      `json:"record_type"`
+	Amount        money.Money `json:"amount"`
+	DepositDate   time.Time   `json:"deposit_date"`
+	PeriodNumber  *int        `json:"period_number"`
+	Notes         string      `json:"notes"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// SavingsOperationResponse 存取款响应
+type SavingsOperationResponse struct {
+	Transaction *TransactionResponse   `json:"transaction"`
+	Record      *SavingsRecordResponse `json:"record"`
+	Plan        *SavingsPlanResponse   `json:"plan"`
+}
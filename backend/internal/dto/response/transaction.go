@@ -1,77 +1,142 @@
 package response
 
-import "time"
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
 
 // TransactionResponse 交易响应
 type TransactionResponse struct {
-	ID              int64                 `json:"id"`
-	UserID          int64                 `json:"user_id"`
-	Type            string                `json:"type"`
-	CategoryID      *int64                `json:"category_id"`
-	Category        *CategoryResponse     `json:"category,omitempty"`
-	AccountID       *int64                `json:"account_id"`
-	Account         *AccountResponse      `json:"account,omitempty"`
-	ToAccountID     *int64                `json:"to_account_id"`
-	ToAccount       *AccountResponse      `json:"to_account,omitempty"`
-	Amount          float64               `json:"amount"`
-	Currency        string                `json:"currency"`
-	Title           string                `json:"title"`
-	Description     string                `json:"description"`
-	Location        string                `json:"location"`
-	TransactionDate time.Time             `json:"transaction_date"`
-	TransactionTime *time.Time            `json:"transaction_time"`
-	BillID          *int64                `json:"bill_id"`
-	WishlistID      *int64                `json:"wishlist_id"`
-	Tags            []string              `json:"tags"`
-	Images          []string              `json:"images"`
-	CreatedAt       time.Time             `json:"created_at"`
-	UpdatedAt       time.Time             `json:"updated_at"`
+	ID              int64             `json:"id"`
+	UserID          int64             `json:"user_id"`
+	Type            string            `json:"type"`
+	CategoryID      *int64            `json:"category_id"`
+	Category        *CategoryResponse `json:"category,omitempty"`
+	AccountID       *int64            `json:"account_id"`
+	Account         *AccountResponse  `json:"account,omitempty"`
+	ToAccountID     *int64            `json:"to_account_id"`
+	ToAccount       *AccountResponse  `json:"to_account,omitempty"`
+	Amount          money.Money       `json:"amount"`
+	Currency        string            `json:"currency"`
+	ExchangeRate    money.Rate        `json:"exchange_rate"` // 记账时兑用户本位币的汇率
+	BaseAmount      money.Money       `json:"base_amount"`   // 按记账汇率折算的本位币金额
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	Location        string            `json:"location"`
+	TransactionDate time.Time         `json:"transaction_date"`
+	TransactionTime *time.Time        `json:"transaction_time"`
+	BillID          *int64            `json:"bill_id"`
+	WishlistID      *int64            `json:"wishlist_id"`
+	RecurringRuleID *int64            `json:"recurring_rule_id"`
+	ImportBatchID   *int64            `json:"import_batch_id"`
+	ExternalID      string            `json:"external_id"`
+	RefundOfID      *int64            `json:"refund_of_id"`
+	Reimbursable    bool              `json:"reimbursable"`
+	Reconciled      bool              `json:"reconciled"` // 已对账的交易不能修改金额、账户、类型和日期
+	Tags            []string          `json:"tags"`
+	Images          []string          `json:"images"`
+	Splits          []*SplitResponse  `json:"splits,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// SplitResponse 交易拆分明细响应
+type SplitResponse struct {
+	ID         int64             `json:"id"`
+	CategoryID *int64            `json:"category_id"`
+	Category   *CategoryResponse `json:"category,omitempty"`
+	Amount     money.Money       `json:"amount"`
+	Note       string            `json:"note"`
+}
+
+// PendingReimbursementResponse 待报销支出
+type PendingReimbursementResponse struct {
+	Transaction      *TransactionResponse `json:"transaction"`
+	ReimbursedAmount money.Money          `json:"reimbursed_amount"` // 已报销到账金额
+	PendingAmount    money.Money          `json:"pending_amount"`    // 待报销金额
+}
+
+// ReimbursementReportResponse 待报销报告
+type ReimbursementReportResponse struct {
+	TotalPending money.Money                     `json:"total_pending"`
+	Count        int                             `json:"count"`
+	Items        []*PendingReimbursementResponse `json:"items"`
 }
 
 // TransactionListResponse 交易列表响应
 type TransactionListResponse struct {
-	Total    int64                   `json:"total"`
-	Page     int                     `json:"page"`
-	PageSize int                     `json:"page_size"`
-	Items    []*TransactionResponse  `json:"items"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+	Items    []*TransactionResponse `json:"items"`
 }
 
 // TransactionStatisticsResponse 交易统计响应
 type TransactionStatisticsResponse struct {
-	TotalIncome    float64 `json:"total_income"`
-	TotalExpense   float64 `json:"total_expense"`
-	NetAmount      float64 `json:"net_amount"`
-	TransactionCnt int64   `json:"transaction_cnt"`
+	TotalIncome    money.Money `json:"total_income"`
+	TotalExpense   money.Money `json:"total_expense"`
+	NetAmount      money.Money `json:"net_amount"`
+	TransactionCnt int64       `json:"transaction_cnt"`
 }
 
 // MonthlyStatisticsResponse 月度统计响应
 type MonthlyStatisticsResponse struct {
-	Month          string  `json:"month"`
-	TotalIncome    float64 `json:"total_income"`
-	TotalExpense   float64 `json:"total_expense"`
-	NetAmount      float64 `json:"net_amount"`
-	TransactionCnt int64   `json:"transaction_cnt"`
+	Month          string      `json:"month"`
+	TotalIncome    money.Money `json:"total_income"`
+	TotalExpense   money.Money `json:"total_expense"`
+	NetAmount      money.Money `json:"net_amount"`
+	TransactionCnt int64       `json:"transaction_cnt"`
 }
 
 // CategoryStatisticsResponse 分类统计响应
 type CategoryStatisticsResponse struct {
-	CategoryID   int64                 `json:"category_id"`
-	Category     *CategoryResponse     `json:"category"`
-	TotalAmount  float64               `json:"total_amount"`
-	Percentage   float64               `json:"percentage"`
-	TransactionCnt int64               `json:"transaction_cnt"`
+	CategoryID     int64             `json:"category_id"`
+	Category       *CategoryResponse `json:"category"`
+	TotalAmount    money.Money       `json:"total_amount"`
+	Percentage     float64           `json:"percentage"`
+	TransactionCnt int64             `json:"transaction_cnt"`
 }
 
 // TransactionDetailsResponse 交易详情响应
 type TransactionDetailsResponse struct {
-	Transaction    *TransactionResponse           `json:"transaction"`
-	RelatedBill    interface{}                    `json:"related_bill,omitempty"`
-	RelatedWishlist interface{}                   `json:"related_wishlist,omitempty"`
+	Transaction     *TransactionResponse `json:"transaction"`
+	RelatedBill     interface{}          `json:"related_bill,omitempty"`
+	RelatedWishlist interface{}          `json:"related_wishlist,omitempty"`
 }
 
 // BulkOperationResponse 批量操作响应
 type BulkOperationResponse struct {
-	SuccessCount int64  `json:"success_count"`
-	FailureCount int64  `json:"failure_count"`
+	SuccessCount int64    `json:"success_count"`
+	FailureCount int64    `json:"failure_count"`
 	Errors       []string `json:"errors,omitempty"`
 }
+
+// QuickEntryResponse 快速记账解析结果，字段与创建交易请求一致，确认后可直接提交创建
+type QuickEntryResponse struct {
+	Type            string      `json:"type"`
+	CategoryID      *int64      `json:"category_id"`
+	AccountID       *int64      `json:"account_id"`
+	ToAccountID     *int64      `json:"to_account_id"`
+	Amount          money.Money `json:"amount"`
+	Title           string      `json:"title"`
+	TransactionDate time.Time   `json:"transaction_date"`
+
+	CategoryName  string               `json:"category_name"`
+	AccountName   string               `json:"account_name"`
+	ToAccountName string               `json:"to_account_name"`
+	Confidence    QuickEntryConfidence `json:"confidence"`
+	Complete      bool                 `json:"complete"` // 必填字段是否都已识别，可直接提交创建
+	Warnings      []string             `json:"warnings"` // 未识别或有歧义的部分
+}
+
+// QuickEntryConfidence 各字段的置信度（0~1），未识别的字段为 0
+type QuickEntryConfidence struct {
+	Overall   float64 `json:"overall"` // 必填字段中的最低值
+	Type      float64 `json:"type"`
+	Amount    float64 `json:"amount"`
+	Date      float64 `json:"date"`
+	Account   float64 `json:"account"`
+	ToAccount float64 `json:"to_account"`
+	Category  float64 `json:"category"`
+}
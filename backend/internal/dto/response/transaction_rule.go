@@ -0,0 +1,51 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// TransactionRuleResponse 交易规则响应
+type TransactionRuleResponse struct {
+	ID                  int64        `json:"id"`
+	Name                string       `json:"name"`
+	Priority            int          `json:"priority"`
+	IsActive            bool         `json:"is_active"`
+	TransactionType     string       `json:"transaction_type"`
+	TitleContains       string       `json:"title_contains"`
+	DescriptionContains string       `json:"description_contains"`
+	LocationContains    string       `json:"location_contains"`
+	MinAmount           *money.Money `json:"min_amount"`
+	MaxAmount           *money.Money `json:"max_amount"`
+	AccountID           *int64       `json:"account_id"`
+	CategoryID          *int64       `json:"category_id"`
+	AddTags             []string     `json:"add_tags"`
+	RenameTitle         string       `json:"rename_title"`
+	CreatedAt           time.Time    `json:"created_at"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+}
+
+// RuleChangeResponse 规则对一笔已有交易的修改
+type RuleChangeResponse struct {
+	TransactionID   int64       `json:"transaction_id"`
+	TransactionDate time.Time   `json:"transaction_date"`
+	Type            string      `json:"type"`
+	Amount          money.Money `json:"amount"`
+	Currency        string      `json:"currency"`
+	Title           string      `json:"title"`           // 修改前的标题
+	CategoryID      *int64      `json:"category_id"`     // 修改前的分类
+	NewCategoryID   *int64      `json:"new_category_id"` // 为空表示分类不变
+	NewTitle        string      `json:"new_title"`       // 为空表示标题不变
+	AddedTags       []string    `json:"added_tags"`
+}
+
+// RuleApplyResponse 交易规则预览或追溯执行结果
+type RuleApplyResponse struct {
+	RuleID       int64                 `json:"rule_id"`
+	Scanned      int                   `json:"scanned"`       // 检查的交易笔数
+	Truncated    bool                  `json:"truncated"`     // 交易笔数超出检查上限，更早的交易未检查，可缩短起始日期后分段执行
+	Matched      int                   `json:"matched"`       // 满足规则条件的笔数
+	ChangedCount int                   `json:"changed_count"` // 需要修改（预览）或已修改（执行）的笔数
+	Changes      []*RuleChangeResponse `json:"changes"`       // 修改明细，最多返回前200笔
+}
@@ -1,6 +1,10 @@
 package response
 
-import "time"
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
 
 // AuthResponse 认证响应
 type AuthResponse struct {
@@ -40,18 +44,18 @@ type UserResponse struct {
 
 // UserStatsResponse 用户统计响应
 type UserStatsResponse struct {
-	TotalAssets     float64 `json:"total_assets"`
-	TotalDebt       float64 `json:"total_debt"`
-	NetWorth        float64 `json:"net_worth"`
-	TotalRecords    int     `json:"total_records"`
-	ContinuousDays  int     `json:"continuous_days"`
-	MonthIncome     float64 `json:"month_income"`
-	MonthExpense    float64 `json:"month_expense"`
-	MonthNet        float64 `json:"month_net"`
-	ActiveBudgets   int     `json:"active_budgets"`
-	ActiveBills     int     `json:"active_bills"`
-	ActiveSavings   int     `json:"active_savings"`
-	ActiveWishlists int     `json:"active_wishlists"`
+	TotalAssets     money.Money `json:"total_assets"`
+	TotalDebt       money.Money `json:"total_debt"`
+	NetWorth        money.Money `json:"net_worth"`
+	TotalRecords    int         `json:"total_records"`
+	ContinuousDays  int         `json:"continuous_days"`
+	MonthIncome     money.Money `json:"month_income"`
+	MonthExpense    money.Money `json:"month_expense"`
+	MonthNet        money.Money `json:"month_net"`
+	ActiveBudgets   int         `json:"active_budgets"`
+	ActiveBills     int         `json:"active_bills"`
+	ActiveSavings   int         `json:"active_savings"`
+	ActiveWishlists int         `json:"active_wishlists"`
 }
 
 // TokenResponse Token响应
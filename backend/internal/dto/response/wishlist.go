@@ -0,0 +1,51 @@
+package response
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// WishlistResponse 心愿响应（含进度）
+type WishlistResponse struct {
+	ID              int64       `json:"id"`
+	ItemName        string      `json:"item_name"`
+	TargetAmount    money.Money `json:"target_amount"`
+	CurrentAmount   money.Money `json:"current_amount"`
+	RemainingAmount money.Money `json:"remaining_amount"`
+	Progress        float64     `json:"progress"` // 完成百分比，0-100
+	Icon            string      `json:"icon"`
+	ImageURL        string      `json:"image_url"`
+	Priority        string      `json:"priority"`
+	Status          string      `json:"status"`
+	Description     string      `json:"description"`
+	URL             string      `json:"url"`
+	TargetDate      *time.Time  `json:"target_date"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	CompletedAt     *time.Time  `json:"completed_at"`
+}
+
+// WishlistDepositResponse 心愿存款记录响应
+type WishlistDepositResponse struct {
+	ID            int64       `json:"id"`
+	WishlistID    int64       `json:"wishlist_id"`
+	TransactionID *int64      `json:"transaction_id"`
+	RecordType    string      `json:"record_type"`
+	Amount        money.Money `json:"amount"`
+	DepositDate   time.Time   `json:"deposit_date"`
+	Notes         string      `json:"notes"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// WishlistDepositResultResponse 心愿存取响应
+type WishlistDepositResultResponse struct {
+	Deposit  *WishlistDepositResponse `json:"deposit"`
+	Wishlist *WishlistResponse        `json:"wishlist"`
+}
+
+// PurchaseWishlistResponse 购买心愿响应
+type PurchaseWishlistResponse struct {
+	Transaction *TransactionResponse `json:"transaction"`
+	Wishlist    *WishlistResponse    `json:"wishlist"`
+}
@@ -0,0 +1,90 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// FileProvider 读取本地 JSON 汇率文件的数据源，供离线环境使用
+// 文件内容为单个汇率表或汇率表数组，如 {"base":"USD","date":"2024-03-01","rates":{"CNY":7.19,"EUR":0.92}}
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider 创建本地文件汇率数据源
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+// Name 数据源名称
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+// Fetch 读取并解析汇率文件
+func (p *FileProvider) Fetch() ([]*Table, error) {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseTables(file)
+}
+
+// fileTable 汇率文件中的一个汇率表
+type fileTable struct {
+	Base  string                `json:"base"`
+	Date  string                `json:"date"`
+	Rates map[string]money.Rate `json:"rates"`
+}
+
+// ParseTables 解析 JSON 汇率文件，支持单个汇率表或汇率表数组
+func ParseTables(r io.Reader) ([]*Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []fileTable
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		var single fileTable
+		err = json.Unmarshal(data, &single)
+		raw = []fileTable{single}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("汇率文件格式错误: %w", err)
+	}
+
+	tables := make([]*Table, 0, len(raw))
+	for i, item := range raw {
+		base := NormalizeCurrency(item.Base)
+		if base == "" {
+			return nil, fmt.Errorf("第%d个汇率表的基准币种无效: %q", i+1, item.Base)
+		}
+		date, err := time.ParseInLocation("2006-01-02", item.Date, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("第%d个汇率表的日期无效: %q", i+1, item.Date)
+		}
+
+		table := &Table{Base: base, Date: date, Rates: make(map[string]money.Rate, len(item.Rates))}
+		for code, rate := range item.Rates {
+			currency := NormalizeCurrency(code)
+			if currency == "" || rate <= 0 {
+				return nil, fmt.Errorf("第%d个汇率表中的汇率无效: %s", i+1, code)
+			}
+			if currency != base {
+				table.Rates[currency] = rate
+			}
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
@@ -0,0 +1,54 @@
+package exchange
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseTables 测试解析单个汇率表与汇率表数组，币种代码统一为大写
+func TestParseTables(t *testing.T) {
+	tables, err := ParseTables(strings.NewReader(`{"base":"usd","date":"2024-03-01","rates":{"CNY":7.19,"eur":"0.92","USD":1}}`))
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+	assert.Equal(t, "USD", tables[0].Base)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), tables[0].Date)
+	assert.Equal(t, map[string]money.Rate{"CNY": money.MustParseRate("7.19"), "EUR": money.MustParseRate("0.92")}, tables[0].Rates)
+
+	tables, err = ParseTables(strings.NewReader(`[{"base":"USD","date":"2024-03-01","rates":{"CNY":7.19}},{"base":"USD","date":"2024-03-02","rates":{"CNY":7.2}}]`))
+	assert.NoError(t, err)
+	assert.Len(t, tables, 2)
+	assert.Equal(t, money.MustParseRate("7.2"), tables[1].Rates["CNY"])
+}
+
+// TestParseTablesInvalid 测试基准币种、日期或汇率无效时报错
+func TestParseTablesInvalid(t *testing.T) {
+	for _, content := range []string{
+		`{"base":"US","date":"2024-03-01","rates":{"CNY":7.19}}`,
+		`{"base":"USD","date":"2024/03/01","rates":{"CNY":7.19}}`,
+		`{"base":"USD","date":"2024-03-01","rates":{"CNY":0}}`,
+		`{"base":"USD","date":"2024-03-01","rates":{"人民币":7.19}}`,
+		`not json`,
+	} {
+		_, err := ParseTables(strings.NewReader(content))
+		assert.Error(t, err, content)
+	}
+}
+
+// TestFileProvider 测试从本地文件读取汇率
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"base":"USD","date":"2024-03-01","rates":{"CNY":7.19}}`), 0644))
+
+	tables, err := NewFileProvider(path).Fetch()
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParseRate("7.19"), tables[0].Rates["CNY"])
+
+	_, err = NewFileProvider(filepath.Join(t.TempDir(), "missing.json")).Fetch()
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package exchange
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// currencyPattern ISO 4217 三位字母币种代码
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Table 数据源提供的某一天的汇率：1 单位 Base 可兑换 Rates[币种] 单位该币种
+type Table struct {
+	Base  string
+	Date  time.Time
+	Rates map[string]money.Rate
+}
+
+// Provider 汇率数据源，同步任务定期拉取后写入汇率表；可替换为在线接口等其他实现
+type Provider interface {
+	// Name 数据源名称，记录为汇率来源
+	Name() string
+	// Fetch 拉取数据源中的全部汇率
+	Fetch() ([]*Table, error)
+}
+
+// NormalizeCurrency 统一币种代码为大写，非三位字母代码返回空字符串
+func NormalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !currencyPattern.MatchString(code) {
+		return ""
+	}
+	return code
+}
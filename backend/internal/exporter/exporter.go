@@ -0,0 +1,187 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/xuri/excelize/v2"
+)
+
+// 导出格式
+const (
+	FormatCSV   = "csv"
+	FormatExcel = "excel"
+	FormatJSON  = "json"
+	FormatPDF   = "pdf" // 月度收支报告
+)
+
+// Extensions 各导出格式的文件扩展名
+var Extensions = map[string]string{
+	FormatCSV:   ".csv",
+	FormatExcel: ".xlsx",
+	FormatJSON:  ".json",
+	FormatPDF:   ".pdf",
+}
+
+var errUnsupportedFormat = errors.New("不支持的导出格式")
+
+// typeLabels 交易类型的中文名称，与导入时的默认类型取值一致，导出的 CSV 可直接重新导入
+var typeLabels = map[string]string{
+	"expense":  "支出",
+	"income":   "收入",
+	"transfer": "转账",
+}
+
+// header 表格导出的列名
+var header = []string{"日期", "时间", "类型", "金额", "币种", "分类", "账户", "转入账户", "标题", "备注", "地点", "标签"}
+
+// Row 导出的一条交易，分类和账户已解析为名称
+type Row struct {
+	Date         time.Time   `json:"date"`
+	Time         *time.Time  `json:"time,omitempty"`
+	Type         string      `json:"type"`
+	Amount       money.Money `json:"amount"`
+	Currency     string      `json:"currency"`
+	ExchangeRate money.Rate  `json:"exchange_rate"` // 记账时交易币种兑用户本位币的汇率，报告汇总按此换算
+	Category     string      `json:"category"`
+	Account      string      `json:"account"`
+	ToAccount    string      `json:"to_account"`
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	Location     string      `json:"location"`
+	Tags         []string    `json:"tags"`
+}
+
+// Write 按格式写出交易明细，PDF 报告请使用 WriteReport
+func Write(format string, w io.Writer, rows []*Row) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, rows)
+	case FormatExcel:
+		return WriteXLSX(w, rows)
+	case FormatJSON:
+		return WriteJSON(w, rows)
+	}
+	return errUnsupportedFormat
+}
+
+// WriteCSV 写出 CSV，带 UTF-8 BOM 以便 Excel 正确识别中文
+func WriteCSV(w io.Writer, rows []*Row) error {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row.cells()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteXLSX 写出 XLSX，金额列为数值格式
+func WriteXLSX(w io.Writer, rows []*Row) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	amountStyle, err := file.NewStyle(&excelize.Style{NumFmt: 2}) // 0.00
+	if err != nil {
+		return err
+	}
+
+	cells := make([]interface{}, len(header))
+	for i, name := range header {
+		cells[i] = name
+	}
+	if err := stream.SetRow("A1", cells); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		values := row.cells()
+		cells := make([]interface{}, len(values))
+		for j, value := range values {
+			cells[j] = value
+		}
+		cells[3] = excelize.Cell{StyleID: amountStyle, Value: row.Amount.Float64()}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := stream.SetRow(cell, cells); err != nil {
+			return err
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
+
+// WriteJSON 写出 JSON 数组
+func WriteJSON(w io.Writer, rows []*Row) error {
+	if rows == nil {
+		rows = []*Row{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+// cells 表格导出的单元格内容，顺序与 header 一致
+func (r *Row) cells() []string {
+	var clock string
+	if r.Time != nil {
+		clock = r.Time.Format("15:04:05")
+	}
+	return []string{
+		r.Date.Format("2006-01-02"),
+		clock,
+		TypeLabel(r.Type),
+		r.Amount.String(),
+		r.Currency,
+		r.Category,
+		r.Account,
+		r.ToAccount,
+		r.Title,
+		r.Description,
+		r.Location,
+		strings.Join(r.Tags, ","),
+	}
+}
+
+// TypeLabel 交易类型的中文名称
+func TypeLabel(transType string) string {
+	if label, ok := typeLabels[transType]; ok {
+		return label
+	}
+	return transType
+}
+
+// FileName 生成下载文件名，如“交易记录_20240301-20240331.csv”
+func FileName(format string, start, end *time.Time) string {
+	name := "交易记录"
+	if format == FormatPDF {
+		name = "收支报告"
+	}
+	switch {
+	case start != nil && end != nil:
+		name += fmt.Sprintf("_%s-%s", start.Format("20060102"), end.Format("20060102"))
+	case start != nil:
+		name += "_" + start.Format("20060102") + "起"
+	case end != nil:
+		name += "_截至" + end.Format("20060102")
+	}
+	return name + Extensions[format]
+}
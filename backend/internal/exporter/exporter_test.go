@@ -0,0 +1,67 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRows() []*Row {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)
+	return []*Row{
+		{Date: date, Type: "expense", Amount: money.MustParse("30.00"), Currency: "CNY", Category: "餐饮", Account: "钱包", Title: "午餐", Tags: []string{"工作", "外卖"}},
+		{Date: date, Type: "expense", Amount: money.MustParse("10.00"), Currency: "CNY", Account: "钱包"},
+		{Date: date, Type: "income", Amount: money.MustParse("100.00"), Currency: "CNY", Category: "工资", Account: "银行卡"},
+		{Date: date, Type: "transfer", Amount: money.MustParse("50.00"), Currency: "CNY", Account: "银行卡", ToAccount: "钱包"},
+	}
+}
+
+// TestWriteCSV 测试 CSV 带 BOM、中文表头和类型名称
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCSV(&buf, testRows()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "\xef\xbb\xbf日期,时间,类型,金额,币种,分类,账户,转入账户,标题,备注,地点,标签", lines[0])
+	assert.Equal(t, "2024-03-05,,支出,30.00,CNY,餐饮,钱包,,午餐,,,\"工作,外卖\"", lines[1])
+	assert.Equal(t, "2024-03-05,,转账,50.00,CNY,,银行卡,钱包,,,,", lines[4])
+}
+
+// TestReportSummary 测试报告汇总不计入转账，未分类的支出单独汇总
+func TestReportSummary(t *testing.T) {
+	report := &Report{Rows: testRows()}
+	income, expense, expenseCategories, incomeCategories, err := report.Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("100.00"), income)
+	assert.Equal(t, money.MustParse("40.00"), expense)
+	assert.Len(t, expenseCategories, 2)
+	assert.Equal(t, "餐饮", expenseCategories[0].Name)
+	assert.Equal(t, "未分类", expenseCategories[1].Name)
+	assert.Len(t, incomeCategories, 1)
+
+	var buf bytes.Buffer
+	report.Title = "2024年3月收支报告"
+	assert.NoError(t, WriteReport(&buf, report))
+	assert.True(t, strings.HasPrefix(buf.String(), "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(buf.String(), "%%EOF\n"))
+}
+
+// TestReportSummaryConvertsCurrency 测试外币交易按记账汇率换算为本位币后再汇总
+func TestReportSummaryConvertsCurrency(t *testing.T) {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)
+	report := &Report{Rows: []*Row{
+		{Date: date, Type: "expense", Amount: money.MustParse("30.00"), Currency: "CNY", ExchangeRate: money.OneRate, Category: "餐饮"},
+		{Date: date, Type: "expense", Amount: money.MustParse("10.00"), Currency: "USD", ExchangeRate: money.MustParseRate("7.2"), Category: "餐饮"},
+		{Date: date, Type: "income", Amount: money.MustParse("1000.00"), Currency: "JPY", ExchangeRate: money.MustParseRate("0.048"), Category: "工资"},
+	}}
+	income, expense, expenseCategories, _, err := report.Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("48.00"), income)
+	assert.Equal(t, money.MustParse("102.00"), expense)
+	assert.Equal(t, money.MustParse("102.00"), expenseCategories[0].Amount)
+}
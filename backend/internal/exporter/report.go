@@ -0,0 +1,260 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// 报告版式（pt）
+const (
+	reportMargin    = 50.0
+	reportTop       = pageHeight - 60
+	reportBottom    = 60.0
+	reportRowHeight = 16.0
+)
+
+// Report 收支报告，按明细汇总收入、支出及分类占比（转账不计入收支），
+// 汇总金额按各笔记账时的汇率换算为用户本位币，明细仍显示原币金额
+type Report struct {
+	Title       string // 如“2024年3月收支报告”
+	Start       time.Time
+	End         time.Time
+	GeneratedAt time.Time
+	Rows        []*Row
+}
+
+// CategoryTotal 分类汇总
+type CategoryTotal struct {
+	Name   string
+	Amount money.Money
+	Count  int
+}
+
+// Summary 汇总收入、支出（本位币）以及按金额降序排列的支出、收入分类，金额无法换算时返回错误
+func (r *Report) Summary() (income, expense money.Money, expenseCategories, incomeCategories []*CategoryTotal, err error) {
+	expenseTotals := make(map[string]*CategoryTotal)
+	incomeTotals := make(map[string]*CategoryTotal)
+	for _, row := range r.Rows {
+		var totals map[string]*CategoryTotal
+		amount, err := row.baseAmount()
+		if err != nil {
+			return 0, 0, nil, nil, err
+		}
+		switch row.Type {
+		case "expense":
+			expense += amount
+			totals = expenseTotals
+		case "income":
+			income += amount
+			totals = incomeTotals
+		default:
+			continue
+		}
+		name := row.Category
+		if name == "" {
+			name = "未分类"
+		}
+		total, ok := totals[name]
+		if !ok {
+			total = &CategoryTotal{Name: name}
+			totals[name] = total
+		}
+		total.Amount += amount
+		total.Count++
+	}
+	return income, expense, sortedTotals(expenseTotals), sortedTotals(incomeTotals), nil
+}
+
+// baseAmount 按记账汇率换算为本位币金额，未记录汇率时视为本位币
+func (r *Row) baseAmount() (money.Money, error) {
+	if r.ExchangeRate <= 0 {
+		return r.Amount, nil
+	}
+	return r.Amount.Convert(r.ExchangeRate)
+}
+
+func sortedTotals(totals map[string]*CategoryTotal) []*CategoryTotal {
+	result := make([]*CategoryTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Amount != result[j].Amount {
+			return result[i].Amount > result[j].Amount
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// WriteReport 写出 PDF 收支报告：收支概览、支出和收入分类占比、交易明细
+func WriteReport(w io.Writer, report *Report) error {
+	layout := &reportLayout{doc: newPDFDocument()}
+	layout.newPage()
+	doc := layout.doc
+
+	doc.text(reportMargin, layout.y, 18, report.Title)
+	layout.y -= 20
+	doc.text(reportMargin, layout.y, 9, fmt.Sprintf("统计区间：%s 至 %s    生成时间：%s",
+		report.Start.Format("2006-01-02"), report.End.Format("2006-01-02"), report.GeneratedAt.Format("2006-01-02 15:04")))
+	layout.y -= 20
+
+	income, expense, expenseCategories, incomeCategories, err := report.Summary()
+	if err != nil {
+		return err
+	}
+	layout.summary([]string{"总收入", "总支出", "结余", "交易笔数"},
+		[]string{income.String(), expense.String(), (income - expense).String(), fmt.Sprint(len(report.Rows))})
+
+	layout.categories("支出分类", expenseCategories, expense)
+	layout.categories("收入分类", incomeCategories, income)
+	layout.details(report.Rows)
+
+	layout.pageNumbers()
+	return doc.writeTo(w)
+}
+
+// reportLayout 自上而下排版，空间不足时自动换页
+type reportLayout struct {
+	doc *pdfDocument
+	y   float64
+}
+
+func (l *reportLayout) newPage() {
+	l.doc.addPage()
+	l.y = reportTop
+}
+
+// ensure 当前页剩余空间不足 height 时换页，返回是否换页
+func (l *reportLayout) ensure(height float64) bool {
+	if l.y-height >= reportBottom {
+		return false
+	}
+	l.newPage()
+	return true
+}
+
+// summary 绘制收支概览
+func (l *reportLayout) summary(labels, values []string) {
+	width := (pageWidth - 2*reportMargin) / float64(len(labels))
+	l.doc.fillRect(reportMargin, l.y-44, pageWidth-2*reportMargin, 50, 0.93)
+	for i := range labels {
+		x := reportMargin + 10 + float64(i)*width
+		l.doc.text(x, l.y-10, 9, labels[i])
+		l.doc.text(x, l.y-32, 14, values[i])
+	}
+	l.y -= 70
+}
+
+// section 绘制小节标题
+func (l *reportLayout) section(title string) {
+	l.ensure(60)
+	l.doc.text(reportMargin, l.y, 13, title)
+	l.y -= 10
+}
+
+// column 表格列，right 为 true 时右对齐
+type column struct {
+	title string
+	width float64
+	right bool
+}
+
+// tableRow 绘制一行表格，header 为 true 时带灰色背景
+func (l *reportLayout) tableRow(columns []column, values []string, header bool) {
+	top := l.y
+	if header {
+		l.doc.fillRect(reportMargin, top-reportRowHeight, pageWidth-2*reportMargin, reportRowHeight, 0.88)
+	}
+	x := reportMargin
+	for i, col := range columns {
+		value := truncate(values[i], col.width-8, 9)
+		if col.right {
+			l.doc.textRight(x+col.width-4, top-12, 9, value)
+		} else {
+			l.doc.text(x+4, top-12, 9, value)
+		}
+		x += col.width
+	}
+	l.doc.line(reportMargin, top-reportRowHeight, pageWidth-reportMargin, top-reportRowHeight)
+	l.y -= reportRowHeight
+}
+
+// table 绘制表格，换页时重复表头
+func (l *reportLayout) table(columns []column, rows [][]string) {
+	titles := make([]string, len(columns))
+	for i, col := range columns {
+		titles[i] = col.title
+	}
+	l.tableRow(columns, titles, true)
+	for _, row := range rows {
+		if l.ensure(reportRowHeight) {
+			l.tableRow(columns, titles, true)
+		}
+		l.tableRow(columns, row, false)
+	}
+	l.y -= 20
+}
+
+// categories 绘制分类汇总表
+func (l *reportLayout) categories(title string, totals []*CategoryTotal, sum money.Money) {
+	if len(totals) == 0 {
+		return
+	}
+	l.section(title)
+	rows := make([][]string, len(totals))
+	for i, total := range totals {
+		share := 0.0
+		if sum != 0 {
+			share = float64(total.Amount) / float64(sum) * 100
+		}
+		rows[i] = []string{total.Name, fmt.Sprint(total.Count), total.Amount.String(), fmt.Sprintf("%.1f%%", share)}
+	}
+	l.table([]column{
+		{title: "分类", width: 215},
+		{title: "笔数", width: 60, right: true},
+		{title: "金额", width: 120, right: true},
+		{title: "占比", width: 100, right: true},
+	}, rows)
+}
+
+// details 绘制交易明细表
+func (l *reportLayout) details(records []*Row) {
+	if len(records) == 0 {
+		return
+	}
+	l.section("交易明细")
+	rows := make([][]string, len(records))
+	for i, row := range records {
+		amount := row.Amount.String()
+		if row.Type == "expense" {
+			amount = "-" + amount
+		}
+		account := row.Account
+		if row.ToAccount != "" {
+			account += " → " + row.ToAccount
+		}
+		rows[i] = []string{row.Date.Format("2006-01-02"), TypeLabel(row.Type), row.Category, account, row.Title, amount}
+	}
+	l.table([]column{
+		{title: "日期", width: 68},
+		{title: "类型", width: 36},
+		{title: "分类", width: 75},
+		{title: "账户", width: 95},
+		{title: "标题", width: 141},
+		{title: "金额", width: 80, right: true},
+	}, rows)
+}
+
+// pageNumbers 在每页底部绘制页码
+func (l *reportLayout) pageNumbers() {
+	total := len(l.doc.pages)
+	for i, page := range l.doc.pages {
+		l.doc.current = page
+		l.doc.textRight(pageWidth-reportMargin, 30, 8, fmt.Sprintf("第 %d / %d 页", i+1, total))
+	}
+}
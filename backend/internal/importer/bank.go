@@ -0,0 +1,522 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// maxExternalIDLength 与 transactions.external_id 列长度一致，超长的银行流水号取摘要
+const maxExternalIDLength = 64
+
+// BankStatement 银行对账单解析结果
+type BankStatement struct {
+	AccountNumber  string       // 对账单中的账号（OFX ACCTID / camt IBAN）
+	Currency       string       // 对账单币种
+	ClosingBalance *money.Money // 期末余额，QIF 不提供
+	BalanceDate    *time.Time   // 期末余额对应的日期
+	Rows           []*Row       // 交易行，ExternalID 为银行流水号（OFX FITID 等）
+}
+
+// ParseBankStatement 解析 OFX、QIF 或 camt.053 银行对账单
+func ParseBankStatement(format string, r io.Reader) (*BankStatement, error) {
+	data, err := readText(r)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatOFX:
+		return parseOFX(string(data))
+	case FormatQIF:
+		return parseQIF(string(data))
+	case FormatCAMT:
+		return parseCAMT(data)
+	}
+	return nil, errUnsupportedFormat
+}
+
+var (
+	ofxTransactionPattern = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	ofxLedgerPattern      = regexp.MustCompile(`(?is)<LEDGERBAL>(.*?)</LEDGERBAL>`)
+	// ofxElementPattern 匹配叶子元素，兼容 OFX 1.x 中省略结束标签的写法
+	ofxElementPattern = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+)
+
+// parseOFX 解析 OFX 对账单，SGML（1.x）和 XML（2.x）格式均按标签提取
+func parseOFX(content string) (*BankStatement, error) {
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, errors.New("不是有效的 OFX 文件")
+	}
+
+	header := ofxElements(ofxTransactionPattern.ReplaceAllString(content, ""))
+	statement := &BankStatement{
+		AccountNumber: header["ACCTID"],
+		Currency:      strings.ToUpper(header["CURDEF"]),
+	}
+	if match := ofxLedgerPattern.FindStringSubmatch(content); match != nil {
+		ledger := ofxElements(match[1])
+		balance, err := ParseAmount(ledger["BALAMT"])
+		if err != nil {
+			return nil, err
+		}
+		date, _, err := parseOFXDate(ledger["DTASOF"])
+		if err != nil {
+			return nil, err
+		}
+		statement.ClosingBalance = &balance
+		statement.BalanceDate = &date
+	}
+
+	for _, loc := range ofxTransactionPattern.FindAllStringSubmatchIndex(content, -1) {
+		row := &Row{Line: strings.Count(content[:loc[0]], "\n") + 1}
+		row.Record, row.Err = parseOFXTransaction(ofxElements(content[loc[2]:loc[3]]))
+		if row.Record != nil {
+			row.Record.Currency = statement.Currency
+		}
+		statement.Rows = append(statement.Rows, row)
+	}
+	return statement, nil
+}
+
+// parseOFXTransaction 解析 STMTTRN 交易，金额为负表示支出
+func parseOFXTransaction(elements map[string]string) (*Record, error) {
+	date, withTime, err := parseOFXDate(elements["DTPOSTED"])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := ParseAmount(elements["TRNAMT"])
+	if err != nil {
+		return nil, err
+	}
+	if elements["FITID"] == "" {
+		return nil, errors.New("缺少交易流水号 FITID")
+	}
+
+	record := &Record{
+		Date:        time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local),
+		Title:       elements["NAME"],
+		Description: elements["MEMO"],
+		ExternalID:  normalizeExternalID(elements["FITID"]),
+	}
+	if withTime {
+		record.Time = &date
+	}
+	if record.Title == "" {
+		record.Title, record.Description = record.Description, ""
+	}
+	if err := setSignedAmount(record, amount); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// ofxElements 提取片段中的叶子元素值，同名元素取第一个
+func ofxElements(content string) map[string]string {
+	elements := make(map[string]string)
+	for _, match := range ofxElementPattern.FindAllStringSubmatch(content, -1) {
+		name := strings.ToUpper(match[1])
+		value := strings.TrimSpace(html.UnescapeString(match[2]))
+		if _, ok := elements[name]; !ok && value != "" {
+			elements[name] = value
+		}
+	}
+	return elements
+}
+
+// parseOFXDate 解析 OFX 日期，格式为 YYYYMMDD[HHMMSS[.XXX][[-5:EST]]]，忽略时区部分
+func parseOFXDate(value string) (time.Time, bool, error) {
+	digits := value
+	if i := strings.IndexAny(digits, ".["); i >= 0 {
+		digits = digits[:i]
+	}
+	switch {
+	case len(digits) >= 14:
+		if t, err := time.ParseInLocation("20060102150405", digits[:14], time.Local); err == nil {
+			return t, true, nil
+		}
+	case len(digits) >= 8:
+		if t, err := time.ParseInLocation("20060102", digits[:8], time.Local); err == nil {
+			return t, false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("无法识别的日期: %s", value)
+}
+
+// qifDateLayouts QIF 日期格式（美式月/日在前），年份中的撇号已替换为斜杠
+var qifDateLayouts = []string{"1/2/2006", "1/2/06", "2006-01-02", "2006/1/2", "1-2-2006", "1-2-06", "1.2.2006"}
+
+// qifAccountTypes 可导入的 QIF 账户类型，投资、分类列表等其他段落会被忽略
+var qifAccountTypes = map[string]bool{
+	"bank": true, "cash": true, "ccard": true, "oth a": true, "oth l": true,
+}
+
+// parseQIF 解析 QIF 对账单；QIF 没有流水号和余额，按交易内容生成稳定的去重编号
+func parseQIF(content string) (*BankStatement, error) {
+	statement := &BankStatement{}
+	occurrences := make(map[string]int)
+	enabled := false
+	var fields map[byte]string
+	start := 0
+
+	flush := func() {
+		if len(fields) == 0 {
+			return
+		}
+		// 同一天内容完全相同的多笔交易按出现次序区分
+		key := strings.Join([]string{fields['D'], fields['T'], fields['P'], fields['M'], fields['N']}, "|")
+		occurrences[key]++
+		row := &Row{Line: start}
+		row.Record, row.Err = parseQIFTransaction(fields)
+		if row.Record != nil {
+			row.Record.ExternalID = syntheticExternalID("qif", key, fmt.Sprint(occurrences[key]))
+		}
+		statement.Rows = append(statement.Rows, row)
+		fields = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	found := false
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r ")
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "!") {
+			flush()
+			if section := strings.ToLower(text); strings.HasPrefix(section, "!type:") {
+				enabled = qifAccountTypes[strings.TrimSpace(strings.TrimPrefix(section, "!type:"))]
+				found = true
+			} else {
+				enabled = false
+			}
+			continue
+		}
+		if !enabled {
+			continue
+		}
+		if text == "^" {
+			flush()
+			continue
+		}
+		if fields == nil {
+			fields = make(map[byte]string)
+			start = line
+		}
+		code := text[0]
+		if code == 'U' {
+			code = 'T'
+		}
+		// 拆分明细（S/E/$）不单独导入
+		if _, ok := fields[code]; !ok {
+			fields[code] = strings.TrimSpace(text[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	if !found {
+		return nil, errors.New("不是有效的 QIF 文件")
+	}
+	return statement, nil
+}
+
+// parseQIFTransaction 解析 QIF 交易，字段 D 日期、T 金额、P 收付款方、M 备注、L 分类
+func parseQIFTransaction(fields map[byte]string) (*Record, error) {
+	date, err := parseQIFDate(fields['D'])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := ParseAmount(fields['T'])
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{
+		Date:        date,
+		Title:       fields['P'],
+		Description: fields['M'],
+	}
+	if record.Title == "" {
+		record.Title, record.Description = record.Description, ""
+	}
+	// [账户名] 表示转账，不作为分类；多级分类“餐饮:午餐”取最后一级，“/”后为类别标记
+	if category := fields['L']; category != "" && !strings.HasPrefix(category, "[") {
+		category, _, _ = strings.Cut(category, "/")
+		record.Category = category[strings.LastIndex(category, ":")+1:]
+	}
+	if err := setSignedAmount(record, amount); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func parseQIFDate(value string) (time.Time, error) {
+	normalized := strings.ReplaceAll(strings.ReplaceAll(value, " ", ""), "'", "/")
+	for _, layout := range qifDateLayouts {
+		if t, err := time.ParseInLocation(layout, normalized, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if value == "" {
+		return time.Time{}, errors.New("日期不能为空")
+	}
+	return time.Time{}, fmt.Errorf("无法识别的日期: %s", value)
+}
+
+// camt.053 报文结构，仅包含导入需要的元素；不指定命名空间以兼容各版本
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	IBAN     string        `xml:"Acct>Id>IBAN"`
+	OtherID  string        `xml:"Acct>Id>Othr>Id"`
+	Currency string        `xml:"Acct>Ccy"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtBalance struct {
+	Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Date      camtDate   `xml:"Dt"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+// camtStatus 001.02 版本为文本，001.08 及以后为 <Sts><Cd>BOOK</Cd></Sts>
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtEntry struct {
+	Reference      string          `xml:"NtryRef"`
+	Amount         camtAmount      `xml:"Amt"`
+	Indicator      string          `xml:"CdtDbtInd"`
+	Status         camtStatus      `xml:"Sts"`
+	BookingDate    camtDate        `xml:"BookgDt"`
+	ValueDate      camtDate        `xml:"ValDt"`
+	ServicerRef    string          `xml:"AcctSvcrRef"`
+	AdditionalInfo string          `xml:"AddtlNtryInf"`
+	Details        []camtTxDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTxDetails struct {
+	EndToEndID  string    `xml:"Refs>EndToEndId"`
+	ServicerRef string    `xml:"Refs>AcctSvcrRef"`
+	Debtor      camtParty `xml:"RltdPties>Dbtr"`
+	Creditor    camtParty `xml:"RltdPties>Cdtr"`
+	Remittance  []string  `xml:"RmtInf>Ustrd"`
+}
+
+// camtParty 001.08 及以后版本名称位于 Pty 下
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"`
+}
+
+func (p camtParty) name() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.PartyName
+}
+
+// parseCAMT 解析 camt.053 对账单，Row.Line 为交易条目序号；只导入已入账（BOOK）的条目
+func parseCAMT(data []byte) (*BankStatement, error) {
+	var document camtDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("XML 解析失败: %w", err)
+	}
+	if len(document.Statements) == 0 {
+		return nil, errors.New("不是有效的 camt.053 对账单")
+	}
+
+	statement := &BankStatement{}
+	seq := 0
+	for _, stmt := range document.Statements {
+		if statement.AccountNumber == "" {
+			statement.AccountNumber = stmt.IBAN
+			if statement.AccountNumber == "" {
+				statement.AccountNumber = stmt.OtherID
+			}
+		}
+		if statement.Currency == "" {
+			statement.Currency = strings.ToUpper(stmt.Currency)
+		}
+		// 多个对账单时以最后一个期末余额为准
+		for _, balance := range stmt.Balances {
+			if balance.Code != "CLBD" {
+				continue
+			}
+			amount, err := camtSignedAmount(balance.Amount, balance.Indicator)
+			if err != nil {
+				return nil, err
+			}
+			date, _, err := balance.Date.parse()
+			if err != nil {
+				return nil, err
+			}
+			statement.ClosingBalance = &amount
+			statement.BalanceDate = &date
+			if statement.Currency == "" {
+				statement.Currency = strings.ToUpper(balance.Amount.Currency)
+			}
+		}
+		for _, entry := range stmt.Entries {
+			seq++
+			row := &Row{Line: seq}
+			row.Record, row.Skip, row.Err = parseCAMTEntry(entry)
+			statement.Rows = append(statement.Rows, row)
+		}
+	}
+	return statement, nil
+}
+
+// parseCAMTEntry 解析对账单条目，贷记（CRDT）为收入、借记（DBIT）为支出
+func parseCAMTEntry(entry camtEntry) (*Record, string, error) {
+	status := strings.TrimSpace(entry.Status.Value)
+	if entry.Status.Code != "" {
+		status = entry.Status.Code
+	}
+	if status != "" && status != "BOOK" {
+		return nil, "未入账", nil
+	}
+
+	date, withTime, err := entry.BookingDate.parse()
+	if err != nil {
+		if date, withTime, err = entry.ValueDate.parse(); err != nil {
+			return nil, "", err
+		}
+	}
+	amount, err := camtSignedAmount(entry.Amount, entry.Indicator)
+	if err != nil {
+		return nil, "", err
+	}
+
+	record := &Record{
+		Date:        time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local),
+		Currency:    strings.ToUpper(entry.Amount.Currency),
+		Description: entry.AdditionalInfo,
+		ExternalID:  entry.ServicerRef,
+	}
+	if withTime {
+		record.Time = &date
+	}
+	if record.ExternalID == "" {
+		record.ExternalID = entry.Reference
+	}
+	if len(entry.Details) > 0 {
+		details := entry.Details[0]
+		// 支出取收款方名称，收入取付款方名称
+		if amount < 0 {
+			record.Title = details.Creditor.name()
+		} else {
+			record.Title = details.Debtor.name()
+		}
+		if remittance := strings.Join(details.Remittance, " "); remittance != "" {
+			record.Description = remittance
+		}
+		if record.ExternalID == "" {
+			record.ExternalID = details.ServicerRef
+		}
+		if record.ExternalID == "" && details.EndToEndID != "NOTPROVIDED" {
+			record.ExternalID = details.EndToEndID
+		}
+	}
+	if record.Title == "" {
+		record.Title, record.Description = record.Description, ""
+	}
+	if record.ExternalID == "" {
+		record.ExternalID = syntheticExternalID("camt", record.Date.Format("20060102"), amount.String(), record.Title, record.Description)
+	}
+	record.ExternalID = normalizeExternalID(record.ExternalID)
+
+	if err := setSignedAmount(record, amount); err != nil {
+		return nil, "", err
+	}
+	return record, "", nil
+}
+
+func camtSignedAmount(amount camtAmount, indicator string) (money.Money, error) {
+	value, err := ParseAmount(strings.TrimSpace(amount.Value))
+	if err != nil {
+		return money.Zero, err
+	}
+	if indicator == "DBIT" {
+		value = -value
+	}
+	return value, nil
+}
+
+// parse 解析日期或日期时间，返回值表示是否包含时间部分
+func (d camtDate) parse() (time.Time, bool, error) {
+	if d.DateTime != "" {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+			if t, err := time.Parse(layout, d.DateTime); err == nil {
+				return t.In(time.Local), true, nil
+			}
+		}
+		return time.Time{}, false, fmt.Errorf("无法识别的日期: %s", d.DateTime)
+	}
+	if d.Date == "" {
+		return time.Time{}, false, errors.New("日期不能为空")
+	}
+	t, err := time.ParseInLocation("2006-01-02", d.Date, time.Local)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("无法识别的日期: %s", d.Date)
+	}
+	return t, false, nil
+}
+
+// setSignedAmount 按金额正负设置收支类型
+func setSignedAmount(record *Record, amount money.Money) error {
+	if amount == 0 {
+		return errors.New("金额不能为0")
+	}
+	record.Type = TypeIncome
+	if amount < 0 {
+		record.Type = TypeExpense
+	}
+	record.Amount = amount.Abs()
+	return nil
+}
+
+// syntheticExternalID 为没有流水号的交易按内容生成去重编号
+func syntheticExternalID(prefix string, parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "|")))
+	return prefix + "-" + hex.EncodeToString(sum[:16])
+}
+
+// normalizeExternalID 超出列长度的流水号取摘要
+func normalizeExternalID(id string) string {
+	if len(id) <= maxExternalIDLength {
+		return id
+	}
+	return syntheticExternalID("sha1", id)
+}
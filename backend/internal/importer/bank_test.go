@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseOFX 测试 OFX 1.x（SGML，省略结束标签）对账单的交易、流水号和期末余额
+func TestParseOFX(t *testing.T) {
+	content := "OFXHEADER:100\r\nDATA:OFXSGML\r\nVERSION:102\r\nCHARSET:1252\r\n\r\n" +
+		"<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS>\r\n" +
+		"<CURDEF>CNY\r\n" +
+		"<BANKACCTFROM><BANKID>308<ACCTID>6225880012341234<ACCTTYPE>CHECKING</BANKACCTFROM>\r\n" +
+		"<BANKTRANLIST><DTSTART>20240301<DTEND>20240331\r\n" +
+		"<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240305120000.000[+8:CST]<TRNAMT>-25.50<FITID>T001<NAME>Coffee &amp; Tea<MEMO>POS</STMTTRN>\r\n" +
+		"<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20240310<TRNAMT>8000.00<FITID>T002<MEMO>Salary</STMTTRN>\r\n" +
+		"<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240311<TRNAMT>-10.00<NAME>No id</STMTTRN>\r\n" +
+		"</BANKTRANLIST>\r\n" +
+		"<LEDGERBAL><BALAMT>12345.67<DTASOF>20240331235959</LEDGERBAL>\r\n" +
+		"</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\r\n"
+
+	statement, err := ParseBankStatement(FormatOFX, strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, "6225880012341234", statement.AccountNumber)
+	assert.Equal(t, "CNY", statement.Currency)
+	assert.Equal(t, money.MustParse("12345.67"), *statement.ClosingBalance)
+	assert.Equal(t, time.Date(2024, 3, 31, 23, 59, 59, 0, time.Local), *statement.BalanceDate)
+	assert.Len(t, statement.Rows, 3)
+
+	first := statement.Rows[0]
+	assert.Equal(t, 10, first.Line)
+	assert.Equal(t, TypeExpense, first.Record.Type)
+	assert.Equal(t, money.MustParse("25.50"), first.Record.Amount)
+	assert.Equal(t, time.Date(2024, 3, 5, 12, 0, 0, 0, time.Local), *first.Record.Time)
+	assert.Equal(t, "Coffee & Tea", first.Record.Title)
+	assert.Equal(t, "POS", first.Record.Description)
+	assert.Equal(t, "T001", first.Record.ExternalID)
+	assert.Equal(t, "CNY", first.Record.Currency)
+
+	second := statement.Rows[1].Record
+	assert.Equal(t, TypeIncome, second.Type)
+	assert.Equal(t, "Salary", second.Title) // 无 NAME 时使用 MEMO
+	assert.Nil(t, second.Time)
+
+	assert.EqualError(t, statement.Rows[2].Err, "缺少交易流水号 FITID")
+}
+
+// TestParseQIF 测试 QIF：忽略非账户段落，生成稳定且可区分重复交易的去重编号
+func TestParseQIF(t *testing.T) {
+	content := "!Type:Cat\nNDining\n^\n" +
+		"!Type:Bank\n" +
+		"D3/ 5'24\nT-25.50\nPStarbucks\nMLatte\nLDining:Coffee\n^\n" +
+		"D03/05/2024\nT-25.50\nPStarbucks\nMLatte\nLDining:Coffee\n^\n" +
+		"D03/10/2024\nT1,000.00\nPEmployer\nL[Savings]\n^\n" +
+		"D13/45/2024\nT-1.00\n^\n"
+
+	statement, err := ParseBankStatement(FormatQIF, strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Nil(t, statement.ClosingBalance)
+	assert.Len(t, statement.Rows, 4)
+
+	first, second := statement.Rows[0], statement.Rows[1]
+	assert.Equal(t, 5, first.Line)
+	assert.Equal(t, TypeExpense, first.Record.Type)
+	assert.Equal(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local), first.Record.Date)
+	assert.Equal(t, "Coffee", first.Record.Category)
+	assert.NotEqual(t, first.Record.ExternalID, second.Record.ExternalID)
+
+	again, err := ParseBankStatement(FormatQIF, strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, first.Record.ExternalID, again.Rows[0].Record.ExternalID)
+
+	assert.Equal(t, TypeIncome, statement.Rows[2].Record.Type)
+	assert.Equal(t, money.MustParse("1000.00"), statement.Rows[2].Record.Amount)
+	assert.Empty(t, statement.Rows[2].Record.Category) // 转账账户不作为分类
+	assert.EqualError(t, statement.Rows[3].Err, "无法识别的日期: 13/45/2024")
+}
+
+// TestParseCAMT 测试 camt.053：借贷方向、对方名称、参考号和未入账条目
+func TestParseCAMT(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+<BkToCstmrStmt><Stmt>
+  <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy></Acct>
+  <Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">100.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-03-01</Dt></Dt></Bal>
+  <Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">50.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Dt><Dt>2024-03-31</Dt></Dt></Bal>
+  <Ntry>
+    <Amt Ccy="EUR">200.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+    <BookgDt><Dt>2024-03-05</Dt></BookgDt><AcctSvcrRef>REF-1</AcctSvcrRef>
+    <NtryDtls><TxDtls>
+      <RltdPties><Cdtr><Pty><Nm>Landlord</Nm></Pty></Cdtr></RltdPties>
+      <RmtInf><Ustrd>Rent March</Ustrd></RmtInf>
+    </TxDtls></NtryDtls>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="EUR">50.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+    <BookgDt><DtTm>2024-03-06T10:00:00+01:00</DtTm></BookgDt>
+    <NtryDtls><TxDtls><Refs><EndToEndId>E2E-2</EndToEndId></Refs>
+      <RltdPties><Dbtr><Pty><Nm>Friend</Nm></Pty></Dbtr></RltdPties>
+    </TxDtls></NtryDtls>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="EUR">9.99</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>PDNG</Cd></Sts>
+    <BookgDt><Dt>2024-03-30</Dt></BookgDt>
+  </Ntry>
+</Stmt></BkToCstmrStmt>
+</Document>`
+
+	statement, err := ParseBankStatement(FormatCAMT, strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, "DE89370400440532013000", statement.AccountNumber)
+	assert.Equal(t, "EUR", statement.Currency)
+	assert.Equal(t, money.MustParse("-50.00"), *statement.ClosingBalance)
+	assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.Local), *statement.BalanceDate)
+	assert.Len(t, statement.Rows, 3)
+
+	rent := statement.Rows[0].Record
+	assert.Equal(t, TypeExpense, rent.Type)
+	assert.Equal(t, money.MustParse("200.00"), rent.Amount)
+	assert.Equal(t, "Landlord", rent.Title)
+	assert.Equal(t, "Rent March", rent.Description)
+	assert.Equal(t, "REF-1", rent.ExternalID)
+	assert.Equal(t, "EUR", rent.Currency)
+
+	gift := statement.Rows[1].Record
+	assert.Equal(t, TypeIncome, gift.Type)
+	assert.Equal(t, "Friend", gift.Title)
+	assert.Equal(t, "E2E-2", gift.ExternalID)
+	assert.NotNil(t, gift.Time)
+
+	assert.Equal(t, "未入账", statement.Rows[2].Skip)
+}
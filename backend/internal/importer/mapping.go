@@ -0,0 +1,326 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// 映射字段名称
+const (
+	FieldDate          = "date"           // 交易日期（可包含时间）
+	FieldTime          = "time"           // 交易时间
+	FieldType          = "type"           // 交易类型
+	FieldAmount        = "amount"         // 金额（无类型列时按正负区分收支）
+	FieldExpenseAmount = "expense_amount" // 支出金额列
+	FieldIncomeAmount  = "income_amount"  // 收入金额列
+	FieldCategory      = "category"       // 分类名称
+	FieldAccount       = "account"        // 账户名称
+	FieldToAccount     = "to_account"     // 转入账户名称
+	FieldTitle         = "title"          // 标题
+	FieldDescription   = "description"    // 备注
+	FieldCurrency      = "currency"       // 币种
+	FieldTags          = "tags"           // 标签，逗号或分号分隔
+)
+
+// Fields 全部可映射字段
+var Fields = []string{
+	FieldDate, FieldTime, FieldType, FieldAmount, FieldExpenseAmount, FieldIncomeAmount,
+	FieldCategory, FieldAccount, FieldToAccount, FieldTitle, FieldDescription, FieldCurrency, FieldTags,
+}
+
+// 交易类型
+const (
+	TypeExpense  = "expense"
+	TypeIncome   = "income"
+	TypeTransfer = "transfer"
+)
+
+// defaultTypeValues 类型列的默认取值映射
+var defaultTypeValues = map[string]string{
+	"expense":  TypeExpense,
+	"income":   TypeIncome,
+	"transfer": TypeTransfer,
+	"支出":       TypeExpense,
+	"收入":       TypeIncome,
+	"转账":       TypeTransfer,
+}
+
+// dateLayouts 未指定日期格式时依次尝试的格式
+var dateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006.1.2",
+	"20060102",
+	"2006年1月2日 15:04:05",
+	"2006年1月2日",
+	"01-02-06", // XLSX 未设置日期格式时的默认显示
+}
+
+var columnLetters = regexp.MustCompile(`^[A-Za-z]{1,3}$`)
+
+// Mapping 列映射配置
+type Mapping struct {
+	HeaderRow   int               // 表头所在行（从1开始），0 表示无表头、从第一行开始即为数据
+	Columns     map[string]string // 字段 -> 表头名称或列字母（如 "A"）
+	DateFormat  string            // Go 时间格式，为空时自动识别常见格式
+	TypeValues  map[string]string // 类型列取值 -> expense/income/transfer，补充默认映射
+	DefaultType string            // 无类型列时所有行使用的类型，为空时按金额正负区分收支
+}
+
+// Record 解析后的一条交易记录，账户与分类仍为名称，由调用方解析为ID
+type Record struct {
+	Type        string
+	Date        time.Time
+	Time        *time.Time
+	Amount      money.Money
+	Currency    string
+	Category    string
+	Account     string
+	ToAccount   string
+	Title       string
+	Description string
+	Tags        []string
+
+	// 以下字段仅由第三方账单解析器设置
+	ExternalID    string // 平台订单号，用于重复导入去重
+	PaymentMethod string // 收/付款方式，按账户名称或卡号尾号模糊匹配账户
+}
+
+// Row 一行数据的解析结果，Line 为文件中的行号（从1开始），camt.053 等 XML 格式为条目序号
+// Skip 非空表示该行无需导入（如已退款、已关闭的交易）及原因
+type Row struct {
+	Line   int
+	Record *Record
+	Skip   string
+	Err    error
+}
+
+// Parse 按映射解析表格数据行，空行会被跳过
+func Parse(table [][]string, mapping *Mapping) ([]*Row, error) {
+	if mapping.HeaderRow < 0 || mapping.HeaderRow > len(table) {
+		return nil, fmt.Errorf("表头行 %d 超出文件范围", mapping.HeaderRow)
+	}
+	var header []string
+	if mapping.HeaderRow > 0 {
+		header = table[mapping.HeaderRow-1]
+	}
+
+	columns, err := resolveColumns(header, mapping.Columns)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := columns[FieldDate]; !ok {
+		return nil, errors.New("缺少日期列映射")
+	}
+	_, hasAmount := columns[FieldAmount]
+	_, hasExpense := columns[FieldExpenseAmount]
+	_, hasIncome := columns[FieldIncomeAmount]
+	if !hasAmount && !hasExpense && !hasIncome {
+		return nil, errors.New("缺少金额列映射")
+	}
+
+	typeValues := make(map[string]string, len(defaultTypeValues)+len(mapping.TypeValues))
+	for k, v := range defaultTypeValues {
+		typeValues[k] = v
+	}
+	for k, v := range mapping.TypeValues {
+		typeValues[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+
+	rows := make([]*Row, 0, len(table)-mapping.HeaderRow)
+	for i := mapping.HeaderRow; i < len(table); i++ {
+		if isBlankRow(table[i]) {
+			continue
+		}
+		cell := func(field string) string {
+			idx, ok := columns[field]
+			if !ok || idx >= len(table[i]) {
+				return ""
+			}
+			return strings.TrimSpace(table[i][idx])
+		}
+		record, err := parseRecord(cell, mapping, typeValues)
+		rows = append(rows, &Row{Line: i + 1, Record: record, Err: err})
+	}
+	return rows, nil
+}
+
+// parseRecord 解析单行数据
+func parseRecord(cell func(string) string, mapping *Mapping, typeValues map[string]string) (*Record, error) {
+	record := &Record{
+		Currency:    strings.ToUpper(cell(FieldCurrency)),
+		Category:    cell(FieldCategory),
+		Account:     cell(FieldAccount),
+		ToAccount:   cell(FieldToAccount),
+		Title:       cell(FieldTitle),
+		Description: cell(FieldDescription),
+		Tags:        splitTags(cell(FieldTags)),
+	}
+
+	date, withTime, err := ParseDate(cell(FieldDate), mapping.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+	record.Date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	if withTime {
+		record.Time = &date
+	}
+	if value := cell(FieldTime); value != "" {
+		t, err := time.ParseInLocation("15:04:05", value, time.Local)
+		if err != nil {
+			if t, err = time.ParseInLocation("15:04", value, time.Local); err != nil {
+				return nil, fmt.Errorf("无法识别的时间: %s", value)
+			}
+		}
+		full := record.Date.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second)
+		record.Time = &full
+	}
+
+	// 分列的收支金额优先
+	var amount money.Money
+	if value := cell(FieldExpenseAmount); value != "" {
+		if amount, err = ParseAmount(value); err != nil {
+			return nil, err
+		}
+		record.Type = TypeExpense
+	}
+	if value := cell(FieldIncomeAmount); amount == 0 && value != "" {
+		if amount, err = ParseAmount(value); err != nil {
+			return nil, err
+		}
+		record.Type = TypeIncome
+	}
+	if amount == 0 {
+		if amount, err = ParseAmount(cell(FieldAmount)); err != nil {
+			return nil, err
+		}
+		record.Type = ""
+	}
+	if amount == 0 {
+		return nil, errors.New("金额不能为0")
+	}
+
+	if value := cell(FieldType); value != "" {
+		transType, ok := typeValues[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("无法识别的交易类型: %s", value)
+		}
+		record.Type = transType
+	}
+	if record.Type == "" {
+		switch {
+		case mapping.DefaultType != "":
+			record.Type = mapping.DefaultType
+		case amount < 0:
+			record.Type = TypeExpense
+		default:
+			record.Type = TypeIncome
+		}
+	}
+	record.Amount = amount.Abs()
+	return record, nil
+}
+
+// ParseDate 解析日期，layout 为空时自动识别，返回值表示是否包含时间部分
+func ParseDate(value, layout string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, errors.New("日期不能为空")
+	}
+	layouts := dateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, value, time.Local); err == nil {
+			return t, strings.Contains(l, "15"), nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("无法识别的日期: %s", value)
+}
+
+// ParseAmount 解析金额，支持货币符号、千分位和括号表示的负数
+func ParseAmount(value string) (money.Money, error) {
+	if value == "" {
+		return money.Zero, nil
+	}
+	cleaned := strings.NewReplacer(",", "", "¥", "", "￥", "", "$", "", " ", "", "+", "").Replace(value)
+	negative := false
+	if strings.HasPrefix(cleaned, "(") && strings.HasSuffix(cleaned, ")") {
+		negative = true
+		cleaned = cleaned[1 : len(cleaned)-1]
+	}
+	amount, err := money.Parse(cleaned)
+	if err != nil {
+		return money.Zero, fmt.Errorf("无法识别的金额: %s", value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// resolveColumns 将映射中的表头名称或列字母解析为列下标
+func resolveColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	columns := make(map[string]int, len(mapping))
+	for field, ref := range mapping {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		idx := -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), ref) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 && columnLetters.MatchString(ref) {
+			idx = columnIndex(ref)
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("找不到字段 %s 对应的列: %s", field, ref)
+		}
+		columns[field] = idx
+	}
+	return columns, nil
+}
+
+// columnIndex 列字母转下标，如 A -> 0、AA -> 26
+func columnIndex(letters string) int {
+	idx := 0
+	for _, c := range strings.ToUpper(letters) {
+		idx = idx*26 + int(c-'A'+1)
+	}
+	return idx - 1
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func splitTags(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == '，' || r == '；' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
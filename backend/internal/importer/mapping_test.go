@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseSignedAmount 测试按表头映射解析，无类型列时按金额正负区分收支
+func TestParseSignedAmount(t *testing.T) {
+	table, err := ReadTable(FormatCSV, strings.NewReader("\xef\xbb\xbf日期,金额,分类,备注\n"+
+		"2024/3/5 12:30:00,\"-1,234.50\",餐饮,聚餐\n"+
+		",,,\n"+
+		"2024-03-06,8000,工资,\n"+
+		"2024-03-07,abc,,\n"), 0)
+	assert.NoError(t, err)
+
+	rows, err := Parse(table, &Mapping{
+		HeaderRow: 1,
+		Columns:   map[string]string{FieldDate: "日期", FieldAmount: "金额", FieldCategory: "分类", FieldDescription: "D"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3) // 空行跳过
+
+	first := rows[0]
+	assert.Equal(t, 2, first.Line)
+	assert.NoError(t, first.Err)
+	assert.Equal(t, TypeExpense, first.Record.Type)
+	assert.Equal(t, money.MustParse("1234.50"), first.Record.Amount)
+	assert.Equal(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local), first.Record.Date)
+	assert.Equal(t, time.Date(2024, 3, 5, 12, 30, 0, 0, time.Local), *first.Record.Time)
+	assert.Equal(t, "餐饮", first.Record.Category)
+	assert.Equal(t, "聚餐", first.Record.Description) // 按列字母匹配
+
+	assert.Equal(t, TypeIncome, rows[1].Record.Type)
+	assert.Nil(t, rows[1].Record.Time)
+
+	assert.Equal(t, 5, rows[2].Line)
+	assert.EqualError(t, rows[2].Err, "无法识别的金额: abc")
+}
+
+// TestParseTypeColumns 测试类型列与分列收支金额
+func TestParseTypeColumns(t *testing.T) {
+	table := [][]string{
+		{"银行流水"},
+		{"交易日", "收支", "支出", "收入"},
+		{"20240301", "消费", "25.00", ""},
+		{"20240302", "", "", "100.00"},
+		{"20240303", "其他", "10.00", ""},
+	}
+	rows, err := Parse(table, &Mapping{
+		HeaderRow:  2,
+		Columns:    map[string]string{FieldDate: "交易日", FieldType: "收支", FieldExpenseAmount: "支出", FieldIncomeAmount: "收入"},
+		DateFormat: "20060102",
+		TypeValues: map[string]string{"消费": TypeExpense},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, TypeExpense, rows[0].Record.Type)
+	assert.Equal(t, money.MustParse("25.00"), rows[0].Record.Amount)
+	assert.Equal(t, TypeIncome, rows[1].Record.Type)
+	assert.EqualError(t, rows[2].Err, "无法识别的交易类型: 其他")
+
+	_, err = Parse(table, &Mapping{HeaderRow: 2, Columns: map[string]string{FieldDate: "交易日", FieldAmount: "金额"}})
+	assert.EqualError(t, err, "找不到字段 amount 对应的列: 金额")
+}
@@ -0,0 +1,257 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 第三方支付账单来源
+const (
+	SourceAlipay = "alipay"
+	SourceWechat = "wechat"
+)
+
+// 表头前的说明行数上限，超过仍未找到表头则视为无法识别
+const maxStatementPreamble = 40
+
+// ErrUnknownStatement 文件不是支持的第三方支付账单
+var ErrUnknownStatement = errors.New("无法识别的账单格式")
+
+// refundAmountPattern 匹配微信“已退款(￥3.00)”中的退款金额
+var refundAmountPattern = regexp.MustCompile(`[（(][¥￥]?([0-9.,]+)[)）]`)
+
+// statementColumns 账单列名，每个字段按顺序尝试多个别名以兼容新旧版本导出格式
+type statementColumns map[string][]string
+
+var alipayColumns = statementColumns{
+	"time":         {"交易时间", "交易创建时间", "付款时间"},
+	"category":     {"交易分类"},
+	"counterparty": {"交易对方"},
+	"goods":        {"商品说明", "商品名称"},
+	"direction":    {"收/支"},
+	"amount":       {"金额", "金额（元）", "金额(元)"},
+	"method":       {"收/付款方式"},
+	"status":       {"交易状态"},
+	"order":        {"交易订单号", "交易号"},
+	"refund":       {"成功退款（元）"},
+	"notes":        {"备注"},
+}
+
+var wechatColumns = statementColumns{
+	"time":         {"交易时间"},
+	"kind":         {"交易类型"},
+	"counterparty": {"交易对方"},
+	"goods":        {"商品"},
+	"direction":    {"收/支"},
+	"amount":       {"金额(元)", "金额（元）"},
+	"method":       {"支付方式"},
+	"status":       {"当前状态"},
+	"order":        {"交易单号"},
+	"notes":        {"备注"},
+}
+
+// statementRequired 识别账单格式所需的字段
+var statementRequired = []string{"time", "direction", "amount", "status", "order"}
+
+// DetectStatement 识别支付宝或微信支付导出的账单，返回来源与表头所在下标
+func DetectStatement(table [][]string) (string, int, error) {
+	for i := 0; i < len(table) && i < maxStatementPreamble; i++ {
+		if _, ok := statementIndex(table[i], alipayColumns); ok {
+			return SourceAlipay, i, nil
+		}
+		if _, ok := statementIndex(table[i], wechatColumns); ok {
+			return SourceWechat, i, nil
+		}
+	}
+	return "", 0, ErrUnknownStatement
+}
+
+// ParseStatement 解析支付宝或微信支付账单；source 为空时自动识别
+// 退款、关闭等无需记账的条目以及不计收支的资金划转会标记为跳过
+func ParseStatement(table [][]string, source string) (string, []*Row, error) {
+	detected, headerIdx, err := DetectStatement(table)
+	if err != nil {
+		return "", nil, err
+	}
+	if source != "" && source != detected {
+		return "", nil, fmt.Errorf("文件不是%s账单", statementName(source))
+	}
+
+	columns := alipayColumns
+	parse := parseAlipayRow
+	if detected == SourceWechat {
+		columns = wechatColumns
+		parse = parseWechatRow
+	}
+	index, _ := statementIndex(table[headerIdx], columns)
+
+	rows := make([]*Row, 0, len(table)-headerIdx-1)
+	for i := headerIdx + 1; i < len(table); i++ {
+		if isBlankRow(table[i]) {
+			continue
+		}
+		// 支付宝旧版账单末尾附有统计说明行
+		if strings.HasPrefix(strings.TrimSpace(table[i][0]), "---") {
+			break
+		}
+		cell := func(field string) string {
+			idx, ok := index[field]
+			if !ok || idx >= len(table[i]) {
+				return ""
+			}
+			value := strings.TrimSpace(table[i][idx])
+			if value == "/" {
+				return ""
+			}
+			return value
+		}
+		row := &Row{Line: i + 1}
+		row.Record, row.Skip, row.Err = parse(cell)
+		rows = append(rows, row)
+	}
+	return detected, rows, nil
+}
+
+// parseAlipayRow 解析支付宝账单行
+func parseAlipayRow(cell func(string) string) (*Record, string, error) {
+	status := cell("status")
+	switch status {
+	case "交易关闭", "交易失败", "退款成功":
+		return nil, status, nil
+	}
+
+	record, err := newStatementRecord(cell)
+	if err != nil {
+		return nil, "", err
+	}
+	switch cell("direction") {
+	case "支出":
+		record.Type = TypeExpense
+	case "收入":
+		record.Type = TypeIncome
+	default:
+		return nil, "不计收支", nil
+	}
+	record.Category = cell("category")
+
+	// 旧版账单在原交易上记录部分退款金额
+	if refund, err := ParseAmount(cell("refund")); err == nil && refund > 0 {
+		record.Amount -= refund
+		if record.Amount <= 0 {
+			return nil, "已全额退款", nil
+		}
+	}
+	return record, "", nil
+}
+
+// parseWechatRow 解析微信支付账单行
+func parseWechatRow(cell func(string) string) (*Record, string, error) {
+	status := cell("status")
+	kind := cell("kind")
+	if strings.Contains(kind, "退款") {
+		return nil, "退款记录", nil
+	}
+	if strings.Contains(status, "全额退款") || strings.Contains(status, "已退还") || strings.Contains(status, "关闭") || strings.Contains(status, "失败") {
+		return nil, status, nil
+	}
+
+	record, err := newStatementRecord(cell)
+	if err != nil {
+		return nil, "", err
+	}
+	switch cell("direction") {
+	case "支出":
+		record.Type = TypeExpense
+	case "收入":
+		record.Type = TypeIncome
+	default:
+		return nil, "不计收支", nil
+	}
+	if record.Title == "" {
+		record.Title = kind
+	}
+
+	// 部分退款：当前状态形如“已退款(￥3.00)”
+	if match := refundAmountPattern.FindStringSubmatch(status); match != nil && strings.Contains(status, "退款") {
+		if refund, err := ParseAmount(match[1]); err == nil {
+			record.Amount -= refund
+			if record.Amount <= 0 {
+				return nil, status, nil
+			}
+		}
+	}
+	return record, "", nil
+}
+
+// newStatementRecord 解析两种账单共有的列：时间、金额、交易对方、商品、付款方式和订单号
+func newStatementRecord(cell func(string) string) (*Record, error) {
+	datetime, _, err := ParseDate(cell("time"), "")
+	if err != nil {
+		return nil, err
+	}
+	amount, err := ParseAmount(cell("amount"))
+	if err != nil {
+		return nil, err
+	}
+	if amount == 0 {
+		return nil, errors.New("金额不能为0")
+	}
+
+	record := &Record{
+		Date:          time.Date(datetime.Year(), datetime.Month(), datetime.Day(), 0, 0, 0, 0, time.Local),
+		Time:          &datetime,
+		Amount:        amount.Abs(),
+		Title:         cell("counterparty"),
+		Description:   joinNonEmpty(" ", cell("goods"), cell("notes")),
+		ExternalID:    cell("order"),
+		PaymentMethod: cell("method"),
+	}
+	if record.ExternalID == "" {
+		return nil, errors.New("缺少订单号")
+	}
+	return record, nil
+}
+
+// statementIndex 在表头行中查找账单各字段的列下标，缺少必需字段时返回false
+func statementIndex(header []string, columns statementColumns) (map[string]int, bool) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[strings.TrimSpace(name)] = i
+	}
+
+	index := make(map[string]int, len(columns))
+	for field, aliases := range columns {
+		for _, alias := range aliases {
+			if idx, ok := positions[alias]; ok {
+				index[field] = idx
+				break
+			}
+		}
+	}
+	for _, field := range statementRequired {
+		if _, ok := index[field]; !ok {
+			return nil, false
+		}
+	}
+	return index, true
+}
+
+func statementName(source string) string {
+	if source == SourceWechat {
+		return "微信支付"
+	}
+	return "支付宝"
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// TestParseAlipayStatement 测试GBK编码的支付宝账单：跳过说明行、关闭和不计收支的条目并扣除部分退款
+func TestParseAlipayStatement(t *testing.T) {
+	content := "支付宝交易记录明细查询\n" +
+		"账号:[user@example.com]\n" +
+		"起始日期:[2024-03-01 00:00:00]    终止日期:[2024-04-01 00:00:00]\n" +
+		"---------------------------------交易记录明细列表------------------------------------\n" +
+		"交易时间,交易分类,交易对方,对方账号,商品说明,收/支,金额,收/付款方式,交易状态,交易订单号,商家订单号,备注,成功退款（元）\n" +
+		"2024-03-01 12:30:00,餐饮美食,食堂,/,午餐,支出,25.50,招商银行信用卡(1234),交易成功,2024030100001,/,/,0.00\n" +
+		"2024-03-02 09:00:00,日用百货,超市,/,购物,支出,100.00,余额宝,交易成功,2024030200001,/,/,30.00\n" +
+		"2024-03-03 10:00:00,转账红包,张三,/,红包,收入,66.00,余额,交易成功,2024030300001,/,/,\n" +
+		"2024-03-04 10:00:00,日用百货,超市,/,购物,支出,12.00,余额,交易关闭,2024030400001,/,/,\n" +
+		"2024-03-05 10:00:00,投资理财,余额宝,/,转入,不计收支,500.00,余额,交易成功,2024030500001,/,/,\n" +
+		"2024-03-06 10:00:00,餐饮美食,食堂,/,午餐,支出,,余额,交易成功,,/,/,\n" +
+		"------------------------------------------------------------------------------------\n" +
+		"导出时间:[2024-04-01 10:00:00]\n"
+	encoded, err := simplifiedchinese.GB18030.NewEncoder().String(content)
+	assert.NoError(t, err)
+
+	table, err := ReadTable(FormatCSV, strings.NewReader(encoded), 0)
+	assert.NoError(t, err)
+	source, rows, err := ParseStatement(table, "")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceAlipay, source)
+	assert.Len(t, rows, 6)
+
+	first := rows[0].Record
+	assert.Equal(t, 6, rows[0].Line)
+	assert.Equal(t, TypeExpense, first.Type)
+	assert.Equal(t, money.MustParse("25.50"), first.Amount)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), first.Date)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 30, 0, 0, time.Local), *first.Time)
+	assert.Equal(t, "食堂", first.Title)
+	assert.Equal(t, "午餐", first.Description)
+	assert.Equal(t, "餐饮美食", first.Category)
+	assert.Equal(t, "2024030100001", first.ExternalID)
+	assert.Equal(t, "招商银行信用卡(1234)", first.PaymentMethod)
+
+	assert.Equal(t, money.MustParse("70.00"), rows[1].Record.Amount) // 扣除部分退款
+	assert.Equal(t, TypeIncome, rows[2].Record.Type)
+	assert.Equal(t, "交易关闭", rows[3].Skip)
+	assert.Equal(t, "不计收支", rows[4].Skip)
+	assert.EqualError(t, rows[5].Err, "金额不能为0") // 缺少金额
+
+	_, _, err = ParseStatement(table, SourceWechat)
+	assert.EqualError(t, err, "文件不是微信支付账单")
+}
+
+// TestParseWechatStatement 测试微信支付账单：跳过全额退款和退款记录，部分退款按实付金额记账
+func TestParseWechatStatement(t *testing.T) {
+	table, err := ReadTable(FormatCSV, strings.NewReader("\xef\xbb\xbf微信支付账单明细,,,,,,,,,,\n"+
+		"微信昵称：[测试],,,,,,,,,,\n"+
+		"----------------------微信支付账单明细列表--------------------,,,,,,,,,,\n"+
+		"交易时间,交易类型,交易对方,商品,收/支,金额(元),支付方式,当前状态,交易单号,商户单号,备注\n"+
+		"2024-03-01 08:00:00,商户消费,早餐店,豆浆油条,支出,¥8.00,零钱,支付成功,4200001,10001,/\n"+
+		"2024-03-02 12:00:00,微信红包,李四,/,收入,¥20.00,/,已存入零钱,1000002,/,/\n"+
+		"2024-03-03 18:00:00,商户消费,商城,耳机,支出,¥199.00,招商银行(1234),已全额退款,4200003,10003,/\n"+
+		"2024-03-04 18:00:00,商户消费,商城,数据线,支出,¥50.00,零钱,已退款(￥15.00),4200004,10004,/\n"+
+		"2024-03-05 18:00:00,商户消费-退款,商城,数据线,收入,¥15.00,零钱,已退款,4200004,10004,/\n"+
+		"2024-03-06 18:00:00,零钱提现,招商银行(1234),/,/,¥100.00,零钱,提现已到账,1000006,/,/\n"), 0)
+	assert.NoError(t, err)
+
+	source, rows, err := ParseStatement(table, "")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceWechat, source)
+	assert.Len(t, rows, 6)
+
+	assert.Equal(t, money.MustParse("8.00"), rows[0].Record.Amount)
+	assert.Equal(t, "早餐店", rows[0].Record.Title)
+	assert.Equal(t, "4200001", rows[0].Record.ExternalID)
+	assert.Equal(t, TypeIncome, rows[1].Record.Type)
+	assert.Equal(t, "", rows[1].Record.Description) // “/”视为空
+	assert.Equal(t, "已全额退款", rows[2].Skip)
+	assert.Equal(t, money.MustParse("35.00"), rows[3].Record.Amount)
+	assert.Equal(t, "退款记录", rows[4].Skip)
+	assert.Equal(t, "不计收支", rows[5].Skip)
+
+	_, _, err = ParseStatement([][]string{{"日期", "金额"}}, "")
+	assert.Equal(t, ErrUnknownStatement, err)
+}
@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 支持的文件格式
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatOFX  = "ofx"     // OFX 1.x (SGML) / 2.x (XML)，含 QFX
+	FormatQIF  = "qif"     // Quicken Interchange Format
+	FormatCAMT = "camt053" // ISO 20022 camt.053 银行对账单
+)
+
+var errUnsupportedFormat = errors.New("不支持的文件格式，仅支持 CSV、XLSX、OFX、QIF 和 camt.053 XML")
+
+// DetectFormat 根据文件扩展名识别文件格式
+func DetectFormat(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	case ".ofx", ".qfx":
+		return FormatOFX, nil
+	case ".qif":
+		return FormatQIF, nil
+	case ".xml":
+		return FormatCAMT, nil
+	}
+	return "", errUnsupportedFormat
+}
+
+// IsBankFormat 是否为银行对账单格式（按对账单解析，不使用列映射）
+func IsBankFormat(format string) bool {
+	return format == FormatOFX || format == FormatQIF || format == FormatCAMT
+}
+
+// ReadTable 读取表格文件为二维字符串数组，XLSX 读取第一个工作表
+// delimiter 仅对 CSV 生效，为 0 时使用逗号
+func ReadTable(format string, r io.Reader, delimiter rune) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, delimiter)
+	case FormatXLSX:
+		return readXLSX(r)
+	}
+	return nil, errUnsupportedFormat
+}
+
+func readCSV(r io.Reader, delimiter rune) ([][]string, error) {
+	data, err := readText(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	if delimiter != 0 {
+		reader.Comma = delimiter
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV 解析失败: %w", err)
+	}
+	return rows, nil
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("XLSX 解析失败: %w", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("XLSX 文件中没有工作表")
+	}
+	rows, err := file.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("XLSX 读取失败: %w", err)
+	}
+	return rows, nil
+}
+
+// readText 读取文本文件内容并统一转为 UTF-8
+// 去除 Excel 导出 CSV 常带的 UTF-8 BOM；非 UTF-8 内容按 GBK（GB18030）解码，如支付宝账单和多数银行流水
+func readText(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		if data, err = simplifiedchinese.GB18030.NewDecoder().Bytes(data); err != nil {
+			return nil, fmt.Errorf("文件编码无法识别: %w", err)
+		}
+	}
+	return data, nil
+}
@@ -2,27 +2,41 @@ package models
 
 import (
 	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
 )
 
 // Account 资金账户表
 type Account struct {
-	ID             int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID         int64     `gorm:"not null;index:idx_user_id" json:"user_id"`
-	AccountType    string    `gorm:"type:enum('bank','alipay','wechat','cash','credit','other');not null" json:"account_type"`
-	AccountName    string    `gorm:"size:100;not null" json:"account_name"`
-	AccountNumber  string    `gorm:"size:50" json:"account_number"`
-	Icon           string    `gorm:"size:50" json:"icon"`
-	Color          string    `gorm:"size:20" json:"color"`
-	Balance        float64   `gorm:"type:decimal(15,2);default:0.00" json:"balance"`
-	InitialBalance float64   `gorm:"type:decimal(15,2);default:0.00" json:"initial_balance"`
-	IncludeInTotal bool      `gorm:"default:true" json:"include_in_total"`
-	DisplayOrder   int       `gorm:"default:0" json:"display_order"`
-	IsActive       bool      `gorm:"default:true;index:idx_active" json:"is_active"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         int64       `gorm:"not null;index:idx_user_id" json:"user_id"`
+	AccountType    string      `gorm:"type:enum('bank','alipay','wechat','cash','credit','other');not null" json:"account_type"`
+	AccountName    string      `gorm:"size:100;not null" json:"account_name"`
+	AccountNumber  string      `gorm:"size:50" json:"account_number"`
+	Icon           string      `gorm:"size:50" json:"icon"`
+	Color          string      `gorm:"size:20" json:"color"`
+	Balance        money.Money `gorm:"type:decimal(15,2);default:0.00" json:"balance"`
+	InitialBalance money.Money `gorm:"type:decimal(15,2);default:0.00" json:"initial_balance"`
+	Currency       string      `gorm:"size:10;default:'CNY'" json:"currency"`               // 账户币种，余额与该账户的交易均以此币种记账
+	CreditLimit    money.Money `gorm:"type:decimal(15,2);default:0.00" json:"credit_limit"` // 信用额度（仅信用账户），余额为负表示欠款
+	BillingDay     *int        `json:"billing_day"`                                         // 账单日 (1-31，仅信用账户)
+	PaymentDueDay  *int        `json:"payment_due_day"`                                     // 还款日 (1-31，仅信用账户)
+	IncludeInTotal bool        `gorm:"default:true" json:"include_in_total"`
+	DisplayOrder   int         `gorm:"default:0" json:"display_order"`
+	IsActive       bool        `gorm:"default:true;index:idx_active" json:"is_active"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
 }
 
 // TableName 表名
 func (Account) TableName() string {
 	return "accounts"
 }
+
+// AccountTypeCredit 信用账户类型（信用卡、花呗等），余额为负数表示欠款
+const AccountTypeCredit = "credit"
+
+// HasStatementCycle 是否已设置账单日和还款日，设置后按周期生成信用账单
+func (a *Account) HasStatementCycle() bool {
+	return a.AccountType == AccountTypeCredit && a.BillingDay != nil && a.PaymentDueDay != nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// AccountBalanceSnapshot 账户余额快照表，记录账户在有余额变动的日期的日终余额
+// 由交易记录从初始余额重放生成，无变动的日期沿用前一条快照，首条为账户开立日
+type AccountBalanceSnapshot struct {
+	ID               int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           int64       `gorm:"not null;index:idx_user_date" json:"user_id"`
+	AccountID        int64       `gorm:"not null;uniqueIndex:uk_account_date" json:"account_id"`
+	SnapshotDate     time.Time   `gorm:"type:date;not null;uniqueIndex:uk_account_date;index:idx_user_date" json:"snapshot_date"`
+	Balance          money.Money `gorm:"type:decimal(15,2);not null" json:"balance"` // 日终余额（账户币种）
+	AccountUpdatedAt time.Time   `gorm:"not null" json:"account_updated_at"`         // 生成时账户的最后更新时间，账户再次更新后需重建
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+// TableName 表名
+func (AccountBalanceSnapshot) TableName() string {
+	return "account_balance_snapshots"
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// Bill 账单订阅表
+type Bill struct {
+	ID               int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           int64       `gorm:"not null;index:idx_user_id" json:"user_id"`
+	BillName         string      `gorm:"size:100;not null" json:"bill_name"`
+	CategoryID       *int64      `json:"category_id"`
+	AccountID        *int64      `json:"account_id"`
+	Amount           money.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency         string      `gorm:"size:10;default:'CNY'" json:"currency"`
+	BillingCycle     string      `gorm:"type:enum('daily','weekly','monthly','quarterly','yearly','custom');not null" json:"billing_cycle"`
+	BillingDay       *int        `json:"billing_day"`                    // 每月/每年的账单日 (1-31)
+	IntervalDays     int         `gorm:"default:0" json:"interval_days"` // 自定义周期天数（billing_cycle=custom）
+	NextBillingDate  time.Time   `gorm:"type:date;not null;index:idx_next_billing" json:"next_billing_date"`
+	AutoRenew        bool        `gorm:"default:true" json:"auto_renew"`
+	IsActive         bool        `gorm:"default:true;index:idx_active" json:"is_active"`
+	Icon             string      `gorm:"size:50" json:"icon"`
+	Color            string      `gorm:"size:20" json:"color"`
+	Description      string      `gorm:"type:text" json:"description"`
+	RemindDaysBefore int         `gorm:"default:3" json:"remind_days_before"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+
+	// 关联关系
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Account  *Account  `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+}
+
+// TableName 表名
+func (Bill) TableName() string {
+	return "bills"
+}
+
+// BillHistory 账单历史表
+type BillHistory struct {
+	ID            int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BillID        int64       `gorm:"not null;index:idx_bill_id" json:"bill_id"`
+	TransactionID *int64      `json:"transaction_id"`
+	Amount        money.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	BillingDate   time.Time   `gorm:"type:date;not null;index:idx_billing_date" json:"billing_date"`
+	Status        string      `gorm:"type:enum('pending','paid','failed','cancelled');default:'paid'" json:"status"`
+	Notes         string      `gorm:"type:text" json:"notes"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// TableName 表名
+func (BillHistory) TableName() string {
+	return "bill_history"
+}
+
+// BillingCycle 账单周期常量
+const (
+	BillingCycleDaily     = "daily"
+	BillingCycleWeekly    = "weekly"
+	BillingCycleMonthly   = "monthly"
+	BillingCycleQuarterly = "quarterly"
+	BillingCycleYearly    = "yearly"
+	BillingCycleCustom    = "custom"
+)
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// Budget 预算表
+// 预算按周期循环：以 StartDate 为锚点，每周/每月/每年为一个周期，CategoryID 为空表示总预算
+type Budget struct {
+	ID             int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         int64       `gorm:"not null;index:idx_user_period" json:"user_id"`
+	CategoryID     *int64      `gorm:"index:idx_category" json:"category_id"`
+	Name           string      `gorm:"size:100" json:"name"`
+	BudgetAmount   money.Money `gorm:"type:decimal(15,2);not null" json:"budget_amount"`
+	PeriodType     string      `gorm:"type:enum('weekly','monthly','yearly');default:'monthly'" json:"period_type"`
+	StartDate      time.Time   `gorm:"type:date;not null;index:idx_user_period" json:"start_date"`
+	EndDate        *time.Time  `gorm:"type:date" json:"end_date"`     // 为空表示长期有效
+	Rollover       bool        `gorm:"default:false" json:"rollover"` // 上期未用完的额度结转到本期
+	AlertThreshold float64     `gorm:"type:decimal(5,2);default:0.80" json:"alert_threshold"`
+	IsActive       bool        `gorm:"default:true" json:"is_active"`
+	AlertPeriod    *time.Time  `gorm:"type:date" json:"-"` // 最近一次预警所属周期的开始日期
+	AlertLevel     int         `gorm:"default:0" json:"-"` // 该周期已触发的最高预警等级（百分比）
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+
+	// 关联关系
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
+
+// TableName 表名
+func (Budget) TableName() string {
+	return "budgets"
+}
+
+// BudgetPeriod 预算周期常量
+const (
+	BudgetPeriodWeekly  = "weekly"
+	BudgetPeriodMonthly = "monthly"
+	BudgetPeriodYearly  = "yearly"
+)
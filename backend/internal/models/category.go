@@ -8,6 +8,7 @@ import (
 type Category struct {
 	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
 	UserID       int64     `gorm:"not null;index:idx_user_type" json:"user_id"`
+	ParentID     *int64    `gorm:"index:idx_parent" json:"parent_id"` // 上级分类，为空时是顶级分类
 	Type         string    `gorm:"type:enum('expense','income');not null;index:idx_user_type" json:"type"`
 	Name         string    `gorm:"size:50;not null" json:"name"`
 	Icon         string    `gorm:"size:50;not null" json:"icon"`
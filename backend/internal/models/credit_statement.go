@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// CreditStatement 信用账单表，每个账单周期（上一账单日次日至本账单日）生成一期
+// 金额以账户币种记账，欠款为正数，溢缴款为负数
+type CreditStatement struct {
+	ID               int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           int64       `gorm:"not null;index:idx_user_id" json:"user_id"`
+	AccountID        int64       `gorm:"not null;uniqueIndex:uk_account_statement" json:"account_id"`
+	PeriodStart      time.Time   `gorm:"type:date;not null" json:"period_start"`
+	StatementDate    time.Time   `gorm:"type:date;not null;uniqueIndex:uk_account_statement" json:"statement_date"` // 账单日，即周期最后一天
+	DueDate          time.Time   `gorm:"type:date;not null;index:idx_due_date" json:"due_date"`
+	OpeningBalance   money.Money `gorm:"type:decimal(15,2);default:0.00" json:"opening_balance"`  // 上期账单日欠款
+	NewCharges       money.Money `gorm:"type:decimal(15,2);default:0.00" json:"new_charges"`      // 本期消费及转出
+	Credits          money.Money `gorm:"type:decimal(15,2);default:0.00" json:"credits"`          // 本期还款、退款等入账
+	StatementAmount  money.Money `gorm:"type:decimal(15,2);default:0.00" json:"statement_amount"` // 本期应还，即账单日欠款
+	MinimumPayment   money.Money `gorm:"type:decimal(15,2);default:0.00" json:"minimum_payment"`
+	PaidAmount       money.Money `gorm:"type:decimal(15,2);default:0.00" json:"paid_amount"` // 账单日后的还款及入账
+	Currency         string      `gorm:"size:10;default:'CNY'" json:"currency"`
+	TransactionCount int         `gorm:"default:0" json:"transaction_count"`
+	Status           string      `gorm:"type:enum('unpaid','partial','paid','overdue');default:'unpaid';index:idx_status" json:"status"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// TableName 表名
+func (CreditStatement) TableName() string {
+	return "credit_statements"
+}
+
+// CreditStatementStatus 信用账单状态常量
+const (
+	CreditStatementUnpaid  = "unpaid"
+	CreditStatementPartial = "partial"
+	CreditStatementPaid    = "paid"
+	CreditStatementOverdue = "overdue"
+)
+
+// Remaining 本期剩余应还金额
+func (s *CreditStatement) Remaining() money.Money {
+	if s.PaidAmount >= s.StatementAmount {
+		return 0
+	}
+	return s.StatementAmount - s.PaidAmount
+}
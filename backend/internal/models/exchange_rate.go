@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// ExchangeRate 汇率表，1 单位 FromCurrency 可兑换 Rate 单位 ToCurrency
+type ExchangeRate struct {
+	ID           int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       int64      `gorm:"not null;default:0;uniqueIndex:uk_rate" json:"user_id"` // 0 表示汇率数据源同步的公共汇率
+	FromCurrency string     `gorm:"size:10;not null;uniqueIndex:uk_rate;index:idx_pair" json:"from_currency"`
+	ToCurrency   string     `gorm:"size:10;not null;uniqueIndex:uk_rate;index:idx_pair" json:"to_currency"`
+	Rate         money.Rate `gorm:"type:decimal(18,8);not null" json:"rate"`
+	RateDate     time.Time  `gorm:"type:date;not null;uniqueIndex:uk_rate" json:"rate_date"` // 生效日期，查询时取不晚于交易日期的最近一条
+	Source       string     `gorm:"size:20;default:'manual'" json:"source"`                  // manual 为手工录入，其余为同步的数据源名称
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 表名
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// ExchangeRateSourceManual 手工录入的汇率
+const ExchangeRateSourceManual = "manual"
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// ExportJob 导出任务表
+type ExportJob struct {
+	ID            int64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        int64         `gorm:"not null;index:idx_user_id" json:"user_id"`
+	ExportType    string        `gorm:"type:enum('csv','excel','json','pdf');not null" json:"export_type"`
+	StartDate     *time.Time    `gorm:"type:date" json:"start_date"`
+	EndDate       *time.Time    `gorm:"type:date" json:"end_date"`
+	Filters       ExportFilters `gorm:"type:json" json:"filters"`
+	Status        string        `gorm:"type:enum('pending','processing','completed','failed','expired');default:'pending';index:idx_status" json:"status"`
+	FileName      string        `gorm:"size:255" json:"file_name"`                      // 下载时的文件名
+	FilePath      string        `gorm:"size:500" json:"-"`                              // 文件在存储目录中的路径
+	FileSize      int64         `json:"file_size"`                                      // 文件大小（字节）
+	RowCount      int           `gorm:"default:0" json:"row_count"`                     // 导出的交易条数
+	DownloadToken *string       `gorm:"size:64;uniqueIndex:uk_download_token" json:"-"` // 下载令牌，任务完成时生成，过期后失效
+	ErrorMessage  string        `gorm:"type:text" json:"error_message"`
+	CreatedAt     time.Time     `gorm:"index:idx_created_at" json:"created_at"`
+	CompletedAt   *time.Time    `json:"completed_at"`
+	ExpiresAt     *time.Time    `gorm:"index:idx_expires_at" json:"expires_at"`
+}
+
+// TableName 表名
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
+
+// 导出任务状态
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+	ExportStatusExpired    = "expired"
+)
+
+// ExportFilters 导出筛选条件，与交易列表的筛选条件一致（日期范围单独存储）
+type ExportFilters struct {
+	Type          string `json:"type,omitempty"`
+	CategoryID    *int64 `json:"category_id,omitempty"`
+	AccountID     *int64 `json:"account_id,omitempty"`
+	SearchKeyword string `json:"search_keyword,omitempty"`
+}
+
+// Value 实现driver.Valuer接口
+func (f ExportFilters) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+// Scan 实现sql.Scanner接口
+func (f *ExportFilters) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, f)
+}
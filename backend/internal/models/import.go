@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// ImportProfile 导入列映射配置表
+type ImportProfile struct {
+	ID                int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID            int64     `gorm:"not null;index:idx_user_id" json:"user_id"`
+	Name              string    `gorm:"size:100;not null" json:"name"`
+	Delimiter         string    `gorm:"size:4" json:"delimiter"`           // CSV 分隔符，为空时使用逗号
+	HeaderRow         int       `gorm:"default:1" json:"header_row"`       // 表头所在行，0 表示无表头
+	Columns           JSONMap   `gorm:"type:json;not null" json:"columns"` // 字段 -> 表头名称或列字母
+	DateFormat        string    `gorm:"size:50" json:"date_format"`        // 为空时自动识别
+	TypeValues        JSONMap   `gorm:"type:json" json:"type_values"`      // 类型列取值映射
+	DefaultType       string    `gorm:"size:20" json:"default_type"`       // 无类型列时的默认类型
+	DefaultAccountID  *int64    `json:"default_account_id"`                // 未映射或未匹配账户时使用
+	DefaultCategoryID *int64    `json:"default_category_id"`               // 未映射或未匹配分类时使用
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TableName 表名
+func (ImportProfile) TableName() string {
+	return "import_profiles"
+}
+
+// ImportBatch 导入批次表
+type ImportBatch struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       int64     `gorm:"not null;index:idx_user_id" json:"user_id"`
+	ProfileID    *int64    `json:"profile_id"`
+	FileName     string    `gorm:"size:255;not null" json:"file_name"`
+	FileFormat   string    `gorm:"size:20;not null" json:"file_format"`
+	Source       string    `gorm:"size:20" json:"source"` // 第三方账单来源（alipay/wechat），按列映射导入时为空
+	Status       string    `gorm:"type:enum('previewed','processing','completed','failed','rolled_back');default:'previewed';index" json:"status"`
+	TotalRows    int       `gorm:"default:0" json:"total_rows"`
+	ValidRows    int       `gorm:"default:0" json:"valid_rows"`
+	ImportedRows int       `gorm:"default:0" json:"imported_rows"`
+	FailedRows   int       `gorm:"default:0" json:"failed_rows"`
+	SkippedRows  int       `gorm:"default:0" json:"skipped_rows"` // 退款、关闭或重复导入而跳过的行数
+	Payload      string    `gorm:"type:longtext" json:"-"`        // 预览通过校验的交易请求（JSON），提交时逐条写入
+	Errors       JSONArray `gorm:"type:json" json:"errors"`
+
+	// 银行对账单（OFX/QIF/camt.053）导入的目标账户与余额核对
+	AccountID         *int64       `json:"account_id"`
+	ClosingBalance    *money.Money `gorm:"type:decimal(15,2)" json:"closing_balance"`    // 对账单期末余额
+	BalanceDate       *time.Time   `gorm:"type:date" json:"balance_date"`                // 期末余额日期
+	BalanceDifference *money.Money `gorm:"type:decimal(15,2)" json:"balance_difference"` // 期末余额 - 推算的账户余额，0 表示一致
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// TableName 表名
+func (ImportBatch) TableName() string {
+	return "import_batches"
+}
+
+// 导入批次状态
+const (
+	ImportStatusPreviewed  = "previewed"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportStatusFailed     = "failed"
+	ImportStatusRolledBack = "rolled_back"
+)
+
+// JSONMap 用于处理JSON对象字段（字符串键值）
+type JSONMap map[string]string
+
+// Value 实现driver.Valuer接口
+func (jm JSONMap) Value() (driver.Value, error) {
+	return json.Marshal(jm)
+}
+
+// Scan 实现sql.Scanner接口
+func (jm *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, &jm)
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// Loan 贷款/分期表（花呗分期、信用卡分期、消费贷等）
+// 创建时按还款方式生成完整还款计划，每期到期后自动在关联账户记一笔支出
+type Loan struct {
+	ID                   int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID               int64       `gorm:"not null;index:idx_user_id" json:"user_id"`
+	Name                 string      `gorm:"size:100;not null" json:"name"`
+	LoanType             string      `gorm:"type:enum('installment','loan');not null;default:'installment'" json:"loan_type"`
+	AccountID            int64       `gorm:"not null" json:"account_id"` // 还款账户，分期挂在信用卡/花呗上时即为该信用账户
+	CategoryID           *int64      `json:"category_id"`
+	Principal            money.Money `gorm:"type:decimal(15,2);not null" json:"principal"`
+	AnnualRate           float64     `gorm:"type:decimal(8,4);default:0" json:"annual_rate"` // 年化利率（百分比），0 表示免息
+	RepaymentMethod      string      `gorm:"type:enum('equal_installment','equal_principal');not null" json:"repayment_method"`
+	Term                 int         `gorm:"not null" json:"term"` // 总期数（月）
+	Currency             string      `gorm:"size:10;default:'CNY'" json:"currency"`
+	FirstDueDate         time.Time   `gorm:"type:date;not null" json:"first_due_date"`
+	PaidPeriods          int         `gorm:"not null;default:0" json:"paid_periods"`
+	OutstandingPrincipal money.Money `gorm:"type:decimal(15,2);not null" json:"outstanding_principal"`
+	NextDueDate          *time.Time  `gorm:"type:date;index:idx_next_due" json:"next_due_date"` // 为空表示已结清或已取消
+	Status               string      `gorm:"type:enum('active','completed','cancelled');default:'active';index:idx_next_due" json:"status"`
+	Notes                string      `gorm:"type:text" json:"notes"`
+	CreatedAt            time.Time   `json:"created_at"`
+	UpdatedAt            time.Time   `json:"updated_at"`
+
+	// 关联关系
+	Installments []*LoanInstallment `gorm:"foreignKey:LoanID" json:"installments,omitempty"`
+}
+
+// TableName 表名
+func (Loan) TableName() string {
+	return "loans"
+}
+
+// LoanInstallment 还款计划表，每期一条，入账后关联生成的交易
+type LoanInstallment struct {
+	ID                 int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	LoanID             int64       `gorm:"not null;uniqueIndex:uk_loan_period" json:"loan_id"`
+	Period             int         `gorm:"not null;uniqueIndex:uk_loan_period" json:"period"`
+	DueDate            time.Time   `gorm:"type:date;not null" json:"due_date"`
+	Principal          money.Money `gorm:"type:decimal(15,2);not null" json:"principal"`
+	Interest           money.Money `gorm:"type:decimal(15,2);not null;default:0.00" json:"interest"`
+	Amount             money.Money `gorm:"type:decimal(15,2);not null" json:"amount"`              // 本期应还 = 本金 + 利息
+	RemainingPrincipal money.Money `gorm:"type:decimal(15,2);not null" json:"remaining_principal"` // 本期还款后的剩余本金
+	Status             string      `gorm:"type:enum('pending','paid','cancelled');default:'pending'" json:"status"`
+	TransactionID      *int64      `json:"transaction_id"`
+	PaidAt             *time.Time  `json:"paid_at"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+}
+
+// TableName 表名
+func (LoanInstallment) TableName() string {
+	return "loan_installments"
+}
+
+// LoanType 贷款类型常量
+const (
+	LoanTypeInstallment = "installment"
+	LoanTypeLoan        = "loan"
+)
+
+// RepaymentMethod 还款方式常量
+const (
+	RepaymentEqualInstallment = "equal_installment" // 等额本息
+	RepaymentEqualPrincipal   = "equal_principal"   // 等额本金
+)
+
+// LoanStatus 贷款状态常量
+const (
+	LoanStatusActive    = "active"
+	LoanStatusCompleted = "completed"
+	LoanStatusCancelled = "cancelled"
+)
+
+// InstallmentStatus 还款计划状态常量
+const (
+	InstallmentPending   = "pending"
+	InstallmentPaid      = "paid"
+	InstallmentCancelled = "cancelled"
+)
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Notification 通知表
+type Notification struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      int64      `gorm:"not null;index:idx_user_read" json:"user_id"`
+	Type        string     `gorm:"type:enum('bill_reminder','budget_alert','goal_achieved','large_expense','app_update','system');not null" json:"type"`
+	Title       string     `gorm:"size:200;not null" json:"title"`
+	Content     string     `gorm:"type:text" json:"content"`
+	RelatedType string     `gorm:"size:50;index:idx_related" json:"related_type"` // 关联类型: bill/budget/transaction/app_update等
+	RelatedID   *int64     `gorm:"index:idx_related" json:"related_id"`
+	IsRead      bool       `gorm:"default:false;index:idx_user_read" json:"is_read"`
+	Priority    string     `gorm:"type:enum('low','medium','high');default:'medium'" json:"priority"`
+	ScheduledAt *time.Time `gorm:"index:idx_scheduled" json:"scheduled_at"`
+	SentAt      *time.Time `json:"sent_at"`
+	ReadAt      *time.Time `json:"read_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName 表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationType 通知类型常量
+const (
+	NotificationTypeBillReminder = "bill_reminder"
+	NotificationTypeBudgetAlert  = "budget_alert"
+	NotificationTypeGoalAchieved = "goal_achieved"
+	NotificationTypeLargeExpense = "large_expense"
+	NotificationTypeAppUpdate    = "app_update"
+	NotificationTypeSystem       = "system"
+)
+
+// NotificationPriority 通知优先级常量
+const (
+	NotificationPriorityLow    = "low"
+	NotificationPriorityMedium = "medium"
+	NotificationPriorityHigh   = "high"
+)
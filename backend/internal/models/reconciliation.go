@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// Reconciliation 对账记录表，用户核对截至某日的实际余额后，该日及之前的交易标记为已对账
+type Reconciliation struct {
+	ID                      int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID                  int64       `gorm:"not null;index:idx_user_id" json:"user_id"`
+	AccountID               int64       `gorm:"not null;index:idx_account_date" json:"account_id"`
+	ReconcileDate           time.Time   `gorm:"type:date;not null;index:idx_account_date" json:"reconcile_date"`
+	StatementBalance        money.Money `gorm:"type:decimal(15,2);not null" json:"statement_balance"` // 用户录入的实际余额
+	LedgerBalance           money.Money `gorm:"type:decimal(15,2);not null" json:"ledger_balance"`    // 对账前的账面余额
+	Difference              money.Money `gorm:"type:decimal(15,2);not null" json:"difference"`        // 实际余额 - 账面余额
+	Currency                string      `gorm:"size:10;default:'CNY'" json:"currency"`
+	AdjustmentTransactionID *int64      `json:"adjustment_transaction_id"` // 补记差额的余额调整交易
+	TransactionCount        int         `gorm:"default:0" json:"transaction_count"`
+	Notes                   string      `gorm:"size:500" json:"notes"`
+	CreatedAt               time.Time   `json:"created_at"`
+}
+
+// TableName 表名
+func (Reconciliation) TableName() string {
+	return "reconciliations"
+}
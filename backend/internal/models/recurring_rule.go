@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// RecurringRule 周期交易规则表
+// 规则语义参照RRULE：每 Interval 个 Frequency 发生一次，可按月内日期固定，
+// 以 EndDate 或 MaxOccurrences 结束（均为空表示无限重复）
+type RecurringRule struct {
+	ID              int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID          int64       `gorm:"not null;index:idx_user_id" json:"user_id"`
+	Name            string      `gorm:"size:100;not null" json:"name"`
+	Type            string      `gorm:"type:enum('expense','income','transfer');not null" json:"type"`
+	CategoryID      *int64      `json:"category_id"`
+	AccountID       *int64      `json:"account_id"`
+	ToAccountID     *int64      `json:"to_account_id"`
+	Amount          money.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency        string      `gorm:"size:10;default:'CNY'" json:"currency"`
+	Title           string      `gorm:"size:200" json:"title"`
+	Description     string      `gorm:"type:text" json:"description"`
+	Frequency       string      `gorm:"type:enum('daily','weekly','monthly','yearly');not null" json:"frequency"`
+	Interval        int         `gorm:"column:interval_count;not null;default:1" json:"interval"`
+	ByMonthDay      *int        `json:"by_month_day"` // 每月/每年固定日期 (1-31)，超出当月天数取月末
+	StartDate       time.Time   `gorm:"type:date;not null" json:"start_date"`
+	EndDate         *time.Time  `gorm:"type:date" json:"end_date"`
+	MaxOccurrences  *int        `json:"max_occurrences"`
+	OccurrenceCount int         `gorm:"not null;default:0" json:"occurrence_count"`
+	NextRunDate     *time.Time  `gorm:"type:date;index:idx_next_run" json:"next_run_date"` // 为空表示规则已结束
+	LastRunDate     *time.Time  `gorm:"type:date" json:"last_run_date"`
+	IsActive        bool        `gorm:"default:true;index:idx_next_run" json:"is_active"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// TableName 表名
+func (RecurringRule) TableName() string {
+	return "recurring_rules"
+}
+
+// RecurringFrequency 周期规则频率常量
+const (
+	RecurringFrequencyDaily   = "daily"
+	RecurringFrequencyWeekly  = "weekly"
+	RecurringFrequencyMonthly = "monthly"
+	RecurringFrequencyYearly  = "yearly"
+)
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// SavingsPlan 储蓄计划表
+// 存取款以转账交易的形式在账户之间划转，CurrentAmount 为计划已存金额
+type SavingsPlan struct {
+	ID             int64        `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         int64        `gorm:"not null;index:idx_user_id" json:"user_id"`
+	PlanName       string       `gorm:"size:100;not null" json:"plan_name"`
+	PlanType       string       `gorm:"size:50" json:"plan_type"` // 52周存钱法、365天存钱法等
+	TargetAmount   money.Money  `gorm:"type:decimal(15,2);not null" json:"target_amount"`
+	CurrentAmount  money.Money  `gorm:"type:decimal(15,2);default:0.00" json:"current_amount"`
+	StartDate      time.Time    `gorm:"type:date;not null" json:"start_date"`
+	TargetDate     *time.Time   `gorm:"type:date" json:"target_date"`
+	Frequency      *string      `gorm:"type:enum('daily','weekly','monthly')" json:"frequency"`
+	PeriodicAmount *money.Money `gorm:"type:decimal(15,2)" json:"periodic_amount"`
+	AccountID      *int64       `json:"account_id"` // 存款转入的储蓄账户
+	Icon           string       `gorm:"size:50" json:"icon"`
+	Color          string       `gorm:"size:20" json:"color"`
+	Description    string       `gorm:"type:text" json:"description"`
+	Status         string       `gorm:"type:enum('active','completed','paused','cancelled');default:'active';index:idx_status" json:"status"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	CompletedAt    *time.Time   `json:"completed_at"`
+
+	// 关联关系
+	Account *Account `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+}
+
+// TableName 表名
+func (SavingsPlan) TableName() string {
+	return "savings_plans"
+}
+
+// SavingsRecord 储蓄记录表
+type SavingsRecord struct {
+	ID            int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	PlanID        int64       `gorm:"not null;index:idx_plan_id" json:"plan_id"`
+	TransactionID *int64      `json:"transaction_id"`
+	RecordType    string      `gorm:"type:enum('deposit','withdraw');default:'deposit'" json:"record_type"`
+	Amount        money.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	DepositDate   time.Time   `gorm:"type:date;not null;index:idx_deposit_date" json:"deposit_date"`
+	PeriodNumber  *int        `json:"period_number"` // 第几期（如第32周）
+	Notes         string      `gorm:"type:text" json:"notes"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// TableName 表名
+func (SavingsRecord) TableName() string {
+	return "savings_records"
+}
+
+// SavingsStatus 储蓄计划状态常量
+const (
+	SavingsStatusActive    = "active"
+	SavingsStatusCompleted = "completed"
+	SavingsStatusPaused    = "paused"
+	SavingsStatusCancelled = "cancelled"
+)
+
+// SavingsRecordType 储蓄记录类型常量
+const (
+	SavingsRecordDeposit  = "deposit"
+	SavingsRecordWithdraw = "withdraw"
+)
@@ -4,34 +4,47 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
 )
 
 // Transaction 交易记录表
 type Transaction struct {
-	ID                int64       `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID            int64       `gorm:"not null;index:idx_user_date;index:idx_user_type" json:"user_id"`
-	Type              string      `gorm:"type:enum('expense','income','transfer');not null;index:idx_user_type" json:"type"`
-	CategoryID        *int64      `gorm:"index:idx_category" json:"category_id"`
-	AccountID         *int64      `gorm:"index:idx_account" json:"account_id"`
-	ToAccountID       *int64      `json:"to_account_id"`
-	Amount            float64     `gorm:"type:decimal(15,2);not null" json:"amount"`
-	Currency          string      `gorm:"size:10;default:'CNY'" json:"currency"`
-	Title             string      `gorm:"size:200" json:"title"`
-	Description       string      `gorm:"type:text" json:"description"`
-	Location          string      `gorm:"size:200" json:"location"`
-	TransactionDate   time.Time   `gorm:"type:date;not null;index:idx_date;index:idx_user_date" json:"transaction_date"`
-	TransactionTime   *time.Time  `json:"transaction_time"`
-	BillID            *int64      `json:"bill_id"`
-	WishlistID        *int64      `json:"wishlist_id"`
-	Tags              JSONArray   `gorm:"type:json" json:"tags"`
-	Images            JSONArray   `gorm:"type:json" json:"images"`
-	CreatedAt         time.Time   `json:"created_at"`
-	UpdatedAt         time.Time   `json:"updated_at"`
+	ID                 int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID             int64       `gorm:"not null;index:idx_user_date;index:idx_user_type;uniqueIndex:uk_user_external" json:"user_id"`
+	Type               string      `gorm:"type:enum('expense','income','transfer');not null;index:idx_user_type" json:"type"`
+	CategoryID         *int64      `gorm:"index:idx_category" json:"category_id"`
+	AccountID          *int64      `gorm:"index:idx_account;uniqueIndex:uk_user_external" json:"account_id"`
+	ToAccountID        *int64      `json:"to_account_id"`
+	Amount             money.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency           string      `gorm:"size:10;default:'CNY'" json:"currency"`
+	ExchangeRate       money.Rate  `gorm:"type:decimal(18,8);default:1" json:"exchange_rate"` // 记账时交易币种兑用户本位币的汇率，统计按此换算
+	Title              string      `gorm:"size:200" json:"title"`
+	Description        string      `gorm:"type:text" json:"description"`
+	Location           string      `gorm:"size:200" json:"location"`
+	TransactionDate    time.Time   `gorm:"type:date;not null;index:idx_date;index:idx_user_date;uniqueIndex:uk_recurring_occurrence" json:"transaction_date"`
+	TransactionTime    *time.Time  `json:"transaction_time"`
+	BillID             *int64      `json:"bill_id"`
+	WishlistID         *int64      `json:"wishlist_id"`
+	RecurringRuleID    *int64      `gorm:"uniqueIndex:uk_recurring_occurrence" json:"recurring_rule_id"` // 周期规则生成的交易，同一规则同一天仅一条
+	ImportBatchID      *int64      `gorm:"index:idx_import_batch" json:"import_batch_id"`                // 导入生成的交易，按批次回滚
+	ExternalID         *string     `gorm:"size:64;uniqueIndex:uk_user_external" json:"external_id"`      // 第三方平台订单号或银行流水号，同一账户内唯一，重复导入时去重；非导入交易为空
+	RefundOfID         *int64      `gorm:"index:idx_refund_of" json:"refund_of_id"`                      // 退款或报销到账对应的原支出，统计时冲减原支出而非计为收入
+	Reimbursable       bool        `gorm:"default:false" json:"reimbursable"`                            // 可报销支出，未全额报销前计入待报销
+	ReconciliationID   *int64      `gorm:"index:idx_reconciliation" json:"reconciliation_id"`            // 已对账的交易（转账为转出账户一侧），撤销对账前不能修改金额、账户、类型和日期或删除
+	ToReconciliationID *int64      `gorm:"index:idx_to_reconciliation" json:"to_reconciliation_id"`      // 转账转入账户一侧的对账，两侧分别记录，撤销一侧不影响另一侧
+	Tags               JSONArray   `gorm:"type:json" json:"tags"`
+	Images             JSONArray   `gorm:"type:json" json:"images"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
 
 	// 关联关系
-	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-	Account  *Account  `gorm:"foreignKey:AccountID" json:"account,omitempty"`
-	ToAccount *Account `gorm:"foreignKey:ToAccountID" json:"to_account,omitempty"`
+	Category  *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Account   *Account  `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	ToAccount *Account  `gorm:"foreignKey:ToAccountID" json:"to_account,omitempty"`
+
+	// 拆分明细：一笔交易按分类拆分时，分类统计按明细计算
+	Splits []*TransactionSplit `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"splits,omitempty"`
 }
 
 // TableName 表名
@@ -39,6 +52,25 @@ func (Transaction) TableName() string {
 	return "transactions"
 }
 
+// TransactionSplit 交易拆分明细表，各明细金额之和等于交易金额
+type TransactionSplit struct {
+	ID            int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID int64       `gorm:"not null;index:idx_transaction" json:"transaction_id"`
+	CategoryID    *int64      `gorm:"index:idx_category" json:"category_id"`
+	Amount        money.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Note          string      `gorm:"size:200" json:"note"`
+	DisplayOrder  int         `gorm:"default:0" json:"display_order"`
+	CreatedAt     time.Time   `json:"created_at"`
+
+	// 关联关系
+	Category *Category `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL" json:"category,omitempty"`
+}
+
+// TableName 表名
+func (TransactionSplit) TableName() string {
+	return "transaction_splits"
+}
+
 // JSONArray 用于处理JSON数组字段
 type JSONArray []string
 
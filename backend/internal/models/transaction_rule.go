@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// TransactionRule 交易规则表，新建或导入的交易满足全部条件时自动设置分类、添加标签或修改标题
+type TransactionRule struct {
+	ID       int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID   int64  `gorm:"not null;index:idx_user_priority" json:"user_id"`
+	Name     string `gorm:"size:100;not null" json:"name"`
+	Priority int    `gorm:"default:0;index:idx_user_priority" json:"priority"` // 数值越小越先执行
+	IsActive bool   `gorm:"default:true" json:"is_active"`
+
+	// 匹配条件，未设置的条件不参与匹配；关键词不区分大小写，多个关键词用 | 分隔，包含任一即可
+	TransactionType     string       `gorm:"size:20" json:"transaction_type"`
+	TitleContains       string       `gorm:"size:200" json:"title_contains"`
+	DescriptionContains string       `gorm:"size:200" json:"description_contains"`
+	LocationContains    string       `gorm:"size:200" json:"location_contains"`
+	MinAmount           *money.Money `gorm:"type:decimal(15,2)" json:"min_amount"`
+	MaxAmount           *money.Money `gorm:"type:decimal(15,2)" json:"max_amount"`
+	AccountID           *int64       `json:"account_id"`
+
+	// 执行动作
+	CategoryID  *int64    `json:"category_id"`                  // 交易未指定分类时设置
+	AddTags     JSONArray `gorm:"type:json" json:"add_tags"`    // 追加到交易标签，已有的标签不重复添加
+	RenameTitle string    `gorm:"size:200" json:"rename_title"` // 多条规则匹配时以先执行的为准
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 表名
+func (TransactionRule) TableName() string {
+	return "transaction_rules"
+}
@@ -19,8 +19,9 @@ type User struct {
 	Language        string     `gorm:"size:10;default:'zh-CN'" json:"language"`
 	DarkMode        bool       `gorm:"default:false" json:"dark_mode"`
 	GestureLock     bool       `gorm:"default:true" json:"gesture_lock"`
-	ContinuousDays  int        `gorm:"default:0" json:"continuous_days"`
+	ContinuousDays  int        `gorm:"default:0" json:"continuous_days"` // 截至最近记账日的连续记账天数
 	TotalRecords    int        `gorm:"default:0" json:"total_records"`
+	LastRecordDate  *time.Time `gorm:"type:date" json:"last_record_date,omitempty"` // 最近一次记账的日期，用于累计连续天数
 	TotalBadges     int        `gorm:"default:0" json:"total_badges"`
 	MembershipLevel string     `gorm:"size:20;default:'FREE'" json:"membership_level"`
 	Role            string     `gorm:"size:20;default:'user'" json:"role"`
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// Wishlist 心愿单表
+// 存款仅记录为心愿预留的金额，购买时生成带 WishlistID 的支出交易
+type Wishlist struct {
+	ID            int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        int64       `gorm:"not null;index:idx_user_id" json:"user_id"`
+	ItemName      string      `gorm:"size:200;not null" json:"item_name"`
+	TargetAmount  money.Money `gorm:"type:decimal(15,2);not null" json:"target_amount"`
+	CurrentAmount money.Money `gorm:"type:decimal(15,2);default:0.00" json:"current_amount"`
+	Icon          string      `gorm:"size:100" json:"icon"`
+	ImageURL      string      `gorm:"size:500" json:"image_url"`
+	Priority      string      `gorm:"type:enum('low','medium','high');default:'medium'" json:"priority"`
+	Status        string      `gorm:"type:enum('planning','in_progress','completed','cancelled');default:'planning';index:idx_status" json:"status"`
+	Description   string      `gorm:"type:text" json:"description"`
+	URL           string      `gorm:"size:500" json:"url"`
+	TargetDate    *time.Time  `gorm:"type:date" json:"target_date"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	CompletedAt   *time.Time  `json:"completed_at"`
+}
+
+// TableName 表名
+func (Wishlist) TableName() string {
+	return "wishlists"
+}
+
+// WishlistDeposit 心愿单存款表
+type WishlistDeposit struct {
+	ID            int64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	WishlistID    int64       `gorm:"not null;index:idx_wishlist_id" json:"wishlist_id"`
+	TransactionID *int64      `json:"transaction_id"`
+	RecordType    string      `gorm:"type:enum('deposit','withdraw');default:'deposit'" json:"record_type"`
+	Amount        money.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	DepositDate   time.Time   `gorm:"type:date;not null;index:idx_deposit_date" json:"deposit_date"`
+	Notes         string      `gorm:"type:text" json:"notes"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// TableName 表名
+func (WishlistDeposit) TableName() string {
+	return "wishlist_deposits"
+}
+
+// WishlistStatus 心愿单状态常量
+const (
+	WishlistStatusPlanning   = "planning"
+	WishlistStatusInProgress = "in_progress"
+	WishlistStatusCompleted  = "completed"
+	WishlistStatusCancelled  = "cancelled"
+)
+
+// WishlistPriority 心愿单优先级常量
+const (
+	WishlistPriorityLow    = "low"
+	WishlistPriorityMedium = "medium"
+	WishlistPriorityHigh   = "high"
+)
+
+// WishlistRecordType 心愿单存款记录类型常量
+const (
+	WishlistRecordDeposit  = "deposit"
+	WishlistRecordWithdraw = "withdraw"
+)
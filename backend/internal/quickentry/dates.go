@@ -0,0 +1,142 @@
+package quickentry
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateRule 日期表达式及其解析方法，ok 为 false 表示数值无效（如 2月30日）
+type dateRule struct {
+	pattern *regexp.Regexp
+	resolve func(match []string, today time.Time) (date time.Time, ok bool)
+}
+
+var relativeDays = map[string]int{
+	"大前天": -3, "前天": -2, "昨天": -1, "昨日": -1, "今天": 0, "今日": 0, "明天": 1,
+	"day before yesterday": -2, "yesterday": -1, "today": 0, "tomorrow": 1,
+}
+
+var chineseWeekdays = map[string]time.Weekday{
+	"一": time.Monday, "二": time.Tuesday, "三": time.Wednesday, "四": time.Thursday,
+	"五": time.Friday, "六": time.Saturday, "日": time.Sunday, "天": time.Sunday,
+}
+
+var englishWeekdays = map[string]time.Weekday{
+	"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday, "thursday": time.Thursday,
+	"friday": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+}
+
+var englishMonths = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+const monthPattern = `(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept|sep|oct|nov|dec)\.?`
+
+// dateRules 按从具体到模糊的顺序匹配，只取第一个命中的日期
+var dateRules = []dateRule{
+	{regexp.MustCompile(`(\d{4})[-/.年](\d{1,2})[-/.月](\d{1,2})[日号]?`), func(m []string, today time.Time) (time.Time, bool) {
+		return makeDate(atoi(m[1]), time.Month(atoi(m[2])), atoi(m[3]), today)
+	}},
+	{regexp.MustCompile(`(\d{1,2})月(\d{1,2})[日号]?`), func(m []string, today time.Time) (time.Time, bool) {
+		return recentMonthDay(time.Month(atoi(m[1])), atoi(m[2]), today)
+	}},
+	{regexp.MustCompile(`(?i)\b` + monthPattern + `\s+(\d{1,2})(?:st|nd|rd|th)?\b`), func(m []string, today time.Time) (time.Time, bool) {
+		return recentMonthDay(englishMonths[strings.ToLower(m[1])[:3]], atoi(m[2]), today)
+	}},
+	{regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthPattern + `\b`), func(m []string, today time.Time) (time.Time, bool) {
+		return recentMonthDay(englishMonths[strings.ToLower(m[2])[:3]], atoi(m[1]), today)
+	}},
+	{regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})\b`), func(m []string, today time.Time) (time.Time, bool) {
+		return recentMonthDay(time.Month(atoi(m[1])), atoi(m[2]), today)
+	}},
+	{regexp.MustCompile(`(?i)大前天|前天|昨天|昨日|今天|今日|明天|\bday before yesterday\b|\byesterday\b|\btoday\b|\btomorrow\b`), func(m []string, today time.Time) (time.Time, bool) {
+		return today.AddDate(0, 0, relativeDays[strings.ToLower(m[0])]), true
+	}},
+	{regexp.MustCompile(`(?i)(\d{1,2})\s*(?:天前|\s*days?\s+ago\b)`), func(m []string, today time.Time) (time.Time, bool) {
+		return today.AddDate(0, 0, -atoi(m[1])), true
+	}},
+	{regexp.MustCompile(`(上*)(?:周|星期|礼拜)([一二三四五六日天])`), func(m []string, today time.Time) (time.Time, bool) {
+		weeks := len([]rune(m[1]))
+		if weeks == 0 {
+			return recentWeekday(chineseWeekdays[m[2]], today, false), true
+		}
+		// 上周X：按周一为一周的开始，取前 weeks 周的星期X
+		monday := today.AddDate(0, 0, -((int(today.Weekday())+6)%7)-7*weeks)
+		return monday.AddDate(0, 0, (int(chineseWeekdays[m[2]])+6)%7), true
+	}},
+	{regexp.MustCompile(`(?i)\b(last\s+)?(monday|tuesday|wednesday|thursday|friday|saturday|sunday)\b`), func(m []string, today time.Time) (time.Time, bool) {
+		return recentWeekday(englishWeekdays[strings.ToLower(m[2])], today, m[1] != ""), true
+	}},
+	{regexp.MustCompile(`(\d{1,2})[日号]`), func(m []string, today time.Time) (time.Time, bool) {
+		return recentMonthDay(0, atoi(m[1]), today)
+	}},
+	{regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)\b`), func(m []string, today time.Time) (time.Time, bool) {
+		return recentMonthDay(0, atoi(m[1]), today)
+	}},
+}
+
+// extractDate 取出文本中的第一个日期表达式，返回日期和去掉该表达式后的文本。
+// 数值无效的表达式（如 2月30日）整体跳过，不再让后面较模糊的规则匹配其中的一部分
+func extractDate(text string, today time.Time) (*time.Time, string) {
+	search := text
+	for _, rule := range dateRules {
+		for _, loc := range rule.pattern.FindAllStringSubmatchIndex(search, -1) {
+			match := make([]string, len(loc)/2)
+			for i := range match {
+				if loc[2*i] >= 0 {
+					match[i] = search[loc[2*i]:loc[2*i+1]]
+				}
+			}
+			if date, ok := rule.resolve(match, today); ok {
+				return &date, text[:loc[0]] + " " + text[loc[1]:]
+			}
+			search = search[:loc[0]] + strings.Repeat(" ", loc[1]-loc[0]) + search[loc[1]:]
+		}
+	}
+	return nil, text
+}
+
+// makeDate 构造日期，月份或日期超出范围时返回 false
+func makeDate(year int, month time.Month, day int, today time.Time) (time.Time, bool) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if date.Year() != year || date.Month() != month || date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// recentMonthDay 不晚于今天的最近一个某月某日；month 为 0 时表示本月或上月的某日
+func recentMonthDay(month time.Month, day int, today time.Time) (time.Time, bool) {
+	if month == 0 {
+		for offset := 0; offset < 12; offset++ {
+			first := time.Date(today.Year(), today.Month()-time.Month(offset), 1, 0, 0, 0, 0, today.Location())
+			if date, ok := makeDate(first.Year(), first.Month(), day, today); ok && !date.After(today) {
+				return date, true
+			}
+		}
+		return time.Time{}, false
+	}
+	date, ok := makeDate(today.Year(), month, day, today)
+	if ok && date.After(today) {
+		date, ok = makeDate(today.Year()-1, month, day, today)
+	}
+	return date, ok
+}
+
+// recentWeekday 不晚于今天的最近一个星期X，strict 为 true 时不含今天
+func recentWeekday(weekday time.Weekday, today time.Time, strict bool) time.Time {
+	days := (int(today.Weekday()) - int(weekday) + 7) % 7
+	if days == 0 && strict {
+		days = 7
+	}
+	return today.AddDate(0, 0, -days)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,62 @@
+package quickentry
+
+import "strings"
+
+// accountTypeAliases 可直接指代账户类型的词，用户只有一个该类型账户时按类型匹配
+var accountTypeAliases = map[string]string{
+	"微信": "wechat", "wechat": "wechat", "weixin": "wechat",
+	"支付宝": "alipay", "alipay": "alipay",
+	"现金": "cash", "cash": "cash",
+	"信用卡": "credit", "credit": "credit", "花呗": "credit",
+	"银行卡": "bank", "储蓄卡": "bank", "bank": "bank", "card": "bank",
+}
+
+// bankAliases 常见银行的英文缩写及其中文简称
+var bankAliases = map[string]string{
+	"icbc": "工商", "cmb": "招商", "ccb": "建设", "abc": "农业", "boc": "中国银行",
+	"bocom": "交通", "psbc": "邮储", "cib": "兴业", "citic": "中信", "cmbc": "民生",
+	"ceb": "光大", "spdb": "浦发", "pab": "平安", "gdb": "广发",
+}
+
+// 名称匹配得分
+const (
+	ScoreExact   = 1.0 // 与名称完全相同（忽略大小写）
+	ScoreContain = 0.8 // 名称包含该词或该词包含名称
+	ScoreAlias   = 0.7 // 通过银行缩写或账户类型别名匹配
+)
+
+// NameScore 计算词与账户或分类名称的匹配得分，不匹配时返回 0。
+// 单个汉字或两个以内的英文字母只接受完全相同，避免“饭”匹配到“饭卡充值”这类误判
+func NameScore(word, name string) float64 {
+	word, name = strings.ToLower(strings.TrimSpace(word)), strings.ToLower(strings.TrimSpace(name))
+	if word == "" || name == "" {
+		return 0
+	}
+	if word == name {
+		return ScoreExact
+	}
+	if len([]rune(word)) < 2 || len(word) <= 2 {
+		return 0
+	}
+	if strings.Contains(name, word) || strings.Contains(word, name) {
+		return ScoreContain
+	}
+	return 0
+}
+
+// AccountScore 计算词与账户的匹配得分，名称之外还识别银行缩写（ICBC -> 工商银行）
+func AccountScore(word, accountName string) float64 {
+	if score := NameScore(word, accountName); score > 0 {
+		return score
+	}
+	if bank, ok := bankAliases[strings.ToLower(word)]; ok && strings.Contains(accountName, bank) {
+		return ScoreAlias
+	}
+	return 0
+}
+
+// AccountTypeAlias 返回词指代的账户类型，如“微信” -> wechat
+func AccountTypeAlias(word string) (string, bool) {
+	accountType, ok := accountTypeAliases[strings.ToLower(word)]
+	return accountType, ok
+}
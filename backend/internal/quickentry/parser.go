@@ -0,0 +1,154 @@
+package quickentry
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// 交易类型
+const (
+	TypeExpense  = "expense"
+	TypeIncome   = "income"
+	TypeTransfer = "transfer"
+)
+
+// 词在句中的角色
+const (
+	RoleFrom = "from" // 前面有“从/from”，表示转出账户
+	RoleTo   = "to"   // 前面有“到/to”，表示转入或收款账户
+)
+
+// Entry 从一句话中解析出的交易要素，账户和分类由调用方按剩余的词匹配
+type Entry struct {
+	Amount      money.Money
+	AmountCount int        // 识别到的金额个数，多于1个时优先取带货币符号的，其次取最大的
+	Date        *time.Time // 未识别到日期时为 nil
+	Type        string     // 关键词表明的交易类型，未识别到时为空
+	Words       []Word     // 去掉金额、日期和连接词后剩余的词，按原文顺序
+}
+
+// Word 剩余的词
+type Word struct {
+	Text string
+	Role string
+}
+
+// amountPattern 金额：可带货币符号或单位，如 ¥35、35.5元、12000rmb
+var amountPattern = regexp.MustCompile(`(?i)([¥￥$]\s*)?(\d+(?:\.\d{1,2})?)(?:\s*(元|块钱|块|rmb|yuan|cny))?`)
+
+var separatorPattern = regexp.MustCompile(`[\s,，。;；、!！?？:：]+`)
+
+// infixPattern 中文转账常把连接词夹在两个账户名中间，如“招行转到支付宝”
+var infixPattern = regexp.MustCompile(`转到|转入`)
+
+// connectors 连接词及其为下一个词设置的角色，空角色表示直接忽略
+var connectors = map[string]string{
+	"to": RoleTo, "into": RoleTo, "到": RoleTo, "转到": RoleTo, "转入": RoleTo, "->": RoleTo, "→": RoleTo,
+	"from": RoleFrom, "从": RoleFrom,
+	"on": "", "at": "", "in": "", "via": "", "with": "", "using": "", "by": "", "for": "", "the": "", "of": "", "paid": "",
+	"用": "", "在": "", "花了": "", "付": "", "付款": "", "支付": "",
+}
+
+// prefixConnectors 中文连接词常与账户名连写，如“到支付宝”“用微信”
+var prefixConnectors = []string{"转到", "转入", "到", "从", "用"}
+
+// typeKeywords 表明交易类型的关键词，英文按整词、中文按包含匹配
+var typeKeywords = map[string]string{
+	"salary": TypeIncome, "income": TypeIncome, "bonus": TypeIncome, "refund": TypeIncome, "received": TypeIncome,
+	"wage": TypeIncome, "wages": TypeIncome, "paycheck": TypeIncome, "interest": TypeIncome, "dividend": TypeIncome,
+	"工资": TypeIncome, "薪水": TypeIncome, "收入": TypeIncome, "奖金": TypeIncome, "收到": TypeIncome,
+	"报销": TypeIncome, "退款": TypeIncome, "利息": TypeIncome, "分红": TypeIncome,
+	"transfer": TypeTransfer, "转账": TypeTransfer,
+}
+
+// Parse 解析一句话，today 用于计算“昨天”“周三”“25号”等相对日期
+func Parse(text string, today time.Time) *Entry {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	entry := &Entry{}
+	entry.Date, text = extractDate(text, today)
+	text = entry.extractAmount(text)
+	text = infixPattern.ReplaceAllString(text, " $0 ")
+
+	role := ""
+	for _, segment := range separatorPattern.Split(text, -1) {
+		if segment == "" {
+			continue
+		}
+		lower := strings.ToLower(segment)
+		if next, ok := connectors[lower]; ok {
+			if next != "" {
+				role = next
+			}
+			continue
+		}
+		for _, prefix := range prefixConnectors {
+			if strings.HasPrefix(segment, prefix) && len(segment) > len(prefix) {
+				segment = segment[len(prefix):]
+				role = connectors[prefix]
+				break
+			}
+		}
+
+		if entry.Type == "" {
+			entry.Type = keywordType(lower)
+		}
+		entry.Words = append(entry.Words, Word{Text: segment, Role: role})
+		role = ""
+	}
+	return entry
+}
+
+// extractAmount 识别金额并从文本中去掉，与英文字母相连的数字（如 iPhone15、7-11 中的 11）不视为金额
+func (e *Entry) extractAmount(text string) string {
+	var chosen []int
+	chosenMarked := false
+	for _, loc := range amountPattern.FindAllStringSubmatchIndex(text, -1) {
+		if adjacentLetter(text, loc[0], loc[1]) {
+			continue
+		}
+		amount, err := money.Parse(text[loc[4]:loc[5]])
+		if err != nil || amount <= 0 {
+			continue
+		}
+		e.AmountCount++
+		marked := loc[2] >= 0 || loc[6] >= 0
+		if chosen == nil || (marked && !chosenMarked) || (marked == chosenMarked && amount > e.Amount) {
+			chosen = loc
+			chosenMarked = marked
+			e.Amount = amount
+		}
+	}
+	if chosen == nil {
+		return text
+	}
+	return text[:chosen[0]] + " " + text[chosen[1]:]
+}
+
+// adjacentLetter 判断匹配前后是否紧邻英文字母或连字符
+func adjacentLetter(text string, start, end int) bool {
+	if start > 0 && (isASCIILetter(text[start-1]) || text[start-1] == '-') {
+		return true
+	}
+	return end < len(text) && isASCIILetter(text[end])
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// keywordType 判断词是否表明交易类型
+func keywordType(word string) string {
+	if transType, ok := typeKeywords[word]; ok {
+		return transType
+	}
+	for keyword, transType := range typeKeywords {
+		if keyword[0] >= utf8.RuneSelf && strings.Contains(word, keyword) {
+			return transType
+		}
+	}
+	return ""
+}
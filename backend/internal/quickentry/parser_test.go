@@ -0,0 +1,102 @@
+package quickentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+// 2024-03-13 是星期三
+var today = time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// TestExtractDate 测试中英文日期表达式，未指明月份的日期取不晚于今天的最近一次
+func TestExtractDate(t *testing.T) {
+	cases := map[string]time.Time{
+		"午饭 昨天":               day(2024, 3, 12),
+		"前天打车":                day(2024, 3, 11),
+		"yesterday lunch":     day(2024, 3, 12),
+		"3天前":                 day(2024, 3, 10),
+		"2 days ago":          day(2024, 3, 11),
+		"2023-12-30 年会":       day(2023, 12, 30),
+		"2月29日":               day(2024, 2, 29),
+		"5月1日":                day(2023, 5, 1),
+		"Mar 5th":             day(2024, 3, 5),
+		"25 of december":      day(2023, 12, 25),
+		"3/1":                 day(2024, 3, 1),
+		"周一":                  day(2024, 3, 11),
+		"上周五":                 day(2024, 3, 8),
+		"friday":              day(2024, 3, 8),
+		"last wednesday":      day(2024, 3, 6),
+		"12号":                 day(2024, 3, 12),
+		"salary to ICBC 25th": day(2024, 2, 25),
+	}
+	for text, want := range cases {
+		date, _ := extractDate(text, day(2024, 3, 13))
+		if assert.NotNil(t, date, text) {
+			assert.Equal(t, want, *date, text)
+		}
+	}
+
+	date, rest := extractDate("market 30 元", day(2024, 3, 13))
+	assert.Nil(t, date)
+	assert.Equal(t, "market 30 元", rest)
+
+	// 无效日期不识别
+	date, _ = extractDate("2月30日", day(2024, 3, 13))
+	assert.Nil(t, date)
+}
+
+// TestParse 测试提取金额、日期、类型关键词和带“从/到”角色的词
+func TestParse(t *testing.T) {
+	entry := Parse("午饭 35 微信 昨天", today)
+	assert.Equal(t, money.MustParse("35"), entry.Amount)
+	assert.Equal(t, 1, entry.AmountCount)
+	assert.Equal(t, day(2024, 3, 12), *entry.Date)
+	assert.Empty(t, entry.Type)
+	assert.Equal(t, []Word{{Text: "午饭"}, {Text: "微信"}}, entry.Words)
+
+	entry = Parse("salary 12000 to ICBC 25th", today)
+	assert.Equal(t, money.MustParse("12000"), entry.Amount)
+	assert.Equal(t, day(2024, 2, 25), *entry.Date)
+	assert.Equal(t, TypeIncome, entry.Type)
+	assert.Equal(t, []Word{{Text: "salary"}, {Text: "ICBC", Role: RoleTo}}, entry.Words)
+
+	entry = Parse("从招行转到支付宝500", today)
+	assert.Equal(t, money.MustParse("500"), entry.Amount)
+	assert.Nil(t, entry.Date)
+	assert.Equal(t, []Word{{Text: "招行", Role: RoleFrom}, {Text: "支付宝", Role: RoleTo}}, entry.Words)
+
+	entry = Parse("转账 从 招行 到 支付宝 500", today)
+	assert.Equal(t, TypeTransfer, entry.Type)
+	assert.Equal(t, []Word{{Text: "转账"}, {Text: "招行", Role: RoleFrom}, {Text: "支付宝", Role: RoleTo}}, entry.Words)
+
+	// 带货币符号的金额优先，与字母相连的数字不是金额
+	entry = Parse("iPhone15 手机壳 2个 ¥39.9", today)
+	assert.Equal(t, money.MustParse("39.90"), entry.Amount)
+	assert.Equal(t, 2, entry.AmountCount)
+
+	entry = Parse("咖啡", today)
+	assert.Equal(t, 0, entry.AmountCount)
+	assert.Equal(t, money.Money(0), entry.Amount)
+}
+
+// TestNameScore 测试名称匹配、银行缩写和账户类型别名
+func TestNameScore(t *testing.T) {
+	assert.Equal(t, ScoreExact, NameScore("微信", "微信"))
+	assert.Equal(t, ScoreExact, NameScore("Cash", "cash"))
+	assert.Equal(t, ScoreContain, NameScore("招行", "招行信用卡"))
+	assert.Equal(t, ScoreContain, NameScore("早餐咖啡", "咖啡"))
+	assert.Equal(t, 0.0, NameScore("饭", "饭卡充值"))
+	assert.Equal(t, ScoreAlias, AccountScore("ICBC", "工商银行储蓄卡"))
+	assert.Equal(t, 0.0, AccountScore("ICBC", "招商银行"))
+
+	accountType, ok := AccountTypeAlias("WeChat")
+	assert.True(t, ok)
+	assert.Equal(t, "wechat", accountType)
+}
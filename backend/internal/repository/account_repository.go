@@ -3,34 +3,54 @@ package repository
 import (
 	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/pkg/database"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AccountRepository 账户仓库接口
 type AccountRepository interface {
 	Create(account *models.Account) error
 	FindByID(id int64) (*models.Account, error)
+	FindByIDForUpdate(id int64) (*models.Account, error)
 	FindByUserID(userID int64) ([]*models.Account, error)
+	FindCreditAccounts() ([]*models.Account, error)
+	FindAll() ([]*models.Account, error)
 	Update(account *models.Account) error
 	Delete(id int64) error
-	GetTotalBalance(userID int64) (float64, error)
+	GetTotalBalance(userID int64) (money.Money, error)
 }
 
-type accountRepository struct{}
+type accountRepository struct {
+	db *gorm.DB
+}
 
 // NewAccountRepository 创建账户仓库实例
 func NewAccountRepository() AccountRepository {
-	return &accountRepository{}
+	return &accountRepository{
+		db: database.GetDB(),
+	}
 }
 
 // Create 创建账户
 func (r *accountRepository) Create(account *models.Account) error {
-	return database.DB.Create(account).Error
+	return r.db.Create(account).Error
 }
 
 // FindByID 根据ID查找账户
 func (r *accountRepository) FindByID(id int64) (*models.Account, error) {
 	var account models.Account
-	err := database.DB.Where("id = ?", id).First(&account).Error
+	err := r.db.Where("id = ?", id).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindByIDForUpdate 根据ID查找账户并加行锁（SELECT ... FOR UPDATE），需在事务中调用
+func (r *accountRepository) FindByIDForUpdate(id int64) (*models.Account, error) {
+	var account models.Account
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&account).Error
 	if err != nil {
 		return nil, err
 	}
@@ -40,15 +60,31 @@ func (r *accountRepository) FindByID(id int64) (*models.Account, error) {
 // FindByUserID 根据用户ID查找账户列表
 func (r *accountRepository) FindByUserID(userID int64) ([]*models.Account, error) {
 	var accounts []*models.Account
-	err := database.DB.Where("user_id = ? AND is_active = ?", userID, true).
+	err := r.db.Where("user_id = ? AND is_active = ?", userID, true).
 		Order("display_order ASC, created_at ASC").
 		Find(&accounts).Error
 	return accounts, err
 }
 
+// FindCreditAccounts 查找所有已设置账单日和还款日的启用信用账户，用于生成信用账单
+func (r *accountRepository) FindCreditAccounts() ([]*models.Account, error) {
+	var accounts []*models.Account
+	err := r.db.Where("account_type = ? AND is_active = ? AND billing_day IS NOT NULL AND payment_due_day IS NOT NULL", models.AccountTypeCredit, true).
+		Order("id ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+// FindAll 查找全部账户（含已停用的），用于生成余额快照
+func (r *accountRepository) FindAll() ([]*models.Account, error) {
+	var accounts []*models.Account
+	err := r.db.Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
 // Update 更新账户
 func (r *accountRepository) Update(account *models.Account) error {
-	return database.DB.Save(account).Error
+	return r.db.Save(account).Error
 }
 
 // Delete 删除账户（软删除）
@@ -56,13 +92,13 @@ func (r *accountRepository) Delete(id int64) error {
 	// 实际项目中可能使用软删除字段，这里根据需求使用GORM的Delete
 	// 如果模型中有DeletedAt字段，GORM会自动进行软删除
 	// 这里我们使用IsActive字段进行逻辑删除
-	return database.DB.Model(&models.Account{}).Where("id = ?", id).Update("is_active", false).Error
+	return r.db.Model(&models.Account{}).Where("id = ?", id).Update("is_active", false).Error
 }
 
 // GetTotalBalance 获取用户总资产（仅统计include_in_total=true的账户）
-func (r *accountRepository) GetTotalBalance(userID int64) (float64, error) {
-	var total float64
-	err := database.DB.Model(&models.Account{}).
+func (r *accountRepository) GetTotalBalance(userID int64) (money.Money, error) {
+	var total money.Money
+	err := r.db.Model(&models.Account{}).
 		Where("user_id = ? AND is_active = ? AND include_in_total = ?", userID, true, true).
 		Select("COALESCE(SUM(balance), 0)").
 		Scan(&total).Error
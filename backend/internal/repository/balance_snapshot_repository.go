@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// BalanceSnapshotRepository 账户余额快照仓库接口
+type BalanceSnapshotRepository interface {
+	ReplaceForAccount(accountID int64, snapshots []*models.AccountBalanceSnapshot) error
+	FindByUserID(userID int64, until time.Time) ([]*models.AccountBalanceSnapshot, error)
+	FindBuiltVersions(accountIDs []int64) (map[int64]time.Time, error)
+}
+
+type balanceSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewBalanceSnapshotRepository 创建账户余额快照仓库实例
+func NewBalanceSnapshotRepository() BalanceSnapshotRepository {
+	return &balanceSnapshotRepository{
+		db: database.GetDB(),
+	}
+}
+
+// ReplaceForAccount 在同一事务中删除账户的全部快照并写入重建结果
+func (r *balanceSnapshotRepository) ReplaceForAccount(accountID int64, snapshots []*models.AccountBalanceSnapshot) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("account_id = ?", accountID).Delete(&models.AccountBalanceSnapshot{}).Error; err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(snapshots, 500).Error
+	})
+}
+
+// FindByUserID 查询用户不晚于指定日期的全部快照，按账户和日期升序
+func (r *balanceSnapshotRepository) FindByUserID(userID int64, until time.Time) ([]*models.AccountBalanceSnapshot, error) {
+	var snapshots []*models.AccountBalanceSnapshot
+	err := r.db.Where("user_id = ? AND snapshot_date <= ?", userID, until.Format("2006-01-02")).
+		Order("account_id ASC, snapshot_date ASC").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// FindBuiltVersions 查询各账户快照生成时的账户更新时间，没有快照的账户不在结果中
+func (r *balanceSnapshotRepository) FindBuiltVersions(accountIDs []int64) (map[int64]time.Time, error) {
+	versions := make(map[int64]time.Time)
+	if len(accountIDs) == 0 {
+		return versions, nil
+	}
+
+	var rows []*models.AccountBalanceSnapshot
+	err := r.db.Model(&models.AccountBalanceSnapshot{}).
+		Select("account_id, MIN(account_updated_at) AS account_updated_at").
+		Where("account_id IN ?", accountIDs).
+		Group("account_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		versions[row.AccountID] = row.AccountUpdatedAt
+	}
+	return versions, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BillRepository 账单仓库接口
+type BillRepository interface {
+	Create(bill *models.Bill) error
+	FindByID(id int64) (*models.Bill, error)
+	FindByIDForUpdate(id int64) (*models.Bill, error)
+	FindByUserID(userID int64, activeOnly bool) ([]*models.Bill, error)
+	FindDueBefore(userID int64, date time.Time) ([]*models.Bill, error)
+	FindReminderDue(date time.Time) ([]*models.Bill, error)
+	Update(bill *models.Bill) error
+	Delete(id int64) error
+	CountActive(userID int64) (int64, error)
+	CreateHistory(history *models.BillHistory) error
+	FindHistoryByBillID(billID int64, limit int) ([]*models.BillHistory, error)
+}
+
+type billRepository struct {
+	db *gorm.DB
+}
+
+// NewBillRepository 创建账单仓库实例
+func NewBillRepository() BillRepository {
+	return &billRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建账单
+func (r *billRepository) Create(bill *models.Bill) error {
+	return r.db.Create(bill).Error
+}
+
+// FindByID 根据ID查找账单
+func (r *billRepository) FindByID(id int64) (*models.Bill, error) {
+	var bill models.Bill
+	err := r.db.
+		Preload("Category").
+		Preload("Account").
+		Where("id = ?", id).
+		First(&bill).Error
+	if err != nil {
+		return nil, err
+	}
+	return &bill, nil
+}
+
+// FindByIDForUpdate 根据ID查找账单并加行锁，需在事务中调用
+func (r *billRepository) FindByIDForUpdate(id int64) (*models.Bill, error) {
+	var bill models.Bill
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&bill).Error
+	if err != nil {
+		return nil, err
+	}
+	return &bill, nil
+}
+
+// FindByUserID 根据用户ID查找账单列表
+func (r *billRepository) FindByUserID(userID int64, activeOnly bool) ([]*models.Bill, error) {
+	var bills []*models.Bill
+	query := r.db.Where("user_id = ?", userID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.
+		Preload("Category").
+		Preload("Account").
+		Order("next_billing_date ASC, id ASC").
+		Find(&bills).Error
+	return bills, err
+}
+
+// FindDueBefore 查找指定日期（含）之前到期的启用账单，包括已逾期的账单
+func (r *billRepository) FindDueBefore(userID int64, date time.Time) ([]*models.Bill, error) {
+	var bills []*models.Bill
+	err := r.db.
+		Where("user_id = ? AND is_active = ? AND next_billing_date <= ?", userID, true, date.Format("2006-01-02")).
+		Preload("Category").
+		Preload("Account").
+		Order("next_billing_date ASC, id ASC").
+		Find(&bills).Error
+	return bills, err
+}
+
+// FindReminderDue 查找所有用户中已进入提醒窗口（扣费日前remind_days_before天起）的启用账单
+func (r *billRepository) FindReminderDue(date time.Time) ([]*models.Bill, error) {
+	var bills []*models.Bill
+	err := r.db.
+		Where("is_active = ? AND DATE_SUB(next_billing_date, INTERVAL remind_days_before DAY) <= ?", true, date.Format("2006-01-02")).
+		Order("next_billing_date ASC, id ASC").
+		Find(&bills).Error
+	return bills, err
+}
+
+// Update 更新账单
+func (r *billRepository) Update(bill *models.Bill) error {
+	return r.db.Omit("Category", "Account").Save(bill).Error
+}
+
+// Delete 删除账单及其历史记录
+func (r *billRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bill_id = ?", id).Delete(&models.BillHistory{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Bill{}, id).Error
+	})
+}
+
+// CountActive 统计用户启用中的账单数
+func (r *billRepository) CountActive(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Bill{}).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateHistory 创建账单历史记录
+func (r *billRepository) CreateHistory(history *models.BillHistory) error {
+	return r.db.Create(history).Error
+}
+
+// FindHistoryByBillID 查询账单历史记录（按扣费日期倒序）
+func (r *billRepository) FindHistoryByBillID(billID int64, limit int) ([]*models.BillHistory, error) {
+	var histories []*models.BillHistory
+	err := r.db.Where("bill_id = ?", billID).
+		Order("billing_date DESC, id DESC").
+		Limit(limit).
+		Find(&histories).Error
+	return histories, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// BudgetRepository 预算仓库接口
+type BudgetRepository interface {
+	Create(budget *models.Budget) error
+	FindByID(id int64) (*models.Budget, error)
+	FindByUserID(userID int64, activeOnly bool) ([]*models.Budget, error)
+	FindActiveForCategory(userID int64, categoryIDs []int64, date time.Time) ([]*models.Budget, error)
+	Update(budget *models.Budget) error
+	UpdateAlertState(id int64, period time.Time, level int) error
+	Delete(id int64) error
+	CountActive(userID int64) (int64, error)
+}
+
+type budgetRepository struct {
+	db *gorm.DB
+}
+
+// NewBudgetRepository 创建预算仓库实例
+func NewBudgetRepository() BudgetRepository {
+	return &budgetRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建预算
+func (r *budgetRepository) Create(budget *models.Budget) error {
+	return r.db.Create(budget).Error
+}
+
+// FindByID 根据ID查找预算
+func (r *budgetRepository) FindByID(id int64) (*models.Budget, error) {
+	var budget models.Budget
+	if err := r.db.Preload("Category").Where("id = ?", id).First(&budget).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// FindByUserID 根据用户ID查找预算列表
+func (r *budgetRepository) FindByUserID(userID int64, activeOnly bool) ([]*models.Budget, error) {
+	var budgets []*models.Budget
+	query := r.db.Where("user_id = ?", userID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Preload("Category").
+		Order("category_id IS NOT NULL, id ASC").
+		Find(&budgets).Error
+	return budgets, err
+}
+
+// FindActiveForCategory 查找在date生效、且覆盖该分类的预算（总预算与categoryIDs中各分类的预算），
+// categoryIDs 为交易分类及其上级分类
+func (r *budgetRepository) FindActiveForCategory(userID int64, categoryIDs []int64, date time.Time) ([]*models.Budget, error) {
+	var budgets []*models.Budget
+	day := date.Format("2006-01-02")
+	query := r.db.Where("user_id = ? AND is_active = ? AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)", userID, true, day, day)
+	if len(categoryIDs) > 0 {
+		query = query.Where("category_id IS NULL OR category_id IN ?", categoryIDs)
+	} else {
+		query = query.Where("category_id IS NULL")
+	}
+	err := query.Preload("Category").Find(&budgets).Error
+	return budgets, err
+}
+
+// Update 更新预算
+func (r *budgetRepository) Update(budget *models.Budget) error {
+	return r.db.Omit("Category").Save(budget).Error
+}
+
+// UpdateAlertState 记录预算预警状态
+func (r *budgetRepository) UpdateAlertState(id int64, period time.Time, level int) error {
+	return r.db.Model(&models.Budget{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"alert_period": period.Format("2006-01-02"), "alert_level": level}).Error
+}
+
+// Delete 删除预算
+func (r *budgetRepository) Delete(id int64) error {
+	return r.db.Delete(&models.Budget{}, id).Error
+}
+
+// CountActive 统计用户启用中的预算数
+func (r *budgetRepository) CountActive(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Budget{}).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Count(&count).Error
+	return count, err
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CategoryRepository 分类仓库接口
@@ -13,25 +15,35 @@ type CategoryRepository interface {
 	FindAll(userID int64) ([]*models.Category, error)
 	Update(category *models.Category) error
 	Delete(id int64) error
+	FindChildren(parentID int64) ([]*models.Category, error)
+	DeleteAndReassign(id int64, newParentID *int64) error
+	DeleteBatch(ids []int64) error
+	Merge(sourceIDs []int64, targetID int64) (int64, error)
 	GetSystemCategories(categoryType string) ([]*models.Category, error)
+	// WithUserLock 在锁定用户行的事务中执行fn，同一用户调整分类层级的校验与写入串行执行，避免并发移动形成循环
+	WithUserLock(userID int64, fn func(repo CategoryRepository) error) error
 }
 
-type categoryRepository struct{}
+type categoryRepository struct {
+	db *gorm.DB
+}
 
 // NewCategoryRepository 创建分类仓库实例
 func NewCategoryRepository() CategoryRepository {
-	return &categoryRepository{}
+	return &categoryRepository{
+		db: database.GetDB(),
+	}
 }
 
 // Create 创建分类
 func (r *categoryRepository) Create(category *models.Category) error {
-	return database.DB.Create(category).Error
+	return r.db.Create(category).Error
 }
 
 // FindByID 根据ID查找分类
 func (r *categoryRepository) FindByID(id int64) (*models.Category, error) {
 	var category models.Category
-	err := database.DB.Where("id = ?", id).First(&category).Error
+	err := r.db.Where("id = ?", id).First(&category).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +53,7 @@ func (r *categoryRepository) FindByID(id int64) (*models.Category, error) {
 // FindByUserID 根据用户ID和类型查找分类
 func (r *categoryRepository) FindByUserID(userID int64, categoryType string) ([]*models.Category, error) {
 	var categories []*models.Category
-	query := database.DB.Where("user_id = ? AND is_active = ?", userID, true)
+	query := r.db.Where("user_id = ? AND is_active = ?", userID, true)
 
 	if categoryType != "" {
 		query = query.Where("type = ?", categoryType)
@@ -58,18 +70,70 @@ func (r *categoryRepository) FindAll(userID int64) ([]*models.Category, error) {
 
 // Update 更新分类
 func (r *categoryRepository) Update(category *models.Category) error {
-	return database.DB.Save(category).Error
+	return r.db.Save(category).Error
 }
 
 // Delete 删除分类
 func (r *categoryRepository) Delete(id int64) error {
-	return database.DB.Delete(&models.Category{}, id).Error
+	return r.db.Delete(&models.Category{}, id).Error
+}
+
+// FindChildren 查找直接下级分类（含已停用的）
+func (r *categoryRepository) FindChildren(parentID int64) ([]*models.Category, error) {
+	var categories []*models.Category
+	err := r.db.Where("parent_id = ?", parentID).Order("display_order ASC, created_at ASC").Find(&categories).Error
+	return categories, err
+}
+
+// DeleteAndReassign 删除分类，并在同一事务中将其直接下级分类移到 newParentID 下（为空时成为顶级分类）
+func (r *categoryRepository) DeleteAndReassign(id int64, newParentID *int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Update("parent_id", newParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Category{}, id).Error
+	})
+}
+
+// DeleteBatch 批量删除分类，用于连同下级分类一并删除
+func (r *categoryRepository) DeleteBatch(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&models.Category{}).Error
+}
+
+// Merge 在同一事务中将源分类下的交易、拆分明细、周期规则、账单、贷款、预算、交易规则、导入默认分类和子分类移到目标分类，
+// 然后停用源分类，返回移动的交易笔数
+func (r *categoryRepository) Merge(sourceIDs []int64, targetID int64) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Transaction{}).Where("category_id IN ?", sourceIDs).Update("category_id", targetID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		for _, model := range []interface{}{&models.TransactionSplit{}, &models.RecurringRule{}, &models.Bill{}, &models.Loan{}, &models.Budget{}, &models.TransactionRule{}} {
+			if err := tx.Model(model).Where("category_id IN ?", sourceIDs).Update("category_id", targetID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.ImportProfile{}).Where("default_category_id IN ?", sourceIDs).Update("default_category_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Category{}).Where("parent_id IN ?", sourceIDs).Update("parent_id", targetID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Category{}).Where("id IN ?", sourceIDs).Update("is_active", false).Error
+	})
+	return moved, err
 }
 
 // GetSystemCategories 获取系统默认分类
 func (r *categoryRepository) GetSystemCategories(categoryType string) ([]*models.Category, error) {
 	var categories []*models.Category
-	query := database.DB.Where("is_system = ? AND is_active = ?", true, true)
+	query := r.db.Where("is_system = ? AND is_active = ?", true, true)
 
 	if categoryType != "" {
 		query = query.Where("type = ?", categoryType)
@@ -78,3 +142,14 @@ func (r *categoryRepository) GetSystemCategories(categoryType string) ([]*models
 	err := query.Order("display_order ASC").Find(&categories).Error
 	return categories, err
 }
+
+// WithUserLock 在锁定用户行的事务中执行fn
+func (r *categoryRepository) WithUserLock(userID int64, fn func(repo CategoryRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+		return fn(&categoryRepository{db: tx})
+	})
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestMergeRepointsCategoryReferences 测试合并分类时引用源分类的各表都改为目标分类
+func TestMergeRepointsCategoryReferences(t *testing.T) {
+	d := useRecordingDB(t)
+
+	_, err := NewCategoryRepository().Merge([]int64{2, 3}, 1)
+	assert.NoError(t, err)
+
+	for _, table := range []string{"transactions", "transaction_splits", "recurring_rules", "bills", "loans", "budgets", "transaction_rules"} {
+		found := false
+		for _, exec := range d.execs {
+			if strings.HasPrefix(exec, "UPDATE `"+table+"` SET `category_id`") {
+				found = true
+				break
+			}
+		}
+		assert.True(t, found, "%s 未改为目标分类", table)
+	}
+	assert.Equal(t, 1, d.commits)
+}
+
+// TestWithUserLockLocksUserRow 测试调整分类层级前先锁定用户行，用户不存在时不执行fn
+func TestWithUserLockLocksUserRow(t *testing.T) {
+	d := useRecordingDB(t)
+
+	called := false
+	err := NewCategoryRepository().WithUserLock(1, func(repo CategoryRepository) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.False(t, called)
+	if assert.Len(t, d.queries, 1) {
+		assert.Contains(t, d.queries[0], "FROM `users`")
+		assert.Contains(t, d.queries[0], "FOR UPDATE")
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// CreditStatementRepository 信用账单仓库接口
+type CreditStatementRepository interface {
+	Create(statement *models.CreditStatement) error
+	FindByID(id int64) (*models.CreditStatement, error)
+	FindByAccountID(accountID int64, limit int) ([]*models.CreditStatement, error)
+	FindLatest(accountID int64) (*models.CreditStatement, error)
+	Update(statement *models.CreditStatement) error
+}
+
+type creditStatementRepository struct {
+	db *gorm.DB
+}
+
+// NewCreditStatementRepository 创建信用账单仓库实例
+func NewCreditStatementRepository() CreditStatementRepository {
+	return &creditStatementRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建信用账单
+func (r *creditStatementRepository) Create(statement *models.CreditStatement) error {
+	return r.db.Create(statement).Error
+}
+
+// FindByID 根据ID查找信用账单
+func (r *creditStatementRepository) FindByID(id int64) (*models.CreditStatement, error) {
+	var statement models.CreditStatement
+	if err := r.db.Where("id = ?", id).First(&statement).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// FindByAccountID 查询账户的历史账单，按账单日倒序
+func (r *creditStatementRepository) FindByAccountID(accountID int64, limit int) ([]*models.CreditStatement, error) {
+	var statements []*models.CreditStatement
+	err := r.db.Where("account_id = ?", accountID).
+		Order("statement_date DESC").
+		Limit(limit).
+		Find(&statements).Error
+	return statements, err
+}
+
+// FindLatest 查找账户最近一期账单，不存在时返回 nil
+func (r *creditStatementRepository) FindLatest(accountID int64) (*models.CreditStatement, error) {
+	var statements []*models.CreditStatement
+	err := r.db.Where("account_id = ?", accountID).
+		Order("statement_date DESC").
+		Limit(1).
+		Find(&statements).Error
+	if err != nil || len(statements) == 0 {
+		return nil, err
+	}
+	return statements[0], nil
+}
+
+// Update 更新信用账单
+func (r *creditStatementRepository) Update(statement *models.CreditStatement) error {
+	return r.db.Save(statement).Error
+}
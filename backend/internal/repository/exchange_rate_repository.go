@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRateRepository 汇率仓库接口
+type ExchangeRateRepository interface {
+	Create(rate *models.ExchangeRate) error
+	FindByID(id int64) (*models.ExchangeRate, error)
+	FindByUserID(userID int64, currency string, limit int) ([]*models.ExchangeRate, error)
+	FindOne(userID int64, from, to string, date time.Time) (*models.ExchangeRate, error)
+	FindLatest(userID int64, from, to string, date time.Time) (*models.ExchangeRate, error)
+	Update(rate *models.ExchangeRate) error
+	Delete(id int64) error
+	UpsertShared(rates []*models.ExchangeRate) error
+}
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository 创建汇率仓库实例
+func NewExchangeRateRepository() ExchangeRateRepository {
+	return &exchangeRateRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建汇率
+func (r *exchangeRateRepository) Create(rate *models.ExchangeRate) error {
+	return r.db.Create(rate).Error
+}
+
+// FindByID 根据ID查找汇率
+func (r *exchangeRateRepository) FindByID(id int64) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	if err := r.db.Where("id = ?", id).First(&rate).Error; err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// FindByUserID 查询用户可用的汇率（本人录入及数据源同步的公共汇率），可按币种筛选，按生效日期倒序
+func (r *exchangeRateRepository) FindByUserID(userID int64, currency string, limit int) ([]*models.ExchangeRate, error) {
+	query := r.db.Where("user_id IN ?", []int64{0, userID})
+	if currency != "" {
+		query = query.Where("from_currency = ? OR to_currency = ?", currency, currency)
+	}
+
+	var rates []*models.ExchangeRate
+	err := query.Order("rate_date DESC, user_id DESC, id DESC").Limit(limit).Find(&rates).Error
+	return rates, err
+}
+
+// FindOne 查找用户在指定日期录入的某币种对汇率，不存在时返回 nil
+func (r *exchangeRateRepository) FindOne(userID int64, from, to string, date time.Time) (*models.ExchangeRate, error) {
+	var rates []*models.ExchangeRate
+	err := r.db.Where("user_id = ? AND from_currency = ? AND to_currency = ? AND rate_date = ?", userID, from, to, date.Format("2006-01-02")).
+		Limit(1).
+		Find(&rates).Error
+	if err != nil || len(rates) == 0 {
+		return nil, err
+	}
+	return rates[0], nil
+}
+
+// FindLatest 查找不晚于指定日期的最近一条汇率，同一天用户手工录入的优先于公共汇率，不存在时返回 nil
+func (r *exchangeRateRepository) FindLatest(userID int64, from, to string, date time.Time) (*models.ExchangeRate, error) {
+	var rates []*models.ExchangeRate
+	err := r.db.Where("user_id IN ? AND from_currency = ? AND to_currency = ? AND rate_date <= ?", []int64{0, userID}, from, to, date.Format("2006-01-02")).
+		Order("rate_date DESC, user_id DESC").
+		Limit(1).
+		Find(&rates).Error
+	if err != nil || len(rates) == 0 {
+		return nil, err
+	}
+	return rates[0], nil
+}
+
+// Update 更新汇率
+func (r *exchangeRateRepository) Update(rate *models.ExchangeRate) error {
+	return r.db.Save(rate).Error
+}
+
+// Delete 删除汇率
+func (r *exchangeRateRepository) Delete(id int64) error {
+	return r.db.Delete(&models.ExchangeRate{}, id).Error
+}
+
+// UpsertShared 批量写入数据源同步的公共汇率，同一币种对同一天已存在时覆盖
+func (r *exchangeRateRepository) UpsertShared(rates []*models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "from_currency"}, {Name: "to_currency"}, {Name: "rate_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(rates, 200).Error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// ExportRepository 导出任务仓库接口
+type ExportRepository interface {
+	Create(job *models.ExportJob) error
+	FindByID(id int64) (*models.ExportJob, error)
+	FindByToken(token string) (*models.ExportJob, error)
+	FindByUserID(userID int64, limit int) ([]*models.ExportJob, error)
+	FindByStatus(status string, createdBefore time.Time, limit int) ([]*models.ExportJob, error)
+	FindExpired(now time.Time, limit int) ([]*models.ExportJob, error)
+	CountActive(userID int64) (int64, error)
+	Update(job *models.ExportJob) error
+	Delete(id int64) error
+	TransitionStatus(id int64, from, to string) (bool, error)
+}
+
+type exportRepository struct {
+	db *gorm.DB
+}
+
+// NewExportRepository 创建导出任务仓库实例
+func NewExportRepository() ExportRepository {
+	return &exportRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建导出任务
+func (r *exportRepository) Create(job *models.ExportJob) error {
+	return r.db.Create(job).Error
+}
+
+// FindByID 根据ID查找导出任务
+func (r *exportRepository) FindByID(id int64) (*models.ExportJob, error) {
+	var job models.ExportJob
+	if err := r.db.Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindByToken 根据下载令牌查找导出任务
+func (r *exportRepository) FindByToken(token string) (*models.ExportJob, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+	var job models.ExportJob
+	if err := r.db.Where("download_token = ?", token).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindByUserID 查找用户最近的导出任务
+func (r *exportRepository) FindByUserID(userID int64, limit int) ([]*models.ExportJob, error) {
+	var jobs []*models.ExportJob
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// FindByStatus 查找在某时间之前创建且处于指定状态的导出任务，按创建先后排序
+func (r *exportRepository) FindByStatus(status string, createdBefore time.Time, limit int) ([]*models.ExportJob, error) {
+	var jobs []*models.ExportJob
+	err := r.db.Where("status = ? AND created_at <= ?", status, createdBefore).Order("id ASC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// FindExpired 查找文件已过期但仍未清理的任务
+func (r *exportRepository) FindExpired(now time.Time, limit int) ([]*models.ExportJob, error) {
+	var jobs []*models.ExportJob
+	err := r.db.Where("status = ? AND expires_at <= ?", models.ExportStatusCompleted, now).
+		Order("id ASC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// CountActive 统计用户排队中和处理中的任务数
+func (r *exportRepository) CountActive(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ExportJob{}).
+		Where("user_id = ? AND status IN ?", userID, []string{models.ExportStatusPending, models.ExportStatusProcessing}).
+		Count(&count).Error
+	return count, err
+}
+
+// Update 更新导出任务
+func (r *exportRepository) Update(job *models.ExportJob) error {
+	return r.db.Save(job).Error
+}
+
+// Delete 删除导出任务
+func (r *exportRepository) Delete(id int64) error {
+	return r.db.Delete(&models.ExportJob{}, id).Error
+}
+
+// TransitionStatus 仅当任务处于from状态时切换为to状态，返回是否切换成功
+// 用于保证同一任务只被一个后台任务处理
+func (r *exportRepository) TransitionStatus(id int64, from, to string) (bool, error) {
+	result := r.db.Model(&models.ExportJob{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected == 1, result.Error
+}
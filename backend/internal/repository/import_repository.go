@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// ImportRepository 导入仓库接口（列映射配置与导入批次）
+type ImportRepository interface {
+	CreateProfile(profile *models.ImportProfile) error
+	FindProfileByID(id int64) (*models.ImportProfile, error)
+	FindProfilesByUserID(userID int64) ([]*models.ImportProfile, error)
+	UpdateProfile(profile *models.ImportProfile) error
+	DeleteProfile(id int64) error
+	CreateBatch(batch *models.ImportBatch) error
+	FindBatchByID(id int64) (*models.ImportBatch, error)
+	FindBatchesByUserID(userID int64, limit int) ([]*models.ImportBatch, error)
+	FindBatchesByStatus(status string, updatedBefore time.Time, limit int) ([]*models.ImportBatch, error)
+	UpdateProgress(batch *models.ImportBatch) (bool, error)
+	FinishBatch(batch *models.ImportBatch, from string) (bool, error)
+	TransitionStatus(id int64, from, to string) (bool, error)
+}
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+// NewImportRepository 创建导入仓库实例
+func NewImportRepository() ImportRepository {
+	return &importRepository{
+		db: database.GetDB(),
+	}
+}
+
+// CreateProfile 创建列映射配置
+func (r *importRepository) CreateProfile(profile *models.ImportProfile) error {
+	return r.db.Create(profile).Error
+}
+
+// FindProfileByID 根据ID查找列映射配置
+func (r *importRepository) FindProfileByID(id int64) (*models.ImportProfile, error) {
+	var profile models.ImportProfile
+	if err := r.db.Where("id = ?", id).First(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// FindProfilesByUserID 查询用户的列映射配置
+func (r *importRepository) FindProfilesByUserID(userID int64) ([]*models.ImportProfile, error) {
+	var profiles []*models.ImportProfile
+	err := r.db.Where("user_id = ?", userID).Order("updated_at DESC, id DESC").Find(&profiles).Error
+	return profiles, err
+}
+
+// UpdateProfile 更新列映射配置
+func (r *importRepository) UpdateProfile(profile *models.ImportProfile) error {
+	return r.db.Save(profile).Error
+}
+
+// DeleteProfile 删除列映射配置
+func (r *importRepository) DeleteProfile(id int64) error {
+	return r.db.Delete(&models.ImportProfile{}, id).Error
+}
+
+// CreateBatch 创建导入批次
+func (r *importRepository) CreateBatch(batch *models.ImportBatch) error {
+	return r.db.Create(batch).Error
+}
+
+// FindBatchByID 根据ID查找导入批次
+func (r *importRepository) FindBatchByID(id int64) (*models.ImportBatch, error) {
+	var batch models.ImportBatch
+	if err := r.db.Where("id = ?", id).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// FindBatchesByUserID 查询用户最近的导入批次（不加载预览数据）
+func (r *importRepository) FindBatchesByUserID(userID int64, limit int) ([]*models.ImportBatch, error) {
+	var batches []*models.ImportBatch
+	err := r.db.Omit("payload").
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&batches).Error
+	return batches, err
+}
+
+// FindBatchesByStatus 查找在某时间之后未再更新且处于指定状态的导入批次（不加载预览数据），按更新先后排序
+func (r *importRepository) FindBatchesByStatus(status string, updatedBefore time.Time, limit int) ([]*models.ImportBatch, error) {
+	var batches []*models.ImportBatch
+	err := r.db.Omit("payload").
+		Where("status = ? AND updated_at < ?", status, updatedBefore).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&batches).Error
+	return batches, err
+}
+
+// UpdateProgress 批次仍在处理中时写入已处理的行数并刷新更新时间，返回批次是否仍在处理中
+func (r *importRepository) UpdateProgress(batch *models.ImportBatch) (bool, error) {
+	result := r.db.Model(&models.ImportBatch{}).
+		Where("id = ? AND status = ?", batch.ID, models.ImportStatusProcessing).
+		Updates(map[string]interface{}{
+			"imported_rows": batch.ImportedRows,
+			"skipped_rows":  batch.SkippedRows,
+			"failed_rows":   batch.FailedRows,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// FinishBatch 仅当批次处于from状态时写入批次的全部字段（含最终状态），返回是否写入成功
+// 用于防止处理结束时覆盖已被标记为中断或已回滚的批次
+func (r *importRepository) FinishBatch(batch *models.ImportBatch, from string) (bool, error) {
+	result := r.db.Model(&models.ImportBatch{}).
+		Where("id = ? AND status = ?", batch.ID, from).
+		Select("*").Omit("id", "created_at").
+		Updates(batch)
+	return result.RowsAffected == 1, result.Error
+}
+
+// TransitionStatus 仅当批次处于from状态时切换为to状态，返回是否切换成功
+// 用于防止同一批次被并发重复提交
+func (r *importRepository) TransitionStatus(id int64, from, to string) (bool, error) {
+	result := r.db.Model(&models.ImportBatch{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestImportBatchWritesAreConditional 测试进度刷新和结束写入都以批次当前状态为条件，不会覆盖已中断或已回滚的批次
+func TestImportBatchWritesAreConditional(t *testing.T) {
+	d := useRecordingDB(t)
+	repo := NewImportRepository()
+	batch := &models.ImportBatch{ID: 12, UserID: 1, Status: models.ImportStatusCompleted, ImportedRows: 3}
+
+	processing, err := repo.UpdateProgress(batch)
+	assert.NoError(t, err)
+	assert.False(t, processing)
+	finished, err := repo.FinishBatch(batch, models.ImportStatusProcessing)
+	assert.NoError(t, err)
+	assert.False(t, finished)
+
+	if assert.Len(t, d.execs, 2) {
+		assert.Contains(t, d.execs[0], "`imported_rows`=?")
+		assert.Contains(t, d.execs[0], "`updated_at`=?")
+		assert.Contains(t, d.execs[0], "id = ? AND status = ?")
+		assert.NotContains(t, d.execs[0], "`status`=?")
+		assert.Contains(t, d.execs[1], "`status`=?")
+		assert.Contains(t, d.execs[1], "id = ? AND status = ?")
+		assert.NotContains(t, d.execs[1], "`created_at`=?")
+	}
+}
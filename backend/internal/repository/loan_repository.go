@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoanRepository 贷款/分期仓库接口
+type LoanRepository interface {
+	Create(loan *models.Loan) error
+	FindByID(id int64) (*models.Loan, error)
+	FindByIDForUpdate(id int64) (*models.Loan, error)
+	FindByUserID(userID int64) ([]*models.Loan, error)
+	FindDue(date time.Time) ([]*models.Loan, error)
+	FindInstallment(loanID int64, period int) (*models.LoanInstallment, error)
+	FindPendingInstallments(userID int64, until time.Time) ([]*models.LoanInstallment, error)
+	Update(loan *models.Loan) error
+	UpdateInstallment(installment *models.LoanInstallment) error
+	Delete(id int64) error
+}
+
+type loanRepository struct {
+	db *gorm.DB
+}
+
+// NewLoanRepository 创建贷款仓库实例
+func NewLoanRepository() LoanRepository {
+	return &loanRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建贷款及其还款计划
+func (r *loanRepository) Create(loan *models.Loan) error {
+	return r.db.Create(loan).Error
+}
+
+// FindByID 根据ID查找贷款，包含按期数排序的还款计划
+func (r *loanRepository) FindByID(id int64) (*models.Loan, error) {
+	var loan models.Loan
+	err := r.db.Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("period ASC")
+	}).Where("id = ?", id).First(&loan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// FindByIDForUpdate 根据ID查找贷款并加行锁，需在事务中调用
+func (r *loanRepository) FindByIDForUpdate(id int64) (*models.Loan, error) {
+	var loan models.Loan
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&loan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// FindByUserID 查询用户的贷款列表（不含还款计划），进行中的排在前面
+func (r *loanRepository) FindByUserID(userID int64) ([]*models.Loan, error) {
+	var loans []*models.Loan
+	err := r.db.Where("user_id = ?", userID).
+		Order("status = 'active' DESC, next_due_date ASC, id DESC").
+		Find(&loans).Error
+	return loans, err
+}
+
+// FindDue 查找下期还款日不晚于指定日期的进行中贷款
+func (r *loanRepository) FindDue(date time.Time) ([]*models.Loan, error) {
+	var loans []*models.Loan
+	err := r.db.Where("status = ? AND next_due_date IS NOT NULL AND next_due_date <= ?", models.LoanStatusActive, date.Format("2006-01-02")).
+		Order("next_due_date ASC, id ASC").
+		Find(&loans).Error
+	return loans, err
+}
+
+// FindInstallment 查找贷款某一期的还款计划
+func (r *loanRepository) FindInstallment(loanID int64, period int) (*models.LoanInstallment, error) {
+	var installment models.LoanInstallment
+	if err := r.db.Where("loan_id = ? AND period = ?", loanID, period).First(&installment).Error; err != nil {
+		return nil, err
+	}
+	return &installment, nil
+}
+
+// FindPendingInstallments 查询用户进行中贷款在指定日期前（含）待还的各期，按还款日排序
+func (r *loanRepository) FindPendingInstallments(userID int64, until time.Time) ([]*models.LoanInstallment, error) {
+	var installments []*models.LoanInstallment
+	err := r.db.Joins("JOIN loans ON loans.id = loan_installments.loan_id").
+		Where("loans.user_id = ? AND loans.status = ? AND loan_installments.status = ? AND loan_installments.due_date <= ?",
+			userID, models.LoanStatusActive, models.InstallmentPending, until.Format("2006-01-02")).
+		Order("loan_installments.due_date ASC, loan_installments.loan_id ASC").
+		Find(&installments).Error
+	return installments, err
+}
+
+// Update 更新贷款
+func (r *loanRepository) Update(loan *models.Loan) error {
+	return r.db.Omit("Installments").Save(loan).Error
+}
+
+// UpdateInstallment 更新单期还款计划
+func (r *loanRepository) UpdateInstallment(installment *models.LoanInstallment) error {
+	return r.db.Save(installment).Error
+}
+
+// Delete 删除贷款及其还款计划，已入账的交易保留
+func (r *loanRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("loan_id = ?", id).Delete(&models.LoanInstallment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Loan{}, id).Error
+	})
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository 通知仓库接口
+type NotificationRepository interface {
+	Create(notification *models.Notification) error
+	CreateForAllUsers(notification *models.Notification) (int64, error)
+	FindByUserID(userID int64, unreadOnly bool, page, pageSize int) ([]*models.Notification, int64, error)
+	ExistsSince(userID int64, notificationType, relatedType string, relatedID int64, since time.Time) (bool, error)
+	MarkRead(userID, id int64) (int64, error)
+	MarkAllRead(userID int64) (int64, error)
+	Delete(userID, id int64) (int64, error)
+	CountUnread(userID int64) (int64, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建通知仓库实例
+func NewNotificationRepository() NotificationRepository {
+	return &notificationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建通知
+func (r *notificationRepository) Create(notification *models.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+// CreateForAllUsers 以notification为模板向所有用户广播，返回写入条数
+func (r *notificationRepository) CreateForAllUsers(n *models.Notification) (int64, error) {
+	result := r.db.Exec(`
+		INSERT INTO notifications (user_id, type, title, content, related_type, related_id, is_read, priority, sent_at, created_at)
+		SELECT id, ?, ?, ?, ?, ?, FALSE, ?, ?, ?
+		FROM users`,
+		n.Type, n.Title, n.Content, n.RelatedType, n.RelatedID, n.Priority, n.SentAt, n.CreatedAt,
+	)
+	return result.RowsAffected, result.Error
+}
+
+// FindByUserID 分页查询用户通知（按创建时间倒序）
+func (r *notificationRepository) FindByUserID(userID int64, unreadOnly bool, page, pageSize int) ([]*models.Notification, int64, error) {
+	var notifications []*models.Notification
+	var total int64
+
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&notifications).Error
+	return notifications, total, err
+}
+
+// ExistsSince 判断since之后是否已发送过同一对象的同类通知（用于提醒去重）
+func (r *notificationRepository) ExistsSince(userID int64, notificationType, relatedType string, relatedID int64, since time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND type = ? AND related_type = ? AND related_id = ? AND created_at >= ?",
+			userID, notificationType, relatedType, relatedID, since).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// MarkRead 标记单条通知已读，返回受影响行数（0表示不存在或已读）
+func (r *notificationRepository) MarkRead(userID, id int64) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND is_read = ?", id, userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// MarkAllRead 标记用户全部通知已读
+func (r *notificationRepository) MarkAllRead(userID int64) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// Delete 删除通知
+func (r *notificationRepository) Delete(userID, id int64) (int64, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// CountUnread 统计未读通知数
+func (r *notificationRepository) CountUnread(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
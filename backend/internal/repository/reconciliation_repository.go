@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// ReconciliationRepository 对账记录仓库接口
+type ReconciliationRepository interface {
+	Create(reconciliation *models.Reconciliation) error
+	FindByID(id int64) (*models.Reconciliation, error)
+	FindByAccountID(accountID int64, limit int) ([]*models.Reconciliation, error)
+	FindLatest(accountID int64) (*models.Reconciliation, error)
+	Delete(id int64) error
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+// NewReconciliationRepository 创建对账记录仓库实例
+func NewReconciliationRepository() ReconciliationRepository {
+	return &reconciliationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建对账记录
+func (r *reconciliationRepository) Create(reconciliation *models.Reconciliation) error {
+	return r.db.Create(reconciliation).Error
+}
+
+// FindByID 根据ID查找对账记录
+func (r *reconciliationRepository) FindByID(id int64) (*models.Reconciliation, error) {
+	var reconciliation models.Reconciliation
+	if err := r.db.Where("id = ?", id).First(&reconciliation).Error; err != nil {
+		return nil, err
+	}
+	return &reconciliation, nil
+}
+
+// FindByAccountID 查询账户的对账记录，按对账日期倒序
+func (r *reconciliationRepository) FindByAccountID(accountID int64, limit int) ([]*models.Reconciliation, error) {
+	var reconciliations []*models.Reconciliation
+	err := r.db.Where("account_id = ?", accountID).
+		Order("reconcile_date DESC, id DESC").
+		Limit(limit).
+		Find(&reconciliations).Error
+	return reconciliations, err
+}
+
+// FindLatest 查找账户最近一次对账，不存在时返回 nil
+func (r *reconciliationRepository) FindLatest(accountID int64) (*models.Reconciliation, error) {
+	var reconciliations []*models.Reconciliation
+	err := r.db.Where("account_id = ?", accountID).
+		Order("reconcile_date DESC, id DESC").
+		Limit(1).
+		Find(&reconciliations).Error
+	if err != nil || len(reconciliations) == 0 {
+		return nil, err
+	}
+	return reconciliations[0], nil
+}
+
+// Delete 删除对账记录
+func (r *reconciliationRepository) Delete(id int64) error {
+	return r.db.Delete(&models.Reconciliation{}, id).Error
+}
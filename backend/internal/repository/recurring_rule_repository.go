@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecurringRuleRepository 周期规则仓库接口
+type RecurringRuleRepository interface {
+	Create(rule *models.RecurringRule) error
+	FindByID(id int64) (*models.RecurringRule, error)
+	FindByIDForUpdate(id int64) (*models.RecurringRule, error)
+	FindByUserID(userID int64) ([]*models.RecurringRule, error)
+	FindDue(date time.Time) ([]*models.RecurringRule, error)
+	Update(rule *models.RecurringRule) error
+	Delete(id int64) error
+}
+
+type recurringRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewRecurringRuleRepository 创建周期规则仓库实例
+func NewRecurringRuleRepository() RecurringRuleRepository {
+	return &recurringRuleRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建周期规则
+func (r *recurringRuleRepository) Create(rule *models.RecurringRule) error {
+	return r.db.Create(rule).Error
+}
+
+// FindByID 根据ID查找周期规则
+func (r *recurringRuleRepository) FindByID(id int64) (*models.RecurringRule, error) {
+	var rule models.RecurringRule
+	if err := r.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByIDForUpdate 根据ID查找周期规则并加行锁，需在事务中调用
+func (r *recurringRuleRepository) FindByIDForUpdate(id int64) (*models.RecurringRule, error) {
+	var rule models.RecurringRule
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByUserID 根据用户ID查找周期规则列表
+func (r *recurringRuleRepository) FindByUserID(userID int64) ([]*models.RecurringRule, error) {
+	var rules []*models.RecurringRule
+	err := r.db.Where("user_id = ?", userID).
+		Order("is_active DESC, next_run_date ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// FindDue 查找所有用户中下次执行日期不晚于date的启用规则
+func (r *recurringRuleRepository) FindDue(date time.Time) ([]*models.RecurringRule, error) {
+	var rules []*models.RecurringRule
+	err := r.db.
+		Where("is_active = ? AND next_run_date IS NOT NULL AND next_run_date <= ?", true, date.Format("2006-01-02")).
+		Order("next_run_date ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// Update 更新周期规则
+func (r *recurringRuleRepository) Update(rule *models.RecurringRule) error {
+	return r.db.Save(rule).Error
+}
+
+// Delete 删除周期规则（已生成的交易保留）
+func (r *recurringRuleRepository) Delete(id int64) error {
+	return r.db.Delete(&models.RecurringRule{}, id).Error
+}
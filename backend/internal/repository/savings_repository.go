@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SavingsRepository 储蓄计划仓库接口
+type SavingsRepository interface {
+	Create(plan *models.SavingsPlan) error
+	FindByID(id int64) (*models.SavingsPlan, error)
+	FindByIDForUpdate(id int64) (*models.SavingsPlan, error)
+	FindByUserID(userID int64, status string) ([]*models.SavingsPlan, error)
+	Update(plan *models.SavingsPlan) error
+	Delete(id int64) error
+	CountActive(userID int64) (int64, error)
+	CreateRecord(record *models.SavingsRecord) error
+	FindRecordsByPlanID(planID int64, limit int) ([]*models.SavingsRecord, error)
+}
+
+type savingsRepository struct {
+	db *gorm.DB
+}
+
+// NewSavingsRepository 创建储蓄计划仓库实例
+func NewSavingsRepository() SavingsRepository {
+	return &savingsRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建储蓄计划
+func (r *savingsRepository) Create(plan *models.SavingsPlan) error {
+	return r.db.Create(plan).Error
+}
+
+// FindByID 根据ID查找储蓄计划
+func (r *savingsRepository) FindByID(id int64) (*models.SavingsPlan, error) {
+	var plan models.SavingsPlan
+	if err := r.db.Preload("Account").Where("id = ?", id).First(&plan).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// FindByIDForUpdate 根据ID查找储蓄计划并加行锁，需在事务中调用
+func (r *savingsRepository) FindByIDForUpdate(id int64) (*models.SavingsPlan, error) {
+	var plan models.SavingsPlan
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// FindByUserID 根据用户ID查找储蓄计划，status为空时返回全部
+func (r *savingsRepository) FindByUserID(userID int64, status string) ([]*models.SavingsPlan, error) {
+	var plans []*models.SavingsPlan
+	query := r.db.Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Preload("Account").Order("created_at DESC, id DESC").Find(&plans).Error
+	return plans, err
+}
+
+// Update 更新储蓄计划
+func (r *savingsRepository) Update(plan *models.SavingsPlan) error {
+	return r.db.Omit("Account").Save(plan).Error
+}
+
+// Delete 删除储蓄计划及其存取记录（已生成的转账交易保留）
+func (r *savingsRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("plan_id = ?", id).Delete(&models.SavingsRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.SavingsPlan{}, id).Error
+	})
+}
+
+// CountActive 统计用户进行中的储蓄计划数
+func (r *savingsRepository) CountActive(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.SavingsPlan{}).
+		Where("user_id = ? AND status = ?", userID, models.SavingsStatusActive).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateRecord 创建存取记录
+func (r *savingsRepository) CreateRecord(record *models.SavingsRecord) error {
+	return r.db.Create(record).Error
+}
+
+// FindRecordsByPlanID 查询存取记录（按日期倒序）
+func (r *savingsRepository) FindRecordsByPlanID(planID int64, limit int) ([]*models.SavingsRecord, error) {
+	var records []*models.SavingsRecord
+	err := r.db.Where("plan_id = ?", planID).
+		Order("deposit_date DESC, id DESC").
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
@@ -3,12 +3,16 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/qiuhaonan/float-backend/internal/dto/request"
 	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/pkg/database"
+	"github.com/qiuhaonan/float-backend/pkg/money"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionRepository 交易仓库接口
@@ -16,26 +20,57 @@ type TransactionRepository interface {
 	Create(transaction *models.Transaction) error
 	CreateBatch(transactions []*models.Transaction) error
 	FindByID(id int64) (*models.Transaction, error)
+	FindByIDForUpdate(id int64) (*models.Transaction, error)
 	FindByUserID(userID int64, filters *request.ListTransactionRequest) ([]*models.Transaction, int64, error)
+	FindAllByUserID(userID int64, filters *request.ListTransactionRequest, limit int) ([]*models.Transaction, error)
+	FindLatestByUserID(userID int64, filters *request.ListTransactionRequest, limit int) ([]*models.Transaction, error)
+	UpdateCategoryByFilter(userID int64, filters *request.ListTransactionRequest, transType string, categoryID int64) (int64, error)
+	UpdateClassification(transaction *models.Transaction) error
+	FindCategorized(userID int64, afterID int64, since time.Time, limit int) ([]*models.Transaction, error)
 	Update(transaction *models.Transaction) error
 	Delete(id int64) error
 	DeleteBatch(ids []int64) error
+	ReplaceSplits(transactionID int64, splits []*models.TransactionSplit) error
+	GetRefundedTotals(ids []int64) (map[int64]money.Money, error)
+	HasLinkedRecords(id int64) (bool, error)
+	FindPendingReimbursements(userID int64) ([]*models.Transaction, error)
+	FindByImportBatchID(userID int64, batchID int64) ([]*models.Transaction, error)
+	FindExistingExternalIDs(userID int64, externalIDs []string) ([]string, error)
+	FindExistingExternalIDsByAccount(userID int64, accountID int64, externalIDs []string) ([]string, error)
+	GetAccountNetChangeAfter(accountID int64, date time.Time) (money.Money, error)
+	GetAccountFlows(accountID int64, startDate, endDate time.Time) (inflow, outflow money.Money, count int, err error)
+	GetAccountDailyChanges(accountID int64) ([]*models.AccountBalanceSnapshot, error)
+	FindUnreconciled(accountID int64, until time.Time) ([]*models.Transaction, error)
+	MarkReconciled(accountID int64, until time.Time, reconciliationID int64) (int64, error)
+	ClearReconciliation(reconciliationID int64) error
 	GetTotalBalance(userID int64, filters *request.ListTransactionRequest) (*models.Transaction, error)
 	GetMonthlyStatistics(userID int64, month time.Time) (*models.Transaction, error)
 	GetCategoryStatistics(userID int64, startDate, endDate time.Time) ([]*models.Transaction, error)
-	GetDateRangeStatistics(userID int64, startDate, endDate time.Time) (*models.Transaction, error)
+	GetDateRangeStatistics(userID int64, startDate, endDate time.Time) (income, expense money.Money, count int64, err error)
+	CountRecordsByUserID(userID int64) (int64, error)
+	GetRecordDates(userID int64) ([]time.Time, error)
 }
 
-type transactionRepository struct{}
+type transactionRepository struct {
+	db *gorm.DB
+}
 
 // NewTransactionRepository 创建交易仓库实例
 func NewTransactionRepository() TransactionRepository {
-	return &transactionRepository{}
+	return &transactionRepository{
+		db: database.GetDB(),
+	}
+}
+
+// IsDuplicateExternalID 判断写入失败是否因为同一账户下的订单号或流水号已存在（并发导入同一账单时由唯一索引拦截）
+func IsDuplicateExternalID(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "uk_user_external")
 }
 
 // Create 创建单条交易
 func (r *transactionRepository) Create(transaction *models.Transaction) error {
-	if err := database.DB.Create(transaction).Error; err != nil {
+	if err := r.db.Create(transaction).Error; err != nil {
 		return fmt.Errorf("failed to create transaction: %w", err)
 	}
 	return nil
@@ -47,7 +82,7 @@ func (r *transactionRepository) CreateBatch(transactions []*models.Transaction)
 		return errors.New("transactions list cannot be empty")
 	}
 
-	if err := database.DB.CreateInBatches(transactions, 100).Error; err != nil {
+	if err := r.db.CreateInBatches(transactions, 100).Error; err != nil {
 		return fmt.Errorf("failed to create transactions in batch: %w", err)
 	}
 	return nil
@@ -56,10 +91,12 @@ func (r *transactionRepository) CreateBatch(transactions []*models.Transaction)
 // FindByID 根据ID查找交易
 func (r *transactionRepository) FindByID(id int64) (*models.Transaction, error) {
 	var transaction models.Transaction
-	err := database.DB.
+	err := r.db.
 		Preload("Category").
 		Preload("Account").
 		Preload("ToAccount").
+		Preload("Splits", orderSplits).
+		Preload("Splits.Category").
 		Where("id = ?", id).
 		First(&transaction).Error
 
@@ -73,37 +110,28 @@ func (r *transactionRepository) FindByID(id int64) (*models.Transaction, error)
 	return &transaction, nil
 }
 
-// FindByUserID 根据用户ID查找交易列表（带分页和过滤）
-func (r *transactionRepository) FindByUserID(userID int64, filters *request.ListTransactionRequest) ([]*models.Transaction, int64, error) {
-	query := database.DB.Where("user_id = ?", userID)
-
-	// 应用过滤条件
-	if filters.Type != "" {
-		query = query.Where("type = ?", filters.Type)
-	}
-
-	if filters.CategoryID != nil {
-		query = query.Where("category_id = ?", *filters.CategoryID)
-	}
-
-	if filters.AccountID != nil {
-		query = query.Where("account_id = ?", *filters.AccountID)
-	}
+// FindByIDForUpdate 根据ID查找交易（含拆分明细）并加行锁（SELECT ... FOR UPDATE），需在事务中调用
+func (r *transactionRepository) FindByIDForUpdate(id int64) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Splits", orderSplits).
+		Where("id = ?", id).
+		First(&transaction).Error
 
-	if !filters.StartDate.IsZero() {
-		query = query.Where("DATE(transaction_date) >= ?", filters.StartDate.Format("2006-01-02"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transaction not found")
+		}
+		return nil, fmt.Errorf("failed to find transaction: %w", err)
 	}
 
-	if !filters.EndDate.IsZero() {
-		query = query.Where("DATE(transaction_date) <= ?", filters.EndDate.Format("2006-01-02"))
-	}
+	return &transaction, nil
+}
 
-	if filters.SearchKeyword != "" {
-		query = query.Where("title LIKE ? OR description LIKE ? OR location LIKE ?",
-			"%"+filters.SearchKeyword+"%",
-			"%"+filters.SearchKeyword+"%",
-			"%"+filters.SearchKeyword+"%")
-	}
+// FindByUserID 根据用户ID查找交易列表（带分页和过滤）
+func (r *transactionRepository) FindByUserID(userID int64, filters *request.ListTransactionRequest) ([]*models.Transaction, int64, error) {
+	query := r.applyListFilters(r.db.Where("user_id = ?", userID), filters)
 
 	// 设置默认分页
 	if filters.Page == 0 {
@@ -140,6 +168,8 @@ func (r *transactionRepository) FindByUserID(userID int64, filters *request.List
 		Preload("Category").
 		Preload("Account").
 		Preload("ToAccount").
+		Preload("Splits", orderSplits).
+		Preload("Splits.Category").
 		Order(fmt.Sprintf("%s %s", sortBy, sortOrder)).
 		Offset(offset).
 		Limit(filters.PageSize).
@@ -152,13 +182,116 @@ func (r *transactionRepository) FindByUserID(userID int64, filters *request.List
 	return transactions, total, nil
 }
 
+// applyListFilters 应用交易列表的筛选条件（类型、分类、账户、日期范围、关键词）
+func (r *transactionRepository) applyListFilters(query *gorm.DB, filters *request.ListTransactionRequest) *gorm.DB {
+	if filters.Type != "" {
+		query = query.Where("type = ?", filters.Type)
+	}
+
+	// 按分类筛选时，拆分明细中包含该分类的交易也一并返回
+	if filters.CategoryID != nil {
+		query = query.Where("category_id = ? OR id IN (SELECT transaction_id FROM transaction_splits WHERE category_id = ?)",
+			*filters.CategoryID, *filters.CategoryID)
+	}
+
+	if filters.AccountID != nil {
+		query = query.Where("account_id = ?", *filters.AccountID)
+	}
+
+	if !filters.StartDate.IsZero() {
+		query = query.Where("DATE(transaction_date) >= ?", filters.StartDate.Format("2006-01-02"))
+	}
+
+	if !filters.EndDate.IsZero() {
+		query = query.Where("DATE(transaction_date) <= ?", filters.EndDate.Format("2006-01-02"))
+	}
+
+	if filters.SearchKeyword != "" {
+		query = query.Where("title LIKE ? OR description LIKE ? OR location LIKE ?",
+			"%"+filters.SearchKeyword+"%",
+			"%"+filters.SearchKeyword+"%",
+			"%"+filters.SearchKeyword+"%")
+	}
+
+	return query
+}
+
+// UpdateCategoryByFilter 将符合筛选条件的某类型交易改为指定分类，返回修改的笔数。
+// 拆分交易的分类由明细决定、退款沿用原支出的分类，均不修改
+func (r *transactionRepository) UpdateCategoryByFilter(userID int64, filters *request.ListTransactionRequest, transType string, categoryID int64) (int64, error) {
+	result := r.applyListFilters(r.db.Model(&models.Transaction{}).Where("user_id = ? AND type = ?", userID, transType), filters).
+		Where("refund_of_id IS NULL AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = transactions.id)").
+		Update("category_id", categoryID)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to update transaction category: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// UpdateClassification 仅更新交易的分类、标签和标题，不影响金额和关联数据
+func (r *transactionRepository) UpdateClassification(transaction *models.Transaction) error {
+	return r.db.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Updates(map[string]interface{}{
+		"category_id": transaction.CategoryID,
+		"tags":        transaction.Tags,
+		"title":       transaction.Title,
+	}).Error
+}
+
+// FindCategorized 查找ID大于 afterID、日期不早于 since 的已分类收支交易（不含退款），按ID倒序最多返回 limit 条，
+// 只查询训练分类建议所需的字段
+func (r *transactionRepository) FindCategorized(userID int64, afterID int64, since time.Time, limit int) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.Select("id", "type", "category_id", "title", "location", "amount", "transaction_date", "transaction_time").
+		Where("user_id = ? AND id > ? AND DATE(transaction_date) >= ?", userID, afterID, since.Format("2006-01-02")).
+		Where("type IN ? AND category_id IS NOT NULL AND refund_of_id IS NULL", []string{"expense", "income"}).
+		Order("id DESC").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categorized transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// FindAllByUserID 按筛选条件查找交易（不分页），最多返回 limit 条，用于导出
+func (r *transactionRepository) FindAllByUserID(userID int64, filters *request.ListTransactionRequest, limit int) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.applyListFilters(r.db.Where("user_id = ?", userID), filters).
+		Preload("Category").
+		Preload("Account").
+		Preload("ToAccount").
+		Preload("Splits", orderSplits).
+		Preload("Splits.Category").
+		Order("transaction_date ASC, id ASC").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// FindLatestByUserID 按筛选条件查找最近的交易（按交易日期倒序），最多返回 limit 条
+func (r *transactionRepository) FindLatestByUserID(userID int64, filters *request.ListTransactionRequest, limit int) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.applyListFilters(r.db.Where("user_id = ?", userID), filters).
+		Preload("Splits", orderSplits).
+		Order("transaction_date DESC, id DESC").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+	return transactions, nil
+}
+
 // Update 更新交易
 func (r *transactionRepository) Update(transaction *models.Transaction) error {
 	if transaction.ID == 0 {
 		return errors.New("transaction id cannot be zero")
 	}
 
-	if err := database.DB.Save(transaction).Error; err != nil {
+	if err := r.db.Save(transaction).Error; err != nil {
 		return fmt.Errorf("failed to update transaction: %w", err)
 	}
 
@@ -171,7 +304,7 @@ func (r *transactionRepository) Delete(id int64) error {
 		return errors.New("transaction id cannot be zero")
 	}
 
-	if err := database.DB.Where("id = ?", id).Delete(&models.Transaction{}).Error; err != nil {
+	if err := r.db.Where("id = ?", id).Delete(&models.Transaction{}).Error; err != nil {
 		return fmt.Errorf("failed to delete transaction: %w", err)
 	}
 
@@ -184,16 +317,229 @@ func (r *transactionRepository) DeleteBatch(ids []int64) error {
 		return errors.New("ids list cannot be empty")
 	}
 
-	if err := database.DB.Where("id IN ?", ids).Delete(&models.Transaction{}).Error; err != nil {
+	if err := r.db.Where("id IN ?", ids).Delete(&models.Transaction{}).Error; err != nil {
 		return fmt.Errorf("failed to delete transactions in batch: %w", err)
 	}
 
 	return nil
 }
 
+// ReplaceSplits 用新的拆分明细替换交易原有的明细，splits为空时取消拆分
+func (r *transactionRepository) ReplaceSplits(transactionID int64, splits []*models.TransactionSplit) error {
+	if err := r.db.Where("transaction_id = ?", transactionID).Delete(&models.TransactionSplit{}).Error; err != nil {
+		return fmt.Errorf("failed to delete transaction splits: %w", err)
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+	for _, split := range splits {
+		split.ID = 0
+		split.TransactionID = transactionID
+	}
+	if err := r.db.Create(splits).Error; err != nil {
+		return fmt.Errorf("failed to create transaction splits: %w", err)
+	}
+	return nil
+}
+
+// HasLinkedRecords 交易是否被储蓄记录、账单付款记录、贷款分期或心愿单存款引用，或是仍存在的心愿的购买交易
+func (r *transactionRepository) HasLinkedRecords(id int64) (bool, error) {
+	for _, model := range []interface{}{&models.SavingsRecord{}, &models.BillHistory{}, &models.LoanInstallment{}, &models.WishlistDeposit{}} {
+		var count int64
+		if err := r.db.Model(model).Where("transaction_id = ?", id).Count(&count).Error; err != nil {
+			return false, fmt.Errorf("failed to check linked records: %w", err)
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	var count int64
+	err := r.db.Model(&models.Wishlist{}).
+		Where("id = (?)", r.db.Model(&models.Transaction{}).Select("wishlist_id").Where("id = ?", id)).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check linked records: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetRefundedTotals 统计各支出已关联的退款（含报销到账）合计，没有退款的支出不在结果中
+func (r *transactionRepository) GetRefundedTotals(ids []int64) (map[int64]money.Money, error) {
+	totals := make(map[int64]money.Money)
+	if len(ids) == 0 {
+		return totals, nil
+	}
+
+	var rows []struct {
+		RefundOfID int64
+		Total      money.Money
+	}
+	err := r.db.Model(&models.Transaction{}).
+		Select("refund_of_id, SUM(amount) AS total").
+		Where("refund_of_id IN ?", ids).
+		Group("refund_of_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunded totals: %w", err)
+	}
+
+	for _, row := range rows {
+		totals[row.RefundOfID] = row.Total
+	}
+	return totals, nil
+}
+
+// FindPendingReimbursements 查找尚未全额报销的可报销支出，按交易日期排序
+func (r *transactionRepository) FindPendingReimbursements(userID int64) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.
+		Preload("Category").
+		Preload("Account").
+		Where("user_id = ? AND type = 'expense' AND reimbursable = ?", userID, true).
+		Where("amount > (SELECT COALESCE(SUM(r.amount), 0) FROM transactions r WHERE r.refund_of_id = transactions.id)").
+		Order("transaction_date ASC, id ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending reimbursements: %w", err)
+	}
+	return transactions, nil
+}
+
+// orderSplits 拆分明细按录入顺序返回
+func orderSplits(db *gorm.DB) *gorm.DB {
+	return db.Order("display_order ASC, id ASC")
+}
+
+// FindByImportBatchID 查找某次导入生成的全部交易
+func (r *transactionRepository) FindByImportBatchID(userID int64, batchID int64) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.Where("user_id = ? AND import_batch_id = ?", userID, batchID).Find(&transactions).Error
+	return transactions, err
+}
+
+// FindExistingExternalIDs 返回已存在的第三方订单号，用于导入去重
+func (r *transactionRepository) FindExistingExternalIDs(userID int64, externalIDs []string) ([]string, error) {
+	var existing []string
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&models.Transaction{}).
+		Where("user_id = ? AND external_id IN ?", userID, externalIDs).
+		Pluck("external_id", &existing).Error
+	return existing, err
+}
+
+// FindExistingExternalIDsByAccount 返回账户下已存在的银行流水号，银行流水号仅在账户内唯一
+func (r *transactionRepository) FindExistingExternalIDsByAccount(userID int64, accountID int64, externalIDs []string) ([]string, error) {
+	var existing []string
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&models.Transaction{}).
+		Where("user_id = ? AND account_id = ? AND external_id IN ?", userID, accountID, externalIDs).
+		Pluck("external_id", &existing).Error
+	return existing, err
+}
+
+// GetAccountNetChangeAfter 统计某日期之后的交易对账户余额的净影响，用于推算历史日期的余额
+func (r *transactionRepository) GetAccountNetChangeAfter(accountID int64, date time.Time) (money.Money, error) {
+	var total money.Money
+	err := r.db.Model(&models.Transaction{}).
+		Where("(account_id = ? OR to_account_id = ?) AND transaction_date > ?", accountID, accountID, date.Format("2006-01-02")).
+		Select("COALESCE(SUM(CASE "+
+			"WHEN type = 'income' AND account_id = ? THEN amount "+
+			"WHEN type IN ('expense', 'transfer') AND account_id = ? THEN -amount "+
+			"ELSE 0 END), 0) + "+
+			"COALESCE(SUM(CASE WHEN type = 'transfer' AND to_account_id = ? THEN amount ELSE 0 END), 0)",
+			accountID, accountID, accountID).
+		Scan(&total).Error
+	return total, err
+}
+
+// GetAccountFlows 统计日期区间内账户的入账（收入、转入）与出账（支出、转出）金额及交易笔数，用于生成信用账单
+func (r *transactionRepository) GetAccountFlows(accountID int64, startDate, endDate time.Time) (inflow, outflow money.Money, count int, err error) {
+	var result struct {
+		Inflow  money.Money
+		Outflow money.Money
+		Count   int
+	}
+	err = r.db.Model(&models.Transaction{}).
+		Where("(account_id = ? OR to_account_id = ?) AND transaction_date >= ? AND transaction_date <= ?",
+			accountID, accountID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Select("COALESCE(SUM(CASE "+
+			"WHEN type = 'income' AND account_id = ? THEN amount "+
+			"WHEN type = 'transfer' AND to_account_id = ? THEN amount "+
+			"ELSE 0 END), 0) as inflow, "+
+			"COALESCE(SUM(CASE WHEN type IN ('expense', 'transfer') AND account_id = ? THEN amount ELSE 0 END), 0) as outflow, "+
+			"COUNT(*) as count",
+			accountID, accountID, accountID).
+		Scan(&result).Error
+	return result.Inflow, result.Outflow, result.Count, err
+}
+
+// GetAccountDailyChanges 按交易日期汇总交易对账户余额的净影响（结果的 Balance 为当日净变动），按日期升序，用于重放生成余额快照
+func (r *transactionRepository) GetAccountDailyChanges(accountID int64) ([]*models.AccountBalanceSnapshot, error) {
+	var changes []*models.AccountBalanceSnapshot
+	err := r.db.Model(&models.Transaction{}).
+		Where("account_id = ? OR to_account_id = ?", accountID, accountID).
+		Select("transaction_date AS snapshot_date, "+
+			"COALESCE(SUM(CASE "+
+			"WHEN type = 'income' AND account_id = ? THEN amount "+
+			"WHEN type IN ('expense', 'transfer') AND account_id = ? THEN -amount "+
+			"ELSE 0 END), 0) + "+
+			"COALESCE(SUM(CASE WHEN type = 'transfer' AND to_account_id = ? THEN amount ELSE 0 END), 0) AS balance",
+			accountID, accountID, accountID).
+		Group("transaction_date").
+		Order("transaction_date ASC").
+		Find(&changes).Error
+	return changes, err
+}
+
+// FindUnreconciled 查询账户截至某日尚未对账的交易（含转入），按日期升序；转账按该账户一侧的对账状态判断
+func (r *transactionRepository) FindUnreconciled(accountID int64, until time.Time) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.Where("((account_id = ? AND reconciliation_id IS NULL) OR (to_account_id = ? AND to_reconciliation_id IS NULL)) AND transaction_date <= ?",
+		accountID, accountID, until.Format("2006-01-02")).
+		Order("transaction_date ASC, id ASC").
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// MarkReconciled 将账户截至某日尚未对账的交易标记为已对账，返回标记的条数；
+// 转入该账户的转账记在 to_reconciliation_id，不覆盖转出账户一侧的对账
+func (r *transactionRepository) MarkReconciled(accountID int64, until time.Time, reconciliationID int64) (int64, error) {
+	untilDate := until.Format("2006-01-02")
+	outgoing := r.db.Model(&models.Transaction{}).
+		Where("account_id = ? AND transaction_date <= ? AND reconciliation_id IS NULL", accountID, untilDate).
+		Update("reconciliation_id", reconciliationID)
+	if outgoing.Error != nil {
+		return 0, outgoing.Error
+	}
+	incoming := r.db.Model(&models.Transaction{}).
+		Where("to_account_id = ? AND transaction_date <= ? AND to_reconciliation_id IS NULL", accountID, untilDate).
+		Update("to_reconciliation_id", reconciliationID)
+	if incoming.Error != nil {
+		return 0, incoming.Error
+	}
+	return outgoing.RowsAffected + incoming.RowsAffected, nil
+}
+
+// ClearReconciliation 撤销对账时清除交易的已对账标记，只清除该次对账所在账户一侧
+func (r *transactionRepository) ClearReconciliation(reconciliationID int64) error {
+	if err := r.db.Model(&models.Transaction{}).
+		Where("reconciliation_id = ?", reconciliationID).
+		Update("reconciliation_id", nil).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&models.Transaction{}).
+		Where("to_reconciliation_id = ?", reconciliationID).
+		Update("to_reconciliation_id", nil).Error
+}
+
 // GetTotalBalance 获取交易统计（收入、支出、净额等）
 func (r *transactionRepository) GetTotalBalance(userID int64, filters *request.ListTransactionRequest) (*models.Transaction, error) {
-	query := database.DB.Where("user_id = ?", userID)
+	query := r.db.Where("user_id = ?", userID)
 
 	if filters.Type != "" {
 		query = query.Where("type = ?", filters.Type)
@@ -235,7 +581,7 @@ func (r *transactionRepository) GetMonthlyStatistics(userID int64, month time.Ti
 	endDate := startDate.AddDate(0, 1, -1).Add(time.Hour*23 + time.Minute*59 + time.Second*59)
 
 	var transaction models.Transaction
-	err := database.DB.
+	err := r.db.
 		Where("user_id = ? AND transaction_date >= ? AND transaction_date <= ?", userID, startDate, endDate).
 		Select("COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE 0 END), 0) as amount").
 		First(&transaction).Error
@@ -247,14 +593,23 @@ func (r *transactionRepository) GetMonthlyStatistics(userID int64, month time.Ti
 	return &transaction, nil
 }
 
-// GetCategoryStatistics 获取分类统计
+// GetCategoryStatistics 获取分类支出统计，拆分交易按明细计入各自的分类，退款冲减对应分类的支出
+// 返回的 Amount 为按记账汇率折算为本位币的分类净支出，ID 为涉及的支出笔数
 func (r *transactionRepository) GetCategoryStatistics(userID int64, startDate, endDate time.Time) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	err := database.DB.
-		Where("user_id = ? AND transaction_date >= ? AND transaction_date <= ?", userID, startDate, endDate).
-		Group("category_id").
-		Select("category_id, SUM(CASE WHEN type = 'expense' THEN amount ELSE 0 END) as amount, COUNT(*) as id").
-		Find(&transactions).Error
+	err := r.db.Raw("SELECT category_id, SUM(amount) AS amount, COUNT(DISTINCT transaction_id) AS id FROM ("+
+		"SELECT t.category_id, ROUND(t.amount * t.exchange_rate, 2) AS amount, t.id AS transaction_id FROM transactions t "+
+		"WHERE t.user_id = ? AND t.type = 'expense' AND t.transaction_date >= ? AND t.transaction_date <= ? "+
+		"AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id) "+
+		"UNION ALL "+
+		"SELECT s.category_id, ROUND(s.amount * t.exchange_rate, 2), t.id AS transaction_id FROM transaction_splits s JOIN transactions t ON t.id = s.transaction_id "+
+		"WHERE t.user_id = ? AND t.type = 'expense' AND t.transaction_date >= ? AND t.transaction_date <= ? "+
+		"UNION ALL "+
+		"SELECT t.category_id, -ROUND(t.amount * t.exchange_rate, 2), t.refund_of_id AS transaction_id FROM transactions t "+
+		"WHERE t.user_id = ? AND t.type = 'income' AND t.refund_of_id IS NOT NULL AND t.transaction_date >= ? AND t.transaction_date <= ?"+
+		") portions GROUP BY category_id ORDER BY amount DESC",
+		userID, startDate, endDate, userID, startDate, endDate, userID, startDate, endDate).
+		Scan(&transactions).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get category statistics: %w", err)
@@ -263,20 +618,50 @@ func (r *transactionRepository) GetCategoryStatistics(userID int64, startDate, e
 	return transactions, nil
 }
 
-// GetDateRangeStatistics 获取日期范围内的统计
-func (r *transactionRepository) GetDateRangeStatistics(userID int64, startDate, endDate time.Time) (*models.Transaction, error) {
-	var transaction models.Transaction
-	err := database.DB.
-		Where("user_id = ? AND transaction_date >= ? AND transaction_date <= ?", userID, startDate, endDate).
+// GetDateRangeStatistics 获取日期范围内的收入与支出合计（按记账汇率折算为本位币）及交易笔数，退款冲减支出而不计为收入
+func (r *transactionRepository) GetDateRangeStatistics(userID int64, startDate, endDate time.Time) (income, expense money.Money, count int64, err error) {
+	var result struct {
+		Income  money.Money
+		Expense money.Money
+		Count   int64
+	}
+	err = r.db.Model(&models.Transaction{}).
+		Where("user_id = ? AND transaction_date >= ? AND transaction_date <= ?", userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
 		Select(
-			"COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE 0 END), 0) as amount, "+
-				"COALESCE(SUM(CASE WHEN type = 'expense' THEN amount ELSE 0 END), 0) as id",
+			"COALESCE(SUM(CASE WHEN type = 'income' AND refund_of_id IS NULL THEN ROUND(amount * exchange_rate, 2) ELSE 0 END), 0) as income, "+
+				"COALESCE(SUM(CASE WHEN type = 'expense' THEN ROUND(amount * exchange_rate, 2) WHEN type = 'income' AND refund_of_id IS NOT NULL THEN -ROUND(amount * exchange_rate, 2) ELSE 0 END), 0) as expense, "+
+				"COUNT(*) as count",
 		).
-		First(&transaction).Error
-
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("failed to get date range statistics: %w", err)
+		Scan(&result).Error
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to get date range statistics: %w", err)
 	}
 
-	return &transaction, nil
+	return result.Income, result.Expense, result.Count, nil
+}
+
+// userEnteredRecords 只保留用户手动记的账，排除周期规则、导入、账单付款和贷款分期自动生成的交易，
+// 与交易服务写入时累加记账统计的条件一致
+func userEnteredRecords(db *gorm.DB) *gorm.DB {
+	return db.Where("recurring_rule_id IS NULL AND import_batch_id IS NULL AND bill_id IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM loan_installments WHERE loan_installments.transaction_id = transactions.id)")
+}
+
+// CountRecordsByUserID 统计用户手动记账的笔数
+func (r *transactionRepository) CountRecordsByUserID(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Transaction{}).Scopes(userEnteredRecords).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// GetRecordDates 获取用户手动记账的日期（按记录创建时间去重），按日期倒序
+func (r *transactionRepository) GetRecordDates(userID int64) ([]time.Time, error) {
+	var dates []time.Time
+	err := r.db.Model(&models.Transaction{}).
+		Scopes(userEnteredRecords).
+		Where("user_id = ?", userID).
+		Order("record_date DESC").
+		Distinct("DATE(created_at) AS record_date").
+		Pluck("record_date", &dates).Error
+	return dates, err
 }
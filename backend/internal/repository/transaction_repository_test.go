@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReconciliationMarksEachTransferSide 测试对账时转出和转入分别标记，撤销对账时两侧分别按本次对账清除
+func TestReconciliationMarksEachTransferSide(t *testing.T) {
+	d := useRecordingDB(t)
+	repo := NewTransactionRepository()
+
+	_, err := repo.MarkReconciled(1, time.Date(2024, 3, 31, 0, 0, 0, 0, time.Local), 9)
+	assert.NoError(t, err)
+	if assert.Len(t, d.execs, 2) {
+		assert.Contains(t, d.execs[0], "SET `reconciliation_id`")
+		assert.Contains(t, d.execs[0], "account_id = ?")
+		assert.Contains(t, d.execs[0], "reconciliation_id IS NULL")
+		assert.Contains(t, d.execs[1], "SET `to_reconciliation_id`")
+		assert.Contains(t, d.execs[1], "to_account_id = ?")
+		assert.Contains(t, d.execs[1], "to_reconciliation_id IS NULL")
+	}
+
+	d.execs = nil
+	assert.NoError(t, repo.ClearReconciliation(9))
+	if assert.Len(t, d.execs, 2) {
+		assert.Contains(t, d.execs[0], "SET `reconciliation_id`=?,`updated_at`=? WHERE reconciliation_id = ?")
+		assert.Contains(t, d.execs[1], "SET `to_reconciliation_id`=?,`updated_at`=? WHERE to_reconciliation_id = ?")
+	}
+}
+
+// TestHasLinkedRecordsChecksPurchasedWishlist 测试心愿购买交易以心愿是否仍存在判断关联
+func TestHasLinkedRecordsChecksPurchasedWishlist(t *testing.T) {
+	d := useRecordingDB(t)
+
+	linked, err := NewTransactionRepository().HasLinkedRecords(7)
+	assert.NoError(t, err)
+	assert.False(t, linked)
+	if assert.Len(t, d.queries, 5) {
+		assert.Contains(t, d.queries[1], "FROM `bill_history` WHERE transaction_id = ?")
+		assert.Contains(t, d.queries[4], "FROM `wishlists` WHERE id = (SELECT `wishlist_id` FROM `transactions` WHERE id = ?)")
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// TransactionRuleRepository 交易规则仓库接口
+type TransactionRuleRepository interface {
+	Create(rule *models.TransactionRule) error
+	FindByID(id int64) (*models.TransactionRule, error)
+	FindByUserID(userID int64, activeOnly bool) ([]*models.TransactionRule, error)
+	Update(rule *models.TransactionRule) error
+	Delete(id int64) error
+}
+
+type transactionRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewTransactionRuleRepository 创建交易规则仓库实例
+func NewTransactionRuleRepository() TransactionRuleRepository {
+	return &transactionRuleRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建交易规则
+func (r *transactionRuleRepository) Create(rule *models.TransactionRule) error {
+	return r.db.Create(rule).Error
+}
+
+// FindByID 根据ID查找交易规则
+func (r *transactionRuleRepository) FindByID(id int64) (*models.TransactionRule, error) {
+	var rule models.TransactionRule
+	if err := r.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByUserID 查询用户的交易规则，按执行顺序排列
+func (r *transactionRuleRepository) FindByUserID(userID int64, activeOnly bool) ([]*models.TransactionRule, error) {
+	var rules []*models.TransactionRule
+	query := r.db.Where("user_id = ?", userID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("priority ASC, id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// Update 更新交易规则
+func (r *transactionRuleRepository) Update(rule *models.TransactionRule) error {
+	return r.db.Save(rule).Error
+}
+
+// Delete 删除交易规则
+func (r *transactionRuleRepository) Delete(id int64) error {
+	return r.db.Delete(&models.TransactionRule{}, id).Error
+}
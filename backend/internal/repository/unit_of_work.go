@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// TxRepositories 同一数据库事务内可用的仓库集合
+type TxRepositories struct {
+	Transactions    TransactionRepository
+	Accounts        AccountRepository
+	Bills           BillRepository
+	RecurringRules  RecurringRuleRepository
+	Savings         SavingsRepository
+	Wishlists       WishlistRepository
+	Loans           LoanRepository
+	Users           UserRepository
+	Reconciliations ReconciliationRepository
+}
+
+// UnitOfWork 事务单元接口
+// 交易记录写入与账户余额变更必须在同一事务中提交或回滚
+type UnitOfWork interface {
+	Transaction(fn func(repos *TxRepositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork 创建事务单元实例
+func NewUnitOfWork() UnitOfWork {
+	return &unitOfWork{
+		db: database.GetDB(),
+	}
+}
+
+// Transaction 在数据库事务中执行fn，fn返回错误时整体回滚
+func (u *unitOfWork) Transaction(fn func(repos *TxRepositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&TxRepositories{
+			Transactions:    &transactionRepository{db: tx},
+			Accounts:        &accountRepository{db: tx},
+			Bills:           &billRepository{db: tx},
+			RecurringRules:  &recurringRuleRepository{db: tx},
+			Savings:         &savingsRepository{db: tx},
+			Wishlists:       &wishlistRepository{db: tx},
+			Loans:           &loanRepository{db: tx},
+			Users:           &userRepository{db: tx},
+			Reconciliations: &reconciliationRepository{db: tx},
+		})
+	})
+}
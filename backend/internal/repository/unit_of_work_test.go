@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingDriver 只记录执行的 SQL 的数据库驱动，查询均返回空结果，用于不连接 MySQL 测试仓库的接线
+type recordingDriver struct {
+	mu      sync.Mutex
+	execs   []string
+	queries []string
+	commits int
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{driver: d}, nil }
+
+type recordingConn struct{ driver *recordingDriver }
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordingConn) Close() error                        { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)           { return &recordingTx{driver: c.driver}, nil }
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.execs = append(c.driver.execs, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.queries = append(c.driver.queries, query)
+	return emptyRows{}, nil
+}
+
+type recordingTx struct{ driver *recordingDriver }
+
+func (t *recordingTx) Commit() error {
+	t.driver.mu.Lock()
+	defer t.driver.mu.Unlock()
+	t.driver.commits++
+	return nil
+}
+
+func (t *recordingTx) Rollback() error { return nil }
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+var driverSeq struct {
+	sync.Mutex
+	n int
+}
+
+// useRecordingDB 将 database.DB 替换为记录 SQL 的连接，测试结束后恢复
+func useRecordingDB(t *testing.T) *recordingDriver {
+	t.Helper()
+	d := &recordingDriver{}
+	driverSeq.Lock()
+	driverSeq.n++
+	name := fmt.Sprintf("recording%d", driverSeq.n)
+	driverSeq.Unlock()
+	sql.Register(name, d)
+
+	sqlDB, err := sql.Open(name, "")
+	assert.NoError(t, err)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	assert.NoError(t, err)
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return d
+}
+
+// TestUnitOfWorkProvidesAllRepositories 测试通过 NewUnitOfWork 创建的事务中每个仓库都已设置，
+// 服务层的 mock 测试无法发现漏接的仓库
+func TestUnitOfWorkProvidesAllRepositories(t *testing.T) {
+	d := useRecordingDB(t)
+
+	err := NewUnitOfWork().Transaction(func(repos *TxRepositories) error {
+		value := reflect.ValueOf(repos).Elem()
+		for i := 0; i < value.NumField(); i++ {
+			assert.False(t, value.Field(i).IsNil(), "TxRepositories.%s 未设置", value.Type().Field(i).Name)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, d.commits)
+}
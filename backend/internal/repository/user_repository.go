@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/pkg/database"
@@ -18,6 +19,9 @@ type UserRepository interface {
 	UpdateLastLogin(userID int64) error
 	Count() (int64, error)
 	FindAll(page, pageSize int) ([]*models.User, error)
+	AddRecords(userID int64, count int, day time.Time) error
+	RemoveRecords(userID int64, count int) error
+	UpdateRecordStats(userID int64, totalRecords, continuousDays int, lastRecordDate *time.Time) error
 }
 
 type userRepository struct {
@@ -100,3 +104,29 @@ func (r *userRepository) FindAll(page, pageSize int) ([]*models.User, error) {
 	err := r.db.Offset(offset).Limit(pageSize).Order("id desc").Find(&users).Error
 	return users, err
 }
+
+// AddRecords 记账后累加记账笔数并更新连续记账天数：当天已记过账时不变，昨天记过账时加一，否则重新从1开始
+func (r *userRepository) AddRecords(userID int64, count int, day time.Time) error {
+	today := day.Format("2006-01-02")
+	yesterday := day.AddDate(0, 0, -1).Format("2006-01-02")
+	// MySQL 按书写顺序赋值，continuous_days 须在 last_record_date 之前计算
+	return r.db.Exec("UPDATE users SET total_records = total_records + ?, "+
+		"continuous_days = CASE WHEN last_record_date = ? THEN continuous_days WHEN last_record_date = ? THEN continuous_days + 1 ELSE 1 END, "+
+		"last_record_date = ? WHERE id = ?",
+		count, today, yesterday, today, userID).Error
+}
+
+// RemoveRecords 删除交易后扣减记账笔数，连续记账天数由每日校正任务修复
+func (r *userRepository) RemoveRecords(userID int64, count int) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).
+		Update("total_records", gorm.Expr("GREATEST(total_records - ?, 0)", count)).Error
+}
+
+// UpdateRecordStats 覆盖记账笔数和连续记账天数，用于校正累计值的偏差
+func (r *userRepository) UpdateRecordStats(userID int64, totalRecords, continuousDays int, lastRecordDate *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"total_records":    totalRecords,
+		"continuous_days":  continuousDays,
+		"last_record_date": lastRecordDate,
+	}).Error
+}
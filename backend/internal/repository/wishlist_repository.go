@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WishlistRepository 心愿单仓库接口
+type WishlistRepository interface {
+	Create(wishlist *models.Wishlist) error
+	FindByID(id int64) (*models.Wishlist, error)
+	FindByIDForUpdate(id int64) (*models.Wishlist, error)
+	FindByUserID(userID int64, status string) ([]*models.Wishlist, error)
+	Update(wishlist *models.Wishlist) error
+	Delete(id int64) error
+	CountActive(userID int64) (int64, error)
+	CreateDeposit(deposit *models.WishlistDeposit) error
+	FindDepositsByWishlistID(wishlistID int64, limit int) ([]*models.WishlistDeposit, error)
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWishlistRepository 创建心愿单仓库实例
+func NewWishlistRepository() WishlistRepository {
+	return &wishlistRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建心愿
+func (r *wishlistRepository) Create(wishlist *models.Wishlist) error {
+	return r.db.Create(wishlist).Error
+}
+
+// FindByID 根据ID查找心愿
+func (r *wishlistRepository) FindByID(id int64) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	if err := r.db.Where("id = ?", id).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// FindByIDForUpdate 根据ID查找心愿并加行锁，需在事务中调用
+func (r *wishlistRepository) FindByIDForUpdate(id int64) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&wishlist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// FindByUserID 根据用户ID查找心愿列表，status为空时返回全部
+// 按优先级从高到低、再按创建时间倒序排列
+func (r *wishlistRepository) FindByUserID(userID int64, status string) ([]*models.Wishlist, error) {
+	var wishlists []*models.Wishlist
+	query := r.db.Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.
+		Order("FIELD(priority, 'high', 'medium', 'low'), created_at DESC, id DESC").
+		Find(&wishlists).Error
+	return wishlists, err
+}
+
+// Update 更新心愿
+func (r *wishlistRepository) Update(wishlist *models.Wishlist) error {
+	return r.db.Save(wishlist).Error
+}
+
+// Delete 删除心愿及其存款记录（购买生成的交易保留）
+func (r *wishlistRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", id).Delete(&models.WishlistDeposit{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Wishlist{}, id).Error
+	})
+}
+
+// CountActive 统计用户未完成的心愿数（计划中与进行中）
+func (r *wishlistRepository) CountActive(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Wishlist{}).
+		Where("user_id = ? AND status IN ?", userID, []string{models.WishlistStatusPlanning, models.WishlistStatusInProgress}).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateDeposit 创建存款记录
+func (r *wishlistRepository) CreateDeposit(deposit *models.WishlistDeposit) error {
+	return r.db.Create(deposit).Error
+}
+
+// FindDepositsByWishlistID 查询存款记录（按日期倒序）
+func (r *wishlistRepository) FindDepositsByWishlistID(wishlistID int64, limit int) ([]*models.WishlistDeposit, error) {
+	var deposits []*models.WishlistDeposit
+	err := r.db.Where("wishlist_id = ?", wishlistID).
+		Order("deposit_date DESC, id DESC").
+		Limit(limit).
+		Find(&deposits).Error
+	return deposits, err
+}
@@ -3,12 +3,15 @@ package service
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/qiuhaonan/float-backend/internal/dto/request"
 	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/exchange"
 	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/internal/repository"
 	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
 )
 
 // AccountService 账户服务接口
@@ -23,12 +26,14 @@ type AccountService interface {
 
 type accountService struct {
 	accountRepo repository.AccountRepository
+	rates       CurrencyConverter
 }
 
 // NewAccountService 创建账户服务实例
 func NewAccountService() AccountService {
 	return &accountService{
 		accountRepo: repository.NewAccountRepository(),
+		rates:       NewExchangeRateService(repository.NewExchangeRateRepository(), repository.NewUserRepository()),
 	}
 }
 
@@ -44,9 +49,14 @@ func (s *accountService) GetAccounts(userID int64) ([]*response.AccountResponse,
 		return nil, err
 	}
 
+	base, err := s.rates.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
 	var result []*response.AccountResponse
 	for _, acc := range accounts {
-		result = append(result, s.toAccountResponse(acc))
+		result = append(result, s.withBaseBalance(userID, base, toAccountResponse(acc)))
 	}
 
 	logger.Info(fmt.Sprintf("[Service][账户] 获取成功 | 用户ID: %d | 账户数: %d", userID, len(result)))
@@ -65,19 +75,36 @@ func (s *accountService) GetAccountByID(userID int64, accountID int64) (*respons
 		return nil, errors.New("无权访问该账户")
 	}
 
-	return s.toAccountResponse(account), nil
+	base, err := s.rates.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withBaseBalance(userID, base, toAccountResponse(account)), nil
 }
 
 // CreateAccount 创建账户
 func (s *accountService) CreateAccount(userID int64, req *request.CreateAccountRequest) (*response.AccountResponse, error) {
 	timer := logger.NewTimer("创建账户")
-	logger.Info(fmt.Sprintf("[Service][账户] 创建账户 | 用户ID: %d | 账户名: %s | 初始余额: %.2f", userID, req.AccountName, req.InitialBalance))
+	logger.Info(fmt.Sprintf("[Service][账户] 创建账户 | 用户ID: %d | 账户名: %s | 初始余额: %s", userID, req.AccountName, req.InitialBalance))
 
 	includeInTotal := true
 	if req.IncludeInTotal != nil {
 		includeInTotal = *req.IncludeInTotal
 	}
 
+	// 未指定币种时使用用户本位币
+	currency := exchange.NormalizeCurrency(req.Currency)
+	if req.Currency != "" && currency == "" {
+		return nil, errRateCurrency
+	}
+	if currency == "" {
+		base, err := s.rates.BaseCurrency(userID)
+		if err != nil {
+			return nil, err
+		}
+		currency = base
+	}
+
 	account := &models.Account{
 		UserID:         userID,
 		AccountType:    req.AccountType,
@@ -87,9 +114,16 @@ func (s *accountService) CreateAccount(userID int64, req *request.CreateAccountR
 		Color:          req.Color,
 		Balance:        req.InitialBalance, // 初始余额即为当前余额
 		InitialBalance: req.InitialBalance,
+		Currency:       currency,
 		IncludeInTotal: includeInTotal,
 		DisplayOrder:   req.DisplayOrder,
 		IsActive:       true,
+		CreditLimit:    req.CreditLimit,
+		BillingDay:     req.BillingDay,
+		PaymentDueDay:  req.PaymentDueDay,
+	}
+	if err := validateCreditSettings(account); err != nil {
+		return nil, err
 	}
 
 	if err := s.accountRepo.Create(account); err != nil {
@@ -100,7 +134,7 @@ func (s *accountService) CreateAccount(userID int64, req *request.CreateAccountR
 
 	logger.Info(fmt.Sprintf("[Service][账户] 创建成功 | 用户ID: %d | 账户ID: %d", userID, account.ID))
 	timer.LogSlowWithThreshold("创建账户完成", 500)
-	return s.toAccountResponse(account), nil
+	return toAccountResponse(account), nil
 }
 
 // UpdateAccount 更新账户
@@ -135,11 +169,23 @@ func (s *accountService) UpdateAccount(userID int64, accountID int64, req *reque
 	if req.IsActive != nil {
 		account.IsActive = *req.IsActive
 	}
+	if req.CreditLimit != nil {
+		account.CreditLimit = *req.CreditLimit
+	}
+	if req.BillingDay != nil {
+		account.BillingDay = req.BillingDay
+	}
+	if req.PaymentDueDay != nil {
+		account.PaymentDueDay = req.PaymentDueDay
+	}
+	if err := validateCreditSettings(account); err != nil {
+		return err
+	}
 
 	// 如果请求中包含Balance，说明是用户手动修正余额
 	if req.Balance != nil {
 		account.Balance = *req.Balance
-		logger.Info(fmt.Sprintf("[Service][账户] 手动修正余额 | 用户ID: %d | 账户ID: %d | 新余额: %.2f", userID, accountID, *req.Balance))
+		logger.Info(fmt.Sprintf("[Service][账户] 手动修正余额 | 用户ID: %d | 账户ID: %d | 新余额: %s", userID, accountID, *req.Balance))
 	}
 
 	return s.accountRepo.Update(account)
@@ -159,25 +205,76 @@ func (s *accountService) DeleteAccount(userID int64, accountID int64) error {
 	return s.accountRepo.Delete(accountID)
 }
 
-// GetAccountBalance 获取账户余额汇总
+// GetAccountBalance 获取账户余额汇总，外币账户按当前汇率折算为本位币
 func (s *accountService) GetAccountBalance(userID int64) (*response.AccountBalanceResponse, error) {
-	totalBalance, err := s.accountRepo.GetTotalBalance(userID)
+	accounts, err := s.accountRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.rates.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 总余额为净资产
+	assetBalance, debtBalance, err := accountTotals(s.rates, userID, base, accounts)
 	if err != nil {
 		return nil, err
 	}
 
-	// 目前只实现了资产账户，负债账户（信用卡等）后续实现
-	// 暂时将总资产视为净资产
 	return &response.AccountBalanceResponse{
-		TotalBalance: totalBalance,
-		AssetBalance: totalBalance,
-		DebtBalance:  0,
+		Currency:     base,
+		TotalBalance: assetBalance - debtBalance,
+		AssetBalance: assetBalance,
+		DebtBalance:  debtBalance,
 	}, nil
 }
 
+// accountTotals 汇总计入总资产的账户余额（按当前汇率折算为本位币），余额为负的账户（信用卡欠款等）计入负债
+func accountTotals(rates CurrencyConverter, userID int64, base string, accounts []*models.Account) (assets, debt money.Money, err error) {
+	for _, account := range accounts {
+		if !account.IncludeInTotal {
+			continue
+		}
+		balance, err := convertToBase(rates, userID, base, account.Balance, account.Currency)
+		if err != nil {
+			return 0, 0, err
+		}
+		if balance < 0 {
+			debt -= balance
+		} else {
+			assets += balance
+		}
+	}
+	return assets, debt, nil
+}
+
+// convertToBase 按当前汇率将金额折算为本位币
+func convertToBase(rates CurrencyConverter, userID int64, base string, amount money.Money, currency string) (money.Money, error) {
+	if currency == "" || currency == base {
+		return amount, nil
+	}
+	rate, err := rates.GetRate(userID, currency, base, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return amount.Convert(rate)
+}
+
+// withBaseBalance 填充折算后的本位币余额，缺少汇率时不返回
+func (s *accountService) withBaseBalance(userID int64, base string, resp *response.AccountResponse) *response.AccountResponse {
+	balance, err := convertToBase(s.rates, userID, base, resp.Balance, resp.Currency)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("[Service][账户] 余额折算失败 | 用户ID: %d | 账户ID: %d | 错误: %v", userID, resp.ID, err))
+		return resp
+	}
+	resp.BaseBalance = &balance
+	return resp
+}
+
 // toAccountResponse 转换为响应对象
-func (s *accountService) toAccountResponse(account *models.Account) *response.AccountResponse {
-	return &response.AccountResponse{
+func toAccountResponse(account *models.Account) *response.AccountResponse {
+	resp := &response.AccountResponse{
 		ID:             account.ID,
 		AccountType:    account.AccountType,
 		AccountName:    account.AccountName,
@@ -186,10 +283,19 @@ func (s *accountService) toAccountResponse(account *models.Account) *response.Ac
 		Color:          account.Color,
 		Balance:        account.Balance,
 		InitialBalance: account.InitialBalance,
+		Currency:       account.Currency,
+		CreditLimit:    account.CreditLimit,
+		BillingDay:     account.BillingDay,
+		PaymentDueDay:  account.PaymentDueDay,
 		IncludeInTotal: account.IncludeInTotal,
 		DisplayOrder:   account.DisplayOrder,
 		IsActive:       account.IsActive,
 		CreatedAt:      account.CreatedAt,
 		UpdatedAt:      account.UpdatedAt,
 	}
+	if account.AccountType == models.AccountTypeCredit {
+		available := availableCredit(account)
+		resp.AvailableCredit = &available
+	}
+	return resp
 }
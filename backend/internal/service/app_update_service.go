@@ -2,19 +2,22 @@ package service
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/qiuhaonan/float-backend/internal/dto/response"
 	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
 	"gorm.io/gorm"
 )
 
 type AppUpdateService struct {
-	repo *repository.AppUpdateRepository
+	repo     *repository.AppUpdateRepository
+	notifier NotificationService
 }
 
-func NewAppUpdateService(repo *repository.AppUpdateRepository) *AppUpdateService {
-	return &AppUpdateService{repo: repo}
+func NewAppUpdateService(repo *repository.AppUpdateRepository, notifier NotificationService) *AppUpdateService {
+	return &AppUpdateService{repo: repo, notifier: notifier}
 }
 
 // CheckUpdate 检查更新
@@ -120,7 +123,16 @@ func (s *AppUpdateService) GetHistory(platform string) ([]response.AppUpdateResp
 	return res, nil
 }
 
-// CreateAppUpdate 创建更新
+// CreateAppUpdate 创建更新，已发布的版本会通知所有用户
 func (s *AppUpdateService) CreateAppUpdate(update *models.AppUpdate) error {
-	return s.repo.Create(update)
+	if err := s.repo.Create(update); err != nil {
+		return err
+	}
+
+	if s.notifier != nil && update.Status == "released" {
+		if err := s.notifier.NotifyAppUpdate(update); err != nil {
+			logger.Warn(fmt.Sprintf("[Service][软件更新] 新版本通知失败 | 版本: %s | 错误: %v", update.VersionName, err))
+		}
+	}
+	return nil
 }
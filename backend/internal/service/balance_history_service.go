@@ -0,0 +1,383 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// 走势粒度
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+const maxHistoryPoints = 366 // 单次查询的最多时间点数
+
+var (
+	errHistoryDates = errors.New("开始日期不能晚于结束日期")
+	errHistoryRange = errors.New("时间范围过大，请缩小范围或使用更大的粒度")
+)
+
+// BalanceHistoryService 余额走势服务接口
+type BalanceHistoryService interface {
+	// GetHistory 获取净资产与各账户余额走势，查询前重建已过期的快照
+	GetHistory(userID int64, req *request.BalanceHistoryRequest) (*response.BalanceHistoryResponse, error)
+	// RefreshSnapshots 重建全部已过期（账户在快照生成后有更新）或尚未生成的余额快照，返回重建的账户数
+	RefreshSnapshots() (int, error)
+}
+
+type balanceHistoryService struct {
+	accountRepo     repository.AccountRepository
+	snapshotRepo    repository.BalanceSnapshotRepository
+	transactionRepo repository.TransactionRepository
+	loanRepo        repository.LoanRepository
+	rates           CurrencyConverter
+	mu              sync.Mutex // 避免定时任务与查询同时重建同一账户
+}
+
+// NewBalanceHistoryService 创建余额走势服务实例
+func NewBalanceHistoryService(
+	accountRepo repository.AccountRepository,
+	snapshotRepo repository.BalanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	loanRepo repository.LoanRepository,
+	rates CurrencyConverter,
+) BalanceHistoryService {
+	return &balanceHistoryService{
+		accountRepo:     accountRepo,
+		snapshotRepo:    snapshotRepo,
+		transactionRepo: transactionRepo,
+		loanRepo:        loanRepo,
+		rates:           rates,
+	}
+}
+
+// GetHistory 获取净资产与各账户余额走势
+func (s *balanceHistoryService) GetHistory(userID int64, req *request.BalanceHistoryRequest) (*response.BalanceHistoryResponse, error) {
+	granularity, start, end, err := historyRange(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	dates := historyPoints(start, end, granularity)
+	if len(dates) > maxHistoryPoints {
+		return nil, errHistoryRange
+	}
+
+	accounts, err := s.accountRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.refresh(accounts); err != nil {
+		logger.Error(fmt.Sprintf("[Service][余额走势] 重建快照失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	snapshots, err := s.snapshotRepo.FindByUserID(userID, end)
+	if err != nil {
+		return nil, err
+	}
+	byAccount := make(map[int64][]*models.AccountBalanceSnapshot)
+	for _, snapshot := range snapshots {
+		byAccount[snapshot.AccountID] = append(byAccount[snapshot.AccountID], snapshot)
+	}
+
+	loans, err := s.loanRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.rates.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.BalanceHistoryResponse{
+		Currency:    base,
+		Granularity: granularity,
+		StartDate:   start,
+		EndDate:     end,
+		NetWorth:    make([]*response.NetWorthPoint, 0, len(dates)),
+		Accounts:    make([]*response.AccountBalanceSeries, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		resp.Accounts = append(resp.Accounts, &response.AccountBalanceSeries{
+			AccountID:      account.ID,
+			AccountName:    account.AccountName,
+			AccountType:    account.AccountType,
+			Currency:       account.Currency,
+			IncludeInTotal: account.IncludeInTotal,
+			Points:         make([]*response.AccountBalancePoint, 0, len(dates)),
+		})
+	}
+
+	converter := newHistoryConverter(s.rates, userID, base)
+	for _, date := range dates {
+		point := &response.NetWorthPoint{Date: date}
+		for i, account := range accounts {
+			balance := balanceOn(byAccount[account.ID], date)
+			baseBalance, err := converter.convert(balance, account.Currency, date)
+			if err != nil {
+				return nil, err
+			}
+			resp.Accounts[i].Points = append(resp.Accounts[i].Points, &response.AccountBalancePoint{
+				Date:        date,
+				Balance:     balance,
+				BaseBalance: baseBalance,
+			})
+
+			if !account.IncludeInTotal {
+				continue
+			}
+			if baseBalance < 0 {
+				point.TotalDebt -= baseBalance
+			} else {
+				point.TotalAssets += baseBalance
+			}
+		}
+
+		for _, loan := range loans {
+			outstanding, err := converter.convert(loanOutstandingOn(loan, date), loan.Currency, date)
+			if err != nil {
+				return nil, err
+			}
+			point.TotalDebt += outstanding
+		}
+		point.NetWorth = point.TotalAssets - point.TotalDebt
+		resp.NetWorth = append(resp.NetWorth, point)
+	}
+
+	return resp, nil
+}
+
+// RefreshSnapshots 重建全部过期的余额快照
+func (s *balanceHistoryService) RefreshSnapshots() (int, error) {
+	accounts, err := s.accountRepo.FindAll()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][余额走势] 查询账户失败 | 错误: %v", err))
+		return 0, err
+	}
+
+	rebuilt, err := s.refresh(accounts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][余额走势] 重建快照失败 | 已重建: %d | 错误: %v", rebuilt, err))
+		return rebuilt, err
+	}
+	if rebuilt > 0 {
+		logger.Info(fmt.Sprintf("[Service][余额走势] 重建快照完成 | 账户数: %d", rebuilt))
+	}
+	return rebuilt, nil
+}
+
+// refresh 重建账户更新时间晚于快照生成时的账户的快照（含尚未生成快照的账户）
+func (s *balanceHistoryService) refresh(accounts []*models.Account) (int, error) {
+	if len(accounts) == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int64, 0, len(accounts))
+	for _, account := range accounts {
+		ids = append(ids, account.ID)
+	}
+	versions, err := s.snapshotRepo.FindBuiltVersions(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	rebuilt := 0
+	today := truncateToDate(time.Now())
+	for _, account := range accounts {
+		if version, ok := versions[account.ID]; ok && !account.UpdatedAt.After(version) {
+			continue
+		}
+		changes, err := s.transactionRepo.GetAccountDailyChanges(account.ID)
+		if err != nil {
+			return rebuilt, err
+		}
+		if err := s.snapshotRepo.ReplaceForAccount(account.ID, replayBalances(account, changes, today)); err != nil {
+			return rebuilt, err
+		}
+		rebuilt++
+	}
+	return rebuilt, nil
+}
+
+// replayBalances 从初始余额起按日累加交易净变动，生成有余额变动日期的日终余额
+// 首条快照为账户开立日（有更早的交易时为首笔交易日）；重放结果与当前余额不一致时（手工修正过余额），差额计入今天
+func replayBalances(account *models.Account, changes []*models.AccountBalanceSnapshot, today time.Time) []*models.AccountBalanceSnapshot {
+	opened := truncateToDate(account.CreatedAt)
+	var snapshots []*models.AccountBalanceSnapshot
+	add := func(date time.Time, balance money.Money) {
+		if n := len(snapshots); n > 0 && snapshots[n-1].SnapshotDate.Equal(date) {
+			snapshots[n-1].Balance = balance
+			return
+		}
+		snapshots = append(snapshots, &models.AccountBalanceSnapshot{
+			UserID:           account.UserID,
+			AccountID:        account.ID,
+			SnapshotDate:     date,
+			Balance:          balance,
+			AccountUpdatedAt: account.UpdatedAt,
+		})
+	}
+
+	balance := account.InitialBalance
+	if len(changes) == 0 || truncateToDate(changes[0].SnapshotDate).After(opened) {
+		add(opened, balance)
+	}
+	for _, change := range changes {
+		balance += change.Balance
+		add(truncateToDate(change.SnapshotDate), balance)
+	}
+
+	// 手工修正的差额自今天起生效（含今天之后日期的交易）
+	if diff := account.Balance - balance; diff != 0 {
+		i := sort.Search(len(snapshots), func(i int) bool { return !snapshots[i].SnapshotDate.Before(today) })
+		if i == len(snapshots) || !snapshots[i].SnapshotDate.Equal(today) {
+			var previous money.Money
+			if i > 0 {
+				previous = snapshots[i-1].Balance
+			}
+			snapshots = append(snapshots[:i], append([]*models.AccountBalanceSnapshot{{
+				UserID:           account.UserID,
+				AccountID:        account.ID,
+				SnapshotDate:     today,
+				Balance:          previous,
+				AccountUpdatedAt: account.UpdatedAt,
+			}}, snapshots[i:]...)...)
+		}
+		for _, snapshot := range snapshots[i:] {
+			snapshot.Balance += diff
+		}
+	}
+	return snapshots
+}
+
+// balanceOn 取不晚于指定日期的最近一条快照余额，早于首条快照（账户开立前）时为0
+func balanceOn(snapshots []*models.AccountBalanceSnapshot, date time.Time) money.Money {
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshots[i].SnapshotDate.After(date) })
+	if i == 0 {
+		return 0
+	}
+	return snapshots[i-1].Balance
+}
+
+// loanOutstandingOn 按还款计划推算贷款/分期在指定日期的剩余本金，录入前及已取消的为0
+func loanOutstandingOn(loan *models.Loan, date time.Time) money.Money {
+	if loan.Status == models.LoanStatusCancelled || date.Before(truncateToDate(loan.CreatedAt)) {
+		return 0
+	}
+	installments, err := buildLoanSchedule(loan.Principal, loan.AnnualRate, loan.RepaymentMethod, loan.Term, loan.FirstDueDate)
+	if err != nil {
+		return loan.OutstandingPrincipal
+	}
+
+	outstanding := loan.Principal
+	for _, installment := range installments {
+		if installment.DueDate.After(date) {
+			break
+		}
+		outstanding = installment.RemainingPrincipal
+	}
+	return outstanding
+}
+
+// historyRange 解析查询参数：默认粒度为天，结束日期默认今天，开始日期默认按粒度取最近30天、12周或12个月
+func historyRange(req *request.BalanceHistoryRequest, now time.Time) (string, time.Time, time.Time, error) {
+	granularity := req.Granularity
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+
+	end := truncateToDate(now)
+	if req.EndDate != nil {
+		end = truncateToDate(*req.EndDate)
+	}
+
+	var start time.Time
+	switch {
+	case req.StartDate != nil:
+		start = truncateToDate(*req.StartDate)
+	case granularity == GranularityWeek:
+		start = end.AddDate(0, 0, -7*11)
+	case granularity == GranularityMonth:
+		start = time.Date(end.Year(), end.Month()-11, 1, 0, 0, 0, 0, time.Local)
+	default:
+		start = end.AddDate(0, 0, -29)
+	}
+
+	if start.After(end) {
+		return "", time.Time{}, time.Time{}, errHistoryDates
+	}
+	return granularity, start, end, nil
+}
+
+// historyPoints 生成走势的时间点：每天、每周日或每月最后一天，最后一个时间点截止到结束日期
+func historyPoints(start, end time.Time, granularity string) []time.Time {
+	var dates []time.Time
+	for date := periodEnd(start, granularity); ; date = periodEnd(date.AddDate(0, 0, 1), granularity) {
+		if !date.Before(end) {
+			return append(dates, end)
+		}
+		dates = append(dates, date)
+		if len(dates) > maxHistoryPoints {
+			return dates
+		}
+	}
+}
+
+// periodEnd 日期所在周期的最后一天（周以周一至周日计）
+func periodEnd(date time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		return date.AddDate(0, 0, (7-int(date.Weekday()))%7)
+	case GranularityMonth:
+		return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.Local)
+	default:
+		return date
+	}
+}
+
+// historyConverter 按各时间点的汇率折算为本位币，同一币种同一天只查询一次
+type historyConverter struct {
+	rates  CurrencyConverter
+	userID int64
+	base   string
+	cache  map[string]money.Rate
+}
+
+func newHistoryConverter(rates CurrencyConverter, userID int64, base string) *historyConverter {
+	return &historyConverter{rates: rates, userID: userID, base: base, cache: make(map[string]money.Rate)}
+}
+
+// convert 按指定日期的汇率折算，早于最早汇率的日期按当前汇率折算
+func (c *historyConverter) convert(amount money.Money, currency string, date time.Time) (money.Money, error) {
+	if amount == 0 || currency == "" || currency == c.base {
+		return amount, nil
+	}
+
+	key := currency + date.Format("2006-01-02")
+	rate, ok := c.cache[key]
+	if !ok {
+		var err error
+		if rate, err = c.rates.GetRate(c.userID, currency, c.base, date); err != nil {
+			if rate, err = c.rates.GetRate(c.userID, currency, c.base, time.Now()); err != nil {
+				return 0, err
+			}
+		}
+		c.cache[key] = rate
+	}
+	return amount.Convert(rate)
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBalanceSnapshotRepository Mock BalanceSnapshotRepository
+type MockBalanceSnapshotRepository struct {
+	mock.Mock
+}
+
+func (m *MockBalanceSnapshotRepository) ReplaceForAccount(accountID int64, snapshots []*models.AccountBalanceSnapshot) error {
+	args := m.Called(accountID, snapshots)
+	return args.Error(0)
+}
+
+func (m *MockBalanceSnapshotRepository) FindByUserID(userID int64, until time.Time) ([]*models.AccountBalanceSnapshot, error) {
+	args := m.Called(userID, until)
+	return args.Get(0).([]*models.AccountBalanceSnapshot), args.Error(1)
+}
+
+func (m *MockBalanceSnapshotRepository) FindBuiltVersions(accountIDs []int64) (map[int64]time.Time, error) {
+	args := m.Called(accountIDs)
+	return args.Get(0).(map[int64]time.Time), args.Error(1)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func dailyChange(d time.Time, amount string) *models.AccountBalanceSnapshot {
+	return &models.AccountBalanceSnapshot{SnapshotDate: d, Balance: money.MustParse(amount)}
+}
+
+// TestReplayBalances 测试从初始余额重放交易：开立日为首条快照，同一天的变动合并
+func TestReplayBalances(t *testing.T) {
+	account := &models.Account{ID: 1, UserID: 1, InitialBalance: money.MustParse("1000.00"), Balance: money.MustParse("700.00"), CreatedAt: date(2024, 3, 1)}
+	changes := []*models.AccountBalanceSnapshot{dailyChange(date(2024, 3, 5), "-200.00"), dailyChange(date(2024, 3, 9), "-100.00")}
+
+	snapshots := replayBalances(account, changes, date(2024, 3, 20))
+
+	assert.Len(t, snapshots, 3)
+	assert.Equal(t, date(2024, 3, 1), snapshots[0].SnapshotDate)
+	assert.Equal(t, money.MustParse("1000.00"), snapshots[0].Balance)
+	assert.Equal(t, money.MustParse("800.00"), snapshots[1].Balance)
+	assert.Equal(t, money.MustParse("700.00"), snapshots[2].Balance)
+
+	// 开立前补记的交易从首笔交易日开始重放
+	early := replayBalances(account, append([]*models.AccountBalanceSnapshot{dailyChange(date(2024, 2, 20), "0.00")}, changes...), date(2024, 3, 20))
+	assert.Equal(t, date(2024, 2, 20), early[0].SnapshotDate)
+	assert.Len(t, early, 3)
+}
+
+// TestReplayBalancesManualCorrection 测试手工修正余额的差额自今天起生效
+func TestReplayBalancesManualCorrection(t *testing.T) {
+	account := &models.Account{ID: 1, UserID: 1, InitialBalance: money.MustParse("1000.00"), Balance: money.MustParse("950.00"), CreatedAt: date(2024, 3, 1)}
+	changes := []*models.AccountBalanceSnapshot{dailyChange(date(2024, 3, 5), "-200.00"), dailyChange(date(2024, 3, 25), "100.00")}
+
+	snapshots := replayBalances(account, changes, date(2024, 3, 20))
+
+	assert.Len(t, snapshots, 4)
+	assert.Equal(t, money.MustParse("800.00"), snapshots[1].Balance)
+	assert.Equal(t, date(2024, 3, 20), snapshots[2].SnapshotDate)
+	assert.Equal(t, money.MustParse("850.00"), snapshots[2].Balance)
+	assert.Equal(t, money.MustParse("950.00"), snapshots[3].Balance)
+
+	assert.Equal(t, money.Money(0), balanceOn(snapshots, date(2024, 2, 28)))
+	assert.Equal(t, money.MustParse("800.00"), balanceOn(snapshots, date(2024, 3, 19)))
+}
+
+// TestHistoryPoints 测试按周（周日）和按月（月末）取时间点，最后一点截止到结束日期
+func TestHistoryPoints(t *testing.T) {
+	weeks := historyPoints(date(2024, 3, 6), date(2024, 3, 20), GranularityWeek)
+	assert.Equal(t, []time.Time{date(2024, 3, 10), date(2024, 3, 17), date(2024, 3, 20)}, weeks)
+
+	months := historyPoints(date(2024, 1, 15), date(2024, 3, 31), GranularityMonth)
+	assert.Equal(t, []time.Time{date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31)}, months)
+
+	days := historyPoints(date(2024, 3, 1), date(2024, 3, 3), GranularityDay)
+	assert.Len(t, days, 3)
+
+	_, _, _, err := historyRange(&request.BalanceHistoryRequest{StartDate: timePtr(date(2024, 3, 5)), EndDate: timePtr(date(2024, 3, 1))}, date(2024, 3, 20))
+	assert.Equal(t, errHistoryDates, err)
+}
+
+// TestGetBalanceHistory 测试重建过期快照后按时间点汇总净资产（外币按当日汇率折算，贷款按还款计划计入负债）
+func TestGetBalanceHistory(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockSnapshotRepo := new(MockBalanceSnapshotRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockLoanRepo := new(MockLoanRepository)
+	mockRates := new(MockCurrencyConverter)
+	service := NewBalanceHistoryService(mockAccountRepo, mockSnapshotRepo, mockTransRepo, mockLoanRepo, mockRates)
+
+	updated := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	cny := &models.Account{ID: 1, UserID: 1, AccountName: "招商银行", Currency: "CNY", IncludeInTotal: true, UpdatedAt: updated}
+	usd := &models.Account{ID: 2, UserID: 1, AccountName: "美元账户", Currency: "USD", IncludeInTotal: true, UpdatedAt: updated,
+		InitialBalance: money.MustParse("100.00"), Balance: money.MustParse("100.00"), CreatedAt: date(2024, 3, 2)}
+	mockAccountRepo.On("FindByUserID", int64(1)).Return([]*models.Account{cny, usd}, nil)
+
+	// 账户1的快照未过期，账户2尚未生成快照
+	mockSnapshotRepo.On("FindBuiltVersions", []int64{1, 2}).Return(map[int64]time.Time{1: updated}, nil)
+	mockTransRepo.On("GetAccountDailyChanges", int64(2)).Return([]*models.AccountBalanceSnapshot{}, nil)
+	mockSnapshotRepo.On("ReplaceForAccount", int64(2), mock.Anything).Return(nil)
+	mockSnapshotRepo.On("FindByUserID", int64(1), date(2024, 3, 3)).Return([]*models.AccountBalanceSnapshot{
+		{AccountID: 1, SnapshotDate: date(2024, 3, 1), Balance: money.MustParse("1000.00")},
+		{AccountID: 1, SnapshotDate: date(2024, 3, 3), Balance: money.MustParse("-50.00")},
+		{AccountID: 2, SnapshotDate: date(2024, 3, 2), Balance: money.MustParse("100.00")},
+	}, nil)
+
+	mockLoanRepo.On("FindByUserID", int64(1)).Return([]*models.Loan{{
+		ID: 1, Principal: money.MustParse("300.00"), RepaymentMethod: models.RepaymentEqualPrincipal, Term: 3,
+		FirstDueDate: date(2024, 3, 2), Currency: "CNY", Status: models.LoanStatusActive, CreatedAt: date(2024, 2, 1),
+	}}, nil)
+	mockRates.On("BaseCurrency", int64(1)).Return("CNY", nil)
+	mockRates.On("GetRate", int64(1), "USD", "CNY", date(2024, 3, 2)).Return(money.MustParseRate("7.2"), nil)
+	mockRates.On("GetRate", int64(1), "USD", "CNY", date(2024, 3, 3)).Return(money.MustParseRate("7.1"), nil)
+
+	history, err := service.GetHistory(1, &request.BalanceHistoryRequest{StartDate: timePtr(date(2024, 3, 1)), EndDate: timePtr(date(2024, 3, 3))})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "CNY", history.Currency)
+	assert.Len(t, history.NetWorth, 3)
+
+	first := history.NetWorth[0]
+	assert.Equal(t, money.MustParse("1000.00"), first.TotalAssets)
+	assert.Equal(t, money.MustParse("300.00"), first.TotalDebt)
+
+	second := history.NetWorth[1]
+	assert.Equal(t, money.MustParse("1720.00"), second.TotalAssets)
+	assert.Equal(t, money.MustParse("200.00"), second.TotalDebt)
+	assert.Equal(t, money.MustParse("1520.00"), second.NetWorth)
+
+	third := history.NetWorth[2]
+	assert.Equal(t, money.MustParse("710.00"), third.TotalAssets)
+	assert.Equal(t, money.MustParse("250.00"), third.TotalDebt)
+	assert.Equal(t, money.MustParse("460.00"), third.NetWorth)
+
+	assert.Equal(t, money.Money(0), history.Accounts[1].Points[0].Balance)
+	assert.Equal(t, money.MustParse("710.00"), history.Accounts[1].Points[2].BaseBalance)
+	mockSnapshotRepo.AssertNotCalled(t, "ReplaceForAccount", int64(1), mock.Anything)
+}
+
+// TestBalanceHistoryAfterDateChange 测试仅修改交易日期时余额不变，但账户更新时间刷新，余额走势按新日期重建
+func TestBalanceHistoryAfterDateChange(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	transactionService := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, nil)
+
+	built := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	accountID := int64(100)
+	account := &models.Account{ID: accountID, UserID: 1, Currency: "CNY", CreatedAt: date(2024, 3, 1), UpdatedAt: built,
+		InitialBalance: money.MustParse("1000.00"), Balance: money.MustParse("900.00")}
+	transaction := &models.Transaction{ID: 1, UserID: 1, Type: "expense", AccountID: &accountID, Amount: money.MustParse("100.00"),
+		Currency: "CNY", ExchangeRate: money.OneRate, TransactionDate: date(2024, 3, 5)}
+
+	mockTransRepo.On("FindByIDForUpdate", int64(1)).Return(transaction, nil)
+	mockTransRepo.On("Update", transaction).Return(nil)
+	mockTransRepo.On("FindByID", int64(1)).Return(transaction, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	// 模拟GORM保存时刷新更新时间
+	mockAccountRepo.On("Update", account).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Account).UpdatedAt = built.Add(time.Hour)
+	}).Return(nil)
+
+	_, err := transactionService.UpdateTransaction(1, 1, &request.UpdateTransactionRequest{TransactionDate: date(2024, 3, 8)})
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("900.00"), account.Balance)
+	assert.True(t, account.UpdatedAt.After(built))
+
+	mockSnapshotRepo := new(MockBalanceSnapshotRepository)
+	mockLoanRepo := new(MockLoanRepository)
+	mockRates := new(MockCurrencyConverter)
+	service := NewBalanceHistoryService(mockAccountRepo, mockSnapshotRepo, mockTransRepo, mockLoanRepo, mockRates)
+
+	mockAccountRepo.On("FindByUserID", int64(1)).Return([]*models.Account{account}, nil)
+	mockSnapshotRepo.On("FindBuiltVersions", []int64{accountID}).Return(map[int64]time.Time{accountID: built}, nil)
+	mockTransRepo.On("GetAccountDailyChanges", accountID).Return([]*models.AccountBalanceSnapshot{dailyChange(date(2024, 3, 8), "-100.00")}, nil)
+	// 重建后的快照供随后的读取返回
+	mockSnapshotRepo.On("ReplaceForAccount", accountID, mock.Anything).Run(func(args mock.Arguments) {
+		mockSnapshotRepo.On("FindByUserID", int64(1), date(2024, 3, 9)).Return(args.Get(1).([]*models.AccountBalanceSnapshot), nil)
+	}).Return(nil).Once()
+	mockLoanRepo.On("FindByUserID", int64(1)).Return([]*models.Loan{}, nil)
+	mockRates.On("BaseCurrency", int64(1)).Return("CNY", nil)
+
+	history, err := service.GetHistory(1, &request.BalanceHistoryRequest{StartDate: timePtr(date(2024, 3, 4)), EndDate: timePtr(date(2024, 3, 9))})
+
+	assert.NoError(t, err)
+	points := history.Accounts[0].Points
+	assert.Equal(t, date(2024, 3, 7), points[3].Date)
+	assert.Equal(t, money.MustParse("1000.00"), points[3].Balance)
+	assert.Equal(t, money.MustParse("900.00"), points[4].Balance)
+}
@@ -0,0 +1,563 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// 账单历史默认返回条数
+const billHistoryLimit = 50
+
+var (
+	errBillNotFound    = errors.New("账单不存在")
+	errBillAlreadyPaid = errors.New("该期账单已支付，请刷新后重试")
+)
+
+// BillService 账单订阅服务接口
+type BillService interface {
+	GetBills(userID int64, activeOnly bool) ([]*response.BillResponse, error)
+	GetBill(userID int64, billID int64) (*response.BillDetailResponse, error)
+	CreateBill(userID int64, req *request.CreateBillRequest) (*response.BillResponse, error)
+	UpdateBill(userID int64, billID int64, req *request.UpdateBillRequest) (*response.BillResponse, error)
+	DeleteBill(userID int64, billID int64) error
+	GetUpcomingBills(userID int64, days int) ([]*response.BillResponse, error)
+	PayBill(userID int64, billID int64, req *request.PayBillRequest) (*response.PayBillResponse, error)
+	// SendDueReminders 为进入提醒窗口的账单发送到期通知，返回发送数量
+	SendDueReminders(now time.Time) (int, error)
+}
+
+type billService struct {
+	billRepo           repository.BillRepository
+	accountRepo        repository.AccountRepository
+	categoryRepo       repository.CategoryRepository
+	transactionService TransactionService
+	notifier           NotificationService
+}
+
+// NewBillService 创建账单服务实例
+func NewBillService(
+	billRepo repository.BillRepository,
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+	transactionService TransactionService,
+	notifier NotificationService,
+) BillService {
+	return &billService{
+		billRepo:           billRepo,
+		accountRepo:        accountRepo,
+		categoryRepo:       categoryRepo,
+		transactionService: transactionService,
+		notifier:           notifier,
+	}
+}
+
+// GetBills 获取账单列表
+func (s *billService) GetBills(userID int64, activeOnly bool) ([]*response.BillResponse, error) {
+	bills, err := s.billRepo.FindByUserID(userID, activeOnly)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][账单] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	result := make([]*response.BillResponse, 0, len(bills))
+	for _, bill := range bills {
+		result = append(result, s.toBillResponse(bill))
+	}
+	return result, nil
+}
+
+// GetBill 获取账单详情（含历史记录）
+func (s *billService) GetBill(userID int64, billID int64) (*response.BillDetailResponse, error) {
+	bill, err := s.findUserBill(userID, billID)
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := s.billRepo.FindHistoryByBillID(billID, billHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &response.BillDetailResponse{
+		BillResponse: s.toBillResponse(bill),
+		History:      make([]*response.BillHistoryResponse, 0, len(histories)),
+	}
+	for _, h := range histories {
+		detail.History = append(detail.History, s.toBillHistoryResponse(h))
+	}
+	return detail, nil
+}
+
+// CreateBill 创建账单
+func (s *billService) CreateBill(userID int64, req *request.CreateBillRequest) (*response.BillResponse, error) {
+	if err := s.validateReferences(userID, req.AccountID, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	bill := &models.Bill{
+		UserID:           userID,
+		BillName:         req.BillName,
+		CategoryID:       req.CategoryID,
+		AccountID:        req.AccountID,
+		Amount:           req.Amount,
+		Currency:         req.Currency,
+		BillingCycle:     req.BillingCycle,
+		BillingDay:       req.BillingDay,
+		IntervalDays:     req.IntervalDays,
+		AutoRenew:        true,
+		IsActive:         true,
+		Icon:             req.Icon,
+		Color:            req.Color,
+		Description:      req.Description,
+		RemindDaysBefore: 3,
+	}
+	currency, err := s.billCurrency(bill)
+	if err != nil {
+		return nil, err
+	}
+	bill.Currency = currency
+	if req.AutoRenew != nil {
+		bill.AutoRenew = *req.AutoRenew
+	}
+	if req.RemindDaysBefore != nil {
+		bill.RemindDaysBefore = *req.RemindDaysBefore
+	}
+	if err := validateBillCycle(bill); err != nil {
+		return nil, err
+	}
+
+	if req.NextBillingDate != nil {
+		bill.NextBillingDate = truncateToDate(*req.NextBillingDate)
+	} else {
+		bill.NextBillingDate = firstBillingDate(bill, truncateToDate(time.Now()))
+	}
+
+	if err := s.billRepo.Create(bill); err != nil {
+		logger.Error(fmt.Sprintf("[Service][账单] 创建失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, errors.New("创建账单失败")
+	}
+
+	logger.Info(fmt.Sprintf("[Service][账单] 创建成功 | 用户ID: %d | 账单ID: %d | 下次扣费: %s", userID, bill.ID, bill.NextBillingDate.Format("2006-01-02")))
+	return s.toBillResponse(bill), nil
+}
+
+// UpdateBill 更新账单
+func (s *billService) UpdateBill(userID int64, billID int64, req *request.UpdateBillRequest) (*response.BillResponse, error) {
+	bill, err := s.findUserBill(userID, billID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateReferences(userID, req.AccountID, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	if req.BillName != nil {
+		bill.BillName = *req.BillName
+	}
+	if req.CategoryID != nil {
+		bill.CategoryID = req.CategoryID
+		bill.Category = nil
+	}
+	if req.AccountID != nil {
+		bill.AccountID = req.AccountID
+		bill.Account = nil
+	}
+	if req.Amount != nil {
+		bill.Amount = *req.Amount
+	}
+	if req.Currency != nil {
+		bill.Currency = *req.Currency
+	}
+	if req.BillingCycle != nil {
+		bill.BillingCycle = *req.BillingCycle
+	}
+	if req.BillingDay != nil {
+		bill.BillingDay = req.BillingDay
+	}
+	if req.IntervalDays != nil {
+		bill.IntervalDays = *req.IntervalDays
+	}
+	if req.NextBillingDate != nil {
+		bill.NextBillingDate = truncateToDate(*req.NextBillingDate)
+	}
+	if req.AutoRenew != nil {
+		bill.AutoRenew = *req.AutoRenew
+	}
+	if req.IsActive != nil {
+		bill.IsActive = *req.IsActive
+	}
+	if req.Icon != nil {
+		bill.Icon = *req.Icon
+	}
+	if req.Color != nil {
+		bill.Color = *req.Color
+	}
+	if req.Description != nil {
+		bill.Description = *req.Description
+	}
+	if req.RemindDaysBefore != nil {
+		bill.RemindDaysBefore = *req.RemindDaysBefore
+	}
+	if err := validateBillCycle(bill); err != nil {
+		return nil, err
+	}
+	// 更换支付账户或币种时重新校验币种，否则每次付款都会因币种不一致失败
+	if req.AccountID != nil || req.Currency != nil {
+		if bill.Currency, err = s.billCurrency(bill); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.billRepo.Update(bill); err != nil {
+		logger.Error(fmt.Sprintf("[Service][账单] 更新失败 | 用户ID: %d | 账单ID: %d | 错误: %v", userID, billID, err))
+		return nil, errors.New("更新账单失败")
+	}
+
+	updated, err := s.billRepo.FindByID(billID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toBillResponse(updated), nil
+}
+
+// DeleteBill 删除账单（已生成的交易记录保留）
+func (s *billService) DeleteBill(userID int64, billID int64) error {
+	if _, err := s.findUserBill(userID, billID); err != nil {
+		return err
+	}
+	return s.billRepo.Delete(billID)
+}
+
+// GetUpcomingBills 获取未来days天内到期（含已逾期）的账单
+func (s *billService) GetUpcomingBills(userID int64, days int) ([]*response.BillResponse, error) {
+	if days <= 0 {
+		days = 7
+	}
+	until := truncateToDate(time.Now()).AddDate(0, 0, days)
+
+	bills, err := s.billRepo.FindDueBefore(userID, until)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*response.BillResponse, 0, len(bills))
+	for _, bill := range bills {
+		result = append(result, s.toBillResponse(bill))
+	}
+	return result, nil
+}
+
+// PayBill 标记本期账单已支付：生成关联交易、写入账单历史并推进下次扣费日期
+func (s *billService) PayBill(userID int64, billID int64, req *request.PayBillRequest) (*response.PayBillResponse, error) {
+	bill, err := s.findUserBill(userID, billID)
+	if err != nil {
+		return nil, err
+	}
+	if !bill.IsActive {
+		return nil, errors.New("账单已停用")
+	}
+
+	amount := bill.Amount
+	if req.Amount > 0 {
+		amount = req.Amount
+	}
+	accountID := bill.AccountID
+	if req.AccountID != nil {
+		accountID = req.AccountID
+	}
+	if accountID == nil {
+		return nil, errors.New("账单未设置支付账户")
+	}
+	transactionDate := truncateToDate(time.Now())
+	if req.TransactionDate != nil {
+		transactionDate = truncateToDate(*req.TransactionDate)
+	}
+
+	billingDate := bill.NextBillingDate
+	transReq := &request.CreateTransactionRequest{
+		Type:            string(models.TransactionTypeExpense),
+		CategoryID:      bill.CategoryID,
+		AccountID:       accountID,
+		Amount:          amount,
+		Currency:        bill.Currency,
+		Title:           bill.BillName,
+		Description:     req.Notes,
+		TransactionDate: transactionDate,
+		BillID:          &bill.ID,
+	}
+
+	var history *models.BillHistory
+	var paidBill *models.Bill
+	transaction, err := s.transactionService.CreateLinkedTransaction(userID, transReq, func(repos *repository.TxRepositories, t *models.Transaction) error {
+		// 加锁重读，防止同一期账单被重复支付
+		locked, err := repos.Bills.FindByIDForUpdate(bill.ID)
+		if err != nil {
+			return err
+		}
+		if !locked.NextBillingDate.Equal(billingDate) {
+			return errBillAlreadyPaid
+		}
+
+		history = &models.BillHistory{
+			BillID:        locked.ID,
+			TransactionID: &t.ID,
+			Amount:        amount,
+			BillingDate:   billingDate,
+			Status:        "paid",
+			Notes:         req.Notes,
+		}
+		if err := repos.Bills.CreateHistory(history); err != nil {
+			return err
+		}
+
+		locked.NextBillingDate = nextBillingDate(locked, billingDate)
+		if !locked.AutoRenew {
+			locked.IsActive = false
+		}
+		paidBill = locked
+		return repos.Bills.Update(locked)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][账单] 支付失败 | 用户ID: %d | 账单ID: %d | 错误: %v", userID, billID, err))
+		if errors.Is(err, errBillAlreadyPaid) {
+			return nil, errBillAlreadyPaid
+		}
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][账单] 支付成功 | 用户ID: %d | 账单ID: %d | 交易ID: %d | 下次扣费: %s",
+		userID, billID, transaction.ID, paidBill.NextBillingDate.Format("2006-01-02")))
+	paidBill.Category = bill.Category
+	paidBill.Account = bill.Account
+	return &response.PayBillResponse{
+		Transaction: transaction,
+		History:     s.toBillHistoryResponse(history),
+		Bill:        s.toBillResponse(paidBill),
+	}, nil
+}
+
+// SendDueReminders 发送账单到期提醒，同一账单同一期仅提醒一次
+func (s *billService) SendDueReminders(now time.Time) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+	bills, err := s.billRepo.FindReminderDue(truncateToDate(now))
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][账单] 查询待提醒账单失败 | 错误: %v", err))
+		return 0, err
+	}
+
+	sent := 0
+	for _, bill := range bills {
+		if err := s.notifier.NotifyBillDue(bill); err != nil {
+			logger.Error(fmt.Sprintf("[Service][账单] 到期提醒失败 | 用户ID: %d | 账单ID: %d | 错误: %v", bill.UserID, bill.ID, err))
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// findUserBill 查找并校验账单归属
+func (s *billService) findUserBill(userID int64, billID int64) (*models.Bill, error) {
+	bill, err := s.billRepo.FindByID(billID)
+	if err != nil || bill.UserID != userID {
+		return nil, errBillNotFound
+	}
+	return bill, nil
+}
+
+// validateReferences 校验账户与分类归属（系统分类user_id为0）
+func (s *billService) validateReferences(userID int64, accountID, categoryID *int64) error {
+	if accountID != nil {
+		account, err := s.accountRepo.FindByID(*accountID)
+		if err != nil || account.UserID != userID {
+			return errors.New("invalid account")
+		}
+	}
+	if categoryID != nil {
+		category, err := s.categoryRepo.FindByID(*categoryID)
+		if err != nil || (category.UserID != userID && category.UserID != 0) {
+			return errors.New("invalid category")
+		}
+	}
+	return nil
+}
+
+// billCurrency 确定账单币种：未指定时沿用支付账户币种，指定时须与支付账户币种一致
+func (s *billService) billCurrency(bill *models.Bill) (string, error) {
+	var account *models.Account
+	if bill.AccountID != nil {
+		var err error
+		if account, err = s.accountRepo.FindByID(*bill.AccountID); err != nil {
+			return "", errors.New("invalid account")
+		}
+	}
+	return transactionCurrency(bill.Currency, account)
+}
+
+// validateBillCycle 校验周期参数
+func validateBillCycle(bill *models.Bill) error {
+	if bill.BillingCycle == models.BillingCycleCustom && bill.IntervalDays <= 0 {
+		return errors.New("自定义周期需设置间隔天数")
+	}
+	if bill.BillingDay != nil && (*bill.BillingDay < 1 || *bill.BillingDay > 31) {
+		return errors.New("账单日需在1-31之间")
+	}
+	return nil
+}
+
+// firstBillingDate 未指定首次扣费日期时，根据账单日推算今天及之后的第一期
+func firstBillingDate(bill *models.Bill, today time.Time) time.Time {
+	if bill.BillingDay == nil {
+		return today
+	}
+	switch bill.BillingCycle {
+	case models.BillingCycleMonthly, models.BillingCycleQuarterly:
+		candidate := dateWithDay(today.Year(), today.Month(), *bill.BillingDay, today.Location())
+		if candidate.Before(today) {
+			candidate = addMonthsClamped(candidate, 1, *bill.BillingDay)
+		}
+		return candidate
+	case models.BillingCycleYearly:
+		candidate := dateWithDay(today.Year(), today.Month(), *bill.BillingDay, today.Location())
+		if candidate.Before(today) {
+			candidate = addMonthsClamped(candidate, 12, *bill.BillingDay)
+		}
+		return candidate
+	default:
+		return today
+	}
+}
+
+// nextBillingDate 根据账单周期计算from之后的下一次扣费日期
+func nextBillingDate(bill *models.Bill, from time.Time) time.Time {
+	day := from.Day()
+	if bill.BillingDay != nil {
+		day = *bill.BillingDay
+	}
+
+	switch bill.BillingCycle {
+	case models.BillingCycleDaily:
+		return from.AddDate(0, 0, 1)
+	case models.BillingCycleWeekly:
+		return from.AddDate(0, 0, 7)
+	case models.BillingCycleMonthly:
+		return addMonthsClamped(from, 1, day)
+	case models.BillingCycleQuarterly:
+		return addMonthsClamped(from, 3, day)
+	case models.BillingCycleYearly:
+		return addMonthsClamped(from, 12, day)
+	case models.BillingCycleCustom:
+		return from.AddDate(0, 0, bill.IntervalDays)
+	default:
+		return addMonthsClamped(from, 1, day)
+	}
+}
+
+// addMonthsClamped 增加月份并将日期定位到day，超出当月天数时取月末（如1月31日 -> 2月28日）
+func addMonthsClamped(t time.Time, months int, day int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).AddDate(0, months, 0)
+	return dateWithDay(firstOfMonth.Year(), firstOfMonth.Month(), day, t.Location())
+}
+
+// dateWithDay 构造指定年月的日期，day超出当月天数时取月末
+func dateWithDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// truncateToDate 去掉时间部分，仅保留本地日期
+func truncateToDate(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// toBillResponse 转换为响应对象
+func (s *billService) toBillResponse(bill *models.Bill) *response.BillResponse {
+	resp := &response.BillResponse{
+		ID:               bill.ID,
+		BillName:         bill.BillName,
+		CategoryID:       bill.CategoryID,
+		AccountID:        bill.AccountID,
+		Amount:           bill.Amount,
+		Currency:         bill.Currency,
+		BillingCycle:     bill.BillingCycle,
+		BillingDay:       bill.BillingDay,
+		IntervalDays:     bill.IntervalDays,
+		NextBillingDate:  bill.NextBillingDate,
+		DaysUntilDue:     int(truncateToDate(bill.NextBillingDate).Sub(truncateToDate(time.Now())).Hours() / 24),
+		AutoRenew:        bill.AutoRenew,
+		IsActive:         bill.IsActive,
+		Icon:             bill.Icon,
+		Color:            bill.Color,
+		Description:      bill.Description,
+		RemindDaysBefore: bill.RemindDaysBefore,
+		CreatedAt:        bill.CreatedAt,
+		UpdatedAt:        bill.UpdatedAt,
+	}
+
+	if bill.Category != nil {
+		resp.Category = &response.CategoryResponse{
+			ID:           bill.Category.ID,
+			UserID:       bill.Category.UserID,
+			Type:         bill.Category.Type,
+			Name:         bill.Category.Name,
+			Icon:         bill.Category.Icon,
+			Color:        bill.Category.Color,
+			DisplayOrder: bill.Category.DisplayOrder,
+			IsSystem:     bill.Category.IsSystem,
+			IsActive:     bill.Category.IsActive,
+			CreatedAt:    bill.Category.CreatedAt,
+			UpdatedAt:    bill.Category.UpdatedAt,
+		}
+	}
+
+	if bill.Account != nil {
+		resp.Account = &response.AccountResponse{
+			ID:             bill.Account.ID,
+			UserID:         bill.Account.UserID,
+			AccountType:    bill.Account.AccountType,
+			AccountName:    bill.Account.AccountName,
+			AccountNumber:  bill.Account.AccountNumber,
+			Icon:           bill.Account.Icon,
+			Color:          bill.Account.Color,
+			Balance:        bill.Account.Balance,
+			InitialBalance: bill.Account.InitialBalance,
+			IncludeInTotal: bill.Account.IncludeInTotal,
+			DisplayOrder:   bill.Account.DisplayOrder,
+			IsActive:       bill.Account.IsActive,
+			CreatedAt:      bill.Account.CreatedAt,
+			UpdatedAt:      bill.Account.UpdatedAt,
+		}
+	}
+
+	return resp
+}
+
+// toBillHistoryResponse 转换为响应对象
+func (s *billService) toBillHistoryResponse(h *models.BillHistory) *response.BillHistoryResponse {
+	if h == nil {
+		return nil
+	}
+	return &response.BillHistoryResponse{
+		ID:            h.ID,
+		BillID:        h.BillID,
+		TransactionID: h.TransactionID,
+		Amount:        h.Amount,
+		BillingDate:   h.BillingDate,
+		Status:        h.Status,
+		Notes:         h.Notes,
+		CreatedAt:     h.CreatedAt,
+	}
+}
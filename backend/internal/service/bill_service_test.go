@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBillRepository Mock BillRepository
+type MockBillRepository struct {
+	mock.Mock
+}
+
+func (m *MockBillRepository) Create(bill *models.Bill) error {
+	args := m.Called(bill)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) FindByID(id int64) (*models.Bill, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) FindByIDForUpdate(id int64) (*models.Bill, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) FindByUserID(userID int64, activeOnly bool) ([]*models.Bill, error) {
+	args := m.Called(userID, activeOnly)
+	return args.Get(0).([]*models.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) FindDueBefore(userID int64, date time.Time) ([]*models.Bill, error) {
+	args := m.Called(userID, date)
+	return args.Get(0).([]*models.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) FindReminderDue(date time.Time) ([]*models.Bill, error) {
+	args := m.Called(date)
+	return args.Get(0).([]*models.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) Update(bill *models.Bill) error {
+	args := m.Called(bill)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) CountActive(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBillRepository) CreateHistory(history *models.BillHistory) error {
+	args := m.Called(history)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) FindHistoryByBillID(billID int64, limit int) ([]*models.BillHistory, error) {
+	args := m.Called(billID, limit)
+	return args.Get(0).([]*models.BillHistory), args.Error(1)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// TestNextBillingDate 测试各周期下次扣费日期计算（含月末边界）
+func TestNextBillingDate(t *testing.T) {
+	day31 := 31
+	day29 := 29
+	tests := []struct {
+		name string
+		bill *models.Bill
+		from time.Time
+		want time.Time
+	}{
+		{"daily", &models.Bill{BillingCycle: models.BillingCycleDaily}, date(2024, 12, 31), date(2025, 1, 1)},
+		{"weekly", &models.Bill{BillingCycle: models.BillingCycleWeekly}, date(2024, 2, 26), date(2024, 3, 4)},
+		{"monthly month end", &models.Bill{BillingCycle: models.BillingCycleMonthly, BillingDay: &day31}, date(2024, 1, 31), date(2024, 2, 29)},
+		{"monthly restores day", &models.Bill{BillingCycle: models.BillingCycleMonthly, BillingDay: &day31}, date(2024, 2, 29), date(2024, 3, 31)},
+		{"quarterly", &models.Bill{BillingCycle: models.BillingCycleQuarterly, BillingDay: &day31}, date(2024, 11, 30), date(2025, 2, 28)},
+		{"yearly leap day", &models.Bill{BillingCycle: models.BillingCycleYearly, BillingDay: &day29}, date(2024, 2, 29), date(2025, 2, 28)},
+		{"custom", &models.Bill{BillingCycle: models.BillingCycleCustom, IntervalDays: 10}, date(2024, 1, 25), date(2024, 2, 4)},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, nextBillingDate(tt.bill, tt.from), tt.name)
+	}
+}
+
+// TestFirstBillingDate 测试未指定首次扣费日期时的推算
+func TestFirstBillingDate(t *testing.T) {
+	day5 := 5
+	day20 := 20
+	today := date(2024, 3, 10)
+
+	assert.Equal(t, date(2024, 4, 5), firstBillingDate(&models.Bill{BillingCycle: models.BillingCycleMonthly, BillingDay: &day5}, today))
+	assert.Equal(t, date(2024, 3, 20), firstBillingDate(&models.Bill{BillingCycle: models.BillingCycleMonthly, BillingDay: &day20}, today))
+	assert.Equal(t, today, firstBillingDate(&models.Bill{BillingCycle: models.BillingCycleWeekly}, today))
+}
+
+func newTestBillService() (BillService, *MockBillRepository, *MockTransactionRepository, *MockAccountRepository, *MockCategoryRepository) {
+	mockBillRepo := new(MockBillRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo, billRepo: mockBillRepo}
+	transService := newTestTransactionService(uow, mockCategoryRepo)
+	return NewBillService(mockBillRepo, mockAccountRepo, mockCategoryRepo, transService, nil), mockBillRepo, mockTransRepo, mockAccountRepo, mockCategoryRepo
+}
+
+// TestPayBill 测试支付账单：生成交易、记录历史并推进下次扣费日期
+func TestPayBill(t *testing.T) {
+	service, mockBillRepo, mockTransRepo, mockAccountRepo, _ := newTestBillService()
+
+	userID := int64(1)
+	billID := int64(7)
+	accountID := int64(100)
+	billingDay := 31
+	dueDate := date(2024, 1, 31)
+
+	bill := &models.Bill{
+		ID:              billID,
+		UserID:          userID,
+		BillName:        "视频会员",
+		AccountID:       &accountID,
+		Amount:          money.MustParse("25.00"),
+		Currency:        "CNY",
+		BillingCycle:    models.BillingCycleMonthly,
+		BillingDay:      &billingDay,
+		NextBillingDate: dueDate,
+		AutoRenew:       true,
+		IsActive:        true,
+	}
+	locked := *bill
+	mockBillRepo.On("FindByID", billID).Return(bill, nil)
+	mockBillRepo.On("FindByIDForUpdate", billID).Return(&locked, nil)
+
+	account := &models.Account{ID: accountID, UserID: userID, Balance: money.MustParse("100.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+
+	mockTransRepo.On("Create", mock.MatchedBy(func(tr *models.Transaction) bool {
+		return tr.BillID != nil && *tr.BillID == billID && tr.Amount == money.MustParse("25.00") && tr.Type == "expense"
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transaction).ID = 55
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(55)).Return(&models.Transaction{ID: 55, UserID: userID, Type: "expense", Amount: money.MustParse("25.00")}, nil)
+
+	mockBillRepo.On("CreateHistory", mock.MatchedBy(func(h *models.BillHistory) bool {
+		return h.BillID == billID && *h.TransactionID == 55 && h.BillingDate.Equal(dueDate) && h.Status == "paid"
+	})).Return(nil)
+	mockBillRepo.On("Update", mock.MatchedBy(func(b *models.Bill) bool {
+		return b.NextBillingDate.Equal(date(2024, 2, 29))
+	})).Return(nil)
+
+	resp, err := service.PayBill(userID, billID, &request.PayBillRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(55), resp.Transaction.ID)
+	assert.Equal(t, date(2024, 2, 29), resp.Bill.NextBillingDate)
+	assert.Equal(t, money.MustParse("75.00"), account.Balance)
+	mockBillRepo.AssertExpectations(t)
+}
+
+// TestPayBillAlreadyPaid 测试同一期账单并发重复支付时整体回滚
+func TestPayBillAlreadyPaid(t *testing.T) {
+	service, mockBillRepo, mockTransRepo, mockAccountRepo, _ := newTestBillService()
+
+	userID := int64(1)
+	billID := int64(7)
+	accountID := int64(100)
+
+	bill := &models.Bill{
+		ID:              billID,
+		UserID:          userID,
+		AccountID:       &accountID,
+		Amount:          money.MustParse("25.00"),
+		BillingCycle:    models.BillingCycleWeekly,
+		NextBillingDate: date(2024, 1, 1),
+		IsActive:        true,
+	}
+	// 另一请求已推进到下一期
+	locked := *bill
+	locked.NextBillingDate = date(2024, 1, 8)
+	mockBillRepo.On("FindByID", billID).Return(bill, nil)
+	mockBillRepo.On("FindByIDForUpdate", billID).Return(&locked, nil)
+
+	account := &models.Account{ID: accountID, UserID: userID}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockTransRepo.On("Create", mock.Anything).Return(nil)
+
+	_, err := service.PayBill(userID, billID, &request.PayBillRequest{})
+
+	assert.True(t, errors.Is(err, errBillAlreadyPaid))
+	mockBillRepo.AssertNotCalled(t, "CreateHistory", mock.Anything)
+	mockBillRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+// TestPayBillOtherUser 测试不能支付他人账单
+func TestPayBillOtherUser(t *testing.T) {
+	service, mockBillRepo, _, _, _ := newTestBillService()
+
+	mockBillRepo.On("FindByID", int64(7)).Return(&models.Bill{ID: 7, UserID: 2, IsActive: true}, nil)
+
+	_, err := service.PayBill(1, 7, &request.PayBillRequest{})
+	assert.Equal(t, errBillNotFound, err)
+}
+
+// TestBillCurrencyFollowsAccount 测试未指定币种的账单沿用支付账户币种，更换账户时重新校验币种
+func TestBillCurrencyFollowsAccount(t *testing.T) {
+	service, mockBillRepo, _, mockAccountRepo, _ := newTestBillService()
+
+	userID := int64(1)
+	usdAccount, cnyAccount := int64(100), int64(101)
+	mockAccountRepo.On("FindByID", usdAccount).Return(&models.Account{ID: usdAccount, UserID: userID, Currency: "USD"}, nil)
+	mockAccountRepo.On("FindByID", cnyAccount).Return(&models.Account{ID: cnyAccount, UserID: userID, Currency: "CNY"}, nil)
+	var created *models.Bill
+	mockBillRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.Bill)
+		created.ID = 7
+	}).Return(nil)
+
+	resp, err := service.CreateBill(userID, &request.CreateBillRequest{
+		BillName: "Netflix", AccountID: &usdAccount, Amount: money.MustParse("15.49"), BillingCycle: models.BillingCycleMonthly,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", resp.Currency)
+
+	_, err = service.CreateBill(userID, &request.CreateBillRequest{
+		BillName: "Netflix", AccountID: &usdAccount, Amount: money.MustParse("15.49"), Currency: "EUR", BillingCycle: models.BillingCycleMonthly,
+	})
+	assert.Equal(t, errCurrencyMismatch, err)
+
+	// 更换为人民币账户但未修改币种时拒绝，同时修改币种后通过
+	mockBillRepo.On("FindByID", int64(7)).Return(created, nil)
+	mockBillRepo.On("Update", mock.Anything).Return(nil)
+	_, err = service.UpdateBill(userID, 7, &request.UpdateBillRequest{AccountID: &cnyAccount})
+	assert.Equal(t, errCurrencyMismatch, err)
+
+	created.AccountID, created.Currency = &usdAccount, "USD"
+	currency := "CNY"
+	resp, err = service.UpdateBill(userID, 7, &request.UpdateBillRequest{AccountID: &cnyAccount, Currency: &currency})
+	assert.NoError(t, err)
+	assert.Equal(t, "CNY", resp.Currency)
+}
@@ -0,0 +1,532 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// 预算默认预警线与超支预警线（百分比）
+const (
+	defaultBudgetAlertThreshold = 0.80
+	budgetExceededLevel         = 100
+)
+
+// 预算执行状态
+const (
+	BudgetStatusNormal   = "normal"
+	BudgetStatusWarning  = "warning"
+	BudgetStatusExceeded = "exceeded"
+)
+
+var errBudgetNotFound = errors.New("预算不存在")
+
+// BudgetChecker 交易写入后检查相关预算是否越过预警线
+type BudgetChecker interface {
+	CheckThresholds(userID int64, categoryID *int64, date time.Time) error
+}
+
+// BudgetService 预算服务接口
+type BudgetService interface {
+	GetBudgets(userID int64, activeOnly bool) ([]*response.BudgetResponse, error)
+	GetBudget(userID int64, budgetID int64) (*response.BudgetResponse, error)
+	CreateBudget(userID int64, req *request.CreateBudgetRequest) (*response.BudgetResponse, error)
+	UpdateBudget(userID int64, budgetID int64, req *request.UpdateBudgetRequest) (*response.BudgetResponse, error)
+	DeleteBudget(userID int64, budgetID int64) error
+	// CheckThresholds 检查覆盖该分类与日期的预算，首次越过预警线或100%时发送通知
+	CheckThresholds(userID int64, categoryID *int64, date time.Time) error
+}
+
+type budgetService struct {
+	budgetRepo      repository.BudgetRepository
+	transactionRepo repository.TransactionRepository
+	categoryRepo    repository.CategoryRepository
+	notifier        NotificationService
+}
+
+// NewBudgetService 创建预算服务实例
+func NewBudgetService(
+	budgetRepo repository.BudgetRepository,
+	transactionRepo repository.TransactionRepository,
+	categoryRepo repository.CategoryRepository,
+	notifier NotificationService,
+) BudgetService {
+	return &budgetService{
+		budgetRepo:      budgetRepo,
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+		notifier:        notifier,
+	}
+}
+
+// budgetUsage 预算在某一周期内的执行情况
+type budgetUsage struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Rollover    money.Money
+	Available   money.Money
+	Spent       money.Money
+	Projected   money.Money
+}
+
+// categorySpending 按周期缓存分类支出统计，同一次请求内多个预算共用查询结果。
+// 分类预算包含其下级分类的支出，与汇总后的分类统计一致
+type categorySpending struct {
+	transactionRepo repository.TransactionRepository
+	categoryRepo    repository.CategoryRepository
+	userID          int64
+	cache           map[string][]*models.Transaction
+	categories      map[int64]*models.Category
+}
+
+func newCategorySpending(transactionRepo repository.TransactionRepository, categoryRepo repository.CategoryRepository, userID int64) *categorySpending {
+	return &categorySpending{
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+		userID:          userID,
+		cache:           make(map[string][]*models.Transaction),
+		categories:      make(map[int64]*models.Category),
+	}
+}
+
+// spent 统计周期内的支出，categoryID为空时统计全部分类
+func (c *categorySpending) spent(categoryID *int64, start, end time.Time) (money.Money, error) {
+	key := start.Format("2006-01-02") + "~" + end.Format("2006-01-02")
+	rows, ok := c.cache[key]
+	if !ok {
+		var err error
+		rows, err = c.transactionRepo.GetCategoryStatistics(c.userID, start, end)
+		if err != nil {
+			return 0, err
+		}
+		c.cache[key] = rows
+	}
+
+	var total money.Money
+	for _, row := range rows {
+		if categoryID == nil || (row.CategoryID != nil && c.covers(*categoryID, *row.CategoryID)) {
+			total += row.Amount
+		}
+	}
+	return total, nil
+}
+
+// covers 判断分类是否为budgetCategoryID本身或其下级分类
+func (c *categorySpending) covers(budgetCategoryID, categoryID int64) bool {
+	for _, id := range c.chain(categoryID) {
+		if id == budgetCategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// chain 分类自身及沿上级链的全部上级分类ID，上级分类已删除时停在当前层级
+func (c *categorySpending) chain(categoryID int64) []int64 {
+	ids := []int64{categoryID}
+	for depth := 1; depth < maxCategoryDepth; depth++ {
+		category := c.findCategory(ids[len(ids)-1])
+		if category == nil || category.ParentID == nil {
+			break
+		}
+		ids = append(ids, *category.ParentID)
+	}
+	return ids
+}
+
+func (c *categorySpending) findCategory(id int64) *models.Category {
+	if category, ok := c.categories[id]; ok {
+		return category
+	}
+	category, err := c.categoryRepo.FindByID(id)
+	if err != nil {
+		category = nil
+	}
+	c.categories[id] = category
+	return category
+}
+
+// GetBudgets 获取预算列表（含当前周期执行情况）
+func (s *budgetService) GetBudgets(userID int64, activeOnly bool) ([]*response.BudgetResponse, error) {
+	budgets, err := s.budgetRepo.FindByUserID(userID, activeOnly)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][预算] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	now := time.Now()
+	spending := newCategorySpending(s.transactionRepo, s.categoryRepo, userID)
+	result := make([]*response.BudgetResponse, 0, len(budgets))
+	for _, budget := range budgets {
+		usage, err := computeBudgetUsage(budget, spending, now)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][预算] 统计支出失败 | 用户ID: %d | 预算ID: %d | 错误: %v", userID, budget.ID, err))
+			return nil, err
+		}
+		result = append(result, s.toBudgetResponse(budget, usage))
+	}
+	return result, nil
+}
+
+// GetBudget 获取预算详情
+func (s *budgetService) GetBudget(userID int64, budgetID int64) (*response.BudgetResponse, error) {
+	budget, err := s.findUserBudget(userID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildResponse(budget)
+}
+
+// CreateBudget 创建预算
+func (s *budgetService) CreateBudget(userID int64, req *request.CreateBudgetRequest) (*response.BudgetResponse, error) {
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.FindByID(*req.CategoryID)
+		if err != nil || (category.UserID != userID && category.UserID != 0) {
+			return nil, errors.New("invalid category")
+		}
+		if category.Type != "expense" {
+			return nil, errors.New("预算仅支持支出分类")
+		}
+	}
+
+	budget := &models.Budget{
+		UserID:         userID,
+		CategoryID:     req.CategoryID,
+		Name:           req.Name,
+		BudgetAmount:   req.BudgetAmount,
+		PeriodType:     req.PeriodType,
+		EndDate:        req.EndDate,
+		Rollover:       req.Rollover,
+		AlertThreshold: defaultBudgetAlertThreshold,
+		IsActive:       true,
+	}
+	if req.StartDate != nil {
+		budget.StartDate = truncateToDate(*req.StartDate)
+	} else {
+		budget.StartDate = defaultBudgetStart(budget.PeriodType, time.Now())
+	}
+	if req.AlertThreshold != nil {
+		budget.AlertThreshold = *req.AlertThreshold
+	}
+	if budget.EndDate != nil && budget.EndDate.Before(budget.StartDate) {
+		return nil, errors.New("结束日期不能早于开始日期")
+	}
+
+	if err := s.budgetRepo.Create(budget); err != nil {
+		logger.Error(fmt.Sprintf("[Service][预算] 创建失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][预算] 创建成功 | 用户ID: %d | 预算ID: %d | 周期: %s", userID, budget.ID, budget.PeriodType))
+	created, err := s.budgetRepo.FindByID(budget.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildResponse(created)
+}
+
+// UpdateBudget 更新预算
+func (s *budgetService) UpdateBudget(userID int64, budgetID int64, req *request.UpdateBudgetRequest) (*response.BudgetResponse, error) {
+	budget, err := s.findUserBudget(userID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		budget.Name = *req.Name
+	}
+	if req.BudgetAmount != nil {
+		budget.BudgetAmount = *req.BudgetAmount
+	}
+	if req.EndDate != nil {
+		budget.EndDate = req.EndDate
+	}
+	if req.Rollover != nil {
+		budget.Rollover = *req.Rollover
+	}
+	if req.AlertThreshold != nil {
+		budget.AlertThreshold = *req.AlertThreshold
+	}
+	if req.IsActive != nil {
+		budget.IsActive = *req.IsActive
+	}
+	if budget.EndDate != nil && budget.EndDate.Before(budget.StartDate) {
+		return nil, errors.New("结束日期不能早于开始日期")
+	}
+
+	if err := s.budgetRepo.Update(budget); err != nil {
+		logger.Error(fmt.Sprintf("[Service][预算] 更新失败 | 用户ID: %d | 预算ID: %d | 错误: %v", userID, budgetID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][预算] 更新成功 | 用户ID: %d | 预算ID: %d", userID, budgetID))
+	return s.buildResponse(budget)
+}
+
+// DeleteBudget 删除预算
+func (s *budgetService) DeleteBudget(userID int64, budgetID int64) error {
+	if _, err := s.findUserBudget(userID, budgetID); err != nil {
+		return err
+	}
+	if err := s.budgetRepo.Delete(budgetID); err != nil {
+		logger.Error(fmt.Sprintf("[Service][预算] 删除失败 | 用户ID: %d | 预算ID: %d | 错误: %v", userID, budgetID, err))
+		return err
+	}
+	logger.Info(fmt.Sprintf("[Service][预算] 删除成功 | 用户ID: %d | 预算ID: %d", userID, budgetID))
+	return nil
+}
+
+// CheckThresholds 检查覆盖该分类与日期的预算
+// 每个周期内同一预警线只通知一次；支出回落到预警线以下后再次越过会重新通知
+func (s *budgetService) CheckThresholds(userID int64, categoryID *int64, date time.Time) error {
+	// 上级分类的预算同样覆盖该分类
+	spending := newCategorySpending(s.transactionRepo, s.categoryRepo, userID)
+	var categoryIDs []int64
+	if categoryID != nil {
+		categoryIDs = spending.chain(*categoryID)
+	}
+	budgets, err := s.budgetRepo.FindActiveForCategory(userID, categoryIDs, date)
+	if err != nil {
+		return err
+	}
+
+	for _, budget := range budgets {
+		usage, err := computeBudgetUsage(budget, spending, date)
+		if err != nil {
+			return err
+		}
+
+		// 历史周期的交易不覆盖更新周期的预警状态
+		if budget.AlertPeriod != nil && budget.AlertPeriod.After(usage.PeriodStart) {
+			continue
+		}
+		samePeriod := budget.AlertPeriod != nil && budget.AlertPeriod.Equal(usage.PeriodStart)
+		level := budgetAlertLevel(budget, usage)
+
+		if samePeriod && level == budget.AlertLevel {
+			continue
+		}
+		if !samePeriod && level == 0 {
+			continue
+		}
+		if err := s.budgetRepo.UpdateAlertState(budget.ID, usage.PeriodStart, level); err != nil {
+			return err
+		}
+		if level == 0 || (samePeriod && level < budget.AlertLevel) {
+			continue
+		}
+
+		logger.Info(fmt.Sprintf("[Service][预算] 越过预警线 | 用户ID: %d | 预算ID: %d | 预警线: %d%%", userID, budget.ID, level))
+		if s.notifier != nil {
+			if err := s.notifier.NotifyBudgetThreshold(userID, budget.ID, budgetDisplayName(budget), level, usage.Spent, usage.Available); err != nil {
+				logger.Warn(fmt.Sprintf("[Service][预算] 预警通知失败 | 用户ID: %d | 预算ID: %d | 错误: %v", userID, budget.ID, err))
+			}
+		}
+	}
+	return nil
+}
+
+// findUserBudget 查找并校验预算归属
+func (s *budgetService) findUserBudget(userID int64, budgetID int64) (*models.Budget, error) {
+	budget, err := s.budgetRepo.FindByID(budgetID)
+	if err != nil || budget.UserID != userID {
+		return nil, errBudgetNotFound
+	}
+	return budget, nil
+}
+
+// buildResponse 计算当前周期执行情况并转换为响应对象
+func (s *budgetService) buildResponse(budget *models.Budget) (*response.BudgetResponse, error) {
+	usage, err := computeBudgetUsage(budget, newCategorySpending(s.transactionRepo, s.categoryRepo, budget.UserID), time.Now())
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][预算] 统计支出失败 | 用户ID: %d | 预算ID: %d | 错误: %v", budget.UserID, budget.ID, err))
+		return nil, err
+	}
+	return s.toBudgetResponse(budget, usage), nil
+}
+
+// computeBudgetUsage 计算at所在周期的支出、结转与预计支出
+func computeBudgetUsage(budget *models.Budget, spending *categorySpending, at time.Time) (*budgetUsage, error) {
+	start, end := budgetPeriod(budget, at)
+	spent, err := spending.spent(budget.CategoryID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &budgetUsage{
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Available:   budget.BudgetAmount,
+		Spent:       spent,
+	}
+
+	// 结转仅计算上一周期未用完的额度，超支不向后结转
+	if budget.Rollover && start.After(truncateToDate(budget.StartDate)) {
+		prevStart, prevEnd := budgetPeriod(budget, start.AddDate(0, 0, -1))
+		prevSpent, err := spending.spent(budget.CategoryID, prevStart, prevEnd)
+		if err != nil {
+			return nil, err
+		}
+		if prevSpent < budget.BudgetAmount {
+			usage.Rollover = budget.BudgetAmount - prevSpent
+		}
+		usage.Available += usage.Rollover
+	}
+
+	if usage.Projected, err = projectSpending(spent, start, end, at); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// projectSpending 按周期内已过天数的平均速度推算整个周期的支出
+func projectSpending(spent money.Money, start, end, at time.Time) (money.Money, error) {
+	today := truncateToDate(at)
+	if today.After(end) {
+		return spent, nil
+	}
+	totalDays := int64(end.Sub(start).Hours()/24) + 1
+	elapsedDays := int64(today.Sub(start).Hours()/24) + 1
+	if elapsedDays <= 0 {
+		return spent, nil
+	}
+	return spent.MulRat(big.NewRat(totalDays, elapsedDays))
+}
+
+// budgetAlertLevel 返回当前已越过的最高预警线（0表示未越过）
+func budgetAlertLevel(budget *models.Budget, usage *budgetUsage) int {
+	if usage.Spent <= 0 {
+		return 0
+	}
+	if usage.Spent >= usage.Available {
+		return budgetExceededLevel
+	}
+	threshold := budget.AlertThreshold
+	if threshold <= 0 || threshold >= 1 {
+		threshold = defaultBudgetAlertThreshold
+	}
+	if usage.Spent.Float64() >= usage.Available.Float64()*threshold {
+		return int(math.Round(threshold * 100))
+	}
+	return 0
+}
+
+// budgetPeriod 计算date所在的预算周期[start, end]，以StartDate为锚点
+func budgetPeriod(budget *models.Budget, date time.Time) (time.Time, time.Time) {
+	anchor := truncateToDate(budget.StartDate)
+	day := truncateToDate(date)
+	if day.Before(anchor) {
+		day = anchor
+	}
+
+	switch budget.PeriodType {
+	case models.BudgetPeriodWeekly:
+		weeks := int(day.Sub(anchor).Hours()/24) / 7
+		start := anchor.AddDate(0, 0, weeks*7)
+		return start, start.AddDate(0, 0, 6)
+	case models.BudgetPeriodYearly:
+		return monthlyPeriod(anchor, day, 12)
+	default:
+		return monthlyPeriod(anchor, day, 1)
+	}
+}
+
+// monthlyPeriod 以anchor为锚点、每months个月一个周期，返回day所在周期
+func monthlyPeriod(anchor, day time.Time, months int) (time.Time, time.Time) {
+	diff := (day.Year()-anchor.Year())*12 + int(day.Month()-anchor.Month())
+	n := diff / months * months
+	start := addMonthsClamped(anchor, n, anchor.Day())
+	if start.After(day) {
+		n -= months
+		start = addMonthsClamped(anchor, n, anchor.Day())
+	}
+	end := addMonthsClamped(anchor, n+months, anchor.Day()).AddDate(0, 0, -1)
+	return start, end
+}
+
+// defaultBudgetStart 未指定开始日期时取当前周期起点：本周一/本月1日/本年1月1日
+func defaultBudgetStart(periodType string, now time.Time) time.Time {
+	today := truncateToDate(now)
+	switch periodType {
+	case models.BudgetPeriodWeekly:
+		offset := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -offset)
+	case models.BudgetPeriodYearly:
+		return time.Date(today.Year(), 1, 1, 0, 0, 0, 0, today.Location())
+	default:
+		return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	}
+}
+
+// budgetDisplayName 预算名称，未命名时使用分类名
+func budgetDisplayName(budget *models.Budget) string {
+	if budget.Name != "" {
+		return budget.Name
+	}
+	if budget.Category != nil {
+		return budget.Category.Name
+	}
+	return "总预算"
+}
+
+// toBudgetResponse 转换为响应对象
+func (s *budgetService) toBudgetResponse(budget *models.Budget, usage *budgetUsage) *response.BudgetResponse {
+	resp := &response.BudgetResponse{
+		ID:              budget.ID,
+		Name:            budgetDisplayName(budget),
+		CategoryID:      budget.CategoryID,
+		BudgetAmount:    budget.BudgetAmount,
+		PeriodType:      budget.PeriodType,
+		StartDate:       budget.StartDate,
+		EndDate:         budget.EndDate,
+		Rollover:        budget.Rollover,
+		AlertThreshold:  budget.AlertThreshold,
+		IsActive:        budget.IsActive,
+		PeriodStart:     usage.PeriodStart,
+		PeriodEnd:       usage.PeriodEnd,
+		RolloverAmount:  usage.Rollover,
+		AvailableAmount: usage.Available,
+		SpentAmount:     usage.Spent,
+		RemainingAmount: usage.Available - usage.Spent,
+		ProjectedAmount: usage.Projected,
+		Status:          BudgetStatusNormal,
+		CreatedAt:       budget.CreatedAt,
+		UpdatedAt:       budget.UpdatedAt,
+	}
+
+	if usage.Available > 0 {
+		resp.UsagePercent = math.Round(usage.Spent.Float64()/usage.Available.Float64()*10000) / 100
+	}
+	switch level := budgetAlertLevel(budget, usage); {
+	case level >= budgetExceededLevel && usage.Spent > usage.Available:
+		resp.Status = BudgetStatusExceeded
+	case level > 0:
+		resp.Status = BudgetStatusWarning
+	}
+
+	if budget.Category != nil {
+		resp.Category = &response.CategoryResponse{
+			ID:           budget.Category.ID,
+			UserID:       budget.Category.UserID,
+			Type:         budget.Category.Type,
+			Name:         budget.Category.Name,
+			Icon:         budget.Category.Icon,
+			Color:        budget.Category.Color,
+			DisplayOrder: budget.Category.DisplayOrder,
+			IsSystem:     budget.Category.IsSystem,
+			IsActive:     budget.Category.IsActive,
+			CreatedAt:    budget.Category.CreatedAt,
+			UpdatedAt:    budget.Category.UpdatedAt,
+		}
+	}
+	return resp
+}
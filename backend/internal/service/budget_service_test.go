@@ -0,0 +1,179 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBudgetRepository Mock BudgetRepository
+type MockBudgetRepository struct {
+	mock.Mock
+}
+
+func (m *MockBudgetRepository) Create(budget *models.Budget) error {
+	args := m.Called(budget)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) FindByID(id int64) (*models.Budget, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetRepository) FindByUserID(userID int64, activeOnly bool) ([]*models.Budget, error) {
+	args := m.Called(userID, activeOnly)
+	return args.Get(0).([]*models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetRepository) FindActiveForCategory(userID int64, categoryIDs []int64, date time.Time) ([]*models.Budget, error) {
+	args := m.Called(userID, categoryIDs, date)
+	return args.Get(0).([]*models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetRepository) Update(budget *models.Budget) error {
+	args := m.Called(budget)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) UpdateAlertState(id int64, period time.Time, level int) error {
+	args := m.Called(id, period, level)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) CountActive(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// TestBudgetPeriod 测试以开始日期为锚点的周期划分
+func TestBudgetPeriod(t *testing.T) {
+	monthly := &models.Budget{PeriodType: models.BudgetPeriodMonthly, StartDate: date(2024, 1, 31)}
+	start, end := budgetPeriod(monthly, date(2024, 3, 15))
+	assert.Equal(t, date(2024, 2, 29), start)
+	assert.Equal(t, date(2024, 3, 30), end)
+
+	start, end = budgetPeriod(monthly, date(2024, 1, 31))
+	assert.Equal(t, date(2024, 1, 31), start)
+	assert.Equal(t, date(2024, 2, 28), end)
+
+	weekly := &models.Budget{PeriodType: models.BudgetPeriodWeekly, StartDate: date(2024, 1, 1)}
+	start, end = budgetPeriod(weekly, date(2024, 1, 17))
+	assert.Equal(t, date(2024, 1, 15), start)
+	assert.Equal(t, date(2024, 1, 21), end)
+
+	yearly := &models.Budget{PeriodType: models.BudgetPeriodYearly, StartDate: date(2023, 4, 1)}
+	start, end = budgetPeriod(yearly, date(2024, 3, 31))
+	assert.Equal(t, date(2023, 4, 1), start)
+	assert.Equal(t, date(2024, 3, 31), end)
+	start, _ = budgetPeriod(yearly, date(2024, 4, 1))
+	assert.Equal(t, date(2024, 4, 1), start)
+}
+
+// TestComputeBudgetUsage 测试分类支出汇总（含下级分类）、结转与预计支出
+func TestComputeBudgetUsage(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	food := int64(3)
+	other := int64(4)
+	snack := int64(5)
+
+	mockCategoryRepo.On("FindByID", food).Return(&models.Category{ID: food}, nil)
+	mockCategoryRepo.On("FindByID", other).Return(&models.Category{ID: other}, nil)
+	mockCategoryRepo.On("FindByID", snack).Return(&models.Category{ID: snack, ParentID: &food}, nil)
+	mockTransRepo.On("GetCategoryStatistics", int64(1), date(2024, 3, 1), date(2024, 3, 31)).Return([]*models.Transaction{
+		{CategoryID: &food, Amount: money.MustParse("260.00")},
+		{CategoryID: &snack, Amount: money.MustParse("40.00")},
+		{CategoryID: &other, Amount: money.MustParse("50.00")},
+	}, nil).Once()
+	mockTransRepo.On("GetCategoryStatistics", int64(1), date(2024, 2, 1), date(2024, 2, 29)).Return([]*models.Transaction{
+		{CategoryID: &food, Amount: money.MustParse("800.00")},
+	}, nil).Once()
+
+	budget := &models.Budget{
+		UserID:       1,
+		CategoryID:   &food,
+		BudgetAmount: money.MustParse("1000.00"),
+		PeriodType:   models.BudgetPeriodMonthly,
+		StartDate:    date(2024, 1, 1),
+		Rollover:     true,
+	}
+	spending := newCategorySpending(mockTransRepo, mockCategoryRepo, 1)
+
+	usage, err := computeBudgetUsage(budget, spending, date(2024, 3, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("300.00"), usage.Spent)
+	assert.Equal(t, money.MustParse("200.00"), usage.Rollover)
+	assert.Equal(t, money.MustParse("1200.00"), usage.Available)
+	assert.Equal(t, money.MustParse("930.00"), usage.Projected) // 300 / 10天 * 31天
+
+	// 总预算统计全部分类，同一周期复用查询结果
+	overall := &models.Budget{UserID: 1, BudgetAmount: money.MustParse("2000.00"), PeriodType: models.BudgetPeriodMonthly, StartDate: date(2024, 3, 1)}
+	usage, err = computeBudgetUsage(overall, spending, date(2024, 3, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("350.00"), usage.Spent)
+	assert.Equal(t, money.Zero, usage.Rollover)
+	mockTransRepo.AssertNumberOfCalls(t, "GetCategoryStatistics", 2)
+}
+
+// TestCheckThresholdsOncePerLevel 测试同一周期每条预警线只通知一次
+func TestCheckThresholdsOncePerLevel(t *testing.T) {
+	mockBudgetRepo := new(MockBudgetRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockNotifyRepo := new(MockNotificationRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	svc := NewBudgetService(mockBudgetRepo, mockTransRepo, mockCategoryRepo, NewNotificationService(mockNotifyRepo))
+
+	food := int64(3)
+	day := date(2024, 3, 10)
+	mockCategoryRepo.On("FindByID", food).Return(&models.Category{ID: food}, nil)
+	periodStart, periodEnd := date(2024, 3, 1), date(2024, 3, 31)
+	budget := &models.Budget{
+		ID:             5,
+		UserID:         1,
+		CategoryID:     &food,
+		Name:           "餐饮",
+		BudgetAmount:   money.MustParse("1000.00"),
+		PeriodType:     models.BudgetPeriodMonthly,
+		StartDate:      periodStart,
+		AlertThreshold: 0.8,
+	}
+	mockBudgetRepo.On("FindActiveForCategory", int64(1), []int64{food}, day).Return([]*models.Budget{budget}, nil)
+	mockBudgetRepo.On("UpdateAlertState", int64(5), periodStart, mock.Anything).Run(func(args mock.Arguments) {
+		budget.AlertPeriod = &periodStart
+		budget.AlertLevel = args.Int(2)
+	}).Return(nil)
+
+	var levels []string
+	mockNotifyRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		levels = append(levels, args.Get(0).(*models.Notification).Priority)
+	}).Return(nil)
+
+	spend := func(amount string) {
+		mockTransRepo.On("GetCategoryStatistics", int64(1), periodStart, periodEnd).Return([]*models.Transaction{
+			{CategoryID: &food, Amount: money.MustParse(amount)},
+		}, nil).Once()
+		assert.NoError(t, svc.CheckThresholds(1, &food, day))
+	}
+
+	spend("500.00")  // 未达预警线
+	spend("850.00")  // 越过80%
+	spend("900.00")  // 仍在80%，不重复通知
+	spend("1000.00") // 达到100%
+	spend("1200.00") // 已超支，不重复通知
+
+	assert.Equal(t, []string{models.NotificationPriorityMedium, models.NotificationPriorityHigh}, levels)
+	assert.Equal(t, budgetExceededLevel, budget.AlertLevel)
+}
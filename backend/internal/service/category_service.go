@@ -2,36 +2,57 @@ package service
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/qiuhaonan/float-backend/internal/dto/request"
 	"github.com/qiuhaonan/float-backend/internal/dto/response"
 	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// maxCategoryDepth 分类最大层级，顶级分类为第1层
+const maxCategoryDepth = 3
+
+var (
+	errCategoryParent      = errors.New("上级分类不存在")
+	errCategoryParentType  = errors.New("上级分类的收支类型必须一致")
+	errCategoryCycle       = errors.New("不能移到自身或其下级分类下")
+	errCategoryDepth       = fmt.Errorf("分类最多支持%d级", maxCategoryDepth)
+	errCategoryHasChildren = errors.New("该分类下还有子分类，请选择移到其他分类下或一并删除")
+	errCategoryMergeTarget = errors.New("目标分类不存在或已停用")
+	errCategoryMergeSelf   = errors.New("源分类不能包含目标分类")
 )
 
 // CategoryService 分类服务接口
 type CategoryService interface {
-	GetCategories(userID int64, categoryType string) ([]*response.CategoryResponse, error)
+	// GetCategories 获取分类列表，tree 为 true 时按树形返回，下级分类在 Children 中
+	GetCategories(userID int64, categoryType string, tree bool) ([]*response.CategoryResponse, error)
 	GetCategoryByID(userID int64, categoryID int64) (*response.CategoryResponse, error)
 	CreateCategory(userID int64, req *request.CreateCategoryRequest) (*response.CategoryResponse, error)
 	UpdateCategory(userID int64, categoryID int64, req *request.UpdateCategoryRequest) error
-	DeleteCategory(userID int64, categoryID int64) error
+	// DeleteCategory 删除分类，存在下级分类时按 req 移到其他分类下或一并删除
+	DeleteCategory(userID int64, categoryID int64, req *request.DeleteCategoryRequest) error
+	// MergeCategories 合并重复分类：源分类的交易等移到目标分类（同一事务），然后停用源分类
+	MergeCategories(userID int64, req *request.MergeCategoriesRequest) (*response.MergeCategoriesResponse, error)
 	GetSystemCategories(categoryType string) ([]*response.CategoryResponse, error)
 }
 
 type categoryService struct {
 	categoryRepo repository.CategoryRepository
+	suggestions  CategoryModelInvalidator // 可选，合并分类后丢弃分类建议模型
 }
 
 // NewCategoryService 创建分类服务实例
-func NewCategoryService() CategoryService {
+func NewCategoryService(suggestions CategoryModelInvalidator) CategoryService {
 	return &categoryService{
 		categoryRepo: repository.NewCategoryRepository(),
+		suggestions:  suggestions,
 	}
 }
 
 // GetCategories 获取分类列表（包含用户自定义+系统默认）
-func (s *categoryService) GetCategories(userID int64, categoryType string) ([]*response.CategoryResponse, error) {
+func (s *categoryService) GetCategories(userID int64, categoryType string, tree bool) ([]*response.CategoryResponse, error) {
 	// 获取用户自定义分类
 	userCategories, err := s.categoryRepo.FindByUserID(userID, categoryType)
 	if err != nil {
@@ -44,20 +65,38 @@ func (s *categoryService) GetCategories(userID int64, categoryType string) ([]*r
 		return nil, err
 	}
 
-	// 合并结果
-	var result []*response.CategoryResponse
+	// 合并结果（用户自定义在前）
+	categories := append(userCategories, systemCategories...)
+	if tree {
+		return s.buildCategoryTree(categories), nil
+	}
 
-	// 添加用户自定义分类
-	for _, cat := range userCategories {
+	result := make([]*response.CategoryResponse, 0, len(categories))
+	for _, cat := range categories {
 		result = append(result, s.toCategoryResponse(cat))
 	}
+	return result, nil
+}
 
-	// 添加系统分类
-	for _, cat := range systemCategories {
-		result = append(result, s.toCategoryResponse(cat))
+// buildCategoryTree 将分类列表组织为树，上级分类不在列表中（如已停用）的分类作为顶级分类返回
+func (s *categoryService) buildCategoryTree(categories []*models.Category) []*response.CategoryResponse {
+	nodes := make(map[int64]*response.CategoryResponse, len(categories))
+	for _, cat := range categories {
+		nodes[cat.ID] = s.toCategoryResponse(cat)
 	}
 
-	return result, nil
+	var roots []*response.CategoryResponse
+	for _, cat := range categories {
+		node := nodes[cat.ID]
+		if cat.ParentID != nil {
+			if parent, ok := nodes[*cat.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
 }
 
 // GetCategoryByID 获取分类详情
@@ -77,9 +116,16 @@ func (s *categoryService) GetCategoryByID(userID int64, categoryID int64) (*resp
 
 // CreateCategory 创建分类
 func (s *categoryService) CreateCategory(userID int64, req *request.CreateCategoryRequest) (*response.CategoryResponse, error) {
+	if req.ParentID != nil {
+		if err := s.checkParent(userID, req.Type, *req.ParentID, 0, 1); err != nil {
+			return nil, err
+		}
+	}
+
 	// 创建分类对象
 	category := &models.Category{
 		UserID:       userID,
+		ParentID:     req.ParentID,
 		Type:         req.Type,
 		Name:         req.Name,
 		Icon:         req.Icon,
@@ -98,6 +144,12 @@ func (s *categoryService) CreateCategory(userID int64, req *request.CreateCatego
 
 // UpdateCategory 更新分类
 func (s *categoryService) UpdateCategory(userID int64, categoryID int64, req *request.UpdateCategoryRequest) error {
+	return s.withUserLock(userID, func(locked *categoryService) error {
+		return locked.updateCategory(userID, categoryID, req)
+	})
+}
+
+func (s *categoryService) updateCategory(userID int64, categoryID int64, req *request.UpdateCategoryRequest) error {
 	// 查找分类
 	category, err := s.categoryRepo.FindByID(categoryID)
 	if err != nil {
@@ -129,11 +181,33 @@ func (s *categoryService) UpdateCategory(userID int64, categoryID int64, req *re
 		category.IsActive = *req.IsActive
 	}
 
+	// 调整上级分类，校验层级和循环引用
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			height, err := s.subtreeHeight(category.ID)
+			if err != nil {
+				return err
+			}
+			if err := s.checkParent(userID, category.Type, *req.ParentID, category.ID, height); err != nil {
+				return err
+			}
+			category.ParentID = req.ParentID
+		}
+	}
+
 	return s.categoryRepo.Update(category)
 }
 
 // DeleteCategory 删除分类
-func (s *categoryService) DeleteCategory(userID int64, categoryID int64) error {
+func (s *categoryService) DeleteCategory(userID int64, categoryID int64, req *request.DeleteCategoryRequest) error {
+	return s.withUserLock(userID, func(locked *categoryService) error {
+		return locked.deleteCategory(userID, categoryID, req)
+	})
+}
+
+func (s *categoryService) deleteCategory(userID int64, categoryID int64, req *request.DeleteCategoryRequest) error {
 	// 查找分类
 	category, err := s.categoryRepo.FindByID(categoryID)
 	if err != nil {
@@ -148,7 +222,198 @@ func (s *categoryService) DeleteCategory(userID int64, categoryID int64) error {
 		return errors.New("无权删除该分类")
 	}
 
-	return s.categoryRepo.Delete(categoryID)
+	children, err := s.categoryRepo.FindChildren(categoryID)
+	if err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return s.categoryRepo.Delete(categoryID)
+	}
+
+	switch req.Children {
+	case "reassign":
+		// 下级分类移到指定分类下，未指定时移到被删分类的上级
+		target := category.ParentID
+		if req.ReassignTo != nil {
+			target = req.ReassignTo
+		}
+		if target != nil {
+			height, err := s.subtreeHeight(categoryID)
+			if err != nil {
+				return err
+			}
+			if err := s.checkParent(userID, category.Type, *target, categoryID, height-1); err != nil {
+				return err
+			}
+		}
+		return s.categoryRepo.DeleteAndReassign(categoryID, target)
+	case "cascade":
+		ids, err := s.descendantIDs(categoryID)
+		if err != nil {
+			return err
+		}
+		return s.categoryRepo.DeleteBatch(append(ids, categoryID))
+	default:
+		return errCategoryHasChildren
+	}
+}
+
+// MergeCategories 合并分类
+func (s *categoryService) MergeCategories(userID int64, req *request.MergeCategoriesRequest) (*response.MergeCategoriesResponse, error) {
+	var resp *response.MergeCategoriesResponse
+	err := s.withUserLock(userID, func(locked *categoryService) error {
+		var err error
+		resp, err = locked.mergeCategories(userID, req)
+		return err
+	})
+	if err == nil && s.suggestions != nil {
+		s.suggestions.InvalidateModel(userID)
+	}
+	return resp, err
+}
+
+func (s *categoryService) mergeCategories(userID int64, req *request.MergeCategoriesRequest) (*response.MergeCategoriesResponse, error) {
+	target, err := s.categoryRepo.FindByID(req.TargetID)
+	if err != nil || !target.IsActive || (!target.IsSystem && target.UserID != userID) {
+		return nil, errCategoryMergeTarget
+	}
+
+	sourceIDs := make([]int64, 0, len(req.SourceIDs))
+	seen := make(map[int64]bool)
+	for _, sourceID := range req.SourceIDs {
+		if seen[sourceID] {
+			continue
+		}
+		seen[sourceID] = true
+		if sourceID == target.ID {
+			return nil, errCategoryMergeSelf
+		}
+
+		source, err := s.categoryRepo.FindByID(sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("分类%d不存在", sourceID)
+		}
+		if source.IsSystem {
+			return nil, errors.New("系统分类不可合并")
+		}
+		if source.UserID != userID {
+			return nil, errors.New("无权合并该分类")
+		}
+		if source.Type != target.Type {
+			return nil, errCategoryParentType
+		}
+
+		// 源分类的子分类将移到目标分类下，须满足层级上限且目标不能是源分类的下级
+		height, err := s.subtreeHeight(source.ID)
+		if err != nil {
+			return nil, err
+		}
+		if height > 1 {
+			if err := s.checkParent(userID, source.Type, target.ID, source.ID, height-1); err != nil {
+				return nil, err
+			}
+		}
+		sourceIDs = append(sourceIDs, source.ID)
+	}
+
+	moved, err := s.categoryRepo.Merge(sourceIDs, target.ID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][分类] 合并失败 | 用户ID: %d | 目标分类: %d | 错误: %v", userID, target.ID, err))
+		return nil, errors.New("合并分类失败")
+	}
+
+	logger.Info(fmt.Sprintf("[Service][分类] 合并成功 | 用户ID: %d | 源分类: %v | 目标分类: %d | 交易数: %d", userID, sourceIDs, target.ID, moved))
+	return &response.MergeCategoriesResponse{
+		Target:            s.toCategoryResponse(target),
+		MergedCount:       len(sourceIDs),
+		MovedTransactions: moved,
+	}, nil
+}
+
+// checkParent 校验上级分类：须为本人或系统分类且收支类型一致，不能是 categoryID 自身或其下级，
+// 挂上高度为 height 的分类子树后不超过最大层级。categoryID 为 0 表示新建分类
+func (s *categoryService) checkParent(userID int64, categoryType string, parentID int64, categoryID int64, height int) error {
+	parent, err := s.categoryRepo.FindByID(parentID)
+	if err != nil || (!parent.IsSystem && parent.UserID != userID) {
+		return errCategoryParent
+	}
+	if parent.Type != categoryType {
+		return errCategoryParentType
+	}
+
+	// 沿上级链向上，统计上级分类所在层级并检查循环
+	depth := 0
+	for current := parent; ; {
+		if current.ID == categoryID {
+			return errCategoryCycle
+		}
+		depth++
+		if depth+height > maxCategoryDepth {
+			return errCategoryDepth
+		}
+		if current.ParentID == nil {
+			return nil
+		}
+		if current, err = s.categoryRepo.FindByID(*current.ParentID); err != nil {
+			return err
+		}
+	}
+}
+
+// subtreeHeight 分类子树的高度，没有下级分类时为1
+func (s *categoryService) subtreeHeight(categoryID int64) (int, error) {
+	height := 1
+	err := s.walkSubtree(categoryID, func(depth int, _ *models.Category) {
+		if depth+1 > height {
+			height = depth + 1
+		}
+	})
+	return height, err
+}
+
+// descendantIDs 分类的全部下级分类ID
+func (s *categoryService) descendantIDs(categoryID int64) ([]int64, error) {
+	var ids []int64
+	err := s.walkSubtree(categoryID, func(_ int, child *models.Category) {
+		ids = append(ids, child.ID)
+	})
+	return ids, err
+}
+
+// walkSubtree 按层遍历分类的全部下级分类，depth 为相对 categoryID 的层数。
+// 已访问的分类不再展开，数据中存在循环引用时也能结束
+func (s *categoryService) walkSubtree(categoryID int64, visit func(depth int, child *models.Category)) error {
+	visited := map[int64]bool{categoryID: true}
+	level := []int64{categoryID}
+	for depth := 1; len(level) > 0; depth++ {
+		var next []int64
+		for _, id := range level {
+			children, err := s.categoryRepo.FindChildren(id)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				if visited[child.ID] {
+					continue
+				}
+				visited[child.ID] = true
+				visit(depth, child)
+				next = append(next, child.ID)
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+// withUserLock 在锁定用户的事务中以事务内的分类仓库执行fn，
+// 分类层级的校验与写入须在同一把锁内完成，否则并发移动可能各自通过校验后形成循环
+func (s *categoryService) withUserLock(userID int64, fn func(locked *categoryService) error) error {
+	return s.categoryRepo.WithUserLock(userID, func(repo repository.CategoryRepository) error {
+		locked := *s
+		locked.categoryRepo = repo
+		return fn(&locked)
+	})
 }
 
 // GetSystemCategories 获取系统默认分类
@@ -170,6 +435,8 @@ func (s *categoryService) GetSystemCategories(categoryType string) ([]*response.
 func (s *categoryService) toCategoryResponse(category *models.Category) *response.CategoryResponse {
 	return &response.CategoryResponse{
 		ID:           category.ID,
+		UserID:       category.UserID,
+		ParentID:     category.ParentID,
 		Type:         category.Type,
 		Name:         category.Name,
 		Icon:         category.Icon,
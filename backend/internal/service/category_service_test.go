@@ -0,0 +1,176 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// TestGetCategoriesTree 测试分类树：用户子分类可挂在系统分类下，上级分类不在列表中时作为顶级分类
+func TestGetCategoriesTree(t *testing.T) {
+	mockCategoryRepo := new(MockCategoryRepository)
+	service := &categoryService{categoryRepo: mockCategoryRepo}
+
+	mockCategoryRepo.On("FindByUserID", int64(1), "expense").Return([]*models.Category{
+		{ID: 10, UserID: 1, Type: "expense", Name: "咖啡", ParentID: int64Ptr(1)},
+		{ID: 11, UserID: 1, Type: "expense", Name: "手冲", ParentID: int64Ptr(10)},
+		{ID: 12, UserID: 1, Type: "expense", Name: "宠物", ParentID: int64Ptr(99)},
+	}, nil)
+	mockCategoryRepo.On("GetSystemCategories", "expense").Return([]*models.Category{
+		{ID: 1, Type: "expense", Name: "餐饮", IsSystem: true},
+	}, nil)
+
+	tree, err := service.GetCategories(1, "expense", true)
+	assert.NoError(t, err)
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "宠物", tree[0].Name)
+	assert.Equal(t, "餐饮", tree[1].Name)
+	assert.Equal(t, "咖啡", tree[1].Children[0].Name)
+	assert.Equal(t, "手冲", tree[1].Children[0].Children[0].Name)
+
+	flat, err := service.GetCategories(1, "expense", false)
+	assert.NoError(t, err)
+	assert.Len(t, flat, 4)
+	assert.Equal(t, int64(1), *flat[0].ParentID)
+}
+
+// TestCreateCategoryParentChecks 测试创建子分类时校验收支类型和层级上限
+func TestCreateCategoryParentChecks(t *testing.T) {
+	mockCategoryRepo := new(MockCategoryRepository)
+	service := &categoryService{categoryRepo: mockCategoryRepo}
+
+	mockCategoryRepo.On("FindByID", int64(1)).Return(&models.Category{ID: 1, Type: "expense", IsSystem: true}, nil)
+	mockCategoryRepo.On("FindByID", int64(10)).Return(&models.Category{ID: 10, UserID: 1, Type: "expense", ParentID: int64Ptr(1)}, nil)
+	mockCategoryRepo.On("FindByID", int64(11)).Return(&models.Category{ID: 11, UserID: 1, Type: "expense", ParentID: int64Ptr(10)}, nil)
+	mockCategoryRepo.On("FindByID", int64(20)).Return(&models.Category{ID: 20, UserID: 2, Type: "expense"}, nil)
+	mockCategoryRepo.On("Create", mock.Anything).Return(nil)
+
+	_, err := service.CreateCategory(1, &request.CreateCategoryRequest{Type: "income", Name: "奖金", ParentID: int64Ptr(1)})
+	assert.Equal(t, errCategoryParentType, err)
+
+	_, err = service.CreateCategory(1, &request.CreateCategoryRequest{Type: "expense", Name: "拿铁", ParentID: int64Ptr(11)})
+	assert.Equal(t, errCategoryDepth, err)
+
+	_, err = service.CreateCategory(1, &request.CreateCategoryRequest{Type: "expense", Name: "拿铁", ParentID: int64Ptr(20)})
+	assert.Equal(t, errCategoryParent, err)
+
+	resp, err := service.CreateCategory(1, &request.CreateCategoryRequest{Type: "expense", Name: "手冲", ParentID: int64Ptr(10)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), *resp.ParentID)
+}
+
+// TestUpdateCategoryReparent 测试调整上级分类：不能移到自身下级，子树挂上后不能超过层级上限，传 0 移为顶级
+func TestUpdateCategoryReparent(t *testing.T) {
+	mockCategoryRepo := new(MockCategoryRepository)
+	service := &categoryService{categoryRepo: mockCategoryRepo}
+
+	food := &models.Category{ID: 10, UserID: 1, Type: "expense"}
+	coffee := &models.Category{ID: 11, UserID: 1, Type: "expense", ParentID: int64Ptr(10)}
+	other := &models.Category{ID: 20, UserID: 1, Type: "expense"}
+	mockCategoryRepo.On("FindByID", int64(10)).Return(food, nil)
+	mockCategoryRepo.On("FindByID", int64(11)).Return(coffee, nil)
+	mockCategoryRepo.On("FindByID", int64(20)).Return(other, nil)
+	mockCategoryRepo.On("FindChildren", int64(10)).Return([]*models.Category{coffee}, nil)
+	mockCategoryRepo.On("FindChildren", int64(11)).Return([]*models.Category{}, nil)
+	mockCategoryRepo.On("Update", mock.Anything).Return(nil)
+
+	err := service.UpdateCategory(1, 10, &request.UpdateCategoryRequest{ParentID: int64Ptr(11)})
+	assert.Equal(t, errCategoryCycle, err)
+
+	err = service.UpdateCategory(1, 10, &request.UpdateCategoryRequest{ParentID: int64Ptr(20)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), *food.ParentID)
+
+	err = service.UpdateCategory(1, 11, &request.UpdateCategoryRequest{ParentID: int64Ptr(0)})
+	assert.NoError(t, err)
+	assert.Nil(t, coffee.ParentID)
+}
+
+// TestSubtreeWalkStopsOnCycle 测试数据中已存在循环引用时遍历下级分类仍能结束
+func TestSubtreeWalkStopsOnCycle(t *testing.T) {
+	mockCategoryRepo := new(MockCategoryRepository)
+	service := &categoryService{categoryRepo: mockCategoryRepo}
+
+	mockCategoryRepo.On("FindChildren", int64(10)).Return([]*models.Category{{ID: 11, ParentID: int64Ptr(10)}}, nil)
+	mockCategoryRepo.On("FindChildren", int64(11)).Return([]*models.Category{{ID: 10, ParentID: int64Ptr(11)}}, nil)
+
+	ids, err := service.descendantIDs(10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{11}, ids)
+
+	height, err := service.subtreeHeight(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, height)
+}
+
+// TestDeleteCategoryWithChildren 测试删除有子分类的分类：须指定移到其他分类下或连同子分类删除
+func TestDeleteCategoryWithChildren(t *testing.T) {
+	mockCategoryRepo := new(MockCategoryRepository)
+	service := &categoryService{categoryRepo: mockCategoryRepo}
+
+	mockCategoryRepo.On("FindByID", int64(1)).Return(&models.Category{ID: 1, Type: "expense", IsSystem: true}, nil)
+	mockCategoryRepo.On("FindByID", int64(10)).Return(&models.Category{ID: 10, UserID: 1, Type: "expense", ParentID: int64Ptr(1)}, nil)
+	mockCategoryRepo.On("FindByID", int64(11)).Return(&models.Category{ID: 11, UserID: 1, Type: "expense", ParentID: int64Ptr(10)}, nil)
+	mockCategoryRepo.On("FindChildren", int64(10)).Return([]*models.Category{{ID: 11, ParentID: int64Ptr(10)}}, nil)
+	mockCategoryRepo.On("FindChildren", int64(11)).Return([]*models.Category{{ID: 12, ParentID: int64Ptr(11)}}, nil)
+	mockCategoryRepo.On("FindChildren", int64(12)).Return([]*models.Category{}, nil)
+	mockCategoryRepo.On("DeleteAndReassign", int64(10), int64Ptr(1)).Return(nil)
+	mockCategoryRepo.On("DeleteBatch", []int64{11, 12, 10}).Return(nil)
+
+	err := service.DeleteCategory(1, 10, &request.DeleteCategoryRequest{})
+	assert.Equal(t, errCategoryHasChildren, err)
+
+	// 移到被删分类的上级（系统分类“餐饮”）下，子树高度2，挂在第1层下不超过上限
+	err = service.DeleteCategory(1, 10, &request.DeleteCategoryRequest{Children: "reassign"})
+	assert.NoError(t, err)
+
+	err = service.DeleteCategory(1, 10, &request.DeleteCategoryRequest{Children: "reassign", ReassignTo: int64Ptr(11)})
+	assert.Equal(t, errCategoryCycle, err)
+
+	err = service.DeleteCategory(1, 10, &request.DeleteCategoryRequest{Children: "cascade"})
+	assert.NoError(t, err)
+	mockCategoryRepo.AssertCalled(t, "DeleteBatch", []int64{11, 12, 10})
+	mockCategoryRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+// TestMergeCategories 测试合并分类：校验源分类归属和收支类型，子分类随之移到目标分类下，合并后丢弃分类建议模型
+func TestMergeCategories(t *testing.T) {
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockSuggestions := new(MockCategorySuggestionService)
+	service := &categoryService{categoryRepo: mockCategoryRepo, suggestions: mockSuggestions}
+	mockSuggestions.On("InvalidateModel", int64(1)).Return()
+
+	mockCategoryRepo.On("FindByID", int64(1)).Return(&models.Category{ID: 1, Type: "expense", Name: "餐饮", IsSystem: true, IsActive: true}, nil)
+	mockCategoryRepo.On("FindByID", int64(10)).Return(&models.Category{ID: 10, UserID: 1, Type: "expense", Name: "吃饭", IsActive: true}, nil)
+	mockCategoryRepo.On("FindByID", int64(11)).Return(&models.Category{ID: 11, UserID: 1, Type: "expense", Name: "饭钱", IsActive: true}, nil)
+	mockCategoryRepo.On("FindByID", int64(20)).Return(&models.Category{ID: 20, UserID: 1, Type: "income", Name: "工资", IsActive: true}, nil)
+	mockCategoryRepo.On("FindByID", int64(30)).Return(&models.Category{ID: 30, Type: "expense", Name: "交通", IsSystem: true, IsActive: true}, nil)
+	mockCategoryRepo.On("FindChildren", int64(10)).Return([]*models.Category{{ID: 12, ParentID: int64Ptr(10)}}, nil)
+	mockCategoryRepo.On("FindChildren", int64(11)).Return([]*models.Category{}, nil)
+	mockCategoryRepo.On("FindChildren", int64(12)).Return([]*models.Category{}, nil)
+	mockCategoryRepo.On("Merge", []int64{10, 11}, int64(1)).Return(int64(42), nil)
+
+	_, err := service.MergeCategories(1, &request.MergeCategoriesRequest{SourceIDs: []int64{20}, TargetID: 1})
+	assert.Equal(t, errCategoryParentType, err)
+
+	_, err = service.MergeCategories(1, &request.MergeCategoriesRequest{SourceIDs: []int64{30}, TargetID: 1})
+	assert.Error(t, err)
+
+	_, err = service.MergeCategories(1, &request.MergeCategoriesRequest{SourceIDs: []int64{1}, TargetID: 1})
+	assert.Equal(t, errCategoryMergeSelf, err)
+	mockSuggestions.AssertNotCalled(t, "InvalidateModel", mock.Anything)
+
+	result, err := service.MergeCategories(1, &request.MergeCategoriesRequest{SourceIDs: []int64{10, 11, 10}, TargetID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.MergedCount)
+	assert.Equal(t, int64(42), result.MovedTransactions)
+	assert.Equal(t, "餐饮", result.Target.Name)
+	mockSuggestions.AssertNumberOfCalls(t, "InvalidateModel", 1)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"container/list"
+	"fmt"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/classifier"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+const (
+	suggestionHistoryYears    = 2              // 训练使用最近几年的交易
+	suggestionTrainLimit      = 5000           // 每次训练最多读取的交易笔数
+	suggestionRetrainInterval = 24 * time.Hour // 完整重新训练的间隔，期间只增量学习新增的交易
+	suggestionLimit           = 5              // 默认返回的建议条数
+	suggestionModelLimit      = 1000           // 内存中最多保留的用户模型数，超出时淘汰最久未使用的
+)
+
+// CategoryModelInvalidator 批量修改交易分类后丢弃用户的分类建议模型，下次建议时重新训练
+type CategoryModelInvalidator interface {
+	InvalidateModel(userID int64)
+}
+
+// CategorySuggestionService 分类建议服务接口
+type CategorySuggestionService interface {
+	CategoryModelInvalidator
+	// SuggestCategories 根据用户自己的历史交易推荐分类，按可能性从高到低排列
+	SuggestCategories(userID int64, req *request.SuggestCategoryRequest) ([]*response.CategorySuggestionResponse, error)
+}
+
+// categoryModel 单个用户的分类建议模型（朴素贝叶斯），在内存中按需训练
+type categoryModel struct {
+	mu        sync.Mutex
+	userID    int64
+	nb        *classifier.NaiveBayes
+	types     map[int64]string // 分类ID -> 收支类型
+	lastID    int64            // 已学习的最大交易ID
+	trainedAt time.Time        // 最近一次完整训练的时间
+}
+
+type categorySuggestionService struct {
+	transactionRepo repository.TransactionRepository
+	categoryRepo    repository.CategoryRepository
+	mu              sync.Mutex
+	models          map[int64]*list.Element // 用户ID -> lru 中的模型
+	lru             *list.List              // 按最近使用排列，表头为最近使用
+	now             func() time.Time        // 测试中可替换
+}
+
+// NewCategorySuggestionService 创建分类建议服务实例
+func NewCategorySuggestionService(
+	transactionRepo repository.TransactionRepository,
+	categoryRepo repository.CategoryRepository,
+) CategorySuggestionService {
+	return &categorySuggestionService{
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+		models:          make(map[int64]*list.Element),
+		lru:             list.New(),
+		now:             time.Now,
+	}
+}
+
+// SuggestCategories 推荐分类，已停用或不再属于用户的分类不返回
+func (s *categorySuggestionService) SuggestCategories(userID int64, req *request.SuggestCategoryRequest) ([]*response.CategorySuggestionResponse, error) {
+	model := s.userModel(userID)
+	model.mu.Lock()
+	defer model.mu.Unlock()
+
+	if err := s.train(userID, model); err != nil {
+		logger.Error(fmt.Sprintf("[Service][分类建议] 训练失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = suggestionLimit
+	}
+	features := suggestionFeatures(req.Title, req.Location, req.Amount, req.TransactionDate, req.TransactionTime)
+	scores := model.nb.Rank(features, func(label int64) bool {
+		return req.Type == "" || model.types[label] == req.Type
+	})
+
+	result := make([]*response.CategorySuggestionResponse, 0, limit)
+	for _, score := range scores {
+		if len(result) >= limit {
+			break
+		}
+		category, err := s.categoryRepo.FindByID(score.Label)
+		if err != nil || !category.IsActive || (category.UserID != userID && category.UserID != 0) {
+			continue
+		}
+		result = append(result, &response.CategorySuggestionResponse{
+			CategoryID: category.ID,
+			ParentID:   category.ParentID,
+			Type:       category.Type,
+			Name:       category.Name,
+			Icon:       category.Icon,
+			Color:      category.Color,
+			Confidence: score.Probability,
+		})
+	}
+	return result, nil
+}
+
+// InvalidateModel 丢弃用户的模型；正在使用旧模型的请求不受影响
+func (s *categorySuggestionService) InvalidateModel(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.models[userID]; ok {
+		s.lru.Remove(elem)
+		delete(s.models, userID)
+	}
+}
+
+// userModel 获取用户的模型，不存在时创建；模型数超出上限时淘汰最久未使用的
+func (s *categorySuggestionService) userModel(userID int64) *categoryModel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.models[userID]; ok {
+		s.lru.MoveToFront(elem)
+		return elem.Value.(*categoryModel)
+	}
+	model := &categoryModel{userID: userID}
+	s.models[userID] = s.lru.PushFront(model)
+	if s.lru.Len() > suggestionModelLimit {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.models, oldest.Value.(*categoryModel).userID)
+	}
+	return model
+}
+
+// train 学习上次训练之后新增的交易；超过重新训练间隔时丢弃旧模型完整训练，
+// 以反映单笔修改分类和删除交易等变化（批量修改分类、合并分类和追溯执行规则会立即丢弃模型）
+func (s *categorySuggestionService) train(userID int64, model *categoryModel) error {
+	now := s.now()
+	if model.nb == nil || now.Sub(model.trainedAt) >= suggestionRetrainInterval {
+		model.nb = classifier.NewNaiveBayes()
+		model.types = make(map[int64]string)
+		model.lastID = 0
+		model.trainedAt = now
+	}
+
+	transactions, err := s.transactionRepo.FindCategorized(userID, model.lastID, now.AddDate(-suggestionHistoryYears, 0, 0), suggestionTrainLimit)
+	if err != nil {
+		return err
+	}
+	for _, transaction := range transactions {
+		model.nb.Train(*transaction.CategoryID, transactionFeatures(transaction))
+		model.types[*transaction.CategoryID] = transaction.Type
+		if transaction.ID > model.lastID {
+			model.lastID = transaction.ID
+		}
+	}
+	if len(transactions) > 0 {
+		logger.Info(fmt.Sprintf("[Service][分类建议] 模型已更新 | 用户ID: %d | 新增样本: %d | 样本总数: %d", userID, len(transactions), model.nb.Samples()))
+	}
+	return nil
+}
+
+// transactionFeatures 提取已有交易的特征
+func transactionFeatures(transaction *models.Transaction) []string {
+	return suggestionFeatures(transaction.Title, transaction.Location, transaction.Amount, &transaction.TransactionDate, transaction.TransactionTime)
+}
+
+// suggestionFeatures 提取特征：标题词元、地点词元、金额数量级（按2的幂分档）、时段（每3小时一档）和是否周末
+func suggestionFeatures(title, location string, amount money.Money, date, at *time.Time) []string {
+	features := classifier.Tokenize(title)
+	for _, token := range classifier.Tokenize(location) {
+		features = append(features, "location:"+token)
+	}
+	if amount > 0 {
+		features = append(features, fmt.Sprintf("amount:%d", bits.Len64(uint64(amount.Cents()/100))))
+	}
+	if at != nil {
+		features = append(features, fmt.Sprintf("hour:%d", at.Hour()/3))
+	}
+	if date == nil {
+		date = at
+	}
+	if date != nil {
+		if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			features = append(features, "day:weekend")
+		} else {
+			features = append(features, "day:weekday")
+		}
+	}
+	return features
+}
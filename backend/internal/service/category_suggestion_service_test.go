@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSuggestCategories 测试按用户历史推荐分类：新交易增量学习，超过间隔后完整重新训练，停用的分类不返回
+func TestSuggestCategories(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	service := NewCategorySuggestionService(mockTransRepo, mockCategoryRepo).(*categorySuggestionService)
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.Local)
+	service.now = func() time.Time { return now }
+
+	userID := int64(1)
+	transport, coffee, salary, merged := int64(10), int64(11), int64(20), int64(12)
+	history := []*models.Transaction{
+		{ID: 5, Type: "expense", CategoryID: &coffee, Title: "瑞幸咖啡", Amount: money.MustParse("18.00"), TransactionDate: date(2024, 3, 4)},
+		{ID: 4, Type: "expense", CategoryID: &transport, Title: "滴滴快车", Amount: money.MustParse("32.00"), TransactionDate: date(2024, 3, 4)},
+		{ID: 3, Type: "expense", CategoryID: &transport, Title: "滴滴专车", Amount: money.MustParse("45.00"), TransactionDate: date(2024, 3, 3)},
+		{ID: 2, Type: "expense", CategoryID: &merged, Title: "星巴克咖啡", Amount: money.MustParse("35.00"), TransactionDate: date(2024, 3, 2)},
+		{ID: 1, Type: "income", CategoryID: &salary, Title: "工资", Amount: money.MustParse("9000.00"), TransactionDate: date(2024, 2, 29)},
+	}
+	since := now.AddDate(-suggestionHistoryYears, 0, 0)
+	mockTransRepo.On("FindCategorized", userID, int64(0), since, suggestionTrainLimit).Return(history, nil).Once()
+	mockTransRepo.On("FindCategorized", userID, int64(5), since, suggestionTrainLimit).Return([]*models.Transaction{
+		{ID: 6, Type: "expense", CategoryID: &coffee, Title: "Manner咖啡", Amount: money.MustParse("20.00"), TransactionDate: date(2024, 3, 9)},
+	}, nil).Once()
+	mockTransRepo.On("FindCategorized", userID, int64(6), since, suggestionTrainLimit).Return([]*models.Transaction{}, nil)
+	mockCategoryRepo.On("FindByID", transport).Return(&models.Category{ID: transport, UserID: userID, Type: "expense", Name: "交通", IsActive: true}, nil)
+	mockCategoryRepo.On("FindByID", coffee).Return(&models.Category{ID: coffee, UserID: userID, Type: "expense", Name: "咖啡", IsActive: true}, nil)
+	mockCategoryRepo.On("FindByID", merged).Return(&models.Category{ID: merged, UserID: userID, Type: "expense", Name: "饮品", IsActive: false}, nil)
+	mockCategoryRepo.On("FindByID", salary).Return(&models.Category{ID: salary, Type: "income", Name: "工资", IsSystem: true, IsActive: true}, nil)
+
+	suggestions, err := service.SuggestCategories(userID, &request.SuggestCategoryRequest{Type: "expense", Title: "滴滴出行", Amount: money.MustParse("28.00")})
+	assert.NoError(t, err)
+	assert.Len(t, suggestions, 2)
+	assert.Equal(t, transport, suggestions[0].CategoryID)
+	assert.Equal(t, "交通", suggestions[0].Name)
+	assert.Greater(t, suggestions[0].Confidence, suggestions[1].Confidence)
+
+	// 第二次请求只读取新增的交易
+	suggestions, err = service.SuggestCategories(userID, &request.SuggestCategoryRequest{Title: "Manner", Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, suggestions, 1)
+	assert.Equal(t, coffee, suggestions[0].CategoryID)
+
+	// 超过重新训练间隔后从头训练
+	now = now.Add(suggestionRetrainInterval)
+	since = now.AddDate(-suggestionHistoryYears, 0, 0)
+	mockTransRepo.On("FindCategorized", userID, int64(0), since, suggestionTrainLimit).Return(history, nil).Once()
+	_, err = service.SuggestCategories(userID, &request.SuggestCategoryRequest{Title: "工资"})
+	assert.NoError(t, err)
+	mockTransRepo.AssertCalled(t, "FindCategorized", userID, int64(0), since, suggestionTrainLimit)
+}
+
+// TestSuggestionModelEviction 测试模型数超出上限时淘汰最久未使用的用户模型，丢弃后重新创建
+func TestSuggestionModelEviction(t *testing.T) {
+	service := NewCategorySuggestionService(new(MockTransactionRepository), new(MockCategoryRepository)).(*categorySuggestionService)
+
+	first := service.userModel(1)
+	second := service.userModel(2)
+	for userID := int64(3); userID <= suggestionModelLimit; userID++ {
+		service.userModel(userID)
+	}
+	assert.Same(t, first, service.userModel(1))
+
+	// 用户1刚被使用，超出上限时淘汰用户2
+	service.userModel(suggestionModelLimit + 1)
+	assert.Len(t, service.models, suggestionModelLimit)
+	assert.Same(t, first, service.userModel(1))
+	assert.NotSame(t, second, service.userModel(2))
+
+	service.InvalidateModel(1)
+	assert.NotSame(t, first, service.userModel(1))
+	assert.Equal(t, service.lru.Len(), len(service.models))
+}
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/spf13/viper"
+)
+
+const (
+	creditStatementLimit      = 24 // 信用账单列表默认条数
+	creditCatchUpCycles       = 12 // 单次任务最多补生成的账单期数
+	minimumPaymentPercent     = 10 // 最低还款额占本期应还的百分比
+	defaultCreditRemindDays   = 3  // 默认提前提醒还款的天数
+	defaultCreditAlertPercent = 80 // 默认额度使用率预警线
+)
+
+var (
+	errCreditAccountNotFound = errors.New("信用账户不存在")
+	errStatementNotFound     = errors.New("信用账单不存在")
+	errRepaySource           = errors.New("还款账户无效，请选择储蓄卡、支付宝等非信用账户")
+	errNothingToRepay        = errors.New("当前没有待还款项")
+	errCreditSettings        = errors.New("仅信用账户可设置信用额度、账单日和还款日")
+)
+
+// CreditService 信用账户服务接口：额度概览、账单周期与还款
+type CreditService interface {
+	GetCreditAccounts(userID int64) ([]*response.CreditAccountResponse, error)
+	GetCreditAccount(userID int64, accountID int64) (*response.CreditAccountResponse, error)
+	GetStatements(userID int64, accountID int64) ([]*response.CreditStatementResponse, error)
+	GetStatement(userID int64, accountID int64, statementID int64) (*response.CreditStatementResponse, error)
+	// Repay 从非信用账户转账还款
+	Repay(userID int64, accountID int64, req *request.RepayCreditRequest) (*response.RepayCreditResponse, error)
+	// ProcessStatements 生成已到账单日的信用账单、刷新还款状态并发送还款提醒和额度预警，返回生成的账单数
+	ProcessStatements(now time.Time) (int, error)
+}
+
+type creditService struct {
+	accountRepo        repository.AccountRepository
+	statementRepo      repository.CreditStatementRepository
+	transactionRepo    repository.TransactionRepository
+	transactionService TransactionService
+	notifier           NotificationService
+}
+
+// NewCreditService 创建信用账户服务实例
+func NewCreditService(
+	accountRepo repository.AccountRepository,
+	statementRepo repository.CreditStatementRepository,
+	transactionRepo repository.TransactionRepository,
+	transactionService TransactionService,
+	notifier NotificationService,
+) CreditService {
+	return &creditService{
+		accountRepo:        accountRepo,
+		statementRepo:      statementRepo,
+		transactionRepo:    transactionRepo,
+		transactionService: transactionService,
+		notifier:           notifier,
+	}
+}
+
+// GetCreditAccounts 获取用户全部信用账户的额度概览
+func (s *creditService) GetCreditAccounts(userID int64) ([]*response.CreditAccountResponse, error) {
+	accounts, err := s.accountRepo.FindByUserID(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][信用账户] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	today := truncateToDate(time.Now())
+	result := make([]*response.CreditAccountResponse, 0)
+	for _, account := range accounts {
+		if account.AccountType != models.AccountTypeCredit {
+			continue
+		}
+		resp, err := s.toCreditAccountResponse(account, today)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, resp)
+	}
+	return result, nil
+}
+
+// GetCreditAccount 获取单个信用账户的额度概览
+func (s *creditService) GetCreditAccount(userID int64, accountID int64) (*response.CreditAccountResponse, error) {
+	account, err := s.findCreditAccount(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toCreditAccountResponse(account, truncateToDate(time.Now()))
+}
+
+// GetStatements 获取信用账户的历史账单
+func (s *creditService) GetStatements(userID int64, accountID int64) ([]*response.CreditStatementResponse, error) {
+	if _, err := s.findCreditAccount(userID, accountID); err != nil {
+		return nil, err
+	}
+
+	statements, err := s.statementRepo.FindByAccountID(accountID, creditStatementLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*response.CreditStatementResponse, 0, len(statements))
+	for _, statement := range statements {
+		result = append(result, toCreditStatementResponse(statement))
+	}
+	return result, nil
+}
+
+// GetStatement 获取单期账单，本期交易明细可按账单周期通过交易列表查询
+func (s *creditService) GetStatement(userID int64, accountID int64, statementID int64) (*response.CreditStatementResponse, error) {
+	statement, err := s.statementRepo.FindByID(statementID)
+	if err != nil || statement.UserID != userID || statement.AccountID != accountID {
+		return nil, errStatementNotFound
+	}
+	return toCreditStatementResponse(statement), nil
+}
+
+// Repay 还款：生成从来源账户转入信用账户的转账，未指定金额时还清最近一期账单的剩余应还
+func (s *creditService) Repay(userID int64, accountID int64, req *request.RepayCreditRequest) (*response.RepayCreditResponse, error) {
+	account, err := s.findCreditAccount(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.accountRepo.FindByID(req.FromAccountID)
+	if err != nil || source.UserID != userID || !source.IsActive || source.AccountType == models.AccountTypeCredit {
+		return nil, errRepaySource
+	}
+
+	latest, err := s.statementRepo.FindLatest(account.ID)
+	if err != nil {
+		return nil, err
+	}
+	amount := req.Amount
+	if amount == 0 && latest != nil {
+		amount = latest.Remaining()
+	}
+	if amount == 0 {
+		amount = creditDebt(account)
+	}
+	if amount <= 0 {
+		return nil, errNothingToRepay
+	}
+
+	today := truncateToDate(time.Now())
+	transactionDate := today
+	if req.TransactionDate != nil {
+		transactionDate = truncateToDate(*req.TransactionDate)
+	}
+
+	transaction, err := s.transactionService.CreateTransaction(userID, &request.CreateTransactionRequest{
+		Type:            string(models.TransactionTypeTransfer),
+		AccountID:       &source.ID,
+		ToAccountID:     &account.ID,
+		Amount:          amount,
+		Currency:        account.Currency,
+		Title:           fmt.Sprintf("%s还款", account.AccountName),
+		Description:     req.Notes,
+		TransactionDate: transactionDate,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][信用账户] 还款失败 | 用户ID: %d | 账户ID: %d | 错误: %v", userID, accountID, err))
+		return nil, err
+	}
+
+	// 重新读取账户以获得还款后的余额，并刷新账单的已还金额（失败时由每日任务补偿）
+	if updated, err := s.accountRepo.FindByID(account.ID); err == nil {
+		account = updated
+	}
+	if latest != nil {
+		if err := s.refreshStatement(account, latest, today, today); err != nil {
+			logger.Warn(fmt.Sprintf("[Service][信用账户] 刷新账单失败 | 用户ID: %d | 账单ID: %d | 错误: %v", userID, latest.ID, err))
+		}
+	}
+
+	logger.Info(fmt.Sprintf("[Service][信用账户] 还款成功 | 用户ID: %d | 账户ID: %d | 交易ID: %d | 金额: %s", userID, accountID, transaction.ID, amount))
+	summary, err := s.toCreditAccountResponse(account, today)
+	if err != nil {
+		return nil, err
+	}
+	return &response.RepayCreditResponse{
+		Transaction: transaction,
+		Account:     summary,
+	}, nil
+}
+
+// ProcessStatements 每日任务：补生成已过账单日的账单，刷新最近一期的还款状态并发送提醒
+func (s *creditService) ProcessStatements(now time.Time) (int, error) {
+	today := truncateToDate(now)
+	accounts, err := s.accountRepo.FindCreditAccounts()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][信用账户] 查询信用账户失败 | 错误: %v", err))
+		return 0, err
+	}
+
+	created := 0
+	for _, account := range accounts {
+		latest, count, err := s.syncStatements(account, today)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][信用账户] 生成账单失败 | 用户ID: %d | 账户ID: %d | 错误: %v", account.UserID, account.ID, err))
+			continue
+		}
+		created += count
+		s.sendReminders(account, latest, today)
+	}
+	return created, nil
+}
+
+// syncStatements 生成账单日早于今天且尚未生成的账单，返回最近一期账单和新生成的数量
+// 账单日当天的交易计入当期，因此账单在账单日次日生成
+func (s *creditService) syncStatements(account *models.Account, today time.Time) (*models.CreditStatement, int, error) {
+	billingDay := *account.BillingDay
+	latest, err := s.statementRepo.FindLatest(account.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var next time.Time
+	if latest != nil {
+		next = addMonthsClamped(truncateToDate(latest.StatementDate), 1, billingDay)
+	} else {
+		// 首次生成时只出最近一个已结束的周期，账户创建前的周期不出账单
+		next = addMonthsClamped(statementDateOnOrAfter(billingDay, today), -1, billingDay)
+		if next.Before(truncateToDate(account.CreatedAt)) {
+			return nil, 0, nil
+		}
+	}
+
+	created := 0
+	for ; created < creditCatchUpCycles && next.Before(today); created++ {
+		statement, err := s.buildStatement(account, next)
+		if err != nil {
+			return latest, created, err
+		}
+		if err := s.statementRepo.Create(statement); err != nil {
+			return latest, created, err
+		}
+		// 上一期的还款统计截止到新账单日，之后的入账计入新一期
+		if latest != nil {
+			if err := s.refreshStatement(account, latest, next, today); err != nil {
+				return statement, created + 1, err
+			}
+		}
+		logger.Info(fmt.Sprintf("[Service][信用账户] 生成账单 | 用户ID: %d | 账户ID: %d | 账单日: %s | 应还: %s",
+			account.UserID, account.ID, next.Format("2006-01-02"), statement.StatementAmount))
+		latest = statement
+		next = addMonthsClamped(next, 1, billingDay)
+	}
+
+	if latest != nil {
+		if err := s.refreshStatement(account, latest, today, today); err != nil {
+			return latest, created, err
+		}
+	}
+	return latest, created, nil
+}
+
+// buildStatement 按周期内的入账和出账汇总生成账单，账单日欠款由当前余额倒推
+func (s *creditService) buildStatement(account *models.Account, statementDate time.Time) (*models.CreditStatement, error) {
+	periodStart := addMonthsClamped(statementDate, -1, *account.BillingDay).AddDate(0, 0, 1)
+	inflow, outflow, count, err := s.transactionRepo.GetAccountFlows(account.ID, periodStart, statementDate)
+	if err != nil {
+		return nil, err
+	}
+	netAfter, err := s.transactionRepo.GetAccountNetChangeAfter(account.ID, statementDate)
+	if err != nil {
+		return nil, err
+	}
+
+	closingDebt := -(account.Balance - netAfter)
+	statement := &models.CreditStatement{
+		UserID:           account.UserID,
+		AccountID:        account.ID,
+		PeriodStart:      periodStart,
+		StatementDate:    statementDate,
+		DueDate:          statementDueDate(statementDate, *account.PaymentDueDay),
+		OpeningBalance:   closingDebt - outflow + inflow,
+		NewCharges:       outflow,
+		Credits:          inflow,
+		Currency:         account.Currency,
+		TransactionCount: count,
+		Status:           models.CreditStatementUnpaid,
+	}
+	if closingDebt > 0 {
+		statement.StatementAmount = closingDebt
+		minimum, err := closingDebt.MulRat(big.NewRat(minimumPaymentPercent, 100))
+		if err != nil {
+			return nil, err
+		}
+		statement.MinimumPayment = minimum
+	} else {
+		statement.Status = models.CreditStatementPaid
+	}
+	return statement, nil
+}
+
+// refreshStatement 统计账单日次日至until的入账作为已还金额，并更新账单状态
+func (s *creditService) refreshStatement(account *models.Account, statement *models.CreditStatement, until, today time.Time) error {
+	paid := statement.PaidAmount
+	if statement.StatementAmount > 0 {
+		start := truncateToDate(statement.StatementDate).AddDate(0, 0, 1)
+		if !until.Before(start) {
+			inflow, _, _, err := s.transactionRepo.GetAccountFlows(account.ID, start, until)
+			if err != nil {
+				return err
+			}
+			paid = inflow
+		}
+	}
+
+	status := creditStatementStatus(statement, paid, today)
+	if paid == statement.PaidAmount && status == statement.Status {
+		return nil
+	}
+	statement.PaidAmount = paid
+	statement.Status = status
+	return s.statementRepo.Update(statement)
+}
+
+// sendReminders 进入提醒窗口的未还清账单发送还款提醒，额度使用率超过预警线时发送预警，每个周期各一次
+func (s *creditService) sendReminders(account *models.Account, latest *models.CreditStatement, today time.Time) {
+	if s.notifier == nil {
+		return
+	}
+
+	if latest != nil && latest.Remaining() > 0 {
+		dueDate := truncateToDate(latest.DueDate)
+		if !today.Before(dueDate.AddDate(0, 0, -creditRemindDays())) && !today.After(dueDate) {
+			if err := s.notifier.NotifyCreditDue(account, latest); err != nil {
+				logger.Error(fmt.Sprintf("[Service][信用账户] 还款提醒失败 | 用户ID: %d | 账单ID: %d | 错误: %v", account.UserID, latest.ID, err))
+			}
+		}
+	}
+
+	percent := creditUtilization(account)
+	if threshold := creditAlertPercent(); threshold > 0 && percent >= float64(threshold) {
+		cycleStart := addMonthsClamped(statementDateOnOrAfter(*account.BillingDay, today), -1, *account.BillingDay).AddDate(0, 0, 1)
+		if err := s.notifier.NotifyCreditUtilization(account, int(percent), cycleStart); err != nil {
+			logger.Error(fmt.Sprintf("[Service][信用账户] 额度预警失败 | 用户ID: %d | 账户ID: %d | 错误: %v", account.UserID, account.ID, err))
+		}
+	}
+}
+
+// findCreditAccount 查找并校验信用账户归属
+func (s *creditService) findCreditAccount(userID int64, accountID int64) (*models.Account, error) {
+	account, err := s.accountRepo.FindByID(accountID)
+	if err != nil || account.UserID != userID || account.AccountType != models.AccountTypeCredit {
+		return nil, errCreditAccountNotFound
+	}
+	return account, nil
+}
+
+// toCreditAccountResponse 汇总额度使用情况、下一账单日和最近一期账单
+func (s *creditService) toCreditAccountResponse(account *models.Account, today time.Time) (*response.CreditAccountResponse, error) {
+	resp := &response.CreditAccountResponse{
+		Account:         toAccountResponse(account),
+		CreditLimit:     account.CreditLimit,
+		CurrentDebt:     creditDebt(account),
+		AvailableCredit: availableCredit(account),
+		UtilizationRate: creditUtilization(account),
+	}
+	if !account.HasStatementCycle() {
+		return resp, nil
+	}
+
+	nextStatement := statementDateOnOrAfter(*account.BillingDay, today)
+	resp.NextStatementDate = &nextStatement
+
+	latest, err := s.statementRepo.FindLatest(account.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		resp.LatestStatement = toCreditStatementResponse(latest)
+		if latest.Remaining() > 0 {
+			resp.NextDueDate = &latest.DueDate
+		}
+	}
+	return resp, nil
+}
+
+// validateCreditSettings 校验信用额度与账单周期设置，非信用账户不可设置
+func validateCreditSettings(account *models.Account) error {
+	if account.AccountType != models.AccountTypeCredit {
+		if account.CreditLimit != 0 || account.BillingDay != nil || account.PaymentDueDay != nil {
+			return errCreditSettings
+		}
+		return nil
+	}
+	if account.CreditLimit < 0 {
+		return errors.New("信用额度不能为负数")
+	}
+	if account.BillingDay != nil && (*account.BillingDay < 1 || *account.BillingDay > 31) {
+		return errors.New("账单日需在1-31之间")
+	}
+	if account.PaymentDueDay != nil && (*account.PaymentDueDay < 1 || *account.PaymentDueDay > 31) {
+		return errors.New("还款日需在1-31之间")
+	}
+	return nil
+}
+
+// creditDebt 信用账户当前欠款，余额为正（溢缴款）时为0
+func creditDebt(account *models.Account) money.Money {
+	if account.Balance >= 0 {
+		return 0
+	}
+	return -account.Balance
+}
+
+// availableCredit 可用额度 = 信用额度 - 欠款，超额使用时为0
+func availableCredit(account *models.Account) money.Money {
+	available := account.CreditLimit + account.Balance
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// creditUtilization 额度使用率百分比，保留两位小数
+func creditUtilization(account *models.Account) float64 {
+	if account.CreditLimit <= 0 {
+		return 0
+	}
+	return math.Round(creditDebt(account).Float64()/account.CreditLimit.Float64()*10000) / 100
+}
+
+// statementDateOnOrAfter 返回date当天或之后的第一个账单日
+func statementDateOnOrAfter(billingDay int, date time.Time) time.Time {
+	candidate := dateWithDay(date.Year(), date.Month(), billingDay, date.Location())
+	if candidate.Before(date) {
+		candidate = addMonthsClamped(candidate, 1, billingDay)
+	}
+	return candidate
+}
+
+// statementDueDate 账单日之后的第一个还款日
+func statementDueDate(statementDate time.Time, dueDay int) time.Time {
+	due := dateWithDay(statementDate.Year(), statementDate.Month(), dueDay, statementDate.Location())
+	if !due.After(statementDate) {
+		due = addMonthsClamped(due, 1, dueDay)
+	}
+	return due
+}
+
+// creditStatementStatus 根据已还金额和还款日计算账单状态
+func creditStatementStatus(statement *models.CreditStatement, paid money.Money, today time.Time) string {
+	switch {
+	case paid >= statement.StatementAmount:
+		return models.CreditStatementPaid
+	case today.After(truncateToDate(statement.DueDate)):
+		return models.CreditStatementOverdue
+	case paid > 0:
+		return models.CreditStatementPartial
+	default:
+		return models.CreditStatementUnpaid
+	}
+}
+
+func creditRemindDays() int {
+	if viper.IsSet("credit.remind_days_before") {
+		return viper.GetInt("credit.remind_days_before")
+	}
+	return defaultCreditRemindDays
+}
+
+func creditAlertPercent() int {
+	if viper.IsSet("credit.utilization_alert_percent") {
+		return viper.GetInt("credit.utilization_alert_percent")
+	}
+	return defaultCreditAlertPercent
+}
+
+func toCreditStatementResponse(statement *models.CreditStatement) *response.CreditStatementResponse {
+	return &response.CreditStatementResponse{
+		ID:               statement.ID,
+		AccountID:        statement.AccountID,
+		PeriodStart:      statement.PeriodStart,
+		StatementDate:    statement.StatementDate,
+		DueDate:          statement.DueDate,
+		OpeningBalance:   statement.OpeningBalance,
+		NewCharges:       statement.NewCharges,
+		Credits:          statement.Credits,
+		StatementAmount:  statement.StatementAmount,
+		MinimumPayment:   statement.MinimumPayment,
+		PaidAmount:       statement.PaidAmount,
+		RemainingAmount:  statement.Remaining(),
+		Currency:         statement.Currency,
+		TransactionCount: statement.TransactionCount,
+		Status:           statement.Status,
+		CreatedAt:        statement.CreatedAt,
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCreditStatementRepository Mock CreditStatementRepository
+type MockCreditStatementRepository struct {
+	mock.Mock
+}
+
+func (m *MockCreditStatementRepository) Create(statement *models.CreditStatement) error {
+	args := m.Called(statement)
+	return args.Error(0)
+}
+
+func (m *MockCreditStatementRepository) FindByID(id int64) (*models.CreditStatement, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CreditStatement), args.Error(1)
+}
+
+func (m *MockCreditStatementRepository) FindByAccountID(accountID int64, limit int) ([]*models.CreditStatement, error) {
+	args := m.Called(accountID, limit)
+	return args.Get(0).([]*models.CreditStatement), args.Error(1)
+}
+
+func (m *MockCreditStatementRepository) FindLatest(accountID int64) (*models.CreditStatement, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CreditStatement), args.Error(1)
+}
+
+func (m *MockCreditStatementRepository) Update(statement *models.CreditStatement) error {
+	args := m.Called(statement)
+	return args.Error(0)
+}
+
+func newTestCreditService() (CreditService, *MockCreditStatementRepository, *MockTransactionRepository, *MockAccountRepository) {
+	mockStatementRepo := new(MockCreditStatementRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}
+	transService := newTestTransactionService(uow, mockCategoryRepo)
+	return NewCreditService(mockAccountRepo, mockStatementRepo, mockTransRepo, transService, nil), mockStatementRepo, mockTransRepo, mockAccountRepo
+}
+
+// TestStatementCycleDates 测试账单日与还款日推算
+func TestStatementCycleDates(t *testing.T) {
+	assert.Equal(t, date(2024, 3, 5), statementDateOnOrAfter(5, date(2024, 3, 5)))
+	assert.Equal(t, date(2024, 4, 5), statementDateOnOrAfter(5, date(2024, 3, 6)))
+	assert.Equal(t, date(2024, 2, 29), statementDateOnOrAfter(31, date(2024, 2, 10)))
+
+	// 还款日早于账单日时落在次月
+	assert.Equal(t, date(2024, 4, 15), statementDueDate(date(2024, 3, 25), 15))
+	assert.Equal(t, date(2024, 3, 25), statementDueDate(date(2024, 3, 5), 25))
+}
+
+// TestProcessStatementsGeneratesStatement 测试按周期汇总生成账单，账单日欠款由当前余额倒推
+func TestProcessStatementsGeneratesStatement(t *testing.T) {
+	service, mockStatementRepo, mockTransRepo, mockAccountRepo := newTestCreditService()
+
+	billingDay, dueDay := 5, 25
+	account := &models.Account{
+		ID:            9,
+		UserID:        1,
+		AccountType:   models.AccountTypeCredit,
+		Balance:       money.MustParse("-1500.00"),
+		CreditLimit:   money.MustParse("10000.00"),
+		Currency:      "CNY",
+		BillingDay:    &billingDay,
+		PaymentDueDay: &dueDay,
+		CreatedAt:     date(2024, 1, 1),
+	}
+	mockAccountRepo.On("FindCreditAccounts").Return([]*models.Account{account}, nil)
+	mockStatementRepo.On("FindLatest", int64(9)).Return(nil, nil)
+
+	// 周期 2/6 - 3/5：消费 1000，还款 200；账单日之后又消费 300
+	mockTransRepo.On("GetAccountFlows", int64(9), date(2024, 2, 6), date(2024, 3, 5)).
+		Return(money.MustParse("200.00"), money.MustParse("1000.00"), 5, nil)
+	mockTransRepo.On("GetAccountNetChangeAfter", int64(9), date(2024, 3, 5)).Return(money.MustParse("-300.00"), nil)
+	mockTransRepo.On("GetAccountFlows", int64(9), date(2024, 3, 6), date(2024, 3, 10)).
+		Return(money.Money(0), money.MustParse("300.00"), 2, nil)
+
+	var created *models.CreditStatement
+	mockStatementRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.CreditStatement)
+	}).Return(nil)
+
+	count, err := service.ProcessStatements(time.Date(2024, 3, 10, 8, 30, 0, 0, time.Local))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, date(2024, 2, 6), created.PeriodStart)
+	assert.Equal(t, date(2024, 3, 5), created.StatementDate)
+	assert.Equal(t, date(2024, 3, 25), created.DueDate)
+	assert.Equal(t, money.MustParse("400.00"), created.OpeningBalance)
+	assert.Equal(t, money.MustParse("1200.00"), created.StatementAmount)
+	assert.Equal(t, money.MustParse("120.00"), created.MinimumPayment)
+	assert.Equal(t, 5, created.TransactionCount)
+	assert.Equal(t, models.CreditStatementUnpaid, created.Status)
+	mockStatementRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+// TestRepayCreditAccount 测试缺省金额还清账单剩余应还，并刷新账单状态
+func TestRepayCreditAccount(t *testing.T) {
+	service, mockStatementRepo, mockTransRepo, mockAccountRepo := newTestCreditService()
+
+	credit := &models.Account{ID: 9, UserID: 1, AccountType: models.AccountTypeCredit, AccountName: "招行信用卡",
+		Balance: money.MustParse("-1500.00"), CreditLimit: money.MustParse("10000.00"), Currency: "CNY"}
+	bank := &models.Account{ID: 3, UserID: 1, AccountType: "bank", Balance: money.MustParse("5000.00"), Currency: "CNY", IsActive: true}
+	mockAccountRepo.On("FindByID", int64(9)).Return(credit, nil)
+	mockAccountRepo.On("FindByID", int64(3)).Return(bank, nil)
+	mockAccountRepo.On("FindByIDForUpdate", int64(9)).Return(credit, nil)
+	mockAccountRepo.On("FindByIDForUpdate", int64(3)).Return(bank, nil)
+	mockAccountRepo.On("Update", mock.Anything).Return(nil)
+
+	today := truncateToDate(time.Now())
+	statement := &models.CreditStatement{ID: 4, UserID: 1, AccountID: 9, StatementDate: today.AddDate(0, 0, -5),
+		DueDate: today.AddDate(0, 0, 15), StatementAmount: money.MustParse("1200.00"), PaidAmount: money.MustParse("200.00"),
+		Status: models.CreditStatementPartial}
+	mockStatementRepo.On("FindLatest", int64(9)).Return(statement, nil)
+
+	mockTransRepo.On("Create", mock.MatchedBy(func(tr *models.Transaction) bool {
+		return tr.Type == "transfer" && *tr.AccountID == 3 && *tr.ToAccountID == 9 && tr.Amount == money.MustParse("1000.00")
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transaction).ID = 70
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(70)).Return(&models.Transaction{ID: 70, UserID: 1, Type: "transfer", Amount: money.MustParse("1000.00")}, nil)
+	mockTransRepo.On("GetAccountFlows", int64(9), statement.StatementDate.AddDate(0, 0, 1), today).
+		Return(money.MustParse("1200.00"), money.Money(0), 2, nil)
+	mockStatementRepo.On("Update", statement).Return(nil)
+
+	resp, err := service.Repay(1, 9, &request.RepayCreditRequest{FromAccountID: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(70), resp.Transaction.ID)
+	assert.Equal(t, money.MustParse("500.00"), resp.Account.CurrentDebt)
+	assert.Equal(t, money.MustParse("9500.00"), resp.Account.AvailableCredit)
+	assert.Equal(t, 5.0, resp.Account.UtilizationRate)
+	assert.Equal(t, money.MustParse("4000.00"), bank.Balance)
+	assert.Equal(t, models.CreditStatementPaid, statement.Status)
+	assert.Equal(t, money.MustParse("1200.00"), statement.PaidAmount)
+}
+
+// TestRepayFromCreditAccountRejected 测试不能用信用账户还款
+func TestRepayFromCreditAccountRejected(t *testing.T) {
+	service, _, _, mockAccountRepo := newTestCreditService()
+
+	mockAccountRepo.On("FindByID", int64(9)).Return(&models.Account{ID: 9, UserID: 1, AccountType: models.AccountTypeCredit}, nil)
+	mockAccountRepo.On("FindByID", int64(8)).Return(&models.Account{ID: 8, UserID: 1, AccountType: models.AccountTypeCredit, IsActive: true}, nil)
+
+	_, err := service.Repay(1, 9, &request.RepayCreditRequest{FromAccountID: 8})
+
+	assert.Equal(t, errRepaySource, err)
+}
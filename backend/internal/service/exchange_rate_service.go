@@ -0,0 +1,325 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/exchange"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultCurrency      = "CNY" // 用户和账户未设置币种时的默认币种
+	defaultPivotCurrency = "USD" // 默认的交叉换算中间币种
+	exchangeRateLimit    = 200   // 汇率列表默认条数
+)
+
+var (
+	errRateNotFound = errors.New("汇率不存在")
+	errRateReadOnly = errors.New("同步的公共汇率不可修改")
+	errRateExists   = errors.New("该日期的汇率已存在，请直接修改")
+	errRateSameCode = errors.New("源币种与目标币种不能相同")
+	errRateCurrency = errors.New("币种代码应为三位字母，如 USD")
+)
+
+// CurrencyConverter 提供用户本位币及币种间汇率，用于交易记账汇率和余额折算
+type CurrencyConverter interface {
+	// BaseCurrency 用户的本位币
+	BaseCurrency(userID int64) (string, error)
+	// GetRate 查询 from 兑 to 在指定日期的汇率：取不晚于该日期的最近汇率，支持反向汇率和经由中间币种的交叉汇率
+	GetRate(userID int64, from, to string, date time.Time) (money.Rate, error)
+}
+
+// ExchangeRateService 汇率服务接口
+type ExchangeRateService interface {
+	CurrencyConverter
+	CreateRate(userID int64, req *request.CreateExchangeRateRequest) (*response.ExchangeRateResponse, error)
+	GetRates(userID int64, req *request.ListExchangeRateRequest) ([]*response.ExchangeRateResponse, error)
+	UpdateRate(userID int64, rateID int64, req *request.UpdateExchangeRateRequest) (*response.ExchangeRateResponse, error)
+	DeleteRate(userID int64, rateID int64) error
+	// Convert 按汇率换算金额，未指定目标币种时换算为本位币
+	Convert(userID int64, req *request.ConvertCurrencyRequest) (*response.ConvertCurrencyResponse, error)
+	// SyncRates 从汇率数据源同步公共汇率，返回写入的条数
+	SyncRates() (int, error)
+}
+
+type exchangeRateService struct {
+	rateRepo repository.ExchangeRateRepository
+	userRepo repository.UserRepository
+	provider exchange.Provider // 汇率数据源，未配置时仅使用手工录入的汇率
+	pivot    string            // 交叉换算的中间币种
+}
+
+// NewExchangeRateService 创建汇率服务实例
+func NewExchangeRateService(rateRepo repository.ExchangeRateRepository, userRepo repository.UserRepository) ExchangeRateService {
+	return &exchangeRateService{
+		rateRepo: rateRepo,
+		userRepo: userRepo,
+		provider: rateProvider(),
+		pivot:    ratePivotCurrency(),
+	}
+}
+
+// BaseCurrency 获取用户本位币
+func (s *exchangeRateService) BaseCurrency(userID int64) (string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if currency := exchange.NormalizeCurrency(user.Currency); currency != "" {
+		return currency, nil
+	}
+	return defaultCurrency, nil
+}
+
+// GetRate 查询汇率，依次尝试直接汇率、反向汇率和经由中间币种的交叉汇率
+func (s *exchangeRateService) GetRate(userID int64, from, to string, date time.Time) (money.Rate, error) {
+	if from == to {
+		return money.OneRate, nil
+	}
+
+	rate, err := s.lookupRate(userID, from, to, date)
+	if err != nil || rate > 0 {
+		return rate, err
+	}
+
+	if s.pivot != "" && s.pivot != from && s.pivot != to {
+		first, err := s.lookupRate(userID, from, s.pivot, date)
+		if err != nil {
+			return 0, err
+		}
+		second, err := s.lookupRate(userID, s.pivot, to, date)
+		if err != nil {
+			return 0, err
+		}
+		if first > 0 && second > 0 {
+			return first.Cross(second), nil
+		}
+	}
+
+	return 0, fmt.Errorf("缺少%s兑%s的汇率，请先录入汇率", from, to)
+}
+
+// lookupRate 查询直接或反向汇率，两者都存在时取生效日期较近的一条，均不存在时返回0
+func (s *exchangeRateService) lookupRate(userID int64, from, to string, date time.Time) (money.Rate, error) {
+	direct, err := s.rateRepo.FindLatest(userID, from, to, date)
+	if err != nil {
+		return 0, err
+	}
+	inverse, err := s.rateRepo.FindLatest(userID, to, from, date)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case direct != nil && (inverse == nil || !inverse.RateDate.After(direct.RateDate)):
+		return direct.Rate, nil
+	case inverse != nil:
+		return inverse.Rate.Inverse(), nil
+	default:
+		return 0, nil
+	}
+}
+
+// CreateRate 手工录入汇率
+func (s *exchangeRateService) CreateRate(userID int64, req *request.CreateExchangeRateRequest) (*response.ExchangeRateResponse, error) {
+	from, to := exchange.NormalizeCurrency(req.FromCurrency), exchange.NormalizeCurrency(req.ToCurrency)
+	if from == "" || to == "" {
+		return nil, errRateCurrency
+	}
+	if from == to {
+		return nil, errRateSameCode
+	}
+
+	rateDate := *truncateDate(&req.RateDate)
+	existing, err := s.rateRepo.FindOne(userID, from, to, rateDate)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errRateExists
+	}
+
+	rate := &models.ExchangeRate{
+		UserID:       userID,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         req.Rate,
+		RateDate:     rateDate,
+		Source:       models.ExchangeRateSourceManual,
+	}
+	if err := s.rateRepo.Create(rate); err != nil {
+		logger.Error(fmt.Sprintf("[Service][汇率] 录入失败 | 用户ID: %d | 币种: %s/%s | 错误: %v", userID, from, to, err))
+		return nil, errors.New("录入汇率失败")
+	}
+
+	logger.Info(fmt.Sprintf("[Service][汇率] 录入成功 | 用户ID: %d | 币种: %s/%s | 汇率: %s", userID, from, to, rate.Rate))
+	return toExchangeRateResponse(userID, rate), nil
+}
+
+// GetRates 获取汇率列表，包含本人录入的和同步的公共汇率
+func (s *exchangeRateService) GetRates(userID int64, req *request.ListExchangeRateRequest) ([]*response.ExchangeRateResponse, error) {
+	currency := ""
+	if req.Currency != "" {
+		if currency = exchange.NormalizeCurrency(req.Currency); currency == "" {
+			return nil, errRateCurrency
+		}
+	}
+
+	rates, err := s.rateRepo.FindByUserID(userID, currency, exchangeRateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*response.ExchangeRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		result = append(result, toExchangeRateResponse(userID, rate))
+	}
+	return result, nil
+}
+
+// UpdateRate 修改本人录入的汇率，已记账交易保留记账时的汇率不受影响
+func (s *exchangeRateService) UpdateRate(userID int64, rateID int64, req *request.UpdateExchangeRateRequest) (*response.ExchangeRateResponse, error) {
+	rate, err := s.findUserRate(userID, rateID)
+	if err != nil {
+		return nil, err
+	}
+
+	rate.Rate = req.Rate
+	if err := s.rateRepo.Update(rate); err != nil {
+		return nil, err
+	}
+	return toExchangeRateResponse(userID, rate), nil
+}
+
+// DeleteRate 删除本人录入的汇率
+func (s *exchangeRateService) DeleteRate(userID int64, rateID int64) error {
+	if _, err := s.findUserRate(userID, rateID); err != nil {
+		return err
+	}
+	return s.rateRepo.Delete(rateID)
+}
+
+// Convert 按汇率换算金额
+func (s *exchangeRateService) Convert(userID int64, req *request.ConvertCurrencyRequest) (*response.ConvertCurrencyResponse, error) {
+	from, to := exchange.NormalizeCurrency(req.FromCurrency), exchange.NormalizeCurrency(req.ToCurrency)
+	if from == "" || (req.ToCurrency != "" && to == "") {
+		return nil, errRateCurrency
+	}
+	if to == "" {
+		base, err := s.BaseCurrency(userID)
+		if err != nil {
+			return nil, err
+		}
+		to = base
+	}
+
+	date := time.Now()
+	if req.Date != nil {
+		date = *req.Date
+	}
+	rate, err := s.GetRate(userID, from, to, date)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := req.Amount.Convert(rate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.ConvertCurrencyResponse{
+		Amount:       req.Amount,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         rate,
+		Converted:    converted,
+	}, nil
+}
+
+// SyncRates 从数据源拉取汇率写入公共汇率表，同一币种对同一天重复同步时覆盖
+func (s *exchangeRateService) SyncRates() (int, error) {
+	if s.provider == nil {
+		return 0, nil
+	}
+
+	tables, err := s.provider.Fetch()
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Warn(fmt.Sprintf("[Service][汇率] 汇率文件不存在，跳过同步 | 数据源: %s", s.provider.Name()))
+		return 0, nil
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][汇率] 读取数据源失败 | 数据源: %s | 错误: %v", s.provider.Name(), err))
+		return 0, err
+	}
+
+	var rates []*models.ExchangeRate
+	for _, table := range tables {
+		for currency, rate := range table.Rates {
+			rates = append(rates, &models.ExchangeRate{
+				FromCurrency: table.Base,
+				ToCurrency:   currency,
+				Rate:         rate,
+				RateDate:     table.Date,
+				Source:       s.provider.Name(),
+			})
+		}
+	}
+	if err := s.rateRepo.UpsertShared(rates); err != nil {
+		logger.Error(fmt.Sprintf("[Service][汇率] 写入汇率失败 | 数据源: %s | 错误: %v", s.provider.Name(), err))
+		return 0, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][汇率] 同步完成 | 数据源: %s | 汇率数: %d", s.provider.Name(), len(rates)))
+	return len(rates), nil
+}
+
+// findUserRate 查找本人录入的汇率，公共汇率只读
+func (s *exchangeRateService) findUserRate(userID int64, rateID int64) (*models.ExchangeRate, error) {
+	rate, err := s.rateRepo.FindByID(rateID)
+	if err != nil {
+		return nil, errRateNotFound
+	}
+	if rate.UserID == 0 {
+		return nil, errRateReadOnly
+	}
+	if rate.UserID != userID {
+		return nil, errRateNotFound
+	}
+	return rate, nil
+}
+
+func toExchangeRateResponse(userID int64, rate *models.ExchangeRate) *response.ExchangeRateResponse {
+	return &response.ExchangeRateResponse{
+		ID:           rate.ID,
+		FromCurrency: rate.FromCurrency,
+		ToCurrency:   rate.ToCurrency,
+		Rate:         rate.Rate,
+		RateDate:     rate.RateDate,
+		Source:       rate.Source,
+		Editable:     rate.UserID != 0 && rate.UserID == userID,
+		UpdatedAt:    rate.UpdatedAt,
+	}
+}
+
+// rateProvider 根据配置创建汇率数据源，未配置汇率文件时返回 nil
+func rateProvider() exchange.Provider {
+	if path := viper.GetString("exchange_rate.file_path"); path != "" {
+		return exchange.NewFileProvider(path)
+	}
+	return nil
+}
+
+func ratePivotCurrency() string {
+	if currency := exchange.NormalizeCurrency(viper.GetString("exchange_rate.pivot_currency")); currency != "" {
+		return currency
+	}
+	return defaultPivotCurrency
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/exchange"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExchangeRateRepository Mock ExchangeRateRepository
+type MockExchangeRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRateRepository) Create(rate *models.ExchangeRate) error {
+	args := m.Called(rate)
+	return args.Error(0)
+}
+
+func (m *MockExchangeRateRepository) FindByID(id int64) (*models.ExchangeRate, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) FindByUserID(userID int64, currency string, limit int) ([]*models.ExchangeRate, error) {
+	args := m.Called(userID, currency, limit)
+	return args.Get(0).([]*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) FindOne(userID int64, from, to string, date time.Time) (*models.ExchangeRate, error) {
+	args := m.Called(userID, from, to, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) FindLatest(userID int64, from, to string, date time.Time) (*models.ExchangeRate, error) {
+	args := m.Called(userID, from, to, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) Update(rate *models.ExchangeRate) error {
+	args := m.Called(rate)
+	return args.Error(0)
+}
+
+func (m *MockExchangeRateRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockExchangeRateRepository) UpsertShared(rates []*models.ExchangeRate) error {
+	args := m.Called(rates)
+	return args.Error(0)
+}
+
+func newTestExchangeRateService() (*exchangeRateService, *MockExchangeRateRepository) {
+	mockRateRepo := new(MockExchangeRateRepository)
+	svc := &exchangeRateService{rateRepo: mockRateRepo, pivot: "USD"}
+	return svc, mockRateRepo
+}
+
+// TestGetRateInverseAndCross 测试缺少直接汇率时使用反向汇率，以及经由中间币种的交叉汇率
+func TestGetRateInverseAndCross(t *testing.T) {
+	svc, mockRateRepo := newTestExchangeRateService()
+
+	userID := int64(1)
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)
+	usdCNY := &models.ExchangeRate{FromCurrency: "USD", ToCurrency: "CNY", Rate: money.MustParseRate("7.2"), RateDate: date.AddDate(0, 0, -1)}
+	usdEUR := &models.ExchangeRate{FromCurrency: "USD", ToCurrency: "EUR", Rate: money.MustParseRate("0.9"), RateDate: date}
+	mockRateRepo.On("FindLatest", userID, "USD", "CNY", date).Return(usdCNY, nil)
+	mockRateRepo.On("FindLatest", userID, "CNY", "USD", date).Return(nil, nil)
+	mockRateRepo.On("FindLatest", userID, "EUR", "USD", date).Return(nil, nil)
+	mockRateRepo.On("FindLatest", userID, "USD", "EUR", date).Return(usdEUR, nil)
+	mockRateRepo.On("FindLatest", userID, "EUR", "CNY", date).Return(nil, nil)
+	mockRateRepo.On("FindLatest", userID, "CNY", "EUR", date).Return(nil, nil)
+	mockRateRepo.On("FindLatest", userID, "JPY", "CNY", date).Return(nil, nil)
+	mockRateRepo.On("FindLatest", userID, "CNY", "JPY", date).Return(nil, nil)
+	mockRateRepo.On("FindLatest", userID, "JPY", "USD", date).Return(nil, nil)
+	mockRateRepo.On("FindLatest", userID, "USD", "JPY", date).Return(nil, nil)
+
+	rate, err := svc.GetRate(userID, "CNY", "USD", date)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.13888889", rate.String())
+
+	// EUR→USD（反向 1/0.9）→CNY（7.2）
+	rate, err = svc.GetRate(userID, "EUR", "CNY", date)
+	assert.NoError(t, err)
+	converted, err := money.MustParse("100.00").Convert(rate)
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("800.00"), converted)
+
+	rate, err = svc.GetRate(userID, "CNY", "CNY", date)
+	assert.NoError(t, err)
+	assert.Equal(t, money.OneRate, rate)
+
+	_, err = svc.GetRate(userID, "JPY", "CNY", date)
+	assert.EqualError(t, err, "缺少JPY兑CNY的汇率，请先录入汇率")
+}
+
+// TestCreateRateDuplicate 测试同一天重复录入同一币种对的汇率
+func TestCreateRateDuplicate(t *testing.T) {
+	svc, mockRateRepo := newTestExchangeRateService()
+
+	userID := int64(1)
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)
+	mockRateRepo.On("FindOne", userID, "USD", "CNY", date).Return(&models.ExchangeRate{ID: 3}, nil)
+
+	_, err := svc.CreateRate(userID, &request.CreateExchangeRateRequest{FromCurrency: "usd", ToCurrency: "CNY", Rate: money.MustParseRate("7.2"), RateDate: date.Add(10 * time.Hour)})
+	assert.Equal(t, errRateExists, err)
+	mockRateRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	mockRateRepo.On("FindByID", int64(9)).Return(&models.ExchangeRate{ID: 9, UserID: 0}, nil)
+	assert.Equal(t, errRateReadOnly, svc.DeleteRate(userID, 9))
+}
+
+// TestSyncRatesFromFile 测试从离线汇率文件同步公共汇率
+func TestSyncRatesFromFile(t *testing.T) {
+	svc, mockRateRepo := newTestExchangeRateService()
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"base":"USD","date":"2024-03-01","rates":{"CNY":7.19,"EUR":0.92}}`), 0644))
+	svc.provider = exchange.NewFileProvider(path)
+
+	mockRateRepo.On("UpsertShared", mock.MatchedBy(func(rates []*models.ExchangeRate) bool {
+		for _, rate := range rates {
+			if rate.UserID != 0 || rate.FromCurrency != "USD" || rate.Source != "file" {
+				return false
+			}
+		}
+		return len(rates) == 2
+	})).Return(nil)
+
+	count, err := svc.SyncRates()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// 汇率文件不存在时跳过同步
+	svc.provider = exchange.NewFileProvider(filepath.Join(t.TempDir(), "missing.json"))
+	count, err = svc.SyncRates()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
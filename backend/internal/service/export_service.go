@@ -0,0 +1,462 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/exporter"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/spf13/viper"
+)
+
+const (
+	maxExportRows       = 50000 // 单个任务最多导出的交易条数
+	maxActiveExports    = 3     // 每个用户同时排队或处理中的任务数
+	exportJobLimit      = 50    // 导出任务列表默认条数
+	exportProcessLimit  = 20    // 后台每次补做的排队任务数
+	defaultExportTTL    = 24 * time.Hour
+	exportStaleAfter    = time.Hour // 处理中超过该时间的任务视为中断
+	exportDownloadRoute = "/api/v1/exports/download/"
+)
+
+var (
+	errExportJobNotFound  = errors.New("导出任务不存在")
+	errExportJobState     = errors.New("导出任务处理中，请稍后再试")
+	errExportTooManyJobs  = fmt.Errorf("最多同时进行%d个导出任务，请稍后再试", maxActiveExports)
+	errExportDateRange    = errors.New("开始日期不能晚于结束日期")
+	errExportLinkExpired  = errors.New("下载链接无效或已过期")
+	errExportTooManyRows  = fmt.Errorf("导出数据超过%d条，请缩小筛选范围", maxExportRows)
+	errExportInvalidMonth = errors.New("月份格式应为 YYYY-MM")
+)
+
+// ExportService 导出服务接口
+type ExportService interface {
+	// CreateJob 创建导出任务，文件在后台生成
+	CreateJob(userID int64, req *request.CreateExportRequest) (*response.ExportJobResponse, error)
+	GetJobs(userID int64) ([]*response.ExportJobResponse, error)
+	GetJob(userID int64, jobID int64) (*response.ExportJobResponse, error)
+	DeleteJob(userID int64, jobID int64) error
+	// ResolveDownload 校验下载令牌，返回文件路径和下载文件名
+	ResolveDownload(token string) (string, string, error)
+	// ProcessPendingJobs 处理仍在排队的任务（如服务重启前未处理的任务）
+	ProcessPendingJobs() (int, error)
+	// CleanupExpired 删除过期的导出文件，并将中断的任务标记为失败
+	CleanupExpired(now time.Time) (int, error)
+}
+
+type exportService struct {
+	exportRepo      repository.ExportRepository
+	transactionRepo repository.TransactionRepository
+	accountRepo     repository.AccountRepository
+	categoryRepo    repository.CategoryRepository
+	dir             string            // 导出文件目录
+	ttl             time.Duration     // 下载链接有效期
+	async           func(task func()) // 后台执行导出任务，测试中可替换为同步执行
+}
+
+// NewExportService 创建导出服务实例
+func NewExportService(
+	exportRepo repository.ExportRepository,
+	transactionRepo repository.TransactionRepository,
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+) ExportService {
+	return &exportService{
+		exportRepo:      exportRepo,
+		transactionRepo: transactionRepo,
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+		dir:             exportDir(),
+		ttl:             exportFileTTL(),
+		async:           func(task func()) { go task() },
+	}
+}
+
+// CreateJob 创建导出任务
+func (s *exportService) CreateJob(userID int64, req *request.CreateExportRequest) (*response.ExportJobResponse, error) {
+	if err := s.checkFilters(userID, req); err != nil {
+		return nil, err
+	}
+
+	job := &models.ExportJob{
+		UserID:     userID,
+		ExportType: req.ExportType,
+		Filters: models.ExportFilters{
+			Type:          req.Type,
+			CategoryID:    req.CategoryID,
+			AccountID:     req.AccountID,
+			SearchKeyword: req.SearchKeyword,
+		},
+		Status: models.ExportStatusPending,
+	}
+	if req.Month != "" {
+		month, err := time.ParseInLocation("2006-01", req.Month, time.Local)
+		if err != nil {
+			return nil, errExportInvalidMonth
+		}
+		end := month.AddDate(0, 1, -1)
+		job.StartDate, job.EndDate = &month, &end
+	} else {
+		job.StartDate, job.EndDate = truncateDate(req.StartDate), truncateDate(req.EndDate)
+	}
+	if job.StartDate != nil && job.EndDate != nil && job.StartDate.After(*job.EndDate) {
+		return nil, errExportDateRange
+	}
+
+	active, err := s.exportRepo.CountActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	if active >= maxActiveExports {
+		return nil, errExportTooManyJobs
+	}
+
+	if err := s.exportRepo.Create(job); err != nil {
+		logger.Error(fmt.Sprintf("[Service][导出] 创建任务失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][导出] 创建任务成功 | 用户ID: %d | 任务ID: %d | 格式: %s", userID, job.ID, job.ExportType))
+	jobID := job.ID
+	s.async(func() { s.processJob(jobID) })
+	return s.toJobResponse(job), nil
+}
+
+// GetJobs 获取最近的导出任务
+func (s *exportService) GetJobs(userID int64) ([]*response.ExportJobResponse, error) {
+	jobs, err := s.exportRepo.FindByUserID(userID, exportJobLimit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导出] 查询任务失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	result := make([]*response.ExportJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, s.toJobResponse(job))
+	}
+	return result, nil
+}
+
+// GetJob 获取导出任务详情
+func (s *exportService) GetJob(userID int64, jobID int64) (*response.ExportJobResponse, error) {
+	job, err := s.findUserJob(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toJobResponse(job), nil
+}
+
+// DeleteJob 删除导出任务及其文件
+func (s *exportService) DeleteJob(userID int64, jobID int64) error {
+	job, err := s.findUserJob(userID, jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status == models.ExportStatusProcessing {
+		return errExportJobState
+	}
+
+	s.removeFile(job)
+	if err := s.exportRepo.Delete(jobID); err != nil {
+		logger.Error(fmt.Sprintf("[Service][导出] 删除任务失败 | 用户ID: %d | 任务ID: %d | 错误: %v", userID, jobID, err))
+		return err
+	}
+	logger.Info(fmt.Sprintf("[Service][导出] 删除任务成功 | 用户ID: %d | 任务ID: %d", userID, jobID))
+	return nil
+}
+
+// ResolveDownload 校验下载令牌，令牌过期或文件已清理时返回错误
+func (s *exportService) ResolveDownload(token string) (string, string, error) {
+	job, err := s.exportRepo.FindByToken(token)
+	if err != nil || job.Status != models.ExportStatusCompleted || job.ExpiresAt == nil || time.Now().After(*job.ExpiresAt) {
+		return "", "", errExportLinkExpired
+	}
+	if _, err := os.Stat(job.FilePath); err != nil {
+		logger.Warn(fmt.Sprintf("[Service][导出] 导出文件不存在 | 任务ID: %d | 错误: %v", job.ID, err))
+		return "", "", errExportLinkExpired
+	}
+	return job.FilePath, job.FileName, nil
+}
+
+// ProcessPendingJobs 处理仍在排队的任务
+func (s *exportService) ProcessPendingJobs() (int, error) {
+	jobs, err := s.exportRepo.FindByStatus(models.ExportStatusPending, time.Now(), exportProcessLimit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导出] 查询排队任务失败 | 错误: %v", err))
+		return 0, err
+	}
+	for _, job := range jobs {
+		s.processJob(job.ID)
+	}
+	return len(jobs), nil
+}
+
+// CleanupExpired 删除过期文件，并将处理中断（如服务重启）的任务标记为失败
+func (s *exportService) CleanupExpired(now time.Time) (int, error) {
+	expired, err := s.exportRepo.FindExpired(now, exportProcessLimit*5)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导出] 查询过期任务失败 | 错误: %v", err))
+		return 0, err
+	}
+	for _, job := range expired {
+		s.removeFile(job)
+		job.Status = models.ExportStatusExpired
+		job.FilePath = ""
+		if err := s.exportRepo.Update(job); err != nil {
+			logger.Error(fmt.Sprintf("[Service][导出] 更新过期任务失败 | 任务ID: %d | 错误: %v", job.ID, err))
+		}
+	}
+
+	stale, err := s.exportRepo.FindByStatus(models.ExportStatusProcessing, now.Add(-exportStaleAfter), exportProcessLimit*5)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导出] 查询中断任务失败 | 错误: %v", err))
+		return len(expired), err
+	}
+	for _, job := range stale {
+		job.Status = models.ExportStatusFailed
+		job.ErrorMessage = "导出任务已中断，请重新导出"
+		if err := s.exportRepo.Update(job); err != nil {
+			logger.Error(fmt.Sprintf("[Service][导出] 更新中断任务失败 | 任务ID: %d | 错误: %v", job.ID, err))
+		}
+	}
+
+	if len(expired) > 0 || len(stale) > 0 {
+		logger.Info(fmt.Sprintf("[Service][导出] 清理完成 | 过期文件: %d | 中断任务: %d", len(expired), len(stale)))
+	}
+	return len(expired), nil
+}
+
+// processJob 生成导出文件；任务只会被一个后台任务领取
+func (s *exportService) processJob(jobID int64) {
+	switched, err := s.exportRepo.TransitionStatus(jobID, models.ExportStatusPending, models.ExportStatusProcessing)
+	if err != nil || !switched {
+		return
+	}
+	job, err := s.exportRepo.FindByID(jobID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导出] 查询任务失败 | 任务ID: %d | 错误: %v", jobID, err))
+		return
+	}
+
+	if err := s.writeFile(job); err != nil {
+		job.Status = models.ExportStatusFailed
+		job.ErrorMessage = err.Error()
+		logger.Error(fmt.Sprintf("[Service][导出] 导出失败 | 用户ID: %d | 任务ID: %d | 错误: %v", job.UserID, job.ID, err))
+	}
+	if err := s.exportRepo.Update(job); err != nil {
+		logger.Error(fmt.Sprintf("[Service][导出] 更新任务失败 | 任务ID: %d | 错误: %v", job.ID, err))
+		return
+	}
+	if job.Status == models.ExportStatusCompleted {
+		logger.Info(fmt.Sprintf("[Service][导出] 导出完成 | 用户ID: %d | 任务ID: %d | 条数: %d | 大小: %d", job.UserID, job.ID, job.RowCount, job.FileSize))
+	}
+}
+
+// writeFile 查询交易并写出文件，成功后生成下载令牌
+func (s *exportService) writeFile(job *models.ExportJob) error {
+	filters := &request.ListTransactionRequest{
+		Type:          job.Filters.Type,
+		CategoryID:    job.Filters.CategoryID,
+		AccountID:     job.Filters.AccountID,
+		SearchKeyword: job.Filters.SearchKeyword,
+	}
+	if job.StartDate != nil {
+		filters.StartDate = *job.StartDate
+	}
+	if job.EndDate != nil {
+		filters.EndDate = *job.EndDate
+	}
+	transactions, err := s.transactionRepo.FindAllByUserID(job.UserID, filters, maxExportRows+1)
+	if err != nil {
+		return err
+	}
+	if len(transactions) > maxExportRows {
+		return errExportTooManyRows
+	}
+	rows := make([]*exporter.Row, 0, len(transactions))
+	for _, t := range transactions {
+		rows = append(rows, toExportRow(t))
+	}
+
+	token, err := newDownloadToken()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(s.dir, strconv.FormatInt(job.UserID, 10))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, strconv.FormatInt(job.ID, 10)+exporter.Extensions[job.ExportType])
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if job.ExportType == exporter.FormatPDF {
+		err = exporter.WriteReport(file, &exporter.Report{
+			Title:       fmt.Sprintf("%d年%d月收支报告", job.StartDate.Year(), job.StartDate.Month()),
+			Start:       *job.StartDate,
+			End:         *job.EndDate,
+			GeneratedAt: now,
+			Rows:        rows,
+		})
+	} else {
+		err = exporter.Write(job.ExportType, file, rows)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(s.ttl)
+	job.Status = models.ExportStatusCompleted
+	job.FileName = exporter.FileName(job.ExportType, job.StartDate, job.EndDate)
+	job.FilePath = path
+	job.FileSize = info.Size()
+	job.RowCount = len(rows)
+	job.DownloadToken = &token
+	job.ErrorMessage = ""
+	job.CompletedAt = &now
+	job.ExpiresAt = &expiresAt
+	return nil
+}
+
+// checkFilters 校验筛选条件中的分类和账户归属
+func (s *exportService) checkFilters(userID int64, req *request.CreateExportRequest) error {
+	if req.AccountID != nil {
+		account, err := s.accountRepo.FindByID(*req.AccountID)
+		if err != nil || account.UserID != userID {
+			return errors.New("invalid account")
+		}
+	}
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.FindByID(*req.CategoryID)
+		if err != nil || (category.UserID != userID && category.UserID != 0) {
+			return errors.New("invalid category")
+		}
+	}
+	return nil
+}
+
+// findUserJob 查找并校验导出任务归属
+func (s *exportService) findUserJob(userID int64, jobID int64) (*models.ExportJob, error) {
+	job, err := s.exportRepo.FindByID(jobID)
+	if err != nil || job.UserID != userID {
+		return nil, errExportJobNotFound
+	}
+	return job, nil
+}
+
+// removeFile 删除导出文件，文件不存在时忽略
+func (s *exportService) removeFile(job *models.ExportJob) {
+	if job.FilePath == "" {
+		return
+	}
+	if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+		logger.Warn(fmt.Sprintf("[Service][导出] 删除文件失败 | 任务ID: %d | 错误: %v", job.ID, err))
+	}
+}
+
+// toExportRow 转换为导出行，分类和账户取名称
+func toExportRow(t *models.Transaction) *exporter.Row {
+	row := &exporter.Row{
+		Date:         t.TransactionDate,
+		Time:         t.TransactionTime,
+		Type:         t.Type,
+		Amount:       t.Amount,
+		Currency:     t.Currency,
+		ExchangeRate: t.ExchangeRate,
+		Title:        t.Title,
+		Description:  t.Description,
+		Location:     t.Location,
+		Tags:         []string(t.Tags),
+	}
+	if t.Category != nil {
+		row.Category = t.Category.Name
+	}
+	if t.Account != nil {
+		row.Account = t.Account.AccountName
+	}
+	if t.ToAccount != nil {
+		row.ToAccount = t.ToAccount.AccountName
+	}
+	return row
+}
+
+// toJobResponse 转换为响应对象，任务完成且未过期时附带下载地址
+func (s *exportService) toJobResponse(job *models.ExportJob) *response.ExportJobResponse {
+	resp := &response.ExportJobResponse{
+		ID:            job.ID,
+		ExportType:    job.ExportType,
+		StartDate:     job.StartDate,
+		EndDate:       job.EndDate,
+		Type:          job.Filters.Type,
+		CategoryID:    job.Filters.CategoryID,
+		AccountID:     job.Filters.AccountID,
+		SearchKeyword: job.Filters.SearchKeyword,
+		Status:        job.Status,
+		FileName:      job.FileName,
+		FileSize:      job.FileSize,
+		RowCount:      job.RowCount,
+		ErrorMessage:  job.ErrorMessage,
+		CreatedAt:     job.CreatedAt,
+		CompletedAt:   job.CompletedAt,
+		ExpiresAt:     job.ExpiresAt,
+	}
+	if job.Status == models.ExportStatusCompleted && job.DownloadToken != nil &&
+		job.ExpiresAt != nil && time.Now().Before(*job.ExpiresAt) {
+		resp.DownloadURL = exportDownloadRoute + *job.DownloadToken
+	}
+	return resp
+}
+
+// newDownloadToken 生成随机下载令牌
+func newDownloadToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// truncateDate 去掉时间部分
+func truncateDate(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return &date
+}
+
+func exportDir() string {
+	dir := viper.GetString("storage.local_path")
+	if dir == "" {
+		dir = "./uploads"
+	}
+	return filepath.Join(dir, "exports")
+}
+
+func exportFileTTL() time.Duration {
+	if hours := viper.GetInt("export.file_ttl"); hours > 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return defaultExportTTL
+}
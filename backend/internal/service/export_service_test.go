@@ -0,0 +1,176 @@
+package service
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/exporter"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExportRepository Mock ExportRepository
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) Create(job *models.ExportJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) FindByID(id int64) (*models.ExportJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExportJob), args.Error(1)
+}
+
+func (m *MockExportRepository) FindByToken(token string) (*models.ExportJob, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExportJob), args.Error(1)
+}
+
+func (m *MockExportRepository) FindByUserID(userID int64, limit int) ([]*models.ExportJob, error) {
+	args := m.Called(userID, limit)
+	return args.Get(0).([]*models.ExportJob), args.Error(1)
+}
+
+func (m *MockExportRepository) FindByStatus(status string, createdBefore time.Time, limit int) ([]*models.ExportJob, error) {
+	args := m.Called(status, createdBefore, limit)
+	return args.Get(0).([]*models.ExportJob), args.Error(1)
+}
+
+func (m *MockExportRepository) FindExpired(now time.Time, limit int) ([]*models.ExportJob, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]*models.ExportJob), args.Error(1)
+}
+
+func (m *MockExportRepository) CountActive(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockExportRepository) Update(job *models.ExportJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) TransitionStatus(id int64, from, to string) (bool, error) {
+	args := m.Called(id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func newTestExportService(t *testing.T) (*exportService, *MockExportRepository, *MockTransactionRepository) {
+	mockExportRepo := new(MockExportRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	svc := NewExportService(mockExportRepo, mockTransRepo, new(MockAccountRepository), new(MockCategoryRepository)).(*exportService)
+	svc.dir = t.TempDir()
+	svc.async = func(task func()) { task() }
+	return svc, mockExportRepo, mockTransRepo
+}
+
+// TestExportJobCSV 测试创建任务后生成 CSV 文件，并可凭下载令牌取回
+func TestExportJobCSV(t *testing.T) {
+	svc, mockExportRepo, mockTransRepo := newTestExportService(t)
+
+	userID := int64(1)
+	job := &models.ExportJob{}
+	mockExportRepo.On("CountActive", userID).Return(int64(0), nil)
+	mockExportRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created := args.Get(0).(*models.ExportJob)
+		created.ID = 5
+		*job = *created
+	}).Return(nil)
+	mockExportRepo.On("TransitionStatus", int64(5), models.ExportStatusPending, models.ExportStatusProcessing).Return(true, nil)
+	mockExportRepo.On("FindByID", int64(5)).Return(job, nil)
+	mockExportRepo.On("Update", mock.Anything).Return(nil)
+	mockTransRepo.On("FindAllByUserID", userID, mock.MatchedBy(func(f *request.ListTransactionRequest) bool {
+		return f.Type == "expense" && f.StartDate.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)) &&
+			f.EndDate.Equal(time.Date(2024, 3, 31, 0, 0, 0, 0, time.Local))
+	}), maxExportRows+1).Return([]*models.Transaction{{
+		Type:            "expense",
+		Amount:          money.MustParse("25.50"),
+		Currency:        "CNY",
+		Title:           "午餐",
+		TransactionDate: time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local),
+		Category:        &models.Category{Name: "餐饮"},
+		Account:         &models.Account{AccountName: "钱包"},
+	}}, nil)
+
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.Local)
+	resp, err := svc.CreateJob(userID, &request.CreateExportRequest{ExportType: exporter.FormatCSV, Type: "expense", StartDate: &start, EndDate: &end})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), resp.ID)
+
+	assert.Equal(t, models.ExportStatusCompleted, job.Status)
+	assert.Equal(t, 1, job.RowCount)
+	assert.Equal(t, "交易记录_20240301-20240331.csv", job.FileName)
+	assert.NotNil(t, job.DownloadToken)
+
+	content, err := os.ReadFile(job.FilePath)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(content), "2024-03-05,,支出,25.50,CNY,餐饮,钱包,,午餐"))
+
+	mockExportRepo.On("FindByToken", *job.DownloadToken).Return(job, nil)
+	path, name, err := svc.ResolveDownload(*job.DownloadToken)
+	assert.NoError(t, err)
+	assert.Equal(t, job.FilePath, path)
+	assert.Equal(t, job.FileName, name)
+
+	detail := svc.toJobResponse(job)
+	assert.Equal(t, exportDownloadRoute+*job.DownloadToken, detail.DownloadURL)
+}
+
+// TestExportJobTooManyActive 测试同时进行的任务数上限
+func TestExportJobTooManyActive(t *testing.T) {
+	svc, mockExportRepo, _ := newTestExportService(t)
+
+	mockExportRepo.On("CountActive", int64(1)).Return(int64(maxActiveExports), nil)
+	_, err := svc.CreateJob(1, &request.CreateExportRequest{ExportType: exporter.FormatPDF, Month: "2024-03"})
+	assert.Equal(t, errExportTooManyJobs, err)
+	mockExportRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestExportCleanupExpired 测试过期任务的文件被删除、下载令牌失效
+func TestExportCleanupExpired(t *testing.T) {
+	svc, mockExportRepo, _ := newTestExportService(t)
+
+	path := svc.dir + "/1.csv"
+	assert.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+	token := "abc"
+	expiresAt := time.Now().Add(-time.Minute)
+	job := &models.ExportJob{ID: 1, UserID: 1, Status: models.ExportStatusCompleted, FilePath: path, DownloadToken: &token, ExpiresAt: &expiresAt}
+
+	now := time.Now()
+	mockExportRepo.On("FindExpired", now, mock.Anything).Return([]*models.ExportJob{job}, nil)
+	mockExportRepo.On("FindByStatus", models.ExportStatusProcessing, now.Add(-exportStaleAfter), mock.Anything).Return([]*models.ExportJob{}, nil)
+	mockExportRepo.On("Update", job).Return(nil)
+	mockExportRepo.On("FindByToken", token).Return(job, nil)
+
+	_, _, err := svc.ResolveDownload(token)
+	assert.Equal(t, errExportLinkExpired, err)
+
+	count, err := svc.CleanupExpired(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, models.ExportStatusExpired, job.Status)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.Empty(t, svc.toJobResponse(job).DownloadURL)
+}
@@ -0,0 +1,897 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/importer"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+const (
+	maxImportRows       = 5000 // 单次导入最大行数
+	maxImportErrors     = 100  // 批次中保留的错误信息条数
+	importBatchLimit    = 50   // 导入批次列表默认条数
+	importProgressEvery = 100  // 每写入该行数刷新一次批次进度，避免被判定为中断
+
+	importStaleAfter = time.Hour // 处理中超过该时间的批次视为中断
+)
+
+var (
+	errImportProfileNotFound = errors.New("导入配置不存在")
+	errImportBatchNotFound   = errors.New("导入批次不存在")
+	errImportBatchState      = errors.New("导入批次当前状态不允许此操作")
+	errImportNoMapping       = errors.New("无法识别账单格式，请选择导入配置或提交列映射")
+	errImportNoAccount       = errors.New("银行对账单导入需要指定目标账户")
+	errImportEmpty           = errors.New("文件中没有可导入的数据行")
+	errImportTooManyRows     = fmt.Errorf("单次最多导入%d行", maxImportRows)
+)
+
+// importFieldNames 校验失败时展示的字段名称
+var importFieldNames = map[string]string{
+	"Type":            "交易类型",
+	"AccountID":       "账户",
+	"ToAccountID":     "转入账户",
+	"Amount":          "金额",
+	"Currency":        "币种",
+	"Title":           "标题",
+	"Location":        "地点",
+	"TransactionDate": "日期",
+}
+
+// ImportService 交易导入服务接口
+type ImportService interface {
+	GetProfiles(userID int64) ([]*response.ImportProfileResponse, error)
+	CreateProfile(userID int64, req *request.ImportProfileRequest) (*response.ImportProfileResponse, error)
+	UpdateProfile(userID int64, profileID int64, req *request.ImportProfileRequest) (*response.ImportProfileResponse, error)
+	DeleteProfile(userID int64, profileID int64) error
+	// Preview 解析并校验文件，生成待提交的导入批次，不写入任何交易
+	Preview(userID int64, fileName string, file io.Reader, req *request.PreviewImportRequest) (*response.ImportPreviewResponse, error)
+	// Commit 提交预览通过的行，在后台异步写入交易
+	Commit(userID int64, batchID int64) (*response.ImportBatchResponse, error)
+	// Rollback 删除该批次导入的全部交易并恢复账户余额
+	Rollback(userID int64, batchID int64) (*response.ImportBatchResponse, error)
+	GetBatches(userID int64) ([]*response.ImportBatchResponse, error)
+	GetBatch(userID int64, batchID int64) (*response.ImportBatchResponse, error)
+	// FailStaleBatches 将处理中断（如服务重启）的批次标记为失败，已写入的交易可通过回滚删除
+	FailStaleBatches(now time.Time) (int, error)
+}
+
+// importPayloadRow 预览通过校验、等待提交的一行
+type importPayloadRow struct {
+	Line       int                              `json:"line"`
+	ExternalID string                           `json:"external_id,omitempty"`
+	Request    request.CreateTransactionRequest `json:"request"`
+}
+
+type importService struct {
+	importRepo         repository.ImportRepository
+	transactionRepo    repository.TransactionRepository
+	accountRepo        repository.AccountRepository
+	categoryRepo       repository.CategoryRepository
+	transactionService TransactionService
+	rules              TransactionRuleApplier
+	async              func(task func()) // 后台执行提交任务，测试中可替换为同步执行
+}
+
+// NewImportService 创建交易导入服务实例
+func NewImportService(
+	importRepo repository.ImportRepository,
+	transactionRepo repository.TransactionRepository,
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+	transactionService TransactionService,
+	rules TransactionRuleApplier,
+) ImportService {
+	return &importService{
+		importRepo:         importRepo,
+		transactionRepo:    transactionRepo,
+		accountRepo:        accountRepo,
+		categoryRepo:       categoryRepo,
+		transactionService: transactionService,
+		rules:              rules,
+		async:              func(task func()) { go task() },
+	}
+}
+
+// GetProfiles 获取列映射配置列表
+func (s *importService) GetProfiles(userID int64) ([]*response.ImportProfileResponse, error) {
+	profiles, err := s.importRepo.FindProfilesByUserID(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 查询配置失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	result := make([]*response.ImportProfileResponse, 0, len(profiles))
+	for _, profile := range profiles {
+		result = append(result, s.toProfileResponse(profile))
+	}
+	return result, nil
+}
+
+// CreateProfile 创建列映射配置
+func (s *importService) CreateProfile(userID int64, req *request.ImportProfileRequest) (*response.ImportProfileResponse, error) {
+	profile := &models.ImportProfile{UserID: userID}
+	if err := s.applyProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.importRepo.CreateProfile(profile); err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 创建配置失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][导入] 创建配置成功 | 用户ID: %d | 配置ID: %d", userID, profile.ID))
+	return s.toProfileResponse(profile), nil
+}
+
+// UpdateProfile 更新列映射配置
+func (s *importService) UpdateProfile(userID int64, profileID int64, req *request.ImportProfileRequest) (*response.ImportProfileResponse, error) {
+	profile, err := s.findUserProfile(userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.importRepo.UpdateProfile(profile); err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 更新配置失败 | 用户ID: %d | 配置ID: %d | 错误: %v", userID, profileID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][导入] 更新配置成功 | 用户ID: %d | 配置ID: %d", userID, profileID))
+	return s.toProfileResponse(profile), nil
+}
+
+// DeleteProfile 删除列映射配置
+func (s *importService) DeleteProfile(userID int64, profileID int64) error {
+	if _, err := s.findUserProfile(userID, profileID); err != nil {
+		return err
+	}
+	if err := s.importRepo.DeleteProfile(profileID); err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 删除配置失败 | 用户ID: %d | 配置ID: %d | 错误: %v", userID, profileID, err))
+		return err
+	}
+	logger.Info(fmt.Sprintf("[Service][导入] 删除配置成功 | 用户ID: %d | 配置ID: %d", userID, profileID))
+	return nil
+}
+
+// Preview 解析文件并逐行校验，通过校验的行保存在批次中等待提交
+// 未选择配置也未提交列映射时，按支付宝、微信支付账单格式解析；OFX、QIF、camt.053 按银行对账单解析
+func (s *importService) Preview(userID int64, fileName string, file io.Reader, req *request.PreviewImportRequest) (*response.ImportPreviewResponse, error) {
+	profile, err := s.resolvePreviewProfile(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDefaults(userID, req.AccountID, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	format, err := importer.DetectFormat(fileName)
+	if err != nil {
+		return nil, err
+	}
+	batch := &models.ImportBatch{
+		UserID:     userID,
+		ProfileID:  req.ProfileID,
+		FileName:   fileName,
+		FileFormat: format,
+		Status:     models.ImportStatusPreviewed,
+	}
+
+	var rows []*importer.Row
+	duplicateReason := "订单号已导入"
+	if importer.IsBankFormat(format) {
+		// 银行对账单绑定到指定账户，流水号在账户内去重
+		if req.AccountID == nil {
+			return nil, errImportNoAccount
+		}
+		statement, err := importer.ParseBankStatement(format, file)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("[Service][导入] 对账单解析失败 | 用户ID: %d | 文件: %s | 错误: %v", userID, fileName, err))
+			return nil, err
+		}
+		rows = statement.Rows
+		batch.AccountID = req.AccountID
+		batch.ClosingBalance = statement.ClosingBalance
+		batch.BalanceDate = statement.BalanceDate
+		duplicateReason = "流水号已导入"
+	} else {
+		rows, batch.Source, err = s.parseTable(userID, fileName, format, file, profile, req.Source)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(rows) == 0 {
+		return nil, errImportEmpty
+	}
+	if len(rows) > maxImportRows {
+		return nil, errImportTooManyRows
+	}
+	batch.TotalRows = len(rows)
+
+	resolver, err := s.newImportResolver(userID, batch.Source)
+	if err != nil {
+		return nil, err
+	}
+	if profile != nil {
+		resolver.defaultAccountID = profile.DefaultAccountID
+		resolver.defaultCategoryID = profile.DefaultCategoryID
+	}
+	if req.AccountID != nil {
+		resolver.defaultAccountID = req.AccountID
+	}
+	if req.CategoryID != nil {
+		resolver.defaultCategoryID = req.CategoryID
+	}
+	if s.rules != nil {
+		if resolver.rules, err = s.rules.EnabledRules(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.existingExternalIDs(userID, batch.AccountID, rows)
+	if err != nil {
+		return nil, err
+	}
+	previewRows := make([]*response.ImportPreviewRow, 0, len(rows))
+	payload := make([]importPayloadRow, 0, len(rows))
+	var pending money.Money // 期末余额日期及之前待导入交易的净额
+	for _, row := range rows {
+		// 同一订单号或流水号（文件内重复或此前已导入）只导入一次
+		if row.Record != nil && row.Record.ExternalID != "" && row.Skip == "" && row.Err == nil {
+			if existing[row.Record.ExternalID] {
+				row.Skip = duplicateReason
+			}
+			existing[row.Record.ExternalID] = true
+		}
+
+		preview, transReq := resolver.resolve(row)
+		previewRows = append(previewRows, preview)
+		switch {
+		case preview.Skipped:
+			batch.SkippedRows++
+		case preview.Valid:
+			payload = append(payload, importPayloadRow{Line: row.Line, ExternalID: row.Record.ExternalID, Request: *transReq})
+			if batch.BalanceDate != nil && !transReq.TransactionDate.After(*batch.BalanceDate) {
+				pending += transactionEffects(transReq.Type, transReq.AccountID, transReq.ToAccountID, transReq.Amount)[*batch.AccountID]
+			}
+		}
+	}
+	batch.ValidRows = len(payload)
+
+	if batch.ClosingBalance != nil {
+		if batch.BalanceDifference, err = s.balanceDifference(batch, pending); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	batch.Payload = string(data)
+	if err := s.importRepo.CreateBatch(batch); err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 创建批次失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][导入] 预览完成 | 用户ID: %d | 批次ID: %d | 总行数: %d | 有效行数: %d | 跳过: %d", userID, batch.ID, batch.TotalRows, batch.ValidRows, batch.SkippedRows))
+	return &response.ImportPreviewResponse{
+		Batch: s.toBatchResponse(batch),
+		Rows:  previewRows,
+	}, nil
+}
+
+// parseTable 解析 CSV/XLSX 表格：有配置时按列映射解析，否则按支付宝、微信支付账单解析并返回账单来源
+func (s *importService) parseTable(userID int64, fileName, format string, file io.Reader, profile *models.ImportProfile, source string) ([]*importer.Row, string, error) {
+	var delimiter rune
+	if profile != nil {
+		if r, _ := utf8.DecodeRuneInString(profile.Delimiter); r != utf8.RuneError {
+			delimiter = r
+		}
+	}
+	table, err := importer.ReadTable(format, file, delimiter)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("[Service][导入] 文件解析失败 | 用户ID: %d | 文件: %s | 错误: %v", userID, fileName, err))
+		return nil, "", err
+	}
+
+	if profile != nil {
+		rows, err := importer.Parse(table, importMapping(profile))
+		return rows, "", err
+	}
+	detected, rows, err := importer.ParseStatement(table, source)
+	if errors.Is(err, importer.ErrUnknownStatement) {
+		return nil, "", errImportNoMapping
+	}
+	return rows, detected, err
+}
+
+// balanceDifference 核对对账单期末余额，返回期末余额与推算余额之差
+// 推算余额 = 账户当前余额 + 待导入交易净额 - 期末日期之后已记账交易的净额
+func (s *importService) balanceDifference(batch *models.ImportBatch, pending money.Money) (*money.Money, error) {
+	account, err := s.accountRepo.FindByID(*batch.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.transactionRepo.GetAccountNetChangeAfter(*batch.AccountID, *batch.BalanceDate)
+	if err != nil {
+		return nil, err
+	}
+	difference := *batch.ClosingBalance - (account.Balance + pending - after)
+	if difference != 0 {
+		logger.Warn(fmt.Sprintf("[Service][导入] 对账单余额不一致 | 用户ID: %d | 账户ID: %d | 期末余额: %s | 差额: %s", batch.UserID, account.ID, batch.ClosingBalance.String(), difference.String()))
+	}
+	return &difference, nil
+}
+
+// Commit 提交导入批次，交易在后台逐条写入，可通过批次详情查询进度
+func (s *importService) Commit(userID int64, batchID int64) (*response.ImportBatchResponse, error) {
+	batch, err := s.findUserBatch(userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.ValidRows == 0 {
+		return nil, errImportEmpty
+	}
+
+	switched, err := s.importRepo.TransitionStatus(batchID, models.ImportStatusPreviewed, models.ImportStatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+	if !switched {
+		return nil, errImportBatchState
+	}
+	batch.Status = models.ImportStatusProcessing
+
+	logger.Info(fmt.Sprintf("[Service][导入] 开始提交 | 用户ID: %d | 批次ID: %d | 行数: %d", userID, batchID, batch.ValidRows))
+	s.async(func() { s.runImport(batch) })
+	return s.toBatchResponse(batch), nil
+}
+
+// runImport 逐条写入批次中的交易，单行失败不影响其他行
+func (s *importService) runImport(batch *models.ImportBatch) {
+	var rows []importPayloadRow
+	if err := json.Unmarshal([]byte(batch.Payload), &rows); err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 批次数据损坏 | 批次ID: %d | 错误: %v", batch.ID, err))
+		batch.Errors = append(batch.Errors, "批次数据损坏，无法导入")
+	}
+
+	// 预览后可能已通过其他批次导入了相同订单，提交前再次去重
+	externalIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.ExternalID != "" {
+			externalIDs = append(externalIDs, row.ExternalID)
+		}
+	}
+	imported := make(map[string]bool, len(externalIDs))
+	if len(externalIDs) > 0 {
+		existing, err := s.findExistingExternalIDs(batch.UserID, batch.AccountID, externalIDs)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][导入] 订单号去重查询失败 | 批次ID: %d | 错误: %v", batch.ID, err))
+		}
+		for _, id := range existing {
+			imported[id] = true
+		}
+	}
+
+	for i, row := range rows {
+		if i > 0 && i%importProgressEvery == 0 && !s.touchProgress(batch) {
+			return
+		}
+		if row.ExternalID != "" && imported[row.ExternalID] {
+			batch.SkippedRows++
+			continue
+		}
+		transReq := row.Request
+		transReq.ImportBatchID = &batch.ID
+		transReq.ExternalID = row.ExternalID
+		transReq.RulesApplied = true
+		if _, err := s.transactionService.CreateTransaction(batch.UserID, &transReq); err != nil {
+			// 并发提交的其他批次已导入同一订单
+			if repository.IsDuplicateExternalID(err) {
+				batch.SkippedRows++
+				continue
+			}
+			batch.FailedRows++
+			if len(batch.Errors) < maxImportErrors {
+				batch.Errors = append(batch.Errors, fmt.Sprintf("第%d行: %v", row.Line, err))
+			}
+			continue
+		}
+		batch.ImportedRows++
+	}
+
+	now := time.Now()
+	batch.Status = models.ImportStatusCompleted
+	if batch.ImportedRows == 0 {
+		batch.Status = models.ImportStatusFailed
+	}
+	batch.Payload = ""
+	batch.CompletedAt = &now
+	if batch.ClosingBalance != nil && batch.ImportedRows > 0 {
+		difference, err := s.balanceDifference(batch, 0)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][导入] 余额核对失败 | 批次ID: %d | 错误: %v", batch.ID, err))
+		} else {
+			batch.BalanceDifference = difference
+		}
+	}
+	// 批次可能已被判定为中断并回滚，此时不能覆盖其状态
+	finished, err := s.importRepo.FinishBatch(batch, models.ImportStatusProcessing)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 更新批次失败 | 批次ID: %d | 错误: %v", batch.ID, err))
+		return
+	}
+	if !finished {
+		logger.Warn(fmt.Sprintf("[Service][导入] 批次已被标记为中断，放弃更新 | 用户ID: %d | 批次ID: %d | 成功: %d", batch.UserID, batch.ID, batch.ImportedRows))
+		return
+	}
+
+	logger.Info(fmt.Sprintf("[Service][导入] 提交完成 | 用户ID: %d | 批次ID: %d | 成功: %d | 失败: %d", batch.UserID, batch.ID, batch.ImportedRows, batch.FailedRows))
+}
+
+// touchProgress 刷新批次进度，返回批次是否仍在处理中；批次已被标记为中断时停止写入
+func (s *importService) touchProgress(batch *models.ImportBatch) bool {
+	processing, err := s.importRepo.UpdateProgress(batch)
+	if err != nil {
+		// 刷新失败不影响导入，最终结果仍按条件写入
+		logger.Error(fmt.Sprintf("[Service][导入] 刷新批次进度失败 | 批次ID: %d | 错误: %v", batch.ID, err))
+		return true
+	}
+	if !processing {
+		logger.Warn(fmt.Sprintf("[Service][导入] 批次已被标记为中断，停止写入 | 用户ID: %d | 批次ID: %d | 已写入: %d", batch.UserID, batch.ID, batch.ImportedRows))
+	}
+	return processing
+}
+
+// Rollback 回滚导入批次
+func (s *importService) Rollback(userID int64, batchID int64) (*response.ImportBatchResponse, error) {
+	batch, err := s.findUserBatch(userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != models.ImportStatusCompleted && batch.Status != models.ImportStatusFailed {
+		return nil, errImportBatchState
+	}
+
+	deleted, err := s.transactionService.DeleteImportedTransactions(userID, batchID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 回滚失败 | 用户ID: %d | 批次ID: %d | 错误: %v", userID, batchID, err))
+		return nil, err
+	}
+
+	from := batch.Status
+	batch.Status = models.ImportStatusRolledBack
+	rolledBack, err := s.importRepo.FinishBatch(batch, from)
+	if err != nil {
+		return nil, err
+	}
+	if !rolledBack {
+		return nil, errImportBatchState
+	}
+
+	logger.Info(fmt.Sprintf("[Service][导入] 回滚成功 | 用户ID: %d | 批次ID: %d | 删除交易: %d", userID, batchID, deleted))
+	return s.toBatchResponse(batch), nil
+}
+
+// FailStaleBatches 将长时间停留在处理中的批次标记为失败，并按已写入的交易更新成功行数，
+// 使用户可以回滚后重新导入
+func (s *importService) FailStaleBatches(now time.Time) (int, error) {
+	stale, err := s.importRepo.FindBatchesByStatus(models.ImportStatusProcessing, now.Add(-importStaleAfter), importBatchLimit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 查询中断批次失败 | 错误: %v", err))
+		return 0, err
+	}
+
+	failed := 0
+	for _, batch := range stale {
+		imported, err := s.transactionRepo.FindByImportBatchID(batch.UserID, batch.ID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][导入] 统计中断批次已写入交易失败 | 批次ID: %d | 错误: %v", batch.ID, err))
+		} else {
+			batch.ImportedRows = len(imported)
+		}
+		batch.Status = models.ImportStatusFailed
+		batch.Payload = ""
+		batch.CompletedAt = &now
+		batch.Errors = append(batch.Errors, "导入已中断，已写入的交易可回滚后重新导入")
+		// 仅处理仍在处理中的批次，避免覆盖刚刚完成的导入
+		switched, err := s.importRepo.FinishBatch(batch, models.ImportStatusProcessing)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][导入] 标记中断批次失败 | 批次ID: %d | 错误: %v", batch.ID, err))
+			continue
+		}
+		if !switched {
+			continue
+		}
+		failed++
+		logger.Warn(fmt.Sprintf("[Service][导入] 批次已中断 | 用户ID: %d | 批次ID: %d | 已写入: %d", batch.UserID, batch.ID, batch.ImportedRows))
+	}
+	return failed, nil
+}
+
+// GetBatches 获取最近的导入批次
+func (s *importService) GetBatches(userID int64) ([]*response.ImportBatchResponse, error) {
+	batches, err := s.importRepo.FindBatchesByUserID(userID, importBatchLimit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][导入] 查询批次失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	result := make([]*response.ImportBatchResponse, 0, len(batches))
+	for _, batch := range batches {
+		result = append(result, s.toBatchResponse(batch))
+	}
+	return result, nil
+}
+
+// GetBatch 获取导入批次详情（含提交进度）
+func (s *importService) GetBatch(userID int64, batchID int64) (*response.ImportBatchResponse, error) {
+	batch, err := s.findUserBatch(userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toBatchResponse(batch), nil
+}
+
+// resolvePreviewProfile 获取预览使用的配置：已保存的配置或表单中直接提交的列映射，均未提供时返回nil
+func (s *importService) resolvePreviewProfile(userID int64, req *request.PreviewImportRequest) (*models.ImportProfile, error) {
+	if req.ProfileID != nil {
+		return s.findUserProfile(userID, *req.ProfileID)
+	}
+	if req.Profile == nil {
+		return nil, nil
+	}
+	profile := &models.ImportProfile{UserID: userID}
+	if err := s.applyProfileRequest(profile, req.Profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// existingExternalIDs 查询已导入过的订单号或银行流水号
+func (s *importService) existingExternalIDs(userID int64, accountID *int64, rows []*importer.Row) (map[string]bool, error) {
+	externalIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Record != nil && row.Record.ExternalID != "" {
+			externalIDs = append(externalIDs, row.Record.ExternalID)
+		}
+	}
+	existing := make(map[string]bool, len(externalIDs))
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+	ids, err := s.findExistingExternalIDs(userID, accountID, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// findExistingExternalIDs 银行流水号仅在账户内唯一，指定账户时在账户范围内去重
+func (s *importService) findExistingExternalIDs(userID int64, accountID *int64, externalIDs []string) ([]string, error) {
+	if accountID != nil {
+		return s.transactionRepo.FindExistingExternalIDsByAccount(userID, *accountID, externalIDs)
+	}
+	return s.transactionRepo.FindExistingExternalIDs(userID, externalIDs)
+}
+
+// applyProfileRequest 校验并写入配置字段
+func (s *importService) applyProfileRequest(profile *models.ImportProfile, req *request.ImportProfileRequest) error {
+	for field := range req.Columns {
+		if !isImportField(field) {
+			return fmt.Errorf("未知的映射字段: %s", field)
+		}
+	}
+	for value, transType := range req.TypeValues {
+		if transType != importer.TypeExpense && transType != importer.TypeIncome && transType != importer.TypeTransfer {
+			return fmt.Errorf("类型取值 %s 的映射无效: %s", value, transType)
+		}
+	}
+	if err := s.checkDefaults(profile.UserID, req.DefaultAccountID, req.DefaultCategoryID); err != nil {
+		return err
+	}
+
+	profile.Name = req.Name
+	profile.Delimiter = req.Delimiter
+	profile.HeaderRow = 1
+	if req.HeaderRow != nil {
+		profile.HeaderRow = *req.HeaderRow
+	}
+	profile.Columns = models.JSONMap(req.Columns)
+	profile.DateFormat = req.DateFormat
+	profile.TypeValues = models.JSONMap(req.TypeValues)
+	profile.DefaultType = req.DefaultType
+	profile.DefaultAccountID = req.DefaultAccountID
+	profile.DefaultCategoryID = req.DefaultCategoryID
+	return nil
+}
+
+// checkDefaults 校验默认账户和分类归属（系统分类user_id为0）
+func (s *importService) checkDefaults(userID int64, accountID, categoryID *int64) error {
+	if accountID != nil {
+		account, err := s.accountRepo.FindByID(*accountID)
+		if err != nil || account.UserID != userID {
+			return errors.New("invalid account")
+		}
+	}
+	if categoryID != nil {
+		category, err := s.categoryRepo.FindByID(*categoryID)
+		if err != nil || (category.UserID != userID && category.UserID != 0) {
+			return errors.New("invalid category")
+		}
+	}
+	return nil
+}
+
+// findUserProfile 查找并校验配置归属
+func (s *importService) findUserProfile(userID int64, profileID int64) (*models.ImportProfile, error) {
+	profile, err := s.importRepo.FindProfileByID(profileID)
+	if err != nil || profile.UserID != userID {
+		return nil, errImportProfileNotFound
+	}
+	return profile, nil
+}
+
+// findUserBatch 查找并校验批次归属
+func (s *importService) findUserBatch(userID int64, batchID int64) (*models.ImportBatch, error) {
+	batch, err := s.importRepo.FindBatchByID(batchID)
+	if err != nil || batch.UserID != userID {
+		return nil, errImportBatchNotFound
+	}
+	return batch, nil
+}
+
+// importResolver 将解析出的账户、分类名称匹配为用户的账户和分类
+type importResolver struct {
+	accounts          map[string]int64 // 小写账户名 -> ID
+	cardSuffixes      map[string]int64 // 账号后四位 -> ID
+	categories        map[string]int64 // 类型|小写分类名 -> ID
+	defaultAccountID  *int64
+	defaultCategoryID *int64
+	rules             TransactionRules // 在匹配文件中的分类之后、使用默认分类之前执行
+}
+
+// cardSuffixPattern 匹配付款方式中的卡号尾号，如“招商银行信用卡(1234)”
+var cardSuffixPattern = regexp.MustCompile(`[（(](\d{4})[)）]`)
+
+// newImportResolver 加载用户账户和分类；导入第三方账单时默认使用对应类型（支付宝/微信）的首个账户
+func (s *importService) newImportResolver(userID int64, source string) (*importResolver, error) {
+	accounts, err := s.accountRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	systemCategories, err := s.categoryRepo.GetSystemCategories("")
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &importResolver{
+		accounts:     make(map[string]int64, len(accounts)),
+		cardSuffixes: make(map[string]int64, len(accounts)),
+		categories:   make(map[string]int64, len(categories)+len(systemCategories)),
+	}
+	for _, account := range accounts {
+		resolver.accounts[strings.ToLower(account.AccountName)] = account.ID
+		if number := account.AccountNumber; len(number) >= 4 {
+			resolver.cardSuffixes[number[len(number)-4:]] = account.ID
+		}
+		if source != "" && account.AccountType == source && resolver.defaultAccountID == nil {
+			id := account.ID
+			resolver.defaultAccountID = &id
+		}
+	}
+	// 用户分类优先于同名系统分类
+	for _, category := range append(systemCategories, categories...) {
+		resolver.categories[category.Type+"|"+strings.ToLower(category.Name)] = category.ID
+	}
+	return resolver, nil
+}
+
+// resolve 生成一行的预览结果，通过校验时同时返回交易请求
+func (r *importResolver) resolve(row *importer.Row) (*response.ImportPreviewRow, *request.CreateTransactionRequest) {
+	preview := &response.ImportPreviewRow{Line: row.Line, Errors: []string{}, Warnings: []string{}}
+	if row.Err != nil {
+		preview.Errors = append(preview.Errors, row.Err.Error())
+		return preview, nil
+	}
+	if row.Skip != "" {
+		preview.Skipped = true
+		preview.SkipReason = row.Skip
+		if row.Record != nil {
+			preview.ExternalID = row.Record.ExternalID
+		}
+		return preview, nil
+	}
+
+	record := row.Record
+	transReq := &request.CreateTransactionRequest{
+		Type:            record.Type,
+		Amount:          record.Amount,
+		Currency:        record.Currency,
+		Title:           record.Title,
+		Description:     record.Description,
+		TransactionDate: record.Date,
+		TransactionTime: record.Time,
+		Tags:            record.Tags,
+	}
+
+	transReq.AccountID = r.defaultAccountID
+	switch {
+	case record.Account != "":
+		if id, ok := r.accounts[strings.ToLower(record.Account)]; ok {
+			transReq.AccountID = &id
+		} else {
+			preview.Errors = append(preview.Errors, fmt.Sprintf("未找到账户: %s", record.Account))
+		}
+	case record.PaymentMethod != "":
+		if id, ok := r.matchPaymentMethod(record.PaymentMethod); ok {
+			transReq.AccountID = &id
+		}
+	}
+	if record.ToAccount != "" {
+		if id, ok := r.accounts[strings.ToLower(record.ToAccount)]; ok {
+			transReq.ToAccountID = &id
+		} else {
+			preview.Errors = append(preview.Errors, fmt.Sprintf("未找到转入账户: %s", record.ToAccount))
+		}
+	}
+	if record.Type != importer.TypeTransfer && record.Category != "" {
+		if id, ok := r.categories[record.Type+"|"+strings.ToLower(record.Category)]; ok {
+			transReq.CategoryID = &id
+		} else {
+			preview.Warnings = append(preview.Warnings, fmt.Sprintf("未找到分类: %s，将使用默认分类", record.Category))
+		}
+	}
+	r.rules.Apply(transReq)
+	if record.Type != importer.TypeTransfer && transReq.CategoryID == nil {
+		transReq.CategoryID = r.defaultCategoryID
+	}
+
+	if len(preview.Errors) == 0 {
+		if err := binding.Validator.ValidateStruct(transReq); err != nil {
+			preview.Errors = append(preview.Errors, validationMessages(err)...)
+		}
+	}
+
+	preview.Valid = len(preview.Errors) == 0
+	preview.ExternalID = record.ExternalID
+	preview.Type = transReq.Type
+	preview.TransactionDate = &transReq.TransactionDate
+	preview.Amount = transReq.Amount
+	preview.Currency = transReq.Currency
+	preview.AccountID = transReq.AccountID
+	preview.ToAccountID = transReq.ToAccountID
+	preview.CategoryID = transReq.CategoryID
+	preview.Title = transReq.Title
+	preview.Description = transReq.Description
+	preview.Tags = transReq.Tags
+	if !preview.Valid {
+		return preview, nil
+	}
+	return preview, transReq
+}
+
+// matchPaymentMethod 按账户名称或卡号尾号匹配付款方式，未匹配时使用默认账户
+func (r *importResolver) matchPaymentMethod(method string) (int64, bool) {
+	if id, ok := r.accounts[strings.ToLower(method)]; ok {
+		return id, true
+	}
+	if match := cardSuffixPattern.FindStringSubmatch(method); match != nil {
+		if id, ok := r.cardSuffixes[match[1]]; ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// validationMessages 将请求校验错误转换为可读信息
+func validationMessages(err error) []string {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return []string{err.Error()}
+	}
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		name, ok := importFieldNames[fe.Field()]
+		if !ok {
+			name = fe.Field()
+		}
+		if fe.Tag() == "required" || strings.HasPrefix(fe.Tag(), "required_") {
+			messages = append(messages, fmt.Sprintf("%s不能为空", name))
+		} else {
+			messages = append(messages, fmt.Sprintf("%s格式错误", name))
+		}
+	}
+	return messages
+}
+
+// importMapping 将配置转换为解析器的列映射
+func importMapping(profile *models.ImportProfile) *importer.Mapping {
+	return &importer.Mapping{
+		HeaderRow:   profile.HeaderRow,
+		Columns:     profile.Columns,
+		DateFormat:  profile.DateFormat,
+		TypeValues:  profile.TypeValues,
+		DefaultType: profile.DefaultType,
+	}
+}
+
+func isImportField(field string) bool {
+	for _, f := range importer.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// toProfileResponse 转换为响应对象
+func (s *importService) toProfileResponse(profile *models.ImportProfile) *response.ImportProfileResponse {
+	return &response.ImportProfileResponse{
+		ID:                profile.ID,
+		Name:              profile.Name,
+		Delimiter:         profile.Delimiter,
+		HeaderRow:         profile.HeaderRow,
+		Columns:           profile.Columns,
+		DateFormat:        profile.DateFormat,
+		TypeValues:        profile.TypeValues,
+		DefaultType:       profile.DefaultType,
+		DefaultAccountID:  profile.DefaultAccountID,
+		DefaultCategoryID: profile.DefaultCategoryID,
+		CreatedAt:         profile.CreatedAt,
+		UpdatedAt:         profile.UpdatedAt,
+	}
+}
+
+// toBatchResponse 转换为响应对象
+func (s *importService) toBatchResponse(batch *models.ImportBatch) *response.ImportBatchResponse {
+	errs := []string(batch.Errors)
+	if errs == nil {
+		errs = []string{}
+	}
+	return &response.ImportBatchResponse{
+		ID:                batch.ID,
+		ProfileID:         batch.ProfileID,
+		FileName:          batch.FileName,
+		FileFormat:        batch.FileFormat,
+		Source:            batch.Source,
+		Status:            batch.Status,
+		TotalRows:         batch.TotalRows,
+		ValidRows:         batch.ValidRows,
+		ImportedRows:      batch.ImportedRows,
+		FailedRows:        batch.FailedRows,
+		SkippedRows:       batch.SkippedRows,
+		Errors:            errs,
+		AccountID:         batch.AccountID,
+		ClosingBalance:    batch.ClosingBalance,
+		BalanceDate:       batch.BalanceDate,
+		BalanceDifference: batch.BalanceDifference,
+		CreatedAt:         batch.CreatedAt,
+		UpdatedAt:         batch.UpdatedAt,
+		CompletedAt:       batch.CompletedAt,
+	}
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/importer"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockImportRepository Mock ImportRepository
+type MockImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportRepository) CreateProfile(profile *models.ImportProfile) error {
+	args := m.Called(profile)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) FindProfileByID(id int64) (*models.ImportProfile, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportProfile), args.Error(1)
+}
+
+func (m *MockImportRepository) FindProfilesByUserID(userID int64) ([]*models.ImportProfile, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]*models.ImportProfile), args.Error(1)
+}
+
+func (m *MockImportRepository) UpdateProfile(profile *models.ImportProfile) error {
+	args := m.Called(profile)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) DeleteProfile(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) CreateBatch(batch *models.ImportBatch) error {
+	args := m.Called(batch)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) FindBatchByID(id int64) (*models.ImportBatch, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportBatch), args.Error(1)
+}
+
+func (m *MockImportRepository) FindBatchesByUserID(userID int64, limit int) ([]*models.ImportBatch, error) {
+	args := m.Called(userID, limit)
+	return args.Get(0).([]*models.ImportBatch), args.Error(1)
+}
+
+func (m *MockImportRepository) FindBatchesByStatus(status string, updatedBefore time.Time, limit int) ([]*models.ImportBatch, error) {
+	args := m.Called(status, updatedBefore, limit)
+	return args.Get(0).([]*models.ImportBatch), args.Error(1)
+}
+
+func (m *MockImportRepository) UpdateProgress(batch *models.ImportBatch) (bool, error) {
+	args := m.Called(batch)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockImportRepository) FinishBatch(batch *models.ImportBatch, from string) (bool, error) {
+	args := m.Called(batch, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockImportRepository) TransitionStatus(id int64, from, to string) (bool, error) {
+	args := m.Called(id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func newTestImportService() (*importService, *MockImportRepository, *MockTransactionRepository, *MockAccountRepository, *MockCategoryRepository) {
+	mockImportRepo := new(MockImportRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}
+	transService := newTestTransactionService(uow, mockCategoryRepo)
+	svc := NewImportService(mockImportRepo, mockTransRepo, mockAccountRepo, mockCategoryRepo, transService, nil).(*importService)
+	svc.async = func(task func()) { task() }
+	return svc, mockImportRepo, mockTransRepo, mockAccountRepo, mockCategoryRepo
+}
+
+// TestImportPreviewAndCommit 测试预览逐行校验、仅提交有效行并标记导入批次
+func TestImportPreviewAndCommit(t *testing.T) {
+	svc, mockImportRepo, mockTransRepo, mockAccountRepo, mockCategoryRepo := newTestImportService()
+
+	userID := int64(1)
+	wallet := &models.Account{ID: 100, UserID: userID, AccountName: "钱包", Balance: money.MustParse("1000.00")}
+	mockAccountRepo.On("FindByUserID", userID).Return([]*models.Account{wallet}, nil)
+	mockAccountRepo.On("FindByID", int64(100)).Return(wallet, nil)
+	mockAccountRepo.On("FindByIDForUpdate", int64(100)).Return(wallet, nil)
+	mockAccountRepo.On("Update", wallet).Return(nil)
+	mockCategoryRepo.On("FindAll", userID).Return([]*models.Category{{ID: 7, UserID: userID, Type: "expense", Name: "餐饮"}}, nil)
+	mockCategoryRepo.On("GetSystemCategories", "").Return([]*models.Category{}, nil)
+	mockCategoryRepo.On("FindByID", int64(7)).Return(&models.Category{ID: 7, UserID: userID, Type: "expense"}, nil)
+
+	var batch *models.ImportBatch
+	mockImportRepo.On("CreateBatch", mock.Anything).Run(func(args mock.Arguments) {
+		batch = args.Get(0).(*models.ImportBatch)
+		batch.ID = 12
+	}).Return(nil)
+
+	csv := "日期,金额,分类,账户\n" +
+		"2024-03-01,-30.00,餐饮,钱包\n" +
+		"2024-03-02,-15.00,打车,\n" +
+		"2024-03-03,-20.00,餐饮,信用卡\n" +
+		"2024-03-04,0,,\n"
+	resp, err := svc.Preview(userID, "流水.csv", strings.NewReader(csv), &request.PreviewImportRequest{
+		AccountID: &wallet.ID,
+		Profile: &request.ImportProfileRequest{
+			Name:    "临时",
+			Columns: map[string]string{"date": "日期", "amount": "金额", "category": "分类", "account": "账户"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, resp.Batch.TotalRows)
+	assert.Equal(t, 2, resp.Batch.ValidRows)
+	assert.Equal(t, int64(7), *resp.Rows[0].CategoryID)
+	assert.Nil(t, resp.Rows[1].CategoryID)
+	assert.Equal(t, []string{"未找到分类: 打车，将使用默认分类"}, resp.Rows[1].Warnings)
+	assert.Equal(t, []string{"未找到账户: 信用卡"}, resp.Rows[2].Errors)
+	assert.Equal(t, []string{"金额不能为0"}, resp.Rows[3].Errors)
+	mockTransRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	// 提交：只写入两条有效行，且都带上批次ID
+	mockImportRepo.On("FindBatchByID", int64(12)).Return(batch, nil)
+	mockImportRepo.On("TransitionStatus", int64(12), models.ImportStatusPreviewed, models.ImportStatusProcessing).Return(true, nil).Once()
+	mockImportRepo.On("FinishBatch", batch, models.ImportStatusProcessing).Return(true, nil)
+	mockTransRepo.On("Create", mock.MatchedBy(func(tr *models.Transaction) bool {
+		return tr.ImportBatchID != nil && *tr.ImportBatchID == 12 && tr.Type == "expense"
+	})).Return(nil).Twice()
+	mockTransRepo.On("FindByID", mock.Anything).Return(&models.Transaction{}, nil)
+
+	_, err = svc.Commit(userID, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportStatusCompleted, batch.Status)
+	assert.Equal(t, 2, batch.ImportedRows)
+	assert.Equal(t, 0, batch.FailedRows)
+	assert.Empty(t, batch.Payload)
+	assert.Equal(t, money.MustParse("955.00"), wallet.Balance)
+
+	// 重复提交被拒绝
+	mockImportRepo.On("TransitionStatus", int64(12), models.ImportStatusPreviewed, models.ImportStatusProcessing).Return(false, nil)
+	_, err = svc.Commit(userID, 12)
+	assert.Equal(t, errImportBatchState, err)
+}
+
+// TestImportCommitSkipsConcurrentDuplicate 测试提交时订单号被并发提交的其他批次抢先写入，唯一索引冲突的行计为跳过而非失败
+func TestImportCommitSkipsConcurrentDuplicate(t *testing.T) {
+	svc, mockImportRepo, mockTransRepo, mockAccountRepo, _ := newTestImportService()
+
+	userID := int64(1)
+	wallet := &models.Account{ID: 100, UserID: userID, Balance: money.MustParse("1000.00")}
+	mockAccountRepo.On("FindByID", int64(100)).Return(wallet, nil)
+
+	payload, err := json.Marshal([]importPayloadRow{{
+		Line:       2,
+		ExternalID: "4200001",
+		Request: request.CreateTransactionRequest{
+			Type:            "expense",
+			AccountID:       &wallet.ID,
+			Amount:          money.MustParse("30.00"),
+			TransactionDate: date(2024, 3, 1),
+		},
+	}})
+	assert.NoError(t, err)
+	batch := &models.ImportBatch{ID: 12, UserID: userID, Status: models.ImportStatusPreviewed, ValidRows: 1, Payload: string(payload)}
+	mockImportRepo.On("FindBatchByID", int64(12)).Return(batch, nil)
+	mockImportRepo.On("TransitionStatus", int64(12), models.ImportStatusPreviewed, models.ImportStatusProcessing).Return(true, nil)
+	mockImportRepo.On("FinishBatch", batch, models.ImportStatusProcessing).Return(true, nil)
+	mockTransRepo.On("FindExistingExternalIDs", userID, []string{"4200001"}).Return([]string{}, nil)
+	mockTransRepo.On("Create", mock.Anything).Return(&mysql.MySQLError{
+		Number:  1062,
+		Message: "Duplicate entry '1-100-4200001' for key 'transactions.uk_user_external'",
+	})
+
+	_, err = svc.Commit(userID, 12)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, batch.SkippedRows)
+	assert.Equal(t, 0, batch.FailedRows)
+	assert.Equal(t, money.MustParse("1000.00"), wallet.Balance)
+}
+
+// TestImportRollback 测试回滚删除批次内全部交易并恢复账户余额
+func TestImportRollback(t *testing.T) {
+	svc, mockImportRepo, mockTransRepo, mockAccountRepo, _ := newTestImportService()
+
+	userID := int64(1)
+	walletID, cardID := int64(100), int64(200)
+	batch := &models.ImportBatch{ID: 12, UserID: userID, Status: models.ImportStatusCompleted}
+	mockImportRepo.On("FindBatchByID", int64(12)).Return(batch, nil)
+	mockImportRepo.On("FinishBatch", batch, models.ImportStatusCompleted).Return(true, nil)
+
+	mockTransRepo.On("FindByImportBatchID", userID, int64(12)).Return([]*models.Transaction{
+		{ID: 1, Type: "expense", AccountID: &walletID, Amount: money.MustParse("30.00")},
+		{ID: 2, Type: "income", AccountID: &walletID, Amount: money.MustParse("100.00")},
+		{ID: 3, Type: "transfer", AccountID: &walletID, ToAccountID: &cardID, Amount: money.MustParse("50.00")},
+	}, nil)
+	mockTransRepo.On("DeleteBatch", []int64{1, 2, 3}).Return(nil)
+
+	wallet := &models.Account{ID: walletID, UserID: userID, Balance: money.MustParse("500.00")}
+	card := &models.Account{ID: cardID, UserID: userID, Balance: money.MustParse("-200.00")}
+	for _, account := range []*models.Account{wallet, card} {
+		mockAccountRepo.On("FindByIDForUpdate", account.ID).Return(account, nil)
+		mockAccountRepo.On("Update", account).Return(nil)
+	}
+
+	resp, err := svc.Rollback(userID, 12)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportStatusRolledBack, resp.Status)
+	assert.Equal(t, money.MustParse("480.00"), wallet.Balance) // +30 -100 +50
+	assert.Equal(t, money.MustParse("-250.00"), card.Balance)
+	mockTransRepo.AssertExpectations(t)
+
+	// 已回滚的批次不能再次回滚
+	_, err = svc.Rollback(userID, 12)
+	assert.Equal(t, errImportBatchState, err)
+}
+
+// TestImportFailStaleBatches 测试服务重启后停留在处理中的批次被标记为失败，之后可以回滚
+func TestImportFailStaleBatches(t *testing.T) {
+	svc, mockImportRepo, mockTransRepo, _, _ := newTestImportService()
+
+	now := time.Now()
+	stale := &models.ImportBatch{ID: 12, UserID: 1, Status: models.ImportStatusProcessing, ValidRows: 3}
+	finished := &models.ImportBatch{ID: 13, UserID: 1, Status: models.ImportStatusProcessing}
+	mockImportRepo.On("FindBatchesByStatus", models.ImportStatusProcessing, now.Add(-importStaleAfter), importBatchLimit).
+		Return([]*models.ImportBatch{stale, finished}, nil)
+	mockTransRepo.On("FindByImportBatchID", int64(1), int64(12)).Return([]*models.Transaction{{ID: 1}, {ID: 2}}, nil)
+	mockTransRepo.On("FindByImportBatchID", int64(1), int64(13)).Return([]*models.Transaction{}, nil)
+	mockImportRepo.On("FinishBatch", stale, models.ImportStatusProcessing).Return(true, nil)
+	// 批次13在查询后已完成，条件写入不生效
+	mockImportRepo.On("FinishBatch", finished, models.ImportStatusProcessing).Return(false, nil)
+
+	count, err := svc.FailStaleBatches(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, models.ImportStatusFailed, stale.Status)
+	assert.Equal(t, 2, stale.ImportedRows)
+	assert.Len(t, stale.Errors, 1)
+}
+
+// TestImportStopsAfterMarkedStale 测试写入过程中定期刷新进度，批次已被标记为中断时停止写入且不覆盖批次状态
+func TestImportStopsAfterMarkedStale(t *testing.T) {
+	svc, mockImportRepo, mockTransRepo, mockAccountRepo, _ := newTestImportService()
+
+	userID := int64(1)
+	wallet := &models.Account{ID: 100, UserID: userID, Balance: money.MustParse("1000.00")}
+	mockAccountRepo.On("FindByID", int64(100)).Return(wallet, nil)
+	mockAccountRepo.On("FindByIDForUpdate", int64(100)).Return(wallet, nil)
+	mockAccountRepo.On("Update", wallet).Return(nil)
+	mockTransRepo.On("Create", mock.Anything).Return(nil)
+	mockTransRepo.On("FindByID", mock.Anything).Return(&models.Transaction{}, nil)
+
+	rows := make([]importPayloadRow, importProgressEvery*2+1)
+	for i := range rows {
+		rows[i] = importPayloadRow{Line: i + 2, Request: request.CreateTransactionRequest{
+			Type:            "expense",
+			AccountID:       &wallet.ID,
+			Amount:          money.MustParse("1.00"),
+			TransactionDate: date(2024, 3, 1),
+		}}
+	}
+	payload, err := json.Marshal(rows)
+	assert.NoError(t, err)
+	batch := &models.ImportBatch{ID: 12, UserID: userID, Status: models.ImportStatusProcessing, Payload: string(payload)}
+	mockImportRepo.On("UpdateProgress", batch).Return(true, nil).Once()
+	mockImportRepo.On("UpdateProgress", batch).Return(false, nil).Once()
+
+	svc.runImport(batch)
+
+	assert.Equal(t, importProgressEvery*2, batch.ImportedRows)
+	assert.Equal(t, money.MustParse("800.00"), wallet.Balance)
+	mockImportRepo.AssertNotCalled(t, "FinishBatch", mock.Anything, mock.Anything)
+
+	// 写入完成时批次已被标记为中断，放弃更新
+	batch = &models.ImportBatch{ID: 13, UserID: userID, Status: models.ImportStatusProcessing, Payload: "[]"}
+	mockImportRepo.On("FinishBatch", batch, models.ImportStatusProcessing).Return(false, nil)
+	svc.runImport(batch)
+	mockImportRepo.AssertCalled(t, "FinishBatch", batch, models.ImportStatusProcessing)
+}
+
+// TestImportStatementPreview 测试第三方账单：默认使用对应平台账户、按卡号尾号匹配付款方式并按订单号去重
+func TestImportStatementPreview(t *testing.T) {
+	svc, mockImportRepo, mockTransRepo, mockAccountRepo, mockCategoryRepo := newTestImportService()
+
+	userID := int64(1)
+	mockAccountRepo.On("FindByUserID", userID).Return([]*models.Account{
+		{ID: 100, UserID: userID, AccountName: "招行卡", AccountType: "bank", AccountNumber: "6225880012341234"},
+		{ID: 200, UserID: userID, AccountName: "微信钱包", AccountType: "wechat"},
+	}, nil)
+	mockCategoryRepo.On("FindAll", userID).Return([]*models.Category{}, nil)
+	mockCategoryRepo.On("GetSystemCategories", "").Return([]*models.Category{}, nil)
+	mockTransRepo.On("FindExistingExternalIDs", userID, []string{"4200001", "4200002", "4200002", "4200003"}).Return([]string{"4200003"}, nil)
+	mockImportRepo.On("CreateBatch", mock.Anything).Return(nil)
+
+	csv := "微信支付账单明细\n" +
+		"交易时间,交易类型,交易对方,商品,收/支,金额(元),支付方式,当前状态,交易单号,商户单号,备注\n" +
+		"2024-03-01 08:00:00,商户消费,早餐店,豆浆,支出,¥8.00,零钱,支付成功,4200001,/,/\n" +
+		"2024-03-02 08:00:00,商户消费,商城,耳机,支出,¥99.00,招商银行(1234),支付成功,4200002,/,/\n" +
+		"2024-03-02 08:00:00,商户消费,商城,耳机,支出,¥99.00,招商银行(1234),支付成功,4200002,/,/\n" +
+		"2024-03-03 08:00:00,商户消费,午餐店,套餐,支出,¥20.00,零钱,支付成功,4200003,/,/\n" +
+		"2024-03-04 08:00:00,商户消费,商城,键盘,支出,¥199.00,零钱,已全额退款,4200004,/,/\n"
+	resp, err := svc.Preview(userID, "微信支付账单.csv", strings.NewReader(csv), &request.PreviewImportRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "wechat", resp.Batch.Source)
+	assert.Equal(t, 5, resp.Batch.TotalRows)
+	assert.Equal(t, 2, resp.Batch.ValidRows)
+	assert.Equal(t, 3, resp.Batch.SkippedRows)
+	assert.Equal(t, int64(200), *resp.Rows[0].AccountID)
+	assert.Equal(t, int64(100), *resp.Rows[1].AccountID)
+	assert.Equal(t, "4200002", resp.Rows[1].ExternalID)
+	assert.True(t, resp.Rows[2].Skipped)
+	assert.Equal(t, "订单号已导入", resp.Rows[3].SkipReason)
+	assert.Equal(t, "已全额退款", resp.Rows[4].SkipReason)
+
+	// 未识别的格式且未提供列映射
+	_, err = svc.Preview(userID, "流水.csv", strings.NewReader("日期,金额\n2024-03-01,10\n"), &request.PreviewImportRequest{})
+	assert.Equal(t, errImportNoMapping, err)
+}
+
+// TestImportBankStatementPreview 测试银行对账单：必须指定账户、按账户内流水号去重并核对期末余额
+func TestImportBankStatementPreview(t *testing.T) {
+	svc, mockImportRepo, mockTransRepo, mockAccountRepo, mockCategoryRepo := newTestImportService()
+
+	userID := int64(1)
+	account := &models.Account{ID: 100, UserID: userID, AccountName: "招行卡", AccountType: "bank", Balance: money.MustParse("1000.00")}
+	mockAccountRepo.On("FindByID", int64(100)).Return(account, nil)
+	mockAccountRepo.On("FindByUserID", userID).Return([]*models.Account{account}, nil)
+	mockCategoryRepo.On("FindAll", userID).Return([]*models.Category{}, nil)
+	mockCategoryRepo.On("GetSystemCategories", "").Return([]*models.Category{}, nil)
+	mockImportRepo.On("CreateBatch", mock.Anything).Return(nil)
+
+	ofx := "<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>CNY\n" +
+		"<BANKTRANLIST>\n" +
+		"<STMTTRN><DTPOSTED>20240305<TRNAMT>-30.00<FITID>T001<NAME>超市</STMTTRN>\n" +
+		"<STMTTRN><DTPOSTED>20240306<TRNAMT>-50.00<FITID>T002<NAME>加油</STMTTRN>\n" +
+		"<STMTTRN><DTPOSTED>20240310<TRNAMT>200.00<FITID>T003<NAME>退款</STMTTRN>\n" +
+		"</BANKTRANLIST>\n" +
+		"<LEDGERBAL><BALAMT>1100.00<DTASOF>20240331</LEDGERBAL>\n" +
+		"</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n"
+
+	_, err := svc.Preview(userID, "statement.ofx", strings.NewReader(ofx), &request.PreviewImportRequest{})
+	assert.Equal(t, errImportNoAccount, err)
+
+	// T001 此前已导入该账户；期末余额日期之后另记了一笔 20.00 的支出
+	mockTransRepo.On("FindExistingExternalIDsByAccount", userID, int64(100), []string{"T001", "T002", "T003"}).Return([]string{"T001"}, nil)
+	mockTransRepo.On("GetAccountNetChangeAfter", int64(100), time.Date(2024, 3, 31, 0, 0, 0, 0, time.Local)).Return(money.MustParse("-20.00"), nil)
+
+	resp, err := svc.Preview(userID, "statement.ofx", strings.NewReader(ofx), &request.PreviewImportRequest{AccountID: &account.ID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, importer.FormatOFX, resp.Batch.FileFormat)
+	assert.Equal(t, int64(100), *resp.Batch.AccountID)
+	assert.Equal(t, 2, resp.Batch.ValidRows)
+	assert.Equal(t, 1, resp.Batch.SkippedRows)
+	assert.Equal(t, "流水号已导入", resp.Rows[0].SkipReason)
+	assert.Equal(t, int64(100), *resp.Rows[1].AccountID)
+	assert.Equal(t, "CNY", resp.Rows[1].Currency)
+	// 推算余额 = 1000 + (-50 + 200) - (-20) = 1170，期末余额 1100
+	assert.Equal(t, money.MustParse("1100.00"), *resp.Batch.ClosingBalance)
+	assert.Equal(t, money.MustParse("-70.00"), *resp.Batch.BalanceDifference)
+}
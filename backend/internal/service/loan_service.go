@@ -0,0 +1,490 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+const (
+	defaultLoanUpcomingDays = 30  // 预计交易默认查询天数
+	maxLoanUpcomingDays     = 366 // 预计交易最多查询天数
+)
+
+var (
+	errLoanNotFound       = errors.New("贷款不存在")
+	errInstallmentPosted  = errors.New("该期已入账")
+	errLoanPaidPeriods    = errors.New("已还期数需小于总期数")
+	errLoanScheduleAmount = errors.New("本金过小，无法按期数分摊")
+)
+
+// LoanService 贷款/分期服务接口
+type LoanService interface {
+	GetLoans(userID int64) ([]*response.LoanResponse, error)
+	GetLoan(userID int64, loanID int64) (*response.LoanDetailResponse, error)
+	// PreviewSchedule 试算还款计划，不保存
+	PreviewSchedule(req *request.LoanScheduleRequest) (*response.LoanScheduleResponse, error)
+	CreateLoan(userID int64, req *request.CreateLoanRequest) (*response.LoanDetailResponse, error)
+	UpdateLoan(userID int64, loanID int64, req *request.UpdateLoanRequest) (*response.LoanResponse, error)
+	DeleteLoan(userID int64, loanID int64) error
+	// GetUpcomingInstallments 未来days天内待入账的各期，作为预计交易返回
+	GetUpcomingInstallments(userID int64, days int) ([]*response.ProjectedTransactionResponse, error)
+	// ProcessDueInstallments 将截至now（含当天）到期的各期记为支出交易，返回入账期数
+	ProcessDueInstallments(now time.Time) (int, error)
+}
+
+type loanService struct {
+	loanRepo           repository.LoanRepository
+	accountRepo        repository.AccountRepository
+	categoryRepo       repository.CategoryRepository
+	transactionService TransactionService
+	running            sync.Mutex
+}
+
+// NewLoanService 创建贷款服务实例
+func NewLoanService(
+	loanRepo repository.LoanRepository,
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+	transactionService TransactionService,
+) LoanService {
+	return &loanService{
+		loanRepo:           loanRepo,
+		accountRepo:        accountRepo,
+		categoryRepo:       categoryRepo,
+		transactionService: transactionService,
+	}
+}
+
+// GetLoans 获取贷款列表
+func (s *loanService) GetLoans(userID int64) ([]*response.LoanResponse, error) {
+	loans, err := s.loanRepo.FindByUserID(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][贷款] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	result := make([]*response.LoanResponse, 0, len(loans))
+	for _, loan := range loans {
+		result = append(result, toLoanResponse(loan))
+	}
+	return result, nil
+}
+
+// GetLoan 获取贷款详情及完整还款计划
+func (s *loanService) GetLoan(userID int64, loanID int64) (*response.LoanDetailResponse, error) {
+	loan, err := s.findUserLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	return toLoanDetailResponse(loan), nil
+}
+
+// PreviewSchedule 试算还款计划
+func (s *loanService) PreviewSchedule(req *request.LoanScheduleRequest) (*response.LoanScheduleResponse, error) {
+	installments, err := buildLoanSchedule(req.Principal, req.AnnualRate, req.RepaymentMethod, req.Term, truncateToDate(req.FirstDueDate))
+	if err != nil {
+		return nil, err
+	}
+	return toLoanScheduleResponse(installments), nil
+}
+
+// CreateLoan 创建贷款并生成还款计划
+// 已还期数内的各期直接标记为已还（不生成交易）；首期还款日早于今天时，其余已到期的各期在下次调度时补记
+func (s *loanService) CreateLoan(userID int64, req *request.CreateLoanRequest) (*response.LoanDetailResponse, error) {
+	account, err := s.accountRepo.FindByID(req.AccountID)
+	if err != nil || account.UserID != userID || !account.IsActive {
+		return nil, errors.New("invalid account")
+	}
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.FindByID(*req.CategoryID)
+		if err != nil || (category.UserID != userID && category.UserID != 0) {
+			return nil, errors.New("invalid category")
+		}
+	}
+	if req.PaidPeriods < 0 || req.PaidPeriods >= req.Term {
+		return nil, errLoanPaidPeriods
+	}
+
+	firstDue := truncateToDate(req.FirstDueDate)
+	installments, err := buildLoanSchedule(req.Principal, req.AnnualRate, req.RepaymentMethod, req.Term, firstDue)
+	if err != nil {
+		return nil, err
+	}
+
+	loan := &models.Loan{
+		UserID:               userID,
+		Name:                 req.Name,
+		LoanType:             req.LoanType,
+		AccountID:            account.ID,
+		CategoryID:           req.CategoryID,
+		Principal:            req.Principal,
+		AnnualRate:           req.AnnualRate,
+		RepaymentMethod:      req.RepaymentMethod,
+		Term:                 req.Term,
+		Currency:             account.Currency,
+		FirstDueDate:         firstDue,
+		PaidPeriods:          req.PaidPeriods,
+		OutstandingPrincipal: req.Principal,
+		Status:               models.LoanStatusActive,
+		Notes:                req.Notes,
+		Installments:         installments,
+	}
+	if loan.LoanType == "" {
+		loan.LoanType = models.LoanTypeInstallment
+	}
+	if loan.Currency == "" {
+		loan.Currency = defaultCurrency
+	}
+	for _, installment := range installments[:req.PaidPeriods] {
+		installment.Status = models.InstallmentPaid
+		loan.OutstandingPrincipal = installment.RemainingPrincipal
+	}
+	nextDue := installments[req.PaidPeriods].DueDate
+	loan.NextDueDate = &nextDue
+
+	if err := s.loanRepo.Create(loan); err != nil {
+		logger.Error(fmt.Sprintf("[Service][贷款] 创建失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, errors.New("创建贷款失败")
+	}
+
+	logger.Info(fmt.Sprintf("[Service][贷款] 创建成功 | 用户ID: %d | 贷款ID: %d | 本金: %s | 期数: %d", userID, loan.ID, loan.Principal, loan.Term))
+	return toLoanDetailResponse(loan), nil
+}
+
+// UpdateLoan 更新贷款名称、分类和备注，分类变更只影响之后入账的各期
+func (s *loanService) UpdateLoan(userID int64, loanID int64, req *request.UpdateLoanRequest) (*response.LoanResponse, error) {
+	loan, err := s.findUserLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		loan.Name = *req.Name
+	}
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.FindByID(*req.CategoryID)
+		if err != nil || (category.UserID != userID && category.UserID != 0) {
+			return nil, errors.New("invalid category")
+		}
+		loan.CategoryID = req.CategoryID
+	}
+	if req.Notes != nil {
+		loan.Notes = *req.Notes
+	}
+
+	if err := s.loanRepo.Update(loan); err != nil {
+		logger.Error(fmt.Sprintf("[Service][贷款] 更新失败 | 用户ID: %d | 贷款ID: %d | 错误: %v", userID, loanID, err))
+		return nil, errors.New("更新贷款失败")
+	}
+	return toLoanResponse(loan), nil
+}
+
+// DeleteLoan 删除贷款及还款计划，已入账的交易保留
+func (s *loanService) DeleteLoan(userID int64, loanID int64) error {
+	if _, err := s.findUserLoan(userID, loanID); err != nil {
+		return err
+	}
+	return s.loanRepo.Delete(loanID)
+}
+
+// GetUpcomingInstallments 获取未来days天内待入账的各期
+func (s *loanService) GetUpcomingInstallments(userID int64, days int) ([]*response.ProjectedTransactionResponse, error) {
+	if days <= 0 {
+		days = defaultLoanUpcomingDays
+	}
+	if days > maxLoanUpcomingDays {
+		days = maxLoanUpcomingDays
+	}
+
+	installments, err := s.loanRepo.FindPendingInstallments(userID, truncateToDate(time.Now()).AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+	loans, err := s.loanRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	loansByID := make(map[int64]*models.Loan, len(loans))
+	for _, loan := range loans {
+		loansByID[loan.ID] = loan
+	}
+
+	result := make([]*response.ProjectedTransactionResponse, 0, len(installments))
+	for _, installment := range installments {
+		loan, ok := loansByID[installment.LoanID]
+		if !ok {
+			continue
+		}
+		result = append(result, &response.ProjectedTransactionResponse{
+			LoanID:          loan.ID,
+			LoanName:        loan.Name,
+			Period:          installment.Period,
+			Term:            loan.Term,
+			Type:            string(models.TransactionTypeExpense),
+			AccountID:       loan.AccountID,
+			CategoryID:      loan.CategoryID,
+			Title:           installmentTitle(loan, installment.Period),
+			Amount:          installment.Amount,
+			Principal:       installment.Principal,
+			Interest:        installment.Interest,
+			Currency:        loan.Currency,
+			TransactionDate: installment.DueDate,
+		})
+	}
+	return result, nil
+}
+
+// ProcessDueInstallments 逐期入账所有到期的还款
+// 停机期间错过的期数按顺序补齐；多实例并发时依靠贷款行锁和已还期数校验保证不重复入账
+func (s *loanService) ProcessDueInstallments(now time.Time) (int, error) {
+	if !s.running.TryLock() {
+		logger.Info("[Service][贷款] 上一轮调度仍在执行，跳过")
+		return 0, nil
+	}
+	defer s.running.Unlock()
+
+	today := truncateToDate(now)
+	loans, err := s.loanRepo.FindDue(today)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][贷款] 查询到期贷款失败 | 错误: %v", err))
+		return 0, err
+	}
+
+	posted := 0
+	for _, loan := range loans {
+		n, err := s.processLoan(loan, today)
+		posted += n
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][贷款] 还款入账失败 | 用户ID: %d | 贷款ID: %d | 错误: %v", loan.UserID, loan.ID, err))
+		}
+	}
+
+	if posted > 0 {
+		logger.Info(fmt.Sprintf("[Service][贷款] 调度完成 | 到期贷款: %d | 入账期数: %d", len(loans), posted))
+	}
+	return posted, nil
+}
+
+// processLoan 逐期入账单笔贷款截至today的还款
+func (s *loanService) processLoan(loan *models.Loan, today time.Time) (int, error) {
+	posted := 0
+	for posted < loan.Term {
+		if loan.Status != models.LoanStatusActive || loan.NextDueDate == nil || loan.NextDueDate.After(today) {
+			return posted, nil
+		}
+		period := loan.PaidPeriods + 1
+		installment, err := s.loanRepo.FindInstallment(loan.ID, period)
+		if err != nil {
+			return posted, err
+		}
+
+		req := &request.CreateTransactionRequest{
+			Type:            string(models.TransactionTypeExpense),
+			CategoryID:      loan.CategoryID,
+			AccountID:       &loan.AccountID,
+			Amount:          installment.Amount,
+			Currency:        loan.Currency,
+			Title:           installmentTitle(loan, period),
+			Description:     fmt.Sprintf("本金 %s，利息 %s", installment.Principal, installment.Interest),
+			TransactionDate: installment.DueDate,
+			AutoPosted:      true,
+		}
+
+		var advanced *models.Loan
+		_, err = s.transactionService.CreateLinkedTransaction(loan.UserID, req, func(repos *repository.TxRepositories, t *models.Transaction) error {
+			locked, err := repos.Loans.FindByIDForUpdate(loan.ID)
+			if err != nil {
+				return err
+			}
+			if locked.Status != models.LoanStatusActive || locked.PaidPeriods+1 != period {
+				return errInstallmentPosted
+			}
+
+			paidAt := time.Now()
+			installment.Status = models.InstallmentPaid
+			installment.TransactionID = &t.ID
+			installment.PaidAt = &paidAt
+			if err := repos.Loans.UpdateInstallment(installment); err != nil {
+				return err
+			}
+
+			advanceLoan(locked, installment)
+			advanced = locked
+			return repos.Loans.Update(locked)
+		})
+		if err != nil {
+			// 其他实例已入账该期时重新加载贷款继续
+			if !errors.Is(err, errInstallmentPosted) {
+				return posted, err
+			}
+			reloaded, findErr := s.loanRepo.FindByID(loan.ID)
+			if findErr != nil || reloaded.PaidPeriods < period {
+				return posted, err
+			}
+			loan = reloaded
+			continue
+		}
+
+		loan = advanced
+		posted++
+	}
+	return posted, nil
+}
+
+// findUserLoan 查找并校验贷款归属
+func (s *loanService) findUserLoan(userID int64, loanID int64) (*models.Loan, error) {
+	loan, err := s.loanRepo.FindByID(loanID)
+	if err != nil || loan.UserID != userID {
+		return nil, errLoanNotFound
+	}
+	return loan, nil
+}
+
+// advanceLoan 记录一期已还：更新剩余本金，并推进到下一期或结清
+func advanceLoan(loan *models.Loan, installment *models.LoanInstallment) {
+	loan.PaidPeriods = installment.Period
+	loan.OutstandingPrincipal = installment.RemainingPrincipal
+	if loan.PaidPeriods >= loan.Term {
+		loan.NextDueDate = nil
+		loan.Status = models.LoanStatusCompleted
+		return
+	}
+	next := installmentDueDate(loan.FirstDueDate, loan.PaidPeriods+1)
+	loan.NextDueDate = &next
+}
+
+// buildLoanSchedule 按还款方式生成还款计划，利息按月利率（年利率/12）和期初剩余本金计算，
+// 各期金额四舍五入到分，尾差计入最后一期
+func buildLoanSchedule(principal money.Money, annualRate float64, method string, term int, firstDue time.Time) ([]*models.LoanInstallment, error) {
+	if term <= 0 || principal < money.Money(term) {
+		return nil, errLoanScheduleAmount
+	}
+	monthlyRate, ok := new(big.Rat).SetString(strconv.FormatFloat(annualRate, 'f', -1, 64))
+	if !ok || monthlyRate.Sign() < 0 {
+		return nil, errors.New("年利率无效")
+	}
+	monthlyRate.Quo(monthlyRate, big.NewRat(1200, 1))
+
+	// 等额本息每期还款额 = P × r × (1+r)^n / ((1+r)^n - 1)，免息时退化为本金平均分摊
+	var payment money.Money
+	if method == models.RepaymentEqualInstallment && monthlyRate.Sign() > 0 {
+		growth := new(big.Rat).Add(big.NewRat(1, 1), monthlyRate)
+		factor := big.NewRat(1, 1)
+		for i := 0; i < term; i++ {
+			factor.Mul(factor, growth)
+		}
+		ratio := new(big.Rat).Mul(monthlyRate, factor)
+		ratio.Quo(ratio, new(big.Rat).Sub(factor, big.NewRat(1, 1)))
+		var err error
+		if payment, err = principal.MulRat(ratio); err != nil {
+			return nil, err
+		}
+	}
+	evenPrincipal := money.Money(int64(principal) / int64(term))
+
+	installments := make([]*models.LoanInstallment, 0, term)
+	remaining := principal
+	for period := 1; period <= term; period++ {
+		interest, err := remaining.MulRat(monthlyRate)
+		if err != nil {
+			return nil, err
+		}
+		periodPrincipal := evenPrincipal
+		if payment > 0 {
+			periodPrincipal = payment - interest
+		}
+		if period == term || periodPrincipal > remaining {
+			periodPrincipal = remaining
+		}
+		remaining -= periodPrincipal
+
+		installments = append(installments, &models.LoanInstallment{
+			Period:             period,
+			DueDate:            installmentDueDate(firstDue, period),
+			Principal:          periodPrincipal,
+			Interest:           interest,
+			Amount:             periodPrincipal + interest,
+			RemainingPrincipal: remaining,
+			Status:             models.InstallmentPending,
+		})
+	}
+	return installments, nil
+}
+
+// installmentDueDate 第period期的还款日：首期还款日之后每月同一天，超出当月天数取月末
+func installmentDueDate(firstDue time.Time, period int) time.Time {
+	return addMonthsClamped(firstDue, period-1, firstDue.Day())
+}
+
+func installmentTitle(loan *models.Loan, period int) string {
+	return fmt.Sprintf("%s 第%d/%d期", loan.Name, period, loan.Term)
+}
+
+// toLoanResponse 转换为响应对象
+func toLoanResponse(loan *models.Loan) *response.LoanResponse {
+	resp := &response.LoanResponse{
+		ID:                   loan.ID,
+		Name:                 loan.Name,
+		LoanType:             loan.LoanType,
+		AccountID:            loan.AccountID,
+		CategoryID:           loan.CategoryID,
+		Principal:            loan.Principal,
+		AnnualRate:           loan.AnnualRate,
+		RepaymentMethod:      loan.RepaymentMethod,
+		Term:                 loan.Term,
+		Currency:             loan.Currency,
+		FirstDueDate:         loan.FirstDueDate,
+		PaidPeriods:          loan.PaidPeriods,
+		OutstandingPrincipal: loan.OutstandingPrincipal,
+		NextDueDate:          loan.NextDueDate,
+		Status:               loan.Status,
+		Notes:                loan.Notes,
+		CreatedAt:            loan.CreatedAt,
+		UpdatedAt:            loan.UpdatedAt,
+	}
+	if loan.NextDueDate != nil && loan.PaidPeriods < len(loan.Installments) {
+		next := loan.Installments[loan.PaidPeriods].Amount
+		resp.NextAmount = &next
+	}
+	return resp
+}
+
+func toLoanDetailResponse(loan *models.Loan) *response.LoanDetailResponse {
+	return &response.LoanDetailResponse{
+		LoanResponse: toLoanResponse(loan),
+		Schedule:     toLoanScheduleResponse(loan.Installments),
+	}
+}
+
+func toLoanScheduleResponse(installments []*models.LoanInstallment) *response.LoanScheduleResponse {
+	resp := &response.LoanScheduleResponse{
+		Installments: make([]*response.LoanInstallmentResponse, 0, len(installments)),
+	}
+	for _, installment := range installments {
+		resp.TotalAmount += installment.Amount
+		resp.TotalInterest += installment.Interest
+		resp.Installments = append(resp.Installments, &response.LoanInstallmentResponse{
+			Period:             installment.Period,
+			DueDate:            installment.DueDate,
+			Principal:          installment.Principal,
+			Interest:           installment.Interest,
+			Amount:             installment.Amount,
+			RemainingPrincipal: installment.RemainingPrincipal,
+			Status:             installment.Status,
+			TransactionID:      installment.TransactionID,
+			PaidAt:             installment.PaidAt,
+		})
+	}
+	return resp
+}
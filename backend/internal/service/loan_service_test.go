@@ -0,0 +1,208 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLoanRepository Mock LoanRepository
+type MockLoanRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoanRepository) Create(loan *models.Loan) error {
+	args := m.Called(loan)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) FindByID(id int64) (*models.Loan, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) FindByIDForUpdate(id int64) (*models.Loan, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) FindByUserID(userID int64) ([]*models.Loan, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]*models.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) FindDue(date time.Time) ([]*models.Loan, error) {
+	args := m.Called(date)
+	return args.Get(0).([]*models.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) FindInstallment(loanID int64, period int) (*models.LoanInstallment, error) {
+	args := m.Called(loanID, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoanInstallment), args.Error(1)
+}
+
+func (m *MockLoanRepository) FindPendingInstallments(userID int64, until time.Time) ([]*models.LoanInstallment, error) {
+	args := m.Called(userID, until)
+	return args.Get(0).([]*models.LoanInstallment), args.Error(1)
+}
+
+func (m *MockLoanRepository) Update(loan *models.Loan) error {
+	args := m.Called(loan)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) UpdateInstallment(installment *models.LoanInstallment) error {
+	args := m.Called(installment)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// TestBuildLoanScheduleEqualInstallment 测试等额本息：每期金额相同，尾差计入最后一期
+func TestBuildLoanScheduleEqualInstallment(t *testing.T) {
+	installments, err := buildLoanSchedule(money.MustParse("12000.00"), 12, models.RepaymentEqualInstallment, 12, date(2024, 1, 31))
+	assert.NoError(t, err)
+	assert.Len(t, installments, 12)
+
+	first := installments[0]
+	assert.Equal(t, money.MustParse("1066.19"), first.Amount)
+	assert.Equal(t, money.MustParse("120.00"), first.Interest)
+	assert.Equal(t, money.MustParse("946.19"), first.Principal)
+	assert.Equal(t, date(2024, 2, 29), installments[1].DueDate)
+	assert.Equal(t, date(2024, 3, 31), installments[2].DueDate)
+
+	last := installments[11]
+	assert.Equal(t, money.MustParse("1066.14"), last.Amount)
+	assert.Equal(t, money.Money(0), last.RemainingPrincipal)
+
+	schedule := toLoanScheduleResponse(installments)
+	assert.Equal(t, money.MustParse("794.23"), schedule.TotalInterest)
+	assert.Equal(t, money.MustParse("12794.23"), schedule.TotalAmount)
+}
+
+// TestBuildLoanScheduleEqualPrincipal 测试等额本金与免息分期，利息溢出时报错
+func TestBuildLoanScheduleEqualPrincipal(t *testing.T) {
+	installments, err := buildLoanSchedule(money.MustParse("1000.00"), 12, models.RepaymentEqualPrincipal, 3, date(2024, 1, 15))
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("333.33"), installments[0].Principal)
+	assert.Equal(t, money.MustParse("10.00"), installments[0].Interest)
+	assert.Equal(t, money.MustParse("6.67"), installments[1].Interest)
+	assert.Equal(t, money.MustParse("333.34"), installments[2].Principal)
+	assert.Equal(t, money.MustParse("3.33"), installments[2].Interest)
+
+	// 免息分期按等额本息计算时退化为本金平均分摊
+	free, err := buildLoanSchedule(money.MustParse("6000.00"), 0, models.RepaymentEqualInstallment, 6, date(2024, 1, 15))
+	assert.NoError(t, err)
+	for _, installment := range free {
+		assert.Equal(t, money.MustParse("1000.00"), installment.Amount)
+		assert.Equal(t, money.Money(0), installment.Interest)
+	}
+
+	_, err = buildLoanSchedule(money.MustParse("0.05"), 0, models.RepaymentEqualPrincipal, 12, date(2024, 1, 15))
+	assert.Equal(t, errLoanScheduleAmount, err)
+
+	// 利息超出金额范围时返回错误，而不是按零利息生成计划
+	_, err = buildLoanSchedule(money.FromCents(1<<60), 12000, models.RepaymentEqualPrincipal, 2, date(2024, 1, 15))
+	assert.Error(t, err)
+}
+
+// TestCreateLoanWithPaidPeriods 测试录入进行中的分期：已还期数标记为已还，下期还款日与剩余本金随之推进
+func TestCreateLoanWithPaidPeriods(t *testing.T) {
+	mockLoanRepo := new(MockLoanRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	service := NewLoanService(mockLoanRepo, mockAccountRepo, new(MockCategoryRepository), nil)
+
+	mockAccountRepo.On("FindByID", int64(9)).Return(&models.Account{ID: 9, UserID: 1, AccountType: models.AccountTypeCredit, Currency: "CNY", IsActive: true}, nil)
+	mockLoanRepo.On("Create", mock.Anything).Return(nil)
+
+	req := &request.CreateLoanRequest{
+		LoanScheduleRequest: request.LoanScheduleRequest{
+			Principal:       money.MustParse("6000.00"),
+			RepaymentMethod: models.RepaymentEqualInstallment,
+			Term:            6,
+			FirstDueDate:    date(2024, 1, 10),
+		},
+		Name:        "手机分期",
+		AccountID:   9,
+		PaidPeriods: 2,
+	}
+	resp, err := service.CreateLoan(1, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.LoanTypeInstallment, resp.LoanType)
+	assert.Equal(t, money.MustParse("4000.00"), resp.OutstandingPrincipal)
+	assert.Equal(t, date(2024, 3, 10), *resp.NextDueDate)
+	assert.Equal(t, money.MustParse("1000.00"), *resp.NextAmount)
+	assert.Equal(t, models.InstallmentPaid, resp.Schedule.Installments[1].Status)
+	assert.Equal(t, models.InstallmentPending, resp.Schedule.Installments[2].Status)
+
+	req.PaidPeriods = 6
+	_, err = service.CreateLoan(1, req)
+	assert.Equal(t, errLoanPaidPeriods, err)
+}
+
+// TestProcessDueInstallments 测试到期各期依次记为支出，最后一期入账后贷款结清
+func TestProcessDueInstallments(t *testing.T) {
+	mockLoanRepo := new(MockLoanRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo, loanRepo: mockLoanRepo}
+	transService := newTestTransactionService(uow, nil)
+	service := NewLoanService(mockLoanRepo, mockAccountRepo, new(MockCategoryRepository), transService)
+
+	installments, _ := buildLoanSchedule(money.MustParse("2000.00"), 0, models.RepaymentEqualPrincipal, 2, date(2024, 1, 10))
+	nextDue := date(2024, 1, 10)
+	loan := &models.Loan{ID: 3, UserID: 1, Name: "耳机分期", AccountID: 9, Term: 2, Currency: "CNY",
+		Principal: money.MustParse("2000.00"), OutstandingPrincipal: money.MustParse("2000.00"),
+		FirstDueDate: date(2024, 1, 10), NextDueDate: &nextDue, Status: models.LoanStatusActive}
+	locked := *loan
+
+	mockLoanRepo.On("FindDue", date(2024, 3, 1)).Return([]*models.Loan{loan}, nil)
+	mockLoanRepo.On("FindInstallment", int64(3), 1).Return(installments[0], nil)
+	mockLoanRepo.On("FindInstallment", int64(3), 2).Return(installments[1], nil)
+	mockLoanRepo.On("FindByIDForUpdate", int64(3)).Return(&locked, nil)
+	mockLoanRepo.On("UpdateInstallment", mock.Anything).Return(nil)
+	mockLoanRepo.On("Update", &locked).Return(nil)
+
+	account := &models.Account{ID: 9, UserID: 1, Currency: "CNY", Balance: money.MustParse("-100.00")}
+	mockAccountRepo.On("FindByID", int64(9)).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", int64(9)).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+
+	var titles []string
+	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		tr := args.Get(0).(*models.Transaction)
+		tr.ID = int64(100 + len(titles))
+		titles = append(titles, tr.Title)
+	}).Return(nil)
+	mockTransRepo.On("FindByID", mock.Anything).Return(&models.Transaction{ID: 100, UserID: 1, Type: "expense"}, nil)
+
+	posted, err := service.ProcessDueInstallments(time.Date(2024, 3, 1, 0, 15, 0, 0, time.Local))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, posted)
+	assert.Equal(t, []string{"耳机分期 第1/2期", "耳机分期 第2/2期"}, titles)
+	assert.Equal(t, money.MustParse("-2100.00"), account.Balance)
+	assert.Equal(t, models.LoanStatusCompleted, locked.Status)
+	assert.Nil(t, locked.NextDueDate)
+	assert.Equal(t, money.Money(0), locked.OutstandingPrincipal)
+	assert.Equal(t, models.InstallmentPaid, installments[1].Status)
+	assert.Equal(t, int64(101), *installments[1].TransactionID)
+}
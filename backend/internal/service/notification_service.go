@@ -0,0 +1,351 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/cache"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/spf13/viper"
+)
+
+const (
+	unreadCountKeyPrefix      = "notification:unread:"
+	defaultUnreadCacheTTL     = 10 * time.Minute
+	defaultLargeExpenseAmount = "1000"
+)
+
+var errNotificationNotFound = errors.New("通知不存在")
+
+// NotificationService 通知服务接口
+// 除面向用户的查询与已读管理外，其他服务通过 Notify* 方法发布通知
+type NotificationService interface {
+	GetNotifications(userID int64, req *request.ListNotificationRequest) (*response.NotificationListResponse, error)
+	GetUnreadCount(userID int64) (int64, error)
+	MarkRead(userID int64, notificationID int64) error
+	MarkAllRead(userID int64) (int64, error)
+	DeleteNotification(userID int64, notificationID int64) error
+
+	Publish(notification *models.Notification) error
+	NotifyBillDue(bill *models.Bill) error
+	NotifyCreditDue(account *models.Account, statement *models.CreditStatement) error
+	NotifyCreditUtilization(account *models.Account, percent int, since time.Time) error
+	NotifyBudgetThreshold(userID int64, budgetID int64, budgetName string, percent int, spent, available money.Money) error
+	NotifyLargeExpense(transaction *models.Transaction) error
+	NotifyAppUpdate(update *models.AppUpdate) error
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+// NewNotificationService 创建通知服务实例
+func NewNotificationService(notificationRepo repository.NotificationRepository) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// GetNotifications 分页获取通知列表
+func (s *notificationService) GetNotifications(userID int64, req *request.ListNotificationRequest) (*response.NotificationListResponse, error) {
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	notifications, total, err := s.notificationRepo.FindByUserID(userID, req.UnreadOnly, page, pageSize)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][通知] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	unread, err := s.GetUnreadCount(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*response.NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		items = append(items, toNotificationResponse(n))
+	}
+	return &response.NotificationListResponse{
+		Total:       total,
+		UnreadCount: unread,
+		Page:        page,
+		PageSize:    pageSize,
+		Items:       items,
+	}, nil
+}
+
+// GetUnreadCount 获取未读数量，优先读取Redis缓存，Redis不可用时回退数据库
+func (s *notificationService) GetUnreadCount(userID int64) (int64, error) {
+	key := unreadCountKey(userID)
+	if cached, err := cache.Get(key); err == nil {
+		if count, err := strconv.ParseInt(cached, 10, 64); err == nil {
+			return count, nil
+		}
+	}
+
+	count, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][通知] 统计未读失败 | 用户ID: %d | 错误: %v", userID, err))
+		return 0, err
+	}
+
+	if err := cache.Set(key, count, unreadCacheTTL()); err != nil && !errors.Is(err, cache.ErrNotInitialized) {
+		logger.Warn(fmt.Sprintf("[Service][通知] 写入未读缓存失败 | 用户ID: %d | 错误: %v", userID, err))
+	}
+	return count, nil
+}
+
+// MarkRead 标记单条通知已读
+func (s *notificationService) MarkRead(userID int64, notificationID int64) error {
+	affected, err := s.notificationRepo.MarkRead(userID, notificationID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][通知] 标记已读失败 | 用户ID: %d | 通知ID: %d | 错误: %v", userID, notificationID, err))
+		return err
+	}
+	if affected > 0 {
+		s.invalidateUnreadCount(userID)
+	}
+	return nil
+}
+
+// MarkAllRead 标记全部通知已读，返回标记数量
+func (s *notificationService) MarkAllRead(userID int64) (int64, error) {
+	affected, err := s.notificationRepo.MarkAllRead(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][通知] 全部已读失败 | 用户ID: %d | 错误: %v", userID, err))
+		return 0, err
+	}
+	s.invalidateUnreadCount(userID)
+
+	logger.Info(fmt.Sprintf("[Service][通知] 全部已读 | 用户ID: %d | 数量: %d", userID, affected))
+	return affected, nil
+}
+
+// DeleteNotification 删除通知
+func (s *notificationService) DeleteNotification(userID int64, notificationID int64) error {
+	affected, err := s.notificationRepo.Delete(userID, notificationID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][通知] 删除失败 | 用户ID: %d | 通知ID: %d | 错误: %v", userID, notificationID, err))
+		return err
+	}
+	if affected == 0 {
+		return errNotificationNotFound
+	}
+	s.invalidateUnreadCount(userID)
+	return nil
+}
+
+// Publish 发布一条通知
+func (s *notificationService) Publish(notification *models.Notification) error {
+	if notification.Priority == "" {
+		notification.Priority = models.NotificationPriorityMedium
+	}
+	now := time.Now()
+	if notification.ScheduledAt == nil || !notification.ScheduledAt.After(now) {
+		notification.SentAt = &now
+	}
+
+	if err := s.notificationRepo.Create(notification); err != nil {
+		logger.Error(fmt.Sprintf("[Service][通知] 发布失败 | 用户ID: %d | 类型: %s | 错误: %v", notification.UserID, notification.Type, err))
+		return err
+	}
+	s.invalidateUnreadCount(notification.UserID)
+
+	logger.Info(fmt.Sprintf("[Service][通知] 发布成功 | 用户ID: %d | 类型: %s | 通知ID: %d", notification.UserID, notification.Type, notification.ID))
+	return nil
+}
+
+// NotifyBillDue 账单到期提醒，同一账单同一期只提醒一次
+func (s *notificationService) NotifyBillDue(bill *models.Bill) error {
+	windowStart := bill.NextBillingDate.AddDate(0, 0, -bill.RemindDaysBefore)
+	exists, err := s.notificationRepo.ExistsSince(bill.UserID, models.NotificationTypeBillReminder, "bill", bill.ID, windowStart)
+	if err != nil || exists {
+		return err
+	}
+
+	return s.Publish(&models.Notification{
+		UserID:      bill.UserID,
+		Type:        models.NotificationTypeBillReminder,
+		Title:       fmt.Sprintf("账单提醒：%s", bill.BillName),
+		Content:     fmt.Sprintf("您的%s将在%s扣费 %s %s", bill.BillName, bill.NextBillingDate.Format("2006-01-02"), bill.Amount, bill.Currency),
+		RelatedType: "bill",
+		RelatedID:   &bill.ID,
+		Priority:    models.NotificationPriorityMedium,
+	})
+}
+
+// NotifyCreditDue 信用账单还款提醒，同一期账单只提醒一次
+func (s *notificationService) NotifyCreditDue(account *models.Account, statement *models.CreditStatement) error {
+	exists, err := s.notificationRepo.ExistsSince(statement.UserID, models.NotificationTypeBillReminder, "credit_statement", statement.ID, statement.StatementDate)
+	if err != nil || exists {
+		return err
+	}
+
+	return s.Publish(&models.Notification{
+		UserID: statement.UserID,
+		Type:   models.NotificationTypeBillReminder,
+		Title:  fmt.Sprintf("还款提醒：%s", account.AccountName),
+		Content: fmt.Sprintf("%s账单应还 %s %s，尚需还款 %s，最低还款 %s，请于%s前还款",
+			statement.StatementDate.Format("01月02日"), statement.StatementAmount, statement.Currency,
+			statement.Remaining(), statement.MinimumPayment, statement.DueDate.Format("2006-01-02")),
+		RelatedType: "credit_statement",
+		RelatedID:   &statement.ID,
+		Priority:    models.NotificationPriorityHigh,
+	})
+}
+
+// NotifyCreditUtilization 信用额度使用率预警，since之后已预警过时不再重复发送
+func (s *notificationService) NotifyCreditUtilization(account *models.Account, percent int, since time.Time) error {
+	exists, err := s.notificationRepo.ExistsSince(account.UserID, models.NotificationTypeSystem, "credit_account", account.ID, since)
+	if err != nil || exists {
+		return err
+	}
+
+	priority := models.NotificationPriorityMedium
+	if percent >= 100 {
+		priority = models.NotificationPriorityHigh
+	}
+	return s.Publish(&models.Notification{
+		UserID:      account.UserID,
+		Type:        models.NotificationTypeSystem,
+		Title:       fmt.Sprintf("额度预警：%s 已使用%d%%", account.AccountName, percent),
+		Content:     fmt.Sprintf("当前欠款 %s %s，可用额度 %s", creditDebt(account), account.Currency, availableCredit(account)),
+		RelatedType: "credit_account",
+		RelatedID:   &account.ID,
+		Priority:    priority,
+	})
+}
+
+// NotifyBudgetThreshold 预算预警，percent为达到的预警线（如80、100），去重由预算服务按周期控制
+func (s *notificationService) NotifyBudgetThreshold(userID int64, budgetID int64, budgetName string, percent int, spent, available money.Money) error {
+	title := fmt.Sprintf("预算预警：%s 已使用%d%%", budgetName, percent)
+	priority := models.NotificationPriorityMedium
+	if percent >= 100 {
+		title = fmt.Sprintf("预算超支：%s", budgetName)
+		priority = models.NotificationPriorityHigh
+	}
+
+	return s.Publish(&models.Notification{
+		UserID:      userID,
+		Type:        models.NotificationTypeBudgetAlert,
+		Title:       title,
+		Content:     fmt.Sprintf("本期已支出 %s，可用预算 %s", spent, available),
+		RelatedType: "budget",
+		RelatedID:   &budgetID,
+		Priority:    priority,
+	})
+}
+
+// NotifyLargeExpense 大额支出提醒，阈值由 notification.large_expense_threshold 配置
+func (s *notificationService) NotifyLargeExpense(transaction *models.Transaction) error {
+	if transaction.Type != string(models.TransactionTypeExpense) {
+		return nil
+	}
+	threshold := largeExpenseThreshold()
+	if threshold <= 0 || transaction.Amount < threshold {
+		return nil
+	}
+
+	title := transaction.Title
+	if title == "" {
+		title = "未命名支出"
+	}
+	return s.Publish(&models.Notification{
+		UserID:      transaction.UserID,
+		Type:        models.NotificationTypeLargeExpense,
+		Title:       fmt.Sprintf("大额支出：%s", transaction.Amount),
+		Content:     fmt.Sprintf("%s 记录了一笔 %s %s 的支出「%s」", transaction.TransactionDate.Format("2006-01-02"), transaction.Amount, transaction.Currency, title),
+		RelatedType: "transaction",
+		RelatedID:   &transaction.ID,
+		Priority:    models.NotificationPriorityMedium,
+	})
+}
+
+// NotifyAppUpdate 新版本发布通知，向所有用户广播
+func (s *notificationService) NotifyAppUpdate(update *models.AppUpdate) error {
+	now := time.Now()
+	priority := models.NotificationPriorityLow
+	if update.IsForceUpdate {
+		priority = models.NotificationPriorityHigh
+	}
+
+	affected, err := s.notificationRepo.CreateForAllUsers(&models.Notification{
+		Type:        models.NotificationTypeAppUpdate,
+		Title:       fmt.Sprintf("新版本 %s 已发布", update.VersionName),
+		Content:     update.Title,
+		RelatedType: "app_update",
+		RelatedID:   &update.ID,
+		Priority:    priority,
+		SentAt:      &now,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][通知] 版本通知广播失败 | 版本: %s | 错误: %v", update.VersionName, err))
+		return err
+	}
+
+	if err := cache.DelPattern(unreadCountKeyPrefix + "*"); err != nil && !errors.Is(err, cache.ErrNotInitialized) {
+		logger.Warn(fmt.Sprintf("[Service][通知] 清理未读缓存失败 | 错误: %v", err))
+	}
+	logger.Info(fmt.Sprintf("[Service][通知] 版本通知广播完成 | 版本: %s | 用户数: %d", update.VersionName, affected))
+	return nil
+}
+
+// invalidateUnreadCount 清除未读数缓存，下次读取时从数据库重建
+func (s *notificationService) invalidateUnreadCount(userID int64) {
+	if err := cache.Del(unreadCountKey(userID)); err != nil && !errors.Is(err, cache.ErrNotInitialized) {
+		logger.Warn(fmt.Sprintf("[Service][通知] 清理未读缓存失败 | 用户ID: %d | 错误: %v", userID, err))
+	}
+}
+
+func unreadCountKey(userID int64) string {
+	return fmt.Sprintf("%s%d", unreadCountKeyPrefix, userID)
+}
+
+func unreadCacheTTL() time.Duration {
+	if seconds := viper.GetInt("notification.unread_cache_ttl"); seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultUnreadCacheTTL
+}
+
+func largeExpenseThreshold() money.Money {
+	value := defaultLargeExpenseAmount
+	if viper.IsSet("notification.large_expense_threshold") {
+		value = viper.GetString("notification.large_expense_threshold")
+	}
+	threshold, err := money.Parse(value)
+	if err != nil {
+		return 0
+	}
+	return threshold
+}
+
+// toNotificationResponse 转换为响应对象
+func toNotificationResponse(n *models.Notification) *response.NotificationResponse {
+	return &response.NotificationResponse{
+		ID:          n.ID,
+		Type:        n.Type,
+		Title:       n.Title,
+		Content:     n.Content,
+		RelatedType: n.RelatedType,
+		RelatedID:   n.RelatedID,
+		IsRead:      n.IsRead,
+		Priority:    n.Priority,
+		ReadAt:      n.ReadAt,
+		CreatedAt:   n.CreatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationRepository Mock NotificationRepository
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(notification *models.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) CreateForAllUsers(notification *models.Notification) (int64, error) {
+	args := m.Called(notification)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) FindByUserID(userID int64, unreadOnly bool, page, pageSize int) ([]*models.Notification, int64, error) {
+	args := m.Called(userID, unreadOnly, page, pageSize)
+	return args.Get(0).([]*models.Notification), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockNotificationRepository) ExistsSince(userID int64, notificationType, relatedType string, relatedID int64, since time.Time) (bool, error) {
+	args := m.Called(userID, notificationType, relatedType, relatedID, since)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkRead(userID, id int64) (int64, error) {
+	args := m.Called(userID, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkAllRead(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) Delete(userID, id int64) (int64, error) {
+	args := m.Called(userID, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) CountUnread(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// TestNotifyLargeExpense 测试仅达到阈值的支出才发送大额通知
+func TestNotifyLargeExpense(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	svc := NewNotificationService(mockRepo)
+
+	mockRepo.On("Create", mock.MatchedBy(func(n *models.Notification) bool {
+		return n.Type == models.NotificationTypeLargeExpense && n.UserID == 1 && n.SentAt != nil
+	})).Return(nil).Once()
+
+	assert.NoError(t, svc.NotifyLargeExpense(&models.Transaction{ID: 1, UserID: 1, Type: "expense", Amount: money.MustParse("999.99")}))
+	assert.NoError(t, svc.NotifyLargeExpense(&models.Transaction{ID: 2, UserID: 1, Type: "income", Amount: money.MustParse("5000.00")}))
+	assert.NoError(t, svc.NotifyLargeExpense(&models.Transaction{ID: 3, UserID: 1, Type: "expense", Amount: money.MustParse("1000.00")}))
+
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+// TestNotifyBillDueOncePerCycle 测试同一期账单只提醒一次
+func TestNotifyBillDueOncePerCycle(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	svc := NewNotificationService(mockRepo)
+
+	bill := &models.Bill{ID: 7, UserID: 1, BillName: "房租", NextBillingDate: date(2024, 3, 10), RemindDaysBefore: 3}
+	windowStart := date(2024, 3, 7)
+
+	mockRepo.On("ExistsSince", int64(1), models.NotificationTypeBillReminder, "bill", int64(7), windowStart).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything).Return(nil).Once()
+	assert.NoError(t, svc.NotifyBillDue(bill))
+
+	mockRepo.On("ExistsSince", int64(1), models.NotificationTypeBillReminder, "bill", int64(7), windowStart).Return(true, nil).Once()
+	assert.NoError(t, svc.NotifyBillDue(bill))
+
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+// TestGetUnreadCountFallsBackToDatabase 测试Redis不可用时从数据库统计未读数
+func TestGetUnreadCountFallsBackToDatabase(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	svc := NewNotificationService(mockRepo)
+
+	mockRepo.On("CountUnread", int64(1)).Return(int64(4), nil)
+
+	count, err := svc.GetUnreadCount(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
+
+// TestDeleteNotificationNotFound 测试删除他人或不存在的通知
+func TestDeleteNotificationNotFound(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	svc := NewNotificationService(mockRepo)
+
+	mockRepo.On("Delete", int64(1), int64(99)).Return(int64(0), nil)
+
+	assert.Equal(t, errNotificationNotFound, svc.DeleteNotification(1, 99))
+}
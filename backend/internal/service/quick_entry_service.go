@@ -0,0 +1,316 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/quickentry"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// 快速记账各字段的置信度
+const (
+	quickEntryKeywordType    = 0.9 // 由“工资”“转账”等关键词确定的类型
+	quickEntryInferredType   = 0.8 // 由账户个数或分类推断的类型
+	quickEntryDefaultType    = 0.5 // 未识别时默认为支出
+	quickEntryDefaultDate    = 0.8 // 未提及日期时默认当天
+	quickEntryMultipleAmount = 0.6 // 句中有多个金额
+	quickEntryTypeAlias      = 0.7 // 通过“微信”“现金”等类型别名匹配到唯一账户
+	quickEntryAmbiguous      = 0.5 // 匹配到多个同分账户，或用户只有一个账户时默认使用
+)
+
+// QuickEntryService 快速记账服务接口
+type QuickEntryService interface {
+	// ParseQuickEntry 解析一句话生成交易草稿，不创建交易；金额、日期、账户和分类按用户自己的数据识别并给出置信度
+	ParseQuickEntry(userID int64, req *request.QuickEntryRequest) (*response.QuickEntryResponse, error)
+}
+
+type quickEntryService struct {
+	accountRepo  repository.AccountRepository
+	categoryRepo repository.CategoryRepository
+	suggestions  CategorySuggestionService // 可选，名称匹配不到分类时按历史交易推荐
+	now          func() time.Time          // 测试中可替换
+}
+
+// NewQuickEntryService 创建快速记账服务实例
+func NewQuickEntryService(
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+	suggestions CategorySuggestionService,
+) QuickEntryService {
+	return &quickEntryService{
+		accountRepo:  accountRepo,
+		categoryRepo: categoryRepo,
+		suggestions:  suggestions,
+		now:          time.Now,
+	}
+}
+
+// quickEntryWord 句中剩余的词及其最佳匹配的账户和分类
+type quickEntryWord struct {
+	quickentry.Word
+	account       *models.Account
+	accountScore  float64
+	ambiguous     bool // 有多个同分账户
+	category      *models.Category
+	categoryScore float64
+}
+
+// ParseQuickEntry 解析快速记账文本
+func (s *quickEntryService) ParseQuickEntry(userID int64, req *request.QuickEntryRequest) (*response.QuickEntryResponse, error) {
+	today := s.now()
+	if req.Today != nil {
+		today = *req.Today
+	}
+	entry := quickentry.Parse(req.Text, today)
+
+	accounts, err := s.accountRepo.FindByUserID(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][快速记账] 获取账户失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+	categories, err := s.categoryRepo.FindAll(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][快速记账] 获取分类失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+	systemCategories, err := s.categoryRepo.GetSystemCategories("")
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][快速记账] 获取系统分类失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+	categories = append(categories, systemCategories...)
+
+	resp := &response.QuickEntryResponse{Amount: entry.Amount, Warnings: []string{}}
+
+	// 金额
+	switch {
+	case entry.AmountCount == 0:
+		resp.Warnings = append(resp.Warnings, "未识别到金额")
+	case entry.AmountCount > 1:
+		resp.Confidence.Amount = quickEntryMultipleAmount
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("识别到多个金额，已使用 %s", entry.Amount.String()))
+	default:
+		resp.Confidence.Amount = quickentry.ScoreExact
+	}
+
+	// 日期
+	if entry.Date != nil {
+		resp.TransactionDate = *entry.Date
+		resp.Confidence.Date = quickentry.ScoreExact
+	} else {
+		resp.TransactionDate = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+		resp.Confidence.Date = quickEntryDefaultDate
+	}
+
+	// 逐词匹配账户和分类，更像账户的词作为账户，其余的词作为标题
+	var accountWords []*quickEntryWord
+	var titleWords []*quickEntryWord
+	for _, word := range entry.Words {
+		match := &quickEntryWord{Word: word}
+		match.account, match.accountScore, match.ambiguous = matchAccount(word.Text, accounts)
+		match.category, match.categoryScore = matchCategory(word.Text, categories, "")
+		if match.account != nil && (word.Role != "" || match.accountScore >= match.categoryScore) {
+			if match.ambiguous {
+				resp.Warnings = append(resp.Warnings, fmt.Sprintf("“%s”匹配到多个账户，已使用 %s", word.Text, match.account.AccountName))
+			}
+			accountWords = append(accountWords, match)
+		} else {
+			titleWords = append(titleWords, match)
+		}
+	}
+
+	// 类型
+	switch {
+	case entry.Type != "":
+		resp.Type = entry.Type
+		resp.Confidence.Type = quickEntryKeywordType
+	case len(accountWords) >= 2:
+		resp.Type = quickentry.TypeTransfer
+		resp.Confidence.Type = quickEntryInferredType
+	default:
+		resp.Type = quickentry.TypeExpense
+		resp.Confidence.Type = quickEntryDefaultType
+		for _, word := range titleWords {
+			if word.category != nil {
+				resp.Type = word.category.Type
+				resp.Confidence.Type = quickEntryInferredType
+				break
+			}
+		}
+	}
+
+	s.assignAccounts(resp, accountWords, accounts)
+
+	// 分类：转账不需要分类；先按名称匹配，匹配不到时按历史交易推荐
+	title := quickEntryTitle(titleWords)
+	if resp.Type != quickentry.TypeTransfer {
+		for _, word := range titleWords {
+			if category, score := matchCategory(word.Text, categories, resp.Type); category != nil && score > resp.Confidence.Category {
+				resp.CategoryID, resp.CategoryName, resp.Confidence.Category = &category.ID, category.Name, score
+			}
+		}
+		if resp.CategoryID == nil {
+			s.suggestCategory(userID, resp, title)
+		}
+		if resp.CategoryID == nil {
+			resp.Warnings = append(resp.Warnings, "未识别到分类")
+		}
+	}
+
+	resp.Title = title
+	if resp.Title == "" {
+		resp.Title = resp.CategoryName
+	}
+
+	// 必填字段都已识别时可直接提交，总体置信度取必填字段中的最低值
+	required := []float64{resp.Confidence.Type, resp.Confidence.Amount, resp.Confidence.Date, resp.Confidence.Account}
+	if resp.Type == quickentry.TypeTransfer {
+		required = append(required, resp.Confidence.ToAccount)
+	}
+	resp.Confidence.Overall = 1
+	for _, confidence := range required {
+		resp.Confidence.Overall = math.Min(resp.Confidence.Overall, confidence)
+	}
+	resp.Complete = resp.Confidence.Overall > 0
+
+	logger.Info(fmt.Sprintf("[Service][快速记账] 解析完成 | 用户ID: %d | 类型: %s | 金额: %s | 置信度: %.2f", userID, resp.Type, resp.Amount.String(), resp.Confidence.Overall))
+	return resp, nil
+}
+
+// assignAccounts 按“从/到”和出现顺序确定账户：转账时第一个为转出账户、第二个为转入账户；
+// 未提及账户且用户只有一个账户时默认使用该账户
+func (s *quickEntryService) assignAccounts(resp *response.QuickEntryResponse, words []*quickEntryWord, accounts []*models.Account) {
+	setAccount := func(word *quickEntryWord) {
+		resp.AccountID, resp.AccountName, resp.Confidence.Account = &word.account.ID, word.account.AccountName, word.accountScore
+	}
+	setToAccount := func(word *quickEntryWord) {
+		resp.ToAccountID, resp.ToAccountName, resp.Confidence.ToAccount = &word.account.ID, word.account.AccountName, word.accountScore
+	}
+
+	if resp.Type == quickentry.TypeTransfer {
+		var rest []*quickEntryWord
+		for _, word := range words {
+			switch {
+			case word.Role == quickentry.RoleFrom && resp.AccountID == nil:
+				setAccount(word)
+			case word.Role == quickentry.RoleTo && resp.ToAccountID == nil:
+				setToAccount(word)
+			default:
+				rest = append(rest, word)
+			}
+		}
+		for _, word := range rest {
+			if resp.AccountID == nil {
+				setAccount(word)
+			} else if resp.ToAccountID == nil {
+				setToAccount(word)
+			}
+		}
+		if resp.AccountID == nil {
+			resp.Warnings = append(resp.Warnings, "未识别到转出账户")
+		}
+		if resp.ToAccountID == nil {
+			resp.Warnings = append(resp.Warnings, "未识别到转入账户")
+		}
+		return
+	}
+
+	if len(words) > 0 {
+		setAccount(words[0])
+		if len(words) > 1 {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("识别到多个账户，已使用 %s", words[0].account.AccountName))
+		}
+		return
+	}
+	if len(accounts) == 1 {
+		resp.AccountID, resp.AccountName, resp.Confidence.Account = &accounts[0].ID, accounts[0].AccountName, quickEntryAmbiguous
+		return
+	}
+	resp.Warnings = append(resp.Warnings, "未识别到账户")
+}
+
+// suggestCategory 按历史交易推荐分类，推荐失败不影响解析
+func (s *quickEntryService) suggestCategory(userID int64, resp *response.QuickEntryResponse, title string) {
+	if s.suggestions == nil || title == "" {
+		return
+	}
+	suggestions, err := s.suggestions.SuggestCategories(userID, &request.SuggestCategoryRequest{
+		Type:            resp.Type,
+		Title:           title,
+		Amount:          resp.Amount,
+		TransactionDate: &resp.TransactionDate,
+		Limit:           1,
+	})
+	if err != nil {
+		logger.Warn(fmt.Sprintf("[Service][快速记账] 分类推荐失败 | 用户ID: %d | 错误: %v", userID, err))
+		return
+	}
+	if len(suggestions) > 0 {
+		resp.CategoryID, resp.CategoryName, resp.Confidence.Category = &suggestions[0].CategoryID, suggestions[0].Name, suggestions[0].Confidence
+	}
+}
+
+// matchAccount 找出与词最匹配的账户；名称匹配不到时按“微信”“现金”等类型别名匹配，
+// 同分的账户有多个时取排序靠前的并降低置信度
+func matchAccount(word string, accounts []*models.Account) (*models.Account, float64, bool) {
+	var best []*models.Account
+	bestScore := 0.0
+	for _, account := range accounts {
+		score := quickentry.AccountScore(word, account.AccountName)
+		if score > bestScore {
+			best, bestScore = []*models.Account{account}, score
+		} else if score > 0 && score == bestScore {
+			best = append(best, account)
+		}
+	}
+	if bestScore == 0 {
+		if accountType, ok := quickentry.AccountTypeAlias(word); ok {
+			for _, account := range accounts {
+				if account.AccountType == accountType {
+					best = append(best, account)
+				}
+			}
+			bestScore = quickEntryTypeAlias
+		}
+	}
+
+	switch len(best) {
+	case 0:
+		return nil, 0, false
+	case 1:
+		return best[0], bestScore, false
+	default:
+		return best[0], math.Min(bestScore, quickEntryAmbiguous), true
+	}
+}
+
+// matchCategory 找出与词最匹配的分类，categoryType 为空时不限收支类型
+func matchCategory(word string, categories []*models.Category, categoryType string) (*models.Category, float64) {
+	var best *models.Category
+	bestScore := 0.0
+	for _, category := range categories {
+		if categoryType != "" && category.Type != categoryType {
+			continue
+		}
+		if score := quickentry.NameScore(word, category.Name); score > bestScore {
+			best, bestScore = category, score
+		}
+	}
+	return best, bestScore
+}
+
+// quickEntryTitle 用账户之外剩余的词组成标题
+func quickEntryTitle(words []*quickEntryWord) string {
+	texts := make([]string, 0, len(words))
+	for _, word := range words {
+		texts = append(texts, word.Text)
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCategorySuggestionService Mock CategorySuggestionService
+type MockCategorySuggestionService struct {
+	mock.Mock
+}
+
+func (m *MockCategorySuggestionService) InvalidateModel(userID int64) {
+	m.Called(userID)
+}
+
+func (m *MockCategorySuggestionService) SuggestCategories(userID int64, req *request.SuggestCategoryRequest) ([]*response.CategorySuggestionResponse, error) {
+	args := m.Called(userID, req)
+	return args.Get(0).([]*response.CategorySuggestionResponse), args.Error(1)
+}
+
+// TestParseQuickEntry 测试按用户自己的账户和分类解析中英文快速记账文本
+func TestParseQuickEntry(t *testing.T) {
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockSuggestions := new(MockCategorySuggestionService)
+	service := NewQuickEntryService(mockAccountRepo, mockCategoryRepo, mockSuggestions).(*quickEntryService)
+	service.now = func() time.Time { return time.Date(2024, 3, 13, 20, 0, 0, 0, time.Local) }
+
+	userID := int64(1)
+	mockAccountRepo.On("FindByUserID", userID).Return([]*models.Account{
+		{ID: 1, UserID: userID, AccountType: "wechat", AccountName: "微信零钱"},
+		{ID: 2, UserID: userID, AccountType: "bank", AccountName: "工商银行工资卡"},
+		{ID: 3, UserID: userID, AccountType: "alipay", AccountName: "支付宝"},
+	}, nil)
+	mockCategoryRepo.On("FindAll", userID).Return([]*models.Category{
+		{ID: 10, UserID: userID, Type: "expense", Name: "午饭"},
+		{ID: 11, UserID: userID, Type: "expense", Name: "交通"},
+	}, nil)
+	mockCategoryRepo.On("GetSystemCategories", "").Return([]*models.Category{
+		{ID: 20, Type: "income", Name: "工资", IsSystem: true},
+		{ID: 21, Type: "expense", Name: "餐饮", IsSystem: true},
+	}, nil)
+
+	// 分类按名称匹配，“微信”按账户类型别名匹配
+	resp, err := service.ParseQuickEntry(userID, &request.QuickEntryRequest{Text: "午饭 35 微信 昨天"})
+	assert.NoError(t, err)
+	assert.Equal(t, "expense", resp.Type)
+	assert.Equal(t, money.MustParse("35.00"), resp.Amount)
+	assert.Equal(t, date(2024, 3, 12), resp.TransactionDate)
+	assert.Equal(t, int64(1), *resp.AccountID)
+	assert.Equal(t, "微信零钱", resp.AccountName)
+	assert.Equal(t, int64(10), *resp.CategoryID)
+	assert.Equal(t, "午饭", resp.Title)
+	assert.Equal(t, quickEntryInferredType, resp.Confidence.Type)
+	assert.Equal(t, 1.0, resp.Confidence.Category)
+	assert.True(t, resp.Complete)
+	assert.Empty(t, resp.Warnings)
+	mockSuggestions.AssertNotCalled(t, "SuggestCategories", mock.Anything, mock.Anything)
+
+	// 名称匹配不到分类时按历史交易推荐，银行缩写匹配账户名
+	mockSuggestions.On("SuggestCategories", userID, mock.MatchedBy(func(req *request.SuggestCategoryRequest) bool {
+		return req.Type == "income" && req.Title == "salary"
+	})).Return([]*response.CategorySuggestionResponse{{CategoryID: 20, Type: "income", Name: "工资", Confidence: 0.92}}, nil)
+	today := date(2024, 3, 20)
+	resp, err = service.ParseQuickEntry(userID, &request.QuickEntryRequest{Text: "salary 12000 to ICBC 25th", Today: &today})
+	assert.NoError(t, err)
+	assert.Equal(t, "income", resp.Type)
+	assert.Equal(t, money.MustParse("12000.00"), resp.Amount)
+	assert.Equal(t, date(2024, 2, 25), resp.TransactionDate)
+	assert.Equal(t, int64(2), *resp.AccountID)
+	assert.Nil(t, resp.ToAccountID)
+	assert.Equal(t, int64(20), *resp.CategoryID)
+	assert.Equal(t, 0.92, resp.Confidence.Category)
+	assert.Equal(t, "salary", resp.Title)
+	assert.True(t, resp.Complete)
+
+	// 两个账户推断为转账，第一个为转出账户
+	resp, err = service.ParseQuickEntry(userID, &request.QuickEntryRequest{Text: "支付宝转到工商 2000"})
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer", resp.Type)
+	assert.Equal(t, int64(3), *resp.AccountID)
+	assert.Equal(t, int64(2), *resp.ToAccountID)
+	assert.Nil(t, resp.CategoryID)
+	assert.Equal(t, date(2024, 3, 13), resp.TransactionDate)
+	assert.Equal(t, quickEntryDefaultDate, resp.Confidence.Date)
+	assert.True(t, resp.Complete)
+
+	// 缺少金额和账户时不完整
+	mockSuggestions.On("SuggestCategories", userID, mock.Anything).Return([]*response.CategorySuggestionResponse{}, nil)
+	resp, err = service.ParseQuickEntry(userID, &request.QuickEntryRequest{Text: "打车"})
+	assert.NoError(t, err)
+	assert.False(t, resp.Complete)
+	assert.Equal(t, 0.0, resp.Confidence.Overall)
+	assert.Contains(t, resp.Warnings, "未识别到金额")
+	assert.Contains(t, resp.Warnings, "未识别到账户")
+	assert.Contains(t, resp.Warnings, "未识别到分类")
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+const (
+	reconciliationLimit = 50     // 对账记录列表默认条数
+	adjustmentTitle     = "余额调整" // 补记差额交易的标题
+)
+
+var (
+	errReconcileAccount   = errors.New("账户不存在")
+	errReconcileFuture    = errors.New("对账日期不能晚于今天")
+	errReconcileBefore    = errors.New("对账日期不能早于上次对账日期")
+	errReconcileChanged   = errors.New("对账期间账户有新的交易，请重新对账")
+	errReconcileNotFound  = errors.New("对账记录不存在")
+	errReconcileNotLatest = errors.New("只能撤销最近一次对账")
+)
+
+// ReconciliationService 对账服务接口：核对账户实际余额，补记差额并锁定已核对的交易
+type ReconciliationService interface {
+	// Preview 计算截至对账日的账面余额与实际余额的差额，列出尚未对账的交易
+	Preview(userID int64, accountID int64, req *request.ReconcileRequest) (*response.ReconcilePreviewResponse, error)
+	// Reconcile 完成对账：有差额时按需记一笔余额调整交易，再将对账日及之前的交易标记为已对账
+	Reconcile(userID int64, accountID int64, req *request.ReconcileRequest) (*response.ReconciliationResponse, error)
+	GetReconciliations(userID int64, accountID int64) ([]*response.ReconciliationResponse, error)
+	// UndoReconciliation 撤销最近一次对账，解除交易锁定（余额调整交易保留）
+	UndoReconciliation(userID int64, accountID int64, reconciliationID int64) error
+}
+
+type reconciliationService struct {
+	accountRepo        repository.AccountRepository
+	reconciliationRepo repository.ReconciliationRepository
+	transactionRepo    repository.TransactionRepository
+	uow                repository.UnitOfWork
+	transactionService TransactionService
+}
+
+// NewReconciliationService 创建对账服务实例
+func NewReconciliationService(
+	accountRepo repository.AccountRepository,
+	reconciliationRepo repository.ReconciliationRepository,
+	transactionRepo repository.TransactionRepository,
+	uow repository.UnitOfWork,
+	transactionService TransactionService,
+) ReconciliationService {
+	return &reconciliationService{
+		accountRepo:        accountRepo,
+		reconciliationRepo: reconciliationRepo,
+		transactionRepo:    transactionRepo,
+		uow:                uow,
+		transactionService: transactionService,
+	}
+}
+
+// Preview 对账预览
+func (s *reconciliationService) Preview(userID int64, accountID int64, req *request.ReconcileRequest) (*response.ReconcilePreviewResponse, error) {
+	account, latest, reconcileDate, err := s.checkReconcile(userID, accountID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := ledgerBalance(s.transactionRepo, account, reconcileDate)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.transactionRepo.FindUnreconciled(account.ID, reconcileDate)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.ReconcilePreviewResponse{
+		AccountID:        account.ID,
+		Currency:         account.Currency,
+		ReconcileDate:    reconcileDate,
+		StatementBalance: req.StatementBalance,
+		LedgerBalance:    ledger,
+		Difference:       req.StatementBalance - ledger,
+		Transactions:     make([]*response.UnreconciledTransactionResponse, 0, len(transactions)),
+	}
+	if latest != nil {
+		resp.LastReconciledDate = &latest.ReconcileDate
+	}
+	for _, t := range transactions {
+		resp.Transactions = append(resp.Transactions, &response.UnreconciledTransactionResponse{
+			ID:              t.ID,
+			Type:            t.Type,
+			Title:           t.Title,
+			CategoryID:      t.CategoryID,
+			Amount:          t.Amount,
+			Effect:          transactionEffects(t.Type, t.AccountID, t.ToAccountID, t.Amount)[account.ID],
+			TransactionDate: t.TransactionDate,
+		})
+	}
+	return resp, nil
+}
+
+// Reconcile 完成对账
+func (s *reconciliationService) Reconcile(userID int64, accountID int64, req *request.ReconcileRequest) (*response.ReconciliationResponse, error) {
+	account, _, reconcileDate, err := s.checkReconcile(userID, accountID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := ledgerBalance(s.transactionRepo, account, reconcileDate)
+	if err != nil {
+		return nil, err
+	}
+	difference := req.StatementBalance - ledger
+	if difference != 0 && !req.PostAdjustment {
+		return nil, fmt.Errorf("账面余额与实际余额相差%s，请核对交易或记一笔余额调整", difference)
+	}
+
+	reconciliation := &models.Reconciliation{
+		UserID:           userID,
+		AccountID:        account.ID,
+		ReconcileDate:    reconcileDate,
+		StatementBalance: req.StatementBalance,
+		LedgerBalance:    ledger,
+		Difference:       difference,
+		Currency:         account.Currency,
+		Notes:            req.Notes,
+	}
+
+	// 在同一事务中复核余额、写入对账记录并锁定交易，余额调整交易与对账一并提交
+	link := func(repos *repository.TxRepositories, adjustment *models.Transaction) error {
+		locked, err := repos.Accounts.FindByIDForUpdate(account.ID)
+		if err != nil {
+			return err
+		}
+		current, err := ledgerBalance(repos.Transactions, locked, reconcileDate)
+		if err != nil {
+			return err
+		}
+		if current != req.StatementBalance {
+			return errReconcileChanged
+		}
+
+		if adjustment != nil {
+			reconciliation.AdjustmentTransactionID = &adjustment.ID
+		}
+		if err := repos.Reconciliations.Create(reconciliation); err != nil {
+			return err
+		}
+		count, err := repos.Transactions.MarkReconciled(account.ID, reconcileDate, reconciliation.ID)
+		if err != nil {
+			return err
+		}
+		reconciliation.TransactionCount = int(count)
+		return nil
+	}
+
+	if difference == 0 {
+		err = s.uow.Transaction(func(repos *repository.TxRepositories) error {
+			return link(repos, nil)
+		})
+	} else {
+		_, err = s.transactionService.CreateLinkedTransaction(userID, adjustmentRequest(account, reconcileDate, difference, req), link)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][对账] 对账失败 | 用户ID: %d | 账户ID: %d | 错误: %v", userID, accountID, err))
+		if errors.Is(err, errReconcileChanged) {
+			return nil, errReconcileChanged
+		}
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][对账] 对账完成 | 用户ID: %d | 账户ID: %d | 对账日期: %s | 差额: %s | 交易数: %d",
+		userID, accountID, reconcileDate.Format("2006-01-02"), difference, reconciliation.TransactionCount))
+	return toReconciliationResponse(reconciliation), nil
+}
+
+// GetReconciliations 获取账户的对账记录
+func (s *reconciliationService) GetReconciliations(userID int64, accountID int64) ([]*response.ReconciliationResponse, error) {
+	if _, err := s.findAccount(userID, accountID); err != nil {
+		return nil, err
+	}
+
+	reconciliations, err := s.reconciliationRepo.FindByAccountID(accountID, reconciliationLimit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*response.ReconciliationResponse, 0, len(reconciliations))
+	for _, reconciliation := range reconciliations {
+		result = append(result, toReconciliationResponse(reconciliation))
+	}
+	return result, nil
+}
+
+// UndoReconciliation 撤销最近一次对账
+func (s *reconciliationService) UndoReconciliation(userID int64, accountID int64, reconciliationID int64) error {
+	if _, err := s.findAccount(userID, accountID); err != nil {
+		return err
+	}
+	latest, err := s.reconciliationRepo.FindLatest(accountID)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		return errReconcileNotFound
+	}
+	if latest.ID != reconciliationID {
+		return errReconcileNotLatest
+	}
+
+	err = s.uow.Transaction(func(repos *repository.TxRepositories) error {
+		if err := repos.Transactions.ClearReconciliation(reconciliationID); err != nil {
+			return err
+		}
+		return repos.Reconciliations.Delete(reconciliationID)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][对账] 撤销失败 | 用户ID: %d | 对账ID: %d | 错误: %v", userID, reconciliationID, err))
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][对账] 撤销成功 | 用户ID: %d | 账户ID: %d | 对账ID: %d", userID, accountID, reconciliationID))
+	return nil
+}
+
+// checkReconcile 校验账户归属和对账日期：不晚于今天，且不早于上次对账日期
+func (s *reconciliationService) checkReconcile(userID int64, accountID int64, req *request.ReconcileRequest) (*models.Account, *models.Reconciliation, time.Time, error) {
+	account, err := s.findAccount(userID, accountID)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	reconcileDate := truncateToDate(req.ReconcileDate)
+	if reconcileDate.After(truncateToDate(time.Now())) {
+		return nil, nil, time.Time{}, errReconcileFuture
+	}
+	latest, err := s.reconciliationRepo.FindLatest(account.ID)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	if latest != nil && reconcileDate.Before(truncateToDate(latest.ReconcileDate)) {
+		return nil, nil, time.Time{}, errReconcileBefore
+	}
+	return account, latest, reconcileDate, nil
+}
+
+func (s *reconciliationService) findAccount(userID int64, accountID int64) (*models.Account, error) {
+	account, err := s.accountRepo.FindByID(accountID)
+	if err != nil || account.UserID != userID {
+		return nil, errReconcileAccount
+	}
+	return account, nil
+}
+
+// ledgerBalance 截至某日的账面余额 = 当前余额 - 该日之后交易的净影响
+func ledgerBalance(transactions repository.TransactionRepository, account *models.Account, date time.Time) (money.Money, error) {
+	after, err := transactions.GetAccountNetChangeAfter(account.ID, date)
+	if err != nil {
+		return 0, err
+	}
+	return account.Balance - after, nil
+}
+
+// adjustmentRequest 构造补记差额的余额调整交易：实际余额多于账面记收入，少于账面记支出
+func adjustmentRequest(account *models.Account, date time.Time, difference money.Money, req *request.ReconcileRequest) *request.CreateTransactionRequest {
+	transType, amount := models.TransactionTypeIncome, difference
+	if difference < 0 {
+		transType, amount = models.TransactionTypeExpense, -difference
+	}
+	return &request.CreateTransactionRequest{
+		Type:            string(transType),
+		CategoryID:      req.CategoryID,
+		AccountID:       &account.ID,
+		Amount:          amount,
+		Currency:        account.Currency,
+		Title:           adjustmentTitle,
+		Description:     req.Notes,
+		TransactionDate: date,
+	}
+}
+
+func toReconciliationResponse(reconciliation *models.Reconciliation) *response.ReconciliationResponse {
+	return &response.ReconciliationResponse{
+		ID:                      reconciliation.ID,
+		AccountID:               reconciliation.AccountID,
+		ReconcileDate:           reconciliation.ReconcileDate,
+		StatementBalance:        reconciliation.StatementBalance,
+		LedgerBalance:           reconciliation.LedgerBalance,
+		Difference:              reconciliation.Difference,
+		Currency:                reconciliation.Currency,
+		AdjustmentTransactionID: reconciliation.AdjustmentTransactionID,
+		TransactionCount:        reconciliation.TransactionCount,
+		Notes:                   reconciliation.Notes,
+		CreatedAt:               reconciliation.CreatedAt,
+	}
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReconciliationRepository Mock ReconciliationRepository
+type MockReconciliationRepository struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationRepository) Create(reconciliation *models.Reconciliation) error {
+	args := m.Called(reconciliation)
+	return args.Error(0)
+}
+
+func (m *MockReconciliationRepository) FindByID(id int64) (*models.Reconciliation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reconciliation), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) FindByAccountID(accountID int64, limit int) ([]*models.Reconciliation, error) {
+	args := m.Called(accountID, limit)
+	return args.Get(0).([]*models.Reconciliation), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) FindLatest(accountID int64) (*models.Reconciliation, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reconciliation), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func newTestReconciliationService(transRepo *MockTransactionRepository, accountRepo *MockAccountRepository, reconcileRepo *MockReconciliationRepository) ReconciliationService {
+	uow := &MockUnitOfWork{transactionRepo: transRepo, accountRepo: accountRepo, reconcileRepo: reconcileRepo}
+	transService := newTestTransactionService(uow, nil)
+	return NewReconciliationService(accountRepo, reconcileRepo, transRepo, uow, transService)
+}
+
+// TestPreviewReconciliation 测试对账预览：按对账日之后的交易倒推账面余额，列出未对账交易及其对余额的影响
+func TestPreviewReconciliation(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockReconcileRepo := new(MockReconciliationRepository)
+	service := newTestReconciliationService(mockTransRepo, mockAccountRepo, mockReconcileRepo)
+
+	accountID := int64(5)
+	otherID := int64(6)
+	lastDate := date(2024, 2, 29)
+	mockAccountRepo.On("FindByID", accountID).Return(&models.Account{ID: accountID, UserID: 1, Currency: "CNY", Balance: money.MustParse("800.00")}, nil)
+	mockReconcileRepo.On("FindLatest", accountID).Return(&models.Reconciliation{ID: 2, AccountID: accountID, ReconcileDate: lastDate}, nil)
+	mockTransRepo.On("GetAccountNetChangeAfter", accountID, date(2024, 3, 31)).Return(money.MustParse("-200.00"), nil)
+	mockTransRepo.On("FindUnreconciled", accountID, date(2024, 3, 31)).Return([]*models.Transaction{
+		{ID: 11, Type: "expense", AccountID: &accountID, Amount: money.MustParse("50.00"), TransactionDate: date(2024, 3, 3)},
+		{ID: 12, Type: "transfer", AccountID: &otherID, ToAccountID: &accountID, Amount: money.MustParse("300.00"), TransactionDate: date(2024, 3, 9)},
+	}, nil)
+
+	preview, err := service.Preview(1, accountID, &request.ReconcileRequest{
+		StatementBalance: money.MustParse("990.00"),
+		ReconcileDate:    date(2024, 3, 31),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("1000.00"), preview.LedgerBalance)
+	assert.Equal(t, money.MustParse("-10.00"), preview.Difference)
+	assert.Equal(t, lastDate, *preview.LastReconciledDate)
+	assert.Len(t, preview.Transactions, 2)
+	assert.Equal(t, money.MustParse("-50.00"), preview.Transactions[0].Effect)
+	assert.Equal(t, money.MustParse("300.00"), preview.Transactions[1].Effect)
+}
+
+// TestReconcileRejectsDifferenceWithoutAdjustment 测试存在差额且未选择补记调整时拒绝对账
+func TestReconcileRejectsDifferenceWithoutAdjustment(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockReconcileRepo := new(MockReconciliationRepository)
+	service := newTestReconciliationService(mockTransRepo, mockAccountRepo, mockReconcileRepo)
+
+	accountID := int64(5)
+	mockAccountRepo.On("FindByID", accountID).Return(&models.Account{ID: accountID, UserID: 1, Currency: "CNY", Balance: money.MustParse("1000.00")}, nil)
+	mockReconcileRepo.On("FindLatest", accountID).Return(nil, nil)
+	mockTransRepo.On("GetAccountNetChangeAfter", accountID, date(2024, 3, 31)).Return(money.Money(0), nil)
+
+	_, err := service.Reconcile(1, accountID, &request.ReconcileRequest{
+		StatementBalance: money.MustParse("990.00"),
+		ReconcileDate:    date(2024, 3, 31),
+	})
+
+	assert.Error(t, err)
+	mockReconcileRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockTransRepo.AssertNotCalled(t, "MarkReconciled", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReconcileRejectsDateBeforeLatest 测试对账日期不能早于上次对账
+func TestReconcileRejectsDateBeforeLatest(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockReconcileRepo := new(MockReconciliationRepository)
+	service := newTestReconciliationService(mockTransRepo, mockAccountRepo, mockReconcileRepo)
+
+	accountID := int64(5)
+	mockAccountRepo.On("FindByID", accountID).Return(&models.Account{ID: accountID, UserID: 1, Currency: "CNY"}, nil)
+	mockReconcileRepo.On("FindLatest", accountID).Return(&models.Reconciliation{ID: 2, ReconcileDate: date(2024, 3, 31)}, nil)
+
+	_, err := service.Reconcile(1, accountID, &request.ReconcileRequest{ReconcileDate: date(2024, 3, 1)})
+
+	assert.Equal(t, errReconcileBefore, err)
+}
+
+// TestReconcileWithAdjustment 测试补记余额调整后完成对账：调整交易记在对账日，与对账记录在同一事务中写入
+func TestReconcileWithAdjustment(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockReconcileRepo := new(MockReconciliationRepository)
+	service := newTestReconciliationService(mockTransRepo, mockAccountRepo, mockReconcileRepo)
+
+	accountID := int64(5)
+	reconcileDate := date(2024, 3, 31)
+	account := &models.Account{ID: accountID, UserID: 1, Currency: "CNY", Balance: money.MustParse("1000.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockReconcileRepo.On("FindLatest", accountID).Return(nil, nil)
+	mockTransRepo.On("GetAccountNetChangeAfter", accountID, reconcileDate).Return(money.Money(0), nil)
+
+	var adjustment *models.Transaction
+	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		adjustment = args.Get(0).(*models.Transaction)
+		adjustment.ID = 77
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(77)).Return(&models.Transaction{ID: 77, UserID: 1, Type: "expense"}, nil)
+	mockReconcileRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Reconciliation).ID = 3
+	}).Return(nil)
+	mockTransRepo.On("MarkReconciled", accountID, reconcileDate, int64(3)).Return(int64(12), nil)
+
+	resp, err := service.Reconcile(1, accountID, &request.ReconcileRequest{
+		StatementBalance: money.MustParse("990.00"),
+		ReconcileDate:    reconcileDate,
+		PostAdjustment:   true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "expense", adjustment.Type)
+	assert.Equal(t, money.MustParse("10.00"), adjustment.Amount)
+	assert.Equal(t, reconcileDate, adjustment.TransactionDate)
+	assert.Equal(t, money.MustParse("990.00"), account.Balance)
+	assert.Equal(t, money.MustParse("-10.00"), resp.Difference)
+	assert.Equal(t, int64(77), *resp.AdjustmentTransactionID)
+	assert.Equal(t, 12, resp.TransactionCount)
+}
+
+// TestUndoReconciliationOnlyLatest 测试只能撤销最近一次对账
+func TestUndoReconciliationOnlyLatest(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockReconcileRepo := new(MockReconciliationRepository)
+	service := newTestReconciliationService(mockTransRepo, mockAccountRepo, mockReconcileRepo)
+
+	accountID := int64(5)
+	mockAccountRepo.On("FindByID", accountID).Return(&models.Account{ID: accountID, UserID: 1}, nil)
+	mockReconcileRepo.On("FindLatest", accountID).Return(&models.Reconciliation{ID: 3, AccountID: accountID}, nil)
+	mockTransRepo.On("ClearReconciliation", int64(3)).Return(nil)
+	mockReconcileRepo.On("Delete", int64(3)).Return(nil)
+
+	assert.Equal(t, errReconcileNotLatest, service.UndoReconciliation(1, accountID, 2))
+	assert.NoError(t, service.UndoReconciliation(1, accountID, 3))
+	mockTransRepo.AssertCalled(t, "ClearReconciliation", int64(3))
+}
+
+// TestReconciledTransactionLocked 测试已对账交易不能修改金额或删除，但可以修改标题；转账仅转入一侧已对账时同样锁定
+func TestReconciledTransactionLocked(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, nil)
+
+	accountID := int64(5)
+	reconciliationID := int64(3)
+	mockTransRepo.On("FindByIDForUpdate", int64(1)).Return(&models.Transaction{
+		ID:               1,
+		UserID:           1,
+		Type:             "expense",
+		AccountID:        &accountID,
+		Amount:           money.MustParse("50.00"),
+		TransactionDate:  date(2024, 3, 3),
+		ReconciliationID: &reconciliationID,
+	}, nil)
+
+	_, err := service.UpdateTransaction(1, 1, &request.UpdateTransactionRequest{Amount: money.MustParse("60.00")})
+	assert.Equal(t, errReconciledLocked, err)
+
+	_, err = service.UpdateTransaction(1, 1, &request.UpdateTransactionRequest{TransactionDate: date(2024, 4, 1)})
+	assert.Equal(t, errReconciledLocked, err)
+
+	assert.Equal(t, errReconciledLocked, service.DeleteTransaction(1, 1))
+
+	toAccountID := int64(6)
+	mockTransRepo.On("FindByIDForUpdate", int64(2)).Return(&models.Transaction{
+		ID:                 2,
+		UserID:             1,
+		Type:               "transfer",
+		AccountID:          &accountID,
+		ToAccountID:        &toAccountID,
+		Amount:             money.MustParse("200.00"),
+		TransactionDate:    date(2024, 3, 3),
+		ToReconciliationID: &reconciliationID,
+	}, nil)
+	_, err = service.UpdateTransaction(1, 2, &request.UpdateTransactionRequest{Amount: money.MustParse("300.00")})
+	assert.Equal(t, errReconciledLocked, err)
+	assert.Equal(t, errReconciledLocked, service.DeleteTransaction(1, 2))
+	mockTransRepo.AssertNotCalled(t, "Delete", mock.Anything)
+
+	assert.False(t, changesReconciledFields(&models.Transaction{Type: "expense", Amount: money.MustParse("50.00"), TransactionDate: date(2024, 3, 3)},
+		&request.UpdateTransactionRequest{Title: "午餐", Amount: money.MustParse("50.00")}))
+}
+
+// TestReconciledPeriodClosed 测试新增交易、修改日期或账户（含转入账户）落在账户已对账的期间内时被拒绝
+func TestReconciledPeriodClosed(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockReconcileRepo := new(MockReconciliationRepository)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo, reconcileRepo: mockReconcileRepo}, nil)
+
+	walletID, cardID, otherID := int64(5), int64(6), int64(7)
+	for _, id := range []int64{walletID, cardID, otherID} {
+		account := &models.Account{ID: id, UserID: 1, Currency: "CNY", Balance: money.MustParse("1000.00")}
+		mockAccountRepo.On("FindByID", id).Return(account, nil)
+		mockAccountRepo.On("FindByIDForUpdate", id).Return(account, nil)
+	}
+	mockAccountRepo.On("Update", mock.Anything).Return(nil)
+	mockReconcileRepo.On("FindLatest", walletID).Return(&models.Reconciliation{ID: 3, AccountID: walletID, ReconcileDate: date(2024, 3, 31)}, nil)
+	mockReconcileRepo.On("FindLatest", cardID).Return(nil, nil)
+	mockReconcileRepo.On("FindLatest", otherID).Return(nil, nil)
+	mockTransRepo.On("Create", mock.Anything).Return(nil)
+	mockTransRepo.On("Update", mock.Anything).Return(nil)
+	mockTransRepo.On("FindByID", mock.Anything).Return(&models.Transaction{}, nil)
+
+	newExpense := func(accountID int64, d time.Time) *request.CreateTransactionRequest {
+		return &request.CreateTransactionRequest{Type: "expense", AccountID: &accountID, Amount: money.MustParse("10.00"), TransactionDate: d}
+	}
+	_, err := service.CreateTransaction(1, newExpense(walletID, date(2024, 3, 31)))
+	assert.ErrorIs(t, err, errReconciledPeriod)
+	_, err = service.CreateTransaction(1, &request.CreateTransactionRequest{
+		Type: "transfer", AccountID: &cardID, ToAccountID: &walletID, Amount: money.MustParse("10.00"), TransactionDate: date(2024, 3, 20),
+	})
+	assert.ErrorIs(t, err, errReconciledPeriod)
+	_, err = service.CreateTransaction(1, newExpense(walletID, date(2024, 4, 1)))
+	assert.NoError(t, err)
+
+	// 修改日期或账户
+	mockTransRepo.On("FindByIDForUpdate", int64(1)).Return(&models.Transaction{
+		ID: 1, UserID: 1, Type: "expense", AccountID: &cardID, Amount: money.MustParse("10.00"), Currency: "CNY", TransactionDate: date(2024, 3, 15),
+	}, nil)
+	_, err = service.UpdateTransaction(1, 1, &request.UpdateTransactionRequest{AccountID: &walletID})
+	assert.Equal(t, errReconciledPeriod, err)
+	mockTransRepo.On("FindByIDForUpdate", int64(2)).Return(&models.Transaction{
+		ID: 2, UserID: 1, Type: "expense", AccountID: &walletID, Amount: money.MustParse("10.00"), Currency: "CNY", TransactionDate: date(2024, 4, 1),
+	}, nil)
+	_, err = service.UpdateTransaction(1, 2, &request.UpdateTransactionRequest{TransactionDate: date(2024, 3, 30)})
+	assert.Equal(t, errReconciledPeriod, err)
+	mockTransRepo.On("FindByIDForUpdate", int64(3)).Return(&models.Transaction{
+		ID: 3, UserID: 1, Type: "transfer", AccountID: &cardID, ToAccountID: &otherID, Amount: money.MustParse("10.00"), Currency: "CNY", TransactionDate: date(2024, 3, 15),
+	}, nil)
+	_, err = service.UpdateTransaction(1, 3, &request.UpdateTransactionRequest{ToAccountID: &walletID})
+	assert.Equal(t, errReconciledPeriod, err)
+
+	// 只修改描述信息不受影响
+	_, err = service.UpdateTransaction(1, 1, &request.UpdateTransactionRequest{Title: "午餐"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// 单条规则单次补生成的最大期数，超出部分留到下次调度继续
+const maxRecurringCatchUp = 400
+
+var (
+	errRecurringRuleNotFound = errors.New("周期规则不存在")
+	errOccurrenceTaken       = errors.New("该期已生成")
+)
+
+// RecurringService 周期交易服务接口
+type RecurringService interface {
+	GetRules(userID int64) ([]*response.RecurringRuleResponse, error)
+	GetRule(userID int64, ruleID int64) (*response.RecurringRuleResponse, error)
+	CreateRule(userID int64, req *request.CreateRecurringRuleRequest) (*response.RecurringRuleResponse, error)
+	UpdateRule(userID int64, ruleID int64, req *request.UpdateRecurringRuleRequest) (*response.RecurringRuleResponse, error)
+	DeleteRule(userID int64, ruleID int64) error
+	// ProcessDueRules 生成截至now（含当天）所有到期的交易，返回生成数量
+	ProcessDueRules(now time.Time) (int, error)
+}
+
+type recurringService struct {
+	ruleRepo           repository.RecurringRuleRepository
+	accountRepo        repository.AccountRepository
+	categoryRepo       repository.CategoryRepository
+	transactionService TransactionService
+	running            sync.Mutex
+	now                func() time.Time // 测试中可替换
+}
+
+// NewRecurringService 创建周期交易服务实例
+func NewRecurringService(
+	ruleRepo repository.RecurringRuleRepository,
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+	transactionService TransactionService,
+) RecurringService {
+	return &recurringService{
+		ruleRepo:           ruleRepo,
+		accountRepo:        accountRepo,
+		categoryRepo:       categoryRepo,
+		transactionService: transactionService,
+		now:                time.Now,
+	}
+}
+
+// GetRules 获取周期规则列表
+func (s *recurringService) GetRules(userID int64) ([]*response.RecurringRuleResponse, error) {
+	rules, err := s.ruleRepo.FindByUserID(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][周期规则] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	result := make([]*response.RecurringRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toRecurringRuleResponse(rule))
+	}
+	return result, nil
+}
+
+// GetRule 获取周期规则详情
+func (s *recurringService) GetRule(userID int64, ruleID int64) (*response.RecurringRuleResponse, error) {
+	rule, err := s.findUserRule(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return toRecurringRuleResponse(rule), nil
+}
+
+// CreateRule 创建周期规则，开始日期早于今天时会在下次调度时补生成历史交易
+func (s *recurringService) CreateRule(userID int64, req *request.CreateRecurringRuleRequest) (*response.RecurringRuleResponse, error) {
+	if err := s.validateReferences(userID, req.AccountID, req.ToAccountID, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	rule := &models.RecurringRule{
+		UserID:         userID,
+		Name:           req.Name,
+		Type:           req.Type,
+		CategoryID:     req.CategoryID,
+		AccountID:      req.AccountID,
+		ToAccountID:    req.ToAccountID,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		Title:          req.Title,
+		Description:    req.Description,
+		Frequency:      req.Frequency,
+		Interval:       req.Interval,
+		ByMonthDay:     req.ByMonthDay,
+		StartDate:      truncateToDate(req.StartDate),
+		MaxOccurrences: req.MaxOccurrences,
+		IsActive:       true,
+	}
+	if rule.Interval <= 0 {
+		rule.Interval = 1
+	}
+	currency, err := s.ruleCurrency(rule)
+	if err != nil {
+		return nil, err
+	}
+	rule.Currency = currency
+	if rule.Title == "" {
+		rule.Title = rule.Name
+	}
+	if req.EndDate != nil {
+		endDate := truncateToDate(*req.EndDate)
+		if endDate.Before(rule.StartDate) {
+			return nil, errors.New("结束日期不能早于开始日期")
+		}
+		rule.EndDate = &endDate
+	}
+	recomputeNextRun(rule)
+
+	if err := s.ruleRepo.Create(rule); err != nil {
+		logger.Error(fmt.Sprintf("[Service][周期规则] 创建失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, errors.New("创建周期规则失败")
+	}
+
+	logger.Info(fmt.Sprintf("[Service][周期规则] 创建成功 | 用户ID: %d | 规则ID: %d", userID, rule.ID))
+	return toRecurringRuleResponse(rule), nil
+}
+
+// UpdateRule 更新周期规则
+func (s *recurringService) UpdateRule(userID int64, ruleID int64, req *request.UpdateRecurringRuleRequest) (*response.RecurringRuleResponse, error) {
+	rule, err := s.findUserRule(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateReferences(userID, req.AccountID, req.ToAccountID, req.CategoryID); err != nil {
+		return nil, err
+	}
+	previousNextRun, wasActive := rule.NextRunDate, rule.IsActive
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.CategoryID != nil {
+		rule.CategoryID = req.CategoryID
+	}
+	if req.AccountID != nil {
+		rule.AccountID = req.AccountID
+	}
+	if req.ToAccountID != nil {
+		rule.ToAccountID = req.ToAccountID
+	}
+	if req.Amount != nil {
+		rule.Amount = *req.Amount
+	}
+	if req.Currency != nil {
+		rule.Currency = *req.Currency
+	}
+	// 更换账户或币种时重新校验币种，否则每次生成交易都会因币种不一致失败
+	if req.AccountID != nil || req.ToAccountID != nil || req.Currency != nil {
+		if rule.Currency, err = s.ruleCurrency(rule); err != nil {
+			return nil, err
+		}
+	}
+	if req.Title != nil {
+		rule.Title = *req.Title
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if req.EndDate != nil {
+		endDate := truncateToDate(*req.EndDate)
+		if endDate.Before(rule.StartDate) {
+			return nil, errors.New("结束日期不能早于开始日期")
+		}
+		rule.EndDate = &endDate
+	}
+	if req.MaxOccurrences != nil {
+		rule.MaxOccurrences = req.MaxOccurrences
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	recomputeNextRun(rule)
+	switch {
+	case rule.IsActive && !wasActive:
+		// 恢复启用：暂停期间的期数不补记，从今天或之后的第一期开始
+		skipRunsBefore(rule, truncateToDate(s.now()))
+	case previousNextRun != nil:
+		// 保持此前恢复启用时跳过的进度
+		skipRunsBefore(rule, *previousNextRun)
+	}
+
+	if err := s.ruleRepo.Update(rule); err != nil {
+		logger.Error(fmt.Sprintf("[Service][周期规则] 更新失败 | 用户ID: %d | 规则ID: %d | 错误: %v", userID, ruleID, err))
+		return nil, errors.New("更新周期规则失败")
+	}
+	return toRecurringRuleResponse(rule), nil
+}
+
+// DeleteRule 删除周期规则（已生成的交易保留）
+func (s *recurringService) DeleteRule(userID int64, ruleID int64) error {
+	if _, err := s.findUserRule(userID, ruleID); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(ruleID)
+}
+
+// ProcessDueRules 生成所有到期规则的交易
+// 服务停机期间错过的期数会按顺序补齐；同一进程内的重复调度直接跳过，
+// 多实例并发时依靠规则行锁与(transaction_date, recurring_rule_id)唯一索引保证不重复
+func (s *recurringService) ProcessDueRules(now time.Time) (int, error) {
+	if !s.running.TryLock() {
+		logger.Info("[Service][周期规则] 上一轮调度仍在执行，跳过")
+		return 0, nil
+	}
+	defer s.running.Unlock()
+
+	today := truncateToDate(now)
+	rules, err := s.ruleRepo.FindDue(today)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][周期规则] 查询到期规则失败 | 错误: %v", err))
+		return 0, err
+	}
+
+	created := 0
+	for _, rule := range rules {
+		n, err := s.processRule(rule, today)
+		created += n
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][周期规则] 生成交易失败 | 用户ID: %d | 规则ID: %d | 错误: %v", rule.UserID, rule.ID, err))
+		}
+	}
+
+	if created > 0 {
+		logger.Info(fmt.Sprintf("[Service][周期规则] 调度完成 | 到期规则: %d | 生成交易: %d", len(rules), created))
+	}
+	return created, nil
+}
+
+// processRule 逐期生成单条规则截至today的交易
+func (s *recurringService) processRule(rule *models.RecurringRule, today time.Time) (int, error) {
+	created := 0
+	for created < maxRecurringCatchUp {
+		if !rule.IsActive || rule.NextRunDate == nil || rule.NextRunDate.After(today) {
+			return created, nil
+		}
+		occurrence := *rule.NextRunDate
+
+		req := &request.CreateTransactionRequest{
+			Type:            rule.Type,
+			CategoryID:      rule.CategoryID,
+			AccountID:       rule.AccountID,
+			ToAccountID:     rule.ToAccountID,
+			Amount:          rule.Amount,
+			Currency:        rule.Currency,
+			Title:           rule.Title,
+			Description:     rule.Description,
+			TransactionDate: occurrence,
+			RecurringRuleID: &rule.ID,
+		}
+
+		var advanced *models.RecurringRule
+		_, err := s.transactionService.CreateLinkedTransaction(rule.UserID, req, func(repos *repository.TxRepositories, t *models.Transaction) error {
+			locked, err := repos.RecurringRules.FindByIDForUpdate(rule.ID)
+			if err != nil {
+				return err
+			}
+			if !locked.IsActive || locked.NextRunDate == nil || !locked.NextRunDate.Equal(occurrence) {
+				return errOccurrenceTaken
+			}
+			advanceRecurringRule(locked, occurrence)
+			advanced = locked
+			return repos.RecurringRules.Update(locked)
+		})
+		if err != nil {
+			// 其他实例已生成该期（规则已推进或唯一索引冲突）时重新加载规则继续
+			reloaded, findErr := s.ruleRepo.FindByID(rule.ID)
+			if findErr != nil {
+				return created, err
+			}
+			if reloaded.NextRunDate != nil && !reloaded.NextRunDate.After(occurrence) && reloaded.IsActive {
+				return created, err
+			}
+			rule = reloaded
+			continue
+		}
+
+		rule = advanced
+		created++
+	}
+	return created, nil
+}
+
+// findUserRule 查找并校验规则归属
+func (s *recurringService) findUserRule(userID int64, ruleID int64) (*models.RecurringRule, error) {
+	rule, err := s.ruleRepo.FindByID(ruleID)
+	if err != nil || rule.UserID != userID {
+		return nil, errRecurringRuleNotFound
+	}
+	return rule, nil
+}
+
+// validateReferences 校验账户与分类归属（系统分类user_id为0）
+func (s *recurringService) validateReferences(userID int64, accountID, toAccountID, categoryID *int64) error {
+	for _, id := range []*int64{accountID, toAccountID} {
+		if id == nil {
+			continue
+		}
+		account, err := s.accountRepo.FindByID(*id)
+		if err != nil || account.UserID != userID {
+			return errors.New("invalid account")
+		}
+	}
+	if categoryID != nil {
+		category, err := s.categoryRepo.FindByID(*categoryID)
+		if err != nil || (category.UserID != userID && category.UserID != 0) {
+			return errors.New("invalid category")
+		}
+	}
+	return nil
+}
+
+// ruleCurrency 确定规则币种：未指定时沿用账户币种，指定时须与账户币种一致；转账的转入账户币种也须一致
+func (s *recurringService) ruleCurrency(rule *models.RecurringRule) (string, error) {
+	account, err := s.accountRepo.FindByID(*rule.AccountID)
+	if err != nil {
+		return "", errors.New("invalid account")
+	}
+	if rule.Type == string(models.TransactionTypeTransfer) && rule.ToAccountID != nil {
+		toAccount, err := s.accountRepo.FindByID(*rule.ToAccountID)
+		if err != nil {
+			return "", errors.New("invalid to_account")
+		}
+		if account.Currency != "" && toAccount.Currency != "" && account.Currency != toAccount.Currency {
+			return "", errTransferCurrency
+		}
+	}
+	return transactionCurrency(rule.Currency, account)
+}
+
+// advanceRecurringRule 记录一期已生成，并推进到下一期
+func advanceRecurringRule(rule *models.RecurringRule, occurrence time.Time) {
+	rule.OccurrenceCount++
+	lastRun := occurrence
+	rule.LastRunDate = &lastRun
+	recomputeNextRun(rule)
+}
+
+// recomputeNextRun 根据已生成期数与结束条件重新计算下次执行日期
+func recomputeNextRun(rule *models.RecurringRule) {
+	var next time.Time
+	if rule.LastRunDate != nil {
+		next = nextOccurrence(rule, *rule.LastRunDate)
+	} else {
+		next = firstOccurrence(rule)
+	}
+
+	if (rule.MaxOccurrences != nil && rule.OccurrenceCount >= *rule.MaxOccurrences) ||
+		(rule.EndDate != nil && next.After(*rule.EndDate)) {
+		rule.NextRunDate = nil
+		return
+	}
+	rule.NextRunDate = &next
+}
+
+// skipRunsBefore 将下次执行日期顺延到 notBefore 当天或之后，跳过的期数不生成交易，也不计入已生成期数
+func skipRunsBefore(rule *models.RecurringRule, notBefore time.Time) {
+	for rule.NextRunDate != nil && rule.NextRunDate.Before(notBefore) {
+		next := nextOccurrence(rule, *rule.NextRunDate)
+		if rule.EndDate != nil && next.After(*rule.EndDate) {
+			rule.NextRunDate = nil
+			return
+		}
+		rule.NextRunDate = &next
+	}
+}
+
+// firstOccurrence 开始日期当天或之后的第一期
+func firstOccurrence(rule *models.RecurringRule) time.Time {
+	start := rule.StartDate
+	if rule.ByMonthDay == nil {
+		return start
+	}
+	switch rule.Frequency {
+	case models.RecurringFrequencyMonthly:
+		candidate := dateWithDay(start.Year(), start.Month(), *rule.ByMonthDay, start.Location())
+		if candidate.Before(start) {
+			candidate = addMonthsClamped(candidate, 1, *rule.ByMonthDay)
+		}
+		return candidate
+	case models.RecurringFrequencyYearly:
+		candidate := dateWithDay(start.Year(), start.Month(), *rule.ByMonthDay, start.Location())
+		if candidate.Before(start) {
+			candidate = addMonthsClamped(candidate, 12, *rule.ByMonthDay)
+		}
+		return candidate
+	default:
+		return start
+	}
+}
+
+// nextOccurrence 计算from之后的下一期日期
+func nextOccurrence(rule *models.RecurringRule, from time.Time) time.Time {
+	interval := rule.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+	day := rule.StartDate.Day()
+	if rule.ByMonthDay != nil {
+		day = *rule.ByMonthDay
+	}
+
+	switch rule.Frequency {
+	case models.RecurringFrequencyDaily:
+		return from.AddDate(0, 0, interval)
+	case models.RecurringFrequencyWeekly:
+		return from.AddDate(0, 0, 7*interval)
+	case models.RecurringFrequencyYearly:
+		return addMonthsClamped(from, 12*interval, day)
+	default:
+		return addMonthsClamped(from, interval, day)
+	}
+}
+
+// toRecurringRuleResponse 转换为响应对象
+func toRecurringRuleResponse(rule *models.RecurringRule) *response.RecurringRuleResponse {
+	return &response.RecurringRuleResponse{
+		ID:              rule.ID,
+		Name:            rule.Name,
+		Type:            rule.Type,
+		CategoryID:      rule.CategoryID,
+		AccountID:       rule.AccountID,
+		ToAccountID:     rule.ToAccountID,
+		Amount:          rule.Amount,
+		Currency:        rule.Currency,
+		Title:           rule.Title,
+		Description:     rule.Description,
+		Frequency:       rule.Frequency,
+		Interval:        rule.Interval,
+		ByMonthDay:      rule.ByMonthDay,
+		StartDate:       rule.StartDate,
+		EndDate:         rule.EndDate,
+		MaxOccurrences:  rule.MaxOccurrences,
+		OccurrenceCount: rule.OccurrenceCount,
+		NextRunDate:     rule.NextRunDate,
+		LastRunDate:     rule.LastRunDate,
+		IsActive:        rule.IsActive,
+		CreatedAt:       rule.CreatedAt,
+		UpdatedAt:       rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRecurringRuleRepository Mock RecurringRuleRepository
+type MockRecurringRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecurringRuleRepository) Create(rule *models.RecurringRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockRecurringRuleRepository) FindByID(id int64) (*models.RecurringRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecurringRule), args.Error(1)
+}
+
+func (m *MockRecurringRuleRepository) FindByIDForUpdate(id int64) (*models.RecurringRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecurringRule), args.Error(1)
+}
+
+func (m *MockRecurringRuleRepository) FindByUserID(userID int64) ([]*models.RecurringRule, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]*models.RecurringRule), args.Error(1)
+}
+
+func (m *MockRecurringRuleRepository) FindDue(date time.Time) ([]*models.RecurringRule, error) {
+	args := m.Called(date)
+	return args.Get(0).([]*models.RecurringRule), args.Error(1)
+}
+
+func (m *MockRecurringRuleRepository) Update(rule *models.RecurringRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockRecurringRuleRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// TestRecomputeNextRun 测试周期规则的日期推算与结束条件
+func TestRecomputeNextRun(t *testing.T) {
+	day31 := 31
+	rule := &models.RecurringRule{
+		Frequency:  models.RecurringFrequencyMonthly,
+		Interval:   1,
+		ByMonthDay: &day31,
+		StartDate:  date(2024, 1, 15),
+	}
+	recomputeNextRun(rule)
+	assert.Equal(t, date(2024, 1, 31), *rule.NextRunDate)
+
+	advanceRecurringRule(rule, *rule.NextRunDate)
+	assert.Equal(t, date(2024, 2, 29), *rule.NextRunDate)
+	advanceRecurringRule(rule, *rule.NextRunDate)
+	assert.Equal(t, date(2024, 3, 31), *rule.NextRunDate)
+
+	// 次数用尽
+	count := 3
+	rule.MaxOccurrences = &count
+	advanceRecurringRule(rule, *rule.NextRunDate)
+	assert.Nil(t, rule.NextRunDate)
+
+	// 结束日期
+	endDate := date(2024, 1, 20)
+	weekly := &models.RecurringRule{
+		Frequency: models.RecurringFrequencyWeekly,
+		Interval:  2,
+		StartDate: date(2024, 1, 1),
+		EndDate:   &endDate,
+	}
+	recomputeNextRun(weekly)
+	assert.Equal(t, date(2024, 1, 1), *weekly.NextRunDate)
+	advanceRecurringRule(weekly, *weekly.NextRunDate)
+	assert.Equal(t, date(2024, 1, 15), *weekly.NextRunDate)
+	advanceRecurringRule(weekly, *weekly.NextRunDate)
+	assert.Nil(t, weekly.NextRunDate)
+}
+
+func newTestRecurringService() (*recurringService, *MockRecurringRuleRepository, *MockTransactionRepository, *MockAccountRepository) {
+	mockRuleRepo := new(MockRecurringRuleRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo, ruleRepo: mockRuleRepo}
+	transService := newTestTransactionService(uow, mockCategoryRepo)
+	svc := NewRecurringService(mockRuleRepo, mockAccountRepo, mockCategoryRepo, transService).(*recurringService)
+	return svc, mockRuleRepo, mockTransRepo, mockAccountRepo
+}
+
+// TestProcessDueRulesCatchUp 测试停机后补齐错过的期数，且每期只生成一次
+func TestProcessDueRulesCatchUp(t *testing.T) {
+	svc, mockRuleRepo, mockTransRepo, mockAccountRepo := newTestRecurringService()
+
+	userID := int64(1)
+	accountID := int64(100)
+	day1 := 1
+	next := date(2024, 1, 1)
+	// stored 模拟数据库中的规则行，事务内加锁读取后原地推进
+	stored := &models.RecurringRule{
+		ID:          9,
+		UserID:      userID,
+		Type:        "expense",
+		AccountID:   &accountID,
+		Amount:      money.MustParse("3000.00"),
+		Frequency:   models.RecurringFrequencyMonthly,
+		Interval:    1,
+		ByMonthDay:  &day1,
+		StartDate:   next,
+		NextRunDate: &next,
+		IsActive:    true,
+	}
+	due := *stored
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local)
+
+	mockRuleRepo.On("FindDue", date(2024, 3, 15)).Return([]*models.RecurringRule{&due}, nil)
+	mockRuleRepo.On("FindByIDForUpdate", int64(9)).Return(stored, nil)
+	mockRuleRepo.On("Update", stored).Return(nil)
+
+	account := &models.Account{ID: accountID, UserID: userID, Balance: money.MustParse("10000.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+
+	var dates []time.Time
+	mockTransRepo.On("Create", mock.MatchedBy(func(tr *models.Transaction) bool {
+		return tr.RecurringRuleID != nil && *tr.RecurringRuleID == 9
+	})).Run(func(args mock.Arguments) {
+		tr := args.Get(0).(*models.Transaction)
+		tr.ID = int64(len(dates) + 1)
+		dates = append(dates, tr.TransactionDate)
+	}).Return(nil)
+	mockTransRepo.On("FindByID", mock.Anything).Return(&models.Transaction{}, nil)
+
+	created, err := svc.ProcessDueRules(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, created)
+	assert.Equal(t, []time.Time{date(2024, 1, 1), date(2024, 2, 1), date(2024, 3, 1)}, dates)
+	assert.Equal(t, date(2024, 4, 1), *stored.NextRunDate)
+	assert.Equal(t, 3, stored.OccurrenceCount)
+	assert.Equal(t, money.MustParse("1000.00"), account.Balance)
+}
+
+// TestProcessDueRulesSkipsTakenOccurrence 测试其他实例已生成该期时不重复生成
+func TestProcessDueRulesSkipsTakenOccurrence(t *testing.T) {
+	svc, mockRuleRepo, mockTransRepo, mockAccountRepo := newTestRecurringService()
+
+	accountID := int64(100)
+	stale := date(2024, 3, 1)
+	advanced := date(2024, 4, 1)
+	due := &models.RecurringRule{
+		ID:          9,
+		UserID:      1,
+		Type:        "expense",
+		AccountID:   &accountID,
+		Amount:      money.MustParse("10.00"),
+		Frequency:   models.RecurringFrequencyMonthly,
+		Interval:    1,
+		StartDate:   stale,
+		NextRunDate: &stale,
+		IsActive:    true,
+	}
+	current := *due
+	current.NextRunDate = &advanced
+	current.OccurrenceCount = 1
+
+	mockRuleRepo.On("FindDue", mock.Anything).Return([]*models.RecurringRule{due}, nil)
+	mockRuleRepo.On("FindByIDForUpdate", int64(9)).Return(&current, nil)
+	mockRuleRepo.On("FindByID", int64(9)).Return(&current, nil)
+
+	account := &models.Account{ID: accountID, UserID: 1}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockTransRepo.On("Create", mock.Anything).Return(nil)
+
+	created, err := svc.ProcessDueRules(time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, created)
+	mockRuleRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+// TestUpdateRuleReactivation 测试暂停的规则恢复启用时从今天之后的第一期开始，不补记暂停期间的期数
+func TestUpdateRuleReactivation(t *testing.T) {
+	svc, mockRuleRepo, _, _ := newTestRecurringService()
+	svc.now = func() time.Time { return time.Date(2024, 6, 10, 8, 0, 0, 0, time.Local) }
+
+	userID := int64(1)
+	lastRun, nextRun := date(2024, 1, 1), date(2024, 2, 1)
+	rule := &models.RecurringRule{
+		ID: 9, UserID: userID, Frequency: models.RecurringFrequencyMonthly, Interval: 1,
+		StartDate: date(2023, 12, 1), OccurrenceCount: 2, LastRunDate: &lastRun, NextRunDate: &nextRun, IsActive: false,
+	}
+	mockRuleRepo.On("FindByID", int64(9)).Return(rule, nil)
+	mockRuleRepo.On("Update", rule).Return(nil)
+
+	active := true
+	resp, err := svc.UpdateRule(userID, 9, &request.UpdateRecurringRuleRequest{IsActive: &active})
+	assert.NoError(t, err)
+	assert.Equal(t, date(2024, 7, 1), *resp.NextRunDate)
+	assert.Equal(t, 2, rule.OccurrenceCount)
+
+	// 之后的修改保持顺延后的进度
+	name := "房租"
+	resp, err = svc.UpdateRule(userID, 9, &request.UpdateRecurringRuleRequest{Name: &name})
+	assert.NoError(t, err)
+	assert.Equal(t, date(2024, 7, 1), *resp.NextRunDate)
+}
+
+// TestRecurringRuleCurrencyFollowsAccount 测试未指定币种的规则沿用账户币种，更换账户时重新校验，转账两端币种须一致
+func TestRecurringRuleCurrencyFollowsAccount(t *testing.T) {
+	svc, mockRuleRepo, _, mockAccountRepo := newTestRecurringService()
+
+	userID := int64(1)
+	usdAccount, eurAccount := int64(100), int64(101)
+	mockAccountRepo.On("FindByID", usdAccount).Return(&models.Account{ID: usdAccount, UserID: userID, Currency: "USD"}, nil)
+	mockAccountRepo.On("FindByID", eurAccount).Return(&models.Account{ID: eurAccount, UserID: userID, Currency: "EUR"}, nil)
+	var created *models.RecurringRule
+	mockRuleRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.RecurringRule)
+		created.ID = 9
+	}).Return(nil)
+
+	resp, err := svc.CreateRule(userID, &request.CreateRecurringRuleRequest{
+		Name: "房租", Type: "expense", AccountID: &usdAccount, Amount: money.MustParse("1200.00"),
+		Frequency: models.RecurringFrequencyMonthly, StartDate: date(2024, 1, 1),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", resp.Currency)
+
+	_, err = svc.CreateRule(userID, &request.CreateRecurringRuleRequest{
+		Name: "换汇", Type: "transfer", AccountID: &usdAccount, ToAccountID: &eurAccount, Amount: money.MustParse("100.00"),
+		Frequency: models.RecurringFrequencyMonthly, StartDate: date(2024, 1, 1),
+	})
+	assert.Equal(t, errTransferCurrency, err)
+
+	mockRuleRepo.On("FindByID", int64(9)).Return(created, nil)
+	mockRuleRepo.On("Update", mock.Anything).Return(nil)
+	_, err = svc.UpdateRule(userID, 9, &request.UpdateRecurringRuleRequest{AccountID: &eurAccount})
+	assert.Equal(t, errCurrencyMismatch, err)
+
+	created.AccountID, created.Currency = &usdAccount, "USD"
+	currency := "EUR"
+	resp, err = svc.UpdateRule(userID, 9, &request.UpdateRecurringRuleRequest{AccountID: &eurAccount, Currency: &currency})
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", resp.Currency)
+}
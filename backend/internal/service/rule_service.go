@@ -0,0 +1,487 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+const (
+	ruleScanLimit   = 5000 // 预览或追溯执行时最多检查的交易笔数
+	ruleChangeLimit = 200  // 结果中返回的修改明细条数
+)
+
+var (
+	errRuleNotFound     = errors.New("交易规则不存在")
+	errRuleNoCondition  = errors.New("请至少设置一个匹配条件")
+	errRuleNoAction     = errors.New("请至少设置一个执行动作")
+	errRuleAmountRange  = errors.New("最小金额不能大于最大金额")
+	errRuleAccount      = errors.New("账户不存在")
+	errRuleCategory     = errors.New("分类不存在")
+	errRuleCategoryType = errors.New("分类的收支类型与规则的交易类型不一致")
+)
+
+// TransactionRuleApplier 创建交易前加载用户已启用的交易规则
+type TransactionRuleApplier interface {
+	EnabledRules(userID int64) (TransactionRules, error)
+}
+
+// TransactionRuleService 交易规则服务接口
+type TransactionRuleService interface {
+	TransactionRuleApplier
+	GetRules(userID int64) ([]*response.TransactionRuleResponse, error)
+	CreateRule(userID int64, req *request.TransactionRuleRequest) (*response.TransactionRuleResponse, error)
+	UpdateRule(userID int64, ruleID int64, req *request.TransactionRuleRequest) (*response.TransactionRuleResponse, error)
+	DeleteRule(userID int64, ruleID int64) error
+	// PreviewRule 列出规则对已有交易将做的修改，不写入数据
+	PreviewRule(userID int64, ruleID int64, req *request.ApplyTransactionRuleRequest) (*response.RuleApplyResponse, error)
+	// ApplyRule 对已有交易追溯执行规则，分类以规则为准
+	ApplyRule(userID int64, ruleID int64, req *request.ApplyTransactionRuleRequest) (*response.RuleApplyResponse, error)
+}
+
+type transactionRuleService struct {
+	ruleRepo        repository.TransactionRuleRepository
+	transactionRepo repository.TransactionRepository
+	accountRepo     repository.AccountRepository
+	categoryRepo    repository.CategoryRepository
+	uow             repository.UnitOfWork
+	suggestions     CategoryModelInvalidator // 可选，追溯执行规则后丢弃分类建议模型
+}
+
+// NewTransactionRuleService 创建交易规则服务实例
+func NewTransactionRuleService(
+	ruleRepo repository.TransactionRuleRepository,
+	transactionRepo repository.TransactionRepository,
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+	uow repository.UnitOfWork,
+	suggestions CategoryModelInvalidator,
+) TransactionRuleService {
+	return &transactionRuleService{
+		ruleRepo:        ruleRepo,
+		transactionRepo: transactionRepo,
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+		uow:             uow,
+		suggestions:     suggestions,
+	}
+}
+
+// EnabledRules 获取已启用的规则，按执行顺序排列
+func (s *transactionRuleService) EnabledRules(userID int64) (TransactionRules, error) {
+	rules, err := s.ruleRepo.FindByUserID(userID, true)
+	if err != nil {
+		return nil, err
+	}
+	return TransactionRules(rules), nil
+}
+
+// GetRules 获取全部交易规则
+func (s *transactionRuleService) GetRules(userID int64) ([]*response.TransactionRuleResponse, error) {
+	rules, err := s.ruleRepo.FindByUserID(userID, false)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][交易规则] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	result := make([]*response.TransactionRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toTransactionRuleResponse(rule))
+	}
+	return result, nil
+}
+
+// CreateRule 创建交易规则
+func (s *transactionRuleService) CreateRule(userID int64, req *request.TransactionRuleRequest) (*response.TransactionRuleResponse, error) {
+	rule := &models.TransactionRule{UserID: userID}
+	if err := s.applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(rule); err != nil {
+		logger.Error(fmt.Sprintf("[Service][交易规则] 创建失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][交易规则] 创建成功 | 用户ID: %d | 规则ID: %d", userID, rule.ID))
+	return toTransactionRuleResponse(rule), nil
+}
+
+// UpdateRule 更新交易规则
+func (s *transactionRuleService) UpdateRule(userID int64, ruleID int64, req *request.TransactionRuleRequest) (*response.TransactionRuleResponse, error) {
+	rule, err := s.findUserRule(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(rule); err != nil {
+		logger.Error(fmt.Sprintf("[Service][交易规则] 更新失败 | 用户ID: %d | 规则ID: %d | 错误: %v", userID, ruleID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][交易规则] 更新成功 | 用户ID: %d | 规则ID: %d", userID, ruleID))
+	return toTransactionRuleResponse(rule), nil
+}
+
+// DeleteRule 删除交易规则，已执行过的修改保留
+func (s *transactionRuleService) DeleteRule(userID int64, ruleID int64) error {
+	if _, err := s.findUserRule(userID, ruleID); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Delete(ruleID); err != nil {
+		logger.Error(fmt.Sprintf("[Service][交易规则] 删除失败 | 用户ID: %d | 规则ID: %d | 错误: %v", userID, ruleID, err))
+		return err
+	}
+	logger.Info(fmt.Sprintf("[Service][交易规则] 删除成功 | 用户ID: %d | 规则ID: %d", userID, ruleID))
+	return nil
+}
+
+// PreviewRule 预览规则对已有交易的修改
+func (s *transactionRuleService) PreviewRule(userID int64, ruleID int64, req *request.ApplyTransactionRuleRequest) (*response.RuleApplyResponse, error) {
+	rule, err := s.findUserRule(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := s.scan(userID, rule, req)
+	return result, err
+}
+
+// ApplyRule 追溯执行规则，全部修改在同一事务中写入
+func (s *transactionRuleService) ApplyRule(userID int64, ruleID int64, req *request.ApplyTransactionRuleRequest) (*response.RuleApplyResponse, error) {
+	rule, err := s.findUserRule(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	result, changed, err := s.scan(userID, rule, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changed) > 0 {
+		err = s.uow.Transaction(func(repos *repository.TxRepositories) error {
+			for _, transaction := range changed {
+				if err := repos.Transactions.UpdateClassification(transaction); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("[Service][交易规则] 追溯执行失败 | 用户ID: %d | 规则ID: %d | 错误: %v", userID, ruleID, err))
+			return nil, err
+		}
+		if s.suggestions != nil {
+			s.suggestions.InvalidateModel(userID)
+		}
+	}
+
+	logger.Info(fmt.Sprintf("[Service][交易规则] 追溯执行成功 | 用户ID: %d | 规则ID: %d | 匹配: %d | 修改: %d", userID, ruleID, result.Matched, result.ChangedCount))
+	return result, nil
+}
+
+// scan 在已有交易中查找匹配规则的交易，返回结果及需要写入的交易（已修改分类、标签和标题）
+func (s *transactionRuleService) scan(userID int64, rule *models.TransactionRule, req *request.ApplyTransactionRuleRequest) (*response.RuleApplyResponse, []*models.Transaction, error) {
+	filters := &request.ListTransactionRequest{
+		Type:      rule.TransactionType,
+		AccountID: rule.AccountID,
+		StartDate: req.StartDate,
+	}
+	if filters.StartDate.IsZero() {
+		filters.StartDate = time.Now().AddDate(-1, 0, 0)
+	}
+	// 从最近的交易开始检查，多查一条用于判断是否超出检查上限
+	transactions, err := s.transactionRepo.FindLatestByUserID(userID, filters, ruleScanLimit+1)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][交易规则] 查询交易失败 | 用户ID: %d | 规则ID: %d | 错误: %v", userID, rule.ID, err))
+		return nil, nil, err
+	}
+	truncated := len(transactions) > ruleScanLimit
+	if truncated {
+		transactions = transactions[:ruleScanLimit]
+	}
+
+	result := &response.RuleApplyResponse{
+		RuleID:    rule.ID,
+		Scanned:   len(transactions),
+		Truncated: truncated,
+		Changes:   []*response.RuleChangeResponse{},
+	}
+	var changed []*models.Transaction
+	for _, transaction := range transactions {
+		if !ruleMatches(rule, transactionSubject(transaction)) {
+			continue
+		}
+		result.Matched++
+		change := applyRuleToTransaction(rule, transaction)
+		if change == nil {
+			continue
+		}
+		changed = append(changed, transaction)
+		if len(result.Changes) < ruleChangeLimit {
+			result.Changes = append(result.Changes, change)
+		}
+	}
+	result.ChangedCount = len(changed)
+	return result, changed, nil
+}
+
+// applyRuleRequest 校验并写入规则字段
+func (s *transactionRuleService) applyRuleRequest(rule *models.TransactionRule, req *request.TransactionRuleRequest) error {
+	transType := req.TransactionType
+	titleKeywords := normalizeKeywords(req.TitleContains)
+	descriptionKeywords := normalizeKeywords(req.DescriptionContains)
+	locationKeywords := normalizeKeywords(req.LocationContains)
+	tags := uniqueTags(nil, req.AddTags)
+	renameTitle := strings.TrimSpace(req.RenameTitle)
+
+	if transType == "" && titleKeywords == "" && descriptionKeywords == "" && locationKeywords == "" &&
+		req.MinAmount == nil && req.MaxAmount == nil && req.AccountID == nil {
+		return errRuleNoCondition
+	}
+	if req.CategoryID == nil && len(tags) == 0 && renameTitle == "" {
+		return errRuleNoAction
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return errRuleAmountRange
+	}
+	if req.AccountID != nil {
+		account, err := s.accountRepo.FindByID(*req.AccountID)
+		if err != nil || account.UserID != rule.UserID {
+			return errRuleAccount
+		}
+	}
+	// 设置分类的规则只匹配同一收支类型的交易
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.FindByID(*req.CategoryID)
+		if err != nil || (category.UserID != rule.UserID && category.UserID != 0) || !category.IsActive {
+			return errRuleCategory
+		}
+		if transType == "" {
+			transType = category.Type
+		}
+		if transType != category.Type {
+			return errRuleCategoryType
+		}
+	}
+
+	rule.Name = req.Name
+	rule.Priority = req.Priority
+	rule.IsActive = req.IsActive == nil || *req.IsActive
+	rule.TransactionType = transType
+	rule.TitleContains = titleKeywords
+	rule.DescriptionContains = descriptionKeywords
+	rule.LocationContains = locationKeywords
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.AccountID = req.AccountID
+	rule.CategoryID = req.CategoryID
+	rule.AddTags = models.JSONArray(tags)
+	rule.RenameTitle = renameTitle
+	return nil
+}
+
+// findUserRule 查找并校验规则归属
+func (s *transactionRuleService) findUserRule(userID int64, ruleID int64) (*models.TransactionRule, error) {
+	rule, err := s.ruleRepo.FindByID(ruleID)
+	if err != nil || rule.UserID != userID {
+		return nil, errRuleNotFound
+	}
+	return rule, nil
+}
+
+// TransactionRules 按执行顺序排列的交易规则
+type TransactionRules []*models.TransactionRule
+
+// Apply 对待创建的交易依次执行匹配的规则，条件均按交易的原始内容判断。
+// 只为未指定分类的交易设置分类（转账、拆分和退款除外），标签累加，标题以先执行的规则为准
+func (rules TransactionRules) Apply(req *request.CreateTransactionRequest) {
+	subject := ruleSubject{
+		Type:        req.Type,
+		Title:       req.Title,
+		Description: req.Description,
+		Location:    req.Location,
+		Amount:      req.Amount,
+		AccountID:   req.AccountID,
+	}
+	categorized := req.CategoryID != nil || req.Type == "transfer" || len(req.Splits) > 0 || req.RefundOfID != nil
+	renamed := false
+	for _, rule := range rules {
+		if !ruleMatches(rule, subject) {
+			continue
+		}
+		if rule.CategoryID != nil && !categorized {
+			categoryID := *rule.CategoryID
+			req.CategoryID = &categoryID
+			categorized = true
+		}
+		req.Tags = uniqueTags(req.Tags, rule.AddTags)
+		if rule.RenameTitle != "" && !renamed {
+			req.Title = rule.RenameTitle
+			renamed = true
+		}
+	}
+}
+
+// ruleSubject 规则匹配使用的交易字段
+type ruleSubject struct {
+	Type        string
+	Title       string
+	Description string
+	Location    string
+	Amount      money.Money
+	AccountID   *int64
+}
+
+// transactionSubject 取已有交易的匹配字段
+func transactionSubject(transaction *models.Transaction) ruleSubject {
+	return ruleSubject{
+		Type:        transaction.Type,
+		Title:       transaction.Title,
+		Description: transaction.Description,
+		Location:    transaction.Location,
+		Amount:      transaction.Amount,
+		AccountID:   transaction.AccountID,
+	}
+}
+
+// ruleMatches 判断交易是否满足规则的全部条件，金额按交易币种比较
+func ruleMatches(rule *models.TransactionRule, subject ruleSubject) bool {
+	if rule.TransactionType != "" && rule.TransactionType != subject.Type {
+		return false
+	}
+	if rule.AccountID != nil && (subject.AccountID == nil || *subject.AccountID != *rule.AccountID) {
+		return false
+	}
+	if rule.MinAmount != nil && subject.Amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && subject.Amount > *rule.MaxAmount {
+		return false
+	}
+	return containsKeyword(subject.Title, rule.TitleContains) &&
+		containsKeyword(subject.Description, rule.DescriptionContains) &&
+		containsKeyword(subject.Location, rule.LocationContains)
+}
+
+// applyRuleToTransaction 对已有交易执行规则动作，分类以规则为准；没有任何修改时返回 nil
+func applyRuleToTransaction(rule *models.TransactionRule, transaction *models.Transaction) *response.RuleChangeResponse {
+	change := &response.RuleChangeResponse{
+		TransactionID:   transaction.ID,
+		TransactionDate: transaction.TransactionDate,
+		Type:            transaction.Type,
+		Amount:          transaction.Amount,
+		Currency:        transaction.Currency,
+		Title:           transaction.Title,
+		CategoryID:      transaction.CategoryID,
+		AddedTags:       []string{},
+	}
+	modified := false
+
+	// 拆分交易的分类由明细决定、退款沿用原支出的分类，均不修改
+	if rule.CategoryID != nil && transaction.Type != "transfer" && len(transaction.Splits) == 0 && transaction.RefundOfID == nil &&
+		(transaction.CategoryID == nil || *transaction.CategoryID != *rule.CategoryID) {
+		categoryID := *rule.CategoryID
+		transaction.CategoryID = &categoryID
+		change.NewCategoryID = &categoryID
+		modified = true
+	}
+	existing := make(map[string]bool, len(transaction.Tags))
+	for _, tag := range transaction.Tags {
+		existing[tag] = true
+	}
+	for _, tag := range rule.AddTags {
+		if !existing[tag] {
+			existing[tag] = true
+			change.AddedTags = append(change.AddedTags, tag)
+		}
+	}
+	if len(change.AddedTags) > 0 {
+		transaction.Tags = append(transaction.Tags, change.AddedTags...)
+		modified = true
+	}
+	if rule.RenameTitle != "" && transaction.Title != rule.RenameTitle {
+		transaction.Title = rule.RenameTitle
+		change.NewTitle = rule.RenameTitle
+		modified = true
+	}
+
+	if !modified {
+		return nil
+	}
+	return change
+}
+
+// containsKeyword 判断文本是否包含任一关键词（不区分大小写），未设置关键词时视为满足
+func containsKeyword(text, keywords string) bool {
+	if keywords == "" {
+		return true
+	}
+	text = strings.ToLower(text)
+	for _, keyword := range strings.Split(strings.ToLower(keywords), "|") {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKeywords 去除关键词两端空白和空关键词
+func normalizeKeywords(keywords string) string {
+	parts := make([]string, 0)
+	for _, keyword := range strings.Split(keywords, "|") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			parts = append(parts, keyword)
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// uniqueTags 将 added 中尚未存在的标签追加到 tags 之后
+func uniqueTags(tags []string, added []string) []string {
+	seen := make(map[string]bool, len(tags)+len(added))
+	result := make([]string, 0, len(tags)+len(added))
+	for _, tag := range append(append([]string{}, tags...), added...) {
+		if tag = strings.TrimSpace(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// toTransactionRuleResponse 转换为交易规则响应
+func toTransactionRuleResponse(rule *models.TransactionRule) *response.TransactionRuleResponse {
+	tags := []string(rule.AddTags)
+	if tags == nil {
+		tags = []string{}
+	}
+	return &response.TransactionRuleResponse{
+		ID:                  rule.ID,
+		Name:                rule.Name,
+		Priority:            rule.Priority,
+		IsActive:            rule.IsActive,
+		TransactionType:     rule.TransactionType,
+		TitleContains:       rule.TitleContains,
+		DescriptionContains: rule.DescriptionContains,
+		LocationContains:    rule.LocationContains,
+		MinAmount:           rule.MinAmount,
+		MaxAmount:           rule.MaxAmount,
+		AccountID:           rule.AccountID,
+		CategoryID:          rule.CategoryID,
+		AddTags:             tags,
+		RenameTitle:         rule.RenameTitle,
+		CreatedAt:           rule.CreatedAt,
+		UpdatedAt:           rule.UpdatedAt,
+	}
+}
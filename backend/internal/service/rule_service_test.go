@@ -0,0 +1,276 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/importer"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransactionRuleRepository Mock TransactionRuleRepository
+type MockTransactionRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRuleRepository) Create(rule *models.TransactionRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRuleRepository) FindByID(id int64) (*models.TransactionRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TransactionRule), args.Error(1)
+}
+
+func (m *MockTransactionRuleRepository) FindByUserID(userID int64, activeOnly bool) ([]*models.TransactionRule, error) {
+	args := m.Called(userID, activeOnly)
+	return args.Get(0).([]*models.TransactionRule), args.Error(1)
+}
+
+func (m *MockTransactionRuleRepository) Update(rule *models.TransactionRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRuleRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func moneyPtr(value string) *money.Money {
+	amount := money.MustParse(value)
+	return &amount
+}
+
+// TestTransactionRulesApply 测试按顺序执行规则：分类只在未指定时设置，标签去重累加，标题以先执行的规则为准
+func TestTransactionRulesApply(t *testing.T) {
+	rules := TransactionRules{
+		{ID: 1, TransactionType: "expense", TitleContains: "星巴克|starbucks", CategoryID: int64Ptr(10), AddTags: models.JSONArray{"咖啡"}, RenameTitle: "星巴克"},
+		{ID: 2, TransactionType: "expense", MinAmount: moneyPtr("100.00"), CategoryID: int64Ptr(20), AddTags: models.JSONArray{"大额", "咖啡"}, RenameTitle: "大额支出"},
+		{ID: 3, LocationContains: "机场"},
+	}
+
+	req := &request.CreateTransactionRequest{Type: "expense", Title: "STARBUCKS 国贸店", Amount: money.MustParse("120.00"), Tags: []string{"工作"}}
+	rules.Apply(req)
+	assert.Equal(t, int64(10), *req.CategoryID)
+	assert.Equal(t, []string{"工作", "咖啡", "大额"}, req.Tags)
+	assert.Equal(t, "星巴克", req.Title)
+
+	// 用户已选择分类时保留，其余动作照常执行
+	req = &request.CreateTransactionRequest{Type: "expense", Title: "午餐", Amount: money.MustParse("150.00"), CategoryID: int64Ptr(30)}
+	rules.Apply(req)
+	assert.Equal(t, int64(30), *req.CategoryID)
+	assert.Equal(t, "大额支出", req.Title)
+
+	// 拆分交易不设置分类
+	req = &request.CreateTransactionRequest{Type: "expense", Title: "starbucks", Amount: money.MustParse("30.00"), Splits: []request.SplitRequest{{}, {}}}
+	rules.Apply(req)
+	assert.Nil(t, req.CategoryID)
+	assert.Equal(t, []string{"咖啡"}, req.Tags)
+
+	req = &request.CreateTransactionRequest{Type: "income", Title: "starbucks 报销", Amount: money.MustParse("300.00")}
+	rules.Apply(req)
+	assert.Nil(t, req.CategoryID)
+	assert.Equal(t, "starbucks 报销", req.Title)
+}
+
+// TestCreateTransactionAppliesRules 测试记账时执行已启用的规则，导入提交时不再重复执行
+func TestCreateTransactionAppliesRules(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockRuleRepo := new(MockTransactionRuleRepository)
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}
+	rules := NewTransactionRuleService(mockRuleRepo, mockTransRepo, mockAccountRepo, mockCategoryRepo, uow, nil)
+	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo, uow, nil, nil, nil, rules, nil)
+
+	userID := int64(1)
+	accountID := int64(100)
+	account := &models.Account{ID: accountID, UserID: userID, Currency: "CNY", Balance: money.MustParse("500.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockCategoryRepo.On("FindByID", int64(10)).Return(&models.Category{ID: 10, UserID: userID, Type: "expense"}, nil)
+	mockRuleRepo.On("FindByUserID", userID, true).Return([]*models.TransactionRule{
+		{ID: 1, TransactionType: "expense", TitleContains: "滴滴", CategoryID: int64Ptr(10), AddTags: models.JSONArray{"通勤"}},
+	}, nil)
+
+	var created *models.Transaction
+	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.Transaction)
+		created.ID = 1
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(1)).Return(&models.Transaction{ID: 1, UserID: userID, Type: "expense"}, nil)
+
+	_, err := service.CreateTransaction(userID, &request.CreateTransactionRequest{
+		Type:            "expense",
+		AccountID:       &accountID,
+		Amount:          money.MustParse("25.00"),
+		Title:           "滴滴快车",
+		TransactionDate: date(2024, 3, 1),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), *created.CategoryID)
+	assert.Equal(t, models.JSONArray{"通勤"}, created.Tags)
+
+	_, err = service.CreateTransaction(userID, &request.CreateTransactionRequest{
+		Type:            "expense",
+		AccountID:       &accountID,
+		Amount:          money.MustParse("25.00"),
+		Title:           "滴滴快车",
+		TransactionDate: date(2024, 3, 2),
+		RulesApplied:    true,
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, created.CategoryID)
+	mockRuleRepo.AssertNumberOfCalls(t, "FindByUserID", 1)
+}
+
+// TestCreateRuleValidation 测试规则至少包含一个条件和一个动作，交易类型默认取分类的收支类型
+func TestCreateRuleValidation(t *testing.T) {
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockRuleRepo := new(MockTransactionRuleRepository)
+	service := NewTransactionRuleService(mockRuleRepo, new(MockTransactionRepository), new(MockAccountRepository), mockCategoryRepo, nil, nil)
+
+	mockCategoryRepo.On("FindByID", int64(10)).Return(&models.Category{ID: 10, Type: "expense", IsSystem: true, IsActive: true}, nil)
+	mockCategoryRepo.On("FindByID", int64(20)).Return(&models.Category{ID: 20, UserID: 2, Type: "expense", IsActive: true}, nil)
+	mockRuleRepo.On("Create", mock.Anything).Return(nil)
+
+	_, err := service.CreateRule(1, &request.TransactionRuleRequest{Name: "空", TitleContains: " | ", CategoryID: int64Ptr(10)})
+	assert.Equal(t, errRuleNoCondition, err)
+
+	_, err = service.CreateRule(1, &request.TransactionRuleRequest{Name: "空", TitleContains: "地铁"})
+	assert.Equal(t, errRuleNoAction, err)
+
+	_, err = service.CreateRule(1, &request.TransactionRuleRequest{Name: "金额", MinAmount: moneyPtr("100.00"), MaxAmount: moneyPtr("10.00"), AddTags: []string{"x"}})
+	assert.Equal(t, errRuleAmountRange, err)
+
+	_, err = service.CreateRule(1, &request.TransactionRuleRequest{Name: "工资", TransactionType: "income", TitleContains: "工资", CategoryID: int64Ptr(10)})
+	assert.Equal(t, errRuleCategoryType, err)
+
+	_, err = service.CreateRule(1, &request.TransactionRuleRequest{Name: "他人分类", TitleContains: "地铁", CategoryID: int64Ptr(20)})
+	assert.Equal(t, errRuleCategory, err)
+
+	rule, err := service.CreateRule(1, &request.TransactionRuleRequest{Name: "地铁", TitleContains: " 地铁 || metro ", CategoryID: int64Ptr(10), AddTags: []string{"通勤", "通勤"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "expense", rule.TransactionType)
+	assert.Equal(t, "地铁|metro", rule.TitleContains)
+	assert.Equal(t, []string{"通勤"}, rule.AddTags)
+	assert.True(t, rule.IsActive)
+}
+
+// TestPreviewAndApplyRule 测试对已有交易预览和追溯执行规则：分类以规则为准，拆分交易不改分类，无修改的交易不写入，
+// 执行后丢弃分类建议模型
+func TestPreviewAndApplyRule(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockRuleRepo := new(MockTransactionRuleRepository)
+	mockSuggestions := new(MockCategorySuggestionService)
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: new(MockAccountRepository)}
+	service := NewTransactionRuleService(mockRuleRepo, mockTransRepo, new(MockAccountRepository), new(MockCategoryRepository), uow, mockSuggestions)
+
+	userID := int64(1)
+	mockRuleRepo.On("FindByID", int64(5)).Return(&models.TransactionRule{
+		ID: 5, UserID: userID, TransactionType: "expense", TitleContains: "盒马", CategoryID: int64Ptr(10), AddTags: models.JSONArray{"买菜"},
+	}, nil)
+	mockRuleRepo.On("FindByID", int64(6)).Return(&models.TransactionRule{ID: 6, UserID: 2}, nil)
+	transactions := func() []*models.Transaction {
+		return []*models.Transaction{
+			{ID: 1, Type: "expense", Title: "盒马鲜生", CategoryID: int64Ptr(3)},
+			{ID: 2, Type: "expense", Title: "盒马", CategoryID: int64Ptr(10), Tags: models.JSONArray{"买菜"}},
+			{ID: 3, Type: "expense", Title: "盒马", Splits: []*models.TransactionSplit{{ID: 1}, {ID: 2}}},
+			{ID: 4, Type: "expense", Title: "全家"},
+		}
+	}
+	startDate := date(2024, 1, 1)
+	filters := &request.ListTransactionRequest{Type: "expense", StartDate: startDate}
+	mockTransRepo.On("FindLatestByUserID", userID, filters, ruleScanLimit+1).Return(transactions(), nil).Once()
+	mockTransRepo.On("FindLatestByUserID", userID, filters, ruleScanLimit+1).Return(transactions(), nil).Once()
+	mockTransRepo.On("UpdateClassification", mock.Anything).Return(nil)
+	mockSuggestions.On("InvalidateModel", userID).Return()
+
+	_, err := service.PreviewRule(userID, 6, &request.ApplyTransactionRuleRequest{})
+	assert.Equal(t, errRuleNotFound, err)
+
+	preview, err := service.PreviewRule(userID, 5, &request.ApplyTransactionRuleRequest{StartDate: startDate})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, preview.Scanned)
+	assert.False(t, preview.Truncated)
+	assert.Equal(t, 3, preview.Matched)
+	assert.Equal(t, 2, preview.ChangedCount)
+	assert.Equal(t, int64(10), *preview.Changes[0].NewCategoryID)
+	assert.Equal(t, []string{"买菜"}, preview.Changes[0].AddedTags)
+	assert.Nil(t, preview.Changes[1].NewCategoryID)
+	mockTransRepo.AssertNotCalled(t, "UpdateClassification", mock.Anything)
+	mockSuggestions.AssertNotCalled(t, "InvalidateModel", mock.Anything)
+
+	result, err := service.ApplyRule(userID, 5, &request.ApplyTransactionRuleRequest{StartDate: startDate})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.ChangedCount)
+	mockTransRepo.AssertCalled(t, "UpdateClassification", mock.MatchedBy(func(tr *models.Transaction) bool {
+		return tr.ID == 1 && *tr.CategoryID == 10
+	}))
+	mockTransRepo.AssertNumberOfCalls(t, "UpdateClassification", 2)
+	mockSuggestions.AssertNumberOfCalls(t, "InvalidateModel", 1)
+}
+
+// TestPreviewRuleTruncated 测试交易笔数超出检查上限时只检查最近的交易并标记结果不完整
+func TestPreviewRuleTruncated(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockRuleRepo := new(MockTransactionRuleRepository)
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: new(MockAccountRepository)}
+	service := NewTransactionRuleService(mockRuleRepo, mockTransRepo, new(MockAccountRepository), new(MockCategoryRepository), uow, nil)
+
+	userID := int64(1)
+	mockRuleRepo.On("FindByID", int64(5)).Return(&models.TransactionRule{
+		ID: 5, UserID: userID, TransactionType: "expense", TitleContains: "盒马", CategoryID: int64Ptr(10),
+	}, nil)
+	transactions := make([]*models.Transaction, ruleScanLimit+1)
+	for i := range transactions {
+		transactions[i] = &models.Transaction{ID: int64(len(transactions) - i), Type: "expense", Title: "盒马"}
+	}
+	mockTransRepo.On("FindLatestByUserID", userID, mock.Anything, ruleScanLimit+1).Return(transactions, nil)
+
+	preview, err := service.PreviewRule(userID, 5, &request.ApplyTransactionRuleRequest{StartDate: date(2020, 1, 1)})
+	assert.NoError(t, err)
+	assert.True(t, preview.Truncated)
+	assert.Equal(t, ruleScanLimit, preview.Scanned)
+	assert.Equal(t, ruleScanLimit, preview.ChangedCount)
+	assert.Equal(t, int64(ruleScanLimit+1), preview.Changes[0].TransactionID)
+}
+
+// TestImportResolverAppliesRules 测试导入预览时文件中的分类优先于规则，规则优先于默认分类
+func TestImportResolverAppliesRules(t *testing.T) {
+	accountID := int64(100)
+	resolver := &importResolver{
+		accounts:          map[string]int64{},
+		cardSuffixes:      map[string]int64{},
+		categories:        map[string]int64{"expense|餐饮": 7},
+		defaultAccountID:  &accountID,
+		defaultCategoryID: int64Ptr(9),
+		rules: TransactionRules{
+			{ID: 1, TransactionType: "expense", TitleContains: "美团", CategoryID: int64Ptr(8), AddTags: models.JSONArray{"外卖"}},
+		},
+	}
+	row := func(title, category string) *importer.Row {
+		return &importer.Row{Line: 2, Record: &importer.Record{
+			Type: "expense", Date: date(2024, 3, 1), Amount: money.MustParse("30.00"), Title: title, Category: category,
+		}}
+	}
+
+	preview, _ := resolver.resolve(row("美团外卖", "餐饮"))
+	assert.Equal(t, int64(7), *preview.CategoryID)
+	assert.Equal(t, []string{"外卖"}, preview.Tags)
+
+	preview, _ = resolver.resolve(row("美团外卖", ""))
+	assert.Equal(t, int64(8), *preview.CategoryID)
+
+	preview, _ = resolver.resolve(row("超市", ""))
+	assert.Equal(t, int64(9), *preview.CategoryID)
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+)
+
+// 存取记录默认返回条数
+const savingsRecordLimit = 100
+
+var (
+	errSavingsPlanNotFound      = errors.New("储蓄计划不存在")
+	errSavingsPlanCancelled     = errors.New("储蓄计划已取消")
+	errSavingsInsufficient      = errors.New("取款金额超过计划已存金额")
+	errSavingsSameAccount       = errors.New("转出与转入账户不能相同")
+	errSavingsAccountRequired   = errors.New("储蓄计划未关联账户")
+	errSavingsInvalidTargetDate = errors.New("目标日期不能早于开始日期")
+)
+
+// SavingsService 储蓄计划服务接口
+type SavingsService interface {
+	GetPlans(userID int64, status string) ([]*response.SavingsPlanResponse, error)
+	GetPlan(userID int64, planID int64) (*response.SavingsPlanResponse, error)
+	CreatePlan(userID int64, req *request.CreateSavingsPlanRequest) (*response.SavingsPlanResponse, error)
+	UpdatePlan(userID int64, planID int64, req *request.UpdateSavingsPlanRequest) (*response.SavingsPlanResponse, error)
+	DeletePlan(userID int64, planID int64) error
+	GetRecords(userID int64, planID int64) ([]*response.SavingsRecordResponse, error)
+	// Deposit 从来源账户转账存入计划关联账户
+	Deposit(userID int64, planID int64, req *request.SavingsDepositRequest) (*response.SavingsOperationResponse, error)
+	// Withdraw 从计划关联账户转账取出到目标账户
+	Withdraw(userID int64, planID int64, req *request.SavingsWithdrawRequest) (*response.SavingsOperationResponse, error)
+}
+
+type savingsService struct {
+	savingsRepo        repository.SavingsRepository
+	accountRepo        repository.AccountRepository
+	transactionService TransactionService
+}
+
+// NewSavingsService 创建储蓄计划服务实例
+func NewSavingsService(
+	savingsRepo repository.SavingsRepository,
+	accountRepo repository.AccountRepository,
+	transactionService TransactionService,
+) SavingsService {
+	return &savingsService{
+		savingsRepo:        savingsRepo,
+		accountRepo:        accountRepo,
+		transactionService: transactionService,
+	}
+}
+
+// GetPlans 获取储蓄计划列表
+func (s *savingsService) GetPlans(userID int64, status string) ([]*response.SavingsPlanResponse, error) {
+	plans, err := s.savingsRepo.FindByUserID(userID, status)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][储蓄] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*response.SavingsPlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		result = append(result, s.toSavingsPlanResponse(plan, now))
+	}
+	return result, nil
+}
+
+// GetPlan 获取储蓄计划详情
+func (s *savingsService) GetPlan(userID int64, planID int64) (*response.SavingsPlanResponse, error) {
+	plan, err := s.findUserPlan(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toSavingsPlanResponse(plan, time.Now()), nil
+}
+
+// CreatePlan 创建储蓄计划
+func (s *savingsService) CreatePlan(userID int64, req *request.CreateSavingsPlanRequest) (*response.SavingsPlanResponse, error) {
+	if err := s.validateAccount(userID, req.AccountID); err != nil {
+		return nil, err
+	}
+
+	accountID := req.AccountID
+	plan := &models.SavingsPlan{
+		UserID:         userID,
+		PlanName:       req.PlanName,
+		PlanType:       req.PlanType,
+		TargetAmount:   req.TargetAmount,
+		TargetDate:     req.TargetDate,
+		Frequency:      req.Frequency,
+		PeriodicAmount: req.PeriodicAmount,
+		AccountID:      &accountID,
+		Icon:           req.Icon,
+		Color:          req.Color,
+		Description:    req.Description,
+		Status:         models.SavingsStatusActive,
+	}
+	plan.StartDate = truncateToDate(time.Now())
+	if req.StartDate != nil {
+		plan.StartDate = truncateToDate(*req.StartDate)
+	}
+	if plan.TargetDate != nil && plan.TargetDate.Before(plan.StartDate) {
+		return nil, errSavingsInvalidTargetDate
+	}
+
+	if err := s.savingsRepo.Create(plan); err != nil {
+		logger.Error(fmt.Sprintf("[Service][储蓄] 创建失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][储蓄] 创建成功 | 用户ID: %d | 计划ID: %d", userID, plan.ID))
+	created, err := s.savingsRepo.FindByID(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toSavingsPlanResponse(created, time.Now()), nil
+}
+
+// UpdatePlan 更新储蓄计划
+func (s *savingsService) UpdatePlan(userID int64, planID int64, req *request.UpdateSavingsPlanRequest) (*response.SavingsPlanResponse, error) {
+	plan, err := s.findUserPlan(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AccountID != nil {
+		if err := s.validateAccount(userID, *req.AccountID); err != nil {
+			return nil, err
+		}
+		plan.AccountID = req.AccountID
+		plan.Account = nil
+	}
+	if req.PlanName != nil {
+		plan.PlanName = *req.PlanName
+	}
+	if req.PlanType != nil {
+		plan.PlanType = *req.PlanType
+	}
+	if req.TargetAmount != nil {
+		plan.TargetAmount = *req.TargetAmount
+	}
+	if req.TargetDate != nil {
+		plan.TargetDate = req.TargetDate
+	}
+	if req.Frequency != nil {
+		plan.Frequency = req.Frequency
+	}
+	if req.PeriodicAmount != nil {
+		plan.PeriodicAmount = req.PeriodicAmount
+	}
+	if req.Icon != nil {
+		plan.Icon = *req.Icon
+	}
+	if req.Color != nil {
+		plan.Color = *req.Color
+	}
+	if req.Description != nil {
+		plan.Description = *req.Description
+	}
+	if req.Status != nil {
+		plan.Status = *req.Status
+	}
+	if plan.TargetDate != nil && plan.TargetDate.Before(plan.StartDate) {
+		return nil, errSavingsInvalidTargetDate
+	}
+	refreshSavingsCompletion(plan, time.Now())
+
+	if err := s.savingsRepo.Update(plan); err != nil {
+		logger.Error(fmt.Sprintf("[Service][储蓄] 更新失败 | 用户ID: %d | 计划ID: %d | 错误: %v", userID, planID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][储蓄] 更新成功 | 用户ID: %d | 计划ID: %d", userID, planID))
+	updated, err := s.savingsRepo.FindByID(planID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toSavingsPlanResponse(updated, time.Now()), nil
+}
+
+// DeletePlan 删除储蓄计划，已生成的转账交易与账户余额保持不变
+func (s *savingsService) DeletePlan(userID int64, planID int64) error {
+	if _, err := s.findUserPlan(userID, planID); err != nil {
+		return err
+	}
+	if err := s.savingsRepo.Delete(planID); err != nil {
+		logger.Error(fmt.Sprintf("[Service][储蓄] 删除失败 | 用户ID: %d | 计划ID: %d | 错误: %v", userID, planID, err))
+		return err
+	}
+	logger.Info(fmt.Sprintf("[Service][储蓄] 删除成功 | 用户ID: %d | 计划ID: %d", userID, planID))
+	return nil
+}
+
+// GetRecords 获取存取记录
+func (s *savingsService) GetRecords(userID int64, planID int64) ([]*response.SavingsRecordResponse, error) {
+	if _, err := s.findUserPlan(userID, planID); err != nil {
+		return nil, err
+	}
+	records, err := s.savingsRepo.FindRecordsByPlanID(planID, savingsRecordLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*response.SavingsRecordResponse, 0, len(records))
+	for _, record := range records {
+		result = append(result, s.toSavingsRecordResponse(record))
+	}
+	return result, nil
+}
+
+// Deposit 存款：生成 来源账户 -> 计划账户 的转账交易，并在同一事务中累加计划金额
+func (s *savingsService) Deposit(userID int64, planID int64, req *request.SavingsDepositRequest) (*response.SavingsOperationResponse, error) {
+	plan, err := s.findUserPlan(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Status == models.SavingsStatusCancelled {
+		return nil, errSavingsPlanCancelled
+	}
+	if plan.AccountID == nil {
+		return nil, errSavingsAccountRequired
+	}
+	if req.FromAccountID == *plan.AccountID {
+		return nil, errSavingsSameAccount
+	}
+
+	depositDate := truncateToDate(time.Now())
+	if req.DepositDate != nil {
+		depositDate = truncateToDate(*req.DepositDate)
+	}
+	fromAccountID := req.FromAccountID
+	transReq := &request.CreateTransactionRequest{
+		Type:            string(models.TransactionTypeTransfer),
+		AccountID:       &fromAccountID,
+		ToAccountID:     plan.AccountID,
+		Amount:          req.Amount,
+		Title:           fmt.Sprintf("储蓄存入：%s", plan.PlanName),
+		Description:     req.Notes,
+		TransactionDate: depositDate,
+	}
+
+	return s.applyOperation(userID, plan, transReq, func(locked *models.SavingsPlan) (*models.SavingsRecord, error) {
+		if locked.Status == models.SavingsStatusCancelled {
+			return nil, errSavingsPlanCancelled
+		}
+		locked.CurrentAmount += req.Amount
+		return &models.SavingsRecord{
+			RecordType:   models.SavingsRecordDeposit,
+			Amount:       req.Amount,
+			DepositDate:  depositDate,
+			PeriodNumber: req.PeriodNumber,
+			Notes:        req.Notes,
+		}, nil
+	})
+}
+
+// Withdraw 取款：生成 计划账户 -> 目标账户 的转账交易，并在同一事务中扣减计划金额
+func (s *savingsService) Withdraw(userID int64, planID int64, req *request.SavingsWithdrawRequest) (*response.SavingsOperationResponse, error) {
+	plan, err := s.findUserPlan(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.AccountID == nil {
+		return nil, errSavingsAccountRequired
+	}
+	if req.ToAccountID == *plan.AccountID {
+		return nil, errSavingsSameAccount
+	}
+	if req.Amount > plan.CurrentAmount {
+		return nil, errSavingsInsufficient
+	}
+
+	withdrawDate := truncateToDate(time.Now())
+	if req.WithdrawDate != nil {
+		withdrawDate = truncateToDate(*req.WithdrawDate)
+	}
+	toAccountID := req.ToAccountID
+	transReq := &request.CreateTransactionRequest{
+		Type:            string(models.TransactionTypeTransfer),
+		AccountID:       plan.AccountID,
+		ToAccountID:     &toAccountID,
+		Amount:          req.Amount,
+		Title:           fmt.Sprintf("储蓄取出：%s", plan.PlanName),
+		Description:     req.Notes,
+		TransactionDate: withdrawDate,
+	}
+
+	return s.applyOperation(userID, plan, transReq, func(locked *models.SavingsPlan) (*models.SavingsRecord, error) {
+		// 加锁后重新校验，防止并发取款超额
+		if req.Amount > locked.CurrentAmount {
+			return nil, errSavingsInsufficient
+		}
+		locked.CurrentAmount -= req.Amount
+		return &models.SavingsRecord{
+			RecordType:  models.SavingsRecordWithdraw,
+			Amount:      req.Amount,
+			DepositDate: withdrawDate,
+			Notes:       req.Notes,
+		}, nil
+	})
+}
+
+// applyOperation 在转账交易的同一事务中加锁更新计划金额并写入存取记录
+func (s *savingsService) applyOperation(
+	userID int64,
+	plan *models.SavingsPlan,
+	transReq *request.CreateTransactionRequest,
+	mutate func(locked *models.SavingsPlan) (*models.SavingsRecord, error),
+) (*response.SavingsOperationResponse, error) {
+	var record *models.SavingsRecord
+	var updated *models.SavingsPlan
+	transaction, err := s.transactionService.CreateLinkedTransaction(userID, transReq, func(repos *repository.TxRepositories, t *models.Transaction) error {
+		locked, err := repos.Savings.FindByIDForUpdate(plan.ID)
+		if err != nil {
+			return err
+		}
+		record, err = mutate(locked)
+		if err != nil {
+			return err
+		}
+		record.PlanID = locked.ID
+		record.TransactionID = &t.ID
+		if err := repos.Savings.CreateRecord(record); err != nil {
+			return err
+		}
+
+		refreshSavingsCompletion(locked, time.Now())
+		updated = locked
+		return repos.Savings.Update(locked)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][储蓄] 存取失败 | 用户ID: %d | 计划ID: %d | 错误: %v", userID, plan.ID, err))
+		for _, known := range []error{errSavingsPlanCancelled, errSavingsInsufficient} {
+			if errors.Is(err, known) {
+				return nil, known
+			}
+		}
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][储蓄] 存取成功 | 用户ID: %d | 计划ID: %d | 类型: %s | 金额: %s | 已存: %s",
+		userID, plan.ID, record.RecordType, record.Amount, updated.CurrentAmount))
+	updated.Account = plan.Account
+	return &response.SavingsOperationResponse{
+		Transaction: transaction,
+		Record:      s.toSavingsRecordResponse(record),
+		Plan:        s.toSavingsPlanResponse(updated, time.Now()),
+	}, nil
+}
+
+// findUserPlan 查找并校验储蓄计划归属
+func (s *savingsService) findUserPlan(userID int64, planID int64) (*models.SavingsPlan, error) {
+	plan, err := s.savingsRepo.FindByID(planID)
+	if err != nil || plan.UserID != userID {
+		return nil, errSavingsPlanNotFound
+	}
+	return plan, nil
+}
+
+// validateAccount 校验账户归属
+func (s *savingsService) validateAccount(userID int64, accountID int64) error {
+	account, err := s.accountRepo.FindByID(accountID)
+	if err != nil || account.UserID != userID {
+		return errors.New("invalid account")
+	}
+	return nil
+}
+
+// refreshSavingsCompletion 根据已存金额切换完成状态：达到目标时完成，取款后不足目标时恢复进行中
+func refreshSavingsCompletion(plan *models.SavingsPlan, now time.Time) {
+	switch {
+	case plan.Status == models.SavingsStatusActive && plan.CurrentAmount >= plan.TargetAmount:
+		plan.Status = models.SavingsStatusCompleted
+		plan.CompletedAt = &now
+	case plan.Status == models.SavingsStatusCompleted && plan.CurrentAmount < plan.TargetAmount:
+		plan.Status = models.SavingsStatusActive
+		plan.CompletedAt = nil
+	}
+}
+
+// savingsMonthsLeft 计算距目标日期剩余的存款月数（含本月），已到期时按1个月计
+func savingsMonthsLeft(today, targetDate time.Time) int64 {
+	months := (targetDate.Year()-today.Year())*12 + int(targetDate.Month()-today.Month())
+	if targetDate.Day() >= today.Day() {
+		months++
+	}
+	if months < 1 {
+		months = 1
+	}
+	return int64(months)
+}
+
+// toSavingsPlanResponse 转换为响应对象，并计算进度与每月需存金额
+func (s *savingsService) toSavingsPlanResponse(plan *models.SavingsPlan, now time.Time) *response.SavingsPlanResponse {
+	resp := &response.SavingsPlanResponse{
+		ID:             plan.ID,
+		PlanName:       plan.PlanName,
+		PlanType:       plan.PlanType,
+		TargetAmount:   plan.TargetAmount,
+		CurrentAmount:  plan.CurrentAmount,
+		StartDate:      plan.StartDate,
+		TargetDate:     plan.TargetDate,
+		Frequency:      plan.Frequency,
+		PeriodicAmount: plan.PeriodicAmount,
+		AccountID:      plan.AccountID,
+		Icon:           plan.Icon,
+		Color:          plan.Color,
+		Description:    plan.Description,
+		Status:         plan.Status,
+		CreatedAt:      plan.CreatedAt,
+		UpdatedAt:      plan.UpdatedAt,
+		CompletedAt:    plan.CompletedAt,
+	}
+
+	if plan.CurrentAmount < plan.TargetAmount {
+		resp.RemainingAmount = plan.TargetAmount - plan.CurrentAmount
+	}
+	if plan.TargetAmount > 0 {
+		progress := plan.CurrentAmount.Float64() / plan.TargetAmount.Float64() * 100
+		resp.Progress = math.Min(math.Round(progress*100)/100, 100)
+	}
+	if plan.TargetDate != nil {
+		today := truncateToDate(now)
+		daysLeft := int(truncateToDate(*plan.TargetDate).Sub(today).Hours() / 24)
+		resp.DaysLeft = &daysLeft
+
+		contribution := money.Zero
+		if resp.RemainingAmount > 0 {
+			// 按剩余月数均分，结果不大于剩余金额，不会超出金额范围
+			contribution, _ = resp.RemainingAmount.MulRat(big.NewRat(1, savingsMonthsLeft(today, *plan.TargetDate)))
+		}
+		resp.MonthlyContribution = &contribution
+	}
+
+	if plan.Account != nil {
+		resp.Account = &response.AccountResponse{
+			ID:             plan.Account.ID,
+			UserID:         plan.Account.UserID,
+			AccountType:    plan.Account.AccountType,
+			AccountName:    plan.Account.AccountName,
+			AccountNumber:  plan.Account.AccountNumber,
+			Icon:           plan.Account.Icon,
+			Color:          plan.Account.Color,
+			Balance:        plan.Account.Balance,
+			InitialBalance: plan.Account.InitialBalance,
+			IncludeInTotal: plan.Account.IncludeInTotal,
+			DisplayOrder:   plan.Account.DisplayOrder,
+			IsActive:       plan.Account.IsActive,
+			CreatedAt:      plan.Account.CreatedAt,
+			UpdatedAt:      plan.Account.UpdatedAt,
+		}
+	}
+	return resp
+}
+
+// toSavingsRecordResponse 转换为响应对象
+func (s *savingsService) toSavingsRecordResponse(record *models.SavingsRecord) *response.SavingsRecordResponse {
+	if record == nil {
+		return nil
+	}
+	return &response.SavingsRecordResponse{
+		ID:            record.ID,
+		PlanID:        record.PlanID,
+		TransactionID: record.TransactionID,
+		RecordType:    record.RecordType,
+		Amount:        record.Amount,
+		DepositDate:   record.DepositDate,
+		PeriodNumber:  record.PeriodNumber,
+		Notes:         record.Notes,
+		CreatedAt:     record.CreatedAt,
+	}
+}
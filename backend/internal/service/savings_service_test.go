@@ -0,0 +1,169 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSavingsRepository Mock SavingsRepository
+type MockSavingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockSavingsRepository) Create(plan *models.SavingsPlan) error {
+	args := m.Called(plan)
+	return args.Error(0)
+}
+
+func (m *MockSavingsRepository) FindByID(id int64) (*models.SavingsPlan, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavingsPlan), args.Error(1)
+}
+
+func (m *MockSavingsRepository) FindByIDForUpdate(id int64) (*models.SavingsPlan, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavingsPlan), args.Error(1)
+}
+
+func (m *MockSavingsRepository) FindByUserID(userID int64, status string) ([]*models.SavingsPlan, error) {
+	args := m.Called(userID, status)
+	return args.Get(0).([]*models.SavingsPlan), args.Error(1)
+}
+
+func (m *MockSavingsRepository) Update(plan *models.SavingsPlan) error {
+	args := m.Called(plan)
+	return args.Error(0)
+}
+
+func (m *MockSavingsRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSavingsRepository) CountActive(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSavingsRepository) CreateRecord(record *models.SavingsRecord) error {
+	args := m.Called(record)
+	return args.Error(0)
+}
+
+func (m *MockSavingsRepository) FindRecordsByPlanID(planID int64, limit int) ([]*models.SavingsRecord, error) {
+	args := m.Called(planID, limit)
+	return args.Get(0).([]*models.SavingsRecord), args.Error(1)
+}
+
+func newTestSavingsService() (SavingsService, *MockSavingsRepository, *MockTransactionRepository, *MockAccountRepository) {
+	mockSavingsRepo := new(MockSavingsRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo, savingsRepo: mockSavingsRepo}
+	transService := newTestTransactionService(uow, mockCategoryRepo)
+	return NewSavingsService(mockSavingsRepo, mockAccountRepo, transService), mockSavingsRepo, mockTransRepo, mockAccountRepo
+}
+
+// TestSavingsDepositCompletesPlan 测试存款生成转账交易、更新余额并在达到目标时完成计划
+func TestSavingsDepositCompletesPlan(t *testing.T) {
+	svc, mockSavingsRepo, mockTransRepo, mockAccountRepo := newTestSavingsService()
+
+	userID := int64(1)
+	savingAccountID := int64(200)
+	plan := &models.SavingsPlan{
+		ID:            3,
+		UserID:        userID,
+		PlanName:      "旅行基金",
+		TargetAmount:  money.MustParse("1000.00"),
+		CurrentAmount: money.MustParse("800.00"),
+		AccountID:     &savingAccountID,
+		Status:        models.SavingsStatusActive,
+	}
+	locked := *plan
+	mockSavingsRepo.On("FindByID", int64(3)).Return(plan, nil)
+	mockSavingsRepo.On("FindByIDForUpdate", int64(3)).Return(&locked, nil)
+	mockSavingsRepo.On("CreateRecord", mock.MatchedBy(func(r *models.SavingsRecord) bool {
+		return r.PlanID == 3 && r.RecordType == models.SavingsRecordDeposit && *r.TransactionID == 77
+	})).Return(nil)
+	mockSavingsRepo.On("Update", &locked).Return(nil)
+
+	wallet := &models.Account{ID: 100, UserID: userID, Balance: money.MustParse("500.00")}
+	saving := &models.Account{ID: savingAccountID, UserID: userID, Balance: money.MustParse("800.00")}
+	for _, account := range []*models.Account{wallet, saving} {
+		mockAccountRepo.On("FindByID", account.ID).Return(account, nil)
+		mockAccountRepo.On("FindByIDForUpdate", account.ID).Return(account, nil)
+		mockAccountRepo.On("Update", account).Return(nil)
+	}
+
+	mockTransRepo.On("Create", mock.MatchedBy(func(tr *models.Transaction) bool {
+		return tr.Type == "transfer" && *tr.AccountID == 100 && *tr.ToAccountID == savingAccountID
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transaction).ID = 77
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(77)).Return(&models.Transaction{ID: 77, UserID: userID, Type: "transfer"}, nil)
+
+	resp, err := svc.Deposit(userID, 3, &request.SavingsDepositRequest{Amount: money.MustParse("200.00"), FromAccountID: 100})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("300.00"), wallet.Balance)
+	assert.Equal(t, money.MustParse("1000.00"), saving.Balance)
+	assert.Equal(t, models.SavingsStatusCompleted, resp.Plan.Status)
+	assert.Equal(t, float64(100), resp.Plan.Progress)
+	assert.NotNil(t, locked.CompletedAt)
+}
+
+// TestSavingsWithdrawInsufficient 测试取款超过已存金额时拒绝且不生成交易
+func TestSavingsWithdrawInsufficient(t *testing.T) {
+	svc, mockSavingsRepo, mockTransRepo, _ := newTestSavingsService()
+
+	savingAccountID := int64(200)
+	mockSavingsRepo.On("FindByID", int64(3)).Return(&models.SavingsPlan{
+		ID:            3,
+		UserID:        1,
+		TargetAmount:  money.MustParse("1000.00"),
+		CurrentAmount: money.MustParse("50.00"),
+		AccountID:     &savingAccountID,
+		Status:        models.SavingsStatusActive,
+	}, nil)
+
+	_, err := svc.Withdraw(1, 3, &request.SavingsWithdrawRequest{Amount: money.MustParse("80.00"), ToAccountID: 100})
+
+	assert.Equal(t, errSavingsInsufficient, err)
+	mockTransRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestSavingsMonthlyContribution 测试按目标日期计算每月需存金额
+func TestSavingsMonthlyContribution(t *testing.T) {
+	svc := &savingsService{}
+	targetDate := date(2024, 6, 10)
+	plan := &models.SavingsPlan{
+		TargetAmount:  money.MustParse("1200.00"),
+		CurrentAmount: money.MustParse("200.00"),
+		TargetDate:    &targetDate,
+		Status:        models.SavingsStatusActive,
+	}
+
+	// 3月10日至6月10日共4次月存款
+	resp := svc.toSavingsPlanResponse(plan, date(2024, 3, 10))
+	assert.Equal(t, money.MustParse("1000.00"), resp.RemainingAmount)
+	assert.Equal(t, money.MustParse("250.00"), *resp.MonthlyContribution)
+	assert.Equal(t, 92, *resp.DaysLeft)
+	assert.Equal(t, 16.67, resp.Progress)
+
+	// 已逾期时剩余金额需一次存齐
+	resp = svc.toSavingsPlanResponse(plan, date(2024, 7, 1))
+	assert.Equal(t, money.MustParse("1000.00"), *resp.MonthlyContribution)
+}
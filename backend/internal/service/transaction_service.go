@@ -3,32 +3,73 @@ package service
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/qiuhaonan/float-backend/internal/dto/request"
 	"github.com/qiuhaonan/float-backend/internal/dto/response"
 	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
 )
 
 // TransactionService 交易业务逻辑层
 type TransactionService interface {
 	CreateTransaction(userID int64, req *request.CreateTransactionRequest) (*response.TransactionResponse, error)
+	CreateLinkedTransaction(userID int64, req *request.CreateTransactionRequest, link LinkFunc) (*response.TransactionResponse, error)
 	CreateBatchTransactions(userID int64, req *request.BulkCreateTransactionRequest) (*response.BulkOperationResponse, error)
 	UpdateTransaction(userID int64, transactionID int64, req *request.UpdateTransactionRequest) (*response.TransactionResponse, error)
 	DeleteTransaction(userID int64, transactionID int64) error
 	DeleteBatchTransactions(userID int64, req *request.BulkDeleteTransactionRequest) (*response.BulkOperationResponse, error)
+	DeleteImportedTransactions(userID int64, batchID int64) (int, error)
+	// RecategorizeTransactions 将符合筛选条件的交易批量改为指定分类
+	RecategorizeTransactions(userID int64, filters *request.ListTransactionRequest, req *request.RecategorizeTransactionRequest) (*response.BulkOperationResponse, error)
 	GetTransactionByID(userID int64, transactionID int64) (*response.TransactionResponse, error)
 	ListTransactions(userID int64, filters *request.ListTransactionRequest) (*response.TransactionListResponse, error)
 	GetTransactionStatistics(userID int64, startDate, endDate time.Time) (*response.TransactionStatisticsResponse, error)
 	GetMonthlyStatistics(userID int64, month time.Time) ([]*response.MonthlyStatisticsResponse, error)
-	GetCategoryStatistics(userID int64, startDate, endDate time.Time) ([]*response.CategoryStatisticsResponse, error)
+	// GetCategoryStatistics 分类支出统计，rollup 为 true 时子分类的支出汇总到顶级分类
+	GetCategoryStatistics(userID int64, startDate, endDate time.Time, rollup bool) ([]*response.CategoryStatisticsResponse, error)
+	// GetPendingReimbursements 获取尚未全额报销的可报销支出
+	GetPendingReimbursements(userID int64) (*response.ReimbursementReportResponse, error)
 }
 
+var (
+	errSplitTransfer    = errors.New("转账交易不支持拆分")
+	errSplitTooFew      = errors.New("拆分至少需要两项")
+	errRefundType       = errors.New("退款须记为收入")
+	errRefundOriginal   = errors.New("原支出交易不存在")
+	errRefundNotExpense = errors.New("只能对支出交易退款")
+	errRefundSplits     = errors.New("退款不支持拆分")
+	errHasRefunds       = errors.New("该支出存在关联退款，请先删除退款记录")
+	errLinkedDelete     = errors.New("该交易由储蓄计划、账单、心愿单或贷款生成，不能直接删除")
+	errRefundedType     = errors.New("该支出存在关联退款，不能修改交易类型")
+	errRefundCurrency   = errors.New("退款币种须与原支出一致")
+	errRefundedCurrency = errors.New("该支出存在关联退款，不能修改币种")
+	errCurrencyMismatch = errors.New("交易币种须与账户币种一致")
+	errTransferCurrency = errors.New("暂不支持不同币种账户之间的转账")
+	errReconciledLocked = errors.New("该交易已对账，如需修改金额、账户、类型、日期或删除，请先撤销对账")
+	errReconciledPeriod = errors.New("交易日期须晚于账户最近一次对账日期，如需补记请先撤销对账")
+	errRecategorizeAll  = errors.New("请至少指定一个筛选条件")
+	errRecategorizeType = errors.New("分类的收支类型与筛选的交易类型不一致")
+	errPostingRate      = errors.New("金额按记账汇率折算为本位币后超出范围")
+)
+
+// LinkFunc 在创建交易的同一数据库事务中写入关联记录（如账单历史），返回错误时整体回滚
+type LinkFunc func(repos *repository.TxRepositories, transaction *models.Transaction) error
+
 type transactionService struct {
 	transactionRepo repository.TransactionRepository
 	accountRepo     repository.AccountRepository
 	categoryRepo    repository.CategoryRepository
+	uow             repository.UnitOfWork
+	notifier        NotificationService
+	budgets         BudgetChecker
+	rates           CurrencyConverter
+	rules           TransactionRuleApplier
+	suggestions     CategoryModelInvalidator // 可选，批量修改分类后丢弃分类建议模型
 }
 
 // NewTransactionService 创建交易服务实例
@@ -36,23 +77,58 @@ func NewTransactionService(
 	transactionRepo repository.TransactionRepository,
 	accountRepo repository.AccountRepository,
 	categoryRepo repository.CategoryRepository,
+	uow repository.UnitOfWork,
+	notifier NotificationService,
+	budgets BudgetChecker,
+	rates CurrencyConverter,
+	rules TransactionRuleApplier,
+	suggestions CategoryModelInvalidator,
 ) TransactionService {
 	return &transactionService{
 		transactionRepo: transactionRepo,
 		accountRepo:     accountRepo,
 		categoryRepo:    categoryRepo,
+		uow:             uow,
+		notifier:        notifier,
+		budgets:         budgets,
+		rates:           rates,
+		rules:           rules,
+		suggestions:     suggestions,
 	}
 }
 
-// CreateTransaction 创建交易
+// CreateTransaction 创建交易，写入前按优先级执行用户的交易规则
 func (s *transactionService) CreateTransaction(userID int64, req *request.CreateTransactionRequest) (*response.TransactionResponse, error) {
+	if req != nil {
+		s.applyRules(userID, req)
+	}
+	return s.CreateLinkedTransaction(userID, req, nil)
+}
+
+// applyRules 执行交易规则，规则加载失败不影响记账
+func (s *transactionService) applyRules(userID int64, req *request.CreateTransactionRequest) {
+	if s.rules == nil || req.RulesApplied {
+		return
+	}
+	rules, err := s.rules.EnabledRules(userID)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("[Service][交易] 加载交易规则失败 | 用户ID: %d | 错误: %v", userID, err))
+		return
+	}
+	rules.Apply(req)
+}
+
+// CreateLinkedTransaction 创建交易，并在同一事务中执行link写入关联记录
+func (s *transactionService) CreateLinkedTransaction(userID int64, req *request.CreateTransactionRequest, link LinkFunc) (*response.TransactionResponse, error) {
 	if req == nil {
 		return nil, errors.New("request cannot be nil")
 	}
 
 	// 验证账户
+	var account *models.Account
 	if req.AccountID != nil {
-		account, err := s.accountRepo.FindByID(*req.AccountID)
+		var err error
+		account, err = s.accountRepo.FindByID(*req.AccountID)
 		if err != nil || account.UserID != userID {
 			return nil, errors.New("invalid account")
 		}
@@ -64,8 +140,18 @@ func (s *transactionService) CreateTransaction(userID int64, req *request.Create
 		if err != nil || toAccount.UserID != userID {
 			return nil, errors.New("invalid to_account")
 		}
+		if account != nil && account.Currency != "" && toAccount.Currency != "" && account.Currency != toAccount.Currency {
+			return nil, errTransferCurrency
+		}
 	}
 
+	// 交易币种与账户币种一致，未指定时沿用账户币种
+	currency, err := transactionCurrency(req.Currency, account)
+	if err != nil {
+		return nil, err
+	}
+	req.Currency = currency
+
 	// 验证分类（系统分类user_id为0，不需要匹配）
 	if req.CategoryID != nil {
 		category, err := s.categoryRepo.FindByID(*req.CategoryID)
@@ -74,9 +160,36 @@ func (s *transactionService) CreateTransaction(userID int64, req *request.Create
 		}
 	}
 
-	// 设置默认货币
-	if req.Currency == "" {
-		req.Currency = "CNY"
+	// 验证退款：未指定分类时沿用原支出的分类，统计时冲减该分类的支出
+	if req.RefundOfID != nil {
+		if len(req.Splits) > 0 {
+			return nil, errRefundSplits
+		}
+		original, err := s.checkRefund(userID, *req.RefundOfID, req.Type, req.Currency, req.Amount, 0)
+		if err != nil {
+			return nil, err
+		}
+		if req.CategoryID == nil {
+			req.CategoryID = original.CategoryID
+		}
+	}
+
+	// 验证拆分明细，未指定分类时以金额最大的明细分类作为主分类
+	splits, err := s.buildSplits(userID, req.Type, req.Amount, req.Splits)
+	if err != nil {
+		return nil, err
+	}
+	if req.CategoryID == nil && len(splits) > 0 {
+		req.CategoryID = mainSplitCategory(splits)
+	}
+
+	// 记录记账时兑本位币的汇率，统计按此折算
+	rate, err := s.postingRate(userID, req.Currency, req.TransactionDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPostingRate(req.Amount, rate); err != nil {
+		return nil, err
 	}
 
 	// 创建交易对象
@@ -88,6 +201,7 @@ func (s *transactionService) CreateTransaction(userID int64, req *request.Create
 		ToAccountID:     req.ToAccountID,
 		Amount:          req.Amount,
 		Currency:        req.Currency,
+		ExchangeRate:    rate,
 		Title:           req.Title,
 		Description:     req.Description,
 		Location:        req.Location,
@@ -95,46 +209,54 @@ func (s *transactionService) CreateTransaction(userID int64, req *request.Create
 		TransactionTime: req.TransactionTime,
 		BillID:          req.BillID,
 		WishlistID:      req.WishlistID,
+		RecurringRuleID: req.RecurringRuleID,
+		ImportBatchID:   req.ImportBatchID,
+		RefundOfID:      req.RefundOfID,
+		Reimbursable:    req.Reimbursable,
 		Tags:            models.JSONArray(req.Tags),
 		Images:          models.JSONArray(req.Images),
+		Splits:          splits,
 	}
-
-	// 保存交易并更新账户余额
-	if err := s.transactionRepo.Create(transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	if req.ExternalID != "" {
+		transaction.ExternalID = &req.ExternalID
 	}
 
-	// 更新账户余额
-	if transaction.Type == "expense" || transaction.Type == "income" {
-		if req.AccountID != nil {
-			account, _ := s.accountRepo.FindByID(*req.AccountID)
-			if transaction.Type == "income" {
-				account.Balance += transaction.Amount
-			} else {
-				account.Balance -= transaction.Amount
-			}
-			if err := s.accountRepo.Update(account); err != nil {
-				return nil, fmt.Errorf("failed to update account: %w", err)
-			}
+	// 保存交易（含拆分明细）并更新账户余额和记账统计（同一事务）
+	err = s.uow.Transaction(func(repos *repository.TxRepositories) error {
+		if err := repos.Transactions.Create(transaction); err != nil {
+			return err
+		}
+		deltas := transactionEffects(transaction.Type, transaction.AccountID, transaction.ToAccountID, transaction.Amount)
+		if err := s.applyBalanceDeltas(repos.Accounts, deltas); err != nil {
+			return err
 		}
-	} else if transaction.Type == "transfer" {
-		// 处理转账：从源账户扣除，到目标账户增加
-		if req.AccountID != nil {
-			fromAccount, _ := s.accountRepo.FindByID(*req.AccountID)
-			fromAccount.Balance -= transaction.Amount
-			if err := s.accountRepo.Update(fromAccount); err != nil {
-				return nil, fmt.Errorf("failed to update account: %w", err)
+		// 账户已加锁，与对账互斥
+		if err := checkReconciledPeriod(repos.Reconciliations, deltas, transaction.TransactionDate); err != nil {
+			return err
+		}
+		if isUserEntered(transaction) && !req.AutoPosted {
+			if err := repos.Users.AddRecords(userID, 1, truncateToDate(time.Now())); err != nil {
+				return err
 			}
 		}
+		if link != nil {
+			return link(repos, transaction)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
 
-		if req.ToAccountID != nil {
-			toAccount, _ := s.accountRepo.FindByID(*req.ToAccountID)
-			toAccount.Balance += transaction.Amount
-			if err := s.accountRepo.Update(toAccount); err != nil {
-				return nil, fmt.Errorf("failed to update account: %w", err)
-			}
+	// 大额支出提醒与预算预警（通知失败不影响记账，导入的历史交易不提醒）
+	if s.notifier != nil && transaction.ImportBatchID == nil {
+		if err := s.notifier.NotifyLargeExpense(transaction); err != nil {
+			logger.Warn(fmt.Sprintf("[Service][交易] 大额支出通知失败 | 用户ID: %d | 交易ID: %d | 错误: %v", userID, transaction.ID, err))
 		}
 	}
+	if transaction.ImportBatchID == nil {
+		s.checkBudgets(transaction)
+	}
 
 	// 获取完整的交易信息
 	fullTransaction, _ := s.transactionRepo.FindByID(transaction.ID)
@@ -167,26 +289,39 @@ func (s *transactionService) CreateBatchTransactions(userID int64, req *request.
 
 // UpdateTransaction 更新交易
 func (s *transactionService) UpdateTransaction(userID int64, transactionID int64, req *request.UpdateTransactionRequest) (*response.TransactionResponse, error) {
-	// 获取原交易
-	oldTransaction, err := s.transactionRepo.FindByID(transactionID)
+	var updated *models.Transaction
+	err := s.uow.Transaction(func(repos *repository.TxRepositories) error {
+		var err error
+		updated, err = s.updateLocked(repos, userID, transactionID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.checkBudgets(updated)
+
+	fullTransaction, _ := s.transactionRepo.FindByID(transactionID)
+	return s.toTransactionResponse(fullTransaction), nil
+}
+
+// updateLocked 在事务中加锁读取原交易后更新，对账检查和余额回滚都基于锁定的数据，
+// 避免与并发的修改、删除或对账重复计算余额
+func (s *transactionService) updateLocked(repos *repository.TxRepositories, userID int64, transactionID int64, req *request.UpdateTransactionRequest) (*models.Transaction, error) {
+	oldTransaction, err := repos.Transactions.FindByIDForUpdate(transactionID)
 	if err != nil || oldTransaction.UserID != userID {
 		return nil, errors.New("transaction not found")
 	}
 
-	// 保存原始值用于余额计算
-	oldAmount := oldTransaction.Amount
-	oldType := oldTransaction.Type
-	var oldAccountID *int64
-	var oldToAccountID *int64
-	if oldTransaction.AccountID != nil {
-		id := *oldTransaction.AccountID
-		oldAccountID = &id
-	}
-	if oldTransaction.ToAccountID != nil {
-		id := *oldTransaction.ToAccountID
-		oldToAccountID = &id
+	// 已对账的交易只能修改标题、备注、分类等描述信息
+	if isReconciled(oldTransaction) && changesReconciledFields(oldTransaction, req) {
+		return nil, errReconciledLocked
 	}
 
+	// 保存原交易对账户的影响，用于余额回滚
+	oldDeltas := transactionEffects(oldTransaction.Type, oldTransaction.AccountID, oldTransaction.ToAccountID, oldTransaction.Amount)
+	oldType, oldAmount, oldCurrency, oldDate := oldTransaction.Type, oldTransaction.Amount, oldTransaction.Currency, oldTransaction.TransactionDate
+	oldAccountID, oldToAccountID := oldTransaction.AccountID, oldTransaction.ToAccountID
+
 	// 更新字段
 	if req.Type != "" {
 		oldTransaction.Type = req.Type
@@ -206,9 +341,6 @@ func (s *transactionService) UpdateTransaction(userID int64, transactionID int64
 	if req.Amount != 0 {
 		oldTransaction.Amount = req.Amount
 	}
-	if req.Currency != "" {
-		oldTransaction.Currency = req.Currency
-	}
 	if req.Title != "" {
 		oldTransaction.Title = req.Title
 	}
@@ -230,152 +362,452 @@ func (s *transactionService) UpdateTransaction(userID int64, transactionID int64
 	if len(req.Images) > 0 {
 		oldTransaction.Images = models.JSONArray(req.Images)
 	}
+	if req.Reimbursable != nil {
+		oldTransaction.Reimbursable = *req.Reimbursable
+	}
 
-	// 获取新值
-	newAmount := oldTransaction.Amount
-	newType := oldTransaction.Type
-	newAccountID := oldTransaction.AccountID
-	newToAccountID := oldTransaction.ToAccountID
+	// 修改币种或更换账户时，交易币种须与账户币种一致，转账的转入账户须属于当前用户且币种一致
+	if req.Currency != "" || req.AccountID != nil || req.ToAccountID != nil || oldTransaction.Type != oldType {
+		var account *models.Account
+		if oldTransaction.AccountID != nil {
+			account, err = s.accountRepo.FindByID(*oldTransaction.AccountID)
+			if err != nil || account.UserID != userID {
+				return nil, errors.New("invalid account")
+			}
+		}
+		if oldTransaction.Type == "transfer" && oldTransaction.ToAccountID != nil {
+			toAccount, err := s.accountRepo.FindByID(*oldTransaction.ToAccountID)
+			if err != nil || toAccount.UserID != userID {
+				return nil, errors.New("invalid to_account")
+			}
+			if account != nil && account.Currency != "" && toAccount.Currency != "" && account.Currency != toAccount.Currency {
+				return nil, errTransferCurrency
+			}
+		}
+		currency := req.Currency
+		if currency == "" {
+			currency = oldTransaction.Currency
+			if account != nil && account.Currency != "" {
+				currency = account.Currency
+			}
+		}
+		if currency != "" {
+			if oldTransaction.Currency, err = transactionCurrency(currency, account); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 修改类型、金额或币种时，退款不能超过原支出可退金额，已有退款的支出金额不能低于已退款金额
+	if oldTransaction.Type != oldType || oldTransaction.Amount != oldAmount || oldTransaction.Currency != oldCurrency {
+		if oldTransaction.RefundOfID != nil {
+			if _, err := s.checkRefund(userID, *oldTransaction.RefundOfID, oldTransaction.Type, oldTransaction.Currency, oldTransaction.Amount, oldAmount); err != nil {
+				return nil, err
+			}
+		} else if oldType == "expense" {
+			totals, err := repos.Transactions.GetRefundedTotals([]int64{transactionID})
+			if err != nil {
+				return nil, fmt.Errorf("failed to update transaction: %w", err)
+			}
+			if refunded := totals[transactionID]; refunded > 0 {
+				if oldTransaction.Type != "expense" {
+					return nil, errRefundedType
+				}
+				if oldTransaction.Currency != oldCurrency {
+					return nil, errRefundedCurrency
+				}
+				if oldTransaction.Amount < refunded {
+					return nil, fmt.Errorf("交易金额不能低于已退款金额%s", refunded)
+				}
+			}
+		}
+	}
+
+	// 拆分明细：传入时整体替换；未传入时原有明细须与新的类型和金额仍然匹配
+	var splits []*models.TransactionSplit
+	if req.Splits != nil {
+		splits, err = s.buildSplits(userID, oldTransaction.Type, oldTransaction.Amount, req.Splits)
+		if err != nil {
+			return nil, err
+		}
+		if req.CategoryID == nil && len(splits) > 0 {
+			oldTransaction.CategoryID = mainSplitCategory(splits)
+			oldTransaction.Category = nil
+		}
+	} else if len(oldTransaction.Splits) > 0 {
+		if err := validateSplits(oldTransaction.Type, oldTransaction.Amount, oldTransaction.Splits); err != nil {
+			return nil, err
+		}
+	}
+	existingSplits := oldTransaction.Splits
 
-	// 1. 恢复旧交易对账户的影响（逆向操作）
-	if err := s.reverseTransactionEffect(oldType, oldAccountID, oldToAccountID, oldAmount); err != nil {
-		return nil, fmt.Errorf("failed to reverse old transaction effect: %w", err)
+	// 修改币种或交易日期时按新的币种和日期重新取记账汇率
+	if oldTransaction.Currency != oldCurrency || !oldTransaction.TransactionDate.Equal(oldDate) || oldTransaction.ExchangeRate <= 0 {
+		if oldTransaction.ExchangeRate, err = s.postingRate(userID, oldTransaction.Currency, oldTransaction.TransactionDate); err != nil {
+			return nil, err
+		}
 	}
+	if err := checkPostingRate(oldTransaction.Amount, oldTransaction.ExchangeRate); err != nil {
+		return nil, err
+	}
+	oldTransaction.Splits = nil // 明细单独维护，避免随交易保存
 
-	// 2. 应用新交易对账户的影响（正向操作）
-	if err := s.applyTransactionEffect(newType, newAccountID, newToAccountID, newAmount); err != nil {
-		return nil, fmt.Errorf("failed to apply new transaction effect: %w", err)
+	// 合并新旧影响：先逆向恢复旧交易，再正向应用新交易
+	deltas := transactionEffects(oldTransaction.Type, oldTransaction.AccountID, oldTransaction.ToAccountID, oldTransaction.Amount)
+	for accountID, delta := range oldDeltas {
+		deltas[accountID] -= delta
 	}
 
-	if err := s.transactionRepo.Update(oldTransaction); err != nil {
+	// 仅修改日期时余额净变动为0，仍要更新新旧账户的更新时间，使余额走势快照按新日期重建
+	var touched []int64
+	if !oldTransaction.TransactionDate.Equal(oldDate) {
+		for accountID := range deltas {
+			touched = append(touched, accountID)
+		}
+	}
+	if err := s.applyBalanceDeltas(repos.Accounts, deltas, touched...); err != nil {
 		return nil, fmt.Errorf("failed to update transaction: %w", err)
 	}
+	// 修改日期或账户时，原位置和新位置都不能落在账户已对账的期间内
+	if !oldTransaction.TransactionDate.Equal(oldDate) || !sameID(oldAccountID, oldTransaction.AccountID) || !sameID(oldToAccountID, oldTransaction.ToAccountID) {
+		if err := checkReconciledPeriod(repos.Reconciliations, oldDeltas, oldDate); err != nil {
+			return nil, err
+		}
+		newDeltas := transactionEffects(oldTransaction.Type, oldTransaction.AccountID, oldTransaction.ToAccountID, oldTransaction.Amount)
+		if err := checkReconciledPeriod(repos.Reconciliations, newDeltas, oldTransaction.TransactionDate); err != nil {
+			return nil, err
+		}
+	}
+	if err := repos.Transactions.Update(oldTransaction); err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+	if req.Splits != nil {
+		if err := repos.Transactions.ReplaceSplits(oldTransaction.ID, splits); err != nil {
+			return nil, fmt.Errorf("failed to update transaction: %w", err)
+		}
+		oldTransaction.Splits = splits
+	} else {
+		oldTransaction.Splits = existingSplits
+	}
+	return oldTransaction, nil
+}
 
-	fullTransaction, _ := s.transactionRepo.FindByID(transactionID)
-	return s.toTransactionResponse(fullTransaction), nil
+// checkReconciledPeriod 交易日期不能落在所涉账户最近一次对账日期及之前，否则已核对的余额会改变
+func checkReconciledPeriod(reconciliations repository.ReconciliationRepository, effects map[int64]money.Money, date time.Time) error {
+	for accountID := range effects {
+		latest, err := reconciliations.FindLatest(accountID)
+		if err != nil {
+			return fmt.Errorf("failed to check reconciliation: %w", err)
+		}
+		if latest != nil && !truncateToDate(date).After(truncateToDate(latest.ReconcileDate)) {
+			return errReconciledPeriod
+		}
+	}
+	return nil
 }
 
-// reverseTransactionEffect 逆向恢复交易对账户的影响
-func (s *transactionService) reverseTransactionEffect(transType string, accountID, toAccountID *int64, amount float64) error {
-	if transType == "expense" || transType == "income" {
-		if accountID != nil {
-			account, err := s.accountRepo.FindByID(*accountID)
-			if err != nil {
-				return err
-			}
-			if transType == "income" {
-				// 收入的逆向：减少余额
-				account.Balance -= amount
-			} else {
-				// 支出的逆向：增加余额（退款）
-				account.Balance += amount
-			}
-			if err := s.accountRepo.Update(account); err != nil {
-				return err
+// sameID 两个可空ID是否相同
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// changesReconciledFields 更新请求是否修改了影响账户余额的字段（类型、金额、币种、账户、日期）
+func changesReconciledFields(t *models.Transaction, req *request.UpdateTransactionRequest) bool {
+	switch {
+	case req.Type != "" && req.Type != t.Type:
+		return true
+	case req.Amount != 0 && req.Amount != t.Amount:
+		return true
+	case req.Currency != "" && req.Currency != t.Currency:
+		return true
+	case req.AccountID != nil && (t.AccountID == nil || *req.AccountID != *t.AccountID):
+		return true
+	case req.ToAccountID != nil && (t.ToAccountID == nil || *req.ToAccountID != *t.ToAccountID):
+		return true
+	case !req.TransactionDate.IsZero() && !truncateToDate(req.TransactionDate).Equal(truncateToDate(t.TransactionDate)):
+		return true
+	}
+	return false
+}
+
+// checkBudgets 支出写入后检查预算预警（检查失败不影响记账），拆分交易按明细分类逐一检查
+func (s *transactionService) checkBudgets(transaction *models.Transaction) {
+	if s.budgets == nil || transaction.Type != "expense" {
+		return
+	}
+	categoryIDs := []*int64{transaction.CategoryID}
+	if len(transaction.Splits) > 0 {
+		categoryIDs = categoryIDs[:0]
+		seen := make(map[int64]bool)
+		for _, split := range transaction.Splits {
+			if split.CategoryID != nil && !seen[*split.CategoryID] {
+				seen[*split.CategoryID] = true
+				categoryIDs = append(categoryIDs, split.CategoryID)
 			}
 		}
-	} else if transType == "transfer" {
-		// 转账的逆向：从目标账户扣除，向源账户返还
-		if accountID != nil {
-			fromAccount, err := s.accountRepo.FindByID(*accountID)
-			if err != nil {
-				return err
-			}
-			fromAccount.Balance += amount
-			if err := s.accountRepo.Update(fromAccount); err != nil {
-				return err
-			}
+	}
+	for _, categoryID := range categoryIDs {
+		if err := s.budgets.CheckThresholds(transaction.UserID, categoryID, transaction.TransactionDate); err != nil {
+			logger.Warn(fmt.Sprintf("[Service][交易] 预算预警检查失败 | 用户ID: %d | 交易ID: %d | 错误: %v", transaction.UserID, transaction.ID, err))
 		}
-		if toAccountID != nil {
-			toAccount, err := s.accountRepo.FindByID(*toAccountID)
-			if err != nil {
-				return err
-			}
-			toAccount.Balance -= amount
-			if err := s.accountRepo.Update(toAccount); err != nil {
-				return err
+	}
+}
+
+// buildSplits 校验拆分明细的分类归属和金额，返回待保存的明细；未拆分时返回nil
+func (s *transactionService) buildSplits(userID int64, transType string, amount money.Money, reqs []request.SplitRequest) ([]*models.TransactionSplit, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	splits := make([]*models.TransactionSplit, 0, len(reqs))
+	for i, req := range reqs {
+		if req.CategoryID != nil {
+			category, err := s.categoryRepo.FindByID(*req.CategoryID)
+			if err != nil || (category.UserID != userID && category.UserID != 0) {
+				return nil, errors.New("invalid category")
 			}
 		}
+		splits = append(splits, &models.TransactionSplit{
+			CategoryID:   req.CategoryID,
+			Amount:       req.Amount,
+			Note:         req.Note,
+			DisplayOrder: i,
+		})
+	}
+	if err := validateSplits(transType, amount, splits); err != nil {
+		return nil, err
+	}
+	return splits, nil
+}
+
+// validateSplits 校验拆分明细与交易类型、金额是否匹配
+func validateSplits(transType string, amount money.Money, splits []*models.TransactionSplit) error {
+	if transType == "transfer" {
+		return errSplitTransfer
+	}
+	if len(splits) < 2 {
+		return errSplitTooFew
+	}
+	var total money.Money
+	for _, split := range splits {
+		total += split.Amount
+	}
+	if total != amount {
+		return fmt.Errorf("拆分金额合计%s与交易金额%s不一致", total, amount)
+	}
+	return nil
+}
+
+// checkRefund 校验退款关联的原支出，previous为该退款此前已计入的金额（新建时为0）
+func (s *transactionService) checkRefund(userID int64, originalID int64, transType string, currency string, amount money.Money, previous money.Money) (*models.Transaction, error) {
+	if transType != "income" {
+		return nil, errRefundType
+	}
+	original, err := s.transactionRepo.FindByID(originalID)
+	if err != nil || original.UserID != userID {
+		return nil, errRefundOriginal
+	}
+	if original.Type != "expense" || original.RefundOfID != nil {
+		return nil, errRefundNotExpense
+	}
+	originalCurrency := original.Currency
+	if originalCurrency == "" {
+		originalCurrency = defaultCurrency // 未记录币种的历史数据按默认币种
+	}
+	if originalCurrency != currency {
+		return nil, errRefundCurrency
+	}
+
+	totals, err := s.transactionRepo.GetRefundedTotals([]int64{originalID})
+	if err != nil {
+		return nil, err
+	}
+	refundable := original.Amount - (totals[originalID] - previous)
+	if amount > refundable {
+		return nil, fmt.Errorf("退款金额超过原支出可退金额%s", refundable)
+	}
+	return original, nil
+}
+
+// transactionCurrency 确定交易币种：未指定时沿用账户币种，指定时须与账户币种一致
+func transactionCurrency(currency string, account *models.Account) (string, error) {
+	accountCurrency := ""
+	if account != nil {
+		accountCurrency = strings.ToUpper(account.Currency)
+	}
+
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	switch {
+	case currency == "" && accountCurrency != "":
+		return accountCurrency, nil
+	case currency == "":
+		return defaultCurrency, nil
+	case accountCurrency != "" && currency != accountCurrency:
+		return "", errCurrencyMismatch
+	}
+	return currency, nil
+}
+
+// postingRate 记账汇率：交易币种兑用户本位币在交易日期的汇率，未配置汇率服务时按1:1记账
+func (s *transactionService) postingRate(userID int64, currency string, date time.Time) (money.Rate, error) {
+	if s.rates == nil {
+		return money.OneRate, nil
+	}
+	base, err := s.rates.BaseCurrency(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get base currency: %w", err)
+	}
+	return s.rates.GetRate(userID, currency, base, date)
+}
+
+// baseAmount 按记账汇率折算的本位币金额，记账时已由 checkPostingRate 校验可以换算
+func baseAmount(t *models.Transaction) money.Money {
+	amount, _ := convertAtRate(t.Amount, t.ExchangeRate)
+	return amount
+}
+
+// checkPostingRate 校验金额按记账汇率折算后不超出金额范围
+func checkPostingRate(amount money.Money, rate money.Rate) error {
+	if _, err := convertAtRate(amount, rate); err != nil {
+		return errPostingRate
 	}
 	return nil
 }
 
-// applyTransactionEffect 正向应用交易对账户的影响
-func (s *transactionService) applyTransactionEffect(transType string, accountID, toAccountID *int64, amount float64) error {
-	if transType == "expense" || transType == "income" {
+// convertAtRate 按汇率换算金额，历史数据未记录汇率时按1:1
+func convertAtRate(amount money.Money, rate money.Rate) (money.Money, error) {
+	if rate <= 0 {
+		return amount, nil
+	}
+	return amount.Convert(rate)
+}
+
+// mainSplitCategory 金额最大的明细分类，作为拆分交易的主分类
+func mainSplitCategory(splits []*models.TransactionSplit) *int64 {
+	main := splits[0]
+	for _, split := range splits[1:] {
+		if split.Amount > main.Amount {
+			main = split
+		}
+	}
+	return main.CategoryID
+}
+
+// transactionEffects 计算交易对各账户余额的影响（账户ID -> 余额变动）
+func transactionEffects(transType string, accountID, toAccountID *int64, amount money.Money) map[int64]money.Money {
+	deltas := make(map[int64]money.Money)
+	switch transType {
+	case "income":
 		if accountID != nil {
-			account, err := s.accountRepo.FindByID(*accountID)
-			if err != nil {
-				return err
-			}
-			if transType == "income" {
-				account.Balance += amount
-			} else {
-				account.Balance -= amount
-			}
-			if err := s.accountRepo.Update(account); err != nil {
-				return err
-			}
+			deltas[*accountID] += amount
 		}
-	} else if transType == "transfer" {
+	case "expense":
 		if accountID != nil {
-			fromAccount, err := s.accountRepo.FindByID(*accountID)
-			if err != nil {
-				return err
-			}
-			fromAccount.Balance -= amount
-			if err := s.accountRepo.Update(fromAccount); err != nil {
-				return err
-			}
+			deltas[*accountID] -= amount
+		}
+	case "transfer":
+		// 转账：从源账户扣除，到目标账户增加
+		if accountID != nil {
+			deltas[*accountID] -= amount
 		}
 		if toAccountID != nil {
-			toAccount, err := s.accountRepo.FindByID(*toAccountID)
-			if err != nil {
-				return err
-			}
-			toAccount.Balance += amount
-			if err := s.accountRepo.Update(toAccount); err != nil {
-				return err
-			}
+			deltas[*toAccountID] += amount
+		}
+	}
+	return deltas
+}
+
+// applyBalanceDeltas 在事务中加锁并更新账户余额，touched 中的账户即使变动为0也会更新（刷新更新时间）
+// 按账户ID升序加锁，避免并发事务之间死锁
+func (s *transactionService) applyBalanceDeltas(accounts repository.AccountRepository, deltas map[int64]money.Money, touched ...int64) error {
+	accountIDs := make([]int64, 0, len(deltas))
+	for accountID, delta := range deltas {
+		if delta != 0 {
+			accountIDs = append(accountIDs, accountID)
+		}
+	}
+	for _, accountID := range touched {
+		if deltas[accountID] == 0 {
+			accountIDs = append(accountIDs, accountID)
+		}
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+	for _, accountID := range accountIDs {
+		account, err := accounts.FindByIDForUpdate(accountID)
+		if err != nil {
+			return fmt.Errorf("failed to lock account %d: %w", accountID, err)
+		}
+		account.Balance += deltas[accountID]
+		if err := accounts.Update(account); err != nil {
+			return fmt.Errorf("failed to update account %d: %w", accountID, err)
 		}
 	}
 	return nil
 }
 
 // DeleteTransaction 删除交易
+// 在事务中加锁读取交易后再检查和恢复余额，并发删除或修改同一交易时只有一方生效
 func (s *transactionService) DeleteTransaction(userID int64, transactionID int64) error {
-	transaction, err := s.transactionRepo.FindByID(transactionID)
-	if err != nil || transaction.UserID != userID {
-		return errors.New("transaction not found")
-	}
+	return s.uow.Transaction(func(repos *repository.TxRepositories) error {
+		transaction, err := repos.Transactions.FindByIDForUpdate(transactionID)
+		if err != nil || transaction.UserID != userID {
+			return errors.New("transaction not found")
+		}
 
-	// 恢复账户余额
-	if transaction.Type == "expense" || transaction.Type == "income" {
-		if transaction.AccountID != nil {
-			account, _ := s.accountRepo.FindByID(*transaction.AccountID)
-			if transaction.Type == "income" {
-				account.Balance -= transaction.Amount
-			} else {
-				account.Balance += transaction.Amount
-			}
-			_ = s.accountRepo.Update(account)
+		if isReconciled(transaction) {
+			return errReconciledLocked
+		}
+
+		// 其他功能生成的交易不能直接删除，否则储蓄进度、付款记录、心愿状态或还款计划与账户余额不一致
+		linked, err := repos.Transactions.HasLinkedRecords(transactionID)
+		if err != nil {
+			return err
 		}
-	} else if transaction.Type == "transfer" {
-		// 恢复转账的两个账户
-		if transaction.AccountID != nil {
-			fromAccount, _ := s.accountRepo.FindByID(*transaction.AccountID)
-			fromAccount.Balance += transaction.Amount
-			_ = s.accountRepo.Update(fromAccount)
+		if linked {
+			return errLinkedDelete
 		}
 
-		if transaction.ToAccountID != nil {
-			toAccount, _ := s.accountRepo.FindByID(*transaction.ToAccountID)
-			toAccount.Balance -= transaction.Amount
-			_ = s.accountRepo.Update(toAccount)
+		// 存在关联退款的支出不能直接删除，否则退款会被当作收入统计
+		if transaction.Type == "expense" {
+			totals, err := repos.Transactions.GetRefundedTotals([]int64{transactionID})
+			if err != nil {
+				return err
+			}
+			if _, ok := totals[transactionID]; ok {
+				return errHasRefunds
+			}
 		}
-	}
 
-	return s.transactionRepo.Delete(transactionID)
+		// 删除交易并恢复账户余额
+		deltas := transactionEffects(transaction.Type, transaction.AccountID, transaction.ToAccountID, transaction.Amount)
+		for accountID, delta := range deltas {
+			deltas[accountID] = -delta
+		}
+		if err := s.applyBalanceDeltas(repos.Accounts, deltas); err != nil {
+			return err
+		}
+		if isUserEntered(transaction) {
+			if err := repos.Users.RemoveRecords(userID, 1); err != nil {
+				return err
+			}
+		}
+		return repos.Transactions.Delete(transactionID)
+	})
+}
+
+// isUserEntered 是否为用户手动记的账：周期规则、导入和账单付款生成的交易不计入记账笔数与连续记账天数，
+// 贷款分期由请求的 AutoPosted 标记。与 TransactionRepository.GetRecordDates 的统计口径一致
+func isUserEntered(t *models.Transaction) bool {
+	return t.RecurringRuleID == nil && t.ImportBatchID == nil && t.BillID == nil
+}
+
+// isReconciled 交易是否已对账：转账的转出、转入两侧任一已对账即锁定
+func isReconciled(t *models.Transaction) bool {
+	return t.ReconciliationID != nil || t.ToReconciliationID != nil
 }
 
 // DeleteBatchTransactions 批量删除交易
@@ -401,6 +833,71 @@ func (s *transactionService) DeleteBatchTransactions(userID int64, req *request.
 	return result, nil
 }
 
+// RecategorizeTransactions 批量修改分类：只修改与分类收支类型相同的交易，拆分交易和退款不修改
+func (s *transactionService) RecategorizeTransactions(userID int64, filters *request.ListTransactionRequest, req *request.RecategorizeTransactionRequest) (*response.BulkOperationResponse, error) {
+	if filters.Type == "" && filters.CategoryID == nil && filters.AccountID == nil &&
+		filters.StartDate.IsZero() && filters.EndDate.IsZero() && filters.SearchKeyword == "" {
+		return nil, errRecategorizeAll
+	}
+
+	category, err := s.categoryRepo.FindByID(req.CategoryID)
+	if err != nil || (category.UserID != userID && category.UserID != 0) || !category.IsActive {
+		return nil, errors.New("invalid category")
+	}
+	if filters.Type != "" && filters.Type != category.Type {
+		return nil, errRecategorizeType
+	}
+
+	updated, err := s.transactionRepo.UpdateCategoryByFilter(userID, filters, category.Type, category.ID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][交易] 批量修改分类失败 | 用户ID: %d | 分类ID: %d | 错误: %v", userID, category.ID, err))
+		return nil, err
+	}
+
+	if updated > 0 && s.suggestions != nil {
+		s.suggestions.InvalidateModel(userID)
+	}
+
+	logger.Info(fmt.Sprintf("[Service][交易] 批量修改分类 | 用户ID: %d | 分类ID: %d | 交易数: %d", userID, category.ID, updated))
+	return &response.BulkOperationResponse{SuccessCount: updated}, nil
+}
+
+// DeleteImportedTransactions 删除某次导入生成的全部交易并恢复账户余额（同一事务），返回删除条数
+func (s *transactionService) DeleteImportedTransactions(userID int64, batchID int64) (int, error) {
+	var deleted int
+	err := s.uow.Transaction(func(repos *repository.TxRepositories) error {
+		transactions, err := repos.Transactions.FindByImportBatchID(userID, batchID)
+		if err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+
+		deltas := make(map[int64]money.Money)
+		ids := make([]int64, 0, len(transactions))
+		for _, t := range transactions {
+			if isReconciled(t) {
+				return errReconciledLocked
+			}
+			for accountID, delta := range transactionEffects(t.Type, t.AccountID, t.ToAccountID, t.Amount) {
+				deltas[accountID] -= delta
+			}
+			ids = append(ids, t.ID)
+		}
+		// 导入的交易不计入记账统计，无需扣减
+		if err := s.applyBalanceDeltas(repos.Accounts, deltas); err != nil {
+			return err
+		}
+		deleted = len(ids)
+		return repos.Transactions.DeleteBatch(ids)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete imported transactions: %w", err)
+	}
+	return deleted, nil
+}
+
 // GetTransactionByID 获取交易详情
 func (s *transactionService) GetTransactionByID(userID int64, transactionID int64) (*response.TransactionResponse, error) {
 	transaction, err := s.transactionRepo.FindByID(transactionID)
@@ -438,35 +935,20 @@ func (s *transactionService) ListTransactions(userID int64, filters *request.Lis
 	}, nil
 }
 
-// GetTransactionStatistics 获取交易统计
+// GetTransactionStatistics 获取交易统计，金额按记账汇率折算为本位币
 func (s *transactionService) GetTransactionStatistics(userID int64, startDate, endDate time.Time) (*response.TransactionStatisticsResponse, error) {
-	// 这里需要通过SQL查询进行统计，实际实现需要在repository中添加专门的统计方法
-	// 这里给出简化版本
-
-	filters := &request.ListTransactionRequest{
-		StartDate: startDate,
-		EndDate:   endDate,
-		Page:      1,
-		PageSize:  1000,
-	}
-
-	transactions, _, err := s.transactionRepo.FindByUserID(userID, filters)
+	// 在SQL中按记账汇率折算为本位币汇总，退款冲减支出
+	income, expense, count, err := s.transactionRepo.GetDateRangeStatistics(userID, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get statistics: %w", err)
 	}
 
-	stats := &response.TransactionStatisticsResponse{}
-	for _, t := range transactions {
-		stats.TransactionCnt++
-		if t.Type == "income" {
-			stats.TotalIncome += t.Amount
-		} else if t.Type == "expense" {
-			stats.TotalExpense += t.Amount
-		}
-	}
-	stats.NetAmount = stats.TotalIncome - stats.TotalExpense
-
-	return stats, nil
+	return &response.TransactionStatisticsResponse{
+		TotalIncome:    income,
+		TotalExpense:   expense,
+		NetAmount:      income - expense,
+		TransactionCnt: count,
+	}, nil
 }
 
 // GetMonthlyStatistics 获取月度统计
@@ -491,49 +973,50 @@ func (s *transactionService) GetMonthlyStatistics(userID int64, month time.Time)
 	}, nil
 }
 
-// GetCategoryStatistics 获取分类统计
-func (s *transactionService) GetCategoryStatistics(userID int64, startDate, endDate time.Time) ([]*response.CategoryStatisticsResponse, error) {
-	// 实现分类统计逻辑
-	filters := &request.ListTransactionRequest{
-		Type:      "expense",
-		StartDate: startDate,
-		EndDate:   endDate,
-		Page:      1,
-		PageSize:  1000,
-	}
-
-	transactions, _, err := s.transactionRepo.FindByUserID(userID, filters)
+// GetCategoryStatistics 获取分类统计，拆分交易按明细金额计入各自的分类
+func (s *transactionService) GetCategoryStatistics(userID int64, startDate, endDate time.Time, rollup bool) ([]*response.CategoryStatisticsResponse, error) {
+	rows, err := s.transactionRepo.GetCategoryStatistics(userID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	// 按分类汇总
-	categoryMap := make(map[int64]*response.CategoryStatisticsResponse)
-	var totalExpense float64
-
-	for _, t := range transactions {
-		totalExpense += t.Amount
-		if t.CategoryID == nil {
-			continue
+	categories := make(map[int64]*models.Category)
+	findCategory := func(id int64) *models.Category {
+		if category, ok := categories[id]; ok {
+			return category
 		}
-
-		catID := *t.CategoryID
-		if _, exists := categoryMap[catID]; !exists {
-			categoryMap[catID] = &response.CategoryStatisticsResponse{
-				CategoryID: catID,
-				Category:   s.toCategoryResponse(t.Category),
-			}
+		category, err := s.categoryRepo.FindByID(id)
+		if err != nil {
+			category = nil
 		}
+		categories[id] = category
+		return category
+	}
+	if rollup {
+		rows = rollupCategoryRows(rows, findCategory)
+	}
 
-		categoryMap[catID].TotalAmount += t.Amount
-		categoryMap[catID].TransactionCnt++
+	var totalExpense money.Money
+	for _, row := range rows {
+		totalExpense += row.Amount
 	}
 
-	// 计算百分比
-	result := make([]*response.CategoryStatisticsResponse, 0)
-	for _, stat := range categoryMap {
+	// 计算百分比（未分类的支出计入总额，但不单独列出）
+	result := make([]*response.CategoryStatisticsResponse, 0, len(rows))
+	for _, row := range rows {
+		if row.CategoryID == nil {
+			continue
+		}
+		stat := &response.CategoryStatisticsResponse{
+			CategoryID:     *row.CategoryID,
+			TotalAmount:    row.Amount,
+			TransactionCnt: row.ID,
+		}
+		if category := findCategory(*row.CategoryID); category != nil {
+			stat.Category = s.toCategoryResponse(category)
+		}
 		if totalExpense > 0 {
-			stat.Percentage = stat.TotalAmount / totalExpense * 100
+			stat.Percentage = float64(stat.TotalAmount) / float64(totalExpense) * 100
 		}
 		result = append(result, stat)
 	}
@@ -541,6 +1024,78 @@ func (s *transactionService) GetCategoryStatistics(userID int64, startDate, endD
 	return result, nil
 }
 
+// rollupCategoryRows 将各分类的统计行汇总到其顶级分类，按汇总金额倒序。
+// 笔数为各子分类笔数之和，同一笔拆分交易的明细分属多个子分类时会重复计数
+func rollupCategoryRows(rows []*models.Transaction, findCategory func(id int64) *models.Category) []*models.Transaction {
+	totals := make(map[int64]*models.Transaction)
+	var result []*models.Transaction
+	for _, row := range rows {
+		if row.CategoryID == nil {
+			result = append(result, row)
+			continue
+		}
+
+		// 沿上级链找到顶级分类，上级分类已删除时停在当前层级
+		rootID := *row.CategoryID
+		for depth := 1; depth < maxCategoryDepth; depth++ {
+			category := findCategory(rootID)
+			if category == nil || category.ParentID == nil || findCategory(*category.ParentID) == nil {
+				break
+			}
+			rootID = *category.ParentID
+		}
+
+		total, ok := totals[rootID]
+		if !ok {
+			id := rootID
+			total = &models.Transaction{CategoryID: &id}
+			totals[rootID] = total
+			result = append(result, total)
+		}
+		total.Amount += row.Amount
+		total.ID += row.ID
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Amount > result[j].Amount })
+	return result
+}
+
+// GetPendingReimbursements 获取待报销报告，报销到账以关联原支出的收入记录，合计按记账汇率折算为本位币
+func (s *transactionService) GetPendingReimbursements(userID int64) (*response.ReimbursementReportResponse, error) {
+	transactions, err := s.transactionRepo.FindPendingReimbursements(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.ID
+	}
+	totals, err := s.transactionRepo.GetRefundedTotals(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &response.ReimbursementReportResponse{
+		Items: make([]*response.PendingReimbursementResponse, 0, len(transactions)),
+	}
+	for _, t := range transactions {
+		item := &response.PendingReimbursementResponse{
+			Transaction:      s.toTransactionResponse(t),
+			ReimbursedAmount: totals[t.ID],
+			PendingAmount:    t.Amount - totals[t.ID],
+		}
+		pending, err := convertAtRate(item.PendingAmount, t.ExchangeRate)
+		if err != nil {
+			return nil, err
+		}
+		report.TotalPending += pending
+		report.Items = append(report.Items, item)
+	}
+	report.Count = len(report.Items)
+	return report, nil
+}
+
 // 辅助方法：将Transaction模型转换为Response
 func (s *transactionService) toTransactionResponse(t *models.Transaction) *response.TransactionResponse {
 	if t == nil {
@@ -556,6 +1111,8 @@ func (s *transactionService) toTransactionResponse(t *models.Transaction) *respo
 		ToAccountID:     t.ToAccountID,
 		Amount:          t.Amount,
 		Currency:        t.Currency,
+		ExchangeRate:    t.ExchangeRate,
+		BaseAmount:      baseAmount(t),
 		Title:           t.Title,
 		Description:     t.Description,
 		Location:        t.Location,
@@ -563,12 +1120,21 @@ func (s *transactionService) toTransactionResponse(t *models.Transaction) *respo
 		TransactionTime: t.TransactionTime,
 		BillID:          t.BillID,
 		WishlistID:      t.WishlistID,
+		RecurringRuleID: t.RecurringRuleID,
+		ImportBatchID:   t.ImportBatchID,
+		RefundOfID:      t.RefundOfID,
+		Reimbursable:    t.Reimbursable,
+		Reconciled:      isReconciled(t),
 		Tags:            t.Tags,
 		Images:          t.Images,
 		CreatedAt:       t.CreatedAt,
 		UpdatedAt:       t.UpdatedAt,
 	}
 
+	if t.ExternalID != nil {
+		resp.ExternalID = *t.ExternalID
+	}
+
 	if t.Category != nil {
 		resp.Category = s.toCategoryResponse(t.Category)
 	}
@@ -581,6 +1147,16 @@ func (s *transactionService) toTransactionResponse(t *models.Transaction) *respo
 		resp.ToAccount = s.toAccountResponse(t.ToAccount)
 	}
 
+	for _, split := range t.Splits {
+		resp.Splits = append(resp.Splits, &response.SplitResponse{
+			ID:         split.ID,
+			CategoryID: split.CategoryID,
+			Category:   s.toCategoryResponse(split.Category),
+			Amount:     split.Amount,
+			Note:       split.Note,
+		})
+	}
+
 	return resp
 }
 
@@ -593,6 +1169,7 @@ func (s *transactionService) toCategoryResponse(c *models.Category) *response.Ca
 	return &response.CategoryResponse{
 		ID:           c.ID,
 		UserID:       c.UserID,
+		ParentID:     c.ParentID,
 		Type:         c.Type,
 		Name:         c.Name,
 		Icon:         c.Icon,
@@ -621,6 +1198,7 @@ func (s *transactionService) toAccountResponse(a *models.Account) *response.Acco
 		Color:          a.Color,
 		Balance:        a.Balance,
 		InitialBalance: a.InitialBalance,
+		Currency:       a.Currency,
 		IncludeInTotal: a.IncludeInTotal,
 		DisplayOrder:   a.DisplayOrder,
 		IsActive:       a.IsActive,
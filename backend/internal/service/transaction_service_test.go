@@ -7,6 +7,8 @@ import (
 
 	"github.com/qiuhaonan/float-backend/internal/dto/request"
 	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -34,11 +36,34 @@ func (m *MockTransactionRepository) FindByID(id int64) (*models.Transaction, err
 	return args.Get(0).(*models.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionRepository) FindByIDForUpdate(id int64) (*models.Transaction, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
 func (m *MockTransactionRepository) FindByUserID(userID int64, filters *request.ListTransactionRequest) ([]*models.Transaction, int64, error) {
 	args := m.Called(userID, filters)
 	return args.Get(0).([]*models.Transaction), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockTransactionRepository) UpdateCategoryByFilter(userID int64, filters *request.ListTransactionRequest, transType string, categoryID int64) (int64, error) {
+	args := m.Called(userID, filters, transType, categoryID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionRepository) UpdateClassification(transaction *models.Transaction) error {
+	args := m.Called(transaction)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) FindCategorized(userID int64, afterID int64, since time.Time, limit int) ([]*models.Transaction, error) {
+	args := m.Called(userID, afterID, since, limit)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
 func (m *MockTransactionRepository) Update(transaction *models.Transaction) error {
 	args := m.Called(transaction)
 	return args.Error(0)
@@ -54,6 +79,61 @@ func (m *MockTransactionRepository) DeleteBatch(ids []int64) error {
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) ReplaceSplits(transactionID int64, splits []*models.TransactionSplit) error {
+	args := m.Called(transactionID, splits)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) GetRefundedTotals(ids []int64) (map[int64]money.Money, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[int64]money.Money), args.Error(1)
+}
+
+func (m *MockTransactionRepository) HasLinkedRecords(id int64) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindPendingReimbursements(userID int64) ([]*models.Transaction, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindByImportBatchID(userID int64, batchID int64) ([]*models.Transaction, error) {
+	args := m.Called(userID, batchID)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindAllByUserID(userID int64, filters *request.ListTransactionRequest, limit int) ([]*models.Transaction, error) {
+	args := m.Called(userID, filters, limit)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindLatestByUserID(userID int64, filters *request.ListTransactionRequest, limit int) ([]*models.Transaction, error) {
+	args := m.Called(userID, filters, limit)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindExistingExternalIDsByAccount(userID int64, accountID int64, externalIDs []string) ([]string, error) {
+	args := m.Called(userID, accountID, externalIDs)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetAccountNetChangeAfter(accountID int64, date time.Time) (money.Money, error) {
+	args := m.Called(accountID, date)
+	return args.Get(0).(money.Money), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetAccountFlows(accountID int64, startDate, endDate time.Time) (money.Money, money.Money, int, error) {
+	args := m.Called(accountID, startDate, endDate)
+	return args.Get(0).(money.Money), args.Get(1).(money.Money), args.Int(2), args.Error(3)
+}
+
+func (m *MockTransactionRepository) FindExistingExternalIDs(userID int64, externalIDs []string) ([]string, error) {
+	args := m.Called(userID, externalIDs)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockTransactionRepository) GetTotalBalance(userID int64, filters *request.ListTransactionRequest) (*models.Transaction, error) {
 	args := m.Called(userID, filters)
 	if args.Get(0) == nil {
@@ -75,12 +155,39 @@ func (m *MockTransactionRepository) GetCategoryStatistics(userID int64, startDat
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) GetDateRangeStatistics(userID int64, startDate, endDate time.Time) (*models.Transaction, error) {
+func (m *MockTransactionRepository) GetDateRangeStatistics(userID int64, startDate, endDate time.Time) (money.Money, money.Money, int64, error) {
 	args := m.Called(userID, startDate, endDate)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Transaction), args.Error(1)
+	return args.Get(0).(money.Money), args.Get(1).(money.Money), args.Get(2).(int64), args.Error(3)
+}
+
+func (m *MockTransactionRepository) GetAccountDailyChanges(accountID int64) ([]*models.AccountBalanceSnapshot, error) {
+	args := m.Called(accountID)
+	return args.Get(0).([]*models.AccountBalanceSnapshot), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindUnreconciled(accountID int64, until time.Time) ([]*models.Transaction, error) {
+	args := m.Called(accountID, until)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) MarkReconciled(accountID int64, until time.Time, reconciliationID int64) (int64, error) {
+	args := m.Called(accountID, until, reconciliationID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionRepository) ClearReconciliation(reconciliationID int64) error {
+	args := m.Called(reconciliationID)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) CountRecordsByUserID(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetRecordDates(userID int64) ([]time.Time, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]time.Time), args.Error(1)
 }
 
 // MockAccountRepository Mock AccountRepository
@@ -101,11 +208,29 @@ func (m *MockAccountRepository) FindByID(id int64) (*models.Account, error) {
 	return args.Get(0).(*models.Account), args.Error(1)
 }
 
+func (m *MockAccountRepository) FindByIDForUpdate(id int64) (*models.Account, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Account), args.Error(1)
+}
+
 func (m *MockAccountRepository) FindByUserID(userID int64) ([]*models.Account, error) {
 	args := m.Called(userID)
 	return args.Get(0).([]*models.Account), args.Error(1)
 }
 
+func (m *MockAccountRepository) FindCreditAccounts() ([]*models.Account, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) FindAll() ([]*models.Account, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.Account), args.Error(1)
+}
+
 func (m *MockAccountRepository) Update(account *models.Account) error {
 	args := m.Called(account)
 	return args.Error(0)
@@ -116,9 +241,71 @@ func (m *MockAccountRepository) Delete(id int64) error {
 	return args.Error(0)
 }
 
-func (m *MockAccountRepository) GetTotalBalance(userID int64) (float64, error) {
+func (m *MockAccountRepository) GetTotalBalance(userID int64) (money.Money, error) {
 	args := m.Called(userID)
-	return args.Get(0).(float64), args.Error(1)
+	return args.Get(0).(money.Money), args.Error(1)
+}
+
+// MockUnitOfWork 直接以Mock仓库执行事务函数
+type MockUnitOfWork struct {
+	transactionRepo *MockTransactionRepository
+	accountRepo     *MockAccountRepository
+	billRepo        *MockBillRepository
+	ruleRepo        *MockRecurringRuleRepository
+	savingsRepo     *MockSavingsRepository
+	wishlistRepo    *MockWishlistRepository
+	loanRepo        *MockLoanRepository
+	userRepo        *MockUserRepository
+	reconcileRepo   *MockReconciliationRepository
+}
+
+func (m *MockUnitOfWork) Transaction(fn func(repos *repository.TxRepositories) error) error {
+	repos := &repository.TxRepositories{
+		Transactions: m.transactionRepo,
+		Accounts:     m.accountRepo,
+	}
+	if m.billRepo != nil {
+		repos.Bills = m.billRepo
+	}
+	if m.ruleRepo != nil {
+		repos.RecurringRules = m.ruleRepo
+	}
+	if m.savingsRepo != nil {
+		repos.Savings = m.savingsRepo
+	}
+	if m.wishlistRepo != nil {
+		repos.Wishlists = m.wishlistRepo
+	}
+	if m.loanRepo != nil {
+		repos.Loans = m.loanRepo
+	}
+	if m.reconcileRepo != nil {
+		repos.Reconciliations = m.reconcileRepo
+	} else {
+		// 不关注对账的用例视为账户从未对账
+		reconciliations := new(MockReconciliationRepository)
+		reconciliations.On("FindLatest", mock.Anything).Return(nil, nil)
+		repos.Reconciliations = reconciliations
+	}
+	if m.userRepo != nil {
+		repos.Users = m.userRepo
+	} else {
+		// 不关注记账统计的用例放行计数更新
+		users := new(MockUserRepository)
+		users.On("AddRecords", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		users.On("RemoveRecords", mock.Anything, mock.Anything).Return(nil)
+		repos.Users = users
+	}
+	return fn(repos)
+}
+
+// newTestTransactionService 以Mock事务单元中的交易与账户仓库创建交易服务，不启用通知、预算、汇率和交易规则；
+// categoryRepo 为空时使用未设置预期的Mock分类仓库
+func newTestTransactionService(uow *MockUnitOfWork, categoryRepo *MockCategoryRepository) TransactionService {
+	if categoryRepo == nil {
+		categoryRepo = new(MockCategoryRepository)
+	}
+	return NewTransactionService(uow.transactionRepo, uow.accountRepo, categoryRepo, uow, nil, nil, nil, nil, nil)
 }
 
 // MockCategoryRepository Mock CategoryRepository
@@ -159,18 +346,43 @@ func (m *MockCategoryRepository) Delete(id int64) error {
 	return args.Error(0)
 }
 
+func (m *MockCategoryRepository) FindChildren(parentID int64) ([]*models.Category, error) {
+	args := m.Called(parentID)
+	return args.Get(0).([]*models.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) DeleteAndReassign(id int64, newParentID *int64) error {
+	args := m.Called(id, newParentID)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) DeleteBatch(ids []int64) error {
+	args := m.Called(ids)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) Merge(sourceIDs []int64, targetID int64) (int64, error) {
+	args := m.Called(sourceIDs, targetID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockCategoryRepository) GetSystemCategories(categoryType string) ([]*models.Category, error) {
 	args := m.Called(categoryType)
 	return args.Get(0).([]*models.Category), args.Error(1)
 }
 
+// WithUserLock 直接以Mock仓库执行fn
+func (m *MockCategoryRepository) WithUserLock(userID int64, fn func(repo repository.CategoryRepository) error) error {
+	return fn(m)
+}
+
 // TestCreateTransaction 测试创建交易
 func TestCreateTransaction(t *testing.T) {
 	mockTransRepo := new(MockTransactionRepository)
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
 
 	userID := int64(1)
 	accountID := int64(100)
@@ -181,7 +393,7 @@ func TestCreateTransaction(t *testing.T) {
 		Type:            "expense",
 		CategoryID:      &categoryID,
 		AccountID:       &accountID,
-		Amount:          100.00,
+		Amount:          money.MustParse("100.00"),
 		Currency:        "CNY",
 		Title:           "测试交易",
 		TransactionDate: transactionDate,
@@ -193,6 +405,7 @@ func TestCreateTransaction(t *testing.T) {
 		UserID: userID,
 	}
 	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
 
 	// Mock分类查询
 	category := &models.Category{
@@ -204,7 +417,7 @@ func TestCreateTransaction(t *testing.T) {
 
 	// Mock交易创建
 	mockTransRepo.On("Create", mock.MatchedBy(func(t *models.Transaction) bool {
-		return t.UserID == userID && t.Amount == 100.00
+		return t.UserID == userID && t.Amount == money.MustParse("100.00")
 	})).Run(func(args mock.Arguments) {
 		trans := args.Get(0).(*models.Transaction)
 		trans.ID = 1
@@ -220,7 +433,7 @@ func TestCreateTransaction(t *testing.T) {
 		ID:       1,
 		UserID:   userID,
 		Type:     "expense",
-		Amount:   100.00,
+		Amount:   money.MustParse("100.00"),
 		Category: category,
 		Account:  account,
 	}, nil)
@@ -232,7 +445,7 @@ func TestCreateTransaction(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, userID, resp.UserID)
-	assert.Equal(t, 100.00, resp.Amount)
+	assert.Equal(t, money.MustParse("100.00"), resp.Amount)
 	assert.Equal(t, "expense", resp.Type)
 }
 
@@ -242,7 +455,8 @@ func TestUpdateTransaction(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+	mockTransRepo.On("GetRefundedTotals", mock.Anything).Return(map[int64]money.Money{}, nil)
 
 	userID := int64(1)
 	transactionID := int64(1)
@@ -251,7 +465,7 @@ func TestUpdateTransaction(t *testing.T) {
 	oldTransaction := &models.Transaction{
 		ID:        transactionID,
 		UserID:    userID,
-		Amount:    100.00,
+		Amount:    money.MustParse("100.00"),
 		Type:      "expense",
 		AccountID: &accountID,
 	}
@@ -259,25 +473,26 @@ func TestUpdateTransaction(t *testing.T) {
 	account := &models.Account{
 		ID:      accountID,
 		UserID:  userID,
-		Balance: 900.00, // 初始1000，已扣100
+		Balance: money.MustParse("900.00"), // 初始1000，已扣100
 	}
 
 	req := &request.UpdateTransactionRequest{
-		Amount: 150.00,
+		Amount: money.MustParse("150.00"),
 	}
 
-	mockTransRepo.On("FindByID", transactionID).Return(oldTransaction, nil).Once()
+	mockTransRepo.On("FindByIDForUpdate", transactionID).Return(oldTransaction, nil)
 	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
 	mockAccountRepo.On("Update", mock.MatchedBy(func(a *models.Account) bool {
 		return a.ID == accountID
 	})).Return(nil)
 	mockTransRepo.On("Update", mock.MatchedBy(func(t *models.Transaction) bool {
-		return t.ID == transactionID && t.Amount == 150.00
+		return t.ID == transactionID && t.Amount == money.MustParse("150.00")
 	})).Return(nil)
 	mockTransRepo.On("FindByID", transactionID).Return(&models.Transaction{
 		ID:        transactionID,
 		UserID:    userID,
-		Amount:    150.00,
+		Amount:    money.MustParse("150.00"),
 		Type:      "expense",
 		AccountID: &accountID,
 	}, nil)
@@ -286,7 +501,7 @@ func TestUpdateTransaction(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.Equal(t, 150.00, resp.Amount)
+	assert.Equal(t, money.MustParse("150.00"), resp.Amount)
 }
 
 // TestUpdateTransactionWithAmountChange 测试金额变化时账户余额更新
@@ -295,7 +510,8 @@ func TestUpdateTransactionWithAmountChange(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+	mockTransRepo.On("GetRefundedTotals", mock.Anything).Return(map[int64]money.Money{}, nil)
 
 	userID := int64(1)
 	transactionID := int64(1)
@@ -305,7 +521,7 @@ func TestUpdateTransactionWithAmountChange(t *testing.T) {
 	oldTransaction := &models.Transaction{
 		ID:        transactionID,
 		UserID:    userID,
-		Amount:    100.00,
+		Amount:    money.MustParse("100.00"),
 		Type:      "expense",
 		AccountID: &accountID,
 	}
@@ -313,23 +529,24 @@ func TestUpdateTransactionWithAmountChange(t *testing.T) {
 	account := &models.Account{
 		ID:      accountID,
 		UserID:  userID,
-		Balance: 900.00,
+		Balance: money.MustParse("900.00"),
 	}
 
 	// 修改金额为80
 	req := &request.UpdateTransactionRequest{
-		Amount: 80.00,
+		Amount: money.MustParse("80.00"),
 	}
 
-	mockTransRepo.On("FindByID", transactionID).Return(oldTransaction, nil).Once()
+	mockTransRepo.On("FindByIDForUpdate", transactionID).Return(oldTransaction, nil)
 	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
 	// 期望账户余额：900 + 100（恢复旧支出）- 80（应用新支出）= 920
 	mockAccountRepo.On("Update", mock.Anything).Return(nil)
 	mockTransRepo.On("Update", mock.Anything).Return(nil)
 	mockTransRepo.On("FindByID", transactionID).Return(&models.Transaction{
 		ID:        transactionID,
 		UserID:    userID,
-		Amount:    80.00,
+		Amount:    money.MustParse("80.00"),
 		Type:      "expense",
 		AccountID: &accountID,
 	}, nil)
@@ -338,7 +555,8 @@ func TestUpdateTransactionWithAmountChange(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.Equal(t, 80.00, resp.Amount)
+	assert.Equal(t, money.MustParse("80.00"), resp.Amount)
+	assert.Equal(t, money.MustParse("920.00"), account.Balance)
 }
 
 // TestUpdateTransactionWithAccountChange 测试付款账户变更
@@ -347,7 +565,7 @@ func TestUpdateTransactionWithAccountChange(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
 
 	userID := int64(1)
 	transactionID := int64(1)
@@ -358,7 +576,7 @@ func TestUpdateTransactionWithAccountChange(t *testing.T) {
 	oldTransaction := &models.Transaction{
 		ID:        transactionID,
 		UserID:    userID,
-		Amount:    100.00,
+		Amount:    money.MustParse("100.00"),
 		Type:      "expense",
 		AccountID: &oldAccountID,
 	}
@@ -366,13 +584,13 @@ func TestUpdateTransactionWithAccountChange(t *testing.T) {
 	oldAccount := &models.Account{
 		ID:      oldAccountID,
 		UserID:  userID,
-		Balance: 900.00, // 已扣款
+		Balance: money.MustParse("900.00"), // 已扣款
 	}
 
 	newAccount := &models.Account{
 		ID:      newAccountID,
 		UserID:  userID,
-		Balance: 500.00,
+		Balance: money.MustParse("500.00"),
 	}
 
 	// 修改账户为200
@@ -380,15 +598,17 @@ func TestUpdateTransactionWithAccountChange(t *testing.T) {
 		AccountID: &newAccountID,
 	}
 
-	mockTransRepo.On("FindByID", transactionID).Return(oldTransaction, nil).Once()
+	mockTransRepo.On("FindByIDForUpdate", transactionID).Return(oldTransaction, nil)
 	mockAccountRepo.On("FindByID", oldAccountID).Return(oldAccount, nil)
+	mockAccountRepo.On("FindByIDForUpdate", oldAccountID).Return(oldAccount, nil)
 	mockAccountRepo.On("FindByID", newAccountID).Return(newAccount, nil)
+	mockAccountRepo.On("FindByIDForUpdate", newAccountID).Return(newAccount, nil)
 	mockAccountRepo.On("Update", mock.Anything).Return(nil)
 	mockTransRepo.On("Update", mock.Anything).Return(nil)
 	mockTransRepo.On("FindByID", transactionID).Return(&models.Transaction{
 		ID:        transactionID,
 		UserID:    userID,
-		Amount:    100.00,
+		Amount:    money.MustParse("100.00"),
 		Type:      "expense",
 		AccountID: &newAccountID,
 	}, nil)
@@ -398,6 +618,119 @@ func TestUpdateTransactionWithAccountChange(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	// 期望：旧账户余额恢复1000，新账户余额变为400
+	assert.Equal(t, money.MustParse("1000.00"), oldAccount.Balance)
+	assert.Equal(t, money.MustParse("400.00"), newAccount.Balance)
+}
+
+// TestUpdateTransferToAccount 测试修改转账的转入账户时校验账户归属和两侧币种
+func TestUpdateTransferToAccount(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, nil)
+
+	walletID, cardID, usdID, otherUserID, savingsID := int64(100), int64(200), int64(300), int64(400), int64(500)
+	wallet := &models.Account{ID: walletID, UserID: 1, Currency: "CNY", Balance: money.MustParse("800.00")}
+	card := &models.Account{ID: cardID, UserID: 1, Currency: "CNY", Balance: money.MustParse("200.00")}
+	savings := &models.Account{ID: savingsID, UserID: 1, Currency: "CNY", Balance: money.MustParse("0.00")}
+	mockAccountRepo.On("FindByID", usdID).Return(&models.Account{ID: usdID, UserID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("FindByID", otherUserID).Return(&models.Account{ID: otherUserID, UserID: 2, Currency: "CNY"}, nil)
+	for _, account := range []*models.Account{wallet, card, savings} {
+		mockAccountRepo.On("FindByID", account.ID).Return(account, nil)
+		mockAccountRepo.On("FindByIDForUpdate", account.ID).Return(account, nil)
+		mockAccountRepo.On("Update", account).Return(nil)
+	}
+	// 失败的修改不落库，每次读取到的都是原交易
+	for i := 0; i < 3; i++ {
+		mockTransRepo.On("FindByIDForUpdate", int64(1)).Return(&models.Transaction{
+			ID: 1, UserID: 1, Type: "transfer", AccountID: &walletID, ToAccountID: &cardID, Amount: money.MustParse("200.00"),
+			Currency: "CNY", ExchangeRate: money.OneRate, TransactionDate: date(2024, 3, 1),
+		}, nil).Once()
+	}
+	mockTransRepo.On("Update", mock.Anything).Return(nil)
+	mockTransRepo.On("FindByID", int64(1)).Return(&models.Transaction{ID: 1}, nil)
+
+	_, err := service.UpdateTransaction(1, 1, &request.UpdateTransactionRequest{ToAccountID: &otherUserID})
+	assert.EqualError(t, err, "invalid to_account")
+	_, err = service.UpdateTransaction(1, 1, &request.UpdateTransactionRequest{ToAccountID: &usdID})
+	assert.Equal(t, errTransferCurrency, err)
+	mockAccountRepo.AssertNotCalled(t, "Update", mock.Anything)
+
+	_, err = service.UpdateTransaction(1, 1, &request.UpdateTransactionRequest{ToAccountID: &savingsID})
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("0.00"), card.Balance)
+	assert.Equal(t, money.MustParse("200.00"), savings.Balance)
+	assert.Equal(t, money.MustParse("800.00"), wallet.Balance)
+}
+
+// TestDeleteTransactionRollbackOnBalanceFailure 测试余额更新失败时不删除交易
+func TestDeleteTransactionRollbackOnBalanceFailure(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+	mockTransRepo.On("GetRefundedTotals", mock.Anything).Return(map[int64]money.Money{}, nil)
+
+	userID := int64(1)
+	transactionID := int64(1)
+	accountID := int64(100)
+
+	mockTransRepo.On("FindByIDForUpdate", transactionID).Return(&models.Transaction{
+		ID:        transactionID,
+		UserID:    userID,
+		Type:      "expense",
+		Amount:    money.MustParse("100.00"),
+		AccountID: &accountID,
+	}, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(nil, errors.New("lock wait timeout"))
+	mockTransRepo.On("HasLinkedRecords", transactionID).Return(false, nil)
+
+	err := service.DeleteTransaction(userID, transactionID)
+
+	assert.Error(t, err)
+	mockTransRepo.AssertNotCalled(t, "Delete", transactionID)
+}
+
+// TestTransferLocksAccountsInOrder 测试转账按账户ID升序加锁
+func TestTransferLocksAccountsInOrder(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+
+	userID := int64(1)
+	fromID := int64(200)
+	toID := int64(100)
+	fromAccount := &models.Account{ID: fromID, UserID: userID, Balance: money.MustParse("500.00")}
+	toAccount := &models.Account{ID: toID, UserID: userID, Balance: 0}
+
+	var locked []int64
+	mockAccountRepo.On("FindByID", fromID).Return(fromAccount, nil)
+	mockAccountRepo.On("FindByID", toID).Return(toAccount, nil)
+	recordLock := func(args mock.Arguments) {
+		locked = append(locked, args.Get(0).(int64))
+	}
+	mockAccountRepo.On("FindByIDForUpdate", fromID).Run(recordLock).Return(fromAccount, nil)
+	mockAccountRepo.On("FindByIDForUpdate", toID).Run(recordLock).Return(toAccount, nil)
+	mockAccountRepo.On("Update", mock.Anything).Return(nil)
+	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transaction).ID = 1
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(1)).Return(&models.Transaction{ID: 1, UserID: userID, Type: "transfer", Amount: money.MustParse("200.00")}, nil)
+
+	_, err := service.CreateTransaction(userID, &request.CreateTransactionRequest{
+		Type:            "transfer",
+		AccountID:       &fromID,
+		ToAccountID:     &toID,
+		Amount:          money.MustParse("200.00"),
+		TransactionDate: time.Now(),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{toID, fromID}, locked)
+	assert.Equal(t, money.MustParse("300.00"), fromAccount.Balance)
+	assert.Equal(t, money.MustParse("200.00"), toAccount.Balance)
 }
 
 // TestDeleteTransaction 测试删除交易
@@ -406,7 +739,8 @@ func TestDeleteTransaction(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+	mockTransRepo.On("GetRefundedTotals", mock.Anything).Return(map[int64]money.Money{}, nil)
 
 	userID := int64(1)
 	transactionID := int64(1)
@@ -416,20 +750,22 @@ func TestDeleteTransaction(t *testing.T) {
 		ID:        transactionID,
 		UserID:    userID,
 		Type:      "expense",
-		Amount:    100.00,
+		Amount:    money.MustParse("100.00"),
 		AccountID: &accountID,
 	}
 
 	account := &models.Account{
 		ID:      accountID,
 		UserID:  userID,
-		Balance: 500.00,
+		Balance: money.MustParse("500.00"),
 	}
 
-	mockTransRepo.On("FindByID", transactionID).Return(transaction, nil)
+	mockTransRepo.On("FindByIDForUpdate", transactionID).Return(transaction, nil)
+	mockTransRepo.On("HasLinkedRecords", transactionID).Return(false, nil)
 	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
 	mockAccountRepo.On("Update", mock.MatchedBy(func(a *models.Account) bool {
-		return a.Balance == 600.00 // 恢复金额
+		return a.Balance == money.MustParse("600.00") // 恢复金额
 	})).Return(nil)
 	mockTransRepo.On("Delete", transactionID).Return(nil)
 
@@ -437,6 +773,8 @@ func TestDeleteTransaction(t *testing.T) {
 
 	assert.NoError(t, err)
 	mockTransRepo.AssertCalled(t, "Delete", transactionID)
+	// 余额回滚基于事务中加锁读取的交易
+	mockTransRepo.AssertNotCalled(t, "FindByID", transactionID)
 }
 
 // TestListTransactions 测试查询交易列表
@@ -445,7 +783,7 @@ func TestListTransactions(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
 
 	userID := int64(1)
 	filters := &request.ListTransactionRequest{
@@ -457,13 +795,13 @@ func TestListTransactions(t *testing.T) {
 		{
 			ID:     1,
 			UserID: userID,
-			Amount: 100.00,
+			Amount: money.MustParse("100.00"),
 			Type:   "expense",
 		},
 		{
 			ID:     2,
 			UserID: userID,
-			Amount: 50.00,
+			Amount: money.MustParse("50.00"),
 			Type:   "income",
 		},
 	}
@@ -484,38 +822,22 @@ func TestGetTransactionStatistics(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
 
 	userID := int64(1)
 	startDate := time.Now().AddDate(0, -1, 0)
 	endDate := time.Now()
 
-	transactions := []*models.Transaction{
-		{
-			ID:     1,
-			UserID: userID,
-			Amount: 100.00,
-			Type:   "income",
-		},
-		{
-			ID:     2,
-			UserID: userID,
-			Amount: 50.00,
-			Type:   "expense",
-		},
-	}
-
-	mockTransRepo.On("FindByUserID", userID, mock.MatchedBy(func(f *request.ListTransactionRequest) bool {
-		return f.StartDate.Equal(startDate) && f.EndDate.Equal(endDate)
-	})).Return(transactions, int64(2), nil)
+	mockTransRepo.On("GetDateRangeStatistics", userID, startDate, endDate).
+		Return(money.MustParse("100.00"), money.MustParse("50.00"), int64(2), nil)
 
 	resp, err := service.GetTransactionStatistics(userID, startDate, endDate)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.Equal(t, 100.00, resp.TotalIncome)
-	assert.Equal(t, 50.00, resp.TotalExpense)
-	assert.Equal(t, 50.00, resp.NetAmount)
+	assert.Equal(t, money.MustParse("100.00"), resp.TotalIncome)
+	assert.Equal(t, money.MustParse("50.00"), resp.TotalExpense)
+	assert.Equal(t, money.MustParse("50.00"), resp.NetAmount)
 	assert.Equal(t, int64(2), resp.TransactionCnt)
 }
 
@@ -525,7 +847,7 @@ func TestCreateBatchTransactions(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
 
 	userID := int64(1)
 	accountID := int64(100)
@@ -535,7 +857,7 @@ func TestCreateBatchTransactions(t *testing.T) {
 			{
 				Type:            "expense",
 				AccountID:       &accountID,
-				Amount:          100.00,
+				Amount:          money.MustParse("100.00"),
 				TransactionDate: time.Now(),
 			},
 		},
@@ -547,6 +869,7 @@ func TestCreateBatchTransactions(t *testing.T) {
 	}
 
 	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
 	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
 		trans := args.Get(0).(*models.Transaction)
 		trans.ID = 1
@@ -555,7 +878,7 @@ func TestCreateBatchTransactions(t *testing.T) {
 	mockTransRepo.On("FindByID", int64(1)).Return(&models.Transaction{
 		ID:      1,
 		UserID:  userID,
-		Amount:  100.00,
+		Amount:  money.MustParse("100.00"),
 		Type:    "expense",
 		Account: account,
 	}, nil)
@@ -573,7 +896,7 @@ func TestInvalidCategoryID(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
 
-	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
 
 	userID := int64(1)
 	accountID := int64(100)
@@ -583,7 +906,7 @@ func TestInvalidCategoryID(t *testing.T) {
 		Type:            "expense",
 		CategoryID:      &invalidCategoryID,
 		AccountID:       &accountID,
-		Amount:          100.00,
+		Amount:          money.MustParse("100.00"),
 		TransactionDate: time.Now(),
 	}
 
@@ -593,6 +916,7 @@ func TestInvalidCategoryID(t *testing.T) {
 	}
 
 	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
 	mockCategoryRepo.On("FindByID", invalidCategoryID).Return(nil, errors.New("category not found"))
 
 	resp, err := service.CreateTransaction(userID, req)
@@ -601,3 +925,371 @@ func TestInvalidCategoryID(t *testing.T) {
 	assert.Nil(t, resp)
 	assert.Equal(t, "invalid category", err.Error())
 }
+
+// TestCreateSplitTransaction 测试拆分交易：明细金额之和须等于交易金额，主分类取金额最大的明细
+func TestCreateSplitTransaction(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+
+	userID := int64(1)
+	accountID := int64(100)
+	groceries, household := int64(10), int64(11)
+	account := &models.Account{ID: accountID, UserID: userID, Balance: money.MustParse("500.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockCategoryRepo.On("FindByID", groceries).Return(&models.Category{ID: groceries, UserID: userID, Type: "expense"}, nil)
+	mockCategoryRepo.On("FindByID", household).Return(&models.Category{ID: household, UserID: 0, Type: "expense"}, nil)
+
+	req := &request.CreateTransactionRequest{
+		Type:            "expense",
+		AccountID:       &accountID,
+		Amount:          money.MustParse("100.00"),
+		Title:           "超市",
+		TransactionDate: time.Now(),
+		Splits: []request.SplitRequest{
+			{CategoryID: &groceries, Amount: money.MustParse("35.00"), Note: "蔬菜水果"},
+			{CategoryID: &household, Amount: money.MustParse("60.00"), Note: "洗衣液"},
+		},
+	}
+
+	// 金额不一致
+	_, err := service.CreateTransaction(userID, req)
+	assert.EqualError(t, err, "拆分金额合计95.00与交易金额100.00不一致")
+
+	// 只有一项
+	_, err = service.CreateTransaction(userID, &request.CreateTransactionRequest{
+		Type: "expense", AccountID: &accountID, Amount: money.MustParse("35.00"), TransactionDate: time.Now(),
+		Splits: req.Splits[:1],
+	})
+	assert.Equal(t, errSplitTooFew, err)
+
+	req.Splits[0].Amount = money.MustParse("40.00")
+	var created *models.Transaction
+	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.Transaction)
+		created.ID = 1
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(1)).Return(&models.Transaction{ID: 1, UserID: userID}, nil)
+
+	_, err = service.CreateTransaction(userID, req)
+	assert.NoError(t, err)
+	assert.Equal(t, household, *created.CategoryID)
+	assert.Len(t, created.Splits, 2)
+	assert.Equal(t, "蔬菜水果", created.Splits[0].Note)
+	assert.Equal(t, 1, created.Splits[1].DisplayOrder)
+	assert.Equal(t, money.MustParse("400.00"), account.Balance)
+}
+
+// TestUpdateTransactionAmountWithSplits 测试修改已拆分交易的金额时须同时调整明细
+func TestUpdateTransactionAmountWithSplits(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+	mockTransRepo.On("GetRefundedTotals", mock.Anything).Return(map[int64]money.Money{}, nil)
+
+	userID := int64(1)
+	groceries, household := int64(10), int64(11)
+	mockTransRepo.On("FindByIDForUpdate", int64(1)).Return(&models.Transaction{
+		ID:     1,
+		UserID: userID,
+		Type:   "expense",
+		Amount: money.MustParse("100.00"),
+		Splits: []*models.TransactionSplit{
+			{ID: 1, CategoryID: &groceries, Amount: money.MustParse("40.00")},
+			{ID: 2, CategoryID: &household, Amount: money.MustParse("60.00")},
+		},
+	}, nil)
+
+	_, err := service.UpdateTransaction(userID, 1, &request.UpdateTransactionRequest{Amount: money.MustParse("120.00")})
+	assert.EqualError(t, err, "拆分金额合计100.00与交易金额120.00不一致")
+
+	_, err = service.UpdateTransaction(userID, 1, &request.UpdateTransactionRequest{Type: "transfer"})
+	assert.Equal(t, errSplitTransfer, err)
+	mockTransRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+// TestGetCategoryStatistics 测试分类统计使用按明细汇总的结果计算占比
+func TestGetCategoryStatistics(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+
+	userID := int64(1)
+	groceries, household := int64(10), int64(11)
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.Local)
+	mockTransRepo.On("GetCategoryStatistics", userID, start, end).Return([]*models.Transaction{
+		{CategoryID: &groceries, Amount: money.MustParse("40.00"), ID: 1},
+		{CategoryID: &household, Amount: money.MustParse("140.00"), ID: 2},
+		{CategoryID: nil, Amount: money.MustParse("20.00"), ID: 1},
+	}, nil)
+	mockCategoryRepo.On("FindByID", groceries).Return(&models.Category{ID: groceries, Name: "食品"}, nil)
+	mockCategoryRepo.On("FindByID", household).Return(&models.Category{ID: household, Name: "日用"}, nil)
+
+	stats, err := service.GetCategoryStatistics(userID, start, end, false)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "食品", stats[0].Category.Name)
+	assert.Equal(t, 20.0, stats[0].Percentage)
+	assert.Equal(t, int64(2), stats[1].TransactionCnt)
+	assert.Equal(t, 70.0, stats[1].Percentage)
+}
+
+// TestGetCategoryStatisticsRollup 测试分类统计汇总到顶级分类，父分类自身的支出与子分类合并
+func TestGetCategoryStatisticsRollup(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+
+	userID := int64(1)
+	food, coffee, pourOver, transport := int64(1), int64(10), int64(11), int64(2)
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.Local)
+	mockTransRepo.On("GetCategoryStatistics", userID, start, end).Return([]*models.Transaction{
+		{CategoryID: &transport, Amount: money.MustParse("90.00"), ID: 3},
+		{CategoryID: &pourOver, Amount: money.MustParse("60.00"), ID: 2},
+		{CategoryID: &food, Amount: money.MustParse("30.00"), ID: 1},
+		{CategoryID: &coffee, Amount: money.MustParse("20.00"), ID: 1},
+	}, nil)
+	mockCategoryRepo.On("FindByID", food).Return(&models.Category{ID: food, Name: "餐饮"}, nil)
+	mockCategoryRepo.On("FindByID", coffee).Return(&models.Category{ID: coffee, Name: "咖啡", ParentID: &food}, nil)
+	mockCategoryRepo.On("FindByID", pourOver).Return(&models.Category{ID: pourOver, Name: "手冲", ParentID: &coffee}, nil)
+	mockCategoryRepo.On("FindByID", transport).Return(&models.Category{ID: transport, Name: "交通"}, nil)
+
+	stats, err := service.GetCategoryStatistics(userID, start, end, true)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "餐饮", stats[0].Category.Name)
+	assert.Equal(t, money.MustParse("110.00"), stats[0].TotalAmount)
+	assert.Equal(t, int64(4), stats[0].TransactionCnt)
+	assert.Equal(t, "交通", stats[1].Category.Name)
+	assert.Equal(t, 45.0, stats[1].Percentage)
+}
+
+// TestRecategorizeTransactions 测试批量修改分类：须有筛选条件，按分类的收支类型修改，修改后丢弃分类建议模型
+func TestRecategorizeTransactions(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockSuggestions := new(MockCategorySuggestionService)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+	service.(*transactionService).suggestions = mockSuggestions
+
+	userID := int64(1)
+	coffee := int64(10)
+	mockCategoryRepo.On("FindByID", coffee).Return(&models.Category{ID: coffee, UserID: userID, Type: "expense", IsActive: true}, nil)
+
+	_, err := service.RecategorizeTransactions(userID, &request.ListTransactionRequest{}, &request.RecategorizeTransactionRequest{CategoryID: coffee})
+	assert.Equal(t, errRecategorizeAll, err)
+
+	_, err = service.RecategorizeTransactions(userID, &request.ListTransactionRequest{Type: "income"}, &request.RecategorizeTransactionRequest{CategoryID: coffee})
+	assert.Equal(t, errRecategorizeType, err)
+
+	filters := &request.ListTransactionRequest{SearchKeyword: "星巴克"}
+	mockTransRepo.On("UpdateCategoryByFilter", userID, filters, "expense", coffee).Return(int64(7), nil)
+	mockSuggestions.On("InvalidateModel", userID).Return()
+
+	resp, err := service.RecategorizeTransactions(userID, filters, &request.RecategorizeTransactionRequest{CategoryID: coffee})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), resp.SuccessCount)
+	mockSuggestions.AssertNumberOfCalls(t, "InvalidateModel", 1)
+}
+
+// TestCreateRefund 测试部分退款：沿用原支出分类，累计退款不能超过原支出金额
+func TestCreateRefund(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+
+	userID := int64(1)
+	accountID := int64(100)
+	categoryID := int64(10)
+	account := &models.Account{ID: accountID, UserID: userID, Balance: money.MustParse("500.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockTransRepo.On("FindByID", int64(1)).Return(&models.Transaction{
+		ID: 1, UserID: userID, Type: "expense", CategoryID: &categoryID, Amount: money.MustParse("200.00"),
+	}, nil)
+	mockTransRepo.On("GetRefundedTotals", []int64{1}).Return(map[int64]money.Money{1: money.MustParse("150.00")}, nil)
+
+	originalID := int64(1)
+	req := &request.CreateTransactionRequest{
+		Type:            "income",
+		AccountID:       &accountID,
+		Amount:          money.MustParse("80.00"),
+		TransactionDate: time.Now(),
+		RefundOfID:      &originalID,
+	}
+	_, err := service.CreateTransaction(userID, req)
+	assert.EqualError(t, err, "退款金额超过原支出可退金额50.00")
+
+	req.Type = "expense"
+	_, err = service.CreateTransaction(userID, req)
+	assert.Equal(t, errRefundType, err)
+
+	var created *models.Transaction
+	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.Transaction)
+		created.ID = 2
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(2)).Return(&models.Transaction{ID: 2, UserID: userID}, nil)
+
+	req.Type = "income"
+	req.Amount = money.MustParse("50.00")
+	_, err = service.CreateTransaction(userID, req)
+	assert.NoError(t, err)
+	assert.Equal(t, categoryID, *created.CategoryID)
+	assert.Equal(t, originalID, *created.RefundOfID)
+	assert.Equal(t, money.MustParse("550.00"), account.Balance)
+
+	// 有退款的支出不能直接删除
+	mockTransRepo.On("FindByIDForUpdate", int64(1)).Return(&models.Transaction{ID: 1, UserID: userID, Type: "expense", Amount: money.MustParse("200.00")}, nil)
+	mockTransRepo.On("HasLinkedRecords", int64(1)).Return(false, nil)
+	assert.Equal(t, errHasRefunds, service.DeleteTransaction(userID, 1))
+}
+
+// TestDeleteLinkedTransaction 测试储蓄、账单、心愿单或贷款生成的交易不能直接删除
+func TestDeleteLinkedTransaction(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, nil)
+
+	billID := int64(5)
+	mockTransRepo.On("FindByIDForUpdate", int64(1)).Return(&models.Transaction{ID: 1, UserID: 1, Type: "expense", BillID: &billID}, nil)
+	mockTransRepo.On("HasLinkedRecords", int64(1)).Return(true, nil)
+	assert.Equal(t, errLinkedDelete, service.DeleteTransaction(1, 1))
+
+	// 储蓄存取款和贷款分期只在关联记录中引用交易
+	mockTransRepo.On("FindByIDForUpdate", int64(2)).Return(&models.Transaction{ID: 2, UserID: 1, Type: "transfer"}, nil)
+	mockTransRepo.On("HasLinkedRecords", int64(2)).Return(true, nil)
+	assert.Equal(t, errLinkedDelete, service.DeleteTransaction(1, 2))
+
+	mockTransRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	mockAccountRepo.AssertNotCalled(t, "Update", mock.Anything)
+
+	// 付款记录已不存在时账单ID不再阻止删除
+	accountID := int64(100)
+	account := &models.Account{ID: accountID, UserID: 1, Balance: money.MustParse("75.00")}
+	mockTransRepo.On("FindByIDForUpdate", int64(3)).Return(&models.Transaction{ID: 3, UserID: 1, Type: "expense", BillID: &billID,
+		AccountID: &accountID, Amount: money.MustParse("25.00")}, nil)
+	mockTransRepo.On("HasLinkedRecords", int64(3)).Return(false, nil)
+	mockTransRepo.On("GetRefundedTotals", []int64{3}).Return(map[int64]money.Money{}, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockTransRepo.On("Delete", int64(3)).Return(nil)
+	assert.NoError(t, service.DeleteTransaction(1, 3))
+	assert.Equal(t, money.MustParse("100.00"), account.Balance)
+}
+
+// TestGetPendingReimbursements 测试待报销报告扣除已报销到账的金额
+func TestGetPendingReimbursements(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := newTestTransactionService(&MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, mockCategoryRepo)
+
+	userID := int64(1)
+	mockTransRepo.On("FindPendingReimbursements", userID).Return([]*models.Transaction{
+		{ID: 1, UserID: userID, Type: "expense", Amount: money.MustParse("300.00"), Reimbursable: true},
+		{ID: 2, UserID: userID, Type: "expense", Amount: money.MustParse("88.00"), Reimbursable: true},
+	}, nil)
+	mockTransRepo.On("GetRefundedTotals", []int64{1, 2}).Return(map[int64]money.Money{1: money.MustParse("100.00")}, nil)
+
+	report, err := service.GetPendingReimbursements(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Count)
+	assert.Equal(t, money.MustParse("288.00"), report.TotalPending)
+	assert.Equal(t, money.MustParse("100.00"), report.Items[0].ReimbursedAmount)
+	assert.Equal(t, money.MustParse("200.00"), report.Items[0].PendingAmount)
+	assert.True(t, report.Items[1].Transaction.Reimbursable)
+}
+
+// MockCurrencyConverter Mock CurrencyConverter
+type MockCurrencyConverter struct {
+	mock.Mock
+}
+
+func (m *MockCurrencyConverter) BaseCurrency(userID int64) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCurrencyConverter) GetRate(userID int64, from, to string, date time.Time) (money.Rate, error) {
+	args := m.Called(userID, from, to, date)
+	return args.Get(0).(money.Rate), args.Error(1)
+}
+
+// TestCreateForeignCurrencyTransaction 测试外币交易沿用账户币种并记录记账时的汇率，折算溢出时拒绝记账
+func TestCreateForeignCurrencyTransaction(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockRates := new(MockCurrencyConverter)
+
+	service := NewTransactionService(mockTransRepo, mockAccountRepo, mockCategoryRepo, &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo}, nil, nil, mockRates, nil, nil)
+
+	userID := int64(1)
+	accountID := int64(100)
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)
+	account := &models.Account{ID: accountID, UserID: userID, Currency: "USD", Balance: money.MustParse("500.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", mock.Anything).Return(nil)
+	mockRates.On("BaseCurrency", userID).Return("CNY", nil)
+	mockRates.On("GetRate", userID, "USD", "CNY", date).Return(money.MustParseRate("7.2"), nil)
+
+	created := &models.Transaction{}
+	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		trans := args.Get(0).(*models.Transaction)
+		trans.ID = 1
+		*created = *trans
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(1)).Return(created, nil)
+
+	resp, err := service.CreateTransaction(userID, &request.CreateTransactionRequest{
+		Type:            "expense",
+		AccountID:       &accountID,
+		Amount:          money.MustParse("25.00"),
+		TransactionDate: date,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", resp.Currency)
+	assert.Equal(t, money.MustParseRate("7.2"), resp.ExchangeRate)
+	assert.Equal(t, money.MustParse("180.00"), resp.BaseAmount)
+	assert.Equal(t, money.MustParse("475.00"), account.Balance)
+
+	// 折算为本位币后超出金额范围时拒绝记账，而不是记为零
+	_, err = service.CreateTransaction(userID, &request.CreateTransactionRequest{
+		Type:            "expense",
+		AccountID:       &accountID,
+		Amount:          money.FromCents(1 << 62),
+		TransactionDate: date,
+	})
+	assert.Equal(t, errPostingRate, err)
+	mockTransRepo.AssertNumberOfCalls(t, "Create", 1)
+
+	// 与账户币种不一致的交易被拒绝
+	_, err = service.CreateTransaction(userID, &request.CreateTransactionRequest{
+		Type:            "expense",
+		AccountID:       &accountID,
+		Amount:          money.MustParse("25.00"),
+		Currency:        "EUR",
+		TransactionDate: date,
+	})
+	assert.Equal(t, errCurrencyMismatch, err)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/qiuhaonan/float-backend/internal/utils"
 	"github.com/qiuhaonan/float-backend/pkg/email"
 	"github.com/qiuhaonan/float-backend/pkg/logger"
+	"github.com/qiuhaonan/float-backend/pkg/money"
 	"github.com/spf13/viper"
 )
 
@@ -27,16 +28,36 @@ type UserService interface {
 	GetUserStats(userID int64) (*response.UserStatsResponse, error)
 	GetSystemOverview() (map[string]interface{}, error)
 	ListUsers(page, pageSize int) ([]*response.UserResponse, int64, error)
+	// RecomputeRecordStats 按交易记录重新计算全部用户的记账笔数和连续记账天数，返回校正的用户数
+	RecomputeRecordStats(now time.Time) (int, error)
 }
 
+const recomputePageSize = 200 // 校正记账统计时每批处理的用户数
+
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo        repository.UserRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	budgetRepo      repository.BudgetRepository
+	billRepo        repository.BillRepository
+	savingsRepo     repository.SavingsRepository
+	wishlistRepo    repository.WishlistRepository
+	loanRepo        repository.LoanRepository
+	rates           CurrencyConverter
 }
 
 // NewUserService 创建用户服务实例
 func NewUserService() UserService {
 	return &userService{
-		userRepo: repository.NewUserRepository(),
+		userRepo:        repository.NewUserRepository(),
+		accountRepo:     repository.NewAccountRepository(),
+		transactionRepo: repository.NewTransactionRepository(),
+		budgetRepo:      repository.NewBudgetRepository(),
+		billRepo:        repository.NewBillRepository(),
+		savingsRepo:     repository.NewSavingsRepository(),
+		wishlistRepo:    repository.NewWishlistRepository(),
+		loanRepo:        repository.NewLoanRepository(),
+		rates:           NewExchangeRateService(repository.NewExchangeRateRepository(), repository.NewUserRepository()),
 	}
 }
 
@@ -316,7 +337,7 @@ func (s *userService) GetUserByID(userID int64) (*response.UserResponse, error)
 		Language:        user.Language,
 		DarkMode:        user.DarkMode,
 		GestureLock:     user.GestureLock,
-		ContinuousDays:  user.ContinuousDays,
+		ContinuousDays:  currentStreak(user, time.Now()),
 		TotalRecords:    user.TotalRecords,
 		TotalBadges:     user.TotalBadges,
 		MembershipLevel: user.MembershipLevel,
@@ -409,28 +430,178 @@ func (s *userService) GetUserStats(userID int64) (*response.UserStatsResponse, e
 		return nil, err
 	}
 
-	// TODO: 从其他表计算统计数据
-	// 这里先返回模拟数据，后续实现其他模块后再补充
+	activeBudgets, err := s.budgetRepo.CountActive(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[用户服务][获取统计] 统计预算失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+	activeSavings, err := s.savingsRepo.CountActive(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[用户服务][获取统计] 统计储蓄计划失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+	activeWishlists, err := s.wishlistRepo.CountActive(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[用户服务][获取统计] 统计心愿单失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	activeBills, err := s.billRepo.CountActive(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[用户服务][获取统计] 统计账单失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	base, err := s.rates.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 资产负债：账户余额按当前汇率折算，贷款/分期未到期的剩余本金计入负债
+	accounts, err := s.accountRepo.FindByUserID(userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[用户服务][获取统计] 查询账户失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+	totalAssets, totalDebt, err := accountTotals(s.rates, userID, base, accounts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[用户服务][获取统计] 汇总账户余额失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+	loanDebt, err := s.loanDebt(userID, base)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[用户服务][获取统计] 统计贷款失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+	totalDebt += loanDebt
+
+	// 本月收支按记账汇率折算为本位币
 	now := time.Now()
-	_ = now
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	monthIncome, monthExpense, _, err := s.transactionRepo.GetDateRangeStatistics(userID, monthStart, monthStart.AddDate(0, 1, -1))
+	if err != nil {
+		logger.Error(fmt.Sprintf("[用户服务][获取统计] 统计本月收支失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
 
 	logger.Info(fmt.Sprintf("[用户服务][获取统计] 成功获取用户统计信息 | 用户ID: %d", userID))
 	return &response.UserStatsResponse{
-		TotalAssets:     0,
-		TotalDebt:       0,
-		NetWorth:        0,
+		TotalAssets:     totalAssets,
+		TotalDebt:       totalDebt,
+		NetWorth:        totalAssets - totalDebt,
 		TotalRecords:    user.TotalRecords,
-		ContinuousDays:  user.ContinuousDays,
-		MonthIncome:     0,
-		MonthExpense:    0,
-		MonthNet:        0,
-		ActiveBudgets:   0,
-		ActiveBills:     0,
-		ActiveSavings:   0,
-		ActiveWishlists: 0,
+		ContinuousDays:  currentStreak(user, now),
+		MonthIncome:     monthIncome,
+		MonthExpense:    monthExpense,
+		MonthNet:        monthIncome - monthExpense,
+		ActiveBudgets:   int(activeBudgets),
+		ActiveBills:     int(activeBills),
+		ActiveSavings:   int(activeSavings),
+		ActiveWishlists: int(activeWishlists),
 	}, nil
 }
 
+// loanDebt 进行中贷款/分期的剩余本金合计，按当前汇率折算为本位币
+func (s *userService) loanDebt(userID int64, base string) (money.Money, error) {
+	loans, err := s.loanRepo.FindByUserID(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var total money.Money
+	for _, loan := range loans {
+		if loan.Status != models.LoanStatusActive {
+			continue
+		}
+		outstanding, err := convertToBase(s.rates, userID, base, loan.OutstandingPrincipal, loan.Currency)
+		if err != nil {
+			return 0, err
+		}
+		total += outstanding
+	}
+	return total, nil
+}
+
+// RecomputeRecordStats 逐批校正用户的记账笔数和连续记账天数，修复增量累计产生的偏差（如删除交易后的连续天数）
+func (s *userService) RecomputeRecordStats(now time.Time) (int, error) {
+	repaired := 0
+	for page := 1; ; page++ {
+		users, err := s.userRepo.FindAll(page, recomputePageSize)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[用户服务][校正统计] 查询用户失败 | 页码: %d | 错误: %v", page, err))
+			return repaired, err
+		}
+
+		for _, user := range users {
+			total, err := s.transactionRepo.CountRecordsByUserID(user.ID)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[用户服务][校正统计] 统计交易失败 | 用户ID: %d | 错误: %v", user.ID, err))
+				continue
+			}
+			dates, err := s.transactionRepo.GetRecordDates(user.ID)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[用户服务][校正统计] 查询记账日期失败 | 用户ID: %d | 错误: %v", user.ID, err))
+				continue
+			}
+
+			streak, lastRecord := recordStreak(dates)
+			if int(total) == user.TotalRecords && streak == user.ContinuousDays && sameDate(lastRecord, user.LastRecordDate) {
+				continue
+			}
+			if err := s.userRepo.UpdateRecordStats(user.ID, int(total), streak, lastRecord); err != nil {
+				logger.Error(fmt.Sprintf("[用户服务][校正统计] 更新失败 | 用户ID: %d | 错误: %v", user.ID, err))
+				continue
+			}
+			logger.Info(fmt.Sprintf("[用户服务][校正统计] 已校正 | 用户ID: %d | 记账笔数: %d -> %d | 连续天数: %d -> %d",
+				user.ID, user.TotalRecords, total, user.ContinuousDays, streak))
+			repaired++
+		}
+
+		if len(users) < recomputePageSize {
+			break
+		}
+	}
+
+	logger.Info(fmt.Sprintf("[用户服务][校正统计] 完成 | 时间: %s | 校正用户数: %d", now.Format("2006-01-02 15:04"), repaired))
+	return repaired, nil
+}
+
+// recordStreak 根据倒序的记账日期计算截至最近记账日的连续天数
+func recordStreak(dates []time.Time) (int, *time.Time) {
+	if len(dates) == 0 {
+		return 0, nil
+	}
+
+	last := truncateToDate(dates[0])
+	streak := 1
+	for i := 1; i < len(dates); i++ {
+		if !truncateToDate(dates[i]).Equal(last.AddDate(0, 0, -streak)) {
+			break
+		}
+		streak++
+	}
+	return streak, &last
+}
+
+// currentStreak 当前的连续记账天数，最近一次记账早于昨天时已中断
+func currentStreak(user *models.User, now time.Time) int {
+	if user.LastRecordDate == nil {
+		return 0
+	}
+	if truncateToDate(*user.LastRecordDate).Before(truncateToDate(now).AddDate(0, 0, -1)) {
+		return 0
+	}
+	return user.ContinuousDays
+}
+
+// sameDate 判断两个可为空的日期是否为同一天
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return truncateToDate(*a).Equal(truncateToDate(*b))
+}
+
 // GetSystemOverview 获取系统概览（管理员）
 func (s *userService) GetSystemOverview() (map[string]interface{}, error) {
 	totalUsers, err := s.userRepo.Count()
@@ -2,11 +2,268 @@ package service
 
 import (
 	"testing"
+	"time"
 
 	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
 	"github.com/qiuhaonan/float-backend/pkg/email"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+// MockUserRepository Mock UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(id int64) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByUsername(username string) (*models.User, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Count() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) FindAll(page, pageSize int) ([]*models.User, error) {
+	args := m.Called(page, pageSize)
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) AddRecords(userID int64, count int, day time.Time) error {
+	args := m.Called(userID, count, day)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RemoveRecords(userID int64, count int) error {
+	args := m.Called(userID, count)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateRecordStats(userID int64, totalRecords, continuousDays int, lastRecordDate *time.Time) error {
+	args := m.Called(userID, totalRecords, continuousDays, lastRecordDate)
+	return args.Error(0)
+}
+
+// TestGetUserStats 测试资产负债按本位币汇总（排除不计入总资产的账户，信用卡欠款与贷款剩余本金计入负债）及本月收支
+func TestGetUserStats(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockBudgetRepo := new(MockBudgetRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockSavingsRepo := new(MockSavingsRepository)
+	mockWishlistRepo := new(MockWishlistRepository)
+	mockLoanRepo := new(MockLoanRepository)
+	mockRates := new(MockCurrencyConverter)
+	service := &userService{
+		userRepo:        mockUserRepo,
+		accountRepo:     mockAccountRepo,
+		transactionRepo: mockTransRepo,
+		budgetRepo:      mockBudgetRepo,
+		billRepo:        mockBillRepo,
+		savingsRepo:     mockSavingsRepo,
+		wishlistRepo:    mockWishlistRepo,
+		loanRepo:        mockLoanRepo,
+		rates:           mockRates,
+	}
+
+	yesterday := truncateToDate(time.Now()).AddDate(0, 0, -1)
+	mockUserRepo.On("FindByID", int64(1)).Return(&models.User{ID: 1, TotalRecords: 42, ContinuousDays: 5, LastRecordDate: &yesterday}, nil)
+	mockBudgetRepo.On("CountActive", int64(1)).Return(int64(3), nil)
+	mockBillRepo.On("CountActive", int64(1)).Return(int64(2), nil)
+	mockSavingsRepo.On("CountActive", int64(1)).Return(int64(1), nil)
+	mockWishlistRepo.On("CountActive", int64(1)).Return(int64(0), nil)
+	mockRates.On("BaseCurrency", int64(1)).Return("CNY", nil)
+	mockRates.On("GetRate", int64(1), "USD", "CNY", mock.Anything).Return(money.MustParseRate("7.2"), nil)
+
+	mockAccountRepo.On("FindByUserID", int64(1)).Return([]*models.Account{
+		{ID: 1, AccountType: "bank", Balance: money.MustParse("1000.00"), Currency: "CNY", IncludeInTotal: true},
+		{ID: 2, AccountType: models.AccountTypeCredit, Balance: money.MustParse("-300.00"), Currency: "CNY", IncludeInTotal: true},
+		{ID: 3, AccountType: "bank", Balance: money.MustParse("100.00"), Currency: "USD", IncludeInTotal: true},
+		{ID: 4, AccountType: "investment", Balance: money.MustParse("500.00"), Currency: "CNY", IncludeInTotal: false},
+	}, nil)
+	mockLoanRepo.On("FindByUserID", int64(1)).Return([]*models.Loan{
+		{ID: 1, OutstandingPrincipal: money.MustParse("2000.00"), Currency: "CNY", Status: models.LoanStatusActive},
+		{ID: 2, OutstandingPrincipal: money.MustParse("800.00"), Currency: "CNY", Status: models.LoanStatusCancelled},
+	}, nil)
+	mockTransRepo.On("GetDateRangeStatistics", int64(1), mock.Anything, mock.Anything).
+		Return(money.MustParse("5000.00"), money.MustParse("1200.00"), int64(12), nil)
+
+	stats, err := service.GetUserStats(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("1720.00"), stats.TotalAssets)
+	assert.Equal(t, money.MustParse("2300.00"), stats.TotalDebt)
+	assert.Equal(t, money.MustParse("-580.00"), stats.NetWorth)
+	assert.Equal(t, money.MustParse("3800.00"), stats.MonthNet)
+	assert.Equal(t, 42, stats.TotalRecords)
+	assert.Equal(t, 5, stats.ContinuousDays)
+	assert.Equal(t, 2, stats.ActiveBills)
+
+	// 本月统计区间为当月1日至月末
+	call := mockTransRepo.Calls[0]
+	start, end := call.Arguments.Get(1).(time.Time), call.Arguments.Get(2).(time.Time)
+	assert.Equal(t, 1, start.Day())
+	assert.Equal(t, start.Month(), end.Month())
+	assert.Equal(t, start.AddDate(0, 1, 0).Month(), end.AddDate(0, 0, 1).Month())
+}
+
+// TestRecordStreak 测试连续记账天数的计算与中断判断
+func TestRecordStreak(t *testing.T) {
+	streak, last := recordStreak([]time.Time{date(2024, 3, 10), date(2024, 3, 9), date(2024, 3, 8), date(2024, 3, 6)})
+	assert.Equal(t, 3, streak)
+	assert.Equal(t, date(2024, 3, 10), *last)
+
+	streak, last = recordStreak(nil)
+	assert.Equal(t, 0, streak)
+	assert.Nil(t, last)
+
+	lastRecord := date(2024, 3, 10)
+	user := &models.User{ContinuousDays: 3, LastRecordDate: &lastRecord}
+	assert.Equal(t, 3, currentStreak(user, time.Date(2024, 3, 11, 23, 0, 0, 0, time.Local)))
+	assert.Equal(t, 0, currentStreak(user, date(2024, 3, 12)))
+	assert.Equal(t, 0, currentStreak(&models.User{}, date(2024, 3, 12)))
+}
+
+// TestRecomputeRecordStats 测试只校正与交易记录不一致的用户
+func TestRecomputeRecordStats(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	service := &userService{userRepo: mockUserRepo, transactionRepo: mockTransRepo}
+
+	lastRecord := date(2024, 3, 10)
+	mockUserRepo.On("FindAll", 1, recomputePageSize).Return([]*models.User{
+		{ID: 1, TotalRecords: 3, ContinuousDays: 2, LastRecordDate: &lastRecord},
+		{ID: 2, TotalRecords: 10, ContinuousDays: 4, LastRecordDate: &lastRecord},
+	}, nil)
+	mockTransRepo.On("CountRecordsByUserID", int64(1)).Return(int64(3), nil)
+	mockTransRepo.On("GetRecordDates", int64(1)).Return([]time.Time{date(2024, 3, 10), date(2024, 3, 9)}, nil)
+	// 删除交易后笔数与连续天数均已偏离
+	mockTransRepo.On("CountRecordsByUserID", int64(2)).Return(int64(8), nil)
+	mockTransRepo.On("GetRecordDates", int64(2)).Return([]time.Time{date(2024, 3, 10), date(2024, 3, 8)}, nil)
+	mockUserRepo.On("UpdateRecordStats", int64(2), 8, 1, &lastRecord).Return(nil)
+
+	repaired, err := service.RecomputeRecordStats(date(2024, 3, 11))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repaired)
+	mockUserRepo.AssertNotCalled(t, "UpdateRecordStats", int64(1), mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestTransactionRecordCounters 测试记账与删除交易在同一事务中更新记账统计
+func TestTransactionRecordCounters(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockUserRepo := new(MockUserRepository)
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo, userRepo: mockUserRepo}
+	service := newTestTransactionService(uow, nil)
+
+	account := &models.Account{ID: 9, UserID: 1, Currency: "CNY"}
+	mockAccountRepo.On("FindByID", int64(9)).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", int64(9)).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockTransRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transaction).ID = 7
+	}).Return(nil)
+	accountID := int64(9)
+	created := &models.Transaction{ID: 7, UserID: 1, Type: "income", AccountID: &accountID, Amount: money.MustParse("50.00")}
+	mockTransRepo.On("FindByID", int64(7)).Return(created, nil)
+	mockTransRepo.On("FindByIDForUpdate", int64(7)).Return(created, nil)
+	mockTransRepo.On("HasLinkedRecords", int64(7)).Return(false, nil)
+	mockTransRepo.On("Delete", int64(7)).Return(nil)
+	mockUserRepo.On("AddRecords", int64(1), 1, truncateToDate(time.Now())).Return(nil)
+	mockUserRepo.On("RemoveRecords", int64(1), 1).Return(nil)
+
+	_, err := service.CreateTransaction(1, &request.CreateTransactionRequest{
+		Type:            "income",
+		AccountID:       &accountID,
+		Amount:          money.MustParse("50.00"),
+		Title:           "红包",
+		TransactionDate: time.Now(),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, service.DeleteTransaction(1, 7))
+	mockUserRepo.AssertExpectations(t)
+}
+
+// TestGeneratedTransactionsNotCounted 测试周期规则、导入和贷款分期自动生成的交易不计入记账统计
+func TestGeneratedTransactionsNotCounted(t *testing.T) {
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockUserRepo := new(MockUserRepository)
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo, userRepo: mockUserRepo}
+	service := newTestTransactionService(uow, nil)
+
+	accountID := int64(9)
+	account := &models.Account{ID: accountID, UserID: 1, Currency: "CNY", Balance: money.MustParse("1000.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+	mockTransRepo.On("Create", mock.Anything).Return(nil)
+	mockTransRepo.On("FindByID", mock.Anything).Return(&models.Transaction{}, nil)
+
+	ruleID, batchID := int64(3), int64(12)
+	for _, req := range []*request.CreateTransactionRequest{
+		{RecurringRuleID: &ruleID},
+		{ImportBatchID: &batchID, RulesApplied: true},
+		{AutoPosted: true},
+	} {
+		req.Type = "expense"
+		req.AccountID = &accountID
+		req.Amount = money.MustParse("10.00")
+		req.TransactionDate = time.Now()
+		_, err := service.CreateTransaction(1, req)
+		assert.NoError(t, err)
+	}
+
+	// 删除周期规则生成的交易不扣减记账笔数
+	mockTransRepo.On("FindByIDForUpdate", int64(8)).Return(&models.Transaction{ID: 8, UserID: 1, Type: "expense", AccountID: &accountID, Amount: money.MustParse("10.00"), RecurringRuleID: &ruleID}, nil)
+	mockTransRepo.On("HasLinkedRecords", int64(8)).Return(false, nil)
+	mockTransRepo.On("GetRefundedTotals", []int64{8}).Return(map[int64]money.Money{}, nil)
+	mockTransRepo.On("Delete", int64(8)).Return(nil)
+	assert.NoError(t, service.DeleteTransaction(1, 8))
+
+	mockUserRepo.AssertNotCalled(t, "AddRecords", mock.Anything, mock.Anything, mock.Anything)
+	mockUserRepo.AssertNotCalled(t, "RemoveRecords", mock.Anything, mock.Anything)
+}
+
 func TestGenerateVerificationCode(t *testing.T) {
 	code1, err := email.GenerateVerificationCode()
 	if err != nil {
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/dto/response"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/internal/repository"
+	"github.com/qiuhaonan/float-backend/pkg/logger"
+)
+
+// 存款记录默认返回条数
+const wishlistDepositLimit = 100
+
+var (
+	errWishlistNotFound     = errors.New("心愿不存在")
+	errWishlistClosed       = errors.New("心愿已完成或已取消")
+	errWishlistInsufficient = errors.New("取出金额超过已存金额")
+)
+
+// WishlistService 心愿单服务接口
+type WishlistService interface {
+	GetWishlists(userID int64, status string) ([]*response.WishlistResponse, error)
+	GetWishlist(userID int64, wishlistID int64) (*response.WishlistResponse, error)
+	CreateWishlist(userID int64, req *request.CreateWishlistRequest) (*response.WishlistResponse, error)
+	UpdateWishlist(userID int64, wishlistID int64, req *request.UpdateWishlistRequest) (*response.WishlistResponse, error)
+	DeleteWishlist(userID int64, wishlistID int64) error
+	GetDeposits(userID int64, wishlistID int64) ([]*response.WishlistDepositResponse, error)
+	// Deposit 为心愿存入或取出预留金额
+	Deposit(userID int64, wishlistID int64, req *request.WishlistDepositRequest) (*response.WishlistDepositResultResponse, error)
+	// Purchase 购买心愿：生成带 WishlistID 的支出交易并将心愿标记为已完成
+	Purchase(userID int64, wishlistID int64, req *request.PurchaseWishlistRequest) (*response.PurchaseWishlistResponse, error)
+}
+
+type wishlistService struct {
+	wishlistRepo       repository.WishlistRepository
+	uow                repository.UnitOfWork
+	transactionService TransactionService
+}
+
+// NewWishlistService 创建心愿单服务实例
+func NewWishlistService(
+	wishlistRepo repository.WishlistRepository,
+	uow repository.UnitOfWork,
+	transactionService TransactionService,
+) WishlistService {
+	return &wishlistService{
+		wishlistRepo:       wishlistRepo,
+		uow:                uow,
+		transactionService: transactionService,
+	}
+}
+
+// GetWishlists 获取心愿列表
+func (s *wishlistService) GetWishlists(userID int64, status string) ([]*response.WishlistResponse, error) {
+	wishlists, err := s.wishlistRepo.FindByUserID(userID, status)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][心愿单] 查询失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	result := make([]*response.WishlistResponse, 0, len(wishlists))
+	for _, wishlist := range wishlists {
+		result = append(result, s.toWishlistResponse(wishlist))
+	}
+	return result, nil
+}
+
+// GetWishlist 获取心愿详情
+func (s *wishlistService) GetWishlist(userID int64, wishlistID int64) (*response.WishlistResponse, error) {
+	wishlist, err := s.findUserWishlist(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toWishlistResponse(wishlist), nil
+}
+
+// CreateWishlist 创建心愿
+func (s *wishlistService) CreateWishlist(userID int64, req *request.CreateWishlistRequest) (*response.WishlistResponse, error) {
+	wishlist := &models.Wishlist{
+		UserID:       userID,
+		ItemName:     req.ItemName,
+		TargetAmount: req.TargetAmount,
+		Icon:         req.Icon,
+		ImageURL:     req.ImageURL,
+		Priority:     req.Priority,
+		Status:       models.WishlistStatusPlanning,
+		Description:  req.Description,
+		URL:          req.URL,
+		TargetDate:   req.TargetDate,
+	}
+	if wishlist.Priority == "" {
+		wishlist.Priority = models.WishlistPriorityMedium
+	}
+
+	if err := s.wishlistRepo.Create(wishlist); err != nil {
+		logger.Error(fmt.Sprintf("[Service][心愿单] 创建失败 | 用户ID: %d | 错误: %v", userID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][心愿单] 创建成功 | 用户ID: %d | 心愿ID: %d", userID, wishlist.ID))
+	return s.toWishlistResponse(wishlist), nil
+}
+
+// UpdateWishlist 更新心愿，已完成的心愿不可修改状态
+func (s *wishlistService) UpdateWishlist(userID int64, wishlistID int64, req *request.UpdateWishlistRequest) (*response.WishlistResponse, error) {
+	wishlist, err := s.findUserWishlist(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ItemName != nil {
+		wishlist.ItemName = *req.ItemName
+	}
+	if req.TargetAmount != nil {
+		wishlist.TargetAmount = *req.TargetAmount
+	}
+	if req.Icon != nil {
+		wishlist.Icon = *req.Icon
+	}
+	if req.ImageURL != nil {
+		wishlist.ImageURL = *req.ImageURL
+	}
+	if req.Priority != nil {
+		wishlist.Priority = *req.Priority
+	}
+	if req.Description != nil {
+		wishlist.Description = *req.Description
+	}
+	if req.URL != nil {
+		wishlist.URL = *req.URL
+	}
+	if req.TargetDate != nil {
+		wishlist.TargetDate = req.TargetDate
+	}
+	if req.Status != nil && *req.Status != wishlist.Status {
+		if wishlist.Status == models.WishlistStatusCompleted {
+			return nil, errWishlistClosed
+		}
+		wishlist.Status = *req.Status
+	}
+
+	if err := s.wishlistRepo.Update(wishlist); err != nil {
+		logger.Error(fmt.Sprintf("[Service][心愿单] 更新失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", userID, wishlistID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][心愿单] 更新成功 | 用户ID: %d | 心愿ID: %d", userID, wishlistID))
+	return s.toWishlistResponse(wishlist), nil
+}
+
+// DeleteWishlist 删除心愿
+func (s *wishlistService) DeleteWishlist(userID int64, wishlistID int64) error {
+	if _, err := s.findUserWishlist(userID, wishlistID); err != nil {
+		return err
+	}
+	if err := s.wishlistRepo.Delete(wishlistID); err != nil {
+		logger.Error(fmt.Sprintf("[Service][心愿单] 删除失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", userID, wishlistID, err))
+		return err
+	}
+	logger.Info(fmt.Sprintf("[Service][心愿单] 删除成功 | 用户ID: %d | 心愿ID: %d", userID, wishlistID))
+	return nil
+}
+
+// GetDeposits 获取存款记录
+func (s *wishlistService) GetDeposits(userID int64, wishlistID int64) ([]*response.WishlistDepositResponse, error) {
+	if _, err := s.findUserWishlist(userID, wishlistID); err != nil {
+		return nil, err
+	}
+	deposits, err := s.wishlistRepo.FindDepositsByWishlistID(wishlistID, wishlistDepositLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*response.WishlistDepositResponse, 0, len(deposits))
+	for _, deposit := range deposits {
+		result = append(result, s.toWishlistDepositResponse(deposit))
+	}
+	return result, nil
+}
+
+// Deposit 存入或取出预留金额，首次存入时心愿进入进行中
+func (s *wishlistService) Deposit(userID int64, wishlistID int64, req *request.WishlistDepositRequest) (*response.WishlistDepositResultResponse, error) {
+	if _, err := s.findUserWishlist(userID, wishlistID); err != nil {
+		return nil, err
+	}
+
+	depositDate := truncateToDate(time.Now())
+	if req.DepositDate != nil {
+		depositDate = truncateToDate(*req.DepositDate)
+	}
+	deposit := &models.WishlistDeposit{
+		WishlistID:  wishlistID,
+		RecordType:  models.WishlistRecordDeposit,
+		Amount:      req.Amount,
+		DepositDate: depositDate,
+		Notes:       req.Notes,
+	}
+	if req.Withdraw {
+		deposit.RecordType = models.WishlistRecordWithdraw
+	}
+
+	var updated *models.Wishlist
+	err := s.uow.Transaction(func(repos *repository.TxRepositories) error {
+		locked, err := repos.Wishlists.FindByIDForUpdate(wishlistID)
+		if err != nil {
+			return err
+		}
+		if locked.Status == models.WishlistStatusCompleted || locked.Status == models.WishlistStatusCancelled {
+			return errWishlistClosed
+		}
+
+		if req.Withdraw {
+			if req.Amount > locked.CurrentAmount {
+				return errWishlistInsufficient
+			}
+			locked.CurrentAmount -= req.Amount
+		} else {
+			locked.CurrentAmount += req.Amount
+			if locked.Status == models.WishlistStatusPlanning {
+				locked.Status = models.WishlistStatusInProgress
+			}
+		}
+
+		if err := repos.Wishlists.CreateDeposit(deposit); err != nil {
+			return err
+		}
+		updated = locked
+		return repos.Wishlists.Update(locked)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][心愿单] 存取失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", userID, wishlistID, err))
+		for _, known := range []error{errWishlistClosed, errWishlistInsufficient} {
+			if errors.Is(err, known) {
+				return nil, known
+			}
+		}
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][心愿单] 存取成功 | 用户ID: %d | 心愿ID: %d | 类型: %s | 金额: %s", userID, wishlistID, deposit.RecordType, deposit.Amount))
+	return &response.WishlistDepositResultResponse{
+		Deposit:  s.toWishlistDepositResponse(deposit),
+		Wishlist: s.toWishlistResponse(updated),
+	}, nil
+}
+
+// Purchase 购买心愿，交易与心愿状态在同一事务中提交
+func (s *wishlistService) Purchase(userID int64, wishlistID int64, req *request.PurchaseWishlistRequest) (*response.PurchaseWishlistResponse, error) {
+	wishlist, err := s.findUserWishlist(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	if wishlist.Status == models.WishlistStatusCompleted || wishlist.Status == models.WishlistStatusCancelled {
+		return nil, errWishlistClosed
+	}
+
+	amount := wishlist.TargetAmount
+	if req.Amount > 0 {
+		amount = req.Amount
+	}
+	transactionDate := truncateToDate(time.Now())
+	if req.TransactionDate != nil {
+		transactionDate = truncateToDate(*req.TransactionDate)
+	}
+	accountID := req.AccountID
+	transReq := &request.CreateTransactionRequest{
+		Type:            string(models.TransactionTypeExpense),
+		CategoryID:      req.CategoryID,
+		AccountID:       &accountID,
+		Amount:          amount,
+		Title:           wishlist.ItemName,
+		Description:     req.Notes,
+		TransactionDate: transactionDate,
+		WishlistID:      &wishlist.ID,
+	}
+
+	var purchased *models.Wishlist
+	transaction, err := s.transactionService.CreateLinkedTransaction(userID, transReq, func(repos *repository.TxRepositories, t *models.Transaction) error {
+		// 加锁重读，防止同一心愿被重复购买
+		locked, err := repos.Wishlists.FindByIDForUpdate(wishlist.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status == models.WishlistStatusCompleted || locked.Status == models.WishlistStatusCancelled {
+			return errWishlistClosed
+		}
+
+		now := time.Now()
+		locked.Status = models.WishlistStatusCompleted
+		locked.CompletedAt = &now
+		purchased = locked
+		return repos.Wishlists.Update(locked)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[Service][心愿单] 购买失败 | 用户ID: %d | 心愿ID: %d | 错误: %v", userID, wishlistID, err))
+		if errors.Is(err, errWishlistClosed) {
+			return nil, errWishlistClosed
+		}
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("[Service][心愿单] 购买成功 | 用户ID: %d | 心愿ID: %d | 交易ID: %d", userID, wishlistID, transaction.ID))
+	return &response.PurchaseWishlistResponse{
+		Transaction: transaction,
+		Wishlist:    s.toWishlistResponse(purchased),
+	}, nil
+}
+
+// findUserWishlist 查找并校验心愿归属
+func (s *wishlistService) findUserWishlist(userID int64, wishlistID int64) (*models.Wishlist, error) {
+	wishlist, err := s.wishlistRepo.FindByID(wishlistID)
+	if err != nil || wishlist.UserID != userID {
+		return nil, errWishlistNotFound
+	}
+	return wishlist, nil
+}
+
+// toWishlistResponse 转换为响应对象
+func (s *wishlistService) toWishlistResponse(wishlist *models.Wishlist) *response.WishlistResponse {
+	resp := &response.WishlistResponse{
+		ID:            wishlist.ID,
+		ItemName:      wishlist.ItemName,
+		TargetAmount:  wishlist.TargetAmount,
+		CurrentAmount: wishlist.CurrentAmount,
+		Icon:          wishlist.Icon,
+		ImageURL:      wishlist.ImageURL,
+		Priority:      wishlist.Priority,
+		Status:        wishlist.Status,
+		Description:   wishlist.Description,
+		URL:           wishlist.URL,
+		TargetDate:    wishlist.TargetDate,
+		CreatedAt:     wishlist.CreatedAt,
+		UpdatedAt:     wishlist.UpdatedAt,
+		CompletedAt:   wishlist.CompletedAt,
+	}
+	if wishlist.CurrentAmount < wishlist.TargetAmount {
+		resp.RemainingAmount = wishlist.TargetAmount - wishlist.CurrentAmount
+	}
+	if wishlist.TargetAmount > 0 {
+		progress := wishlist.CurrentAmount.Float64() / wishlist.TargetAmount.Float64() * 100
+		resp.Progress = math.Min(math.Round(progress*100)/100, 100)
+	}
+	return resp
+}
+
+// toWishlistDepositResponse 转换为响应对象
+func (s *wishlistService) toWishlistDepositResponse(deposit *models.WishlistDeposit) *response.WishlistDepositResponse {
+	return &response.WishlistDepositResponse{
+		ID:            deposit.ID,
+		WishlistID:    deposit.WishlistID,
+		TransactionID: deposit.TransactionID,
+		RecordType:    deposit.RecordType,
+		Amount:        deposit.Amount,
+		DepositDate:   deposit.DepositDate,
+		Notes:         deposit.Notes,
+		CreatedAt:     deposit.CreatedAt,
+	}
+}
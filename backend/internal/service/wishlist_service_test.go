@@ -0,0 +1,163 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/qiuhaonan/float-backend/internal/dto/request"
+	"github.com/qiuhaonan/float-backend/internal/models"
+	"github.com/qiuhaonan/float-backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWishlistRepository Mock WishlistRepository
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) Create(wishlist *models.Wishlist) error {
+	args := m.Called(wishlist)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) FindByID(id int64) (*models.Wishlist, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) FindByIDForUpdate(id int64) (*models.Wishlist, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) FindByUserID(userID int64, status string) ([]*models.Wishlist, error) {
+	args := m.Called(userID, status)
+	return args.Get(0).([]*models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) Update(wishlist *models.Wishlist) error {
+	args := m.Called(wishlist)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) CountActive(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWishlistRepository) CreateDeposit(deposit *models.WishlistDeposit) error {
+	args := m.Called(deposit)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) FindDepositsByWishlistID(wishlistID int64, limit int) ([]*models.WishlistDeposit, error) {
+	args := m.Called(wishlistID, limit)
+	return args.Get(0).([]*models.WishlistDeposit), args.Error(1)
+}
+
+func newTestWishlistService() (WishlistService, *MockWishlistRepository, *MockTransactionRepository, *MockAccountRepository) {
+	mockWishlistRepo := new(MockWishlistRepository)
+	mockTransRepo := new(MockTransactionRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	uow := &MockUnitOfWork{transactionRepo: mockTransRepo, accountRepo: mockAccountRepo, wishlistRepo: mockWishlistRepo}
+	transService := newTestTransactionService(uow, mockCategoryRepo)
+	return NewWishlistService(mockWishlistRepo, uow, transService), mockWishlistRepo, mockTransRepo, mockAccountRepo
+}
+
+// TestWishlistPurchase 测试购买心愿生成关联支出交易并标记为已完成
+func TestWishlistPurchase(t *testing.T) {
+	svc, mockWishlistRepo, mockTransRepo, mockAccountRepo := newTestWishlistService()
+
+	userID := int64(1)
+	accountID := int64(100)
+	wishlist := &models.Wishlist{
+		ID:            4,
+		UserID:        userID,
+		ItemName:      "降噪耳机",
+		TargetAmount:  money.MustParse("1999.00"),
+		CurrentAmount: money.MustParse("1500.00"),
+		Status:        models.WishlistStatusInProgress,
+	}
+	locked := *wishlist
+	mockWishlistRepo.On("FindByID", int64(4)).Return(wishlist, nil)
+	mockWishlistRepo.On("FindByIDForUpdate", int64(4)).Return(&locked, nil)
+	mockWishlistRepo.On("Update", &locked).Return(nil)
+
+	account := &models.Account{ID: accountID, UserID: userID, Balance: money.MustParse("3000.00")}
+	mockAccountRepo.On("FindByID", accountID).Return(account, nil)
+	mockAccountRepo.On("FindByIDForUpdate", accountID).Return(account, nil)
+	mockAccountRepo.On("Update", account).Return(nil)
+
+	mockTransRepo.On("Create", mock.MatchedBy(func(tr *models.Transaction) bool {
+		return tr.Type == "expense" && tr.WishlistID != nil && *tr.WishlistID == 4 && tr.Amount == money.MustParse("1899.00")
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transaction).ID = 66
+	}).Return(nil)
+	mockTransRepo.On("FindByID", int64(66)).Return(&models.Transaction{ID: 66, UserID: userID, Type: "expense", Amount: money.MustParse("1899.00")}, nil)
+
+	// 实际成交价低于目标价
+	resp, err := svc.Purchase(userID, 4, &request.PurchaseWishlistRequest{AccountID: accountID, Amount: money.MustParse("1899.00")})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(66), resp.Transaction.ID)
+	assert.Equal(t, models.WishlistStatusCompleted, resp.Wishlist.Status)
+	assert.NotNil(t, locked.CompletedAt)
+	assert.Equal(t, money.MustParse("1101.00"), account.Balance)
+}
+
+// TestWishlistPurchaseClosed 测试已完成的心愿不能重复购买
+func TestWishlistPurchaseClosed(t *testing.T) {
+	svc, mockWishlistRepo, mockTransRepo, _ := newTestWishlistService()
+
+	mockWishlistRepo.On("FindByID", int64(4)).Return(&models.Wishlist{
+		ID:           4,
+		UserID:       1,
+		TargetAmount: money.MustParse("100.00"),
+		Status:       models.WishlistStatusCompleted,
+	}, nil)
+
+	_, err := svc.Purchase(1, 4, &request.PurchaseWishlistRequest{AccountID: 100})
+
+	assert.Equal(t, errWishlistClosed, err)
+	mockTransRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestWishlistDeposit 测试首次存入进入进行中，取出不得超过已存金额
+func TestWishlistDeposit(t *testing.T) {
+	svc, mockWishlistRepo, _, _ := newTestWishlistService()
+
+	wishlist := &models.Wishlist{
+		ID:           4,
+		UserID:       1,
+		TargetAmount: money.MustParse("500.00"),
+		Status:       models.WishlistStatusPlanning,
+	}
+	mockWishlistRepo.On("FindByID", int64(4)).Return(wishlist, nil)
+	mockWishlistRepo.On("FindByIDForUpdate", int64(4)).Return(wishlist, nil)
+	mockWishlistRepo.On("CreateDeposit", mock.Anything).Return(nil)
+	mockWishlistRepo.On("Update", wishlist).Return(nil)
+
+	resp, err := svc.Deposit(1, 4, &request.WishlistDepositRequest{Amount: money.MustParse("200.00")})
+	assert.NoError(t, err)
+	assert.Equal(t, models.WishlistStatusInProgress, resp.Wishlist.Status)
+	assert.Equal(t, money.MustParse("300.00"), resp.Wishlist.RemainingAmount)
+	assert.Equal(t, float64(40), resp.Wishlist.Progress)
+
+	_, err = svc.Deposit(1, 4, &request.WishlistDepositRequest{Amount: money.MustParse("250.00"), Withdraw: true})
+	assert.Equal(t, errWishlistInsufficient, err)
+	assert.Equal(t, money.MustParse("200.00"), wishlist.CurrentAmount)
+	mockWishlistRepo.AssertNumberOfCalls(t, "CreateDeposit", 1)
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,9 @@ import (
 var (
 	Client *redis.Client
 	ctx    = context.Background()
+
+	// ErrNotInitialized Redis 尚未初始化（如单元测试环境）
+	ErrNotInitialized = errors.New("redis client not initialized")
 )
 
 // Init 初始化 Redis 连接
@@ -34,34 +38,77 @@ func Init() error {
 
 // Get 获取缓存
 func Get(key string) (string, error) {
+	if Client == nil {
+		return "", ErrNotInitialized
+	}
 	return Client.Get(ctx, key).Result()
 }
 
 // Set 设置缓存
 func Set(key string, value interface{}, expiration time.Duration) error {
+	if Client == nil {
+		return ErrNotInitialized
+	}
 	return Client.Set(ctx, key, value, expiration).Err()
 }
 
 // Del 删除缓存
 func Del(keys ...string) error {
+	if Client == nil {
+		return ErrNotInitialized
+	}
 	return Client.Del(ctx, keys...).Err()
 }
 
 // Exists 检查键是否存在
 func Exists(keys ...string) (int64, error) {
+	if Client == nil {
+		return 0, ErrNotInitialized
+	}
 	return Client.Exists(ctx, keys...).Result()
 }
 
 // Incr 自增
 func Incr(key string) (int64, error) {
+	if Client == nil {
+		return 0, ErrNotInitialized
+	}
 	return Client.Incr(ctx, key).Result()
 }
 
 // Expire 设置过期时间
 func Expire(key string, expiration time.Duration) error {
+	if Client == nil {
+		return ErrNotInitialized
+	}
 	return Client.Expire(ctx, key, expiration).Err()
 }
 
+// DelPattern 按通配符删除缓存（使用 SCAN 分批遍历，避免 KEYS 阻塞）
+func DelPattern(pattern string) error {
+	if Client == nil {
+		return ErrNotInitialized
+	}
+	iter := Client.Scan(ctx, 0, pattern, 500).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= 500 {
+			if err := Client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return Client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
 // Close 关闭 Redis 连接
 func Close() error {
 	if Client != nil {
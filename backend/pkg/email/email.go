@@ -136,7 +136,7 @@ func (s *emailService) sendEmail(toEmail, subject, body string) error {
 	}
 
 	// SMTP 连接地址
-	addr := fmt.Sprintf("%s:%s", s.smtpHost, s.smtpPort)
+	addr := net.JoinHostPort(s.smtpHost, s.smtpPort)
 
 	// 第一步：建立TCP连接
 	conn, err := net.Dial("tcp", addr)
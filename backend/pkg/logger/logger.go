@@ -7,7 +7,8 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-var Logger *zap.SugaredLogger
+// Logger 全局日志实例，未调用 Init 前为空实现，避免单元测试中空指针
+var Logger = zap.NewNop().Sugar()
 
 // Init 初始化日志
 func Init() {
@@ -0,0 +1,161 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money 金额类型，以最小货币单位（分）的整数存储，避免浮点运算误差
+// 数据库中映射为 decimal(15,2)，JSON 中序列化为保留两位小数的数字
+type Money int64
+
+// Zero 零金额
+const Zero Money = 0
+
+// FromCents 由分构造金额
+func FromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// FromFloat 由浮点数构造金额，按十进制表示四舍五入到分
+func FromFloat(f float64) Money {
+	m, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Zero
+	}
+	return m
+}
+
+// Parse 解析十进制金额字符串（如 "12.34"、"-5"、"1e3"），超过两位小数时四舍五入
+func Parse(s string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return Zero, fmt.Errorf("invalid money amount: %q", s)
+	}
+	return fromRat(r.Mul(r, big.NewRat(100, 1)))
+}
+
+// MustParse 解析金额字符串，失败时 panic（仅用于常量和测试）
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// fromRat 将以分为单位的有理数四舍五入（远离零）为金额
+func fromRat(r *big.Rat) (Money, error) {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(den) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	if !quo.IsInt64() {
+		return Zero, fmt.Errorf("money amount out of range: %s", r.FloatString(2))
+	}
+	return Money(quo.Int64()), nil
+}
+
+// Cents 返回以分为单位的整数值
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Float64 返回浮点表示，仅用于展示或比例计算，不可回写参与记账
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// Abs 绝对值
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// MulRat 按比例换算（如汇率、分摊比例），结果四舍五入到分，超出金额范围时返回错误
+func (m Money) MulRat(r *big.Rat) (Money, error) {
+	return fromRat(new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), r))
+}
+
+// String 返回两位小数的十进制字符串，如 "-12.30"
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON 序列化为 JSON 数字
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON 支持 JSON 数字或字符串，按十进制精确解析
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	if s == "" {
+		*m = Zero
+		return nil
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口，以十进制字符串写入 decimal 列
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = Zero
+		return nil
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case int64:
+		*m = Money(v * 100)
+		return nil
+	case float64:
+		*m = FromFloat(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into money.Money", value)
+	}
+}
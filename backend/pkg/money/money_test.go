@@ -0,0 +1,88 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{"0", 0},
+		{"12.34", 1234},
+		{"-12.3", -1230},
+		{"100", 10000},
+		{"0.005", 1},
+		{"-0.005", -1},
+		{"0.004", 0},
+		{"1e2", 10000},
+		{"0.30000000000000004", 30},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+
+	_, err := Parse("abc")
+	assert.Error(t, err)
+}
+
+func TestAccumulationHasNoDrift(t *testing.T) {
+	// 浮点数累加一万次 0.1 会产生误差，Money 必须精确
+	var total Money
+	for i := 0; i < 10000; i++ {
+		total += MustParse("0.10")
+	}
+	assert.Equal(t, MustParse("1000.00"), total)
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Amount Money `json:"amount"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": 19.99}`), &v))
+	assert.Equal(t, FromCents(1999), v.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": "-5.5"}`), &v))
+	assert.Equal(t, FromCents(-550), v.Amount)
+
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount": -5.50}`, string(data))
+}
+
+func TestScanAndValue(t *testing.T) {
+	var m Money
+	assert.NoError(t, m.Scan([]byte("1234.56")))
+	assert.Equal(t, FromCents(123456), m)
+
+	assert.NoError(t, m.Scan(int64(3)))
+	assert.Equal(t, FromCents(300), m)
+
+	value, err := FromCents(-7).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "-0.07", value)
+}
+
+func TestMulRat(t *testing.T) {
+	// 100.00 * 7.1234 = 712.34
+	result, err := MustParse("100.00").MulRat(big.NewRat(71234, 10000))
+	assert.NoError(t, err)
+	assert.Equal(t, MustParse("712.34"), result)
+	// 10.00 / 3 = 3.33
+	result, err = MustParse("10.00").MulRat(big.NewRat(1, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, MustParse("3.33"), result)
+	// 超出金额范围时返回错误而不是零
+	_, err = FromCents(math.MaxInt64 / 2).MulRat(big.NewRat(3, 1))
+	assert.Error(t, err)
+}
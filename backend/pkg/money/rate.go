@@ -0,0 +1,137 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Rate 汇率，以 1e-8 为最小单位的整数存储，避免浮点运算误差
+// 数据库中映射为 decimal(18,8)，JSON 中序列化为十进制数字
+type Rate int64
+
+// rateScale 汇率的缩放倍数（8 位小数）
+const rateScale = 100000000
+
+// OneRate 1:1 汇率，同币种换算或未设置汇率时使用
+const OneRate Rate = rateScale
+
+// ParseRate 解析十进制汇率字符串（如 "7.1234"），超过八位小数时四舍五入，必须为正数
+func ParseRate(s string) (Rate, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid exchange rate: %q", s)
+	}
+	scaled, err := fromRat(r.Mul(r, big.NewRat(rateScale, 1)))
+	if err != nil || scaled <= 0 {
+		return 0, fmt.Errorf("invalid exchange rate: %q", s)
+	}
+	return Rate(scaled), nil
+}
+
+// MustParseRate 解析汇率字符串，失败时 panic（仅用于常量和测试）
+func MustParseRate(s string) Rate {
+	r, err := ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Rat 返回有理数表示，用于金额换算
+func (r Rate) Rat() *big.Rat {
+	return big.NewRat(int64(r), rateScale)
+}
+
+// Inverse 倒数汇率（如 USD/CNY 换算为 CNY/USD），结果四舍五入到八位小数
+func (r Rate) Inverse() Rate {
+	if r <= 0 {
+		return 0
+	}
+	inverse, err := fromRat(big.NewRat(rateScale*rateScale, int64(r)))
+	if err != nil {
+		return 0
+	}
+	return Rate(inverse)
+}
+
+// Cross 经由中间币种换算的交叉汇率：r 为 A→P，other 为 P→B，结果为 A→B
+func (r Rate) Cross(other Rate) Rate {
+	cross, err := fromRat(new(big.Rat).Mul(big.NewRat(int64(r), 1), big.NewRat(int64(other), rateScale)))
+	if err != nil {
+		return 0
+	}
+	return Rate(cross)
+}
+
+// Convert 按汇率换算金额，结果四舍五入到分，超出金额范围时返回错误
+func (m Money) Convert(r Rate) (Money, error) {
+	return m.MulRat(r.Rat())
+}
+
+// String 返回去除末尾零的十进制字符串，如 "7.1234"
+func (r Rate) String() string {
+	s := r.Rat().FloatString(8)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// MarshalJSON 序列化为 JSON 数字
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON 支持 JSON 数字或字符串，按十进制精确解析
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(strings.TrimSpace(string(data)), `"`)
+	if s == "null" || s == "" {
+		return nil
+	}
+
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口，以十进制字符串写入 decimal 列
+func (r Rate) Value() (driver.Value, error) {
+	return r.Rat().FloatString(8), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (r *Rate) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*r = 0
+		return nil
+	case []byte:
+		return r.scanString(string(v))
+	case string:
+		return r.scanString(v)
+	case int64:
+		*r = Rate(v * rateScale)
+		return nil
+	case float64:
+		return r.scanString(fmt.Sprintf("%.8f", v))
+	default:
+		return fmt.Errorf("cannot scan %T into money.Rate", value)
+	}
+}
+
+// scanString 解析数据库中的十进制字符串
+func (r *Rate) scanString(s string) error {
+	parsed, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return fmt.Errorf("invalid exchange rate: %q", s)
+	}
+	scaled, err := fromRat(parsed.Mul(parsed, big.NewRat(rateScale, 1)))
+	if err != nil {
+		return err
+	}
+	*r = Rate(scaled)
+	return nil
+}
@@ -0,0 +1,53 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRate(t *testing.T) {
+	r, err := ParseRate("7.1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "7.1234", r.String())
+	assert.Equal(t, "1", OneRate.String())
+
+	_, err = ParseRate("0")
+	assert.Error(t, err)
+	_, err = ParseRate("-1.5")
+	assert.Error(t, err)
+	_, err = ParseRate("abc")
+	assert.Error(t, err)
+}
+
+func TestRateConvert(t *testing.T) {
+	usdCNY := MustParseRate("7.2")
+	converted, err := MustParse("100.00").Convert(usdCNY)
+	assert.NoError(t, err)
+	assert.Equal(t, MustParse("720.00"), converted)
+	// 倒数汇率四舍五入到八位小数
+	assert.Equal(t, "0.13888889", usdCNY.Inverse().String())
+	// EUR→USD→CNY
+	assert.Equal(t, "7.776", MustParseRate("1.08").Cross(usdCNY).String())
+}
+
+func TestRateJSONAndScan(t *testing.T) {
+	var payload struct {
+		Rate Rate `json:"rate"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"rate":0.92}`), &payload))
+	assert.Equal(t, MustParseRate("0.92"), payload.Rate)
+
+	data, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"rate":0.92}`, string(data))
+
+	var scanned Rate
+	assert.NoError(t, scanned.Scan([]byte("7.12340000")))
+	assert.Equal(t, MustParseRate("7.1234"), scanned)
+
+	value, err := scanned.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "7.12340000", value)
+}